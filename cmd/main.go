@@ -1,15 +1,20 @@
 package main
 
 import (
+	"context"
 	"net"
 	"net/http"
+	"time"
 
 	"Finance-Manager-System/configs"
 	"Finance-Manager-System/internal/infrastructure/cache"
 	"Finance-Manager-System/internal/infrastructure/database"
+	"Finance-Manager-System/internal/infrastructure/jwtauth"
 	"Finance-Manager-System/internal/infrastructure/logger"
+	"Finance-Manager-System/internal/infrastructure/mailer"
 	authMiddleware "Finance-Manager-System/internal/infrastructure/middleware"
 	"Finance-Manager-System/internal/infrastructure/postgres"
+	"Finance-Manager-System/internal/infrastructure/ratelimit"
 
 	"github.com/go-chi/chi/v5"
 	chiMiddleware "github.com/go-chi/chi/v5/middleware"
@@ -52,6 +57,42 @@ import (
 	goalHandler "Finance-Manager-System/internal/infrastructure/modules/goals/handler"
 	goalRepo "Finance-Manager-System/internal/infrastructure/modules/goals/repository"
 	goalUC "Finance-Manager-System/internal/infrastructure/modules/goals/usecase"
+
+	// Модуль Currency
+	currencyHandler "Finance-Manager-System/internal/infrastructure/modules/currency/handler"
+	currencyRepo "Finance-Manager-System/internal/infrastructure/modules/currency/repository"
+	currencyUC "Finance-Manager-System/internal/infrastructure/modules/currency/usecase"
+
+	// Модуль Recurring
+	recurringHandler "Finance-Manager-System/internal/infrastructure/modules/recurring/handler"
+	recurringRepo "Finance-Manager-System/internal/infrastructure/modules/recurring/repository"
+	recurringUC "Finance-Manager-System/internal/infrastructure/modules/recurring/usecase"
+
+	// Модуль Budgets
+	budgetHandler "Finance-Manager-System/internal/infrastructure/modules/budgets/handler"
+	budgetRepo "Finance-Manager-System/internal/infrastructure/modules/budgets/repository"
+	budgetUC "Finance-Manager-System/internal/infrastructure/modules/budgets/usecase"
+
+	// Модуль Tags
+	tagHandler "Finance-Manager-System/internal/infrastructure/modules/tags/handler"
+	tagRepo "Finance-Manager-System/internal/infrastructure/modules/tags/repository"
+	tagUC "Finance-Manager-System/internal/infrastructure/modules/tags/usecase"
+
+	// Модуль Rules
+	ruleHandler "Finance-Manager-System/internal/infrastructure/modules/rules/handler"
+	ruleRepo "Finance-Manager-System/internal/infrastructure/modules/rules/repository"
+	ruleUC "Finance-Manager-System/internal/infrastructure/modules/rules/usecase"
+
+	// Модуль Debts
+	debtHandler "Finance-Manager-System/internal/infrastructure/modules/debts/handler"
+	debtRepo "Finance-Manager-System/internal/infrastructure/modules/debts/repository"
+	debtUC "Finance-Manager-System/internal/infrastructure/modules/debts/usecase"
+
+	// Парсеры банковских выписок
+	"Finance-Manager-System/internal/infrastructure/modules/csvstatement"
+	"Finance-Manager-System/internal/infrastructure/modules/ofxstatement"
+	"Finance-Manager-System/internal/infrastructure/modules/statement"
+	"Finance-Manager-System/internal/infrastructure/modules/tbankpdf"
 )
 
 // @title Finance Manager API
@@ -69,8 +110,6 @@ func main() {
 	}
 	defer logger.Sync()
 
-	authMiddleware.SetJWTSecret(cnf.JWTSecret)
-
 	db, err := postgres.NewDB(cnf)
 	if err != nil {
 		zap.L().Fatal("db_connection_failed", zap.Error(err))
@@ -82,7 +121,32 @@ func main() {
 		zap.L().Info("redis_cache_enabled")
 	}
 
+	jwtKeys, err := jwtauth.NewKeySet(cnf.JWT, cnf.JWTSecret)
+	if err != nil {
+		zap.L().Fatal("jwt_keys_invalid", zap.Error(err))
+	}
+
+	trustedProxies, err := authMiddleware.ParseTrustedProxies(cnf.HttpServer.TrustedProxies)
+	if err != nil {
+		zap.L().Fatal("trusted_proxies_invalid", zap.Error(err))
+	}
+
+	mail, err := mailer.New(cnf.Mail)
+	if err != nil {
+		zap.L().Fatal("mailer_invalid", zap.Error(err))
+	}
+
+	rateStore := ratelimit.NewStore(redisCache)
+	limiter := ratelimit.NewLimiter(rateStore)
+	loginLockout := ratelimit.NewLockout(rateStore, ratelimit.LockoutPolicy{
+		MaxFailures:   int64(cnf.RateLimit.LoginMaxFailures),
+		BaseLock:      time.Duration(cnf.RateLimit.LoginLockoutSeconds) * time.Second,
+		MaxLock:       time.Duration(cnf.RateLimit.LoginMaxLockoutSeconds) * time.Second,
+		FailureWindow: 24 * time.Hour,
+	})
+
 	txManager := database.NewTxManager(db)
+	statementParsers := statement.NewRegistry(tbankpdf.NewParser(), ofxstatement.NewParser(), csvstatement.NewParser())
 
 	userRepository := userRepo.NewUserRepository(db)
 	accRepository := accountRepo.NewAccountRepo(db)
@@ -91,22 +155,55 @@ func main() {
 	analyticsRepository := analyticsRepo.NewAnalyticsRepository(db)
 	recommendationsRepository := recommendationRepo.NewRecommendationRepository(db)
 	goalsRepository := goalRepo.NewGoalRepo(db)
+	currencyRepository := currencyRepo.NewCurrencyRepo(db)
+	recurringRepository := recurringRepo.NewRecurringRepo(db)
+	budgetRepository := budgetRepo.NewBudgetRepo(db)
+	tagRepository := tagRepo.NewTagRepo(db)
+	ruleRepository := ruleRepo.NewRuleRepo(db)
+	debtRepository := debtRepo.NewDebtRepo(db)
 
-	userUseCase := userUC.NewUserCase(userRepository, cnf.JWTSecret, catRepository)
-	accountUseCase := accountUC.NewAccountUseCase(accRepository, catRepository, transactionRepository, txManager)
-	transactionUseCase := transUC.NewTransactionUseCase(transactionRepository, accRepository, txManager)
+	ruleUseCase := ruleUC.NewRuleUseCase(ruleRepository, catRepository, tagRepository, accRepository, txManager)
+	userUseCase := userUC.NewUserCase(userRepository, jwtKeys, time.Duration(cnf.Auth.AccessTTLMinutes)*time.Minute, time.Duration(cnf.Auth.RefreshTTLHours)*time.Hour, catRepository, loginLockout, mail, userUC.EmailSettings{
+		LinkBaseURL: cnf.Mail.LinkBaseURL,
+		ResetTTL:    time.Duration(cnf.Auth.PasswordResetTTLMinutes) * time.Minute,
+		VerifyTTL:   time.Duration(cnf.Auth.EmailVerifyTTLHours) * time.Hour,
+		ResetLimit:  ratelimit.Rule{Limit: int64(cnf.RateLimit.PasswordResetsPerHour), Window: time.Hour},
+	}, limiter)
+	accountUseCase := accountUC.NewAccountUseCase(accRepository, catRepository, transactionRepository, ruleUseCase, statementParsers, txManager)
+	transactionUseCase := transUC.NewTransactionUseCase(transactionRepository, accRepository, tagRepository, txManager)
 	categoryUseCase := categoryUC.NewCategoryUseCase(catRepository, transactionRepository, txManager)
 	analyticsUseCase := analyticsUC.NewAnalyticsUseCase(analyticsRepository)
 	recommendationsUseCase := recommendationUC.NewRecommendationUseCase(recommendationsRepository)
 	goalsUseCase := goalUC.NewGoalUseCase(goalsRepository, transactionRepository, txManager)
+	currencyUseCase := currencyUC.NewCurrencyUseCase(currencyRepository)
+	recurringUseCase := recurringUC.NewRecurringUseCase(recurringRepository, accRepository, transactionUseCase, txManager, redisCache)
+	budgetUseCase := budgetUC.NewBudgetUseCase(budgetRepository, catRepository, recommendationsUseCase, txManager)
+	tagUseCase := tagUC.NewTagUseCase(tagRepository, txManager)
+	debtUseCase := debtUC.NewDebtUseCase(debtRepository, accRepository, txManager)
+
+	authenticator := authMiddleware.NewAuthenticator(jwtKeys, userUseCase)
 
-	userRouter := userHandler.NewUserRouter(userUseCase)
+	ipLimit := authMiddleware.RateLimit(limiter, "ip", ratelimit.Rule{Limit: int64(cnf.RateLimit.IPPerMinute), Window: time.Minute}, authMiddleware.ClientIPKey)
+	authLimit := authMiddleware.RateLimit(limiter, "auth", ratelimit.Rule{Limit: int64(cnf.RateLimit.AuthPerMinute), Window: time.Minute}, authMiddleware.ClientIPKey)
+	userLimit := authMiddleware.RateLimit(limiter, "user", ratelimit.Rule{Limit: int64(cnf.RateLimit.UserPerMinute), Window: time.Minute}, authMiddleware.UserKey)
+	uploadLimit := authMiddleware.RateLimit(limiter, "upload", ratelimit.Rule{Limit: int64(cnf.RateLimit.UploadsPerHour), Window: time.Hour}, authMiddleware.UploadKey)
+
+	userRouter := userHandler.NewUserRouter(userUseCase, authenticator.RequireAuth, authLimit)
 	accountRouter := accountHandler.NewAccountRouter(accountUseCase)
 	categoryRouter := categoryHandler.NewCategoryRouter(categoryUseCase)
 	transactionRouter := transHandler.NewTransactionRouter(transactionUseCase)
 	analyticsRouter := analyticsHandler.NewAnalyticsRouter(analyticsUseCase)
 	recommendationRouter := recommendationHandler.NewRecommendationRouter(recommendationsUseCase)
 	goalsRouter := goalHandler.NewGoalRouter(goalsUseCase)
+	currencyRouter := currencyHandler.NewCurrencyRouter(currencyUseCase)
+	recurringRouter := recurringHandler.NewRecurringRouter(recurringUseCase)
+	budgetRouter := budgetHandler.NewBudgetRouter(budgetUseCase)
+	tagRouter := tagHandler.NewTagRouter(tagUseCase)
+	ruleRouter := ruleHandler.NewRuleRouter(ruleUseCase)
+	debtRouter := debtHandler.NewDebtRouter(debtUseCase)
+
+	recurringScheduler := recurringUC.NewScheduler(recurringUseCase, time.Duration(cnf.Scheduler.IntervalSeconds)*time.Second)
+	go recurringScheduler.Run(context.Background())
 
 	r := chi.NewRouter()
 	r.Use(cors.Handler(cors.Options{
@@ -118,17 +215,21 @@ func main() {
 	}))
 
 	r.Use(chiMiddleware.RequestID)
-	r.Use(chiMiddleware.RealIP)
+	r.Use(authMiddleware.RealIP(trustedProxies))
 	r.Use(authMiddleware.ZapRequestLogger)
 	r.Use(authMiddleware.ZapRecoverer)
 
 	r.Get("/swagger/*", httpSwagger.WrapHandler)
+	r.Get("/.well-known/jwks.json", jwtKeys.JWKSHandler)
 
 	r.Route("/api/v1", func(r chi.Router) {
+		r.Use(ipLimit)
 		r.Mount("/users", userRouter.Route())
 
 		r.Group(func(r chi.Router) {
-			r.Use(authMiddleware.RequireAuth)
+			r.Use(authenticator.RequireAuth)
+			r.Use(userLimit)
+			r.Use(uploadLimit)
 			r.Use(authMiddleware.CacheHTTPMiddleware(redisCache))
 			r.Mount("/accounts", accountRouter.Route())
 			r.Mount("/categories", categoryRouter.Route())
@@ -136,6 +237,12 @@ func main() {
 			r.Mount("/analytics", analyticsRouter.Route())
 			r.Mount("/recommendations", recommendationRouter.Route())
 			r.Mount("/goals", goalsRouter.Route())
+			r.Mount("/currencies", currencyRouter.Route())
+			r.Mount("/recurring", recurringRouter.Route())
+			r.Mount("/budgets", budgetRouter.Route())
+			r.Mount("/tags", tagRouter.Route())
+			r.Mount("/rules", ruleRouter.Route())
+			r.Mount("/debts", debtRouter.Route())
 		})
 	})
 
@@ -13,8 +13,78 @@ type Config struct {
 	Postgres   PostgressConfig `yaml:"postgres"`
 	Redis      RedisConfig     `yaml:"redis"`
 	Logger     LoggerConfig    `yaml:"logger"`
+	Scheduler  SchedulerConfig `yaml:"scheduler"`
+	Auth       AuthConfig      `yaml:"auth"`
+	JWT        JWTConfig       `yaml:"jwt"`
+	RateLimit  RateLimitConfig `yaml:"rate_limit"`
+	Mail       MailConfig      `yaml:"mail"`
 	TypeDB     string          `yaml:"db_type" env:"TYPE_DB" env-default:"postgres"`
-	JWTSecret  string          `yaml:"jwt_secret" env:"JWT_SECRET" env-required:"true"`
+	// JWTSecret adds an HS256 key with id "hs256". It is optional once
+	// asymmetric keys are configured in JWT.Keys.
+	JWTSecret string `yaml:"jwt_secret" env:"JWT_SECRET"`
+}
+
+type SchedulerConfig struct {
+	IntervalSeconds int `yaml:"interval_seconds" env:"SCHEDULER_INTERVAL_SECONDS" env-default:"60"`
+}
+
+type AuthConfig struct {
+	AccessTTLMinutes int `yaml:"access_ttl_minutes" env:"AUTH_ACCESS_TTL_MINUTES" env-default:"15"`
+	RefreshTTLHours  int `yaml:"refresh_ttl_hours" env:"AUTH_REFRESH_TTL_HOURS" env-default:"720"`
+	// How long the emailed password reset and email verification links work.
+	PasswordResetTTLMinutes int `yaml:"password_reset_ttl_minutes" env:"AUTH_PASSWORD_RESET_TTL_MINUTES" env-default:"60"`
+	EmailVerifyTTLHours     int `yaml:"email_verify_ttl_hours" env:"AUTH_EMAIL_VERIFY_TTL_HOURS" env-default:"48"`
+}
+
+// MailConfig picks how emails are sent: "smtp" delivers them through
+// SMTPHost, "file" writes each one as an .eml file to FileDir for local
+// development. Links in emails start with LinkBaseURL.
+type MailConfig struct {
+	Driver       string `yaml:"driver" env:"MAIL_DRIVER" env-default:"file"`
+	From         string `yaml:"from" env:"MAIL_FROM" env-default:"Finance Manager <no-reply@localhost>"`
+	LinkBaseURL  string `yaml:"link_base_url" env:"MAIL_LINK_BASE_URL" env-default:"http://localhost:8080"`
+	FileDir      string `yaml:"file_dir" env:"MAIL_FILE_DIR" env-default:"./mail"`
+	SMTPHost     string `yaml:"smtp_host" env:"MAIL_SMTP_HOST" env-default:"localhost"`
+	SMTPPort     string `yaml:"smtp_port" env:"MAIL_SMTP_PORT" env-default:"587"`
+	SMTPUsername string `yaml:"smtp_username" env:"MAIL_SMTP_USERNAME"`
+
+	SMTPPassword string `env:"MAIL_SMTP_PASSWORD"`
+}
+
+// RateLimitConfig sets request limits per client IP, per signed-in user,
+// for the sign-in endpoints and for file uploads; zero turns a limit off.
+// After LoginMaxFailures wrong passwords the login is locked, and every
+// further failure doubles the lock up to LoginMaxLockoutSeconds. At most
+// PasswordResetsPerHour reset emails are sent to one address.
+type RateLimitConfig struct {
+	IPPerMinute            int `yaml:"ip_per_minute" env:"RATE_LIMIT_IP_PER_MINUTE" env-default:"300"`
+	UserPerMinute          int `yaml:"user_per_minute" env:"RATE_LIMIT_USER_PER_MINUTE" env-default:"600"`
+	AuthPerMinute          int `yaml:"auth_per_minute" env:"RATE_LIMIT_AUTH_PER_MINUTE" env-default:"10"`
+	UploadsPerHour         int `yaml:"uploads_per_hour" env:"RATE_LIMIT_UPLOADS_PER_HOUR" env-default:"30"`
+	LoginMaxFailures       int `yaml:"login_max_failures" env:"RATE_LIMIT_LOGIN_MAX_FAILURES" env-default:"5"`
+	LoginLockoutSeconds    int `yaml:"login_lockout_seconds" env:"RATE_LIMIT_LOGIN_LOCKOUT_SECONDS" env-default:"60"`
+	LoginMaxLockoutSeconds int `yaml:"login_max_lockout_seconds" env:"RATE_LIMIT_LOGIN_MAX_LOCKOUT_SECONDS" env-default:"3600"`
+	PasswordResetsPerHour  int `yaml:"password_resets_per_hour" env:"RATE_LIMIT_PASSWORD_RESETS_PER_HOUR" env-default:"3"`
+}
+
+// JWTConfig lists the keys tokens are signed and verified with. Tokens are
+// signed with SigningKeyID, or the first key when it is empty; the other keys
+// only verify, so a retired key is kept here until its tokens expire.
+type JWTConfig struct {
+	Issuer        string         `yaml:"issuer" env:"JWT_ISSUER" env-default:"finance-manager"`
+	Audience      string         `yaml:"audience" env:"JWT_AUDIENCE" env-default:"finance-manager-api"`
+	SigningKeyID  string         `yaml:"signing_key_id" env:"JWT_SIGNING_KEY_ID"`
+	LeewaySeconds int            `yaml:"leeway_seconds" env:"JWT_LEEWAY_SECONDS" env-default:"30"`
+	Keys          []JWTKeyConfig `yaml:"keys"`
+}
+
+// JWTKeyConfig is an RS256 or EdDSA key in PEM files. A key with only a
+// public key file can verify tokens but not sign them.
+type JWTKeyConfig struct {
+	ID             string `yaml:"id"`
+	Algorithm      string `yaml:"algorithm"`
+	PrivateKeyFile string `yaml:"private_key_file"`
+	PublicKeyFile  string `yaml:"public_key_file"`
 }
 
 type LoggerConfig struct {
@@ -23,6 +93,10 @@ type LoggerConfig struct {
 type HttpServer struct {
 	Port   string `yaml:"port" env-default:"8080"`
 	Adress string `yaml:"adress" env-default:"localhost"`
+	// TrustedProxies lists the proxies, as CIDRs or IPs, whose
+	// X-Forwarded-For and X-Real-IP headers name the client. Requests from
+	// other addresses are known by their socket address.
+	TrustedProxies []string `yaml:"trusted_proxies" env:"HTTP_TRUSTED_PROXIES" env-separator:","`
 }
 type PostgressConfig struct {
 	Host   string `yaml:"host" env:"POSTGRESS_HOST" env-default:"postgres"`
@@ -3,6 +3,7 @@ package cache
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strconv"
 	"time"
@@ -19,6 +20,19 @@ type Client struct {
 	ttl         time.Duration
 }
 
+// ErrDisabled is returned by counter operations when Redis is not connected.
+var ErrDisabled = errors.New("redis cache is disabled")
+
+// incrWindowScript counts a hit and starts the window on the first one, so
+// the counter and its expiry are set atomically.
+var incrWindowScript = redis.NewScript(`
+local count = redis.call('INCR', KEYS[1])
+if redis.call('PTTL', KEYS[1]) < 0 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[1])
+end
+return {count, redis.call('PTTL', KEYS[1])}
+`)
+
 type ResponsePayload struct {
 	StatusCode  int    `json:"status_code"`
 	ContentType string `json:"content_type"`
@@ -139,3 +153,52 @@ func (c *Client) Stats(ctx context.Context) (map[string]string, error) {
 	}
 	return info, nil
 }
+
+// IncrWindow adds one to the counter at key, which expires window after its
+// first hit, and returns the count and the time left in the window.
+func (c *Client) IncrWindow(ctx context.Context, key string, window time.Duration) (int64, time.Duration, error) {
+	if !c.Enabled() {
+		return 0, 0, ErrDisabled
+	}
+	res, err := incrWindowScript.Run(ctx, c.redisClient, []string{key}, window.Milliseconds()).Int64Slice()
+	if err != nil {
+		return 0, 0, err
+	}
+	return res[0], time.Duration(res[1]) * time.Millisecond, nil
+}
+
+// GetCounter returns the counter at key and its time to live; a missing key
+// counts as zero.
+func (c *Client) GetCounter(ctx context.Context, key string) (int64, time.Duration, error) {
+	if !c.Enabled() {
+		return 0, 0, ErrDisabled
+	}
+	pipe := c.redisClient.Pipeline()
+	get := pipe.Get(ctx, key)
+	ttl := pipe.PTTL(ctx, key)
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return 0, 0, err
+	}
+	count, err := get.Int64()
+	if err == redis.Nil {
+		return 0, 0, nil
+	}
+	if err != nil {
+		return 0, 0, err
+	}
+	return count, max(ttl.Val(), 0), nil
+}
+
+func (c *Client) SetCounter(ctx context.Context, key string, value int64, ttl time.Duration) error {
+	if !c.Enabled() {
+		return ErrDisabled
+	}
+	return c.redisClient.Set(ctx, key, value, ttl).Err()
+}
+
+func (c *Client) Delete(ctx context.Context, key string) error {
+	if !c.Enabled() {
+		return ErrDisabled
+	}
+	return c.redisClient.Del(ctx, key).Err()
+}
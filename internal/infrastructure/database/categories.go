@@ -0,0 +1,32 @@
+package database
+
+import "fmt"
+
+// CategoryPathsCTE lists the user's categories with the ids of their
+// ancestors from the top level down, ending with the category itself.
+// userArg is the placeholder holding the user id. The query must start with
+// this CTE before using CategoryPathJoin and CategoryAtDepth.
+func CategoryPathsCTE(userArg string) string {
+	return fmt.Sprintf(`
+		WITH RECURSIVE category_paths AS (
+			SELECT category_id, ARRAY[category_id] AS path
+			FROM Category
+			WHERE user_id = %[1]s AND parent_id IS NULL
+			UNION ALL
+			SELECT c.category_id, cp.path || c.category_id
+			FROM Category c
+			JOIN category_paths cp ON c.parent_id = cp.category_id
+			WHERE c.user_id = %[1]s
+		)`, userArg)
+}
+
+func CategoryPathJoin(categoryExpr string) string {
+	return fmt.Sprintf(`
+		LEFT JOIN category_paths cp ON cp.category_id = %s`, categoryExpr)
+}
+
+// CategoryAtDepth rolls the category up to its ancestor at depth (1 is the
+// top level). Categories above that depth are kept as is.
+func CategoryAtDepth(categoryExpr string, depth int) string {
+	return fmt.Sprintf("COALESCE(cp.path[LEAST(%d, array_length(cp.path, 1))], %s)", depth, categoryExpr)
+}
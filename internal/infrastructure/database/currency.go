@@ -0,0 +1,89 @@
+package database
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// BaseCurrencyJoin joins the owner's base currency and the rate that converts
+// the transaction into it: 1 for the base currency itself, otherwise the
+// latest stored exchange rate on or before the transaction date. Rates are
+// looked up in both directions, so a single EUR/RUB row also converts RUB
+// into EUR. fx.rate is NULL when no rate is known.
+func BaseCurrencyJoin(alias string) string {
+	return fmt.Sprintf(`
+		JOIN Users fx_user ON fx_user.user_id = %[1]s.user_id
+		LEFT JOIN LATERAL (
+			SELECT 1::NUMERIC AS rate WHERE %[1]s.currency = fx_user.base_currency
+			UNION ALL
+			(SELECT CASE WHEN r.from_currency = %[1]s.currency THEN r.rate ELSE 1 / r.rate END AS rate
+			FROM ExchangeRates r
+			WHERE r.user_id = %[1]s.user_id
+			  AND %[1]s.currency <> fx_user.base_currency
+			  AND r.rate_date <= %[1]s.completed_at::date
+			  AND (
+				(r.from_currency = %[1]s.currency AND r.to_currency = fx_user.base_currency)
+				OR (r.from_currency = fx_user.base_currency AND r.to_currency = %[1]s.currency)
+			  )
+			ORDER BY r.rate_date DESC
+			LIMIT 1)
+		) fx ON true`, alias)
+}
+
+// BaseAmount converts amountExpr into the base currency using the rate joined
+// by BaseCurrencyJoin. Amounts without a known rate are NULL, so SUM leaves
+// them out instead of adding foreign money as if it were in base.
+func BaseAmount(amountExpr string) string {
+	return fmt.Sprintf("ROUND(%s * fx.rate)::BIGINT", amountExpr)
+}
+
+// UnconvertedCurrencies aggregates the currencies of the rows BaseAmount
+// left out into a comma-separated list, NULL when every row was converted.
+// Scan it with CurrencyList.
+func UnconvertedCurrencies(alias string) string {
+	return fmt.Sprintf("string_agg(DISTINCT %[1]s.currency, ',' ORDER BY %[1]s.currency) FILTER (WHERE fx.rate IS NULL)", alias)
+}
+
+// CurrencyList scans the column built by UnconvertedCurrencies.
+type CurrencyList []string
+
+func (l *CurrencyList) Scan(src any) error {
+	var raw string
+	switch v := src.(type) {
+	case nil:
+		*l = nil
+		return nil
+	case string:
+		raw = v
+	case []byte:
+		raw = string(v)
+	default:
+		return fmt.Errorf("cannot scan %T into CurrencyList", src)
+	}
+	if raw == "" {
+		*l = nil
+		return nil
+	}
+	*l = strings.Split(raw, ",")
+	return nil
+}
+
+// MergeCurrencies adds the currencies of more to list, keeping it sorted
+// and without duplicates.
+func MergeCurrencies(list []string, more ...[]string) []string {
+	seen := make(map[string]bool, len(list))
+	for _, c := range list {
+		seen[c] = true
+	}
+	for _, m := range more {
+		for _, c := range m {
+			if !seen[c] {
+				seen[c] = true
+				list = append(list, c)
+			}
+		}
+	}
+	sort.Strings(list)
+	return list
+}
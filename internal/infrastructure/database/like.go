@@ -0,0 +1,9 @@
+package database
+
+import "strings"
+
+// EscapeLike escapes the wildcards of a LIKE pattern, so s matches literally
+// in a pattern compared with ESCAPE '\'.
+func EscapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
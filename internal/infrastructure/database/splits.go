@@ -0,0 +1,19 @@
+package database
+
+import "fmt"
+
+// SplitJoin expands split transactions into one row per part. Transactions
+// without parts keep a single row. Use SplitCategory and SplitAmount instead
+// of the transaction's own category_id and amount.
+func SplitJoin(alias string) string {
+	return fmt.Sprintf(`
+		LEFT JOIN TransactionSplits sp ON sp.transaction_id = %s.transaction_id`, alias)
+}
+
+func SplitCategory(alias string) string {
+	return fmt.Sprintf("CASE WHEN sp.split_id IS NULL THEN %s.category_id ELSE sp.category_id END", alias)
+}
+
+func SplitAmount(alias string) string {
+	return fmt.Sprintf("COALESCE(sp.amount, %s.amount)", alias)
+}
@@ -0,0 +1,222 @@
+package jwtauth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"Finance-Manager-System/configs"
+)
+
+// SecretKeyID is the kid of the HS256 key made from configs.Config.JWTSecret.
+const SecretKeyID = "hs256"
+
+const minRSABits = 2048
+
+var (
+	ErrNoKeys            = errors.New("no JWT keys configured: set JWT_SECRET or jwt.keys")
+	ErrUnknownKey        = errors.New("token is signed with an unknown key")
+	ErrAlgorithmMismatch = errors.New("token algorithm does not match its key")
+)
+
+type Key struct {
+	ID        string
+	Method    jwt.SigningMethod
+	signKey   interface{}
+	verifyKey interface{}
+	// public is set for asymmetric keys, which are published in JWKS.
+	public crypto.PublicKey
+}
+
+func (k *Key) CanSign() bool {
+	return k.signKey != nil
+}
+
+// KeySet signs tokens with its signing key and verifies them with any of its
+// keys, picked by the kid header. The algorithm is pinned per key, so a token
+// cannot switch to "none" or to HS256 with a public key as the secret.
+type KeySet struct {
+	issuer   string
+	audience string
+	leeway   time.Duration
+	signing  *Key
+	keys     map[string]*Key
+	order    []string
+}
+
+func NewKeySet(cfg configs.JWTConfig, secret string) (*KeySet, error) {
+	ks := &KeySet{
+		issuer:   cfg.Issuer,
+		audience: cfg.Audience,
+		leeway:   time.Duration(cfg.LeewaySeconds) * time.Second,
+		keys:     make(map[string]*Key),
+	}
+
+	for _, keyCfg := range cfg.Keys {
+		key, err := loadKey(keyCfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load JWT key %q: %w", keyCfg.ID, err)
+		}
+		if err := ks.add(key); err != nil {
+			return nil, err
+		}
+	}
+	if secret != "" {
+		key := &Key{ID: SecretKeyID, Method: jwt.SigningMethodHS256, signKey: []byte(secret), verifyKey: []byte(secret)}
+		if err := ks.add(key); err != nil {
+			return nil, err
+		}
+	}
+	if len(ks.order) == 0 {
+		return nil, ErrNoKeys
+	}
+
+	signingID := cfg.SigningKeyID
+	if signingID == "" {
+		signingID = ks.order[0]
+	}
+	signing, ok := ks.keys[signingID]
+	if !ok {
+		return nil, fmt.Errorf("signing key %q is not configured", signingID)
+	}
+	if !signing.CanSign() {
+		return nil, fmt.Errorf("signing key %q has no private key", signingID)
+	}
+	ks.signing = signing
+
+	return ks, nil
+}
+
+// Sign adds the issuer, the audience and the kid header to the claims and
+// signs them with the signing key.
+func (ks *KeySet) Sign(claims jwt.MapClaims) (string, error) {
+	claims["iss"] = ks.issuer
+	claims["aud"] = ks.audience
+
+	token := jwt.NewWithClaims(ks.signing.Method, claims)
+	token.Header["kid"] = ks.signing.ID
+	return token.SignedString(ks.signing.signKey)
+}
+
+// Verify checks the signature, issuer and audience of a token and that it
+// has an expiry that has not passed and, when present, an nbf and iat that
+// have.
+func (ks *KeySet) Verify(tokenStr string) (jwt.MapClaims, error) {
+	methods := make([]string, 0, len(ks.order))
+	for _, id := range ks.order {
+		methods = append(methods, ks.keys[id].Method.Alg())
+	}
+	parser := jwt.NewParser(
+		jwt.WithValidMethods(methods),
+		jwt.WithIssuer(ks.issuer),
+		jwt.WithAudience(ks.audience),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(ks.leeway),
+	)
+
+	claims := jwt.MapClaims{}
+	_, err := parser.ParseWithClaims(tokenStr, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		key, ok := ks.keys[kid]
+		if !ok {
+			return nil, ErrUnknownKey
+		}
+		if token.Method.Alg() != key.Method.Alg() {
+			return nil, ErrAlgorithmMismatch
+		}
+		return key.verifyKey, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+func (ks *KeySet) add(key *Key) error {
+	if key.ID == "" {
+		return errors.New("JWT key id cannot be empty")
+	}
+	if _, ok := ks.keys[key.ID]; ok {
+		return fmt.Errorf("duplicate JWT key id %q", key.ID)
+	}
+	ks.keys[key.ID] = key
+	ks.order = append(ks.order, key.ID)
+	return nil
+}
+
+func loadKey(cfg configs.JWTKeyConfig) (*Key, error) {
+	if cfg.PrivateKeyFile == "" && cfg.PublicKeyFile == "" {
+		return nil, errors.New("private_key_file or public_key_file is required")
+	}
+	key := &Key{ID: cfg.ID}
+
+	switch cfg.Algorithm {
+	case jwt.SigningMethodRS256.Alg():
+		key.Method = jwt.SigningMethodRS256
+		if cfg.PrivateKeyFile != "" {
+			private, err := readPEM(cfg.PrivateKeyFile, jwt.ParseRSAPrivateKeyFromPEM)
+			if err != nil {
+				return nil, err
+			}
+			key.signKey = private
+			key.public = &private.PublicKey
+		} else {
+			public, err := readPEM(cfg.PublicKeyFile, jwt.ParseRSAPublicKeyFromPEM)
+			if err != nil {
+				return nil, err
+			}
+			key.public = public
+		}
+		if bits := key.public.(*rsa.PublicKey).N.BitLen(); bits < minRSABits {
+			return nil, fmt.Errorf("RSA key has %d bits, at least %d are required", bits, minRSABits)
+		}
+	case jwt.SigningMethodEdDSA.Alg():
+		key.Method = jwt.SigningMethodEdDSA
+		if cfg.PrivateKeyFile != "" {
+			private, err := readPEM(cfg.PrivateKeyFile, jwt.ParseEdPrivateKeyFromPEM)
+			if err != nil {
+				return nil, err
+			}
+			edPrivate, ok := private.(ed25519.PrivateKey)
+			if !ok {
+				return nil, errors.New("EdDSA private key is not Ed25519")
+			}
+			key.signKey = edPrivate
+			key.public = edPrivate.Public()
+		} else {
+			public, err := readPEM(cfg.PublicKeyFile, jwt.ParseEdPublicKeyFromPEM)
+			if err != nil {
+				return nil, err
+			}
+			key.public = public
+		}
+		if _, ok := key.public.(ed25519.PublicKey); !ok {
+			return nil, errors.New("EdDSA public key is not Ed25519")
+		}
+	default:
+		return nil, fmt.Errorf("unsupported algorithm %q, use RS256 or EdDSA", cfg.Algorithm)
+	}
+
+	key.verifyKey = key.public
+	return key, nil
+}
+
+func readPEM[T any](path string, parse func([]byte) (T, error)) (T, error) {
+	var zero T
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return zero, err
+	}
+	key, err := parse(data)
+	if err != nil {
+		return zero, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return key, nil
+}
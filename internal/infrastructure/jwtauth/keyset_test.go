@@ -0,0 +1,194 @@
+package jwtauth
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"Finance-Manager-System/configs"
+)
+
+func writePEM(t *testing.T, name string, blockType string, der []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600); err != nil {
+		t.Fatalf("failed to write %s: %v", name, err)
+	}
+	return path
+}
+
+type testKeys struct {
+	rsaKey     *rsa.PrivateKey
+	rsaPrivate string
+	edPrivate  string
+	edPublic   string
+}
+
+func newTestKeys(t *testing.T) testKeys {
+	t.Helper()
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate RSA key: %v", err)
+	}
+	edPublic, edPrivate, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate Ed25519 key: %v", err)
+	}
+	edPrivateDER, _ := x509.MarshalPKCS8PrivateKey(edPrivate)
+	edPublicDER, _ := x509.MarshalPKIXPublicKey(edPublic)
+	return testKeys{
+		rsaKey:     rsaKey,
+		rsaPrivate: writePEM(t, "rsa.pem", "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(rsaKey)),
+		edPrivate:  writePEM(t, "ed.pem", "PRIVATE KEY", edPrivateDER),
+		edPublic:   writePEM(t, "ed.pub.pem", "PUBLIC KEY", edPublicDER),
+	}
+}
+
+func jwtConfig(signingKeyID string, keys ...configs.JWTKeyConfig) configs.JWTConfig {
+	return configs.JWTConfig{Issuer: "finance-manager", Audience: "finance-manager-api", SigningKeyID: signingKeyID, Keys: keys}
+}
+
+func validClaims() jwt.MapClaims {
+	now := time.Now()
+	return jwt.MapClaims{"user_id": "u1", "iat": now.Unix(), "exp": now.Add(time.Minute).Unix()}
+}
+
+func TestKeySetRotation(t *testing.T) {
+	keys := newTestKeys(t)
+	edKey := configs.JWTKeyConfig{ID: "2026-01", Algorithm: "EdDSA", PrivateKeyFile: keys.edPrivate}
+	rsaKey := configs.JWTKeyConfig{ID: "2025-07", Algorithm: "RS256", PrivateKeyFile: keys.rsaPrivate}
+
+	current, err := NewKeySet(jwtConfig("2026-01", edKey, rsaKey), "secret")
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	previous, err := NewKeySet(jwtConfig("2025-07", rsaKey), "")
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+
+	token, err := current.Sign(validClaims())
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	parsed, _, _ := jwt.NewParser().ParseUnverified(token, jwt.MapClaims{})
+	if parsed.Header["kid"] != "2026-01" || parsed.Method.Alg() != "EdDSA" {
+		t.Fatalf("unexpected token header: %v", parsed.Header)
+	}
+	claims, err := current.Verify(token)
+	if err != nil || claims["user_id"] != "u1" || claims["iss"] != "finance-manager" {
+		t.Fatalf("expected verified claims, got %v, %v", claims, err)
+	}
+
+	oldToken, err := previous.Sign(validClaims())
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if _, err := current.Verify(oldToken); err != nil {
+		t.Fatalf("tokens of a retired key must verify until they expire, got %v", err)
+	}
+	if _, err := previous.Verify(token); err == nil {
+		t.Fatalf("a key set without the new key must reject its tokens")
+	}
+
+	// A verify-only key is enough to check tokens but cannot sign them.
+	verifier, err := NewKeySet(jwtConfig("", configs.JWTKeyConfig{ID: "2026-01", Algorithm: "EdDSA", PublicKeyFile: keys.edPublic}, rsaKey), "")
+	if err == nil {
+		t.Fatalf("expected an error for a signing key without a private key, got %v", verifier)
+	}
+	verifier, err = NewKeySet(jwtConfig("2025-07", configs.JWTKeyConfig{ID: "2026-01", Algorithm: "EdDSA", PublicKeyFile: keys.edPublic}, rsaKey), "")
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if _, err := verifier.Verify(token); err != nil {
+		t.Fatalf("public key should verify the token, got %v", err)
+	}
+
+	jwks := current.JWKS()
+	if len(jwks.Keys) != 2 {
+		t.Fatalf("expected two public keys and no HS256 secret, got %+v", jwks.Keys)
+	}
+	if jwks.Keys[0].KeyType != "OKP" || jwks.Keys[0].Curve != "Ed25519" || jwks.Keys[1].KeyType != "RSA" || jwks.Keys[1].E != "AQAB" {
+		t.Fatalf("unexpected JWKS: %+v", jwks.Keys)
+	}
+}
+
+func TestKeySetRejectsTokens(t *testing.T) {
+	keys := newTestKeys(t)
+	ks, err := NewKeySet(jwtConfig("", configs.JWTKeyConfig{ID: "rsa", Algorithm: "RS256", PrivateKeyFile: keys.rsaPrivate}), "secret")
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+
+	sign := func(method jwt.SigningMethod, kid string, key interface{}, claims jwt.MapClaims) string {
+		token := jwt.NewWithClaims(method, claims)
+		if kid != "" {
+			token.Header["kid"] = kid
+		}
+		signed, err := token.SignedString(key)
+		if err != nil {
+			t.Fatalf("failed to sign test token: %v", err)
+		}
+		return signed
+	}
+	withRegistered := func(change func(jwt.MapClaims)) jwt.MapClaims {
+		claims := validClaims()
+		claims["iss"] = "finance-manager"
+		claims["aud"] = "finance-manager-api"
+		change(claims)
+		return claims
+	}
+	publicDER, _ := x509.MarshalPKIXPublicKey(&keys.rsaKey.PublicKey)
+	publicPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})
+	now := time.Now()
+
+	if _, err := ks.Verify(sign(jwt.SigningMethodRS256, "rsa", keys.rsaKey, withRegistered(func(jwt.MapClaims) {}))); err != nil {
+		t.Fatalf("expected a valid token, got %v", err)
+	}
+
+	unknown := sign(jwt.SigningMethodRS256, "retired", keys.rsaKey, withRegistered(func(jwt.MapClaims) {}))
+	if _, err := ks.Verify(unknown); !errors.Is(err, ErrUnknownKey) {
+		t.Fatalf("expected ErrUnknownKey, got %v", err)
+	}
+	if _, err := ks.Verify(sign(jwt.SigningMethodHS256, "rsa", []byte("secret"), withRegistered(func(jwt.MapClaims) {}))); !errors.Is(err, ErrAlgorithmMismatch) {
+		t.Fatalf("expected ErrAlgorithmMismatch, got %v", err)
+	}
+
+	cases := map[string]string{
+		"alg none":           sign(jwt.SigningMethodNone, "rsa", jwt.UnsafeAllowNoneSignatureType, withRegistered(func(jwt.MapClaims) {})),
+		"public key as hmac": sign(jwt.SigningMethodHS256, "rsa", publicPEM, withRegistered(func(jwt.MapClaims) {})),
+		"hmac with rsa kid":  sign(jwt.SigningMethodHS256, "rsa", []byte("secret"), withRegistered(func(jwt.MapClaims) {})),
+		"missing kid":        sign(jwt.SigningMethodHS256, "", []byte("secret"), withRegistered(func(jwt.MapClaims) {})),
+		"wrong issuer":       sign(jwt.SigningMethodRS256, "rsa", keys.rsaKey, withRegistered(func(c jwt.MapClaims) { c["iss"] = "someone-else" })),
+		"wrong audience":     sign(jwt.SigningMethodRS256, "rsa", keys.rsaKey, withRegistered(func(c jwt.MapClaims) { c["aud"] = "other-api" })),
+		"expired":            sign(jwt.SigningMethodRS256, "rsa", keys.rsaKey, withRegistered(func(c jwt.MapClaims) { c["exp"] = now.Add(-time.Hour).Unix() })),
+		"no expiry":          sign(jwt.SigningMethodRS256, "rsa", keys.rsaKey, withRegistered(func(c jwt.MapClaims) { delete(c, "exp") })),
+		"not yet valid":      sign(jwt.SigningMethodRS256, "rsa", keys.rsaKey, withRegistered(func(c jwt.MapClaims) { c["nbf"] = now.Add(time.Hour).Unix() })),
+	}
+	for name, token := range cases {
+		if _, err := ks.Verify(token); err == nil {
+			t.Fatalf("%s: expected the token to be rejected", name)
+		}
+	}
+
+	if _, err := NewKeySet(jwtConfig(""), ""); !errors.Is(err, ErrNoKeys) {
+		t.Fatalf("expected ErrNoKeys, got %v", err)
+	}
+	if _, err := NewKeySet(jwtConfig("", configs.JWTKeyConfig{ID: "x", Algorithm: "HS512", PrivateKeyFile: keys.rsaPrivate}), ""); err == nil {
+		t.Fatalf("expected unsupported algorithm to be rejected")
+	}
+	small, _ := rsa.GenerateKey(rand.Reader, 1024)
+	smallPath := writePEM(t, "small.pem", "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(small))
+	if _, err := NewKeySet(jwtConfig("", configs.JWTKeyConfig{ID: "small", Algorithm: "RS256", PrivateKeyFile: smallPath}), ""); err == nil {
+		t.Fatalf("expected a 1024-bit RSA key to be rejected")
+	}
+}
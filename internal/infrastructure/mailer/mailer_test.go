@@ -0,0 +1,141 @@
+package mailer
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var testFrom = &mail.Address{Name: "Finance Manager", Address: "no-reply@example.com"}
+
+// fakeSMTP accepts one message without TLS or auth and records the
+// envelope and data it got.
+type fakeSMTP struct {
+	listener net.Listener
+	from     string
+	to       string
+	data     string
+	done     chan struct{}
+}
+
+func startFakeSMTP(t *testing.T) *fakeSMTP {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	s := &fakeSMTP{listener: listener, done: make(chan struct{})}
+	go s.serve()
+	t.Cleanup(func() { listener.Close() })
+	return s
+}
+
+func (s *fakeSMTP) serve() {
+	defer close(s.done)
+	conn, err := s.listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	r := bufio.NewReader(conn)
+	reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+	reply("220 localhost ESMTP")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		cmd := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+			reply("250 localhost")
+		case strings.HasPrefix(cmd, "MAIL FROM:"):
+			s.from = strings.Trim(strings.TrimPrefix(cmd, "MAIL FROM:"), "<> ")
+			reply("250 OK")
+		case strings.HasPrefix(cmd, "RCPT TO:"):
+			s.to = strings.Trim(strings.TrimPrefix(cmd, "RCPT TO:"), "<> ")
+			reply("250 OK")
+		case cmd == "DATA":
+			reply("354 Go ahead")
+			var data strings.Builder
+			for {
+				line, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if line == ".\r\n" {
+					break
+				}
+				data.WriteString(line)
+			}
+			s.data = data.String()
+			reply("250 Queued")
+		case cmd == "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("502 Not implemented")
+		}
+	}
+}
+
+func TestSMTPMailer(t *testing.T) {
+	server := startFakeSMTP(t)
+	host, port, _ := net.SplitHostPort(server.listener.Addr().String())
+
+	m := NewSMTPMailer(host, port, "", "", testFrom)
+	err := m.Send(context.Background(), Message{
+		To:      "alice@example.com",
+		Subject: "Сброс пароля",
+		Body:    "Ссылка:\n.https://example.com/reset\n",
+	})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	<-server.done
+
+	if server.from != "no-reply@example.com" || server.to != "alice@example.com" {
+		t.Fatalf("unexpected envelope: from %q to %q", server.from, server.to)
+	}
+	if !strings.Contains(server.data, "Subject: =?utf-8?q?") {
+		t.Fatalf("a non-ASCII subject should be encoded, got:\n%s", server.data)
+	}
+	if !strings.Contains(server.data, "\r\n..https://example.com/reset\r\n") {
+		t.Fatalf("a leading dot in the body should be escaped, got:\n%s", server.data)
+	}
+}
+
+func TestFileMailer(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "mail")
+	m := NewFileMailer(dir, testFrom)
+
+	if err := m.Send(context.Background(), Message{To: "alice@example.com", Subject: "Hi", Body: "line one\nline two"}); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	files, _ := os.ReadDir(dir)
+	if len(files) != 1 || filepath.Ext(files[0].Name()) != ".eml" {
+		t.Fatalf("expected one .eml file, got %v", files)
+	}
+
+	raw, _ := os.ReadFile(filepath.Join(dir, files[0].Name()))
+	parsed, err := mail.ReadMessage(strings.NewReader(string(raw)))
+	if err != nil {
+		t.Fatalf("expected a valid message, got %v", err)
+	}
+	if parsed.Header.Get("To") != "<alice@example.com>" || parsed.Header.Get("Subject") != "Hi" {
+		t.Fatalf("unexpected headers: %v", parsed.Header)
+	}
+	if !strings.Contains(string(raw), "line one\r\nline two\r\n") {
+		t.Fatalf("body lines should end with CRLF, got %q", raw)
+	}
+
+	err = m.Send(context.Background(), Message{To: "alice@example.com", Subject: "Hi\r\nBcc: eve@example.com", Body: "x"})
+	if err != ErrInvalidHeader {
+		t.Fatalf("expected ErrInvalidHeader, got %v", err)
+	}
+}
@@ -13,22 +13,40 @@ import (
 
 type contextKey string
 
-const UserIDKey contextKey = "user_id"
+const (
+	UserIDKey    contextKey = "user_id"
+	SessionIDKey contextKey = "session_id"
+)
 
-var jwtSecretKey []byte
+// accessTokenType is the "typ" claim of access tokens; other tokens signed
+// with the same keys, such as 2FA login challenges, are not accepted.
+const accessTokenType = "access"
 
-func SetJWTSecret(secret string) {
-	jwtSecretKey = []byte(secret)
+// TokenVerifier checks the signature and registered claims of an access
+// token and returns its claims.
+type TokenVerifier interface {
+	Verify(tokenStr string) (jwt.MapClaims, error)
 }
 
-func RequireAuth(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if len(jwtSecretKey) == 0 {
-			zap.L().Error("auth_jwt_secret_not_configured")
-			http.Error(w, "JWT secret is not configured", http.StatusInternalServerError)
-			return
-		}
+// SessionChecker reports whether the session an access token was issued
+// for is still signed in.
+type SessionChecker interface {
+	IsSessionActive(ctx context.Context, userID uuid.UUID, sessionID uuid.UUID) (bool, error)
+}
+
+type Authenticator struct {
+	verifier TokenVerifier
+	sessions SessionChecker
+}
+
+// NewAuthenticator builds the RequireAuth middleware. With a session checker
+// tokens without a session and tokens of revoked sessions are rejected.
+func NewAuthenticator(verifier TokenVerifier, sessions SessionChecker) *Authenticator {
+	return &Authenticator{verifier: verifier, sessions: sessions}
+}
 
+func (a *Authenticator) RequireAuth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		authHeader := r.Header.Get("Authorization")
 		if authHeader == "" {
 			zap.L().Warn("auth_missing_authorization_header")
@@ -43,22 +61,16 @@ func RequireAuth(next http.Handler) http.Handler {
 			return
 		}
 
-		tokenStr := parts[1]
-
-		token, err := jwt.Parse(tokenStr, func(token *jwt.Token) (interface{}, error) {
-			return jwtSecretKey, nil
-		})
-
-		if err != nil || !token.Valid {
+		claims, err := a.verifier.Verify(parts[1])
+		if err != nil {
 			zap.L().Warn("auth_invalid_or_expired_token", zap.Error(err))
 			http.Error(w, "Invalid or expired token", http.StatusUnauthorized)
 			return
 		}
 
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok {
-			zap.L().Warn("auth_invalid_token_claims")
-			http.Error(w, "Invalid token claims", http.StatusUnauthorized)
+		if typ, _ := claims["typ"].(string); typ != accessTokenType {
+			zap.L().Warn("auth_not_an_access_token", zap.String("typ", typ))
+			http.Error(w, "Invalid or expired token", http.StatusUnauthorized)
 			return
 		}
 
@@ -77,6 +89,30 @@ func RequireAuth(next http.Handler) http.Handler {
 		}
 
 		ctx := context.WithValue(r.Context(), UserIDKey, userID)
+
+		if a.sessions != nil {
+			sessionIDStr, _ := claims["sid"].(string)
+			sessionID, err := uuid.Parse(sessionIDStr)
+			if err != nil {
+				zap.L().Warn("auth_session_id_invalid", zap.String("sid", sessionIDStr), zap.Error(err))
+				http.Error(w, "Invalid or expired token", http.StatusUnauthorized)
+				return
+			}
+
+			active, err := a.sessions.IsSessionActive(r.Context(), userID, sessionID)
+			if err != nil {
+				zap.L().Error("auth_session_check_failed", zap.Error(err))
+				http.Error(w, "Internal server error", http.StatusInternalServerError)
+				return
+			}
+			if !active {
+				zap.L().Warn("auth_session_revoked", zap.String("session_id", sessionID.String()))
+				http.Error(w, "Session has been revoked", http.StatusUnauthorized)
+				return
+			}
+			ctx = context.WithValue(ctx, SessionIDKey, sessionID)
+		}
+
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
@@ -88,3 +124,11 @@ func GetUserID(ctx context.Context) (uuid.UUID, error) {
 	}
 	return id, nil
 }
+
+func GetSessionID(ctx context.Context) (uuid.UUID, error) {
+	id, ok := ctx.Value(SessionIDKey).(uuid.UUID)
+	if !ok {
+		return uuid.Nil, errors.New("session ID not found in context")
+	}
+	return id, nil
+}
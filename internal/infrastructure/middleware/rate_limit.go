@@ -0,0 +1,96 @@
+package middleware
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"Finance-Manager-System/internal/infrastructure/ratelimit"
+
+	"go.uber.org/zap"
+)
+
+// RateKeyFunc picks the key a request is counted under; an empty key skips
+// the limit for that request.
+type RateKeyFunc func(r *http.Request) string
+
+// RateLimit allows rule.Limit requests per window for each key in the named
+// bucket and answers 429 with Retry-After when the limit is used up. When
+// the counter store fails the request is let through.
+func RateLimit(limiter *ratelimit.Limiter, name string, rule ratelimit.Rule, key RateKeyFunc) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if limiter == nil || !rule.Enabled() {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			k := key(r)
+			if k == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			res, err := limiter.Allow(r.Context(), name, k, rule)
+			if err != nil {
+				zap.L().Warn("rate_limit_check_failed", zap.String("bucket", name), zap.Error(err))
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Set("X-RateLimit-Limit", strconv.FormatInt(res.Limit, 10))
+			w.Header().Set("X-RateLimit-Remaining", strconv.FormatInt(res.Remaining, 10))
+			if !res.Allowed {
+				zap.L().Warn("rate_limit_exceeded", zap.String("bucket", name), zap.String("key", k))
+				WriteRetryAfter(w, res.RetryAfter)
+				http.Error(w, "Too many requests", http.StatusTooManyRequests)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// WriteRetryAfter sets the Retry-After header in whole seconds, rounded up.
+func WriteRetryAfter(w http.ResponseWriter, d time.Duration) {
+	seconds := int64(math.Ceil(d.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	w.Header().Set("Retry-After", strconv.FormatInt(seconds, 10))
+}
+
+// ClientIP is the address of the client; behind a trusted proxy it relies on
+// RealIP having rewritten RemoteAddr.
+func ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+func ClientIPKey(r *http.Request) string {
+	return ClientIP(r)
+}
+
+// UserKey counts requests of the signed-in user, so it is used after
+// RequireAuth.
+func UserKey(r *http.Request) string {
+	userID, err := GetUserID(r.Context())
+	if err != nil {
+		return ""
+	}
+	return userID.String()
+}
+
+// UploadKey counts file uploads of the signed-in user, leaving other
+// requests alone.
+func UploadKey(r *http.Request) string {
+	if !strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		return ""
+	}
+	return UserKey(r)
+}
@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"Finance-Manager-System/internal/infrastructure/ratelimit"
+)
+
+func TestSpoofedForwardedForKeepsRateLimitBucket(t *testing.T) {
+	trusted, err := ParseTrustedProxies([]string{"10.0.0.0/8", "192.168.1.1"})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	limit := RateLimit(ratelimit.NewLimiter(ratelimit.NewMemoryStore()), "ip", ratelimit.Rule{Limit: 1, Window: time.Minute}, ClientIPKey)
+	var seen string
+	handler := RealIP(trusted)(limit(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = ClientIP(r)
+	})))
+
+	send := func(remoteAddr string, headers map[string]string) int {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = remoteAddr
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	if code := send("203.0.113.7:5000", map[string]string{"X-Forwarded-For": "198.51.100.1"}); code != http.StatusOK || seen != "203.0.113.7" {
+		t.Fatalf("untrusted peer must be known by its socket address, got %d %q", code, seen)
+	}
+	if code := send("203.0.113.7:5001", map[string]string{"X-Forwarded-For": "198.51.100.2"}); code != http.StatusTooManyRequests {
+		t.Fatalf("spoofed X-Forwarded-For must not get a new bucket, got %d", code)
+	}
+	if code := send("203.0.113.7:5002", map[string]string{"X-Real-IP": "198.51.100.3"}); code != http.StatusTooManyRequests {
+		t.Fatalf("spoofed X-Real-IP must not get a new bucket, got %d", code)
+	}
+
+	if code := send("10.1.2.3:5000", map[string]string{"X-Forwarded-For": "198.51.100.9, 198.51.100.4, 192.168.1.1"}); code != http.StatusOK || seen != "198.51.100.4" {
+		t.Fatalf("trusted proxy must name the client, got %d %q", code, seen)
+	}
+	if code := send("10.1.2.3:5001", map[string]string{"X-Forwarded-For": "198.51.100.10, 198.51.100.4"}); code != http.StatusTooManyRequests {
+		t.Fatalf("entries left of the client must be ignored, got %d", code)
+	}
+	if code := send("10.1.2.3:5002", map[string]string{"X-Real-IP": "198.51.100.5"}); code != http.StatusOK || seen != "198.51.100.5" {
+		t.Fatalf("trusted proxy must be able to use X-Real-IP, got %d %q", code, seen)
+	}
+}
+
+func TestParseTrustedProxiesRejectsGarbage(t *testing.T) {
+	if _, err := ParseTrustedProxies([]string{"not-an-ip"}); err == nil {
+		t.Fatalf("expected an error for an invalid proxy")
+	}
+}
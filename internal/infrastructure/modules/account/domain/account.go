@@ -16,21 +16,80 @@ var (
 	ErrEmptyAccountType  = errors.New("account type cannot be empty")
 	ErrInvalidColorHex   = errors.New("color must be a valid hex code (e.g., #FFFFFF)")
 	ErrMissingExternalID = errors.New("external account ID is required for imported accounts")
+	ErrInvalidKind       = errors.New("account kind must be asset or liability")
+	ErrLiabilityBalance  = errors.New("liability balance cannot be positive, enter the amount owed as a negative balance")
 )
 
+// MaxAccountNameLength is the longest account name, in characters.
+const MaxAccountNameLength = 50
+
+// AccountKind tells whether an account counts towards assets or liabilities
+// in net worth. Balances are signed either way: a liability is negative while
+// money is owed on it.
+type AccountKind string
+
+const (
+	KindAsset     AccountKind = "asset"
+	KindLiability AccountKind = "liability"
+)
+
+// liabilityTypes are the account types that are debts unless the kind is set
+// explicitly.
+var liabilityTypes = map[string]bool{
+	"CREDIT":      true,
+	"CREDIT_CARD": true,
+	"LOAN":        true,
+	"MORTGAGE":    true,
+}
+
+// KindForType returns the default kind of an account type.
+func KindForType(accountType string) AccountKind {
+	if liabilityTypes[strings.ToUpper(strings.TrimSpace(accountType))] {
+		return KindLiability
+	}
+	return KindAsset
+}
+
+// ParseAccountKind validates kind. An empty kind falls back to the default of
+// the account type.
+func ParseAccountKind(kind string, accountType string) (AccountKind, error) {
+	switch AccountKind(strings.ToLower(strings.TrimSpace(kind))) {
+	case "":
+		return KindForType(accountType), nil
+	case KindAsset:
+		return KindAsset, nil
+	case KindLiability:
+		return KindLiability, nil
+	}
+	return "", ErrInvalidKind
+}
+
+// CheckBalance validates a balance entered by the user for an account of
+// kind k. Money owed on a liability is a negative balance, so a positive one
+// would count the debt as savings.
+func (k AccountKind) CheckBalance(balance int64) error {
+	if k == KindLiability && balance > 0 {
+		return ErrLiabilityBalance
+	}
+	return nil
+}
+
 type Account struct {
-	AccountID         uuid.UUID  `db:"account_id" json:"account_id"`
-	UserID            uuid.UUID  `db:"user_id" json:"user_id"`
-	Balance           int64      `db:"balance" json:"balance"`
-	IsImported        bool       `db:"is_imported" json:"is_imported"`
-	ExternalAccountID *string    `db:"external_account_id" json:"external_account_id,omitempty"`
-	AccountType       string     `db:"account_type" json:"account_type"`
-	ColorHex          string     `db:"color_hex" json:"color_hex"`
-	IsArchived        bool       `db:"is_archived" json:"is_archived"`
-	NameAccount       string     `db:"name_account" json:"name_account"`
-	Currency          string     `db:"currency" json:"currency"`
-	LastSyncedAt      *time.Time `db:"last_synced_at" json:"last_synced_at,omitempty"`
-	CreatedAt         time.Time  `db:"created_at" json:"created_at"`
+	AccountID         uuid.UUID   `db:"account_id" json:"account_id"`
+	UserID            uuid.UUID   `db:"user_id" json:"user_id"`
+	Balance           int64       `db:"balance" json:"balance"`
+	OpeningBalance    int64       `db:"opening_balance" json:"opening_balance"`
+	IsImported        bool        `db:"is_imported" json:"is_imported"`
+	ExternalAccountID *string     `db:"external_account_id" json:"external_account_id,omitempty"`
+	AccountType       string      `db:"account_type" json:"account_type"`
+	Kind              AccountKind `db:"kind" json:"kind"`
+	ColorHex          string      `db:"color_hex" json:"color_hex"`
+	IsArchived        bool        `db:"is_archived" json:"is_archived"`
+	ArchivedAt        *time.Time  `db:"archived_at" json:"archived_at,omitempty"`
+	NameAccount       string      `db:"name_account" json:"name_account"`
+	Currency          string      `db:"currency" json:"currency"`
+	LastSyncedAt      *time.Time  `db:"last_synced_at" json:"last_synced_at,omitempty"`
+	CreatedAt         time.Time   `db:"created_at" json:"created_at"`
 }
 
 func NewAccount(
@@ -52,7 +111,7 @@ func NewAccount(
 	if name == "" {
 		return nil, ErrEmptyAccountName
 	}
-	if len([]rune(name)) > 50 {
+	if len([]rune(name)) > MaxAccountNameLength {
 		return nil, ErrAccountNameLong
 	}
 
@@ -87,9 +146,11 @@ func NewAccount(
 		AccountID:         uuid.Nil,
 		UserID:            userID,
 		Balance:           initialBalance,
+		OpeningBalance:    initialBalance,
 		IsImported:        isImported,
 		ExternalAccountID: externalAccountID,
 		AccountType:       accountType,
+		Kind:              KindForType(accountType),
 		ColorHex:          colorHex,
 		IsArchived:        false,
 		NameAccount:       name,
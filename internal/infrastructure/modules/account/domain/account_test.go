@@ -40,3 +40,22 @@ func TestNewAccountImportedRequiresExternalID(t *testing.T) {
 	}
 }
 
+
+func TestAccountKind(t *testing.T) {
+	acc, err := NewAccount(uuid.New(), "Card", "RUB", "credit_card", "", false, nil, -2500)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if acc.Kind != KindLiability {
+		t.Fatalf("credit card must be a liability, got %s", acc.Kind)
+	}
+	if kind, err := ParseAccountKind("", "deposit"); err != nil || kind != KindAsset {
+		t.Fatalf("deposit must default to asset, got %s %v", kind, err)
+	}
+	if kind, err := ParseAccountKind(" Liability ", "manual"); err != nil || kind != KindLiability {
+		t.Fatalf("explicit kind must win, got %s %v", kind, err)
+	}
+	if _, err := ParseAccountKind("equity", "manual"); err != ErrInvalidKind {
+		t.Fatalf("expected ErrInvalidKind, got %v", err)
+	}
+}
@@ -0,0 +1,148 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	DefaultHistoryDays = 90
+	MaxHistoryDays     = 731
+)
+
+var (
+	ErrInvalidHistoryRange = errors.New("from must not be after to, and the range cannot be longer than 731 days")
+	ErrInvalidReconcileRef = errors.New("reference must be stored or statement")
+	ErrNoStatementSnapshot = errors.New("account has no statement balance to reconcile against")
+	ErrImportedValuation   = errors.New("imported account balance comes from its statements")
+	ErrFutureValuation     = errors.New("valuation date cannot be in the future")
+)
+
+type SnapshotSource string
+
+const (
+	// SnapshotStatement is the closing balance reported by an imported
+	// statement.
+	SnapshotStatement SnapshotSource = "statement"
+	// SnapshotManual is a balance the user set on a manual account.
+	SnapshotManual SnapshotSource = "manual"
+	// SnapshotReconciliation is the balance confirmed by posting an
+	// adjusting entry.
+	SnapshotReconciliation SnapshotSource = "reconciliation"
+	// SnapshotValuation is a dated market value of an asset or the amount
+	// owed on a liability as a negative balance, entered by the user.
+	SnapshotValuation SnapshotSource = "valuation"
+)
+
+type BalanceSnapshot struct {
+	SnapshotID uuid.UUID      `db:"snapshot_id" json:"snapshot_id"`
+	UserID     uuid.UUID      `db:"user_id" json:"-"`
+	AccountID  uuid.UUID      `db:"account_id" json:"account_id"`
+	BatchID    *uuid.UUID     `db:"batch_id" json:"batch_id,omitempty"`
+	Source     SnapshotSource `db:"source" json:"source"`
+	Balance    int64          `db:"balance" json:"balance"`
+	TakenAt    time.Time      `db:"taken_at" json:"taken_at"`
+	CreatedAt  time.Time      `db:"created_at" json:"created_at"`
+}
+
+func NewBalanceSnapshot(userID, accountID uuid.UUID, source SnapshotSource, balance int64, takenAt time.Time) *BalanceSnapshot {
+	return &BalanceSnapshot{
+		SnapshotID: uuid.New(),
+		UserID:     userID,
+		AccountID:  accountID,
+		Source:     source,
+		Balance:    balance,
+		TakenAt:    takenAt.UTC(),
+		CreatedAt:  time.Now().UTC(),
+	}
+}
+
+type DailyBalance struct {
+	Date    time.Time `json:"date"`
+	Balance int64     `json:"balance"`
+}
+
+// BalanceHistory is the end-of-day balance implied by the ledger together
+// with the balances recorded over the same period.
+type BalanceHistory struct {
+	AccountID     uuid.UUID         `json:"account_id"`
+	Currency      string            `json:"currency"`
+	From          time.Time         `json:"from"`
+	To            time.Time         `json:"to"`
+	StoredBalance int64             `json:"stored_balance"`
+	Points        []DailyBalance    `json:"points"`
+	Snapshots     []BalanceSnapshot `json:"snapshots"`
+}
+
+// HistoryRange normalises the requested period to whole UTC days. Missing
+// bounds default to the last DefaultHistoryDays days up to now.
+func HistoryRange(from, to *time.Time, now time.Time) (time.Time, time.Time, error) {
+	end := startOfDay(now)
+	if to != nil {
+		end = startOfDay(*to)
+	}
+	start := end.AddDate(0, 0, -(DefaultHistoryDays - 1))
+	if from != nil {
+		start = startOfDay(*from)
+	}
+	if start.After(end) || end.Sub(start) >= MaxHistoryDays*24*time.Hour {
+		return time.Time{}, time.Time{}, ErrInvalidHistoryRange
+	}
+	return start, end, nil
+}
+
+// BuildDailyBalances walks the days from start to end. opening is the balance
+// before start; flows maps a UTC day to the net change of that day.
+func BuildDailyBalances(opening int64, flows map[time.Time]int64, start, end time.Time) []DailyBalance {
+	points := make([]DailyBalance, 0, int(end.Sub(start).Hours()/24)+1)
+	balance := opening
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		balance += flows[day]
+		points = append(points, DailyBalance{Date: day, Balance: balance})
+	}
+	return points
+}
+
+type ReconcileReference string
+
+const (
+	// ReconcileStored makes the ledger match the stored account balance.
+	ReconcileStored ReconcileReference = "stored"
+	// ReconcileStatement makes the ledger match the latest statement
+	// balance on the statement date.
+	ReconcileStatement ReconcileReference = "statement"
+)
+
+type ReconcileParams struct {
+	Reference      ReconcileReference
+	PostAdjustment bool
+}
+
+// StatementCheck compares the latest statement balance with the ledger on
+// the statement date.
+type StatementCheck struct {
+	Balance         int64     `json:"balance"`
+	TakenAt         time.Time `json:"taken_at"`
+	ComputedBalance int64     `json:"computed_balance"`
+	Drift           int64     `json:"drift"`
+}
+
+type Reconciliation struct {
+	AccountID uuid.UUID `json:"account_id"`
+	// ComputedBalance is the opening balance plus the visible transactions.
+	ComputedBalance int64           `json:"computed_balance"`
+	StoredBalance   int64           `json:"stored_balance"`
+	Drift           int64           `json:"drift"`
+	Statement       *StatementCheck `json:"statement,omitempty"`
+	// AdjustmentTransactionID is the adjusting entry posted to close the
+	// drift, if one was requested.
+	AdjustmentTransactionID *uuid.UUID `json:"adjustment_transaction_id,omitempty"`
+	AdjustmentAmount        int64      `json:"adjustment_amount,omitempty"`
+}
+
+func startOfDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
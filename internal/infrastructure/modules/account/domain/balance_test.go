@@ -0,0 +1,45 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestHistoryRangeDefaultsAndLimits(t *testing.T) {
+	now := time.Date(2026, 5, 20, 15, 30, 0, 0, time.UTC)
+	start, end, err := HistoryRange(nil, nil, now)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if !end.Equal(time.Date(2026, 5, 20, 0, 0, 0, 0, time.UTC)) || !start.Equal(end.AddDate(0, 0, -(DefaultHistoryDays-1))) {
+		t.Fatalf("unexpected default range: %v %v", start, end)
+	}
+
+	from := now.AddDate(0, 0, 1)
+	if _, _, err := HistoryRange(&from, nil, now); !errors.Is(err, ErrInvalidHistoryRange) {
+		t.Fatalf("expected ErrInvalidHistoryRange, got %v", err)
+	}
+	from = now.AddDate(0, 0, -MaxHistoryDays)
+	if _, _, err := HistoryRange(&from, nil, now); !errors.Is(err, ErrInvalidHistoryRange) {
+		t.Fatalf("range longer than %d days must be rejected, got %v", MaxHistoryDays, err)
+	}
+}
+
+func TestBuildDailyBalances(t *testing.T) {
+	start := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	flows := map[time.Time]int64{
+		start:                  -500,
+		start.AddDate(0, 0, 2): 2000,
+	}
+	points := BuildDailyBalances(10000, flows, start, start.AddDate(0, 0, 3))
+	want := []int64{9500, 9500, 11500, 11500}
+	if len(points) != len(want) {
+		t.Fatalf("expected %d points, got %d", len(want), len(points))
+	}
+	for i, point := range points {
+		if !point.Date.Equal(start.AddDate(0, 0, i)) || point.Balance != want[i] {
+			t.Fatalf("point %d: unexpected %+v", i, point)
+		}
+	}
+}
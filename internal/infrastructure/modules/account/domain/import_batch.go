@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrImportBatchNotFound  = errors.New("import batch not found")
+	ErrImportBatchNotLatest = errors.New("only the latest import batch can be undone")
+)
+
+// ImportBatch records one statement upload booked on an account. The balance
+// and sync time before the upload are kept so the batch can be undone.
+type ImportBatch struct {
+	BatchID          uuid.UUID  `db:"batch_id" json:"batch_id"`
+	UserID           uuid.UUID  `db:"user_id" json:"user_id"`
+	AccountID        uuid.UUID  `db:"account_id" json:"account_id"`
+	Format           string     `db:"format" json:"format"`
+	FileName         string     `db:"file_name" json:"file_name"`
+	FileHash         string     `db:"file_hash" json:"file_hash"`
+	ImportedCount    int        `db:"imported_count" json:"imported_count"`
+	SkippedCount     int        `db:"skipped_count" json:"skipped_count"`
+	PeriodStart      *time.Time `db:"period_start" json:"period_start,omitempty"`
+	PeriodEnd        *time.Time `db:"period_end" json:"period_end,omitempty"`
+	BalanceBefore    int64      `db:"balance_before" json:"balance_before"`
+	BalanceAfter     int64      `db:"balance_after" json:"balance_after"`
+	PreviousSyncedAt *time.Time `db:"previous_synced_at" json:"previous_synced_at,omitempty"`
+	CreatedAt        time.Time  `db:"created_at" json:"created_at"`
+}
+
+// BalanceDelta is how much the batch moved the account balance.
+func (b *ImportBatch) BalanceDelta() int64 {
+	return b.BalanceAfter - b.BalanceBefore
+}
+
+// Include widens the statement period to cover completedAt.
+func (b *ImportBatch) Include(completedAt time.Time) {
+	if b.PeriodStart == nil || completedAt.Before(*b.PeriodStart) {
+		start := completedAt
+		b.PeriodStart = &start
+	}
+	if b.PeriodEnd == nil || completedAt.After(*b.PeriodEnd) {
+		end := completedAt
+		b.PeriodEnd = &end
+	}
+}
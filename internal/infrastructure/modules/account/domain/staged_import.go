@@ -0,0 +1,88 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+
+	"Finance-Manager-System/internal/infrastructure/modules/statement"
+	transactionDomain "Finance-Manager-System/internal/infrastructure/modules/transactions/domain"
+)
+
+const StagedImportTTL = time.Hour
+
+var (
+	ErrStagedImportNotFound   = errors.New("staged import not found")
+	ErrStagedImportExpired    = errors.New("staged import has expired")
+	ErrStagedRowNotFound      = errors.New("staged import row not found")
+	ErrStagedCategoryNotFound = errors.New("category not found")
+)
+
+// StagedImportRow is a parsed statement row as it will be booked on confirm.
+// Row is the position in Statement.Transactions.
+type StagedImportRow struct {
+	Row            int                              `json:"row"`
+	CompletedAt    time.Time                        `json:"completed_at"`
+	Amount         int64                            `json:"amount"`
+	IsIncome       bool                             `json:"is_income"`
+	Description    string                           `json:"description"`
+	MCCCode        *string                          `json:"mcc_code,omitempty"`
+	ExternalID     *string                          `json:"external_id,omitempty"`
+	CategoryID     *uuid.UUID                       `json:"category_id"`
+	CategoryName   string                           `json:"category_name,omitempty"`
+	CategorySource transactionDomain.CategorySource `json:"category_source,omitempty"`
+	// Confidence is set when the category was predicted by the classifier.
+	// Predictions below the threshold leave the row uncategorized.
+	Confidence *float64    `json:"confidence,omitempty"`
+	Duplicate  bool        `json:"duplicate"`
+	Excluded   bool        `json:"excluded"`
+	IsHidden   bool        `json:"is_hidden,omitempty"`
+	TagIDs     []uuid.UUID `json:"tag_ids,omitempty"`
+	Warnings   []string    `json:"warnings,omitempty"`
+}
+
+// StagedImport keeps a parsed statement server-side until the user confirms
+// it. AccountID is set when the statement syncs an existing account.
+type StagedImport struct {
+	StagingID   uuid.UUID            `db:"staging_id" json:"staging_id"`
+	UserID      uuid.UUID            `db:"user_id" json:"user_id"`
+	AccountID   *uuid.UUID           `db:"account_id" json:"account_id,omitempty"`
+	AccountName *string              `db:"account_name" json:"account_name,omitempty"`
+	Format      string               `db:"format" json:"format"`
+	FileName    string               `db:"-" json:"file_name,omitempty"`
+	FileHash    string               `db:"-" json:"-"`
+	Statement   *statement.Statement `db:"-" json:"-"`
+	Rows        []StagedImportRow    `db:"-" json:"rows"`
+	CreatedAt   time.Time            `db:"created_at" json:"created_at"`
+	ExpiresAt   time.Time            `db:"expires_at" json:"expires_at"`
+}
+
+func NewStagedImport(userID uuid.UUID, accountID *uuid.UUID, accountName string, stmt *statement.Statement, rows []StagedImportRow) *StagedImport {
+	now := time.Now().UTC()
+	staged := &StagedImport{
+		StagingID: uuid.New(),
+		UserID:    userID,
+		AccountID: accountID,
+		Format:    stmt.Format,
+		Statement: stmt,
+		Rows:      rows,
+		CreatedAt: now,
+		ExpiresAt: now.Add(StagedImportTTL),
+	}
+	if accountName != "" {
+		staged.AccountName = &accountName
+	}
+	return staged
+}
+
+func (s *StagedImport) IsExpired(now time.Time) bool {
+	return !now.Before(s.ExpiresAt)
+}
+
+func (s *StagedImport) Row(row int) (*StagedImportRow, error) {
+	if row < 0 || row >= len(s.Rows) || s.Rows[row].Row != row {
+		return nil, ErrStagedRowNotFound
+	}
+	return &s.Rows[row], nil
+}
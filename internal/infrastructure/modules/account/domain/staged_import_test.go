@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"Finance-Manager-System/internal/infrastructure/modules/statement"
+)
+
+func TestNewStagedImportExpiryAndRows(t *testing.T) {
+	stmt := &statement.Statement{Format: "csv"}
+	rows := []StagedImportRow{{Row: 0, Amount: 100}, {Row: 1, Amount: 200}}
+	staged := NewStagedImport(uuid.New(), nil, "", stmt, rows)
+
+	if staged.StagingID == uuid.Nil || staged.Format != "csv" || staged.AccountName != nil {
+		t.Fatalf("unexpected staged import: %#v", staged)
+	}
+	if staged.IsExpired(staged.CreatedAt) || !staged.IsExpired(staged.CreatedAt.Add(StagedImportTTL)) {
+		t.Fatalf("staged import must live for %s", StagedImportTTL)
+	}
+	if staged.IsExpired(time.Now().UTC()) {
+		t.Fatalf("fresh staged import must not be expired")
+	}
+
+	row, err := staged.Row(1)
+	if err != nil || row.Amount != 200 {
+		t.Fatalf("unexpected row: %#v %v", row, err)
+	}
+	row.Excluded = true
+	if !staged.Rows[1].Excluded {
+		t.Fatalf("row must be editable in place")
+	}
+	if _, err := staged.Row(2); !errors.Is(err, ErrStagedRowNotFound) {
+		t.Fatalf("expected ErrStagedRowNotFound, got %v", err)
+	}
+}
@@ -5,12 +5,17 @@ import (
 	"errors"
 	"io"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 
 	"Finance-Manager-System/internal/infrastructure/middleware"
+	"Finance-Manager-System/internal/infrastructure/modules/account/domain"
 	"Finance-Manager-System/internal/infrastructure/modules/account/usecase"
+	"Finance-Manager-System/internal/infrastructure/modules/csvstatement"
+	"Finance-Manager-System/internal/infrastructure/modules/statement"
 	"Finance-Manager-System/internal/infrastructure/modules/tbankpdf"
 )
 
@@ -28,8 +33,20 @@ func (a *AccountRouter) Route() chi.Router {
 	r := chi.NewRouter()
 
 	r.Post("/", a.CreateAccount)
+	r.Get("/import/formats", a.GetImportFormats)
+	r.Post("/import", a.ImportAccount)
 	r.Post("/import/pdf", a.ImportAccountFromPDF)
+	r.Get("/import/staged/{stagingID}", a.GetStagedImport)
+	r.Put("/import/staged/{stagingID}", a.UpdateStagedImport)
+	r.Post("/import/staged/{stagingID}/confirm", a.ConfirmStagedImport)
+	r.Delete("/import/staged/{stagingID}", a.DiscardStagedImport)
+	r.Post("/{id}/sync", a.SyncImportedAccount)
 	r.Post("/{id}/sync/pdf", a.SyncImportedAccountFromPDF)
+	r.Get("/{id}/imports", a.GetImportBatches)
+	r.Delete("/{id}/imports/{batchID}", a.UndoImportBatch)
+	r.Get("/{id}/balance-history", a.GetBalanceHistory)
+	r.Post("/{id}/reconcile", a.ReconcileAccount)
+	r.Post("/{id}/valuations", a.AddValuation)
 	r.Get("/", a.GetAccounts)
 	r.Put("/{id}", a.UpdateAccount)
 	r.Delete("/{id}", a.ArchiveAccount)
@@ -41,15 +58,38 @@ type CreateAccountReq struct {
 	Name              string  `json:"name" example:"Мой кошелек"`
 	Currency          string  `json:"currency" example:"RUB"`
 	AccountType       string  `json:"account_type" example:"manual"`
+	Kind              string  `json:"kind" example:"asset"`
 	ColorHex          string  `json:"color_hex" example:"#FF0000"`
 	IsImported        bool    `json:"is_imported" example:"false"`
 	ExternalAccountID *string `json:"external_account_id" example:"null"`
 	InitialBalance    int64   `json:"initial_balance" example:"100000"`
 }
 
+type StagedRowEditReq struct {
+	Row           int        `json:"row" example:"0"`
+	CategoryID    *uuid.UUID `json:"category_id" example:"null"`
+	ClearCategory bool       `json:"clear_category" example:"false"`
+	Excluded      *bool      `json:"excluded" example:"true"`
+}
+
+type UpdateStagedImportReq struct {
+	Rows []StagedRowEditReq `json:"rows"`
+}
+
+type ReconcileReq struct {
+	Reference      string `json:"reference" example:"stored"`
+	PostAdjustment bool   `json:"post_adjustment" example:"false"`
+}
+
 type UpdateAccountReq struct {
 	Name           *string `json:"name" example:"Новое название кошелька"`
 	InitialBalance *int64  `json:"initial_balance" example:"150000"`
+	Kind           *string `json:"kind" example:"liability"`
+}
+
+type ValuationReq struct {
+	Value    int64      `json:"value" example:"120000000"`
+	ValuedAt *time.Time `json:"valued_at" example:"2026-05-01T00:00:00Z"`
 }
 
 // @Summary Создать счет
@@ -79,6 +119,7 @@ func (a *AccountRouter) CreateAccount(w http.ResponseWriter, r *http.Request) {
 		req.Name,
 		req.Currency,
 		req.AccountType,
+		req.Kind,
 		req.ColorHex,
 		req.IsImported,
 		req.ExternalAccountID,
@@ -86,6 +127,10 @@ func (a *AccountRouter) CreateAccount(w http.ResponseWriter, r *http.Request) {
 	)
 
 	if err != nil {
+		if errors.Is(err, domain.ErrLiabilityBalance) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -98,6 +143,38 @@ func (a *AccountRouter) CreateAccount(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// @Summary Поддерживаемые форматы выписок
+// @Tags accounts
+// @Security ApiKeyAuth
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/accounts/import/formats [get]
+func (a *AccountRouter) GetImportFormats(w http.ResponseWriter, r *http.Request) {
+	if _, err := middleware.GetUserID(r.Context()); err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"formats": a.accountUC.SupportedStatementFormats()})
+}
+
+// @Summary Импортировать счет и транзакции из банковской выписки (формат определяется автоматически)
+// @Tags accounts
+// @Security ApiKeyAuth
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "Файл выписки"
+// @Param name formData string false "Название счета"
+// @Param format formData string false "Формат выписки: tbank_pdf, ofx, csv"
+// @Param mapping formData string false "Сопоставление колонок CSV в формате JSON (csvstatement.Mapping)"
+// @Param dry_run query bool false "Только предпросмотр: строки сохраняются для проверки и ничего не записывается"
+// @Success 202 {object} map[string]interface{}
+// @Router /api/v1/accounts/import [post]
+func (a *AccountRouter) ImportAccount(w http.ResponseWriter, r *http.Request) {
+	a.importAccount(w, r, "")
+}
+
 // @Summary Импортировать счет и транзакции из PDF выписки Т-Банка
 // @Tags accounts
 // @Security ApiKeyAuth
@@ -105,55 +182,57 @@ func (a *AccountRouter) CreateAccount(w http.ResponseWriter, r *http.Request) {
 // @Produce json
 // @Param file formData file true "PDF выписка Т-Банка"
 // @Param name formData string false "Название счета"
+// @Param dry_run query bool false "Только предпросмотр: строки сохраняются для проверки и ничего не записывается"
 // @Success 202 {object} map[string]interface{}
 // @Router /api/v1/accounts/import/pdf [post]
 func (a *AccountRouter) ImportAccountFromPDF(w http.ResponseWriter, r *http.Request) {
+	a.importAccount(w, r, tbankpdf.FormatName)
+}
+
+func (a *AccountRouter) importAccount(w http.ResponseWriter, r *http.Request, format string) {
 	userID, err := middleware.GetUserID(r.Context())
 	if err != nil {
 		http.Error(w, "Unauthorized", http.StatusUnauthorized)
 		return
 	}
 
-	if err := r.ParseMultipartForm(25 << 20); err != nil {
-		http.Error(w, "Invalid multipart form", http.StatusBadRequest)
+	src, ok := readStatementFile(w, r)
+	if !ok {
 		return
 	}
+	if format == "" {
+		format = r.FormValue("format")
+	}
 
-	file, _, err := r.FormFile("file")
-	if err != nil {
-		http.Error(w, "file is required", http.StatusBadRequest)
+	accountName := r.FormValue("name")
+	if isDryRun(r) {
+		staged, stageErr := a.accountUC.StageImport(r.Context(), userID, nil, accountName, format, src)
+		if stageErr != nil {
+			writeImportError(w, stageErr)
+			return
+		}
+		writeStagedImport(w, http.StatusAccepted, staged)
 		return
 	}
-	defer file.Close()
 
-	data, err := io.ReadAll(file)
+	result, err := a.accountUC.ImportAccount(r.Context(), userID, accountName, format, src)
 	if err != nil {
-		http.Error(w, "failed to read file", http.StatusBadRequest)
+		writeImportError(w, err)
 		return
 	}
 
-	accountName := r.FormValue("name")
-	result, err := a.accountUC.ImportAccountFromTBankPDF(r.Context(), userID, accountName, data)
-	if err != nil {
-		if errors.Is(err, usecase.ErrInvalidStatement) || errors.Is(err, tbankpdf.ErrInvalidPDF) || errors.Is(err, tbankpdf.ErrStatementNotSupported) {
-			http.Error(w, "Не удалось обработать PDF выписку. Проверьте файл и попробуйте снова", http.StatusBadRequest)
-			return
-		}
-		http.Error(w, "Не удалось импортировать счет. Повторите попытку", http.StatusBadRequest)
+	writeImportResult(w, result)
+}
+
+func writeImportError(w http.ResponseWriter, err error) {
+	if writeStatementError(w, err) {
 		return
 	}
-
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusAccepted)
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"status":                "success",
-		"account_id":            result.AccountID,
-		"imported_transactions": result.ImportedTransactions,
-		"skipped_transactions":  result.SkippedTransactions,
-		"balance":               result.Balance,
-		"account_number":        result.AccountNumber,
-		"contract_number":       result.ContractNumber,
-	})
+	if errors.Is(err, domain.ErrAccountNameLong) {
+		http.Error(w, "Название счета не может быть длиннее 50 символов", http.StatusBadRequest)
+		return
+	}
+	http.Error(w, "Не удалось импортировать счет. Повторите попытку", http.StatusBadRequest)
 }
 
 // @Summary Получить все активные счета
@@ -185,7 +264,8 @@ func (a *AccountRouter) GetAccounts(w http.ResponseWriter, r *http.Request) {
 // @Accept json
 // @Produce json
 // @Param id path string true "ID счета"
-// @Param request body UpdateAccountReq true "Название и/или начальный баланс"
+// @Description kind переключает счет между активами (asset) и обязательствами (liability) для расчета чистого капитала. Долг по обязательству хранится отрицательным балансом.
+// @Param request body UpdateAccountReq true "Название, начальный баланс и/или вид счета"
 // @Success 202 {object} map[string]interface{}
 // @Router /api/v1/accounts/{id} [put]
 func (a *AccountRouter) UpdateAccount(w http.ResponseWriter, r *http.Request) {
@@ -211,8 +291,12 @@ func (a *AccountRouter) UpdateAccount(w http.ResponseWriter, r *http.Request) {
 	if req.Name != nil {
 		name = *req.Name
 	}
-	err = a.accountUC.UpdateManualAccount(r.Context(), userID, accountID, name, req.InitialBalance)
+	err = a.accountUC.UpdateManualAccount(r.Context(), userID, accountID, name, req.InitialBalance, req.Kind)
 	if err != nil {
+		if errors.Is(err, domain.ErrInvalidKind) || errors.Is(err, domain.ErrLiabilityBalance) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -259,6 +343,22 @@ func (a *AccountRouter) ArchiveAccount(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// @Summary Синхронизировать импортированный счет по банковской выписке (формат определяется автоматически)
+// @Tags accounts
+// @Security ApiKeyAuth
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path string true "ID счета"
+// @Param file formData file true "Файл выписки"
+// @Param format formData string false "Формат выписки: tbank_pdf, ofx, csv"
+// @Param mapping formData string false "Сопоставление колонок CSV в формате JSON (csvstatement.Mapping)"
+// @Param dry_run query bool false "Только предпросмотр: строки сохраняются для проверки и ничего не записывается"
+// @Success 202 {object} map[string]interface{}
+// @Router /api/v1/accounts/{id}/sync [post]
+func (a *AccountRouter) SyncImportedAccount(w http.ResponseWriter, r *http.Request) {
+	a.syncImportedAccount(w, r, "")
+}
+
 // @Summary Синхронизировать импортированный счет по PDF выписке
 // @Tags accounts
 // @Security ApiKeyAuth
@@ -266,9 +366,14 @@ func (a *AccountRouter) ArchiveAccount(w http.ResponseWriter, r *http.Request) {
 // @Produce json
 // @Param id path string true "ID счета"
 // @Param file formData file true "PDF выписка Т-Банка"
+// @Param dry_run query bool false "Только предпросмотр: строки сохраняются для проверки и ничего не записывается"
 // @Success 202 {object} map[string]interface{}
 // @Router /api/v1/accounts/{id}/sync/pdf [post]
 func (a *AccountRouter) SyncImportedAccountFromPDF(w http.ResponseWriter, r *http.Request) {
+	a.syncImportedAccount(w, r, tbankpdf.FormatName)
+}
+
+func (a *AccountRouter) syncImportedAccount(w http.ResponseWriter, r *http.Request, format string) {
 	userID, err := middleware.GetUserID(r.Context())
 	if err != nil {
 		http.Error(w, "Unauthorized", http.StatusUnauthorized)
@@ -281,46 +386,542 @@ func (a *AccountRouter) SyncImportedAccountFromPDF(w http.ResponseWriter, r *htt
 		return
 	}
 
-	if err := r.ParseMultipartForm(25 << 20); err != nil {
-		http.Error(w, "Invalid multipart form", http.StatusBadRequest)
+	src, ok := readStatementFile(w, r)
+	if !ok {
+		return
+	}
+	if format == "" {
+		format = r.FormValue("format")
+	}
+
+	if isDryRun(r) {
+		staged, stageErr := a.accountUC.StageImport(r.Context(), userID, &accountID, "", format, src)
+		if stageErr != nil {
+			writeSyncError(w, stageErr)
+			return
+		}
+		writeStagedImport(w, http.StatusAccepted, staged)
 		return
 	}
 
-	file, _, err := r.FormFile("file")
+	result, err := a.accountUC.SyncImportedAccount(r.Context(), userID, accountID, format, src)
 	if err != nil {
-		http.Error(w, "file is required", http.StatusBadRequest)
+		writeSyncError(w, err)
 		return
 	}
-	defer file.Close()
 
-	data, err := io.ReadAll(file)
+	writeImportResult(w, result)
+}
+
+// @Summary Получить историю импортов выписок по счету
+// @Tags accounts
+// @Security ApiKeyAuth
+// @Produce json
+// @Param id path string true "ID счета"
+// @Success 200 {array} domain.ImportBatch
+// @Router /api/v1/accounts/{id}/imports [get]
+func (a *AccountRouter) GetImportBatches(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
 	if err != nil {
-		http.Error(w, "failed to read file", http.StatusBadRequest)
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
 		return
 	}
 
-	result, err := a.accountUC.SyncImportedAccountFromTBankPDF(r.Context(), userID, accountID, data)
+	accountID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid account ID", http.StatusBadRequest)
+		return
+	}
+
+	batches, err := a.accountUC.GetImportBatches(r.Context(), userID, accountID)
+	if err != nil {
+		http.Error(w, "Счет не найден", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(batches)
+}
+
+// @Summary История баланса счета по дням
+// @Description Баланс на конец каждого дня по транзакциям (начальный баланс плюс видимые транзакции) и сохраненные балансы из выписок, ручных изменений и сверок за период.
+// @Tags accounts
+// @Security ApiKeyAuth
+// @Produce json
+// @Param id path string true "ID счета"
+// @Param from query string false "Начало периода (YYYY-MM-DD или RFC3339), по умолчанию 90 дней назад"
+// @Param to query string false "Конец периода (YYYY-MM-DD или RFC3339), по умолчанию сегодня"
+// @Success 200 {object} domain.BalanceHistory
+// @Router /api/v1/accounts/{id}/balance-history [get]
+func (a *AccountRouter) GetBalanceHistory(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	accountID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid account ID", http.StatusBadRequest)
+		return
+	}
+
+	from, err := parseDay(r.URL.Query().Get("from"))
+	if err != nil {
+		http.Error(w, "Invalid from", http.StatusBadRequest)
+		return
+	}
+	to, err := parseDay(r.URL.Query().Get("to"))
+	if err != nil {
+		http.Error(w, "Invalid to", http.StatusBadRequest)
+		return
+	}
+
+	history, err := a.accountUC.GetBalanceHistory(r.Context(), userID, accountID, from, to)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidHistoryRange) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, "Счет не найден", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(history)
+}
+
+// @Summary Сверить баланс счета с транзакциями
+// @Description Пересчитывает баланс по транзакциям и показывает расхождение с сохраненным балансом и последним балансом из выписки. С post_adjustment=true создает корректирующую транзакцию, чтобы транзакции сошлись с выбранным балансом (stored или statement); сохраненный баланс не меняется.
+// @Tags accounts
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "ID счета"
+// @Param request body ReconcileReq false "Параметры сверки"
+// @Success 200 {object} domain.Reconciliation
+// @Router /api/v1/accounts/{id}/reconcile [post]
+func (a *AccountRouter) ReconcileAccount(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	accountID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid account ID", http.StatusBadRequest)
+		return
+	}
+
+	var req ReconcileReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, "Invalid json", http.StatusBadRequest)
+		return
+	}
+
+	result, err := a.accountUC.ReconcileAccount(r.Context(), userID, accountID, domain.ReconcileParams{
+		Reference:      domain.ReconcileReference(req.Reference),
+		PostAdjustment: req.PostAdjustment,
+	})
 	if err != nil {
 		switch {
-		case errors.Is(err, usecase.ErrInvalidStatement), errors.Is(err, tbankpdf.ErrInvalidPDF), errors.Is(err, tbankpdf.ErrStatementNotSupported):
-			http.Error(w, "Не удалось обработать PDF выписку. Проверьте файл и попробуйте снова", http.StatusBadRequest)
-		case errors.Is(err, usecase.ErrStatementAccountMismatch):
-			http.Error(w, "Эта выписка относится к другому счету", http.StatusBadRequest)
+		case errors.Is(err, domain.ErrInvalidReconcileRef):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, domain.ErrNoStatementSnapshot):
+			http.Error(w, "По счету еще не загружено выписок с балансом", http.StatusConflict)
 		default:
-			http.Error(w, "Не удалось синхронизировать счет. Повторите попытку", http.StatusBadRequest)
+			http.Error(w, "Не удалось сверить баланс счета. Повторите попытку", http.StatusBadRequest)
 		}
 		return
 	}
 
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// @Summary Добавить оценку стоимости счета
+// @Description Для ручных счетов: текущая стоимость имущества (квартира, машина) или остаток долга на дату (для обязательств — отрицательное число). Если более поздних оценок нет, баланс счета становится равен оценке плюс транзакции после этой даты.
+// @Tags accounts
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "ID счета"
+// @Param request body ValuationReq true "Стоимость и дата оценки (по умолчанию сейчас)"
+// @Success 201 {object} domain.BalanceSnapshot
+// @Router /api/v1/accounts/{id}/valuations [post]
+func (a *AccountRouter) AddValuation(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	accountID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid account ID", http.StatusBadRequest)
+		return
+	}
+
+	var req ValuationReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid json", http.StatusBadRequest)
+		return
+	}
+
+	snapshot, err := a.accountUC.AddValuation(r.Context(), userID, accountID, req.Value, req.ValuedAt)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrImportedValuation), errors.Is(err, domain.ErrFutureValuation),
+			errors.Is(err, domain.ErrLiabilityBalance):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		default:
+			http.Error(w, "Счет не найден", http.StatusNotFound)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(snapshot)
+}
+
+// parseDay accepts a date or an RFC3339 timestamp. An empty value is nil.
+func parseDay(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	if day, err := time.Parse(time.DateOnly, value); err == nil {
+		return &day, nil
+	}
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, err
+	}
+	return &parsed, nil
+}
+
+// @Summary Отменить последний импорт выписки: удалить его транзакции и вернуть баланс счета
+// @Tags accounts
+// @Security ApiKeyAuth
+// @Produce json
+// @Param id path string true "ID счета"
+// @Param batchID path string true "ID импорта"
+// @Success 202 {object} map[string]interface{}
+// @Router /api/v1/accounts/{id}/imports/{batchID} [delete]
+func (a *AccountRouter) UndoImportBatch(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	accountID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid account ID", http.StatusBadRequest)
+		return
+	}
+
+	batchID, err := uuid.Parse(chi.URLParam(r, "batchID"))
+	if err != nil {
+		http.Error(w, "Invalid batch ID", http.StatusBadRequest)
+		return
+	}
+
+	result, err := a.accountUC.UndoImportBatch(r.Context(), userID, accountID, batchID)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrImportBatchNotFound):
+			http.Error(w, "Импорт не найден", http.StatusNotFound)
+		case errors.Is(err, usecase.ErrAccountNotImported):
+			http.Error(w, "Отменить можно только импорт по импортированному счету", http.StatusBadRequest)
+		case errors.Is(err, domain.ErrImportBatchNotLatest):
+			http.Error(w, "Отменить можно только последний импорт по счету", http.StatusConflict)
+		default:
+			http.Error(w, "Не удалось отменить импорт. Повторите попытку", http.StatusBadRequest)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":               "success",
+		"batch_id":             result.BatchID,
+		"account_id":           result.AccountID,
+		"deleted_transactions": result.DeletedTransactions,
+		"balance":              result.Balance,
+	})
+}
+
+// @Summary Получить предпросмотр импорта
+// @Tags accounts
+// @Security ApiKeyAuth
+// @Produce json
+// @Param stagingID path string true "ID предпросмотра"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/accounts/import/staged/{stagingID} [get]
+func (a *AccountRouter) GetStagedImport(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	stagingID, err := uuid.Parse(chi.URLParam(r, "stagingID"))
+	if err != nil {
+		http.Error(w, "Invalid staging ID", http.StatusBadRequest)
+		return
+	}
+
+	staged, err := a.accountUC.GetStagedImport(r.Context(), userID, stagingID)
+	if err != nil {
+		writeStagedImportError(w, err)
+		return
+	}
+
+	writeStagedImport(w, http.StatusOK, staged)
+}
+
+// @Summary Изменить строки предпросмотра импорта (категория, исключение строки)
+// @Tags accounts
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param stagingID path string true "ID предпросмотра"
+// @Param request body UpdateStagedImportReq true "Изменения строк"
+// @Success 202 {object} map[string]interface{}
+// @Router /api/v1/accounts/import/staged/{stagingID} [put]
+func (a *AccountRouter) UpdateStagedImport(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	stagingID, err := uuid.Parse(chi.URLParam(r, "stagingID"))
+	if err != nil {
+		http.Error(w, "Invalid staging ID", http.StatusBadRequest)
+		return
+	}
+
+	var req UpdateStagedImportReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid json", http.StatusBadRequest)
+		return
+	}
+
+	staged, err := a.accountUC.UpdateStagedImport(r.Context(), userID, stagingID, req.edits())
+	if err != nil {
+		writeStagedImportError(w, err)
+		return
+	}
+
+	writeStagedImport(w, http.StatusAccepted, staged)
+}
+
+// @Summary Подтвердить импорт из предпросмотра
+// @Tags accounts
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param stagingID path string true "ID предпросмотра"
+// @Param request body UpdateStagedImportReq false "Последние изменения строк"
+// @Success 202 {object} map[string]interface{}
+// @Router /api/v1/accounts/import/staged/{stagingID}/confirm [post]
+func (a *AccountRouter) ConfirmStagedImport(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	stagingID, err := uuid.Parse(chi.URLParam(r, "stagingID"))
+	if err != nil {
+		http.Error(w, "Invalid staging ID", http.StatusBadRequest)
+		return
+	}
+
+	var req UpdateStagedImportReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, "Invalid json", http.StatusBadRequest)
+		return
+	}
+
+	result, err := a.accountUC.ConfirmStagedImport(r.Context(), userID, stagingID, req.edits())
+	if err != nil {
+		switch {
+		case errors.Is(err, usecase.ErrStatementAccountMismatch), errors.Is(err, usecase.ErrAccountNotImported):
+			writeSyncError(w, err)
+		default:
+			writeStagedImportError(w, err)
+		}
+		return
+	}
+
+	writeImportResult(w, result)
+}
+
+// @Summary Отменить предпросмотр импорта
+// @Tags accounts
+// @Security ApiKeyAuth
+// @Produce json
+// @Param stagingID path string true "ID предпросмотра"
+// @Success 202 {object} map[string]interface{}
+// @Router /api/v1/accounts/import/staged/{stagingID} [delete]
+func (a *AccountRouter) DiscardStagedImport(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	stagingID, err := uuid.Parse(chi.URLParam(r, "stagingID"))
+	if err != nil {
+		http.Error(w, "Invalid staging ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := a.accountUC.DiscardStagedImport(r.Context(), userID, stagingID); err != nil {
+		writeStagedImportError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]interface{}{"status": "success", "staging_id": stagingID})
+}
+
+func (req UpdateStagedImportReq) edits() []usecase.StagedRowEdit {
+	edits := make([]usecase.StagedRowEdit, 0, len(req.Rows))
+	for _, row := range req.Rows {
+		edits = append(edits, usecase.StagedRowEdit{
+			Row:           row.Row,
+			CategoryID:    row.CategoryID,
+			ClearCategory: row.ClearCategory,
+			Excluded:      row.Excluded,
+		})
+	}
+	return edits
+}
+
+func isDryRun(r *http.Request) bool {
+	dryRun, _ := strconv.ParseBool(r.FormValue("dry_run"))
+	return dryRun
+}
+
+func writeSyncError(w http.ResponseWriter, err error) {
+	if writeStatementError(w, err) {
+		return
+	}
+	switch {
+	case errors.Is(err, usecase.ErrStatementAccountMismatch):
+		http.Error(w, "Эта выписка относится к другому счету", http.StatusBadRequest)
+	case errors.Is(err, usecase.ErrAccountNotImported):
+		http.Error(w, "Синхронизировать можно только импортированный счет", http.StatusBadRequest)
+	default:
+		http.Error(w, "Не удалось синхронизировать счет. Повторите попытку", http.StatusBadRequest)
+	}
+}
+
+func writeStagedImportError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, domain.ErrStagedImportNotFound):
+		http.Error(w, "Предпросмотр импорта не найден", http.StatusNotFound)
+	case errors.Is(err, domain.ErrStagedImportExpired):
+		http.Error(w, "Срок действия предпросмотра истек. Загрузите выписку заново", http.StatusGone)
+	case errors.Is(err, domain.ErrStagedRowNotFound):
+		http.Error(w, "Строка предпросмотра не найдена", http.StatusBadRequest)
+	case errors.Is(err, domain.ErrStagedCategoryNotFound):
+		http.Error(w, "Категория не найдена", http.StatusBadRequest)
+	default:
+		http.Error(w, "Не удалось обработать импорт. Повторите попытку", http.StatusBadRequest)
+	}
+}
+
+func writeStagedImport(w http.ResponseWriter, status int, staged *domain.StagedImport) {
+	response := map[string]interface{}{
+		"status":     "success",
+		"staging_id": staged.StagingID,
+		"expires_at": staged.ExpiresAt,
+		"format":     staged.Format,
+		"account_id": staged.AccountID,
+		"rows":       staged.Rows,
+	}
+	if staged.Statement != nil {
+		response["currency"] = staged.Statement.Currency
+		response["account_number"] = staged.Statement.AccountNumber
+		response["warnings"] = staged.Statement.Warnings
+		if staged.Statement.HasBalance {
+			response["balance"] = staged.Statement.Balance
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(response)
+}
+
+func readStatementFile(w http.ResponseWriter, r *http.Request) (statement.Source, bool) {
+	if err := r.ParseMultipartForm(25 << 20); err != nil {
+		http.Error(w, "Invalid multipart form", http.StatusBadRequest)
+		return statement.Source{}, false
+	}
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		http.Error(w, "file is required", http.StatusBadRequest)
+		return statement.Source{}, false
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		http.Error(w, "failed to read file", http.StatusBadRequest)
+		return statement.Source{}, false
+	}
+
+	options := make(map[string]string, len(r.MultipartForm.Value))
+	for key, values := range r.MultipartForm.Value {
+		if len(values) > 0 {
+			options[key] = values[0]
+		}
+	}
+
+	return statement.Source{Filename: header.Filename, Data: data, Options: options}, true
+}
+
+// writeStatementError reports parsing failures and returns false for errors
+// that the caller has to handle itself.
+func writeStatementError(w http.ResponseWriter, err error) bool {
+	switch {
+	case errors.Is(err, statement.ErrUnknownFormat), errors.Is(err, statement.ErrEmptySource):
+		http.Error(w, "Не удалось определить формат выписки", http.StatusBadRequest)
+	case errors.Is(err, statement.ErrParserNotFound):
+		http.Error(w, "Неподдерживаемый формат выписки", http.StatusBadRequest)
+	case errors.Is(err, csvstatement.ErrInvalidMapping), errors.Is(err, csvstatement.ErrColumnNotFound):
+		http.Error(w, "Некорректное сопоставление колонок CSV: "+err.Error(), http.StatusBadRequest)
+	case errors.Is(err, usecase.ErrInvalidStatement):
+		http.Error(w, "Не удалось обработать выписку. Проверьте файл и попробуйте снова", http.StatusBadRequest)
+	default:
+		return false
+	}
+	return true
+}
+
+func writeImportResult(w http.ResponseWriter, result *usecase.ImportResult) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusAccepted)
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"status":                "success",
 		"account_id":            result.AccountID,
+		"batch_id":              result.BatchID,
+		"format":                result.Format,
 		"imported_transactions": result.ImportedTransactions,
 		"skipped_transactions":  result.SkippedTransactions,
 		"balance":               result.Balance,
 		"account_number":        result.AccountNumber,
 		"contract_number":       result.ContractNumber,
+		"warnings":              result.Warnings,
 	})
 }
@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 
@@ -15,15 +16,23 @@ import (
 	accountDomain "Finance-Manager-System/internal/infrastructure/modules/account/domain"
 	accountUsecase "Finance-Manager-System/internal/infrastructure/modules/account/usecase"
 	categoryDomain "Finance-Manager-System/internal/infrastructure/modules/category/domain"
+	"Finance-Manager-System/internal/infrastructure/modules/classifier"
+	"Finance-Manager-System/internal/infrastructure/modules/csvstatement"
+	"Finance-Manager-System/internal/infrastructure/modules/statement"
+	"Finance-Manager-System/internal/infrastructure/modules/tbankpdf"
 	transactionDomain "Finance-Manager-System/internal/infrastructure/modules/transactions/domain"
 )
 
 type integrationAccountRepo struct {
-	items map[uuid.UUID]*accountDomain.Account
+	items  map[uuid.UUID]*accountDomain.Account
+	staged map[uuid.UUID]*accountDomain.StagedImport
 }
 
 func newIntegrationAccountRepo() *integrationAccountRepo {
-	return &integrationAccountRepo{items: make(map[uuid.UUID]*accountDomain.Account)}
+	return &integrationAccountRepo{
+		items:  make(map[uuid.UUID]*accountDomain.Account),
+		staged: make(map[uuid.UUID]*accountDomain.StagedImport),
+	}
 }
 
 func (r *integrationAccountRepo) AddAccount(ctx context.Context, acc *accountDomain.Account) (uuid.UUID, error) {
@@ -52,6 +61,10 @@ func (r *integrationAccountRepo) UpdateAccountName(ctx context.Context, userID u
 	r.items[accountID].NameAccount = name
 	return nil
 }
+func (r *integrationAccountRepo) SetAccountKind(ctx context.Context, userID uuid.UUID, accountID uuid.UUID, kind accountDomain.AccountKind) error {
+	r.items[accountID].Kind = kind
+	return nil
+}
 func (r *integrationAccountRepo) UpdateManualAccount(ctx context.Context, userID uuid.UUID, accountID uuid.UUID, name string, balance int64) error {
 	r.items[accountID].NameAccount = name
 	r.items[accountID].Balance = balance
@@ -62,6 +75,61 @@ func (r *integrationAccountRepo) UpdateImportedAccountSnapshot(ctx context.Conte
 	return nil
 }
 
+func (r *integrationAccountRepo) SaveStagedImport(ctx context.Context, staged *accountDomain.StagedImport) error {
+	r.staged[staged.StagingID] = staged
+	return nil
+}
+func (r *integrationAccountRepo) GetStagedImport(ctx context.Context, userID uuid.UUID, stagingID uuid.UUID) (*accountDomain.StagedImport, error) {
+	staged, ok := r.staged[stagingID]
+	if !ok || staged.UserID != userID {
+		return nil, accountDomain.ErrStagedImportNotFound
+	}
+	return staged, nil
+}
+func (r *integrationAccountRepo) UpdateStagedImport(ctx context.Context, staged *accountDomain.StagedImport) error {
+	r.staged[staged.StagingID] = staged
+	return nil
+}
+func (r *integrationAccountRepo) DeleteStagedImport(ctx context.Context, userID uuid.UUID, stagingID uuid.UUID) error {
+	if _, ok := r.staged[stagingID]; !ok {
+		return accountDomain.ErrStagedImportNotFound
+	}
+	delete(r.staged, stagingID)
+	return nil
+}
+
+func (r *integrationAccountRepo) AddImportBatch(ctx context.Context, batch *accountDomain.ImportBatch) error {
+	return nil
+}
+func (r *integrationAccountRepo) GetImportBatches(ctx context.Context, userID uuid.UUID, accountID uuid.UUID) ([]accountDomain.ImportBatch, error) {
+	return nil, nil
+}
+func (r *integrationAccountRepo) GetImportBatch(ctx context.Context, userID uuid.UUID, batchID uuid.UUID) (*accountDomain.ImportBatch, error) {
+	return nil, accountDomain.ErrImportBatchNotFound
+}
+func (r *integrationAccountRepo) DeleteImportBatch(ctx context.Context, userID uuid.UUID, batchID uuid.UUID) error {
+	return accountDomain.ErrImportBatchNotFound
+}
+func (r *integrationAccountRepo) RevertImportedAccountSnapshot(ctx context.Context, userID uuid.UUID, accountID uuid.UUID, balance int64, lastSyncedAt *time.Time) error {
+	r.items[accountID].Balance = balance
+	r.items[accountID].LastSyncedAt = lastSyncedAt
+	return nil
+}
+
+func (r *integrationAccountRepo) SetOpeningBalance(ctx context.Context, userID uuid.UUID, accountID uuid.UUID, openingBalance int64) error {
+	r.items[accountID].OpeningBalance = openingBalance
+	return nil
+}
+func (r *integrationAccountRepo) AddBalanceSnapshot(ctx context.Context, snapshot *accountDomain.BalanceSnapshot) error {
+	return nil
+}
+func (r *integrationAccountRepo) GetBalanceSnapshots(ctx context.Context, userID uuid.UUID, accountID uuid.UUID, from, to time.Time) ([]accountDomain.BalanceSnapshot, error) {
+	return nil, nil
+}
+func (r *integrationAccountRepo) GetLatestSnapshot(ctx context.Context, userID uuid.UUID, accountID uuid.UUID, source accountDomain.SnapshotSource) (*accountDomain.BalanceSnapshot, error) {
+	return nil, nil
+}
+
 type integrationAccountCategoryRepo struct{}
 
 func (r *integrationAccountCategoryRepo) GetCategoriesByUser(ctx context.Context, userID uuid.UUID) ([]categoryDomain.Category, error) {
@@ -76,6 +144,27 @@ func (r *integrationAccountTransRepo) AddTransactions(ctx context.Context, trans
 func (r *integrationAccountTransRepo) ResolveAutoCategoryID(ctx context.Context, userID uuid.UUID, isIncome bool, mccCode *string, description string) (*uuid.UUID, error) {
 	return nil, nil
 }
+func (r *integrationAccountTransRepo) GetClassifierSamples(ctx context.Context, userID uuid.UUID, limit int) ([]classifier.Sample, error) {
+	return nil, nil
+}
+
+func (r *integrationAccountTransRepo) GetExistingExternalIDs(ctx context.Context, userID uuid.UUID, accountID uuid.UUID, externalIDs []string) (map[string]bool, error) {
+	return map[string]bool{}, nil
+}
+
+func (r *integrationAccountTransRepo) DeleteTransactionsByImportBatch(ctx context.Context, userID uuid.UUID, batchID uuid.UUID) (int, error) {
+	return 0, nil
+}
+
+func (r *integrationAccountTransRepo) GetAccountLedger(ctx context.Context, userID uuid.UUID, accountID uuid.UUID, until *time.Time) (int64, error) {
+	return 0, nil
+}
+func (r *integrationAccountTransRepo) GetHiddenImportedLedger(ctx context.Context, userID uuid.UUID, accountID uuid.UUID) (int64, error) {
+	return 0, nil
+}
+func (r *integrationAccountTransRepo) GetAccountDailyFlows(ctx context.Context, userID uuid.UUID, accountID uuid.UUID, from, to time.Time) (map[time.Time]int64, error) {
+	return map[time.Time]int64{}, nil
+}
 
 type integrationAccountTxManager struct{}
 
@@ -90,7 +179,7 @@ func withAccountUser(req *http.Request, userID uuid.UUID) *http.Request {
 
 func TestAccountRouterCreateManual(t *testing.T) {
 	repo := newIntegrationAccountRepo()
-	uc := accountUsecase.NewAccountUseCase(repo, &integrationAccountCategoryRepo{}, &integrationAccountTransRepo{}, &integrationAccountTxManager{})
+	uc := accountUsecase.NewAccountUseCase(repo, &integrationAccountCategoryRepo{}, &integrationAccountTransRepo{}, nil, statement.NewRegistry(tbankpdf.NewParser()), &integrationAccountTxManager{})
 	router := NewAccountRouter(uc).Route()
 	userID := uuid.New()
 	body := map[string]interface{}{
@@ -113,7 +202,7 @@ func TestAccountRouterCreateManual(t *testing.T) {
 
 func TestAccountRouterImportInvalidPDF(t *testing.T) {
 	repo := newIntegrationAccountRepo()
-	uc := accountUsecase.NewAccountUseCase(repo, &integrationAccountCategoryRepo{}, &integrationAccountTransRepo{}, &integrationAccountTxManager{})
+	uc := accountUsecase.NewAccountUseCase(repo, &integrationAccountCategoryRepo{}, &integrationAccountTransRepo{}, nil, statement.NewRegistry(tbankpdf.NewParser()), &integrationAccountTxManager{})
 	router := NewAccountRouter(uc).Route()
 	userID := uuid.New()
 
@@ -132,3 +221,113 @@ func TestAccountRouterImportInvalidPDF(t *testing.T) {
 	}
 }
 
+func TestAccountRouterImportUnknownFormat(t *testing.T) {
+	repo := newIntegrationAccountRepo()
+	uc := accountUsecase.NewAccountUseCase(repo, &integrationAccountCategoryRepo{}, &integrationAccountTransRepo{}, nil, statement.NewRegistry(tbankpdf.NewParser()), &integrationAccountTxManager{})
+	router := NewAccountRouter(uc).Route()
+	userID := uuid.New()
+
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	part, _ := writer.CreateFormFile("file", "statement.txt")
+	part.Write([]byte("plain text statement"))
+	writer.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/import", &buf)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, withAccountUser(req, userID))
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("unexpected status: %d body=%s", rr.Code, rr.Body.String())
+	}
+	if len(repo.items) != 0 {
+		t.Fatalf("no account must be created for an unknown format")
+	}
+}
+
+func TestAccountRouterImportCSVWithMapping(t *testing.T) {
+	repo := newIntegrationAccountRepo()
+	parsers := statement.NewRegistry(tbankpdf.NewParser(), csvstatement.NewParser())
+	uc := accountUsecase.NewAccountUseCase(repo, &integrationAccountCategoryRepo{}, &integrationAccountTransRepo{}, nil, parsers, &integrationAccountTxManager{})
+	router := NewAccountRouter(uc).Route()
+	userID := uuid.New()
+
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	part, _ := writer.CreateFormFile("file", "export.csv")
+	part.Write([]byte("when;sum;what\n10.05.2026;-99,90;Кино\n"))
+	writer.WriteField("mapping", `{"date_column":"when","amount_column":"sum","description_column":"what","currency":"EUR"}`)
+	writer.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/import", &buf)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, withAccountUser(req, userID))
+	if rr.Code != http.StatusAccepted {
+		t.Fatalf("unexpected status: %d body=%s", rr.Code, rr.Body.String())
+	}
+
+	var resp map[string]interface{}
+	json.Unmarshal(rr.Body.Bytes(), &resp)
+	if resp["format"] != csvstatement.FormatName || resp["imported_transactions"].(float64) != 1 {
+		t.Fatalf("unexpected response: %v", resp)
+	}
+	for _, acc := range repo.items {
+		if acc.Currency != "EUR" || acc.Balance != -9990 {
+			t.Fatalf("unexpected account: %s %d", acc.Currency, acc.Balance)
+		}
+	}
+}
+
+func TestAccountRouterImportDryRunAndConfirm(t *testing.T) {
+	repo := newIntegrationAccountRepo()
+	parsers := statement.NewRegistry(tbankpdf.NewParser(), csvstatement.NewParser())
+	uc := accountUsecase.NewAccountUseCase(repo, &integrationAccountCategoryRepo{}, &integrationAccountTransRepo{}, nil, parsers, &integrationAccountTxManager{})
+	router := NewAccountRouter(uc).Route()
+	userID := uuid.New()
+
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	part, _ := writer.CreateFormFile("file", "export.csv")
+	part.Write([]byte("date,amount,description\n2026-05-10,-100.00,Taxi\n2026-05-11,-50.00,Bakery\n"))
+	writer.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/import?dry_run=true", &buf)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, withAccountUser(req, userID))
+	if rr.Code != http.StatusAccepted {
+		t.Fatalf("unexpected status: %d body=%s", rr.Code, rr.Body.String())
+	}
+	if len(repo.items) != 0 {
+		t.Fatalf("dry run must not create an account")
+	}
+
+	var staged struct {
+		StagingID uuid.UUID                       `json:"staging_id"`
+		Rows      []accountDomain.StagedImportRow `json:"rows"`
+	}
+	json.Unmarshal(rr.Body.Bytes(), &staged)
+	if staged.StagingID == uuid.Nil || len(staged.Rows) != 2 {
+		t.Fatalf("unexpected dry run response: %s", rr.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/import/staged/"+staged.StagingID.String()+"/confirm", bytes.NewReader([]byte(`{"rows":[{"row":1,"excluded":true}]}`)))
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, withAccountUser(req, userID))
+	if rr.Code != http.StatusAccepted {
+		t.Fatalf("unexpected status: %d body=%s", rr.Code, rr.Body.String())
+	}
+	for _, acc := range repo.items {
+		if acc.Balance != -10000 {
+			t.Fatalf("excluded row must not move the balance, got %d", acc.Balance)
+		}
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/import/staged/"+staged.StagingID.String(), nil)
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, withAccountUser(req, userID))
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("confirmed import must be gone, got %d", rr.Code)
+	}
+}
@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
@@ -76,6 +77,19 @@ func (r *AccountRepo) GetAccountByID(ctx context.Context, userID uuid.UUID, acco
 	return &account, nil
 }
 
+func (r *AccountRepo) GetAccountCurrency(ctx context.Context, userID uuid.UUID, accountID uuid.UUID) (string, error) {
+	q := database.GetQueryer(ctx, r.db)
+	if err := r.ensureAccountsSchema(ctx, q); err != nil {
+		return "", err
+	}
+	var currency string
+	query := `SELECT currency FROM Accounts WHERE user_id = $1 AND account_id = $2`
+	if err := q.GetContext(ctx, &currency, query, userID, accountID); err != nil {
+		return "", fmt.Errorf("account not found: %w", err)
+	}
+	return currency, nil
+}
+
 func (r *AccountRepo) UpdateAccountName(ctx context.Context, userID uuid.UUID, accountID uuid.UUID, name string) error {
 	q := database.GetQueryer(ctx, r.db)
 	if err := r.ensureAccountsSchema(ctx, q); err != nil {
@@ -100,6 +114,30 @@ func (r *AccountRepo) UpdateAccountName(ctx context.Context, userID uuid.UUID, a
 	return nil
 }
 
+func (r *AccountRepo) SetAccountKind(ctx context.Context, userID uuid.UUID, accountID uuid.UUID, kind domain.AccountKind) error {
+	q := database.GetQueryer(ctx, r.db)
+	if err := r.ensureAccountsSchema(ctx, q); err != nil {
+		return err
+	}
+	query := `
+		UPDATE Accounts
+		SET kind = $1
+		WHERE user_id = $2 AND account_id = $3 AND is_archived = false
+	`
+	result, err := q.ExecContext(ctx, query, kind, userID, accountID)
+	if err != nil {
+		return fmt.Errorf("failed to update account kind: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check affected rows: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("account not found or archived")
+	}
+	return nil
+}
+
 func (r *AccountRepo) UpdateManualAccount(ctx context.Context, userID uuid.UUID, accountID uuid.UUID, name string, balance int64) error {
 	q := database.GetQueryer(ctx, r.db)
 	if err := r.ensureAccountsSchema(ctx, q); err != nil {
@@ -107,7 +145,7 @@ func (r *AccountRepo) UpdateManualAccount(ctx context.Context, userID uuid.UUID,
 	}
 	query := `
         UPDATE Accounts 
-        SET name_account = $1, balance = $2
+        SET name_account = $1, balance = $2, opening_balance = opening_balance + ($2 - balance)
         WHERE user_id = $3 AND account_id = $4 AND is_archived = false AND is_imported = false
     `
 
@@ -163,14 +201,14 @@ func (r *AccountRepo) AddAccount(ctx context.Context, acc *domain.Account) (uuid
 	query := `
         INSERT INTO Accounts (
             account_id,
-            user_id, balance, is_imported, external_account_id, 
-            account_type, color_hex, is_archived, name_account, 
+            user_id, balance, opening_balance, is_imported, external_account_id, 
+            account_type, kind, color_hex, is_archived, name_account, 
             currency, last_synced_at, created_at
         ) 
         VALUES (
             :account_id,
-            :user_id, :balance, :is_imported, :external_account_id, 
-            :account_type, :color_hex, :is_archived, :name_account, 
+            :user_id, :balance, :opening_balance, :is_imported, :external_account_id, 
+            :account_type, :kind, :color_hex, :is_archived, :name_account, 
             :currency, :last_synced_at, :created_at
         )
         RETURNING account_id
@@ -198,6 +236,9 @@ func (r *AccountRepo) ensureAccountsSchema(ctx context.Context, q database.Query
 		`ALTER TABLE Accounts ADD COLUMN IF NOT EXISTS is_archived BOOLEAN NOT NULL DEFAULT FALSE`,
 		`ALTER TABLE Accounts ADD COLUMN IF NOT EXISTS last_synced_at TIMESTAMPTZ`,
 		`ALTER TABLE Accounts ADD COLUMN IF NOT EXISTS currency VARCHAR(3) NOT NULL DEFAULT 'RUB'`,
+		`ALTER TABLE Accounts ADD COLUMN IF NOT EXISTS opening_balance BIGINT NOT NULL DEFAULT 0`,
+		`ALTER TABLE Accounts ADD COLUMN IF NOT EXISTS kind VARCHAR(16) NOT NULL DEFAULT 'asset'`,
+		`ALTER TABLE Accounts ADD COLUMN IF NOT EXISTS archived_at TIMESTAMPTZ`,
 	}
 
 	for _, query := range queries {
@@ -215,11 +256,11 @@ func (r *AccountRepo) ArchiveAccount(ctx context.Context, userID uuid.UUID, acco
 	}
 	query := `
         UPDATE Accounts 
-        SET is_archived = true
+        SET is_archived = true, archived_at = $3
         WHERE user_id = $1 AND account_id = $2 AND is_archived = false
     `
 
-	result, err := q.ExecContext(ctx, query, userID, accountID)
+	result, err := q.ExecContext(ctx, query, userID, accountID, time.Now().UTC())
 	if err != nil {
 		return fmt.Errorf("failed to archive account: %w", err)
 	}
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"Finance-Manager-System/internal/infrastructure/database"
+	"Finance-Manager-System/internal/infrastructure/modules/account/domain"
+)
+
+func (r *AccountRepo) AddBalanceSnapshot(ctx context.Context, snapshot *domain.BalanceSnapshot) error {
+	q := database.GetQueryer(ctx, r.db)
+	query := `
+		INSERT INTO BalanceSnapshots (snapshot_id, user_id, account_id, batch_id, source, balance, taken_at, created_at)
+		VALUES (:snapshot_id, :user_id, :account_id, :batch_id, :source, :balance, :taken_at, :created_at)
+	`
+	if _, err := q.NamedExecContext(ctx, query, snapshot); err != nil {
+		return fmt.Errorf("failed to add balance snapshot: %w", err)
+	}
+	return nil
+}
+
+// GetBalanceSnapshots returns the snapshots taken in [from, to), oldest
+// first.
+func (r *AccountRepo) GetBalanceSnapshots(ctx context.Context, userID uuid.UUID, accountID uuid.UUID, from, to time.Time) ([]domain.BalanceSnapshot, error) {
+	q := database.GetQueryer(ctx, r.db)
+	snapshots := make([]domain.BalanceSnapshot, 0)
+	query := `
+		SELECT * FROM BalanceSnapshots
+		WHERE user_id = $1 AND account_id = $2 AND taken_at >= $3 AND taken_at < $4
+		ORDER BY taken_at ASC, created_at ASC
+	`
+	if err := q.SelectContext(ctx, &snapshots, query, userID, accountID, from, to); err != nil {
+		return nil, fmt.Errorf("failed to get balance snapshots: %w", err)
+	}
+	return snapshots, nil
+}
+
+// GetLatestSnapshot returns the most recent snapshot of the given source, or
+// nil if there is none.
+func (r *AccountRepo) GetLatestSnapshot(ctx context.Context, userID uuid.UUID, accountID uuid.UUID, source domain.SnapshotSource) (*domain.BalanceSnapshot, error) {
+	q := database.GetQueryer(ctx, r.db)
+	var snapshot domain.BalanceSnapshot
+	query := `
+		SELECT * FROM BalanceSnapshots
+		WHERE user_id = $1 AND account_id = $2 AND source = $3
+		ORDER BY taken_at DESC, created_at DESC
+		LIMIT 1
+	`
+	if err := q.GetContext(ctx, &snapshot, query, userID, accountID, source); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get balance snapshot: %w", err)
+	}
+	return &snapshot, nil
+}
+
+func (r *AccountRepo) SetOpeningBalance(ctx context.Context, userID uuid.UUID, accountID uuid.UUID, openingBalance int64) error {
+	q := database.GetQueryer(ctx, r.db)
+	query := `UPDATE Accounts SET opening_balance = $1 WHERE user_id = $2 AND account_id = $3`
+	if _, err := q.ExecContext(ctx, query, openingBalance, userID, accountID); err != nil {
+		return fmt.Errorf("failed to set opening balance: %w", err)
+	}
+	return nil
+}
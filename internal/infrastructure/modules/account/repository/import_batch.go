@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"Finance-Manager-System/internal/infrastructure/database"
+	"Finance-Manager-System/internal/infrastructure/modules/account/domain"
+)
+
+func (r *AccountRepo) AddImportBatch(ctx context.Context, batch *domain.ImportBatch) error {
+	q := database.GetQueryer(ctx, r.db)
+	query := `
+		INSERT INTO ImportBatches (
+			batch_id, user_id, account_id, format, file_name, file_hash,
+			imported_count, skipped_count, period_start, period_end,
+			balance_before, balance_after, previous_synced_at, created_at
+		)
+		VALUES (
+			:batch_id, :user_id, :account_id, :format, :file_name, :file_hash,
+			:imported_count, :skipped_count, :period_start, :period_end,
+			:balance_before, :balance_after, :previous_synced_at, :created_at
+		)
+	`
+	if _, err := q.NamedExecContext(ctx, query, batch); err != nil {
+		return fmt.Errorf("failed to save import batch: %w", err)
+	}
+	return nil
+}
+
+func (r *AccountRepo) GetImportBatches(ctx context.Context, userID uuid.UUID, accountID uuid.UUID) ([]domain.ImportBatch, error) {
+	q := database.GetQueryer(ctx, r.db)
+	batches := make([]domain.ImportBatch, 0)
+	query := `
+		SELECT * FROM ImportBatches
+		WHERE user_id = $1 AND account_id = $2
+		ORDER BY created_at DESC, batch_id DESC
+	`
+	if err := q.SelectContext(ctx, &batches, query, userID, accountID); err != nil {
+		return nil, fmt.Errorf("failed to get import batches: %w", err)
+	}
+	return batches, nil
+}
+
+func (r *AccountRepo) GetImportBatch(ctx context.Context, userID uuid.UUID, batchID uuid.UUID) (*domain.ImportBatch, error) {
+	q := database.GetQueryer(ctx, r.db)
+	var batch domain.ImportBatch
+	query := `SELECT * FROM ImportBatches WHERE user_id = $1 AND batch_id = $2`
+	if err := q.GetContext(ctx, &batch, query, userID, batchID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrImportBatchNotFound
+		}
+		return nil, fmt.Errorf("failed to get import batch: %w", err)
+	}
+	return &batch, nil
+}
+
+func (r *AccountRepo) DeleteImportBatch(ctx context.Context, userID uuid.UUID, batchID uuid.UUID) error {
+	q := database.GetQueryer(ctx, r.db)
+	result, err := q.ExecContext(ctx, `DELETE FROM ImportBatches WHERE user_id = $1 AND batch_id = $2`, userID, batchID)
+	if err != nil {
+		return fmt.Errorf("failed to delete import batch: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check affected rows: %w", err)
+	}
+	if rowsAffected == 0 {
+		return domain.ErrImportBatchNotFound
+	}
+	return nil
+}
+
+// RevertImportedAccountSnapshot sets the balance and sync time of an imported
+// account back to the given values after an import batch is undone.
+func (r *AccountRepo) RevertImportedAccountSnapshot(ctx context.Context, userID uuid.UUID, accountID uuid.UUID, balance int64, lastSyncedAt *time.Time) error {
+	q := database.GetQueryer(ctx, r.db)
+	if err := r.ensureAccountsSchema(ctx, q); err != nil {
+		return err
+	}
+	query := `
+		UPDATE Accounts
+		SET balance = $1, last_synced_at = $2
+		WHERE user_id = $3 AND account_id = $4 AND is_archived = false AND is_imported = true
+	`
+	result, err := q.ExecContext(ctx, query, balance, lastSyncedAt, userID, accountID)
+	if err != nil {
+		return fmt.Errorf("failed to revert imported account: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check affected rows: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("imported account not found or archived")
+	}
+	return nil
+}
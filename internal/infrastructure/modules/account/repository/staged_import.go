@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"Finance-Manager-System/internal/infrastructure/database"
+	"Finance-Manager-System/internal/infrastructure/modules/account/domain"
+	"Finance-Manager-System/internal/infrastructure/modules/statement"
+)
+
+type stagedImportPayload struct {
+	Statement *statement.Statement     `json:"statement"`
+	Rows      []domain.StagedImportRow `json:"rows"`
+	FileName  string                   `json:"file_name"`
+	FileHash  string                   `json:"file_hash"`
+}
+
+func newStagedImportPayload(staged *domain.StagedImport) stagedImportPayload {
+	return stagedImportPayload{
+		Statement: staged.Statement,
+		Rows:      staged.Rows,
+		FileName:  staged.FileName,
+		FileHash:  staged.FileHash,
+	}
+}
+
+type stagedImportRecord struct {
+	domain.StagedImport
+	Payload []byte `db:"payload"`
+}
+
+func (r *AccountRepo) SaveStagedImport(ctx context.Context, staged *domain.StagedImport) error {
+	q := database.GetQueryer(ctx, r.db)
+	if _, err := q.ExecContext(ctx, `DELETE FROM StagedImports WHERE expires_at < $1`, time.Now().UTC()); err != nil {
+		return fmt.Errorf("failed to purge expired staged imports: %w", err)
+	}
+
+	payload, err := json.Marshal(newStagedImportPayload(staged))
+	if err != nil {
+		return fmt.Errorf("failed to encode staged import: %w", err)
+	}
+	query := `
+		INSERT INTO StagedImports (staging_id, user_id, account_id, account_name, format, payload, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+	_, err = q.ExecContext(ctx, query,
+		staged.StagingID, staged.UserID, staged.AccountID, staged.AccountName,
+		staged.Format, payload, staged.CreatedAt, staged.ExpiresAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save staged import: %w", err)
+	}
+	return nil
+}
+
+// GetStagedImport returns a staged import that has not expired yet.
+func (r *AccountRepo) GetStagedImport(ctx context.Context, userID uuid.UUID, stagingID uuid.UUID) (*domain.StagedImport, error) {
+	q := database.GetQueryer(ctx, r.db)
+	var record stagedImportRecord
+	query := `SELECT * FROM StagedImports WHERE user_id = $1 AND staging_id = $2 AND expires_at > $3`
+	if err := q.GetContext(ctx, &record, query, userID, stagingID, time.Now().UTC()); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrStagedImportNotFound
+		}
+		return nil, fmt.Errorf("failed to get staged import: %w", err)
+	}
+
+	var payload stagedImportPayload
+	if err := json.Unmarshal(record.Payload, &payload); err != nil {
+		return nil, fmt.Errorf("failed to decode staged import: %w", err)
+	}
+	staged := record.StagedImport
+	staged.Statement = payload.Statement
+	staged.Rows = payload.Rows
+	staged.FileName = payload.FileName
+	staged.FileHash = payload.FileHash
+	return &staged, nil
+}
+
+func (r *AccountRepo) UpdateStagedImport(ctx context.Context, staged *domain.StagedImport) error {
+	q := database.GetQueryer(ctx, r.db)
+	payload, err := json.Marshal(newStagedImportPayload(staged))
+	if err != nil {
+		return fmt.Errorf("failed to encode staged import: %w", err)
+	}
+	query := `UPDATE StagedImports SET payload = $1 WHERE user_id = $2 AND staging_id = $3 AND expires_at > $4`
+	result, err := q.ExecContext(ctx, query, payload, staged.UserID, staged.StagingID, time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("failed to update staged import: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check affected rows: %w", err)
+	}
+	if rowsAffected == 0 {
+		return domain.ErrStagedImportNotFound
+	}
+	return nil
+}
+
+func (r *AccountRepo) DeleteStagedImport(ctx context.Context, userID uuid.UUID, stagingID uuid.UUID) error {
+	q := database.GetQueryer(ctx, r.db)
+	query := `DELETE FROM StagedImports WHERE user_id = $1 AND staging_id = $2 AND expires_at > $3`
+	result, err := q.ExecContext(ctx, query, userID, stagingID, time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("failed to delete staged import: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check affected rows: %w", err)
+	}
+	if rowsAffected == 0 {
+		return domain.ErrStagedImportNotFound
+	}
+	return nil
+}
@@ -2,16 +2,17 @@ package usecase
 
 import (
 	"context"
-	"errors"
 	"fmt"
-	"strings"
+	"time"
 
 	"github.com/google/uuid"
 
 	"Finance-Manager-System/internal/infrastructure/database"
 	"Finance-Manager-System/internal/infrastructure/modules/account/domain"
 	categoryDomain "Finance-Manager-System/internal/infrastructure/modules/category/domain"
-	"Finance-Manager-System/internal/infrastructure/modules/tbankpdf"
+	"Finance-Manager-System/internal/infrastructure/modules/classifier"
+	ruleDomain "Finance-Manager-System/internal/infrastructure/modules/rules/domain"
+	"Finance-Manager-System/internal/infrastructure/modules/statement"
 	transactionDomain "Finance-Manager-System/internal/infrastructure/modules/transactions/domain"
 )
 
@@ -21,8 +22,22 @@ type AccountRepository interface {
 	GetAllAccountsByUser(ctx context.Context, userID uuid.UUID) ([]domain.Account, error)
 	GetAccountByID(ctx context.Context, userID uuid.UUID, accountID uuid.UUID) (*domain.Account, error)
 	UpdateAccountName(ctx context.Context, userID uuid.UUID, accountID uuid.UUID, name string) error
+	SetAccountKind(ctx context.Context, userID uuid.UUID, accountID uuid.UUID, kind domain.AccountKind) error
 	UpdateManualAccount(ctx context.Context, userID uuid.UUID, accountID uuid.UUID, name string, balance int64) error
 	UpdateImportedAccountSnapshot(ctx context.Context, userID uuid.UUID, accountID uuid.UUID, balance int64) error
+	SaveStagedImport(ctx context.Context, staged *domain.StagedImport) error
+	GetStagedImport(ctx context.Context, userID uuid.UUID, stagingID uuid.UUID) (*domain.StagedImport, error)
+	UpdateStagedImport(ctx context.Context, staged *domain.StagedImport) error
+	DeleteStagedImport(ctx context.Context, userID uuid.UUID, stagingID uuid.UUID) error
+	AddImportBatch(ctx context.Context, batch *domain.ImportBatch) error
+	GetImportBatches(ctx context.Context, userID uuid.UUID, accountID uuid.UUID) ([]domain.ImportBatch, error)
+	GetImportBatch(ctx context.Context, userID uuid.UUID, batchID uuid.UUID) (*domain.ImportBatch, error)
+	DeleteImportBatch(ctx context.Context, userID uuid.UUID, batchID uuid.UUID) error
+	RevertImportedAccountSnapshot(ctx context.Context, userID uuid.UUID, accountID uuid.UUID, balance int64, lastSyncedAt *time.Time) error
+	SetOpeningBalance(ctx context.Context, userID uuid.UUID, accountID uuid.UUID, openingBalance int64) error
+	AddBalanceSnapshot(ctx context.Context, snapshot *domain.BalanceSnapshot) error
+	GetBalanceSnapshots(ctx context.Context, userID uuid.UUID, accountID uuid.UUID, from, to time.Time) ([]domain.BalanceSnapshot, error)
+	GetLatestSnapshot(ctx context.Context, userID uuid.UUID, accountID uuid.UUID, source domain.SnapshotSource) (*domain.BalanceSnapshot, error)
 }
 
 type AccountCategoryRepository interface {
@@ -32,12 +47,26 @@ type AccountCategoryRepository interface {
 type AccountTransactionRepository interface {
 	AddTransactions(ctx context.Context, transactions []*transactionDomain.Transaction) (int, error)
 	ResolveAutoCategoryID(ctx context.Context, userID uuid.UUID, isIncome bool, mccCode *string, description string) (*uuid.UUID, error)
+	GetClassifierSamples(ctx context.Context, userID uuid.UUID, limit int) ([]classifier.Sample, error)
+	GetExistingExternalIDs(ctx context.Context, userID uuid.UUID, accountID uuid.UUID, externalIDs []string) (map[string]bool, error)
+	DeleteTransactionsByImportBatch(ctx context.Context, userID uuid.UUID, batchID uuid.UUID) (int, error)
+	GetAccountLedger(ctx context.Context, userID uuid.UUID, accountID uuid.UUID, until *time.Time) (int64, error)
+	GetHiddenImportedLedger(ctx context.Context, userID uuid.UUID, accountID uuid.UUID) (int64, error)
+	GetAccountDailyFlows(ctx context.Context, userID uuid.UUID, accountID uuid.UUID, from, to time.Time) (map[time.Time]int64, error)
+}
+
+// ImportRuleEngine applies the user's categorisation rules to imported rows.
+type ImportRuleEngine interface {
+	EvaluateTargets(ctx context.Context, userID uuid.UUID, targets []ruleDomain.Target) ([]ruleDomain.Outcome, error)
+	TagTransactions(ctx context.Context, userID uuid.UUID, tags map[uuid.UUID][]uuid.UUID) error
 }
 
 type AccountUseCase struct {
 	repo      AccountRepository
 	catRepo   AccountCategoryRepository
 	transRepo AccountTransactionRepository
+	rules     ImportRuleEngine
+	parsers   *statement.Registry
 	txManager database.TxManager
 }
 
@@ -45,150 +74,36 @@ func NewAccountUseCase(
 	repo AccountRepository,
 	catRepo AccountCategoryRepository,
 	transRepo AccountTransactionRepository,
+	rules ImportRuleEngine,
+	parsers *statement.Registry,
 	txManager database.TxManager,
 ) *AccountUseCase {
 	return &AccountUseCase{
 		repo:      repo,
 		catRepo:   catRepo,
 		transRepo: transRepo,
+		rules:     rules,
+		parsers:   parsers,
 		txManager: txManager,
 	}
 }
 
-type ImportPDFResult struct {
-	AccountID            uuid.UUID `json:"account_id"`
-	ImportedTransactions int       `json:"imported_transactions"`
-	SkippedTransactions  int       `json:"skipped_transactions"`
-	Balance              int64     `json:"balance"`
-	AccountNumber        string    `json:"account_number,omitempty"`
-	ContractNumber       string    `json:"contract_number,omitempty"`
-}
-
-var (
-	ErrInvalidStatement         = errors.New("invalid tbank pdf statement")
-	ErrStatementAccountMismatch = errors.New("statement does not match selected imported account")
-)
-
-func (uc *AccountUseCase) ImportAccountFromTBankPDF(ctx context.Context, userID uuid.UUID, customName string, pdfData []byte) (*ImportPDFResult, error) {
-	statement, err := tbankpdf.ParseStatement(pdfData)
-	if err != nil {
-		return nil, ErrInvalidStatement
-	}
-
-	if statement.AccountNumber == "" {
-		statement.AccountNumber = statement.ContractNumber
-	}
-	if statement.AccountNumber == "" {
-		statement.AccountNumber = "tbank-pdf"
-	}
-
-	accountName := customName
-	if accountName == "" {
-		accountName = buildImportedAccountName(statement)
-	}
-
-	var result ImportPDFResult
-	err = uc.txManager.RunInTransaction(ctx, func(txCtx context.Context) error {
-		categories, catErr := uc.catRepo.GetCategoriesByUser(txCtx, userID)
-		if catErr != nil {
-			return catErr
-		}
-
-		externalID := statement.AccountNumber
-		acc, accErr := domain.NewAccount(
-			userID,
-			accountName,
-			"RUB",
-			"imported_pdf",
-			"#FFDD2D",
-			true,
-			&externalID,
-			statement.Balance,
-		)
-		if accErr != nil {
-			return fmt.Errorf("validation failed: %w", accErr)
-		}
-
-		accountID, addErr := uc.repo.AddAccount(txCtx, acc)
-		if addErr != nil {
-			return fmt.Errorf("failed to save account: %w", addErr)
-		}
-
-		trans := make([]*transactionDomain.Transaction, 0, len(statement.Transactions))
-		skippedCount := 0
-		for _, rawTx := range statement.Transactions {
-			categoryID, ruleErr := uc.transRepo.ResolveAutoCategoryID(txCtx, userID, rawTx.IsIncome, rawTx.MCCCode, rawTx.Description)
-			if ruleErr != nil {
-				return fmt.Errorf("failed to resolve auto category: %w", ruleErr)
-			}
-			if categoryID == nil {
-				categoryID = resolveCategoryID(categories, rawTx.Description, rawTx.IsIncome, rawTx.MCCCode)
-			}
-			tx, txErr := transactionDomain.NewTransaction(
-				userID,
-				accountID,
-				categoryID,
-				rawTx.Description,
-				rawTx.IsIncome,
-				rawTx.Amount,
-				rawTx.CompletedAt,
-				true,
-				nil,
-			)
-			if txErr != nil {
-				skippedCount++
-				continue
-			}
-			tx.Currency = "RUB"
-			tx.Status = "completed"
-			tx.BankFee = rawTx.BankFee
-			tx.MCCCode = rawTx.MCCCode
-			tx.SenderAccount = rawTx.SenderAccount
-			tx.ReceiverAccount = rawTx.ReceiverAccount
-			tx.ExternalTransactionID = rawTx.ExternalID
-			trans = append(trans, tx)
-		}
-
-		importedCount := 0
-		if len(trans) > 0 {
-			var insertErr error
-			importedCount, insertErr = uc.transRepo.AddTransactions(txCtx, trans)
-			if insertErr != nil {
-				return fmt.Errorf("failed to import transactions: %w", insertErr)
-			}
-		}
-		if snapshotErr := uc.repo.UpdateImportedAccountSnapshot(txCtx, userID, accountID, statement.Balance); snapshotErr != nil {
-			return fmt.Errorf("failed to update imported account balance: %w", snapshotErr)
-		}
-
-		result = ImportPDFResult{
-			AccountID:            accountID,
-			ImportedTransactions: importedCount,
-			SkippedTransactions:  skippedCount + (len(trans) - importedCount),
-			Balance:              statement.Balance,
-			AccountNumber:        statement.AccountNumber,
-			ContractNumber:       statement.ContractNumber,
-		}
-		return nil
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	return &result, nil
-}
-
 func (uc *AccountUseCase) CreateAccount(
 	ctx context.Context,
 	userID uuid.UUID,
 	name string,
 	currency string,
 	accountType string,
+	kind string,
 	colorHex string,
 	isImported bool,
 	externalAccountID *string,
 	initialBalance int64,
 ) error {
+	accountKind, err := domain.ParseAccountKind(kind, accountType)
+	if err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
 	acc, err := domain.NewAccount(
 		userID,
 		name,
@@ -202,6 +117,10 @@ func (uc *AccountUseCase) CreateAccount(
 	if err != nil {
 		return fmt.Errorf("validation failed: %w", err)
 	}
+	acc.Kind = accountKind
+	if err := acc.Kind.CheckBalance(initialBalance); err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
 
 	_, err = uc.repo.AddAccount(ctx, acc)
 	if err != nil {
@@ -211,31 +130,55 @@ func (uc *AccountUseCase) CreateAccount(
 	return nil
 }
 
-func (uc *AccountUseCase) UpdateManualAccount(ctx context.Context, userID uuid.UUID, accountID uuid.UUID, name string, balance *int64) error {
-	acc, err := uc.repo.GetAccountByID(ctx, userID, accountID)
-	if err != nil {
-		return fmt.Errorf("account not found: %w", err)
-	}
-	if name == "" {
-		name = acc.NameAccount
-	}
-	if acc.IsImported {
-		if balance != nil {
+// UpdateManualAccount renames an account and changes its kind and, for a
+// manual account, its balance in one transaction. An empty name and a nil
+// balance or kind keep the current value.
+func (uc *AccountUseCase) UpdateManualAccount(ctx context.Context, userID uuid.UUID, accountID uuid.UUID, name string, balance *int64, kind *string) error {
+	return uc.txManager.RunInTransaction(ctx, func(ctx context.Context) error {
+		acc, err := uc.repo.GetAccountByID(ctx, userID, accountID)
+		if err != nil {
+			return fmt.Errorf("account not found: %w", err)
+		}
+		if name == "" {
+			name = acc.NameAccount
+		}
+		if acc.IsImported && balance != nil {
 			return fmt.Errorf("imported account cannot change manual balance")
 		}
-		if err := uc.repo.UpdateAccountName(ctx, userID, accountID, name); err != nil {
-			return fmt.Errorf("failed to update account name: %w", err)
+		accountKind := acc.Kind
+		if kind != nil {
+			if accountKind, err = domain.ParseAccountKind(*kind, acc.AccountType); err != nil {
+				return err
+			}
 		}
-		return nil
-	}
-	nextBalance := acc.Balance
-	if balance != nil {
-		nextBalance = *balance
-	}
-	if err := uc.repo.UpdateManualAccount(ctx, userID, accountID, name, nextBalance); err != nil {
-		return fmt.Errorf("failed to update account: %w", err)
-	}
-	return nil
+		nextBalance := acc.Balance
+		if balance != nil {
+			if err := accountKind.CheckBalance(*balance); err != nil {
+				return err
+			}
+			nextBalance = *balance
+		}
+
+		if accountKind != acc.Kind {
+			if err := uc.repo.SetAccountKind(ctx, userID, accountID, accountKind); err != nil {
+				return fmt.Errorf("failed to update account kind: %w", err)
+			}
+		}
+		if acc.IsImported {
+			if err := uc.repo.UpdateAccountName(ctx, userID, accountID, name); err != nil {
+				return fmt.Errorf("failed to update account name: %w", err)
+			}
+			return nil
+		}
+		if err := uc.repo.UpdateManualAccount(ctx, userID, accountID, name, nextBalance); err != nil {
+			return fmt.Errorf("failed to update account: %w", err)
+		}
+		if nextBalance == acc.Balance {
+			return nil
+		}
+		snapshot := domain.NewBalanceSnapshot(userID, accountID, domain.SnapshotManual, nextBalance, time.Now())
+		return uc.repo.AddBalanceSnapshot(ctx, snapshot)
+	})
 }
 
 func (uc *AccountUseCase) GetUserAccounts(ctx context.Context, userID uuid.UUID) ([]domain.Account, error) {
@@ -268,107 +211,6 @@ func (uc *AccountUseCase) RenameAccount(ctx context.Context, userID uuid.UUID, a
 	return nil
 }
 
-func (uc *AccountUseCase) SyncImportedAccountFromTBankPDF(ctx context.Context, userID uuid.UUID, accountID uuid.UUID, pdfData []byte) (*ImportPDFResult, error) {
-	statement, err := tbankpdf.ParseStatement(pdfData)
-	if err != nil {
-		return nil, ErrInvalidStatement
-	}
-
-	var result ImportPDFResult
-	err = uc.txManager.RunInTransaction(ctx, func(txCtx context.Context) error {
-		acc, accErr := uc.repo.GetAccountByID(txCtx, userID, accountID)
-		if accErr != nil {
-			return fmt.Errorf("account not found: %w", accErr)
-		}
-		if !acc.IsImported {
-			return fmt.Errorf("only imported accounts can be synchronized")
-		}
-		statementExternalID := strings.TrimSpace(statement.AccountNumber)
-		if statementExternalID == "" {
-			statementExternalID = strings.TrimSpace(statement.ContractNumber)
-		}
-		accountExternalID := ""
-		if acc.ExternalAccountID != nil {
-			accountExternalID = strings.TrimSpace(*acc.ExternalAccountID)
-		}
-		if statementExternalID != "" && accountExternalID != "" && statementExternalID != accountExternalID {
-			return ErrStatementAccountMismatch
-		}
-
-		categories, catErr := uc.catRepo.GetCategoriesByUser(txCtx, userID)
-		if catErr != nil {
-			return catErr
-		}
-
-		trans := make([]*transactionDomain.Transaction, 0, len(statement.Transactions))
-		skippedCount := 0
-		for _, rawTx := range statement.Transactions {
-			if acc.LastSyncedAt != nil && !rawTx.CompletedAt.After(acc.LastSyncedAt.UTC()) {
-				skippedCount++
-				continue
-			}
-			categoryID, ruleErr := uc.transRepo.ResolveAutoCategoryID(txCtx, userID, rawTx.IsIncome, rawTx.MCCCode, rawTx.Description)
-			if ruleErr != nil {
-				return fmt.Errorf("failed to resolve auto category: %w", ruleErr)
-			}
-			if categoryID == nil {
-				categoryID = resolveCategoryID(categories, rawTx.Description, rawTx.IsIncome, rawTx.MCCCode)
-			}
-			tx, txErr := transactionDomain.NewTransaction(
-				userID,
-				accountID,
-				categoryID,
-				rawTx.Description,
-				rawTx.IsIncome,
-				rawTx.Amount,
-				rawTx.CompletedAt,
-				true,
-				nil,
-			)
-			if txErr != nil {
-				skippedCount++
-				continue
-			}
-			tx.Currency = "RUB"
-			tx.Status = "completed"
-			tx.BankFee = rawTx.BankFee
-			tx.MCCCode = rawTx.MCCCode
-			tx.SenderAccount = rawTx.SenderAccount
-			tx.ReceiverAccount = rawTx.ReceiverAccount
-			tx.ExternalTransactionID = rawTx.ExternalID
-			trans = append(trans, tx)
-		}
-
-		importedCount := 0
-		if len(trans) > 0 {
-			var insertErr error
-			importedCount, insertErr = uc.transRepo.AddTransactions(txCtx, trans)
-			if insertErr != nil {
-				return fmt.Errorf("failed to import transactions: %w", insertErr)
-			}
-		}
-
-		if err := uc.repo.UpdateImportedAccountSnapshot(txCtx, userID, accountID, statement.Balance); err != nil {
-			return fmt.Errorf("failed to update imported account balance: %w", err)
-		}
-
-		result = ImportPDFResult{
-			AccountID:            accountID,
-			ImportedTransactions: importedCount,
-			SkippedTransactions:  skippedCount + (len(trans) - importedCount),
-			Balance:              statement.Balance,
-			AccountNumber:        statement.AccountNumber,
-			ContractNumber:       statement.ContractNumber,
-		}
-		return nil
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	return &result, nil
-}
-
 func (uc *AccountUseCase) ArchiveAccount(ctx context.Context, userID uuid.UUID, accountID uuid.UUID) error {
 	if userID == uuid.Nil || accountID == uuid.Nil {
 		return fmt.Errorf("user ID and account ID cannot be empty")
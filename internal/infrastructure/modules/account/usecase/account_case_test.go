@@ -2,18 +2,29 @@ package usecase
 
 import (
 	"context"
+	"errors"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 
 	accountDomain "Finance-Manager-System/internal/infrastructure/modules/account/domain"
 	categoryDomain "Finance-Manager-System/internal/infrastructure/modules/category/domain"
+	"Finance-Manager-System/internal/infrastructure/modules/classifier"
+	"Finance-Manager-System/internal/infrastructure/modules/csvstatement"
+	ruleDomain "Finance-Manager-System/internal/infrastructure/modules/rules/domain"
+	"Finance-Manager-System/internal/infrastructure/modules/statement"
+	"Finance-Manager-System/internal/infrastructure/modules/tbankpdf"
 	transactionDomain "Finance-Manager-System/internal/infrastructure/modules/transactions/domain"
 )
 
 type fakeAccountRepo struct {
-	account *accountDomain.Account
-	updated bool
+	account   *accountDomain.Account
+	updated   bool
+	staged    map[uuid.UUID]*accountDomain.StagedImport
+	batches   []accountDomain.ImportBatch
+	snapshots []accountDomain.BalanceSnapshot
 }
 
 func (f *fakeAccountRepo) AddAccount(ctx context.Context, acc *accountDomain.Account) (uuid.UUID, error) {
@@ -39,6 +50,10 @@ func (f *fakeAccountRepo) UpdateAccountName(ctx context.Context, userID uuid.UUI
 	f.updated = true
 	return nil
 }
+func (f *fakeAccountRepo) SetAccountKind(ctx context.Context, userID uuid.UUID, accountID uuid.UUID, kind accountDomain.AccountKind) error {
+	f.account.Kind = kind
+	return nil
+}
 func (f *fakeAccountRepo) UpdateManualAccount(ctx context.Context, userID uuid.UUID, accountID uuid.UUID, name string, balance int64) error {
 	f.account.NameAccount = name
 	f.account.Balance = balance
@@ -50,20 +65,199 @@ func (f *fakeAccountRepo) UpdateImportedAccountSnapshot(ctx context.Context, use
 	return nil
 }
 
-type fakeAccountCatRepo struct{}
+func (f *fakeAccountRepo) SaveStagedImport(ctx context.Context, staged *accountDomain.StagedImport) error {
+	if f.staged == nil {
+		f.staged = map[uuid.UUID]*accountDomain.StagedImport{}
+	}
+	f.staged[staged.StagingID] = staged
+	return nil
+}
+func (f *fakeAccountRepo) GetStagedImport(ctx context.Context, userID uuid.UUID, stagingID uuid.UUID) (*accountDomain.StagedImport, error) {
+	staged, ok := f.staged[stagingID]
+	if !ok || staged.UserID != userID {
+		return nil, accountDomain.ErrStagedImportNotFound
+	}
+	return staged, nil
+}
+func (f *fakeAccountRepo) UpdateStagedImport(ctx context.Context, staged *accountDomain.StagedImport) error {
+	f.staged[staged.StagingID] = staged
+	return nil
+}
+func (f *fakeAccountRepo) DeleteStagedImport(ctx context.Context, userID uuid.UUID, stagingID uuid.UUID) error {
+	if _, ok := f.staged[stagingID]; !ok {
+		return accountDomain.ErrStagedImportNotFound
+	}
+	delete(f.staged, stagingID)
+	return nil
+}
+
+func (f *fakeAccountRepo) AddImportBatch(ctx context.Context, batch *accountDomain.ImportBatch) error {
+	f.batches = append([]accountDomain.ImportBatch{*batch}, f.batches...)
+	return nil
+}
+func (f *fakeAccountRepo) GetImportBatches(ctx context.Context, userID uuid.UUID, accountID uuid.UUID) ([]accountDomain.ImportBatch, error) {
+	return f.batches, nil
+}
+func (f *fakeAccountRepo) GetImportBatch(ctx context.Context, userID uuid.UUID, batchID uuid.UUID) (*accountDomain.ImportBatch, error) {
+	for i := range f.batches {
+		if f.batches[i].BatchID == batchID {
+			return &f.batches[i], nil
+		}
+	}
+	return nil, accountDomain.ErrImportBatchNotFound
+}
+func (f *fakeAccountRepo) DeleteImportBatch(ctx context.Context, userID uuid.UUID, batchID uuid.UUID) error {
+	for i := range f.batches {
+		if f.batches[i].BatchID == batchID {
+			f.batches = append(f.batches[:i], f.batches[i+1:]...)
+			return nil
+		}
+	}
+	return accountDomain.ErrImportBatchNotFound
+}
+func (f *fakeAccountRepo) RevertImportedAccountSnapshot(ctx context.Context, userID uuid.UUID, accountID uuid.UUID, balance int64, lastSyncedAt *time.Time) error {
+	f.account.Balance = balance
+	f.account.LastSyncedAt = lastSyncedAt
+	return nil
+}
+
+func (f *fakeAccountRepo) SetOpeningBalance(ctx context.Context, userID uuid.UUID, accountID uuid.UUID, openingBalance int64) error {
+	f.account.OpeningBalance = openingBalance
+	return nil
+}
+func (f *fakeAccountRepo) AddBalanceSnapshot(ctx context.Context, snapshot *accountDomain.BalanceSnapshot) error {
+	f.snapshots = append(f.snapshots, *snapshot)
+	return nil
+}
+func (f *fakeAccountRepo) GetBalanceSnapshots(ctx context.Context, userID uuid.UUID, accountID uuid.UUID, from, to time.Time) ([]accountDomain.BalanceSnapshot, error) {
+	var out []accountDomain.BalanceSnapshot
+	for _, snapshot := range f.snapshots {
+		if !snapshot.TakenAt.Before(from) && snapshot.TakenAt.Before(to) {
+			out = append(out, snapshot)
+		}
+	}
+	return out, nil
+}
+func (f *fakeAccountRepo) GetLatestSnapshot(ctx context.Context, userID uuid.UUID, accountID uuid.UUID, source accountDomain.SnapshotSource) (*accountDomain.BalanceSnapshot, error) {
+	var latest *accountDomain.BalanceSnapshot
+	for i := range f.snapshots {
+		if f.snapshots[i].Source == source && (latest == nil || f.snapshots[i].TakenAt.After(latest.TakenAt)) {
+			latest = &f.snapshots[i]
+		}
+	}
+	return latest, nil
+}
+
+type fakeAccountCatRepo struct {
+	categories []categoryDomain.Category
+}
 
 func (f *fakeAccountCatRepo) GetCategoriesByUser(ctx context.Context, userID uuid.UUID) ([]categoryDomain.Category, error) {
-	return nil, nil
+	return f.categories, nil
 }
 
-type fakeAccountTransRepo struct{}
+type fakeAccountTransRepo struct {
+	added   []*transactionDomain.Transaction
+	samples []classifier.Sample
+}
 
 func (f *fakeAccountTransRepo) AddTransactions(ctx context.Context, transactions []*transactionDomain.Transaction) (int, error) {
-	return len(transactions), nil
+	inserted := 0
+	for _, tx := range transactions {
+		duplicate := false
+		for _, existing := range f.added {
+			if tx.ExternalTransactionID != nil && existing.ExternalTransactionID != nil && *tx.ExternalTransactionID == *existing.ExternalTransactionID {
+				duplicate = true
+				break
+			}
+		}
+		if duplicate {
+			continue
+		}
+		f.added = append(f.added, tx)
+		inserted++
+	}
+	return inserted, nil
 }
 func (f *fakeAccountTransRepo) ResolveAutoCategoryID(ctx context.Context, userID uuid.UUID, isIncome bool, mccCode *string, description string) (*uuid.UUID, error) {
 	return nil, nil
 }
+func (f *fakeAccountTransRepo) GetClassifierSamples(ctx context.Context, userID uuid.UUID, limit int) ([]classifier.Sample, error) {
+	return f.samples, nil
+}
+
+func (f *fakeAccountTransRepo) GetExistingExternalIDs(ctx context.Context, userID uuid.UUID, accountID uuid.UUID, externalIDs []string) (map[string]bool, error) {
+	existing := make(map[string]bool)
+	for _, tx := range f.added {
+		if tx.AccountID != accountID || tx.ExternalTransactionID == nil {
+			continue
+		}
+		for _, id := range externalIDs {
+			if id == *tx.ExternalTransactionID {
+				existing[id] = true
+			}
+		}
+	}
+	return existing, nil
+}
+
+func (f *fakeAccountTransRepo) DeleteTransactionsByImportBatch(ctx context.Context, userID uuid.UUID, batchID uuid.UUID) (int, error) {
+	kept := f.added[:0]
+	deleted := 0
+	for _, tx := range f.added {
+		if tx.ImportBatchID != nil && *tx.ImportBatchID == batchID {
+			deleted++
+			continue
+		}
+		kept = append(kept, tx)
+	}
+	f.added = kept
+	return deleted, nil
+}
+
+func (f *fakeAccountTransRepo) GetAccountLedger(ctx context.Context, userID uuid.UUID, accountID uuid.UUID, until *time.Time) (int64, error) {
+	var sum int64
+	for _, tx := range f.added {
+		if tx.AccountID != accountID || tx.IsHidden || (until != nil && tx.CompletedAt.After(*until)) {
+			continue
+		}
+		if tx.IsIncome {
+			sum += tx.Amount
+		} else {
+			sum -= tx.Amount
+		}
+	}
+	return sum, nil
+}
+func (f *fakeAccountTransRepo) GetHiddenImportedLedger(ctx context.Context, userID uuid.UUID, accountID uuid.UUID) (int64, error) {
+	var sum int64
+	for _, tx := range f.added {
+		if tx.AccountID != accountID || !tx.IsHidden || tx.ExternalTransactionID == nil {
+			continue
+		}
+		if tx.IsIncome {
+			sum += tx.Amount
+		} else {
+			sum -= tx.Amount
+		}
+	}
+	return sum, nil
+}
+func (f *fakeAccountTransRepo) GetAccountDailyFlows(ctx context.Context, userID uuid.UUID, accountID uuid.UUID, from, to time.Time) (map[time.Time]int64, error) {
+	flows := make(map[time.Time]int64)
+	for _, tx := range f.added {
+		if tx.AccountID != accountID || tx.IsHidden || tx.CompletedAt.Before(from) || !tx.CompletedAt.Before(to) {
+			continue
+		}
+		day := tx.CompletedAt.UTC().Truncate(24 * time.Hour)
+		if tx.IsIncome {
+			flows[day] += tx.Amount
+		} else {
+			flows[day] -= tx.Amount
+		}
+	}
+	return flows, nil
+}
 
 type fakeTxManager struct{}
 
@@ -72,9 +266,9 @@ func (f *fakeTxManager) RunInTransaction(ctx context.Context, fn func(ctx contex
 }
 
 func TestImportAccountFromInvalidPDF(t *testing.T) {
-	uc := NewAccountUseCase(&fakeAccountRepo{}, &fakeAccountCatRepo{}, &fakeAccountTransRepo{}, &fakeTxManager{})
-	_, err := uc.ImportAccountFromTBankPDF(context.Background(), uuid.New(), "x", []byte("not pdf"))
-	if err != ErrInvalidStatement {
+	uc := NewAccountUseCase(&fakeAccountRepo{}, &fakeAccountCatRepo{}, &fakeAccountTransRepo{}, nil, statement.NewRegistry(tbankpdf.NewParser()), &fakeTxManager{})
+	_, err := uc.ImportAccount(context.Background(), uuid.New(), "x", tbankpdf.FormatName, statement.Source{Data: []byte("not pdf")})
+	if !errors.Is(err, ErrInvalidStatement) {
 		t.Fatalf("expected ErrInvalidStatement, got %v", err)
 	}
 }
@@ -93,9 +287,9 @@ func TestUpdateManualAccountRejectsBalanceForImported(t *testing.T) {
 			Balance:           100,
 		},
 	}
-	uc := NewAccountUseCase(repo, &fakeAccountCatRepo{}, &fakeAccountTransRepo{}, &fakeTxManager{})
+	uc := NewAccountUseCase(repo, &fakeAccountCatRepo{}, &fakeAccountTransRepo{}, nil, statement.NewRegistry(tbankpdf.NewParser()), &fakeTxManager{})
 	nextBalance := int64(200)
-	err := uc.UpdateManualAccount(context.Background(), userID, accountID, "Renamed", &nextBalance)
+	err := uc.UpdateManualAccount(context.Background(), userID, accountID, "Renamed", &nextBalance, nil)
 	if err == nil {
 		t.Fatalf("expected error")
 	}
@@ -113,9 +307,9 @@ func TestUpdateManualAccountSuccess(t *testing.T) {
 			Balance:     100,
 		},
 	}
-	uc := NewAccountUseCase(repo, &fakeAccountCatRepo{}, &fakeAccountTransRepo{}, &fakeTxManager{})
+	uc := NewAccountUseCase(repo, &fakeAccountCatRepo{}, &fakeAccountTransRepo{}, nil, statement.NewRegistry(tbankpdf.NewParser()), &fakeTxManager{})
 	nextBalance := int64(333)
-	err := uc.UpdateManualAccount(context.Background(), userID, accountID, "Manual 2", &nextBalance)
+	err := uc.UpdateManualAccount(context.Background(), userID, accountID, "Manual 2", &nextBalance, nil)
 	if err != nil {
 		t.Fatalf("expected nil error, got %v", err)
 	}
@@ -124,3 +318,531 @@ func TestUpdateManualAccountSuccess(t *testing.T) {
 	}
 }
 
+func TestLiabilityRejectsPositiveBalance(t *testing.T) {
+	userID := uuid.New()
+	repo := &fakeAccountRepo{}
+	uc := NewAccountUseCase(repo, &fakeAccountCatRepo{}, &fakeAccountTransRepo{}, nil, statement.NewRegistry(tbankpdf.NewParser()), &fakeTxManager{})
+
+	err := uc.CreateAccount(context.Background(), userID, "Ипотека", "RUB", "LOAN", "", "", false, nil, 50000000)
+	if !errors.Is(err, accountDomain.ErrLiabilityBalance) {
+		t.Fatalf("expected ErrLiabilityBalance, got %v", err)
+	}
+	if repo.account != nil {
+		t.Fatalf("account with a positive loan balance must not be saved")
+	}
+
+	if err := uc.CreateAccount(context.Background(), userID, "Ипотека", "RUB", "LOAN", "", "", false, nil, -50000000); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if repo.account.Kind != accountDomain.KindLiability || repo.account.Balance != -50000000 {
+		t.Fatalf("unexpected account: %#v", repo.account)
+	}
+
+	positive := int64(100)
+	err = uc.UpdateManualAccount(context.Background(), userID, repo.account.AccountID, "", &positive, nil)
+	if !errors.Is(err, accountDomain.ErrLiabilityBalance) {
+		t.Fatalf("expected ErrLiabilityBalance on update, got %v", err)
+	}
+	if repo.account.Balance != -50000000 {
+		t.Fatalf("balance must stay as it was, got %d", repo.account.Balance)
+	}
+}
+
+func TestUpdateManualAccountChangesKindWithBalance(t *testing.T) {
+	userID := uuid.New()
+	accountID := uuid.New()
+	repo := &fakeAccountRepo{
+		account: &accountDomain.Account{
+			AccountID:   accountID,
+			UserID:      userID,
+			AccountType: "CUSTOM",
+			Kind:        accountDomain.KindAsset,
+			NameAccount: "Долг другу",
+			Balance:     0,
+		},
+	}
+	uc := NewAccountUseCase(repo, &fakeAccountCatRepo{}, &fakeAccountTransRepo{}, nil, statement.NewRegistry(tbankpdf.NewParser()), &fakeTxManager{})
+
+	liability := string(accountDomain.KindLiability)
+	positive := int64(30000)
+	err := uc.UpdateManualAccount(context.Background(), userID, accountID, "", &positive, &liability)
+	if !errors.Is(err, accountDomain.ErrLiabilityBalance) {
+		t.Fatalf("expected ErrLiabilityBalance, got %v", err)
+	}
+	if repo.account.Kind != accountDomain.KindAsset || repo.account.Balance != 0 {
+		t.Fatalf("nothing must change on a rejected update: %#v", repo.account)
+	}
+
+	owed := -positive
+	if err := uc.UpdateManualAccount(context.Background(), userID, accountID, "", &owed, &liability); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if repo.account.Kind != accountDomain.KindLiability || repo.account.Balance != owed {
+		t.Fatalf("unexpected account: %#v", repo.account)
+	}
+}
+
+type fakeStatementParser struct {
+	stmt *statement.Statement
+}
+
+func (p *fakeStatementParser) Name() string { return "fake_csv" }
+func (p *fakeStatementParser) Detect(src statement.Source) bool {
+	return strings.HasSuffix(src.Filename, ".csv")
+}
+func (p *fakeStatementParser) Parse(src statement.Source) (*statement.Statement, error) {
+	return p.stmt, nil
+}
+
+func TestImportAccountDetectsFormatAndSyncSkipsOldEntries(t *testing.T) {
+	userID := uuid.New()
+	day := time.Date(2026, 5, 10, 10, 0, 0, 0, time.UTC)
+	parser := &fakeStatementParser{stmt: &statement.Statement{
+		BankName:      "Test Bank",
+		AccountNumber: "40817810000000001234",
+		Currency:      "USD",
+		Balance:       5000,
+		Transactions: []statement.TransactionEntry{
+			{CompletedAt: day, Amount: 1000, Description: "Coffee"},
+			{CompletedAt: day.Add(time.Hour), Amount: 6000, IsIncome: true, Description: "Salary"},
+		},
+	}}
+	repo := &fakeAccountRepo{}
+	transRepo := &fakeAccountTransRepo{}
+	uc := NewAccountUseCase(repo, &fakeAccountCatRepo{}, transRepo, nil, statement.NewRegistry(tbankpdf.NewParser(), parser), &fakeTxManager{})
+
+	result, err := uc.ImportAccount(context.Background(), userID, "", "", statement.Source{Filename: "export.csv", Data: []byte("x")})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if result.Format != "fake_csv" || result.ImportedTransactions != 2 {
+		t.Fatalf("unexpected import result: %#v", result)
+	}
+	if repo.account.NameAccount != "Test Bank счет *1234" || repo.account.Currency != "USD" {
+		t.Fatalf("unexpected account: %s %s", repo.account.NameAccount, repo.account.Currency)
+	}
+	if transRepo.added[0].Currency != "USD" || transRepo.added[0].AccountID != result.AccountID {
+		t.Fatalf("transactions must be booked on the imported account in its currency")
+	}
+
+	repo.account.LastSyncedAt = &day
+	result, err = uc.SyncImportedAccount(context.Background(), userID, result.AccountID, "", statement.Source{Filename: "export.csv", Data: []byte("x")})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if result.ImportedTransactions != 1 || result.SkippedTransactions != 1 {
+		t.Fatalf("unexpected sync result: %#v", result)
+	}
+
+	_, err = uc.ImportAccount(context.Background(), userID, "", "", statement.Source{Filename: "export.xlsx", Data: []byte("x")})
+	if !errors.Is(err, statement.ErrUnknownFormat) {
+		t.Fatalf("expected ErrUnknownFormat, got %v", err)
+	}
+}
+
+func TestImportCSVWithoutBalanceMovesBalanceByInsertedRows(t *testing.T) {
+	userID := uuid.New()
+	repo := &fakeAccountRepo{}
+	transRepo := &fakeAccountTransRepo{}
+	uc := NewAccountUseCase(repo, &fakeAccountCatRepo{}, transRepo, nil, statement.NewRegistry(csvstatement.NewParser()), &fakeTxManager{})
+
+	first := "date,amount,description\n2026-05-10,-100.00,Taxi\n2026-05-11,500.00,Cashback\n"
+	result, err := uc.ImportAccount(context.Background(), userID, "Spreadsheet", "", statement.Source{Filename: "a.csv", Data: []byte(first)})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if result.Balance != 40000 || repo.account.Balance != 40000 {
+		t.Fatalf("unexpected balance after import: %d", repo.account.Balance)
+	}
+
+	second := first + "2026-05-12,-50.00,Bakery\n"
+	result, err = uc.SyncImportedAccount(context.Background(), userID, result.AccountID, "csv", statement.Source{Filename: "b.csv", Data: []byte(second)})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if result.ImportedTransactions != 1 || result.SkippedTransactions != 2 {
+		t.Fatalf("duplicates must be skipped: %#v", result)
+	}
+	if repo.account.Balance != 35000 {
+		t.Fatalf("only new rows must move the balance, got %d", repo.account.Balance)
+	}
+}
+
+func TestStageImportRejectsLongAccountName(t *testing.T) {
+	repo := &fakeAccountRepo{}
+	uc := NewAccountUseCase(repo, &fakeAccountCatRepo{}, &fakeAccountTransRepo{}, nil, statement.NewRegistry(csvstatement.NewParser()), &fakeTxManager{})
+
+	data := []byte("date,amount,description\n2026-05-10,-100.00,Taxi\n")
+	name := strings.Repeat("я", accountDomain.MaxAccountNameLength+1)
+	_, err := uc.StageImport(context.Background(), uuid.New(), nil, name, "", statement.Source{Filename: "a.csv", Data: data})
+	if !errors.Is(err, accountDomain.ErrAccountNameLong) {
+		t.Fatalf("expected ErrAccountNameLong, got %v", err)
+	}
+	if len(repo.staged) != 0 {
+		t.Fatalf("nothing must be staged")
+	}
+}
+
+func TestStageEditAndConfirmImport(t *testing.T) {
+	userID := uuid.New()
+	foodID := uuid.New()
+	repo := &fakeAccountRepo{}
+	transRepo := &fakeAccountTransRepo{}
+	catRepo := &fakeAccountCatRepo{categories: []categoryDomain.Category{{CategoryID: foodID, UserID: userID, NameCategory: "Еда"}}}
+	uc := NewAccountUseCase(repo, catRepo, transRepo, nil, statement.NewRegistry(csvstatement.NewParser()), &fakeTxManager{})
+
+	first := "date,amount,description\n2026-05-10,-100.00,Taxi\n2026-05-11,-200.00,Bakery\n"
+	staged, err := uc.StageImport(context.Background(), userID, nil, "Spreadsheet", "", statement.Source{Filename: "a.csv", Data: []byte(first)})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if repo.account != nil || len(transRepo.added) != 0 {
+		t.Fatalf("staging must not book anything")
+	}
+	if len(staged.Rows) != 2 || staged.Format != "csv" {
+		t.Fatalf("unexpected staged import: %#v", staged)
+	}
+
+	excluded := true
+	_, err = uc.UpdateStagedImport(context.Background(), userID, staged.StagingID, []StagedRowEdit{
+		{Row: 0, Excluded: &excluded},
+		{Row: 1, CategoryID: &foodID},
+	})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	unknownID := uuid.New()
+	_, err = uc.UpdateStagedImport(context.Background(), userID, staged.StagingID, []StagedRowEdit{{Row: 1, CategoryID: &unknownID}})
+	if !errors.Is(err, accountDomain.ErrStagedCategoryNotFound) {
+		t.Fatalf("expected ErrStagedCategoryNotFound, got %v", err)
+	}
+	_, err = uc.UpdateStagedImport(context.Background(), userID, staged.StagingID, []StagedRowEdit{{Row: 5}})
+	if !errors.Is(err, accountDomain.ErrStagedRowNotFound) {
+		t.Fatalf("expected ErrStagedRowNotFound, got %v", err)
+	}
+
+	result, err := uc.ConfirmStagedImport(context.Background(), userID, staged.StagingID, nil)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if result.ImportedTransactions != 1 || result.SkippedTransactions != 1 {
+		t.Fatalf("unexpected confirm result: %#v", result)
+	}
+	if repo.account.NameAccount != "Spreadsheet" || repo.account.Balance != -20000 {
+		t.Fatalf("unexpected account: %s %d", repo.account.NameAccount, repo.account.Balance)
+	}
+	if transRepo.added[0].CategoryID == nil || *transRepo.added[0].CategoryID != foodID {
+		t.Fatalf("edited category must be booked")
+	}
+	if _, err := uc.GetStagedImport(context.Background(), userID, staged.StagingID); !errors.Is(err, accountDomain.ErrStagedImportNotFound) {
+		t.Fatalf("confirmed import must be removed, got %v", err)
+	}
+
+	second := first + "2026-05-12,-50.00,Cinema\n"
+	staged, err = uc.StageImport(context.Background(), userID, &result.AccountID, "", "csv", statement.Source{Filename: "b.csv", Data: []byte(second)})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if staged.Rows[0].Duplicate || !staged.Rows[1].Duplicate || staged.Rows[2].Duplicate {
+		t.Fatalf("only already booked rows must be flagged as duplicates: %#v", staged.Rows)
+	}
+
+	staged.ExpiresAt = time.Now().UTC().Add(-time.Minute)
+	if _, err := uc.ConfirmStagedImport(context.Background(), userID, staged.StagingID, nil); !errors.Is(err, accountDomain.ErrStagedImportExpired) {
+		t.Fatalf("expected ErrStagedImportExpired, got %v", err)
+	}
+}
+
+type fakeImportRules struct {
+	rules  []ruleDomain.Rule
+	tagged map[uuid.UUID][]uuid.UUID
+}
+
+func (f *fakeImportRules) EvaluateTargets(ctx context.Context, userID uuid.UUID, targets []ruleDomain.Target) ([]ruleDomain.Outcome, error) {
+	outcomes := make([]ruleDomain.Outcome, len(targets))
+	for i, target := range targets {
+		outcomes[i] = ruleDomain.Evaluate(f.rules, target)
+	}
+	return outcomes, nil
+}
+func (f *fakeImportRules) TagTransactions(ctx context.Context, userID uuid.UUID, tags map[uuid.UUID][]uuid.UUID) error {
+	f.tagged = tags
+	return nil
+}
+
+func TestImportAppliesRules(t *testing.T) {
+	userID := uuid.New()
+	taxiID := uuid.New()
+	tagID := uuid.New()
+	contains := "taxi"
+	rule, err := ruleDomain.NewRule(userID, ruleDomain.RuleParams{DescriptionContains: &contains, CategoryID: &taxiID, TagID: &tagID, Hide: true})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	rules := &fakeImportRules{rules: []ruleDomain.Rule{*rule}}
+	repo := &fakeAccountRepo{}
+	transRepo := &fakeAccountTransRepo{}
+	catRepo := &fakeAccountCatRepo{categories: []categoryDomain.Category{{CategoryID: taxiID, UserID: userID, NameCategory: "Такси"}}}
+	uc := NewAccountUseCase(repo, catRepo, transRepo, rules, statement.NewRegistry(csvstatement.NewParser()), &fakeTxManager{})
+
+	data := "date,amount,description\n2026-05-10,-100.00,City Taxi\n2026-05-11,-200.00,Bakery\n"
+	staged, err := uc.StageImport(context.Background(), userID, nil, "Spreadsheet", "", statement.Source{Filename: "a.csv", Data: []byte(data)})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if staged.Rows[0].CategoryID == nil || *staged.Rows[0].CategoryID != taxiID || !staged.Rows[0].IsHidden || len(staged.Rows[0].TagIDs) != 1 {
+		t.Fatalf("rule must categorise, hide and tag the row: %#v", staged.Rows[0])
+	}
+	if staged.Rows[0].CategorySource != transactionDomain.SourceRule {
+		t.Fatalf("expected rule source, got %q", staged.Rows[0].CategorySource)
+	}
+	if staged.Rows[1].IsHidden || len(staged.Rows[1].TagIDs) != 0 {
+		t.Fatalf("unmatched row must be left alone: %#v", staged.Rows[1])
+	}
+
+	if _, err := uc.ConfirmStagedImport(context.Background(), userID, staged.StagingID, nil); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	taxi := transRepo.added[0]
+	if taxi.CategorySource != transactionDomain.SourceRule {
+		t.Fatalf("category source must be booked, got %q", taxi.CategorySource)
+	}
+	if !taxi.IsHidden || transRepo.added[1].IsHidden {
+		t.Fatalf("only the matched transaction must be hidden")
+	}
+	if tags := rules.tagged[taxi.TransactionID]; len(tags) != 1 || tags[0] != tagID || len(rules.tagged) != 1 {
+		t.Fatalf("rule tag must be added to the booked transaction, got %v", rules.tagged)
+	}
+}
+
+func TestImportWithStatementBalanceLeavesHiddenRowsOut(t *testing.T) {
+	userID := uuid.New()
+	contains := "taxi"
+	rule, err := ruleDomain.NewRule(userID, ruleDomain.RuleParams{DescriptionContains: &contains, Hide: true})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	day := time.Date(2026, 5, 10, 10, 0, 0, 0, time.UTC)
+	taxiID, salaryID, coffeeID := "1", "2", "3"
+	entries := []statement.TransactionEntry{
+		{CompletedAt: day, Amount: 10000, Description: "City Taxi", ExternalID: &taxiID},
+		{CompletedAt: day.Add(time.Hour), Amount: 60000, IsIncome: true, Description: "Salary", ExternalID: &salaryID},
+	}
+	parser := &fakeStatementParser{stmt: &statement.Statement{
+		AccountNumber: "40817810000000001234",
+		Balance:       150000,
+		HasBalance:    true,
+		Transactions:  entries,
+	}}
+	repo := &fakeAccountRepo{}
+	transRepo := &fakeAccountTransRepo{}
+	uc := NewAccountUseCase(repo, &fakeAccountCatRepo{}, transRepo, &fakeImportRules{rules: []ruleDomain.Rule{*rule}}, statement.NewRegistry(parser), &fakeTxManager{})
+
+	// toggle moves the balance as ToggleTransactionsVisibility does.
+	toggle := func(tx *transactionDomain.Transaction, hide bool) {
+		tx.IsHidden = hide
+		if hide == tx.IsIncome {
+			repo.account.Balance -= tx.Amount
+		} else {
+			repo.account.Balance += tx.Amount
+		}
+	}
+	reconciled := func(step string) {
+		t.Helper()
+		result, err := uc.ReconcileAccount(context.Background(), userID, repo.account.AccountID, accountDomain.ReconcileParams{})
+		if err != nil {
+			t.Fatalf("%s: expected nil error, got %v", step, err)
+		}
+		if result.Drift != 0 {
+			t.Fatalf("%s: ledger must match the balance, drift %d", step, result.Drift)
+		}
+	}
+
+	if _, err := uc.ImportAccount(context.Background(), userID, "", "", statement.Source{Filename: "a.csv", Data: []byte("x")}); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	taxi := transRepo.added[0]
+	if !taxi.IsHidden || repo.account.Balance != 160000 {
+		t.Fatalf("the hidden row must be left out of the balance: hidden=%v balance=%d", taxi.IsHidden, repo.account.Balance)
+	}
+	reconciled("import")
+
+	toggle(taxi, false)
+	if repo.account.Balance != 150000 {
+		t.Fatalf("unhiding must bring the statement balance back, got %d", repo.account.Balance)
+	}
+	reconciled("unhide")
+	toggle(taxi, true)
+	reconciled("hide")
+
+	parser.stmt = &statement.Statement{
+		AccountNumber: "40817810000000001234",
+		Balance:       145000,
+		HasBalance:    true,
+		Transactions:  append(entries, statement.TransactionEntry{CompletedAt: day.Add(24 * time.Hour), Amount: 5000, Description: "Coffee", ExternalID: &coffeeID}),
+	}
+	if _, err := uc.SyncImportedAccount(context.Background(), userID, repo.account.AccountID, "", statement.Source{Filename: "b.csv", Data: []byte("x")}); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if repo.account.Balance != 155000 {
+		t.Fatalf("a row hidden earlier must stay out of the balance, got %d", repo.account.Balance)
+	}
+	reconciled("sync")
+
+	toggle(taxi, false)
+	if repo.account.Balance != 145000 {
+		t.Fatalf("unhiding must bring the statement balance back, got %d", repo.account.Balance)
+	}
+	reconciled("unhide after sync")
+}
+
+func TestImportFallsBackToClassifier(t *testing.T) {
+	userID := uuid.New()
+	coffeeID, taxiID, otherID := uuid.New(), uuid.New(), uuid.New()
+	transRepo := &fakeAccountTransRepo{}
+	for i := 0; i < 15; i++ {
+		transRepo.samples = append(transRepo.samples,
+			classifier.Sample{CategoryID: coffeeID, Description: "KOFEMANIA Tverskaya", Amount: 35000},
+			classifier.Sample{CategoryID: taxiID, Description: "CITYMOBIL ride", Amount: 60000},
+		)
+	}
+	catRepo := &fakeAccountCatRepo{categories: []categoryDomain.Category{
+		{CategoryID: coffeeID, UserID: userID, NameCategory: "Кофейни"},
+		{CategoryID: taxiID, UserID: userID, NameCategory: "Такси"},
+		{CategoryID: otherID, UserID: userID, NameCategory: "Другое"},
+	}}
+	uc := NewAccountUseCase(&fakeAccountRepo{}, catRepo, transRepo, nil, statement.NewRegistry(csvstatement.NewParser()), &fakeTxManager{})
+
+	data := "date,amount,description\n2026-05-10,-320.00,Kofemania Arbat\n2026-05-11,-200.00,Bakery\n"
+	staged, err := uc.StageImport(context.Background(), userID, nil, "Spreadsheet", "", statement.Source{Filename: "a.csv", Data: []byte(data)})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	coffee, bakery := staged.Rows[0], staged.Rows[1]
+	if coffee.CategoryID == nil || *coffee.CategoryID != coffeeID || coffee.Confidence == nil || *coffee.Confidence < classifier.DefaultThreshold {
+		t.Fatalf("expected a confident classifier category, got %#v", coffee)
+	}
+	if bakery.CategoryID != nil || bakery.Confidence == nil || *bakery.Confidence >= classifier.DefaultThreshold {
+		t.Fatalf("low-confidence row must be left uncategorized, got %#v", bakery)
+	}
+	if coffee.CategorySource != transactionDomain.SourceClassifier || bakery.CategorySource != transactionDomain.SourceUnknown {
+		t.Fatalf("unexpected category sources: %q %q", coffee.CategorySource, bakery.CategorySource)
+	}
+
+	if _, err := uc.ConfirmStagedImport(context.Background(), userID, staged.StagingID, nil); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if booked := transRepo.added[0]; booked.CategoryConfidence == nil || *booked.CategoryConfidence != *coffee.Confidence {
+		t.Fatalf("classifier confidence must be booked, got %#v", booked)
+	}
+}
+
+func TestUndoImportBatchRestoresBalance(t *testing.T) {
+	userID := uuid.New()
+	repo := &fakeAccountRepo{}
+	transRepo := &fakeAccountTransRepo{}
+	uc := NewAccountUseCase(repo, &fakeAccountCatRepo{}, transRepo, nil, statement.NewRegistry(csvstatement.NewParser()), &fakeTxManager{})
+
+	first := "date,amount,description\n2026-05-10,-100.00,Taxi\n2026-05-11,500.00,Cashback\n"
+	imported, err := uc.ImportAccount(context.Background(), userID, "Spreadsheet", "", statement.Source{Filename: "a.csv", Data: []byte(first)})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	second := first + "2026-05-12,-50.00,Bakery\n"
+	synced, err := uc.SyncImportedAccount(context.Background(), userID, imported.AccountID, "csv", statement.Source{Filename: "b.csv", Data: []byte(second)})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+
+	batches, err := uc.GetImportBatches(context.Background(), userID, imported.AccountID)
+	if err != nil || len(batches) != 2 {
+		t.Fatalf("expected two import batches, got %d %v", len(batches), err)
+	}
+	latest := batches[0]
+	if latest.BatchID != synced.BatchID || latest.ImportedCount != 1 || latest.SkippedCount != 2 || latest.FileName != "b.csv" || len(latest.FileHash) != 64 {
+		t.Fatalf("unexpected batch: %#v", latest)
+	}
+	if latest.PeriodStart == nil || latest.PeriodEnd == nil || latest.PeriodStart.Day() != 10 || latest.PeriodEnd.Day() != 12 {
+		t.Fatalf("unexpected batch period: %v %v", latest.PeriodStart, latest.PeriodEnd)
+	}
+
+	if _, err := uc.UndoImportBatch(context.Background(), userID, uuid.New(), synced.BatchID); !errors.Is(err, accountDomain.ErrImportBatchNotFound) {
+		t.Fatalf("batch of another account must not be found, got %v", err)
+	}
+
+	undone, err := uc.UndoImportBatch(context.Background(), userID, imported.AccountID, synced.BatchID)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if undone.DeletedTransactions != 1 || repo.account.Balance != 40000 || len(transRepo.added) != 2 {
+		t.Fatalf("unexpected undo: %#v balance=%d", undone, repo.account.Balance)
+	}
+
+	undone, err = uc.UndoImportBatch(context.Background(), userID, imported.AccountID, imported.BatchID)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if undone.DeletedTransactions != 2 || repo.account.Balance != 0 || len(transRepo.added) != 0 || repo.account.LastSyncedAt != nil {
+		t.Fatalf("undoing the first import must empty the account: %#v", undone)
+	}
+}
+
+func TestUndoImportBatchOnlyUndoesLatest(t *testing.T) {
+	userID := uuid.New()
+	day := time.Date(2026, 5, 10, 10, 0, 0, 0, time.UTC)
+	parser := &fakeStatementParser{stmt: &statement.Statement{
+		AccountNumber: "40817810000000001234",
+		Balance:       140000,
+		HasBalance:    true,
+		Transactions: []statement.TransactionEntry{
+			{CompletedAt: day, Amount: 10000, Description: "Taxi"},
+			{CompletedAt: day.Add(time.Hour), Amount: 50000, IsIncome: true, Description: "Cashback"},
+		},
+	}}
+	repo := &fakeAccountRepo{}
+	transRepo := &fakeAccountTransRepo{}
+	uc := NewAccountUseCase(repo, &fakeAccountCatRepo{}, transRepo, nil, statement.NewRegistry(parser), &fakeTxManager{})
+
+	imported, err := uc.ImportAccount(context.Background(), userID, "", "", statement.Source{Filename: "a.csv", Data: []byte("x")})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if repo.account.Balance != 140000 || repo.account.OpeningBalance != 100000 {
+		t.Fatalf("unexpected first import: balance=%d opening=%d", repo.account.Balance, repo.account.OpeningBalance)
+	}
+	syncedAt := day.Add(time.Hour)
+	repo.account.LastSyncedAt = &syncedAt
+	parser.stmt = &statement.Statement{
+		AccountNumber: "40817810000000001234",
+		Balance:       135000,
+		HasBalance:    true,
+		Transactions: []statement.TransactionEntry{
+			{CompletedAt: day.Add(24 * time.Hour), Amount: 5000, Description: "Bakery"},
+		},
+	}
+	synced, err := uc.SyncImportedAccount(context.Background(), userID, imported.AccountID, "", statement.Source{Filename: "b.csv", Data: []byte("x")})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+
+	if _, err := uc.UndoImportBatch(context.Background(), userID, imported.AccountID, imported.BatchID); !errors.Is(err, accountDomain.ErrImportBatchNotLatest) {
+		t.Fatalf("expected ErrImportBatchNotLatest, got %v", err)
+	}
+	if repo.account.Balance != 135000 || repo.account.OpeningBalance != 100000 || len(transRepo.added) != 3 || len(repo.batches) != 2 {
+		t.Fatalf("a rejected undo must not change the account: balance=%d opening=%d", repo.account.Balance, repo.account.OpeningBalance)
+	}
+
+	if _, err := uc.UndoImportBatch(context.Background(), userID, imported.AccountID, synced.BatchID); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if repo.account.Balance != 140000 || repo.account.OpeningBalance != 100000 || repo.account.LastSyncedAt == nil || !repo.account.LastSyncedAt.Equal(syncedAt) {
+		t.Fatalf("unexpected account after undoing the sync: balance=%d opening=%d", repo.account.Balance, repo.account.OpeningBalance)
+	}
+
+	if _, err := uc.UndoImportBatch(context.Background(), userID, imported.AccountID, imported.BatchID); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if repo.account.Balance != 0 || repo.account.OpeningBalance != 0 || repo.account.LastSyncedAt != nil || len(transRepo.added) != 0 {
+		t.Fatalf("undoing the first import must reset the account: balance=%d opening=%d", repo.account.Balance, repo.account.OpeningBalance)
+	}
+}
@@ -0,0 +1,230 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"Finance-Manager-System/internal/infrastructure/modules/account/domain"
+	transactionDomain "Finance-Manager-System/internal/infrastructure/modules/transactions/domain"
+)
+
+const adjustmentName = "Корректировка баланса"
+
+// GetBalanceHistory returns the end-of-day balances implied by the ledger
+// between from and to, and the statement and manual balances recorded in that
+// period.
+func (uc *AccountUseCase) GetBalanceHistory(ctx context.Context, userID uuid.UUID, accountID uuid.UUID, from, to *time.Time) (*domain.BalanceHistory, error) {
+	start, end, err := domain.HistoryRange(from, to, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	acc, err := uc.repo.GetAccountByID(ctx, userID, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("account not found: %w", err)
+	}
+
+	before := start.Add(-time.Nanosecond)
+	ledger, err := uc.transRepo.GetAccountLedger(ctx, userID, accountID, &before)
+	if err != nil {
+		return nil, err
+	}
+	next := end.AddDate(0, 0, 1)
+	flows, err := uc.transRepo.GetAccountDailyFlows(ctx, userID, accountID, start, next)
+	if err != nil {
+		return nil, err
+	}
+	snapshots, err := uc.repo.GetBalanceSnapshots(ctx, userID, accountID, start, next)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.BalanceHistory{
+		AccountID:     accountID,
+		Currency:      acc.Currency,
+		From:          start,
+		To:            end,
+		StoredBalance: acc.Balance,
+		Points:        domain.BuildDailyBalances(acc.OpeningBalance+ledger, flows, start, end),
+		Snapshots:     snapshots,
+	}, nil
+}
+
+// ReconcileAccount recomputes the balance from the opening balance and the
+// visible transactions and reports how far the stored balance and the latest
+// statement balance drift from it. When asked, it posts an adjusting entry so
+// that the ledger matches the chosen reference; the stored balance itself is
+// never changed.
+func (uc *AccountUseCase) ReconcileAccount(ctx context.Context, userID uuid.UUID, accountID uuid.UUID, params domain.ReconcileParams) (*domain.Reconciliation, error) {
+	if params.Reference == "" {
+		params.Reference = domain.ReconcileStored
+	}
+	if params.Reference != domain.ReconcileStored && params.Reference != domain.ReconcileStatement {
+		return nil, domain.ErrInvalidReconcileRef
+	}
+
+	var result *domain.Reconciliation
+	err := uc.txManager.RunInTransaction(ctx, func(ctx context.Context) error {
+		acc, err := uc.repo.GetAccountByID(ctx, userID, accountID)
+		if err != nil {
+			return fmt.Errorf("account not found: %w", err)
+		}
+		ledger, err := uc.transRepo.GetAccountLedger(ctx, userID, accountID, nil)
+		if err != nil {
+			return err
+		}
+		result = &domain.Reconciliation{
+			AccountID:       accountID,
+			ComputedBalance: acc.OpeningBalance + ledger,
+			StoredBalance:   acc.Balance,
+		}
+		result.Drift = result.StoredBalance - result.ComputedBalance
+
+		snapshot, err := uc.repo.GetLatestSnapshot(ctx, userID, accountID, domain.SnapshotStatement)
+		if err != nil {
+			return err
+		}
+		if snapshot != nil {
+			atStatement, err := uc.transRepo.GetAccountLedger(ctx, userID, accountID, &snapshot.TakenAt)
+			if err != nil {
+				return err
+			}
+			computed := acc.OpeningBalance + atStatement
+			result.Statement = &domain.StatementCheck{
+				Balance:         snapshot.Balance,
+				TakenAt:         snapshot.TakenAt,
+				ComputedBalance: computed,
+				Drift:           snapshot.Balance - computed,
+			}
+		}
+
+		if !params.PostAdjustment {
+			return nil
+		}
+		amount, postedAt, reference := result.Drift, time.Now().UTC(), result.StoredBalance
+		if params.Reference == domain.ReconcileStatement {
+			if result.Statement == nil {
+				return domain.ErrNoStatementSnapshot
+			}
+			amount, postedAt, reference = result.Statement.Drift, result.Statement.TakenAt, result.Statement.Balance
+		}
+		if amount == 0 {
+			return nil
+		}
+
+		adjustment, err := newAdjustment(userID, acc, amount, postedAt)
+		if err != nil {
+			return err
+		}
+		if _, err := uc.transRepo.AddTransactions(ctx, []*transactionDomain.Transaction{adjustment}); err != nil {
+			return fmt.Errorf("failed to post adjusting entry: %w", err)
+		}
+		confirmed := domain.NewBalanceSnapshot(userID, accountID, domain.SnapshotReconciliation, reference, postedAt)
+		if err := uc.repo.AddBalanceSnapshot(ctx, confirmed); err != nil {
+			return err
+		}
+
+		result.AdjustmentTransactionID = &adjustment.TransactionID
+		result.AdjustmentAmount = amount
+		result.ComputedBalance += amount
+		result.Drift -= amount
+		if result.Statement != nil && !postedAt.After(result.Statement.TakenAt) {
+			result.Statement.ComputedBalance += amount
+			result.Statement.Drift -= amount
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// newAdjustment builds the manual transaction that moves the ledger by amount.
+// It is marked as categorised by the user so it stays out of the review inbox,
+// and as an adjustment so reports and budgets do not count it.
+func newAdjustment(userID uuid.UUID, acc *domain.Account, amount int64, postedAt time.Time) (*transactionDomain.Transaction, error) {
+	isIncome := amount > 0
+	if !isIncome {
+		amount = -amount
+	}
+	adjustment, err := transactionDomain.NewTransaction(userID, acc.AccountID, nil, adjustmentName, isIncome, amount, postedAt, false, nil)
+	if err != nil {
+		return nil, err
+	}
+	adjustment.TransactionID = uuid.New()
+	adjustment.Currency = acc.Currency
+	adjustment.CategorySource = transactionDomain.SourceManual
+	adjustment.IsAdjustment = true
+	return adjustment, nil
+}
+
+// AddValuation records the value of a manual account on a date, such as the
+// market price of a car or what is still owed on a loan. Unless a later
+// valuation or manual balance exists, the stored balance follows it, moved by
+// the transactions posted after that date.
+func (uc *AccountUseCase) AddValuation(ctx context.Context, userID uuid.UUID, accountID uuid.UUID, value int64, valuedAt *time.Time) (*domain.BalanceSnapshot, error) {
+	now := time.Now().UTC()
+	at := now
+	if valuedAt != nil {
+		at = valuedAt.UTC()
+	}
+	if at.After(now) {
+		return nil, domain.ErrFutureValuation
+	}
+
+	var snapshot *domain.BalanceSnapshot
+	err := uc.txManager.RunInTransaction(ctx, func(ctx context.Context) error {
+		acc, err := uc.repo.GetAccountByID(ctx, userID, accountID)
+		if err != nil {
+			return fmt.Errorf("account not found: %w", err)
+		}
+		if acc.IsImported {
+			return domain.ErrImportedValuation
+		}
+		if err := acc.Kind.CheckBalance(value); err != nil {
+			return err
+		}
+
+		superseded := false
+		for _, source := range []domain.SnapshotSource{domain.SnapshotManual, domain.SnapshotValuation} {
+			latest, err := uc.repo.GetLatestSnapshot(ctx, userID, accountID, source)
+			if err != nil {
+				return err
+			}
+			if latest != nil && latest.TakenAt.After(at) {
+				superseded = true
+			}
+		}
+		snapshot = domain.NewBalanceSnapshot(userID, accountID, domain.SnapshotValuation, value, at)
+		if err := uc.repo.AddBalanceSnapshot(ctx, snapshot); err != nil {
+			return err
+		}
+		if superseded {
+			return nil
+		}
+
+		total, err := uc.transRepo.GetAccountLedger(ctx, userID, accountID, nil)
+		if err != nil {
+			return err
+		}
+		before, err := uc.transRepo.GetAccountLedger(ctx, userID, accountID, &at)
+		if err != nil {
+			return err
+		}
+		balance := value + total - before
+		if balance == acc.Balance {
+			return nil
+		}
+		if err := uc.repo.UpdateManualAccount(ctx, userID, accountID, acc.NameAccount, balance); err != nil {
+			return fmt.Errorf("failed to update account: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
@@ -0,0 +1,154 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	accountDomain "Finance-Manager-System/internal/infrastructure/modules/account/domain"
+	"Finance-Manager-System/internal/infrastructure/modules/statement"
+	transactionDomain "Finance-Manager-System/internal/infrastructure/modules/transactions/domain"
+)
+
+func TestReconcileAccountReportsAndClosesDrift(t *testing.T) {
+	userID := uuid.New()
+	day := time.Date(2026, 5, 10, 10, 0, 0, 0, time.UTC)
+	parser := &fakeStatementParser{stmt: &statement.Statement{
+		AccountNumber: "40817810000000001234",
+		Currency:      "RUB",
+		Balance:       7000,
+		HasBalance:    true,
+		Transactions: []statement.TransactionEntry{
+			{CompletedAt: day, Amount: 1000, Description: "Coffee"},
+			{CompletedAt: day.Add(time.Hour), Amount: 6000, IsIncome: true, Description: "Salary"},
+		},
+	}}
+	repo := &fakeAccountRepo{}
+	transRepo := &fakeAccountTransRepo{}
+	uc := NewAccountUseCase(repo, &fakeAccountCatRepo{}, transRepo, nil, statement.NewRegistry(parser), &fakeTxManager{})
+
+	imported, err := uc.ImportAccount(context.Background(), userID, "", "", statement.Source{Filename: "export.csv", Data: []byte("x")})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	// The first statement fixes the opening balance so the ledger starts reconciled.
+	if repo.account.OpeningBalance != 2000 || len(repo.snapshots) != 1 || repo.snapshots[0].Source != accountDomain.SnapshotStatement {
+		t.Fatalf("unexpected opening balance %d and snapshots %+v", repo.account.OpeningBalance, repo.snapshots)
+	}
+
+	// A transaction that never reached the stored balance.
+	transRepo.added = append(transRepo.added, &transactionDomain.Transaction{AccountID: imported.AccountID, Amount: 300, CompletedAt: day.AddDate(0, 0, 2)})
+
+	if _, err := uc.ReconcileAccount(context.Background(), userID, imported.AccountID, accountDomain.ReconcileParams{Reference: "bank"}); !errors.Is(err, accountDomain.ErrInvalidReconcileRef) {
+		t.Fatalf("expected ErrInvalidReconcileRef, got %v", err)
+	}
+
+	report, err := uc.ReconcileAccount(context.Background(), userID, imported.AccountID, accountDomain.ReconcileParams{})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if report.ComputedBalance != 6700 || report.StoredBalance != 7000 || report.Drift != 300 || report.AdjustmentTransactionID != nil {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	if report.Statement == nil || report.Statement.ComputedBalance != 7000 || report.Statement.Drift != 0 {
+		t.Fatalf("statement must match the ledger on its date: %+v", report.Statement)
+	}
+
+	fixed, err := uc.ReconcileAccount(context.Background(), userID, imported.AccountID, accountDomain.ReconcileParams{PostAdjustment: true})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if fixed.AdjustmentTransactionID == nil || fixed.AdjustmentAmount != 300 || fixed.Drift != 0 || fixed.ComputedBalance != 7000 {
+		t.Fatalf("unexpected adjustment: %+v", fixed)
+	}
+	adjustment := transRepo.added[len(transRepo.added)-1]
+	if !adjustment.IsIncome || adjustment.Amount != 300 || adjustment.NameTransaction != adjustmentName || repo.account.Balance != 7000 {
+		t.Fatalf("unexpected adjusting entry %+v, balance %d", adjustment, repo.account.Balance)
+	}
+	if !adjustment.IsAdjustment || adjustment.CategoryID != nil {
+		t.Fatalf("adjusting entry must be kept out of reports and budgets: %+v", adjustment)
+	}
+	if latest := repo.snapshots[len(repo.snapshots)-1]; latest.Source != accountDomain.SnapshotReconciliation || latest.Balance != 7000 {
+		t.Fatalf("unexpected reconciliation snapshot: %+v", latest)
+	}
+
+	again, err := uc.ReconcileAccount(context.Background(), userID, imported.AccountID, accountDomain.ReconcileParams{PostAdjustment: true})
+	if err != nil || again.AdjustmentTransactionID != nil || again.Drift != 0 {
+		t.Fatalf("reconciled account must not get another entry: %+v %v", again, err)
+	}
+}
+
+func TestGetBalanceHistory(t *testing.T) {
+	userID := uuid.New()
+	accountID := uuid.New()
+	day := time.Date(2026, 5, 10, 0, 0, 0, 0, time.UTC)
+	repo := &fakeAccountRepo{account: &accountDomain.Account{AccountID: accountID, Currency: "RUB", Balance: 1500, OpeningBalance: 1000}}
+	transRepo := &fakeAccountTransRepo{added: []*transactionDomain.Transaction{
+		{AccountID: accountID, Amount: 200, CompletedAt: day.AddDate(0, 0, -5)},
+		{AccountID: accountID, Amount: 900, IsIncome: true, CompletedAt: day.Add(12 * time.Hour)},
+		{AccountID: accountID, Amount: 400, IsHidden: true, CompletedAt: day.Add(13 * time.Hour)},
+		{AccountID: accountID, Amount: 200, CompletedAt: day.AddDate(0, 0, 1)},
+	}}
+	uc := NewAccountUseCase(repo, &fakeAccountCatRepo{}, transRepo, nil, statement.NewRegistry(), &fakeTxManager{})
+
+	from, to := day.AddDate(0, 0, -1), day.AddDate(0, 0, 1)
+	history, err := uc.GetBalanceHistory(context.Background(), userID, accountID, &from, &to)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	want := []int64{800, 1700, 1500}
+	if len(history.Points) != len(want) || history.StoredBalance != 1500 || history.Currency != "RUB" {
+		t.Fatalf("unexpected history: %+v", history)
+	}
+	for i, point := range history.Points {
+		if point.Balance != want[i] {
+			t.Fatalf("point %d: expected %d, got %d", i, want[i], point.Balance)
+		}
+	}
+
+	if _, err := uc.GetBalanceHistory(context.Background(), userID, accountID, &to, &from); !errors.Is(err, accountDomain.ErrInvalidHistoryRange) {
+		t.Fatalf("expected ErrInvalidHistoryRange, got %v", err)
+	}
+}
+
+func TestAddValuation(t *testing.T) {
+	userID := uuid.New()
+	accountID := uuid.New()
+	now := time.Now().UTC()
+	repo := &fakeAccountRepo{account: &accountDomain.Account{AccountID: accountID, Kind: accountDomain.KindAsset, Balance: 100000}}
+	transRepo := &fakeAccountTransRepo{added: []*transactionDomain.Transaction{
+		{AccountID: accountID, Amount: 5000, CompletedAt: now.AddDate(0, 0, -2)},
+	}}
+	uc := NewAccountUseCase(repo, &fakeAccountCatRepo{}, transRepo, nil, statement.NewRegistry(), &fakeTxManager{})
+
+	future := now.Add(time.Hour)
+	if _, err := uc.AddValuation(context.Background(), userID, accountID, 1, &future); !errors.Is(err, accountDomain.ErrFutureValuation) {
+		t.Fatalf("expected ErrFutureValuation, got %v", err)
+	}
+
+	// The repair paid two days ago comes on top of last week's valuation.
+	weekAgo := now.AddDate(0, 0, -7)
+	snapshot, err := uc.AddValuation(context.Background(), userID, accountID, 900000, &weekAgo)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if snapshot.Source != accountDomain.SnapshotValuation || snapshot.Balance != 900000 || repo.account.Balance != 895000 {
+		t.Fatalf("unexpected valuation %+v, balance %d", snapshot, repo.account.Balance)
+	}
+
+	monthAgo := now.AddDate(0, -1, 0)
+	if _, err := uc.AddValuation(context.Background(), userID, accountID, 1000000, &monthAgo); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if repo.account.Balance != 895000 || len(repo.snapshots) != 2 {
+		t.Fatalf("older valuation must only be recorded, balance %d", repo.account.Balance)
+	}
+
+	repo.account.IsImported = true
+	if _, err := uc.AddValuation(context.Background(), userID, accountID, 1, nil); !errors.Is(err, accountDomain.ErrImportedValuation) {
+		t.Fatalf("expected ErrImportedValuation, got %v", err)
+	}
+}
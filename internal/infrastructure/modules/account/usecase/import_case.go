@@ -0,0 +1,733 @@
+package usecase
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"Finance-Manager-System/internal/infrastructure/modules/account/domain"
+	"Finance-Manager-System/internal/infrastructure/modules/classifier"
+	ruleDomain "Finance-Manager-System/internal/infrastructure/modules/rules/domain"
+	"Finance-Manager-System/internal/infrastructure/modules/statement"
+	transactionDomain "Finance-Manager-System/internal/infrastructure/modules/transactions/domain"
+)
+
+type ImportResult struct {
+	AccountID            uuid.UUID `json:"account_id"`
+	BatchID              uuid.UUID `json:"batch_id"`
+	Format               string    `json:"format"`
+	ImportedTransactions int       `json:"imported_transactions"`
+	SkippedTransactions  int       `json:"skipped_transactions"`
+	Balance              int64     `json:"balance"`
+	AccountNumber        string    `json:"account_number,omitempty"`
+	ContractNumber       string    `json:"contract_number,omitempty"`
+	Warnings             []string  `json:"warnings,omitempty"`
+}
+
+// StagedRowEdit changes one row of a staged import. A nil field is left as
+// is; ClearCategory leaves the row uncategorized.
+type StagedRowEdit struct {
+	Row           int
+	CategoryID    *uuid.UUID
+	ClearCategory bool
+	Excluded      *bool
+}
+
+// UndoImportResult describes what undoing an import batch removed.
+type UndoImportResult struct {
+	BatchID             uuid.UUID `json:"batch_id"`
+	AccountID           uuid.UUID `json:"account_id"`
+	DeletedTransactions int       `json:"deleted_transactions"`
+	Balance             int64     `json:"balance"`
+}
+
+// importFile identifies the uploaded file an import batch was booked from.
+type importFile struct {
+	Name string
+	Hash string
+}
+
+func newImportFile(src statement.Source) importFile {
+	sum := sha256.Sum256(src.Data)
+	return importFile{Name: src.Filename, Hash: hex.EncodeToString(sum[:])}
+}
+
+var (
+	ErrInvalidStatement         = errors.New("invalid bank statement")
+	ErrStatementAccountMismatch = errors.New("statement does not match selected imported account")
+	ErrAccountNotImported       = errors.New("only imported accounts can be synchronized")
+)
+
+func (uc *AccountUseCase) SupportedStatementFormats() []string {
+	return uc.parsers.Names()
+}
+
+// ImportAccount creates an imported account from a bank statement. An empty
+// format lets the parser registry detect it from the file.
+func (uc *AccountUseCase) ImportAccount(ctx context.Context, userID uuid.UUID, customName string, format string, src statement.Source) (*ImportResult, error) {
+	stmt, err := uc.parseStatement(src, format)
+	if err != nil {
+		return nil, err
+	}
+
+	var result *ImportResult
+	err = uc.txManager.RunInTransaction(ctx, func(txCtx context.Context) error {
+		acc, accErr := uc.createImportedAccount(txCtx, userID, customName, stmt)
+		if accErr != nil {
+			return accErr
+		}
+		rows, planErr := uc.planImport(txCtx, userID, acc, stmt)
+		if planErr != nil {
+			return planErr
+		}
+		var commitErr error
+		result, commitErr = uc.commitImport(txCtx, userID, acc, stmt, rows, newImportFile(src))
+		return commitErr
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// SyncImportedAccount adds statement transactions newer than the last sync to
+// an existing imported account and refreshes its balance snapshot.
+func (uc *AccountUseCase) SyncImportedAccount(ctx context.Context, userID uuid.UUID, accountID uuid.UUID, format string, src statement.Source) (*ImportResult, error) {
+	stmt, err := uc.parseStatement(src, format)
+	if err != nil {
+		return nil, err
+	}
+
+	var result *ImportResult
+	err = uc.txManager.RunInTransaction(ctx, func(txCtx context.Context) error {
+		acc, accErr := uc.getSyncTarget(txCtx, userID, accountID, stmt)
+		if accErr != nil {
+			return accErr
+		}
+		rows, planErr := uc.planImport(txCtx, userID, acc, stmt)
+		if planErr != nil {
+			return planErr
+		}
+		var commitErr error
+		result, commitErr = uc.commitImport(txCtx, userID, acc, stmt, rows, newImportFile(src))
+		return commitErr
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// StageImport parses a statement and stores the proposed rows without booking
+// anything. A nil accountID stages the creation of a new imported account.
+func (uc *AccountUseCase) StageImport(ctx context.Context, userID uuid.UUID, accountID *uuid.UUID, customName string, format string, src statement.Source) (*domain.StagedImport, error) {
+	customName = strings.TrimSpace(customName)
+	if accountID == nil && len([]rune(customName)) > domain.MaxAccountNameLength {
+		return nil, domain.ErrAccountNameLong
+	}
+	stmt, err := uc.parseStatement(src, format)
+	if err != nil {
+		return nil, err
+	}
+
+	var acc *domain.Account
+	if accountID != nil {
+		acc, err = uc.getSyncTarget(ctx, userID, *accountID, stmt)
+		if err != nil {
+			return nil, err
+		}
+	}
+	rows, err := uc.planImport(ctx, userID, acc, stmt)
+	if err != nil {
+		return nil, err
+	}
+
+	staged := domain.NewStagedImport(userID, accountID, customName, stmt, rows)
+	file := newImportFile(src)
+	staged.FileName = file.Name
+	staged.FileHash = file.Hash
+	if err := uc.repo.SaveStagedImport(ctx, staged); err != nil {
+		return nil, err
+	}
+	return staged, nil
+}
+
+func (uc *AccountUseCase) GetStagedImport(ctx context.Context, userID uuid.UUID, stagingID uuid.UUID) (*domain.StagedImport, error) {
+	staged, err := uc.repo.GetStagedImport(ctx, userID, stagingID)
+	if err != nil {
+		return nil, err
+	}
+	if staged.IsExpired(time.Now().UTC()) {
+		return nil, domain.ErrStagedImportExpired
+	}
+	return staged, nil
+}
+
+func (uc *AccountUseCase) UpdateStagedImport(ctx context.Context, userID uuid.UUID, stagingID uuid.UUID, edits []StagedRowEdit) (*domain.StagedImport, error) {
+	staged, err := uc.GetStagedImport(ctx, userID, stagingID)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.applyStagedEdits(ctx, userID, staged, edits); err != nil {
+		return nil, err
+	}
+	if err := uc.repo.UpdateStagedImport(ctx, staged); err != nil {
+		return nil, err
+	}
+	return staged, nil
+}
+
+// ConfirmStagedImport applies the final edits and books the staged rows the
+// same way a direct import does. The staged import is removed afterwards.
+func (uc *AccountUseCase) ConfirmStagedImport(ctx context.Context, userID uuid.UUID, stagingID uuid.UUID, edits []StagedRowEdit) (*ImportResult, error) {
+	var result *ImportResult
+	err := uc.txManager.RunInTransaction(ctx, func(txCtx context.Context) error {
+		staged, err := uc.GetStagedImport(txCtx, userID, stagingID)
+		if err != nil {
+			return err
+		}
+		if err := uc.applyStagedEdits(txCtx, userID, staged, edits); err != nil {
+			return err
+		}
+
+		var acc *domain.Account
+		if staged.AccountID == nil {
+			accountName := ""
+			if staged.AccountName != nil {
+				accountName = *staged.AccountName
+			}
+			acc, err = uc.createImportedAccount(txCtx, userID, accountName, staged.Statement)
+		} else {
+			acc, err = uc.getSyncTarget(txCtx, userID, *staged.AccountID, staged.Statement)
+		}
+		if err != nil {
+			return err
+		}
+
+		file := importFile{Name: staged.FileName, Hash: staged.FileHash}
+		result, err = uc.commitImport(txCtx, userID, acc, staged.Statement, staged.Rows, file)
+		if err != nil {
+			return err
+		}
+		return uc.repo.DeleteStagedImport(txCtx, userID, stagingID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// DiscardStagedImport removes a staged import that has not expired yet;
+// expired ones are already gone.
+func (uc *AccountUseCase) DiscardStagedImport(ctx context.Context, userID uuid.UUID, stagingID uuid.UUID) error {
+	return uc.repo.DeleteStagedImport(ctx, userID, stagingID)
+}
+
+func (uc *AccountUseCase) GetImportBatches(ctx context.Context, userID uuid.UUID, accountID uuid.UUID) ([]domain.ImportBatch, error) {
+	if _, err := uc.repo.GetAccountByID(ctx, userID, accountID); err != nil {
+		return nil, fmt.Errorf("account not found: %w", err)
+	}
+	return uc.repo.GetImportBatches(ctx, userID, accountID)
+}
+
+// UndoImportBatch removes the transactions booked by the latest import batch
+// of an account and puts the balance and sync time back to what they were
+// before it, so the same statement can be imported again. Older batches
+// cannot be undone: the balances of the batches after them were taken from
+// statements that already include their rows. Undoing the first import of an
+// account also resets the opening balance it fixed.
+func (uc *AccountUseCase) UndoImportBatch(ctx context.Context, userID uuid.UUID, accountID uuid.UUID, batchID uuid.UUID) (*UndoImportResult, error) {
+	var result *UndoImportResult
+	err := uc.txManager.RunInTransaction(ctx, func(txCtx context.Context) error {
+		batch, err := uc.repo.GetImportBatch(txCtx, userID, batchID)
+		if err != nil {
+			return err
+		}
+		if batch.AccountID != accountID {
+			return domain.ErrImportBatchNotFound
+		}
+
+		acc, err := uc.repo.GetAccountByID(txCtx, userID, accountID)
+		if err != nil {
+			return fmt.Errorf("account not found: %w", err)
+		}
+		if !acc.IsImported {
+			return ErrAccountNotImported
+		}
+
+		batches, err := uc.repo.GetImportBatches(txCtx, userID, accountID)
+		if err != nil {
+			return err
+		}
+		if len(batches) == 0 || batches[0].BatchID != batch.BatchID {
+			return domain.ErrImportBatchNotLatest
+		}
+
+		deleted, err := uc.transRepo.DeleteTransactionsByImportBatch(txCtx, userID, batchID)
+		if err != nil {
+			return err
+		}
+		balance := acc.Balance - batch.BalanceDelta()
+		if err := uc.repo.RevertImportedAccountSnapshot(txCtx, userID, accountID, balance, batch.PreviousSyncedAt); err != nil {
+			return err
+		}
+		if batch.PreviousSyncedAt == nil {
+			ledger, err := uc.transRepo.GetAccountLedger(txCtx, userID, accountID, nil)
+			if err != nil {
+				return err
+			}
+			if err := uc.repo.SetOpeningBalance(txCtx, userID, accountID, balance-ledger); err != nil {
+				return err
+			}
+		}
+		if err := uc.repo.DeleteImportBatch(txCtx, userID, batchID); err != nil {
+			return err
+		}
+
+		result = &UndoImportResult{
+			BatchID:             batchID,
+			AccountID:           accountID,
+			DeletedTransactions: deleted,
+			Balance:             balance,
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// recordStatementBalance keeps the closing balance of the statement as a
+// snapshot. The first statement of an account also fixes its opening balance
+// from the new account balance, so that the ledger of a new imported account
+// starts reconciled.
+func (uc *AccountUseCase) recordStatementBalance(ctx context.Context, userID uuid.UUID, acc *domain.Account, batch *domain.ImportBatch, statementBalance int64, balance int64) error {
+	if acc.LastSyncedAt == nil {
+		ledger, err := uc.transRepo.GetAccountLedger(ctx, userID, acc.AccountID, nil)
+		if err != nil {
+			return err
+		}
+		if err := uc.repo.SetOpeningBalance(ctx, userID, acc.AccountID, balance-ledger); err != nil {
+			return err
+		}
+	}
+	takenAt := batch.CreatedAt
+	if batch.PeriodEnd != nil {
+		takenAt = *batch.PeriodEnd
+	}
+	snapshot := domain.NewBalanceSnapshot(userID, acc.AccountID, domain.SnapshotStatement, statementBalance, takenAt)
+	snapshot.BatchID = &batch.BatchID
+	return uc.repo.AddBalanceSnapshot(ctx, snapshot)
+}
+
+func (uc *AccountUseCase) parseStatement(src statement.Source, format string) (*statement.Statement, error) {
+	stmt, err := uc.parsers.Parse(src, strings.TrimSpace(format))
+	if err != nil {
+		if errors.Is(err, statement.ErrUnknownFormat) || errors.Is(err, statement.ErrParserNotFound) || errors.Is(err, statement.ErrEmptySource) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: %w", ErrInvalidStatement, err)
+	}
+	return stmt, nil
+}
+
+func (uc *AccountUseCase) createImportedAccount(ctx context.Context, userID uuid.UUID, customName string, stmt *statement.Statement) (*domain.Account, error) {
+	if stmt.AccountNumber == "" {
+		stmt.AccountNumber = stmt.ContractNumber
+	}
+	if stmt.AccountNumber == "" {
+		stmt.AccountNumber = stmt.Format
+	}
+	if stmt.Currency == "" {
+		stmt.Currency = "RUB"
+	}
+	if stmt.AccountType == "" {
+		stmt.AccountType = "imported"
+	}
+
+	accountName := customName
+	if accountName == "" {
+		accountName = buildImportedAccountName(stmt)
+	}
+
+	// The account opens empty; commitImport sets the snapshot balance and
+	// records the change on the import batch.
+	externalID := stmt.AccountNumber
+	acc, err := domain.NewAccount(
+		userID,
+		accountName,
+		stmt.Currency,
+		stmt.AccountType,
+		stmt.ColorHex,
+		true,
+		&externalID,
+		0,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	accountID, err := uc.repo.AddAccount(ctx, acc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save account: %w", err)
+	}
+	acc.AccountID = accountID
+	return acc, nil
+}
+
+func (uc *AccountUseCase) getSyncTarget(ctx context.Context, userID uuid.UUID, accountID uuid.UUID, stmt *statement.Statement) (*domain.Account, error) {
+	acc, err := uc.repo.GetAccountByID(ctx, userID, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("account not found: %w", err)
+	}
+	if !acc.IsImported {
+		return nil, ErrAccountNotImported
+	}
+	statementExternalID := strings.TrimSpace(stmt.AccountNumber)
+	if statementExternalID == "" {
+		statementExternalID = strings.TrimSpace(stmt.ContractNumber)
+	}
+	accountExternalID := ""
+	if acc.ExternalAccountID != nil {
+		accountExternalID = strings.TrimSpace(*acc.ExternalAccountID)
+	}
+	if statementExternalID != "" && accountExternalID != "" && statementExternalID != accountExternalID {
+		return nil, ErrStatementAccountMismatch
+	}
+	if stmt.Currency != "" && !strings.EqualFold(stmt.Currency, acc.Currency) {
+		return nil, ErrStatementAccountMismatch
+	}
+	return acc, nil
+}
+
+// planImport proposes a category for every statement entry and flags rows
+// that will not be booked. acc is nil when the account does not exist yet.
+func (uc *AccountUseCase) planImport(ctx context.Context, userID uuid.UUID, acc *domain.Account, stmt *statement.Statement) ([]domain.StagedImportRow, error) {
+	categories, err := uc.catRepo.GetCategoriesByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	categoryNames := make(map[uuid.UUID]string, len(categories))
+	for _, cat := range categories {
+		categoryNames[cat.CategoryID] = cat.NameCategory
+	}
+
+	existing := map[string]bool{}
+	if acc != nil && acc.AccountID != uuid.Nil {
+		externalIDs := make([]string, 0, len(stmt.Transactions))
+		for _, entry := range stmt.Transactions {
+			if entry.ExternalID != nil {
+				externalIDs = append(externalIDs, *entry.ExternalID)
+			}
+		}
+		existing, err = uc.transRepo.GetExistingExternalIDs(ctx, userID, acc.AccountID, externalIDs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check duplicates: %w", err)
+		}
+	}
+
+	outcomes, err := uc.evaluateRules(ctx, userID, acc, stmt)
+	if err != nil {
+		return nil, err
+	}
+
+	var model *classifier.Model
+	seen := make(map[string]bool, len(stmt.Transactions))
+	rows := make([]domain.StagedImportRow, 0, len(stmt.Transactions))
+	for i, entry := range stmt.Transactions {
+		row := domain.StagedImportRow{
+			Row:         i,
+			CompletedAt: entry.CompletedAt,
+			Amount:      entry.Amount,
+			IsIncome:    entry.IsIncome,
+			Description: entry.Description,
+			MCCCode:     entry.MCCCode,
+			ExternalID:  entry.ExternalID,
+			Warnings:    append([]string(nil), entry.Warnings...),
+		}
+		if entry.ExternalID != nil {
+			row.Duplicate = existing[*entry.ExternalID] || seen[*entry.ExternalID]
+			seen[*entry.ExternalID] = true
+		}
+		if acc != nil && acc.LastSyncedAt != nil && !entry.CompletedAt.After(acc.LastSyncedAt.UTC()) {
+			row.Excluded = true
+			row.Warnings = append(row.Warnings, "not newer than the last synchronization")
+		}
+		if strings.TrimSpace(entry.Description) == "" || entry.Amount <= 0 {
+			row.Excluded = true
+			row.Warnings = append(row.Warnings, "row has no description or amount")
+		}
+
+		// User rules come first, then rules learned from corrections, then
+		// keyword matching.
+		var categoryID *uuid.UUID
+		source := transactionDomain.SourceRule
+		if outcomes != nil {
+			outcome := outcomes[i]
+			categoryID = outcome.CategoryID
+			row.IsHidden = outcome.Hide
+			row.TagIDs = outcome.TagIDs
+		}
+		if categoryID == nil {
+			source = transactionDomain.SourceLearned
+			categoryID, err = uc.transRepo.ResolveAutoCategoryID(ctx, userID, entry.IsIncome, entry.MCCCode, entry.Description)
+			if err != nil {
+				return nil, fmt.Errorf("failed to resolve auto category: %w", err)
+			}
+		}
+		if categoryID == nil {
+			if model == nil {
+				if model, err = uc.trainClassifier(ctx, userID); err != nil {
+					return nil, err
+				}
+			}
+			// Once the user has enough history the classifier replaces the
+			// keyword switch, and unsure predictions are left for review.
+			if prediction, ok := model.Predict(entry.Description, entry.MCCCode, entry.Amount, entry.IsIncome); ok {
+				confidence := math.Round(prediction.Confidence*1000) / 1000
+				row.Confidence = &confidence
+				if _, known := categoryNames[prediction.CategoryID]; known && prediction.Confidence >= classifier.DefaultThreshold {
+					categoryID = &prediction.CategoryID
+					source = transactionDomain.SourceClassifier
+				}
+			} else {
+				categoryID, source = resolveCategory(categories, entry.Description, entry.IsIncome, entry.MCCCode)
+			}
+		}
+		if categoryID != nil {
+			row.CategoryID = categoryID
+			row.CategoryName = categoryNames[*categoryID]
+			row.CategorySource = source
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func (uc *AccountUseCase) trainClassifier(ctx context.Context, userID uuid.UUID) (*classifier.Model, error) {
+	samples, err := uc.transRepo.GetClassifierSamples(ctx, userID, classifier.MaxSamples)
+	if err != nil {
+		return nil, fmt.Errorf("failed to train classifier: %w", err)
+	}
+	return classifier.Train(samples), nil
+}
+
+func (uc *AccountUseCase) evaluateRules(ctx context.Context, userID uuid.UUID, acc *domain.Account, stmt *statement.Statement) ([]ruleDomain.Outcome, error) {
+	if uc.rules == nil {
+		return nil, nil
+	}
+	var accountID uuid.UUID
+	if acc != nil {
+		accountID = acc.AccountID
+	}
+	targets := make([]ruleDomain.Target, len(stmt.Transactions))
+	for i, entry := range stmt.Transactions {
+		targets[i] = ruleDomain.Target{
+			AccountID:   accountID,
+			Description: entry.Description,
+			MCCCode:     entry.MCCCode,
+			Amount:      entry.Amount,
+			IsIncome:    entry.IsIncome,
+		}
+	}
+	outcomes, err := uc.rules.EvaluateTargets(ctx, userID, targets)
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate rules: %w", err)
+	}
+	return outcomes, nil
+}
+
+// commitImport books the rows that are not excluded on acc as one import
+// batch and refreshes the balance snapshot. Must run inside a transaction.
+func (uc *AccountUseCase) commitImport(ctx context.Context, userID uuid.UUID, acc *domain.Account, stmt *statement.Statement, rows []domain.StagedImportRow, file importFile) (*ImportResult, error) {
+	batch := &domain.ImportBatch{
+		BatchID:          uuid.New(),
+		UserID:           userID,
+		AccountID:        acc.AccountID,
+		Format:           stmt.Format,
+		FileName:         file.Name,
+		FileHash:         file.Hash,
+		BalanceBefore:    acc.Balance,
+		PreviousSyncedAt: acc.LastSyncedAt,
+		CreatedAt:        time.Now().UTC(),
+	}
+	for _, entry := range stmt.Transactions {
+		batch.Include(entry.CompletedAt)
+	}
+
+	trans := make([]*transactionDomain.Transaction, 0, len(rows))
+	tags := make(map[uuid.UUID][]uuid.UUID)
+	skippedCount := len(stmt.Transactions) - len(rows)
+	for _, row := range rows {
+		if row.Excluded || row.Row < 0 || row.Row >= len(stmt.Transactions) {
+			skippedCount++
+			continue
+		}
+		rawTx := stmt.Transactions[row.Row]
+		tx, txErr := transactionDomain.NewTransaction(
+			userID,
+			acc.AccountID,
+			row.CategoryID,
+			rawTx.Description,
+			rawTx.IsIncome,
+			rawTx.Amount,
+			rawTx.CompletedAt,
+			true,
+			nil,
+		)
+		if txErr != nil {
+			skippedCount++
+			continue
+		}
+		tx.Currency = acc.Currency
+		tx.Status = "completed"
+		tx.BankFee = rawTx.BankFee
+		tx.MCCCode = rawTx.MCCCode
+		tx.SenderAccount = rawTx.SenderAccount
+		tx.ReceiverAccount = rawTx.ReceiverAccount
+		tx.ExternalTransactionID = rawTx.ExternalID
+		tx.ImportBatchID = &batch.BatchID
+		tx.IsHidden = row.IsHidden
+		if row.CategoryID != nil {
+			tx.CategorySource = row.CategorySource
+			if row.CategorySource == transactionDomain.SourceClassifier {
+				tx.CategoryConfidence = row.Confidence
+			}
+		}
+		if len(row.TagIDs) > 0 {
+			tx.TransactionID = uuid.New()
+			tags[tx.TransactionID] = row.TagIDs
+		}
+		trans = append(trans, tx)
+	}
+
+	importedCount := 0
+	balance := stmt.Balance
+	switch {
+	case len(trans) == 0:
+		if !stmt.HasBalance {
+			balance = acc.Balance
+		}
+	case stmt.HasBalance:
+		var insertErr error
+		importedCount, insertErr = uc.transRepo.AddTransactions(ctx, trans)
+		if insertErr != nil {
+			return nil, fmt.Errorf("failed to import transactions: %w", insertErr)
+		}
+		// The statement balance still counts the rows hidden by rules or by
+		// the user, which the account balance leaves out.
+		hidden, err := uc.transRepo.GetHiddenImportedLedger(ctx, userID, acc.AccountID)
+		if err != nil {
+			return nil, err
+		}
+		balance -= hidden
+	default:
+		// Without a closing balance in the statement the account balance is
+		// moved by the rows that were actually inserted, so rows are inserted
+		// one by one to tell them apart from external-ID duplicates.
+		balance = acc.Balance
+		for _, tx := range trans {
+			inserted, insertErr := uc.transRepo.AddTransactions(ctx, []*transactionDomain.Transaction{tx})
+			if insertErr != nil {
+				return nil, fmt.Errorf("failed to import transactions: %w", insertErr)
+			}
+			if inserted == 0 {
+				continue
+			}
+			importedCount++
+			if tx.IsHidden {
+				continue
+			}
+			if tx.IsIncome {
+				balance += tx.Amount
+			} else {
+				balance -= tx.Amount
+			}
+		}
+	}
+
+	// Tags of rows skipped as duplicates point at transactions that were
+	// never inserted and are ignored by the tag repository.
+	if len(tags) > 0 && uc.rules != nil {
+		if err := uc.rules.TagTransactions(ctx, userID, tags); err != nil {
+			return nil, fmt.Errorf("failed to tag imported transactions: %w", err)
+		}
+	}
+
+	if err := uc.repo.UpdateImportedAccountSnapshot(ctx, userID, acc.AccountID, balance); err != nil {
+		return nil, fmt.Errorf("failed to update imported account balance: %w", err)
+	}
+
+	skipped := skippedCount + (len(trans) - importedCount)
+	batch.ImportedCount = importedCount
+	batch.SkippedCount = skipped
+	batch.BalanceAfter = balance
+	if err := uc.repo.AddImportBatch(ctx, batch); err != nil {
+		return nil, err
+	}
+	if stmt.HasBalance {
+		if err := uc.recordStatementBalance(ctx, userID, acc, batch, stmt.Balance, balance); err != nil {
+			return nil, err
+		}
+	}
+
+	return &ImportResult{
+		AccountID:            acc.AccountID,
+		BatchID:              batch.BatchID,
+		Format:               stmt.Format,
+		ImportedTransactions: importedCount,
+		SkippedTransactions:  skipped,
+		Balance:              balance,
+		AccountNumber:        stmt.AccountNumber,
+		ContractNumber:       stmt.ContractNumber,
+		Warnings:             stmt.Warnings,
+	}, nil
+}
+
+func (uc *AccountUseCase) applyStagedEdits(ctx context.Context, userID uuid.UUID, staged *domain.StagedImport, edits []StagedRowEdit) error {
+	if len(edits) == 0 {
+		return nil
+	}
+	categories, err := uc.catRepo.GetCategoriesByUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	categoryNames := make(map[uuid.UUID]string, len(categories))
+	for _, cat := range categories {
+		categoryNames[cat.CategoryID] = cat.NameCategory
+	}
+
+	for _, edit := range edits {
+		row, err := staged.Row(edit.Row)
+		if err != nil {
+			return fmt.Errorf("%w: %d", err, edit.Row)
+		}
+		if edit.Excluded != nil {
+			row.Excluded = *edit.Excluded
+		}
+		switch {
+		case edit.ClearCategory:
+			row.CategoryID = nil
+			row.CategoryName = ""
+			row.CategorySource = transactionDomain.SourceManual
+		case edit.CategoryID != nil:
+			name, ok := categoryNames[*edit.CategoryID]
+			if !ok {
+				return domain.ErrStagedCategoryNotFound
+			}
+			categoryID := *edit.CategoryID
+			row.CategoryID = &categoryID
+			row.CategoryName = name
+			row.CategorySource = transactionDomain.SourceManual
+		}
+	}
+	return nil
+}
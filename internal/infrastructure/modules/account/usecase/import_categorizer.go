@@ -7,22 +7,38 @@ import (
 	"github.com/google/uuid"
 
 	categoryDomain "Finance-Manager-System/internal/infrastructure/modules/category/domain"
-	"Finance-Manager-System/internal/infrastructure/modules/tbankpdf"
+	"Finance-Manager-System/internal/infrastructure/modules/statement"
+	transactionDomain "Finance-Manager-System/internal/infrastructure/modules/transactions/domain"
 )
 
-func buildImportedAccountName(statement *tbankpdf.Statement) string {
-	base := "Т-Банк счет"
-	if statement.AccountNumber == "" {
+func buildImportedAccountName(stmt *statement.Statement) string {
+	base := "Импортированный счет"
+	if stmt.BankName != "" {
+		base = stmt.BankName + " счет"
+	}
+	if stmt.AccountNumber == "" || stmt.AccountNumber == stmt.Format {
 		return base
 	}
-	if len(statement.AccountNumber) <= 4 {
-		return base + " " + statement.AccountNumber
+	if len(stmt.AccountNumber) <= 4 {
+		return base + " " + stmt.AccountNumber
 	}
-	return base + " *" + statement.AccountNumber[len(statement.AccountNumber)-4:]
+	return base + " *" + stmt.AccountNumber[len(stmt.AccountNumber)-4:]
 }
 
 func resolveCategoryID(categories []categoryDomain.Category, description string, isIncome bool, mccCode *string) *uuid.UUID {
+	categoryID, _ := resolveCategory(categories, description, isIncome, mccCode)
+	return categoryID
+}
+
+// resolveCategory picks a category with the built-in MCC map and keywords and
+// reports which of them matched. A category picked because nothing matched is
+// reported as a fallback.
+func resolveCategory(categories []categoryDomain.Category, description string, isIncome bool, mccCode *string) (*uuid.UUID, transactionDomain.CategorySource) {
 	categoryName := classifyCategoryName(description, isIncome, mccCode)
+	source := transactionDomain.SourceKeyword
+	if mccCode != nil && mapMCCToCategory(*mccCode, isIncome) != "" {
+		source = transactionDomain.SourceMCC
+	}
 	normalizedTarget := normalizeKey(categoryName)
 	normalizedFallback := normalizeKey("Другое")
 
@@ -53,12 +69,18 @@ func resolveCategoryID(categories []categoryDomain.Category, description string,
 	}
 
 	if targetID != nil {
-		return targetID
+		if normalizedTarget == normalizedFallback {
+			source = transactionDomain.SourceFallback
+		}
+		return targetID, source
 	}
 	if fallbackID != nil {
-		return fallbackID
+		return fallbackID, transactionDomain.SourceFallback
+	}
+	if anyTypeID != nil {
+		return anyTypeID, transactionDomain.SourceFallback
 	}
-	return anyTypeID
+	return nil, transactionDomain.SourceUnknown
 }
 
 func classifyCategoryName(description string, isIncome bool, mccCode *string) string {
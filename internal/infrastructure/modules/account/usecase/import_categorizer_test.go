@@ -6,6 +6,7 @@ import (
 	"github.com/google/uuid"
 
 	categoryDomain "Finance-Manager-System/internal/infrastructure/modules/category/domain"
+	transactionDomain "Finance-Manager-System/internal/infrastructure/modules/transactions/domain"
 )
 
 func TestResolveCategoryIDByMCCFallback(t *testing.T) {
@@ -35,3 +36,27 @@ func TestResolveCategoryIDByKeyword(t *testing.T) {
 	}
 }
 
+func TestResolveCategorySource(t *testing.T) {
+	userID := uuid.New()
+	cats := []categoryDomain.Category{
+		{CategoryID: uuid.New(), UserID: userID, NameCategory: "Продукты", IsIncome: false},
+		{CategoryID: uuid.New(), UserID: userID, NameCategory: "Другое", IsIncome: false},
+	}
+	mcc := "5411"
+	cases := []struct {
+		description string
+		mcc         *string
+		want        transactionDomain.CategorySource
+	}{
+		{"Random text", &mcc, transactionDomain.SourceMCC},
+		{"Perekrestok", nil, transactionDomain.SourceKeyword},
+		{"Random text", nil, transactionDomain.SourceFallback},
+		// The keyword matched a category the user does not have.
+		{"Burger place", nil, transactionDomain.SourceFallback},
+	}
+	for _, tc := range cases {
+		if _, got := resolveCategory(cats, tc.description, false, tc.mcc); got != tc.want {
+			t.Fatalf("%s: expected %q, got %q", tc.description, tc.want, got)
+		}
+	}
+}
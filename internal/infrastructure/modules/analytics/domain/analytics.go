@@ -7,34 +7,52 @@ import (
 )
 
 type SummaryReport struct {
-	TotalIncome  int64 `db:"total_income" json:"total_income"`
-	TotalExpense int64 `db:"total_expense" json:"total_expense"`
+	TotalIncome  int64  `db:"total_income" json:"total_income"`
+	TotalExpense int64  `db:"total_expense" json:"total_expense"`
+	Currency     string `db:"currency" json:"currency"`
+	// UnconvertedCurrencies had no rate to the base currency and were left
+	// out of the amounts.
+	UnconvertedCurrencies []string `db:"-" json:"unconverted_currencies,omitempty"`
 }
 
 type CategoryReport struct {
-	CategoryID   *uuid.UUID `db:"category_id" json:"category_id"`
-	CategoryName string     `db:"category_name" json:"category_name"`
-	IconURL      *string    `db:"icon_url" json:"icon_url,omitempty"`
-	TotalAmount  int64      `db:"total_amount" json:"total_amount"`
-	SharePercent float64    `db:"share_percent" json:"share_percent"`
+	CategoryID            *uuid.UUID `db:"category_id" json:"category_id"`
+	CategoryName          string     `db:"category_name" json:"category_name"`
+	IconURL               *string    `db:"icon_url" json:"icon_url,omitempty"`
+	TotalAmount           int64      `db:"total_amount" json:"total_amount"`
+	SharePercent          float64    `db:"share_percent" json:"share_percent"`
+	UnconvertedCurrencies []string   `db:"-" json:"unconverted_currencies,omitempty"`
+}
+
+type TagReport struct {
+	TagID                 uuid.UUID `db:"tag_id" json:"tag_id"`
+	TagName               string    `db:"tag_name" json:"tag_name"`
+	Color                 *string   `db:"color" json:"color,omitempty"`
+	TotalIncome           int64     `db:"total_income" json:"total_income"`
+	TotalExpense          int64     `db:"total_expense" json:"total_expense"`
+	TransactionsCount     int64     `db:"transactions_count" json:"transactions_count"`
+	UnconvertedCurrencies []string  `db:"-" json:"unconverted_currencies,omitempty"`
 }
 
 type DailyReport struct {
-	Date        time.Time `db:"date" json:"date"`
-	TotalAmount int64     `db:"total_amount" json:"total_amount"`
+	Date                  time.Time `db:"date" json:"date"`
+	TotalAmount           int64     `db:"total_amount" json:"total_amount"`
+	UnconvertedCurrencies []string  `db:"-" json:"unconverted_currencies,omitempty"`
 }
 
 type MonthlyReport struct {
-	Month       time.Time `db:"month" json:"month"`
-	TotalAmount int64     `db:"total_amount" json:"total_amount"`
+	Month                 time.Time `db:"month" json:"month"`
+	TotalAmount           int64     `db:"total_amount" json:"total_amount"`
+	UnconvertedCurrencies []string  `db:"-" json:"unconverted_currencies,omitempty"`
 }
 
 type CategoryCompareReport struct {
-	CategoryID         *uuid.UUID `json:"category_id"`
-	CategoryName       string     `json:"category_name"`
-	IconURL            *string    `json:"icon_url,omitempty"`
-	FirstPeriodAmount  int64      `json:"first_period_amount"`
-	SecondPeriodAmount int64      `json:"second_period_amount"`
-	DeltaAmount        int64      `json:"delta_amount"`
-	DeltaPercent       *float64   `json:"delta_percent,omitempty"`
+	CategoryID            *uuid.UUID `json:"category_id"`
+	CategoryName          string     `json:"category_name"`
+	IconURL               *string    `json:"icon_url,omitempty"`
+	FirstPeriodAmount     int64      `json:"first_period_amount"`
+	SecondPeriodAmount    int64      `json:"second_period_amount"`
+	DeltaAmount           int64      `json:"delta_amount"`
+	DeltaPercent          *float64   `json:"delta_percent,omitempty"`
+	UnconvertedCurrencies []string   `db:"-" json:"unconverted_currencies,omitempty"`
 }
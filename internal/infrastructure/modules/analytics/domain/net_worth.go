@@ -0,0 +1,286 @@
+package domain
+
+import (
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	IntervalDay   = "day"
+	IntervalWeek  = "week"
+	IntervalMonth = "month"
+
+	MaxNetWorthPoints = 400
+)
+
+const kindLiability = "liability"
+
+// NetWorthAccount is an account as net worth sees it. Since is the first day
+// the account has any balance: its creation or its first transaction or
+// snapshot, whichever is earlier.
+type NetWorthAccount struct {
+	AccountID  uuid.UUID  `db:"account_id"`
+	Name       string     `db:"name_account"`
+	Kind       string     `db:"kind"`
+	Currency   string     `db:"currency"`
+	Balance    int64      `db:"balance"`
+	IsArchived bool       `db:"is_archived"`
+	ArchivedAt *time.Time `db:"archived_at"`
+	Since      time.Time  `db:"since"`
+}
+
+// BalanceAnchor is a balance known at a moment: a statement, manual,
+// valuation or reconciliation snapshot.
+type BalanceAnchor struct {
+	AccountID uuid.UUID `db:"account_id"`
+	TakenAt   time.Time `db:"taken_at"`
+	Balance   int64     `db:"balance"`
+}
+
+// AccountDailyFlow is the net change of an account on a UTC day from its
+// visible transactions.
+type AccountDailyFlow struct {
+	AccountID uuid.UUID `db:"account_id"`
+	Day       time.Time `db:"day"`
+	Amount    int64     `db:"amount"`
+}
+
+type ExchangeRate struct {
+	FromCurrency string    `db:"from_currency"`
+	ToCurrency   string    `db:"to_currency"`
+	RateDate     time.Time `db:"rate_date"`
+	Rate         float64   `db:"rate"`
+}
+
+type NetWorthPoint struct {
+	Date   time.Time `json:"date"`
+	Assets int64     `json:"assets"`
+	// Liabilities is what is owed, as a positive amount.
+	Liabilities int64 `json:"liabilities"`
+	NetWorth    int64 `json:"net_worth"`
+}
+
+type NetWorthAccountValue struct {
+	AccountID uuid.UUID `json:"account_id"`
+	Name      string    `json:"name"`
+	Kind      string    `json:"kind"`
+	Currency  string    `json:"currency"`
+	Balance   int64     `json:"balance"`
+	// BaseBalance is Balance in the base currency of the report.
+	BaseBalance int64 `json:"base_balance"`
+}
+
+type NetWorthReport struct {
+	Currency string          `json:"currency"`
+	Interval string          `json:"interval"`
+	Points   []NetWorthPoint `json:"points"`
+	// Accounts break down the last point.
+	Accounts []NetWorthAccountValue `json:"accounts"`
+	// UnconvertedCurrencies had no rate to the base currency on some date
+	// and were left out of that point.
+	UnconvertedCurrencies []string `json:"unconverted_currencies,omitempty"`
+}
+
+// NetWorthDates steps back from end by interval while the date is not before
+// start, and returns the UTC days oldest first.
+func NetWorthDates(start, end time.Time, interval string) []time.Time {
+	start, end = startOfDay(start), startOfDay(end)
+	var dates []time.Time
+	for i := 0; ; i++ {
+		var day time.Time
+		switch interval {
+		case IntervalDay:
+			day = end.AddDate(0, 0, -i)
+		case IntervalWeek:
+			day = end.AddDate(0, 0, -7*i)
+		default:
+			day = endOfMonthBefore(end, i)
+		}
+		if day.Before(start) {
+			break
+		}
+		dates = append(dates, day)
+	}
+	for i, j := 0, len(dates)-1; i < j; i, j = i+1, j-1 {
+		dates[i], dates[j] = dates[j], dates[i]
+	}
+	return dates
+}
+
+// BuildNetWorth values every account at the end of each date and converts it
+// into base. The balance of an account on a day is taken from its nearest
+// anchor, the latest one up to that day or else the earliest after it, moved
+// by the transactions in between. The stored balance is the anchor for now.
+// An account counts from its Since day; an archived one only before it was
+// archived.
+func BuildNetWorth(base, interval string, dates []time.Time, now time.Time, accounts []NetWorthAccount, anchors []BalanceAnchor, flows []AccountDailyFlow, rates []ExchangeRate) *NetWorthReport {
+	anchorsByAccount := make(map[uuid.UUID][]BalanceAnchor)
+	for _, anchor := range anchors {
+		anchor.TakenAt = startOfDay(anchor.TakenAt)
+		anchorsByAccount[anchor.AccountID] = append(anchorsByAccount[anchor.AccountID], anchor)
+	}
+	flowsByAccount := make(map[uuid.UUID][]AccountDailyFlow)
+	for _, flow := range flows {
+		flow.Day = startOfDay(flow.Day)
+		flowsByAccount[flow.AccountID] = append(flowsByAccount[flow.AccountID], flow)
+	}
+	converter := newRateTable(base, rates)
+
+	report := &NetWorthReport{
+		Currency: base,
+		Interval: interval,
+		Points:   make([]NetWorthPoint, len(dates)),
+		Accounts: make([]NetWorthAccountValue, 0, len(accounts)),
+	}
+	for i, day := range dates {
+		report.Points[i].Date = day
+	}
+
+	for _, acc := range accounts {
+		ledger := newAccountLedger(acc, anchorsByAccount[acc.AccountID], flowsByAccount[acc.AccountID], startOfDay(now))
+		since := startOfDay(acc.Since)
+		for i, day := range dates {
+			if day.Before(since) {
+				continue
+			}
+			if acc.IsArchived && (acc.ArchivedAt == nil || !day.Before(startOfDay(*acc.ArchivedAt))) {
+				continue
+			}
+			value := converter.convert(ledger.balanceAt(day), acc.Currency, day)
+			if acc.Kind == kindLiability {
+				report.Points[i].Liabilities -= value
+			} else {
+				report.Points[i].Assets += value
+			}
+			if i == len(dates)-1 {
+				report.Accounts = append(report.Accounts, NetWorthAccountValue{
+					AccountID:   acc.AccountID,
+					Name:        acc.Name,
+					Kind:        acc.Kind,
+					Currency:    acc.Currency,
+					Balance:     ledger.balanceAt(day),
+					BaseBalance: value,
+				})
+			}
+		}
+	}
+	for i := range report.Points {
+		report.Points[i].NetWorth = report.Points[i].Assets - report.Points[i].Liabilities
+	}
+	report.UnconvertedCurrencies = converter.missingCurrencies()
+	return report
+}
+
+type accountLedger struct {
+	anchors []BalanceAnchor
+	days    []time.Time
+	// running[i] is the sum of the flows up to and including days[i].
+	running []int64
+}
+
+func newAccountLedger(acc NetWorthAccount, anchors []BalanceAnchor, flows []AccountDailyFlow, today time.Time) *accountLedger {
+	anchors = append(anchors, BalanceAnchor{AccountID: acc.AccountID, TakenAt: today, Balance: acc.Balance})
+	sort.SliceStable(anchors, func(i, j int) bool { return anchors[i].TakenAt.Before(anchors[j].TakenAt) })
+	sort.Slice(flows, func(i, j int) bool { return flows[i].Day.Before(flows[j].Day) })
+
+	ledger := &accountLedger{anchors: anchors}
+	var sum int64
+	for _, flow := range flows {
+		sum += flow.Amount
+		if n := len(ledger.days); n > 0 && ledger.days[n-1].Equal(flow.Day) {
+			ledger.running[n-1] = sum
+			continue
+		}
+		ledger.days = append(ledger.days, flow.Day)
+		ledger.running = append(ledger.running, sum)
+	}
+	return ledger
+}
+
+func (l *accountLedger) balanceAt(day time.Time) int64 {
+	// The anchor list always holds the stored balance for today.
+	idx := sort.Search(len(l.anchors), func(i int) bool { return l.anchors[i].TakenAt.After(day) }) - 1
+	if idx < 0 {
+		idx = 0
+	}
+	anchor := l.anchors[idx]
+	return anchor.Balance + l.runningAt(day) - l.runningAt(anchor.TakenAt)
+}
+
+func (l *accountLedger) runningAt(day time.Time) int64 {
+	idx := sort.Search(len(l.days), func(i int) bool { return l.days[i].After(day) }) - 1
+	if idx < 0 {
+		return 0
+	}
+	return l.running[idx]
+}
+
+// rateTable converts amounts into the base currency with the latest rate on
+// or before the day, like the analytics reports do. Amounts without a rate
+// count as zero and their currency is reported as missing.
+type rateTable struct {
+	base    string
+	rates   map[string][]ExchangeRate
+	missing map[string]bool
+}
+
+func newRateTable(base string, rates []ExchangeRate) *rateTable {
+	table := &rateTable{base: base, rates: make(map[string][]ExchangeRate), missing: make(map[string]bool)}
+	for _, rate := range rates {
+		rate.RateDate = startOfDay(rate.RateDate)
+		switch base {
+		case rate.ToCurrency:
+			table.rates[rate.FromCurrency] = append(table.rates[rate.FromCurrency], rate)
+		case rate.FromCurrency:
+			rate.Rate = 1 / rate.Rate
+			table.rates[rate.ToCurrency] = append(table.rates[rate.ToCurrency], rate)
+		}
+	}
+	for _, byCurrency := range table.rates {
+		sort.SliceStable(byCurrency, func(i, j int) bool { return byCurrency[i].RateDate.Before(byCurrency[j].RateDate) })
+	}
+	return table
+}
+
+func (t *rateTable) convert(amount int64, currency string, day time.Time) int64 {
+	if currency == t.base || amount == 0 {
+		return amount
+	}
+	byCurrency := t.rates[currency]
+	idx := sort.Search(len(byCurrency), func(i int) bool { return byCurrency[i].RateDate.After(day) }) - 1
+	if idx < 0 {
+		t.missing[currency] = true
+		return 0
+	}
+	converted := float64(amount) * byCurrency[idx].Rate
+	if converted < 0 {
+		return int64(converted - 0.5)
+	}
+	return int64(converted + 0.5)
+}
+
+func (t *rateTable) missingCurrencies() []string {
+	currencies := make([]string, 0, len(t.missing))
+	for currency := range t.missing {
+		currencies = append(currencies, currency)
+	}
+	sort.Strings(currencies)
+	return currencies
+}
+
+// endOfMonthBefore is the last day of the month i months before day's month,
+// or day itself for i == 0.
+func endOfMonthBefore(day time.Time, i int) time.Time {
+	if i == 0 {
+		return day
+	}
+	firstOfMonth := time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC)
+	return firstOfMonth.AddDate(0, -i+1, -1)
+}
+
+func startOfDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,68 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func day(month time.Month, d int) time.Time {
+	return time.Date(2026, month, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestNetWorthDates(t *testing.T) {
+	got := NetWorthDates(day(2, 10), day(5, 20).Add(15*time.Hour), IntervalMonth)
+	want := []time.Time{day(2, 28), day(3, 31), day(4, 30), day(5, 20)}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected month dates: %v", got)
+	}
+	got = NetWorthDates(day(5, 5), day(5, 20), IntervalWeek)
+	want = []time.Time{day(5, 6), day(5, 13), day(5, 20)}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected week dates: %v", got)
+	}
+}
+
+func TestBuildNetWorth(t *testing.T) {
+	cash, loan, dollars, closed, euros := uuid.New(), uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	archivedAt := day(5, 14).Add(10 * time.Hour)
+	accounts := []NetWorthAccount{
+		{AccountID: cash, Kind: "asset", Currency: "RUB", Balance: 1000, Since: day(4, 1)},
+		{AccountID: loan, Kind: "liability", Currency: "RUB", Balance: -5000, Since: day(4, 1)},
+		{AccountID: dollars, Kind: "asset", Currency: "USD", Balance: 10, Since: day(5, 3)},
+		{AccountID: closed, Kind: "asset", Currency: "RUB", Balance: 300, Since: day(4, 1), IsArchived: true, ArchivedAt: &archivedAt},
+		{AccountID: euros, Kind: "asset", Currency: "EUR", Balance: 7, Since: day(1, 1)},
+	}
+	anchors := []BalanceAnchor{{AccountID: loan, TakenAt: day(5, 1).Add(9 * time.Hour), Balance: -6000}}
+	flows := []AccountDailyFlow{
+		{AccountID: cash, Day: day(5, 18), Amount: -200},
+		{AccountID: cash, Day: day(5, 10), Amount: 500},
+		{AccountID: loan, Day: day(5, 12), Amount: 1000},
+	}
+	rates := []ExchangeRate{
+		{FromCurrency: "RUB", ToCurrency: "USD", RateDate: day(4, 1), Rate: 0.02},
+		{FromCurrency: "USD", ToCurrency: "RUB", RateDate: day(5, 1), Rate: 90},
+	}
+
+	report := BuildNetWorth("RUB", IntervalDay, []time.Time{day(5, 5), day(5, 15), day(5, 20)}, day(5, 20).Add(12*time.Hour), accounts, anchors, flows, rates)
+
+	want := []NetWorthPoint{
+		{Date: day(5, 5), Assets: 700 + 900 + 300, Liabilities: 6000, NetWorth: 1900 - 6000},
+		{Date: day(5, 15), Assets: 1200 + 900, Liabilities: 5000, NetWorth: 2100 - 5000},
+		{Date: day(5, 20), Assets: 1000 + 900, Liabilities: 5000, NetWorth: 1900 - 5000},
+	}
+	if !reflect.DeepEqual(report.Points, want) {
+		t.Fatalf("unexpected points:\n got %+v\nwant %+v", report.Points, want)
+	}
+	if len(report.Accounts) != 4 || report.Accounts[2].BaseBalance != 900 || report.Accounts[2].Balance != 10 {
+		t.Fatalf("archived account must not be in the breakdown: %+v", report.Accounts)
+	}
+	if report.Accounts[3].Balance != 7 || report.Accounts[3].BaseBalance != 0 {
+		t.Fatalf("an account without a rate must not be counted in base: %+v", report.Accounts[3])
+	}
+	if !reflect.DeepEqual(report.UnconvertedCurrencies, []string{"EUR"}) {
+		t.Fatalf("unexpected unconverted currencies: %v", report.UnconvertedCurrencies)
+	}
+}
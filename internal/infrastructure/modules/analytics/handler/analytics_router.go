@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -12,6 +13,7 @@ import (
 
 	"Finance-Manager-System/internal/infrastructure/middleware"
 	"Finance-Manager-System/internal/infrastructure/modules/analytics/usecase"
+	categoryDomain "Finance-Manager-System/internal/infrastructure/modules/category/domain"
 )
 
 type AnalyticsRouter struct {
@@ -27,9 +29,11 @@ func (a *AnalyticsRouter) Route() chi.Router {
 
 	r.Get("/summary", a.GetSummary)
 	r.Get("/categories", a.GetCategories)
+	r.Get("/tags", a.GetTags)
 	r.Get("/daily", a.GetDaily)
 	r.Get("/monthly", a.GetMonthly)
 	r.Get("/compare/categories", a.CompareCategories)
+	r.Get("/net-worth", a.GetNetWorth)
 
 	return r
 }
@@ -73,6 +77,15 @@ func parseAccountIDs(raw string) ([]uuid.UUID, error) {
 	return ids, nil
 }
 
+// parseDepth reads the category roll-up level; 0 or empty keeps leaf categories.
+func parseDepth(r *http.Request) (int, error) {
+	raw := r.URL.Query().Get("depth")
+	if raw == "" {
+		return 0, nil
+	}
+	return strconv.Atoi(raw)
+}
+
 func parseMonth(value string) (time.Time, error) {
 	return time.Parse("2006-01", value)
 }
@@ -130,6 +143,7 @@ func (a *AnalyticsRouter) GetSummary(w http.ResponseWriter, r *http.Request) {
 // @Param end_date query string false "Конечная дата (RFC3339)"
 // @Param period query string false "Период по умолчанию: day/week/month"
 // @Param is_income query boolean false "Тип: доходы(true) или расходы(false)"
+// @Param depth query integer false "Уровень свёртки категорий: 0 — листовые категории, 1 — верхний уровень и т.д."
 // @Param include_hidden query boolean false "Учитывать скрытые транзакции"
 // @Param account_ids query string false "CSV список account_id для фильтра"
 // @Success 200 {array} domain.CategoryReport
@@ -155,7 +169,59 @@ func (a *AnalyticsRouter) GetCategories(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	report, err := a.analyticsUC.GetCategoryReport(r.Context(), userID, start, end, period, isIncome, includeHidden, accountIDs)
+	depth, err := parseDepth(r)
+	if err != nil {
+		http.Error(w, "depth must be an integer", http.StatusBadRequest)
+		return
+	}
+
+	report, err := a.analyticsUC.GetCategoryReport(r.Context(), userID, start, end, period, isIncome, depth, includeHidden, accountIDs)
+	if err != nil {
+		if errors.Is(err, usecase.ErrInvalidPeriod) || errors.Is(err, categoryDomain.ErrInvalidDepth) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// @Summary Получить доходы и расходы по тегам
+// @Description Транзакция с несколькими тегами учитывается в каждом из них
+// @Tags analytics
+// @Security ApiKeyAuth
+// @Produce json
+// @Param start_date query string false "Начальная дата (RFC3339)"
+// @Param end_date query string false "Конечная дата (RFC3339)"
+// @Param period query string false "Период по умолчанию: day/week/month"
+// @Param include_hidden query boolean false "Учитывать скрытые транзакции"
+// @Param account_ids query string false "CSV список account_id для фильтра"
+// @Success 200 {array} domain.TagReport
+// @Router /api/v1/analytics/tags [get]
+func (a *AnalyticsRouter) GetTags(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	start, end, err := parseDates(r)
+	if err != nil {
+		http.Error(w, "invalid start_date or end_date", http.StatusBadRequest)
+		return
+	}
+	period := r.URL.Query().Get("period")
+	includeHidden := r.URL.Query().Get("include_hidden") == "true"
+	accountIDs, err := parseAccountIDs(r.URL.Query().Get("account_ids"))
+	if err != nil {
+		http.Error(w, "account_ids must contain valid UUIDs", http.StatusBadRequest)
+		return
+	}
+
+	report, err := a.analyticsUC.GetTagReport(r.Context(), userID, start, end, period, includeHidden, accountIDs)
 	if err != nil {
 		if errors.Is(err, usecase.ErrInvalidPeriod) {
 			http.Error(w, err.Error(), http.StatusBadRequest)
@@ -270,6 +336,7 @@ func (a *AnalyticsRouter) GetMonthly(w http.ResponseWriter, r *http.Request) {
 // @Param first_month query string true "Первый месяц в формате YYYY-MM"
 // @Param second_month query string true "Второй месяц в формате YYYY-MM"
 // @Param is_income query boolean false "Доходы (true) или расходы (false)"
+// @Param depth query integer false "Уровень свёртки категорий: 0 — листовые категории, 1 — верхний уровень и т.д."
 // @Param include_hidden query boolean false "Учитывать скрытые транзакции"
 // @Param account_ids query string false "CSV список account_id для фильтра"
 // @Success 200 {array} domain.CategoryCompareReport
@@ -307,8 +374,55 @@ func (a *AnalyticsRouter) CompareCategories(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	report, err := a.analyticsUC.CompareCategoriesByMonths(r.Context(), userID, firstMonth, secondMonth, isIncome, includeHidden, accountIDs)
+	depth, err := parseDepth(r)
+	if err != nil {
+		http.Error(w, "depth must be an integer", http.StatusBadRequest)
+		return
+	}
+
+	report, err := a.analyticsUC.CompareCategoriesByMonths(r.Context(), userID, firstMonth, secondMonth, isIncome, depth, includeHidden, accountIDs)
+	if err != nil {
+		if errors.Is(err, categoryDomain.ErrInvalidDepth) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// @Summary Чистый капитал во времени
+// @Description Активы, обязательства и чистый капитал в базовой валюте на конец каждого интервала. Баланс счета на дату считается от ближайшего известного баланса (выписка, ручное изменение, оценка, текущий баланс) с учетом транзакций между ними. Архивные счета учитываются только до даты архивации.
+// @Tags analytics
+// @Security ApiKeyAuth
+// @Produce json
+// @Param start_date query string false "Начальная дата (RFC3339), по умолчанию год назад"
+// @Param end_date query string false "Конечная дата (RFC3339), по умолчанию сейчас"
+// @Param interval query string false "Шаг ряда: day/week/month, по умолчанию month"
+// @Success 200 {object} domain.NetWorthReport
+// @Router /api/v1/analytics/net-worth [get]
+func (a *AnalyticsRouter) GetNetWorth(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	start, end, err := parseDates(r)
+	if err != nil {
+		http.Error(w, "invalid start_date or end_date", http.StatusBadRequest)
+		return
+	}
+
+	report, err := a.analyticsUC.GetNetWorth(r.Context(), userID, start, end, r.URL.Query().Get("interval"))
 	if err != nil {
+		if errors.Is(err, usecase.ErrInvalidPeriod) || errors.Is(err, usecase.ErrInvalidInterval) || errors.Is(err, usecase.ErrTooManyPoints) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -11,6 +11,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
 
+	"Finance-Manager-System/internal/infrastructure/database"
 	"Finance-Manager-System/internal/infrastructure/modules/analytics/domain"
 )
 
@@ -29,18 +30,22 @@ func (r *AnalyticsRepository) GetSummary(
 	includeHidden bool,
 	accountIDs []uuid.UUID,
 ) (*domain.SummaryReport, error) {
+	amount := database.BaseAmount("t.amount")
 	query := `
 		SELECT 
-			COALESCE(SUM(CASE WHEN is_income = true THEN amount ELSE 0 END), 0) AS total_income,
-			COALESCE(SUM(CASE WHEN is_income = false THEN amount ELSE 0 END), 0) AS total_expense
-		FROM Transactions
-		WHERE user_id = $1 AND completed_at >= $2 AND completed_at <= $3
+			COALESCE(SUM(CASE WHEN t.is_income = true THEN ` + amount + ` ELSE 0 END), 0) AS total_income,
+			COALESCE(SUM(CASE WHEN t.is_income = false THEN ` + amount + ` ELSE 0 END), 0) AS total_expense,
+			` + database.UnconvertedCurrencies("t") + ` AS unconverted_currencies,
+			COALESCE((SELECT base_currency FROM Users WHERE user_id = $1), 'RUB') AS currency
+		FROM Transactions t` + database.BaseCurrencyJoin("t") + `
+		WHERE t.user_id = $1 AND t.completed_at >= $2 AND t.completed_at <= $3
 	`
 
 	args := []interface{}{userID, start, end}
 	nextArg := 4
+	query += " AND t.transfer_id IS NULL AND t.is_adjustment = false"
 	if !includeHidden {
-		query += " AND is_hidden = false"
+		query += " AND t.is_hidden = false"
 	}
 	if len(accountIDs) > 0 {
 		placeholders := make([]string, len(accountIDs))
@@ -49,14 +54,19 @@ func (r *AnalyticsRepository) GetSummary(
 			args = append(args, id)
 			nextArg++
 		}
-		query += " AND account_id IN (" + strings.Join(placeholders, ", ") + ")"
+		query += " AND t.account_id IN (" + strings.Join(placeholders, ", ") + ")"
 	}
 
-	var report domain.SummaryReport
-	err := r.db.GetContext(ctx, &report, query, args...)
+	var row struct {
+		domain.SummaryReport
+		Unconverted database.CurrencyList `db:"unconverted_currencies"`
+	}
+	err := r.db.GetContext(ctx, &row, query, args...)
 	if err != nil {
 		return nil, err
 	}
+	report := row.SummaryReport
+	report.UnconvertedCurrencies = row.Unconverted
 	return &report, nil
 }
 
@@ -65,26 +75,38 @@ func (r *AnalyticsRepository) GetByCategory(
 	userID uuid.UUID,
 	start, end time.Time,
 	isIncome bool,
+	depth int,
 	includeHidden bool,
 	accountIDs []uuid.UUID,
 ) ([]domain.CategoryReport, error) {
-	query := `
+	// Split transactions are counted by their parts.
+	amount := database.BaseAmount(database.SplitAmount("t"))
+	category := database.SplitCategory("t")
+	cte, joins := "", database.BaseCurrencyJoin("t")+database.SplitJoin("t")
+	if depth > 0 {
+		cte = database.CategoryPathsCTE("$1")
+		joins += database.CategoryPathJoin(category)
+		category = database.CategoryAtDepth(category, depth)
+	}
+	query := cte + `
 		SELECT
-			t.category_id,
+			` + category + ` AS category_id,
 			COALESCE(c.name_category, 'Без категории') AS category_name,
 			c.icon_url AS icon_url,
-			COALESCE(SUM(t.amount), 0) AS total_amount,
+			COALESCE(SUM(` + amount + `), 0) AS total_amount,
 			COALESCE(
-				SUM(t.amount) * 100.0 / NULLIF(SUM(SUM(t.amount)) OVER (), 0),
+				SUM(` + amount + `) * 100.0 / NULLIF(SUM(SUM(` + amount + `)) OVER (), 0),
 				0
-			) AS share_percent
-		FROM Transactions t
-		LEFT JOIN Category c ON c.category_id = t.category_id
+			) AS share_percent,
+			` + database.UnconvertedCurrencies("t") + ` AS unconverted_currencies
+		FROM Transactions t` + joins + `
+		LEFT JOIN Category c ON c.category_id = ` + category + `
 		WHERE t.user_id = $1 AND t.is_income = $2 AND t.completed_at >= $3 AND t.completed_at <= $4
 	`
 
 	args := []interface{}{userID, isIncome, start, end}
 	nextArg := 5
+	query += " AND t.transfer_id IS NULL AND t.is_adjustment = false"
 	if !includeHidden {
 		query += " AND t.is_hidden = false"
 	}
@@ -99,13 +121,84 @@ func (r *AnalyticsRepository) GetByCategory(
 	}
 
 	query += `
-		GROUP BY t.category_id, c.name_category, c.icon_url
+		GROUP BY ` + category + `, c.name_category, c.icon_url
 		ORDER BY total_amount DESC
 	`
 
-	reports := make([]domain.CategoryReport, 0)
-	err := r.db.SelectContext(ctx, &reports, query, args...)
-	return reports, err
+	var rows []struct {
+		domain.CategoryReport
+		Unconverted database.CurrencyList `db:"unconverted_currencies"`
+	}
+	if err := r.db.SelectContext(ctx, &rows, query, args...); err != nil {
+		return nil, err
+	}
+	reports := make([]domain.CategoryReport, len(rows))
+	for i, row := range rows {
+		reports[i] = row.CategoryReport
+		reports[i].UnconvertedCurrencies = row.Unconverted
+	}
+	return reports, nil
+}
+
+// GetByTag sums transactions per tag. A transaction with several tags is
+// counted in each of them.
+func (r *AnalyticsRepository) GetByTag(
+	ctx context.Context,
+	userID uuid.UUID,
+	start, end time.Time,
+	includeHidden bool,
+	accountIDs []uuid.UUID,
+) ([]domain.TagReport, error) {
+	amount := database.BaseAmount("t.amount")
+	query := `
+		SELECT
+			g.tag_id AS tag_id,
+			g.name_tag AS tag_name,
+			g.color AS color,
+			COALESCE(SUM(CASE WHEN t.is_income = true THEN ` + amount + ` ELSE 0 END), 0) AS total_income,
+			COALESCE(SUM(CASE WHEN t.is_income = false THEN ` + amount + ` ELSE 0 END), 0) AS total_expense,
+			COUNT(*) AS transactions_count,
+			` + database.UnconvertedCurrencies("t") + ` AS unconverted_currencies
+		FROM Transactions t` + database.BaseCurrencyJoin("t") + `
+		JOIN TransactionTags tt ON tt.transaction_id = t.transaction_id
+		JOIN Tags g ON g.tag_id = tt.tag_id
+		WHERE t.user_id = $1 AND t.completed_at >= $2 AND t.completed_at <= $3
+	`
+
+	args := []interface{}{userID, start, end}
+	nextArg := 4
+	query += " AND t.transfer_id IS NULL AND t.is_adjustment = false"
+	if !includeHidden {
+		query += " AND t.is_hidden = false"
+	}
+	if len(accountIDs) > 0 {
+		placeholders := make([]string, len(accountIDs))
+		for i, id := range accountIDs {
+			placeholders[i] = fmt.Sprintf("$%d", nextArg)
+			args = append(args, id)
+			nextArg++
+		}
+		query += " AND t.account_id IN (" + strings.Join(placeholders, ", ") + ")"
+	}
+
+	query += `
+		GROUP BY g.tag_id, g.name_tag, g.color
+		ORDER BY total_expense DESC, g.name_tag ASC
+	`
+
+	var rows []struct {
+		domain.TagReport
+		Unconverted database.CurrencyList `db:"unconverted_currencies"`
+	}
+	if err := r.db.SelectContext(ctx, &rows, query, args...); err != nil {
+		return nil, err
+	}
+	reports := make([]domain.TagReport, len(rows))
+	for i, row := range rows {
+		reports[i] = row.TagReport
+		reports[i].UnconvertedCurrencies = row.Unconverted
+	}
+	return reports, nil
 }
 
 func (r *AnalyticsRepository) GetDailyDynamics(
@@ -117,15 +210,17 @@ func (r *AnalyticsRepository) GetDailyDynamics(
 	accountIDs []uuid.UUID,
 ) ([]domain.DailyReport, error) {
 	query := `
-		SELECT completed_at::DATE AS date, COALESCE(SUM(amount), 0) AS total_amount
-		FROM Transactions
-		WHERE user_id = $1 AND is_income = $2 AND completed_at >= $3 AND completed_at <= $4
+		SELECT t.completed_at::DATE AS date, COALESCE(SUM(` + database.BaseAmount("t.amount") + `), 0) AS total_amount,
+			` + database.UnconvertedCurrencies("t") + ` AS unconverted_currencies
+		FROM Transactions t` + database.BaseCurrencyJoin("t") + `
+		WHERE t.user_id = $1 AND t.is_income = $2 AND t.completed_at >= $3 AND t.completed_at <= $4
 	`
 
 	args := []interface{}{userID, isIncome, start, end}
 	nextArg := 5
+	query += " AND t.transfer_id IS NULL AND t.is_adjustment = false"
 	if !includeHidden {
-		query += " AND is_hidden = false"
+		query += " AND t.is_hidden = false"
 	}
 	if len(accountIDs) > 0 {
 		placeholders := make([]string, len(accountIDs))
@@ -134,17 +229,27 @@ func (r *AnalyticsRepository) GetDailyDynamics(
 			args = append(args, id)
 			nextArg++
 		}
-		query += " AND account_id IN (" + strings.Join(placeholders, ", ") + ")"
+		query += " AND t.account_id IN (" + strings.Join(placeholders, ", ") + ")"
 	}
 
 	query += `
-		GROUP BY completed_at::DATE
+		GROUP BY t.completed_at::DATE
 		ORDER BY date ASC
 	`
 
-	reports := make([]domain.DailyReport, 0)
-	err := r.db.SelectContext(ctx, &reports, query, args...)
-	return reports, err
+	var rows []struct {
+		domain.DailyReport
+		Unconverted database.CurrencyList `db:"unconverted_currencies"`
+	}
+	if err := r.db.SelectContext(ctx, &rows, query, args...); err != nil {
+		return nil, err
+	}
+	reports := make([]domain.DailyReport, len(rows))
+	for i, row := range rows {
+		reports[i] = row.DailyReport
+		reports[i].UnconvertedCurrencies = row.Unconverted
+	}
+	return reports, nil
 }
 
 func (r *AnalyticsRepository) GetMonthlyDynamics(
@@ -156,15 +261,17 @@ func (r *AnalyticsRepository) GetMonthlyDynamics(
 	accountIDs []uuid.UUID,
 ) ([]domain.MonthlyReport, error) {
 	query := `
-		SELECT date_trunc('month', completed_at)::DATE AS month, COALESCE(SUM(amount), 0) AS total_amount
-		FROM Transactions
-		WHERE user_id = $1 AND is_income = $2 AND completed_at >= $3 AND completed_at <= $4
+		SELECT date_trunc('month', t.completed_at)::DATE AS month, COALESCE(SUM(` + database.BaseAmount("t.amount") + `), 0) AS total_amount,
+			` + database.UnconvertedCurrencies("t") + ` AS unconverted_currencies
+		FROM Transactions t` + database.BaseCurrencyJoin("t") + `
+		WHERE t.user_id = $1 AND t.is_income = $2 AND t.completed_at >= $3 AND t.completed_at <= $4
 	`
 
 	args := []interface{}{userID, isIncome, start, end}
 	nextArg := 5
+	query += " AND t.transfer_id IS NULL AND t.is_adjustment = false"
 	if !includeHidden {
-		query += " AND is_hidden = false"
+		query += " AND t.is_hidden = false"
 	}
 	if len(accountIDs) > 0 {
 		placeholders := make([]string, len(accountIDs))
@@ -173,17 +280,27 @@ func (r *AnalyticsRepository) GetMonthlyDynamics(
 			args = append(args, id)
 			nextArg++
 		}
-		query += " AND account_id IN (" + strings.Join(placeholders, ", ") + ")"
+		query += " AND t.account_id IN (" + strings.Join(placeholders, ", ") + ")"
 	}
 
 	query += `
-		GROUP BY date_trunc('month', completed_at)::DATE
+		GROUP BY date_trunc('month', t.completed_at)::DATE
 		ORDER BY month ASC
 	`
 
-	reports := make([]domain.MonthlyReport, 0)
-	err := r.db.SelectContext(ctx, &reports, query, args...)
-	return reports, err
+	var rows []struct {
+		domain.MonthlyReport
+		Unconverted database.CurrencyList `db:"unconverted_currencies"`
+	}
+	if err := r.db.SelectContext(ctx, &rows, query, args...); err != nil {
+		return nil, err
+	}
+	reports := make([]domain.MonthlyReport, len(rows))
+	for i, row := range rows {
+		reports[i] = row.MonthlyReport
+		reports[i].UnconvertedCurrencies = row.Unconverted
+	}
+	return reports, nil
 }
 
 func (r *AnalyticsRepository) CompareCategoryPeriods(
@@ -192,14 +309,15 @@ func (r *AnalyticsRepository) CompareCategoryPeriods(
 	firstStart, firstEnd time.Time,
 	secondStart, secondEnd time.Time,
 	isIncome bool,
+	depth int,
 	includeHidden bool,
 	accountIDs []uuid.UUID,
 ) ([]domain.CategoryCompareReport, error) {
-	firstPeriodRows, err := r.GetByCategory(ctx, userID, firstStart, firstEnd, isIncome, includeHidden, accountIDs)
+	firstPeriodRows, err := r.GetByCategory(ctx, userID, firstStart, firstEnd, isIncome, depth, includeHidden, accountIDs)
 	if err != nil {
 		return nil, err
 	}
-	secondPeriodRows, err := r.GetByCategory(ctx, userID, secondStart, secondEnd, isIncome, includeHidden, accountIDs)
+	secondPeriodRows, err := r.GetByCategory(ctx, userID, secondStart, secondEnd, isIncome, depth, includeHidden, accountIDs)
 	if err != nil {
 		return nil, err
 	}
@@ -210,6 +328,7 @@ func (r *AnalyticsRepository) CompareCategoryPeriods(
 		iconURL      *string
 		firstAmount  int64
 		secondAmount int64
+		unconverted  []string
 	}
 
 	aggregates := make(map[string]*aggregate)
@@ -227,6 +346,7 @@ func (r *AnalyticsRepository) CompareCategoryPeriods(
 			categoryName: row.CategoryName,
 			iconURL:      row.IconURL,
 			firstAmount:  row.TotalAmount,
+			unconverted:  row.UnconvertedCurrencies,
 		}
 	}
 
@@ -234,6 +354,7 @@ func (r *AnalyticsRepository) CompareCategoryPeriods(
 		key := keyFor(row.CategoryID, row.CategoryName)
 		if existing, ok := aggregates[key]; ok {
 			existing.secondAmount = row.TotalAmount
+			existing.unconverted = database.MergeCurrencies(existing.unconverted, row.UnconvertedCurrencies)
 			if existing.iconURL == nil && row.IconURL != nil {
 				existing.iconURL = row.IconURL
 			}
@@ -247,6 +368,7 @@ func (r *AnalyticsRepository) CompareCategoryPeriods(
 			categoryName: row.CategoryName,
 			iconURL:      row.IconURL,
 			secondAmount: row.TotalAmount,
+			unconverted:  row.UnconvertedCurrencies,
 		}
 	}
 
@@ -259,13 +381,14 @@ func (r *AnalyticsRepository) CompareCategoryPeriods(
 			deltaPercent = &value
 		}
 		result = append(result, domain.CategoryCompareReport{
-			CategoryID:         row.categoryID,
-			CategoryName:       row.categoryName,
-			IconURL:            row.iconURL,
-			FirstPeriodAmount:  row.firstAmount,
-			SecondPeriodAmount: row.secondAmount,
-			DeltaAmount:        delta,
-			DeltaPercent:       deltaPercent,
+			CategoryID:            row.categoryID,
+			CategoryName:          row.categoryName,
+			IconURL:               row.iconURL,
+			FirstPeriodAmount:     row.firstAmount,
+			SecondPeriodAmount:    row.secondAmount,
+			DeltaAmount:           delta,
+			DeltaPercent:          deltaPercent,
+			UnconvertedCurrencies: row.unconverted,
 		})
 	}
 
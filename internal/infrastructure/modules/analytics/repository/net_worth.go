@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"Finance-Manager-System/internal/infrastructure/modules/analytics/domain"
+)
+
+func (r *AnalyticsRepository) GetBaseCurrency(ctx context.Context, userID uuid.UUID) (string, error) {
+	var currency string
+	query := `SELECT COALESCE((SELECT base_currency FROM Users WHERE user_id = $1), 'RUB')`
+	if err := r.db.GetContext(ctx, &currency, query, userID); err != nil {
+		return "", fmt.Errorf("failed to get base currency: %w", err)
+	}
+	return currency, nil
+}
+
+// GetNetWorthAccounts returns every account of the user, archived ones
+// included, with the first day it has a balance.
+func (r *AnalyticsRepository) GetNetWorthAccounts(ctx context.Context, userID uuid.UUID) ([]domain.NetWorthAccount, error) {
+	accounts := make([]domain.NetWorthAccount, 0)
+	query := `
+		SELECT a.account_id, a.name_account, a.kind, a.currency, a.balance, a.is_archived, a.archived_at,
+			LEAST(
+				a.created_at,
+				COALESCE((SELECT MIN(t.completed_at) FROM Transactions t WHERE t.account_id = a.account_id), a.created_at),
+				COALESCE((SELECT MIN(s.taken_at) FROM BalanceSnapshots s WHERE s.account_id = a.account_id), a.created_at)
+			) AS since
+		FROM Accounts a
+		WHERE a.user_id = $1
+		ORDER BY a.created_at ASC
+	`
+	if err := r.db.SelectContext(ctx, &accounts, query, userID); err != nil {
+		return nil, fmt.Errorf("failed to get accounts: %w", err)
+	}
+	return accounts, nil
+}
+
+func (r *AnalyticsRepository) GetBalanceAnchors(ctx context.Context, userID uuid.UUID) ([]domain.BalanceAnchor, error) {
+	anchors := make([]domain.BalanceAnchor, 0)
+	query := `SELECT account_id, taken_at, balance FROM BalanceSnapshots WHERE user_id = $1 ORDER BY taken_at ASC, created_at ASC`
+	if err := r.db.SelectContext(ctx, &anchors, query, userID); err != nil {
+		return nil, fmt.Errorf("failed to get balance snapshots: %w", err)
+	}
+	return anchors, nil
+}
+
+// GetAccountDailyFlows returns the net change of every account per UTC day
+// from its visible transactions. Transfers count, they move money between
+// accounts.
+func (r *AnalyticsRepository) GetAccountDailyFlows(ctx context.Context, userID uuid.UUID) ([]domain.AccountDailyFlow, error) {
+	flows := make([]domain.AccountDailyFlow, 0)
+	query := `
+		SELECT account_id,
+			date_trunc('day', completed_at AT TIME ZONE 'UTC') AS day,
+			SUM(CASE WHEN is_income THEN amount ELSE -amount END) AS amount
+		FROM Transactions
+		WHERE user_id = $1 AND is_hidden = false
+		GROUP BY account_id, day
+	`
+	if err := r.db.SelectContext(ctx, &flows, query, userID); err != nil {
+		return nil, fmt.Errorf("failed to get daily account flows: %w", err)
+	}
+	return flows, nil
+}
+
+// GetBaseRates returns the user's exchange rates to or from base.
+func (r *AnalyticsRepository) GetBaseRates(ctx context.Context, userID uuid.UUID, base string) ([]domain.ExchangeRate, error) {
+	rates := make([]domain.ExchangeRate, 0)
+	query := `
+		SELECT from_currency, to_currency, rate_date, rate
+		FROM ExchangeRates
+		WHERE user_id = $1 AND (from_currency = $2 OR to_currency = $2)
+		ORDER BY rate_date ASC
+	`
+	if err := r.db.SelectContext(ctx, &rates, query, userID, base); err != nil {
+		return nil, fmt.Errorf("failed to get exchange rates: %w", err)
+	}
+	return rates, nil
+}
@@ -9,13 +9,16 @@ import (
 
 	"Finance-Manager-System/internal/infrastructure/modules/analytics/domain"
 	"Finance-Manager-System/internal/infrastructure/modules/analytics/repository"
+	categoryDomain "Finance-Manager-System/internal/infrastructure/modules/category/domain"
 )
 
 type AnalyticsUseCase struct {
 	repo *repository.AnalyticsRepository
 }
 
-var ErrInvalidPeriod = errors.New("invalid period")
+var (
+	ErrInvalidPeriod = errors.New("invalid period")
+)
 
 func NewAnalyticsUseCase(repo *repository.AnalyticsRepository) *AnalyticsUseCase {
 	return &AnalyticsUseCase{repo: repo}
@@ -79,14 +82,33 @@ func (uc *AnalyticsUseCase) GetCategoryReport(
 	start, end *time.Time,
 	period string,
 	isIncome bool,
+	depth int,
 	includeHidden bool,
 	accountIDs []uuid.UUID,
 ) ([]domain.CategoryReport, error) {
+	if err := categoryDomain.ValidateDepth(depth); err != nil {
+		return nil, err
+	}
 	s, e, err := uc.resolveDates(start, end, period)
 	if err != nil {
 		return nil, err
 	}
-	return uc.repo.GetByCategory(ctx, userID, s, e, isIncome, includeHidden, accountIDs)
+	return uc.repo.GetByCategory(ctx, userID, s, e, isIncome, depth, includeHidden, accountIDs)
+}
+
+func (uc *AnalyticsUseCase) GetTagReport(
+	ctx context.Context,
+	userID uuid.UUID,
+	start, end *time.Time,
+	period string,
+	includeHidden bool,
+	accountIDs []uuid.UUID,
+) ([]domain.TagReport, error) {
+	s, e, err := uc.resolveDates(start, end, period)
+	if err != nil {
+		return nil, err
+	}
+	return uc.repo.GetByTag(ctx, userID, s, e, includeHidden, accountIDs)
 }
 
 func (uc *AnalyticsUseCase) GetDailyDynamics(
@@ -132,9 +154,13 @@ func (uc *AnalyticsUseCase) CompareCategoriesByMonths(
 	userID uuid.UUID,
 	firstMonth, secondMonth time.Time,
 	isIncome bool,
+	depth int,
 	includeHidden bool,
 	accountIDs []uuid.UUID,
 ) ([]domain.CategoryCompareReport, error) {
+	if err := categoryDomain.ValidateDepth(depth); err != nil {
+		return nil, err
+	}
 	firstStart, firstEnd := monthRange(firstMonth)
 	secondStart, secondEnd := monthRange(secondMonth)
 	return uc.repo.CompareCategoryPeriods(
@@ -145,6 +171,7 @@ func (uc *AnalyticsUseCase) CompareCategoriesByMonths(
 		secondStart,
 		secondEnd,
 		isIncome,
+		depth,
 		includeHidden,
 		accountIDs,
 	)
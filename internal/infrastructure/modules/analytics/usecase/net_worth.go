@@ -0,0 +1,78 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+
+	"Finance-Manager-System/internal/infrastructure/modules/analytics/domain"
+)
+
+var (
+	ErrInvalidInterval = errors.New("interval must be day, week or month")
+	ErrTooManyPoints   = errors.New("period has too many points for the interval, use a longer interval")
+)
+
+// GetNetWorth returns assets, liabilities and net worth in the base currency
+// at the end of every interval between start and end. By default it covers
+// the last year by month.
+func (uc *AnalyticsUseCase) GetNetWorth(ctx context.Context, userID uuid.UUID, start, end *time.Time, interval string) (*domain.NetWorthReport, error) {
+	dates, err := netWorthDates(start, end, interval, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	if interval == "" {
+		interval = domain.IntervalMonth
+	}
+
+	base, err := uc.repo.GetBaseCurrency(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	accounts, err := uc.repo.GetNetWorthAccounts(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	anchors, err := uc.repo.GetBalanceAnchors(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	flows, err := uc.repo.GetAccountDailyFlows(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	rates, err := uc.repo.GetBaseRates(ctx, userID, base)
+	if err != nil {
+		return nil, err
+	}
+	return domain.BuildNetWorth(base, interval, dates, time.Now().UTC(), accounts, anchors, flows, rates), nil
+}
+
+func netWorthDates(start, end *time.Time, interval string, now time.Time) ([]time.Time, error) {
+	switch interval {
+	case "", domain.IntervalDay, domain.IntervalWeek, domain.IntervalMonth:
+	default:
+		return nil, ErrInvalidInterval
+	}
+	e := now
+	if end != nil {
+		e = *end
+	}
+	if e.After(now) {
+		e = now
+	}
+	s := e.AddDate(-1, 0, 0)
+	if start != nil {
+		s = *start
+	}
+	if e.Before(s) {
+		return nil, ErrInvalidPeriod
+	}
+	dates := domain.NetWorthDates(s, e, interval)
+	if len(dates) > domain.MaxNetWorthPoints {
+		return nil, ErrTooManyPoints
+	}
+	return dates, nil
+}
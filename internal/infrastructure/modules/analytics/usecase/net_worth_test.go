@@ -0,0 +1,30 @@
+package usecase
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNetWorthDatesValidation(t *testing.T) {
+	now := time.Date(2026, 5, 20, 12, 0, 0, 0, time.UTC)
+	dates, err := netWorthDates(nil, nil, "", now)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if len(dates) != 13 || !dates[len(dates)-1].Equal(time.Date(2026, 5, 20, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("default must be a year by month, got %v", dates)
+	}
+
+	if _, err := netWorthDates(nil, nil, "year", now); !errors.Is(err, ErrInvalidInterval) {
+		t.Fatalf("expected ErrInvalidInterval, got %v", err)
+	}
+	start := now.AddDate(-2, 0, 0)
+	if _, err := netWorthDates(&start, nil, "day", now); !errors.Is(err, ErrTooManyPoints) {
+		t.Fatalf("expected ErrTooManyPoints, got %v", err)
+	}
+	end := now.AddDate(0, -1, 0)
+	if _, err := netWorthDates(&now, &end, "week", now); !errors.Is(err, ErrInvalidPeriod) {
+		t.Fatalf("expected ErrInvalidPeriod, got %v", err)
+	}
+}
@@ -0,0 +1,202 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrBudgetEmptyUserID       = errors.New("user ID cannot be empty (nil UUID)")
+	ErrBudgetInvalidAmount     = errors.New("budget amount must be strictly greater than zero")
+	ErrBudgetInvalidPeriod     = errors.New("period must be one of monthly, rolling")
+	ErrBudgetInvalidPeriodDays = errors.New("rolling period must be between 1 and 366 days")
+	ErrBudgetNotFound          = errors.New("budget not found")
+	ErrBudgetCategoryNotFound  = errors.New("category not found")
+	ErrBudgetAlreadyExists     = errors.New("budget for this category already exists")
+)
+
+type PeriodType string
+
+const (
+	PeriodMonthly PeriodType = "monthly"
+	PeriodRolling PeriodType = "rolling"
+)
+
+type BudgetAlert string
+
+const (
+	AlertOK       BudgetAlert = "ok"
+	AlertWarning  BudgetAlert = "warning"
+	AlertExceeded BudgetAlert = "exceeded"
+)
+
+// WarningShare is the part of the available amount after which a budget is
+// flagged with a warning.
+const WarningShare = 0.8
+
+// Budget limits the expenses of one category, or of uncategorized expenses
+// when CategoryID is nil. Monthly budgets follow calendar months; rolling
+// budgets repeat every PeriodDays days counted from StartDate. With Rollover
+// the unused amount of the previous period is added to the current one.
+type Budget struct {
+	BudgetID     uuid.UUID  `db:"budget_id" json:"budget_id"`
+	UserID       uuid.UUID  `db:"user_id" json:"-"`
+	CategoryID   *uuid.UUID `db:"category_id" json:"category_id"`
+	CategoryName string     `db:"category_name" json:"category_name"`
+	Amount       int64      `db:"amount" json:"amount"`
+	PeriodType   PeriodType `db:"period_type" json:"period_type"`
+	PeriodDays   *int       `db:"period_days" json:"period_days,omitempty"`
+	Rollover     bool       `db:"rollover" json:"rollover"`
+	StartDate    time.Time  `db:"start_date" json:"start_date"`
+	CreatedAt    time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt    time.Time  `db:"updated_at" json:"updated_at"`
+}
+
+// BudgetStatus is the state of a budget in the period containing a moment.
+type BudgetStatus struct {
+	Budget
+	PeriodStart     time.Time   `json:"period_start"`
+	PeriodEnd       time.Time   `json:"period_end"`
+	RolloverAmount  int64       `json:"rollover_amount"`
+	Available       int64       `json:"available"`
+	Spent           int64       `json:"spent"`
+	Remaining       int64       `json:"remaining"`
+	Projected       int64       `json:"projected"`
+	UsedPercent     float64     `json:"used_percent"`
+	IsOverBudget    bool        `json:"is_over_budget"`
+	IsProjectedOver bool        `json:"is_projected_over"`
+	Alert           BudgetAlert `json:"alert"`
+	// UnconvertedCurrencies had no rate to the base currency and were left
+	// out of Spent.
+	UnconvertedCurrencies []string `json:"unconverted_currencies,omitempty"`
+}
+
+type CategorySpending struct {
+	CategoryID            *uuid.UUID `db:"category_id"`
+	Amount                int64      `db:"amount"`
+	UnconvertedCurrencies []string   `db:"-"`
+}
+
+// NewBudget validates a budget. Without startDate monthly budgets start at the
+// current month and rolling budgets today.
+func NewBudget(userID uuid.UUID, categoryID *uuid.UUID, amount int64, periodType string, periodDays *int, rollover bool, startDate *time.Time) (*Budget, error) {
+	if userID == uuid.Nil {
+		return nil, ErrBudgetEmptyUserID
+	}
+	if amount <= 0 {
+		return nil, ErrBudgetInvalidAmount
+	}
+
+	now := time.Now().UTC()
+	start := dateOnly(now)
+	if startDate != nil {
+		start = dateOnly(startDate.UTC())
+	}
+
+	switch PeriodType(periodType) {
+	case PeriodMonthly:
+		periodDays = nil
+		start = time.Date(start.Year(), start.Month(), 1, 0, 0, 0, 0, time.UTC)
+	case PeriodRolling:
+		if periodDays == nil || *periodDays < 1 || *periodDays > 366 {
+			return nil, ErrBudgetInvalidPeriodDays
+		}
+	default:
+		return nil, ErrBudgetInvalidPeriod
+	}
+
+	return &Budget{
+		UserID:     userID,
+		CategoryID: categoryID,
+		Amount:     amount,
+		PeriodType: PeriodType(periodType),
+		PeriodDays: periodDays,
+		Rollover:   rollover,
+		StartDate:  start,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}, nil
+}
+
+// PeriodAt returns the period [start, end) containing t. Moments before
+// StartDate belong to the first period.
+func (b *Budget) PeriodAt(t time.Time) (time.Time, time.Time) {
+	t = t.UTC()
+	if b.PeriodType == PeriodRolling && b.PeriodDays != nil {
+		length := time.Duration(*b.PeriodDays) * 24 * time.Hour
+		index := int64(0)
+		if t.After(b.StartDate) {
+			index = int64(t.Sub(b.StartDate) / length)
+		}
+		start := b.StartDate.AddDate(0, 0, int(index)*(*b.PeriodDays))
+		return start, start.AddDate(0, 0, *b.PeriodDays)
+	}
+	start := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	if start.Before(b.StartDate) {
+		start = b.StartDate
+	}
+	return start, start.AddDate(0, 1, 0)
+}
+
+// PreviousPeriod returns the period before the one starting at start, if the
+// budget already existed then.
+func (b *Budget) PreviousPeriod(start time.Time) (time.Time, time.Time, bool) {
+	if !start.After(b.StartDate) {
+		return time.Time{}, time.Time{}, false
+	}
+	if b.PeriodType == PeriodRolling && b.PeriodDays != nil {
+		return start.AddDate(0, 0, -*b.PeriodDays), start, true
+	}
+	return start.AddDate(0, -1, 0), start, true
+}
+
+// NewBudgetStatus computes the state of the period [start, end) at now.
+// Rollover is taken from the previous period only and never goes negative,
+// so an overspent month does not shrink the next one.
+func NewBudgetStatus(b Budget, start time.Time, end time.Time, now time.Time, spent int64, previousSpent *int64) BudgetStatus {
+	status := BudgetStatus{
+		Budget:      b,
+		PeriodStart: start,
+		PeriodEnd:   end,
+		Spent:       spent,
+	}
+	if b.Rollover && previousSpent != nil && *previousSpent < b.Amount {
+		status.RolloverAmount = b.Amount - *previousSpent
+	}
+	status.Available = b.Amount + status.RolloverAmount
+	status.Remaining = status.Available - spent
+
+	elapsed := now.Sub(start)
+	total := end.Sub(start)
+	// At least one day has to pass, otherwise the first purchase of the
+	// period would be extrapolated to an absurd amount.
+	if elapsed < 24*time.Hour {
+		elapsed = 24 * time.Hour
+	}
+	if elapsed > total {
+		elapsed = total
+	}
+	status.Projected = int64(float64(spent) * float64(total) / float64(elapsed))
+
+	if status.Available > 0 {
+		status.UsedPercent = float64(spent) / float64(status.Available) * 100
+	}
+	status.IsOverBudget = spent > status.Available
+	status.IsProjectedOver = status.Projected > status.Available
+
+	switch {
+	case status.IsOverBudget:
+		status.Alert = AlertExceeded
+	case status.IsProjectedOver || float64(spent) >= WarningShare*float64(status.Available):
+		status.Alert = AlertWarning
+	default:
+		status.Alert = AlertOK
+	}
+	return status
+}
+
+func dateOnly(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,65 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestBudgetPeriods(t *testing.T) {
+	start := time.Date(2026, 3, 14, 10, 0, 0, 0, time.UTC)
+	monthly, err := NewBudget(uuid.New(), nil, 1000, "monthly", nil, true, &start)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if !monthly.StartDate.Equal(time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("monthly budget should start at the first of the month, got %v", monthly.StartDate)
+	}
+	from, to := monthly.PeriodAt(time.Date(2026, 5, 20, 0, 0, 0, 0, time.UTC))
+	if !from.Equal(time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)) || !to.Equal(time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected monthly period: %v - %v", from, to)
+	}
+	if _, _, ok := monthly.PreviousPeriod(monthly.StartDate); ok {
+		t.Fatalf("first period must not have a previous one")
+	}
+
+	days := 14
+	rolling, err := NewBudget(uuid.New(), nil, 1000, "rolling", &days, false, &start)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	from, to = rolling.PeriodAt(time.Date(2026, 4, 1, 12, 0, 0, 0, time.UTC))
+	if !from.Equal(time.Date(2026, 3, 28, 0, 0, 0, 0, time.UTC)) || !to.Equal(time.Date(2026, 4, 11, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected rolling period: %v - %v", from, to)
+	}
+
+	if _, err := NewBudget(uuid.New(), nil, 1000, "rolling", nil, false, nil); err != ErrBudgetInvalidPeriodDays {
+		t.Fatalf("expected ErrBudgetInvalidPeriodDays, got %v", err)
+	}
+	if _, err := NewBudget(uuid.New(), nil, 0, "monthly", nil, false, nil); err != ErrBudgetInvalidAmount {
+		t.Fatalf("expected ErrBudgetInvalidAmount, got %v", err)
+	}
+}
+
+func TestNewBudgetStatus(t *testing.T) {
+	budget := Budget{Amount: 3000, PeriodType: PeriodMonthly, Rollover: true}
+	start := time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 1, 0)
+	now := start.AddDate(0, 0, 10)
+
+	previous := int64(2000)
+	status := NewBudgetStatus(budget, start, end, now, 1500, &previous)
+	if status.RolloverAmount != 1000 || status.Available != 4000 || status.Remaining != 2500 {
+		t.Fatalf("unexpected amounts: %+v", status)
+	}
+	if status.Projected != 4500 || !status.IsProjectedOver || status.Alert != AlertWarning {
+		t.Fatalf("expected projected overspend warning, got %+v", status)
+	}
+
+	overspent := int64(5000)
+	status = NewBudgetStatus(budget, start, end, now, 3500, &overspent)
+	if status.RolloverAmount != 0 || !status.IsOverBudget || status.Alert != AlertExceeded {
+		t.Fatalf("expected exceeded budget without rollover, got %+v", status)
+	}
+}
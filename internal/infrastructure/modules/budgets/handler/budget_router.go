@@ -0,0 +1,335 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"Finance-Manager-System/internal/infrastructure/middleware"
+	"Finance-Manager-System/internal/infrastructure/modules/budgets/domain"
+	"Finance-Manager-System/internal/infrastructure/modules/budgets/usecase"
+	recommendationUC "Finance-Manager-System/internal/infrastructure/modules/recommendations/usecase"
+)
+
+type BudgetRouter struct {
+	budgetUC *usecase.BudgetUseCase
+}
+
+func NewBudgetRouter(budgetUC *usecase.BudgetUseCase) *BudgetRouter {
+	return &BudgetRouter{budgetUC: budgetUC}
+}
+
+func (h *BudgetRouter) Route() chi.Router {
+	r := chi.NewRouter()
+	r.Post("/", h.CreateBudget)
+	r.Get("/", h.GetBudgets)
+	r.Get("/status", h.GetStatus)
+	r.Post("/from-recommendations", h.AcceptRecommendations)
+	r.Get("/{id}", h.GetBudget)
+	r.Put("/{id}", h.UpdateBudget)
+	r.Delete("/{id}", h.DeleteBudget)
+	return r
+}
+
+type BudgetReq struct {
+	CategoryID *uuid.UUID `json:"category_id"`
+	Amount     int64      `json:"amount" example:"1500000"`
+	PeriodType string     `json:"period_type" example:"monthly"`
+	PeriodDays *int       `json:"period_days" example:"14"`
+	Rollover   bool       `json:"rollover" example:"false"`
+	StartDate  *time.Time `json:"start_date"`
+}
+
+type AcceptRecommendationsReq struct {
+	PlannedTotal  int64       `json:"planned_total" example:"5000000"`
+	Months        int         `json:"months" example:"3"`
+	IncludeHidden bool        `json:"include_hidden"`
+	AccountIDs    []uuid.UUID `json:"account_ids"`
+	Rollover      bool        `json:"rollover"`
+}
+
+// @Summary Создать бюджет категории
+// @Tags budgets
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param request body BudgetReq true "Лимит, период (monthly или rolling) и перенос остатка"
+// @Success 202 {object} map[string]interface{}
+// @Router /api/v1/budgets [post]
+func (h *BudgetRouter) CreateBudget(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req BudgetReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid json", http.StatusBadRequest)
+		return
+	}
+
+	budgetID, err := h.budgetUC.CreateBudget(r.Context(), userID, req.CategoryID, req.Amount, req.PeriodType, req.PeriodDays, req.Rollover, req.StartDate)
+	if err != nil {
+		h.mapError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]interface{}{"status": "success", "budget_id": budgetID})
+}
+
+// @Summary Получить список бюджетов
+// @Tags budgets
+// @Security ApiKeyAuth
+// @Produce json
+// @Success 200 {array} domain.Budget
+// @Router /api/v1/budgets [get]
+func (h *BudgetRouter) GetBudgets(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	budgets, err := h.budgetUC.GetBudgets(r.Context(), userID)
+	if err != nil {
+		h.mapError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(budgets)
+}
+
+// @Summary Состояние бюджетов в текущем периоде
+// @Description Потрачено, остаток и прогноз до конца периода по каждой категории
+// @Tags budgets
+// @Security ApiKeyAuth
+// @Produce json
+// @Param include_hidden query boolean false "Учитывать скрытые транзакции"
+// @Param account_ids query string false "CSV список account_id для фильтра"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/budgets/status [get]
+func (h *BudgetRouter) GetStatus(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	includeHidden := r.URL.Query().Get("include_hidden") == "true"
+	accountIDs, err := parseAccountIDs(r.URL.Query().Get("account_ids"))
+	if err != nil {
+		http.Error(w, "account_ids must contain valid UUIDs", http.StatusBadRequest)
+		return
+	}
+
+	statuses, err := h.budgetUC.GetStatus(r.Context(), userID, time.Now().UTC(), includeHidden, accountIDs)
+	if err != nil {
+		h.mapError(w, err)
+		return
+	}
+
+	var available, spent, projected int64
+	alerts := 0
+	for _, status := range statuses {
+		available += status.Available
+		spent += status.Spent
+		projected += status.Projected
+		if status.Alert != domain.AlertOK {
+			alerts++
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"include_hidden":  includeHidden,
+		"account_ids":     accountIDs,
+		"total_available": available,
+		"total_spent":     spent,
+		"total_remaining": available - spent,
+		"total_projected": projected,
+		"alerts":          alerts,
+		"budgets":         statuses,
+	})
+}
+
+// @Summary Сохранить рекомендованные лимиты как бюджеты
+// @Description Лимиты из /recommendations/budget сохраняются как месячные бюджеты; бюджеты тех же категорий перезаписываются
+// @Tags budgets
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param request body AcceptRecommendationsReq true "Параметры рекомендаций"
+// @Success 202 {object} map[string]interface{}
+// @Router /api/v1/budgets/from-recommendations [post]
+func (h *BudgetRouter) AcceptRecommendations(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req AcceptRecommendationsReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid json", http.StatusBadRequest)
+		return
+	}
+	if req.Months == 0 {
+		req.Months = 3
+	}
+
+	budgets, err := h.budgetUC.AcceptRecommendations(r.Context(), userID, req.PlannedTotal, req.Months, req.IncludeHidden, req.AccountIDs, req.Rollover)
+	if err != nil {
+		h.mapError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]interface{}{"status": "success", "budgets": budgets})
+}
+
+// @Summary Получить бюджет
+// @Tags budgets
+// @Security ApiKeyAuth
+// @Produce json
+// @Param id path string true "ID бюджета"
+// @Success 200 {object} domain.Budget
+// @Router /api/v1/budgets/{id} [get]
+func (h *BudgetRouter) GetBudget(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	budgetID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid budget ID", http.StatusBadRequest)
+		return
+	}
+
+	budget, err := h.budgetUC.GetBudget(r.Context(), userID, budgetID)
+	if err != nil {
+		h.mapError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(budget)
+}
+
+// @Summary Обновить бюджет
+// @Tags budgets
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "ID бюджета"
+// @Param request body BudgetReq true "Новые параметры бюджета"
+// @Success 202 {object} map[string]interface{}
+// @Router /api/v1/budgets/{id} [put]
+func (h *BudgetRouter) UpdateBudget(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	budgetID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid budget ID", http.StatusBadRequest)
+		return
+	}
+
+	var req BudgetReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid json", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.budgetUC.UpdateBudget(r.Context(), userID, budgetID, req.CategoryID, req.Amount, req.PeriodType, req.PeriodDays, req.Rollover, req.StartDate); err != nil {
+		h.mapError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]interface{}{"status": "success"})
+}
+
+// @Summary Удалить бюджет
+// @Tags budgets
+// @Security ApiKeyAuth
+// @Produce json
+// @Param id path string true "ID бюджета"
+// @Success 202 {object} map[string]interface{}
+// @Router /api/v1/budgets/{id} [delete]
+func (h *BudgetRouter) DeleteBudget(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	budgetID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid budget ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.budgetUC.DeleteBudget(r.Context(), userID, budgetID); err != nil {
+		h.mapError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]interface{}{"status": "success"})
+}
+
+func (h *BudgetRouter) mapError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, domain.ErrBudgetNotFound), errors.Is(err, domain.ErrBudgetCategoryNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, domain.ErrBudgetAlreadyExists):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, domain.ErrBudgetEmptyUserID),
+		errors.Is(err, domain.ErrBudgetInvalidAmount),
+		errors.Is(err, domain.ErrBudgetInvalidPeriod),
+		errors.Is(err, domain.ErrBudgetInvalidPeriodDays),
+		errors.Is(err, recommendationUC.ErrInvalidPlannedTotal),
+		errors.Is(err, recommendationUC.ErrInvalidMonths):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func parseAccountIDs(raw string) ([]uuid.UUID, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
+	}
+
+	parts := strings.Split(raw, ",")
+	ids := make([]uuid.UUID, 0, len(parts))
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		id, err := uuid.Parse(part)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
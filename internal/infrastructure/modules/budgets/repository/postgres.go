@@ -0,0 +1,182 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"Finance-Manager-System/internal/infrastructure/database"
+	"Finance-Manager-System/internal/infrastructure/modules/budgets/domain"
+)
+
+const selectBudgets = `
+	SELECT b.*, COALESCE(c.name_category, 'Без категории') AS category_name
+	FROM Budgets b
+	LEFT JOIN Category c ON c.category_id = b.category_id
+`
+
+type BudgetRepo struct {
+	db *sqlx.DB
+}
+
+func NewBudgetRepo(db *sqlx.DB) *BudgetRepo {
+	return &BudgetRepo{db: db}
+}
+
+func (r *BudgetRepo) AddBudget(ctx context.Context, budget *domain.Budget) (uuid.UUID, error) {
+	q := database.GetQueryer(ctx, r.db)
+	query := `
+		INSERT INTO Budgets (user_id, category_id, amount, period_type, period_days, rollover, start_date, created_at, updated_at)
+		VALUES (:user_id, :category_id, :amount, :period_type, :period_days, :rollover, :start_date, :created_at, :updated_at)
+		RETURNING budget_id
+	`
+	queryStr, args, err := sqlx.Named(query, budget)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to process named query: %w", err)
+	}
+	queryStr = q.Rebind(queryStr)
+
+	var budgetID uuid.UUID
+	if err := q.QueryRowContext(ctx, queryStr, args...).Scan(&budgetID); err != nil {
+		return uuid.Nil, fmt.Errorf("failed to add budget: %w", err)
+	}
+	return budgetID, nil
+}
+
+func (r *BudgetRepo) GetBudgetsByUser(ctx context.Context, userID uuid.UUID) ([]domain.Budget, error) {
+	q := database.GetQueryer(ctx, r.db)
+	budgets := make([]domain.Budget, 0)
+	query := selectBudgets + ` WHERE b.user_id = $1 ORDER BY b.amount DESC, b.created_at ASC`
+	if err := q.SelectContext(ctx, &budgets, query, userID); err != nil {
+		return nil, fmt.Errorf("failed to get budgets: %w", err)
+	}
+	return budgets, nil
+}
+
+func (r *BudgetRepo) GetBudgetByID(ctx context.Context, userID uuid.UUID, budgetID uuid.UUID) (*domain.Budget, error) {
+	q := database.GetQueryer(ctx, r.db)
+	var budget domain.Budget
+	query := selectBudgets + ` WHERE b.user_id = $1 AND b.budget_id = $2`
+	if err := q.GetContext(ctx, &budget, query, userID, budgetID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrBudgetNotFound
+		}
+		return nil, fmt.Errorf("failed to get budget: %w", err)
+	}
+	return &budget, nil
+}
+
+// GetBudgetByCategory finds the budget of a category; a nil categoryID means
+// the budget for uncategorized expenses.
+func (r *BudgetRepo) GetBudgetByCategory(ctx context.Context, userID uuid.UUID, categoryID *uuid.UUID) (*domain.Budget, error) {
+	q := database.GetQueryer(ctx, r.db)
+	var budget domain.Budget
+	query := selectBudgets + ` WHERE b.user_id = $1 AND b.category_id IS NOT DISTINCT FROM $2`
+	if err := q.GetContext(ctx, &budget, query, userID, categoryID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrBudgetNotFound
+		}
+		return nil, fmt.Errorf("failed to get budget: %w", err)
+	}
+	return &budget, nil
+}
+
+func (r *BudgetRepo) UpdateBudget(ctx context.Context, budget *domain.Budget) error {
+	q := database.GetQueryer(ctx, r.db)
+	query := `
+		UPDATE Budgets
+		SET category_id = :category_id, amount = :amount, period_type = :period_type, period_days = :period_days,
+			rollover = :rollover, start_date = :start_date, updated_at = :updated_at
+		WHERE budget_id = :budget_id AND user_id = :user_id
+	`
+	res, err := q.NamedExecContext(ctx, query, budget)
+	if err != nil {
+		return fmt.Errorf("failed to update budget: %w", err)
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check affected rows: %w", err)
+	}
+	if rowsAffected == 0 {
+		return domain.ErrBudgetNotFound
+	}
+	return nil
+}
+
+func (r *BudgetRepo) DeleteBudget(ctx context.Context, userID uuid.UUID, budgetID uuid.UUID) error {
+	q := database.GetQueryer(ctx, r.db)
+	res, err := q.ExecContext(ctx, `DELETE FROM Budgets WHERE user_id = $1 AND budget_id = $2`, userID, budgetID)
+	if err != nil {
+		return fmt.Errorf("failed to delete budget: %w", err)
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check affected rows: %w", err)
+	}
+	if rowsAffected == 0 {
+		return domain.ErrBudgetNotFound
+	}
+	return nil
+}
+
+// GetCategorySpending sums expenses per category in the base currency with
+// the same transfer, hidden and account filters as analytics. Split
+// transactions are counted by their parts.
+func (r *BudgetRepo) GetCategorySpending(
+	ctx context.Context,
+	userID uuid.UUID,
+	start time.Time,
+	end time.Time,
+	includeHidden bool,
+	accountIDs []uuid.UUID,
+) ([]domain.CategorySpending, error) {
+	q := database.GetQueryer(ctx, r.db)
+	category := database.SplitCategory("t")
+	query := `
+		SELECT ` + category + ` AS category_id, COALESCE(SUM(` + database.BaseAmount(database.SplitAmount("t")) + `), 0) AS amount,
+			` + database.UnconvertedCurrencies("t") + ` AS unconverted_currencies
+		FROM Transactions t` + database.BaseCurrencyJoin("t") + database.SplitJoin("t") + `
+		WHERE t.user_id = $1
+		  AND t.is_income = false
+		  AND t.completed_at >= $2
+		  AND t.completed_at < $3
+		  AND t.transfer_id IS NULL
+		  AND t.is_adjustment = false
+		  AND ($4 OR t.is_hidden = false)
+	`
+
+	args := []interface{}{userID, start, end, includeHidden}
+	nextArg := 5
+
+	if len(accountIDs) > 0 {
+		placeholders := make([]string, len(accountIDs))
+		for i, id := range accountIDs {
+			placeholders[i] = fmt.Sprintf("$%d", nextArg)
+			args = append(args, id)
+			nextArg++
+		}
+		query += " AND t.account_id IN (" + strings.Join(placeholders, ", ") + ")"
+	}
+
+	query += " GROUP BY " + category
+
+	var rows []struct {
+		domain.CategorySpending
+		Unconverted database.CurrencyList `db:"unconverted_currencies"`
+	}
+	if err := q.SelectContext(ctx, &rows, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to get category spending: %w", err)
+	}
+	spending := make([]domain.CategorySpending, len(rows))
+	for i, row := range rows {
+		spending[i] = row.CategorySpending
+		spending[i].UnconvertedCurrencies = row.Unconverted
+	}
+	return spending, nil
+}
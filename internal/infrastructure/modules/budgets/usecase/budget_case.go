@@ -0,0 +1,255 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"Finance-Manager-System/internal/infrastructure/database"
+	"Finance-Manager-System/internal/infrastructure/modules/budgets/domain"
+	categoryDomain "Finance-Manager-System/internal/infrastructure/modules/category/domain"
+	recommendationDomain "Finance-Manager-System/internal/infrastructure/modules/recommendations/domain"
+)
+
+type BudgetRepository interface {
+	AddBudget(ctx context.Context, budget *domain.Budget) (uuid.UUID, error)
+	GetBudgetsByUser(ctx context.Context, userID uuid.UUID) ([]domain.Budget, error)
+	GetBudgetByID(ctx context.Context, userID uuid.UUID, budgetID uuid.UUID) (*domain.Budget, error)
+	GetBudgetByCategory(ctx context.Context, userID uuid.UUID, categoryID *uuid.UUID) (*domain.Budget, error)
+	UpdateBudget(ctx context.Context, budget *domain.Budget) error
+	DeleteBudget(ctx context.Context, userID uuid.UUID, budgetID uuid.UUID) error
+	GetCategorySpending(ctx context.Context, userID uuid.UUID, start time.Time, end time.Time, includeHidden bool, accountIDs []uuid.UUID) ([]domain.CategorySpending, error)
+}
+
+type BudgetCategoryRepository interface {
+	GetCategoryByID(ctx context.Context, userID uuid.UUID, categoryID uuid.UUID) (*categoryDomain.Category, error)
+}
+
+// BudgetRecommender suggests per-category limits. It is implemented by the
+// recommendations use case.
+type BudgetRecommender interface {
+	GetBudgetRecommendations(ctx context.Context, userID uuid.UUID, plannedTotal int64, months int, depth int, includeHidden bool, accountIDs []uuid.UUID) ([]recommendationDomain.BudgetRecommendation, error)
+}
+
+type BudgetUseCase struct {
+	repo         BudgetRepository
+	categoryRepo BudgetCategoryRepository
+	recommender  BudgetRecommender
+	txManager    database.TxManager
+}
+
+func NewBudgetUseCase(repo BudgetRepository, categoryRepo BudgetCategoryRepository, recommender BudgetRecommender, txManager database.TxManager) *BudgetUseCase {
+	return &BudgetUseCase{repo: repo, categoryRepo: categoryRepo, recommender: recommender, txManager: txManager}
+}
+
+func (uc *BudgetUseCase) CreateBudget(ctx context.Context, userID uuid.UUID, categoryID *uuid.UUID, amount int64, periodType string, periodDays *int, rollover bool, startDate *time.Time) (uuid.UUID, error) {
+	budget, err := domain.NewBudget(userID, categoryID, amount, periodType, periodDays, rollover, startDate)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if err := uc.checkCategory(ctx, userID, categoryID); err != nil {
+		return uuid.Nil, err
+	}
+
+	var budgetID uuid.UUID
+	err = uc.txManager.RunInTransaction(ctx, func(ctx context.Context) error {
+		if err := uc.ensureCategoryFree(ctx, userID, categoryID, uuid.Nil); err != nil {
+			return err
+		}
+		budgetID, err = uc.repo.AddBudget(ctx, budget)
+		return err
+	})
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return budgetID, nil
+}
+
+func (uc *BudgetUseCase) GetBudgets(ctx context.Context, userID uuid.UUID) ([]domain.Budget, error) {
+	return uc.repo.GetBudgetsByUser(ctx, userID)
+}
+
+func (uc *BudgetUseCase) GetBudget(ctx context.Context, userID uuid.UUID, budgetID uuid.UUID) (*domain.Budget, error) {
+	return uc.repo.GetBudgetByID(ctx, userID, budgetID)
+}
+
+func (uc *BudgetUseCase) UpdateBudget(ctx context.Context, userID uuid.UUID, budgetID uuid.UUID, categoryID *uuid.UUID, amount int64, periodType string, periodDays *int, rollover bool, startDate *time.Time) error {
+	current, err := uc.repo.GetBudgetByID(ctx, userID, budgetID)
+	if err != nil {
+		return err
+	}
+	if startDate == nil {
+		startDate = &current.StartDate
+	}
+	updated, err := domain.NewBudget(userID, categoryID, amount, periodType, periodDays, rollover, startDate)
+	if err != nil {
+		return err
+	}
+	if err := uc.checkCategory(ctx, userID, categoryID); err != nil {
+		return err
+	}
+
+	updated.BudgetID = current.BudgetID
+	updated.CreatedAt = current.CreatedAt
+	return uc.txManager.RunInTransaction(ctx, func(ctx context.Context) error {
+		if err := uc.ensureCategoryFree(ctx, userID, categoryID, budgetID); err != nil {
+			return err
+		}
+		return uc.repo.UpdateBudget(ctx, updated)
+	})
+}
+
+func (uc *BudgetUseCase) DeleteBudget(ctx context.Context, userID uuid.UUID, budgetID uuid.UUID) error {
+	return uc.repo.DeleteBudget(ctx, userID, budgetID)
+}
+
+// AcceptRecommendations saves the recommended limits as monthly budgets in one
+// transaction. Existing budgets of the same categories are overwritten, other
+// budgets stay as they are.
+func (uc *BudgetUseCase) AcceptRecommendations(ctx context.Context, userID uuid.UUID, plannedTotal int64, months int, includeHidden bool, accountIDs []uuid.UUID, rollover bool) ([]domain.Budget, error) {
+	recommendations, err := uc.recommender.GetBudgetRecommendations(ctx, userID, plannedTotal, months, categoryDomain.LeafDepth, includeHidden, accountIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	budgets := make([]domain.Budget, 0, len(recommendations))
+	err = uc.txManager.RunInTransaction(ctx, func(ctx context.Context) error {
+		for _, rec := range recommendations {
+			if rec.RecommendedLimit <= 0 {
+				continue
+			}
+			budget, err := domain.NewBudget(userID, rec.CategoryID, rec.RecommendedLimit, string(domain.PeriodMonthly), nil, rollover, nil)
+			if err != nil {
+				return err
+			}
+			budget.CategoryName = rec.CategoryName
+
+			current, err := uc.repo.GetBudgetByCategory(ctx, userID, rec.CategoryID)
+			switch {
+			case err == nil:
+				budget.BudgetID = current.BudgetID
+				budget.CreatedAt = current.CreatedAt
+				if err := uc.repo.UpdateBudget(ctx, budget); err != nil {
+					return err
+				}
+			case errors.Is(err, domain.ErrBudgetNotFound):
+				if budget.BudgetID, err = uc.repo.AddBudget(ctx, budget); err != nil {
+					return err
+				}
+			default:
+				return err
+			}
+			budgets = append(budgets, *budget)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return budgets, nil
+}
+
+// GetStatus reports every budget for its period containing now.
+func (uc *BudgetUseCase) GetStatus(ctx context.Context, userID uuid.UUID, now time.Time, includeHidden bool, accountIDs []uuid.UUID) ([]domain.BudgetStatus, error) {
+	budgets, err := uc.repo.GetBudgetsByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Budgets with the same period share one spending query.
+	type period struct{ start, end time.Time }
+	type spent struct {
+		amount      int64
+		unconverted []string
+	}
+	spending := make(map[period]map[uuid.UUID]*spent)
+	spentIn := func(start, end time.Time, categoryID *uuid.UUID) (spent, error) {
+		key := period{start: start, end: end}
+		amounts, ok := spending[key]
+		if !ok {
+			rows, err := uc.repo.GetCategorySpending(ctx, userID, start, end, includeHidden, accountIDs)
+			if err != nil {
+				return spent{}, err
+			}
+			amounts = make(map[uuid.UUID]*spent, len(rows))
+			for _, row := range rows {
+				entry, ok := amounts[categoryKey(row.CategoryID)]
+				if !ok {
+					entry = &spent{}
+					amounts[categoryKey(row.CategoryID)] = entry
+				}
+				entry.amount += row.Amount
+				entry.unconverted = database.MergeCurrencies(entry.unconverted, row.UnconvertedCurrencies)
+			}
+			spending[key] = amounts
+		}
+		if entry, ok := amounts[categoryKey(categoryID)]; ok {
+			return *entry, nil
+		}
+		return spent{}, nil
+	}
+
+	statuses := make([]domain.BudgetStatus, 0, len(budgets))
+	for _, budget := range budgets {
+		start, end := budget.PeriodAt(now)
+		current, err := spentIn(start, end, budget.CategoryID)
+		if err != nil {
+			return nil, err
+		}
+
+		var previousSpent *int64
+		unconverted := current.unconverted
+		if budget.Rollover {
+			if prevStart, prevEnd, ok := budget.PreviousPeriod(start); ok {
+				previous, err := spentIn(prevStart, prevEnd, budget.CategoryID)
+				if err != nil {
+					return nil, err
+				}
+				previousSpent = &previous.amount
+				unconverted = database.MergeCurrencies(unconverted, previous.unconverted)
+			}
+		}
+
+		status := domain.NewBudgetStatus(budget, start, end, now, current.amount, previousSpent)
+		status.UnconvertedCurrencies = unconverted
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+func (uc *BudgetUseCase) checkCategory(ctx context.Context, userID uuid.UUID, categoryID *uuid.UUID) error {
+	if categoryID == nil {
+		return nil
+	}
+	if _, err := uc.categoryRepo.GetCategoryByID(ctx, userID, *categoryID); err != nil {
+		return fmt.Errorf("%w: %w", domain.ErrBudgetCategoryNotFound, err)
+	}
+	return nil
+}
+
+// ensureCategoryFree fails if another budget than exceptID already covers
+// the category.
+func (uc *BudgetUseCase) ensureCategoryFree(ctx context.Context, userID uuid.UUID, categoryID *uuid.UUID, exceptID uuid.UUID) error {
+	existing, err := uc.repo.GetBudgetByCategory(ctx, userID, categoryID)
+	if errors.Is(err, domain.ErrBudgetNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if existing.BudgetID != exceptID {
+		return domain.ErrBudgetAlreadyExists
+	}
+	return nil
+}
+
+// categoryKey maps uncategorized expenses to uuid.Nil.
+func categoryKey(categoryID *uuid.UUID) uuid.UUID {
+	if categoryID == nil {
+		return uuid.Nil
+	}
+	return *categoryID
+}
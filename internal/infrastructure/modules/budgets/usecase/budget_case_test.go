@@ -0,0 +1,155 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"Finance-Manager-System/internal/infrastructure/modules/budgets/domain"
+	categoryDomain "Finance-Manager-System/internal/infrastructure/modules/category/domain"
+	recommendationDomain "Finance-Manager-System/internal/infrastructure/modules/recommendations/domain"
+)
+
+type fakeBudgetRepo struct {
+	budgets  map[uuid.UUID]*domain.Budget
+	spending map[time.Time][]domain.CategorySpending
+}
+
+func newFakeBudgetRepo() *fakeBudgetRepo {
+	return &fakeBudgetRepo{
+		budgets:  make(map[uuid.UUID]*domain.Budget),
+		spending: make(map[time.Time][]domain.CategorySpending),
+	}
+}
+
+func (r *fakeBudgetRepo) AddBudget(ctx context.Context, budget *domain.Budget) (uuid.UUID, error) {
+	budget.BudgetID = uuid.New()
+	stored := *budget
+	r.budgets[budget.BudgetID] = &stored
+	return budget.BudgetID, nil
+}
+func (r *fakeBudgetRepo) GetBudgetsByUser(ctx context.Context, userID uuid.UUID) ([]domain.Budget, error) {
+	out := make([]domain.Budget, 0)
+	for _, b := range r.budgets {
+		if b.UserID == userID {
+			out = append(out, *b)
+		}
+	}
+	return out, nil
+}
+func (r *fakeBudgetRepo) GetBudgetByID(ctx context.Context, userID uuid.UUID, budgetID uuid.UUID) (*domain.Budget, error) {
+	b, ok := r.budgets[budgetID]
+	if !ok || b.UserID != userID {
+		return nil, domain.ErrBudgetNotFound
+	}
+	return b, nil
+}
+func (r *fakeBudgetRepo) GetBudgetByCategory(ctx context.Context, userID uuid.UUID, categoryID *uuid.UUID) (*domain.Budget, error) {
+	for _, b := range r.budgets {
+		if b.UserID == userID && categoryKey(b.CategoryID) == categoryKey(categoryID) {
+			return b, nil
+		}
+	}
+	return nil, domain.ErrBudgetNotFound
+}
+func (r *fakeBudgetRepo) UpdateBudget(ctx context.Context, budget *domain.Budget) error {
+	if _, ok := r.budgets[budget.BudgetID]; !ok {
+		return domain.ErrBudgetNotFound
+	}
+	stored := *budget
+	r.budgets[budget.BudgetID] = &stored
+	return nil
+}
+func (r *fakeBudgetRepo) DeleteBudget(ctx context.Context, userID uuid.UUID, budgetID uuid.UUID) error {
+	delete(r.budgets, budgetID)
+	return nil
+}
+func (r *fakeBudgetRepo) GetCategorySpending(ctx context.Context, userID uuid.UUID, start time.Time, end time.Time, includeHidden bool, accountIDs []uuid.UUID) ([]domain.CategorySpending, error) {
+	return r.spending[start], nil
+}
+
+type fakeBudgetCategoryRepo struct{}
+
+func (r *fakeBudgetCategoryRepo) GetCategoryByID(ctx context.Context, userID uuid.UUID, categoryID uuid.UUID) (*categoryDomain.Category, error) {
+	return &categoryDomain.Category{CategoryID: categoryID, UserID: userID}, nil
+}
+
+type fakeRecommender struct {
+	recommendations []recommendationDomain.BudgetRecommendation
+}
+
+func (r *fakeRecommender) GetBudgetRecommendations(ctx context.Context, userID uuid.UUID, plannedTotal int64, months int, depth int, includeHidden bool, accountIDs []uuid.UUID) ([]recommendationDomain.BudgetRecommendation, error) {
+	return r.recommendations, nil
+}
+
+type fakeBudgetTxManager struct{}
+
+func (m *fakeBudgetTxManager) RunInTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func TestAcceptRecommendationsAndStatus(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	foodID := uuid.New()
+	repo := newFakeBudgetRepo()
+	recommender := &fakeRecommender{recommendations: []recommendationDomain.BudgetRecommendation{
+		{CategoryID: &foodID, CategoryName: "Food", RecommendedLimit: 30000},
+		{CategoryID: nil, CategoryName: "Без категории", RecommendedLimit: 5000},
+		{CategoryID: nil, CategoryName: "Empty", RecommendedLimit: 0},
+	}}
+	uc := NewBudgetUseCase(repo, &fakeBudgetCategoryRepo{}, recommender, &fakeBudgetTxManager{})
+
+	existingID, err := uc.CreateBudget(ctx, userID, &foodID, 10000, "monthly", nil, false, nil)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if _, err := uc.CreateBudget(ctx, userID, &foodID, 10000, "monthly", nil, false, nil); err != domain.ErrBudgetAlreadyExists {
+		t.Fatalf("expected ErrBudgetAlreadyExists, got %v", err)
+	}
+
+	budgets, err := uc.AcceptRecommendations(ctx, userID, 35000, 3, false, nil, true)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if len(budgets) != 2 || len(repo.budgets) != 2 {
+		t.Fatalf("expected two budgets, got %d accepted and %d stored", len(budgets), len(repo.budgets))
+	}
+	food := repo.budgets[existingID]
+	if food == nil || food.Amount != 30000 || !food.Rollover {
+		t.Fatalf("expected existing food budget to be overwritten, got %+v", food)
+	}
+
+	// Pretend the budget started last month so the rollover applies.
+	now := time.Now().UTC()
+	current := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	previous := current.AddDate(0, -1, 0)
+	food.StartDate = previous
+	repo.spending[previous] = []domain.CategorySpending{{CategoryID: &foodID, Amount: 20000, UnconvertedCurrencies: []string{"USD"}}}
+	repo.spending[current] = []domain.CategorySpending{{CategoryID: &foodID, Amount: 15000}, {CategoryID: nil, Amount: 6000}}
+
+	statuses, err := uc.GetStatus(ctx, userID, now, false, nil)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	for _, status := range statuses {
+		switch {
+		case status.CategoryID != nil:
+			if status.RolloverAmount != 10000 || status.Available != 40000 || status.Spent != 15000 || status.Remaining != 25000 {
+				t.Fatalf("unexpected food status: %+v", status)
+			}
+			if len(status.UnconvertedCurrencies) != 1 || status.UnconvertedCurrencies[0] != "USD" {
+				t.Fatalf("expected USD reported as skipped in the rollover period, got %v", status.UnconvertedCurrencies)
+			}
+		default:
+			if status.Spent != 6000 || status.RolloverAmount != 0 || status.Alert != domain.AlertExceeded {
+				t.Fatalf("unexpected uncategorized status: %+v", status)
+			}
+			if status.UnconvertedCurrencies != nil {
+				t.Fatalf("expected no skipped currencies, got %v", status.UnconvertedCurrencies)
+			}
+		}
+	}
+}
@@ -7,19 +7,46 @@ import (
 	"github.com/google/uuid"
 )
 
+// Depth counts levels from the top of the tree: 1 is a top-level category.
+// LeafDepth means "do not roll up" in reports.
+const (
+	LeafDepth        = 0
+	MaxCategoryDepth = 5
+)
+
 var (
-	ErrCatEmptyUserID = errors.New("user ID cannot be empty (nil UUID)")
-	ErrCatEmptyName   = errors.New("category name cannot be empty")
-	ErrCatNameLong    = errors.New("category name cannot be longer than 255 characters")
+	ErrCatEmptyUserID        = errors.New("user ID cannot be empty (nil UUID)")
+	ErrCatEmptyName          = errors.New("category name cannot be empty")
+	ErrCatNameLong           = errors.New("category name cannot be longer than 255 characters")
+	ErrCatParentNotFound     = errors.New("parent category not found")
+	ErrCatParentTypeMismatch = errors.New("parent category must be of the same type (income or expense)")
+	ErrCatCycle              = errors.New("category cannot be moved under itself or its subcategory")
+	ErrCatTooDeep            = errors.New("category tree cannot be deeper than 5 levels")
+	ErrInvalidDepth          = errors.New("depth must be between 0 and 5")
 )
 
+// ValidateDepth accepts the depth a report rolls categories up to:
+// LeafDepth (no roll-up) or a tree level.
+func ValidateDepth(depth int) error {
+	if depth < LeafDepth || depth > MaxCategoryDepth {
+		return ErrInvalidDepth
+	}
+	return nil
+}
+
 type Category struct {
-	CategoryID   uuid.UUID `db:"category_id" json:"category_id"`
-	UserID       uuid.UUID `db:"user_id" json:"user_id"`
-	NameCategory string    `db:"name_category" json:"name_category"`
-	IsIncome     bool      `db:"is_income" json:"is_income"`
-	IsCustom     bool      `db:"is_custom" json:"is_custom"`
-	IconURL      *string   `db:"icon_url" json:"icon_url,omitempty"`
+	CategoryID   uuid.UUID  `db:"category_id" json:"category_id"`
+	UserID       uuid.UUID  `db:"user_id" json:"user_id"`
+	NameCategory string     `db:"name_category" json:"name_category"`
+	IsIncome     bool       `db:"is_income" json:"is_income"`
+	IsCustom     bool       `db:"is_custom" json:"is_custom"`
+	IconURL      *string    `db:"icon_url" json:"icon_url,omitempty"`
+	ParentID     *uuid.UUID `db:"parent_id" json:"parent_id,omitempty"`
+}
+
+type CategoryNode struct {
+	Category
+	Children []CategoryNode `json:"children"`
 }
 
 func NewCategory(
@@ -59,3 +86,117 @@ func NewCategory(
 		IconURL:      finalIconURL,
 	}, nil
 }
+
+// ValidateParent checks that category may be placed under parentID within the
+// user's categories: the parent exists, has the same type, is not inside the
+// category's own subtree and the resulting tree is not too deep. A new
+// category has a nil CategoryID.
+func ValidateParent(categories []Category, category Category, parentID *uuid.UUID) error {
+	if parentID == nil {
+		if category.CategoryID == uuid.Nil {
+			return nil
+		}
+		return checkDepth(categories, category, 0)
+	}
+
+	byID := make(map[uuid.UUID]Category, len(categories))
+	for _, c := range categories {
+		byID[c.CategoryID] = c
+	}
+
+	parent, ok := byID[*parentID]
+	if !ok {
+		return ErrCatParentNotFound
+	}
+	if parent.IsIncome != category.IsIncome {
+		return ErrCatParentTypeMismatch
+	}
+
+	parentDepth := 0
+	seen := make(map[uuid.UUID]bool)
+	for current := parent; ; {
+		if current.CategoryID == category.CategoryID || seen[current.CategoryID] {
+			return ErrCatCycle
+		}
+		seen[current.CategoryID] = true
+		parentDepth++
+
+		if current.ParentID == nil {
+			break
+		}
+		next, ok := byID[*current.ParentID]
+		if !ok {
+			break
+		}
+		current = next
+	}
+
+	return checkDepth(categories, category, parentDepth)
+}
+
+// checkDepth verifies the category's subtree fits below parentDepth levels.
+func checkDepth(categories []Category, category Category, parentDepth int) error {
+	height := 1
+	if category.CategoryID != uuid.Nil {
+		height = subtreeHeight(categories, category.CategoryID, make(map[uuid.UUID]bool))
+	}
+	if parentDepth+height > MaxCategoryDepth {
+		return ErrCatTooDeep
+	}
+	return nil
+}
+
+func subtreeHeight(categories []Category, categoryID uuid.UUID, seen map[uuid.UUID]bool) int {
+	if seen[categoryID] {
+		return 0
+	}
+	seen[categoryID] = true
+
+	height := 0
+	for _, c := range categories {
+		if c.ParentID != nil && *c.ParentID == categoryID {
+			if h := subtreeHeight(categories, c.CategoryID, seen); h > height {
+				height = h
+			}
+		}
+	}
+	return height + 1
+}
+
+// BuildCategoryTree nests categories under their parents. Categories whose
+// parent is missing are returned at the top level.
+func BuildCategoryTree(categories []Category) []CategoryNode {
+	present := make(map[uuid.UUID]bool, len(categories))
+	children := make(map[uuid.UUID][]Category)
+	for _, c := range categories {
+		present[c.CategoryID] = true
+	}
+
+	roots := make([]Category, 0)
+	for _, c := range categories {
+		if c.ParentID != nil && present[*c.ParentID] && *c.ParentID != c.CategoryID {
+			children[*c.ParentID] = append(children[*c.ParentID], c)
+			continue
+		}
+		roots = append(roots, c)
+	}
+
+	var build func(c Category, seen map[uuid.UUID]bool) CategoryNode
+	build = func(c Category, seen map[uuid.UUID]bool) CategoryNode {
+		seen[c.CategoryID] = true
+		node := CategoryNode{Category: c, Children: make([]CategoryNode, 0)}
+		for _, child := range children[c.CategoryID] {
+			if !seen[child.CategoryID] {
+				node.Children = append(node.Children, build(child, seen))
+			}
+		}
+		return node
+	}
+
+	seen := make(map[uuid.UUID]bool, len(categories))
+	tree := make([]CategoryNode, 0, len(roots))
+	for _, root := range roots {
+		tree = append(tree, build(root, seen))
+	}
+	return tree
+}
@@ -0,0 +1,64 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestValidateParent(t *testing.T) {
+	transport := Category{CategoryID: uuid.New(), NameCategory: "Транспорт"}
+	taxi := Category{CategoryID: uuid.New(), NameCategory: "Такси", ParentID: &transport.CategoryID}
+	comfort := Category{CategoryID: uuid.New(), NameCategory: "Комфорт", ParentID: &taxi.CategoryID}
+	salary := Category{CategoryID: uuid.New(), NameCategory: "Зарплата", IsIncome: true}
+	categories := []Category{transport, taxi, comfort, salary}
+
+	if err := ValidateParent(categories, Category{NameCategory: "Метро"}, &transport.CategoryID); err != nil {
+		t.Fatalf("expected nil error for a new subcategory, got %v", err)
+	}
+	if err := ValidateParent(categories, taxi, nil); err != nil {
+		t.Fatalf("expected nil error when moving to the top level, got %v", err)
+	}
+	if err := ValidateParent(categories, transport, &comfort.CategoryID); err != ErrCatCycle {
+		t.Fatalf("expected ErrCatCycle for a descendant parent, got %v", err)
+	}
+	if err := ValidateParent(categories, taxi, &taxi.CategoryID); err != ErrCatCycle {
+		t.Fatalf("expected ErrCatCycle for itself, got %v", err)
+	}
+	if err := ValidateParent(categories, taxi, &salary.CategoryID); err != ErrCatParentTypeMismatch {
+		t.Fatalf("expected ErrCatParentTypeMismatch, got %v", err)
+	}
+	missing := uuid.New()
+	if err := ValidateParent(categories, taxi, &missing); err != ErrCatParentNotFound {
+		t.Fatalf("expected ErrCatParentNotFound, got %v", err)
+	}
+
+	chain := []Category{{CategoryID: uuid.New()}}
+	for i := 1; i < MaxCategoryDepth; i++ {
+		parentID := chain[i-1].CategoryID
+		chain = append(chain, Category{CategoryID: uuid.New(), ParentID: &parentID})
+	}
+	deepest := chain[len(chain)-1].CategoryID
+	if err := ValidateParent(chain, Category{}, &deepest); err != ErrCatTooDeep {
+		t.Fatalf("expected ErrCatTooDeep, got %v", err)
+	}
+}
+
+func TestBuildCategoryTree(t *testing.T) {
+	transport := Category{CategoryID: uuid.New(), NameCategory: "Транспорт"}
+	taxi := Category{CategoryID: uuid.New(), NameCategory: "Такси", ParentID: &transport.CategoryID}
+	metro := Category{CategoryID: uuid.New(), NameCategory: "Метро", ParentID: &transport.CategoryID}
+	missing := uuid.New()
+	orphan := Category{CategoryID: uuid.New(), NameCategory: "Другое", ParentID: &missing}
+
+	tree := BuildCategoryTree([]Category{metro, taxi, transport, orphan})
+	if len(tree) != 2 {
+		t.Fatalf("expected two roots, got %d", len(tree))
+	}
+	if tree[0].CategoryID != transport.CategoryID || len(tree[0].Children) != 2 {
+		t.Fatalf("transport must hold both subcategories, got %+v", tree[0])
+	}
+	if tree[1].CategoryID != orphan.CategoryID {
+		t.Fatalf("category with a missing parent must be a root")
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/google/uuid"
 
 	"Finance-Manager-System/internal/infrastructure/middleware"
+	"Finance-Manager-System/internal/infrastructure/modules/category/domain"
 	"Finance-Manager-System/internal/infrastructure/modules/category/usecase"
 )
 
@@ -27,16 +28,23 @@ func (c *CategoryRouter) Route() chi.Router {
 
 	r.Post("/", c.CreateCategory)
 	r.Get("/", c.GetCategories)
+	r.Get("/tree", c.GetCategoryTree)
 	r.Put("/{id}", c.UpdateCategory)
+	r.Put("/{id}/parent", c.MoveCategory)
 	r.Delete("/{id}", c.DeleteCategory)
 
 	return r
 }
 
 type CreateCategoryReq struct {
-	Name     string  `json:"name"`
-	IsIncome bool    `json:"is_income"`
-	IconURL  *string `json:"icon_url"`
+	Name     string     `json:"name"`
+	IsIncome bool       `json:"is_income"`
+	IconURL  *string    `json:"icon_url"`
+	ParentID *uuid.UUID `json:"parent_id"`
+}
+
+type MoveCategoryReq struct {
+	ParentID *uuid.UUID `json:"parent_id"`
 }
 
 type UpdateCategoryReq struct {
@@ -53,7 +61,7 @@ type DeleteCategoryReq struct {
 // @Security ApiKeyAuth
 // @Accept json
 // @Produce json
-// @Param request body CreateCategoryReq true "Данные категории"
+// @Param request body CreateCategoryReq true "Данные категории (parent_id — родительская категория)"
 // @Success 202 {object} map[string]interface{}
 // @Router /api/v1/categories [post]
 func (c *CategoryRouter) CreateCategory(w http.ResponseWriter, r *http.Request) {
@@ -69,9 +77,9 @@ func (c *CategoryRouter) CreateCategory(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	id, err := c.categoryUC.CreateCustomCategory(r.Context(), userID, req.Name, req.IsIncome, req.IconURL)
+	id, err := c.categoryUC.CreateCustomCategory(r.Context(), userID, req.Name, req.IsIncome, req.IconURL, req.ParentID)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		c.mapHierarchyError(w, err)
 		return
 	}
 
@@ -106,6 +114,68 @@ func (c *CategoryRouter) GetCategories(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(categories)
 }
 
+// @Summary Получить дерево категорий
+// @Tags categories
+// @Security ApiKeyAuth
+// @Produce json
+// @Success 200 {array} domain.CategoryNode
+// @Router /api/v1/categories/tree [get]
+func (c *CategoryRouter) GetCategoryTree(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	tree, err := c.categoryUC.GetCategoryTree(r.Context(), userID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tree)
+}
+
+// @Summary Переместить категорию
+// @Description Переносит категорию вместе с подкатегориями под другого родителя; parent_id = null делает её категорией верхнего уровня
+// @Tags categories
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "ID категории"
+// @Param request body MoveCategoryReq true "Новый родитель"
+// @Success 202 {object} map[string]interface{}
+// @Router /api/v1/categories/{id}/parent [put]
+func (c *CategoryRouter) MoveCategory(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	catID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid category ID", http.StatusBadRequest)
+		return
+	}
+
+	var req MoveCategoryReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid json", http.StatusBadRequest)
+		return
+	}
+
+	if err := c.categoryUC.MoveCategory(r.Context(), userID, catID, req.ParentID); err != nil {
+		c.mapHierarchyError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]interface{}{"status": "success"})
+}
+
 // @Summary Обновить категорию
 // @Tags categories
 // @Security ApiKeyAuth
@@ -196,3 +266,21 @@ func (c *CategoryRouter) DeleteCategory(w http.ResponseWriter, r *http.Request)
 	w.WriteHeader(http.StatusAccepted)
 	json.NewEncoder(w).Encode(map[string]interface{}{"status": "success"})
 }
+
+func (c *CategoryRouter) mapHierarchyError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, domain.ErrCatParentNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, domain.ErrCatCycle):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, usecase.ErrCannotModifyDefaultCategory):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, domain.ErrCatEmptyName),
+		errors.Is(err, domain.ErrCatNameLong),
+		errors.Is(err, domain.ErrCatParentTypeMismatch),
+		errors.Is(err, domain.ErrCatTooDeep):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
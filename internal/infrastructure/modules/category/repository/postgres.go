@@ -19,6 +19,8 @@ type defaultCategory struct {
 	Name     string
 	IsIncome bool
 	IconURL  string
+	// Parent names a default category listed earlier.
+	Parent string
 }
 
 var defaultCategories = []defaultCategory{
@@ -32,6 +34,10 @@ var defaultCategories = []defaultCategory{
 	{Name: "Подписки", IsIncome: false, IconURL: "https://raw.githubusercontent.com/twitter/twemoji/gh-pages/svg/1f4f1.svg"},
 	{Name: "Переводы", IsIncome: false, IconURL: "https://raw.githubusercontent.com/twitter/twemoji/gh-pages/svg/1f4b8.svg"},
 	{Name: "Другое", IsIncome: false, IconURL: "https://raw.githubusercontent.com/twitter/twemoji/gh-pages/svg/1f4c2.svg"},
+	{Name: "Такси", IsIncome: false, IconURL: "https://raw.githubusercontent.com/twitter/twemoji/gh-pages/svg/1f695.svg", Parent: "Транспорт"},
+	{Name: "Метро", IsIncome: false, IconURL: "https://raw.githubusercontent.com/twitter/twemoji/gh-pages/svg/1f687.svg", Parent: "Транспорт"},
+	{Name: "Доставка еды", IsIncome: false, IconURL: "https://raw.githubusercontent.com/twitter/twemoji/gh-pages/svg/1f961.svg", Parent: "Кафе и рестораны"},
+	{Name: "Коммунальные услуги", IsIncome: false, IconURL: "https://raw.githubusercontent.com/twitter/twemoji/gh-pages/svg/1f4a1.svg", Parent: "Жилье"},
 	{Name: "Зарплата", IsIncome: true, IconURL: "https://raw.githubusercontent.com/twitter/twemoji/gh-pages/svg/1f4bc.svg"},
 	{Name: "Кэшбэк", IsIncome: true, IconURL: "https://raw.githubusercontent.com/twitter/twemoji/gh-pages/svg/1f4b3.svg"},
 	{Name: "Проценты", IsIncome: true, IconURL: "https://raw.githubusercontent.com/twitter/twemoji/gh-pages/svg/1f4c8.svg"},
@@ -46,13 +52,14 @@ func NewCategoryRepo(db *sqlx.DB) *CategoryRepo {
 func (r *CategoryRepo) EnsureDefaultCategories(ctx context.Context, userID uuid.UUID) error {
 	q := database.GetQueryer(ctx, r.db)
 	query := `
-		INSERT INTO Category (user_id, name_category, is_income, is_custom, icon_url)
-		VALUES ($1, $2, $3, false, $4)
+		INSERT INTO Category (user_id, name_category, is_income, is_custom, icon_url, parent_id)
+		VALUES ($1, $2, $3, false, $4,
+			(SELECT category_id FROM Category WHERE user_id = $1 AND name_category = $5 AND is_income = $3))
 		ON CONFLICT (name_category, is_income, user_id) DO NOTHING
 	`
 
 	for _, cat := range defaultCategories {
-		if _, err := q.ExecContext(ctx, query, userID, cat.Name, cat.IsIncome, cat.IconURL); err != nil {
+		if _, err := q.ExecContext(ctx, query, userID, cat.Name, cat.IsIncome, cat.IconURL, cat.Parent); err != nil {
 			return fmt.Errorf("failed to ensure default categories: %w", err)
 		}
 	}
@@ -64,8 +71,8 @@ func (r *CategoryRepo) AddCategory(ctx context.Context, category *domain.Categor
 	q := database.GetQueryer(ctx, r.db)
 
 	query := `
-        INSERT INTO Category (user_id, name_category, is_income, is_custom, icon_url) 
-        VALUES (:user_id, :name_category, :is_income, :is_custom, :icon_url)
+        INSERT INTO Category (user_id, name_category, is_income, is_custom, icon_url, parent_id) 
+        VALUES (:user_id, :name_category, :is_income, :is_custom, :icon_url, :parent_id)
         RETURNING category_id
     `
 
@@ -98,6 +105,21 @@ func (r *CategoryRepo) GetCategoriesByUser(ctx context.Context, userID uuid.UUID
 	return categories, nil
 }
 
+// GetCategoriesByUserForUpdate locks the returned rows until the transaction
+// ends, so a tree check made on them still holds when the change is saved.
+func (r *CategoryRepo) GetCategoriesByUserForUpdate(ctx context.Context, userID uuid.UUID) ([]domain.Category, error) {
+	q := database.GetQueryer(ctx, r.db)
+	categories := make([]domain.Category, 0)
+	query := `SELECT * FROM Category WHERE user_id = $1 ORDER BY name_category ASC FOR UPDATE`
+
+	err := q.SelectContext(ctx, &categories, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get categories: %w", err)
+	}
+
+	return categories, nil
+}
+
 func (r *CategoryRepo) GetCategoryByID(ctx context.Context, userID uuid.UUID, categoryID uuid.UUID) (*domain.Category, error) {
 	q := database.GetQueryer(ctx, r.db)
 	var cat domain.Category
@@ -154,3 +176,35 @@ func (r *CategoryRepo) DeleteCategory(ctx context.Context, userID uuid.UUID, cat
 
 	return nil
 }
+
+func (r *CategoryRepo) UpdateCategoryParent(ctx context.Context, userID uuid.UUID, categoryID uuid.UUID, parentID *uuid.UUID) error {
+	q := database.GetQueryer(ctx, r.db)
+	query := `UPDATE Category SET parent_id = $1 WHERE category_id = $2 AND user_id = $3`
+
+	result, err := q.ExecContext(ctx, query, parentID, categoryID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to move category: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check affected rows: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("category not found")
+	}
+
+	return nil
+}
+
+// ReparentChildren moves the direct subcategories of oldParentID under newParentID.
+func (r *CategoryRepo) ReparentChildren(ctx context.Context, userID uuid.UUID, oldParentID uuid.UUID, newParentID *uuid.UUID) error {
+	q := database.GetQueryer(ctx, r.db)
+	query := `UPDATE Category SET parent_id = $1 WHERE parent_id = $2 AND user_id = $3`
+
+	if _, err := q.ExecContext(ctx, query, newParentID, oldParentID, userID); err != nil {
+		return fmt.Errorf("failed to move subcategories: %w", err)
+	}
+
+	return nil
+}
@@ -19,9 +19,12 @@ var (
 type CategoryRepository interface {
 	AddCategory(ctx context.Context, category *domain.Category) (uuid.UUID, error)
 	GetCategoriesByUser(ctx context.Context, userID uuid.UUID) ([]domain.Category, error)
+	GetCategoriesByUserForUpdate(ctx context.Context, userID uuid.UUID) ([]domain.Category, error)
 	GetCategoryByID(ctx context.Context, userID uuid.UUID, categoryID uuid.UUID) (*domain.Category, error)
 	UpdateCategory(ctx context.Context, categoryID uuid.UUID, userID uuid.UUID, newName string, newIconURL *string) error
 	DeleteCategory(ctx context.Context, userID uuid.UUID, categoryID uuid.UUID) error
+	UpdateCategoryParent(ctx context.Context, userID uuid.UUID, categoryID uuid.UUID, parentID *uuid.UUID) error
+	ReparentChildren(ctx context.Context, userID uuid.UUID, oldParentID uuid.UUID, newParentID *uuid.UUID) error
 }
 
 type TransactionCategoryUpdater interface {
@@ -42,20 +45,71 @@ func NewCategoryUseCase(cr CategoryRepository, tr TransactionCategoryUpdater, tm
 	}
 }
 
-func (uc *CategoryUseCase) CreateCustomCategory(ctx context.Context, userID uuid.UUID, name string, isIncome bool, iconURL *string) (uuid.UUID, error) {
+func (uc *CategoryUseCase) CreateCustomCategory(ctx context.Context, userID uuid.UUID, name string, isIncome bool, iconURL *string, parentID *uuid.UUID) (uuid.UUID, error) {
 	cat, err := domain.NewCategory(userID, name, isIncome, true, iconURL)
 	if err != nil {
 		return uuid.Nil, fmt.Errorf("validation failed: %w", err)
 	}
 
-	generatedID, err := uc.catRepo.AddCategory(ctx, cat)
+	var generatedID uuid.UUID
+	err = uc.txManager.RunInTransaction(ctx, func(ctx context.Context) error {
+		if parentID != nil {
+			categories, err := uc.catRepo.GetCategoriesByUserForUpdate(ctx, userID)
+			if err != nil {
+				return fmt.Errorf("failed to fetch categories: %w", err)
+			}
+			if err := domain.ValidateParent(categories, *cat, parentID); err != nil {
+				return err
+			}
+			cat.ParentID = parentID
+		}
+
+		generatedID, err = uc.catRepo.AddCategory(ctx, cat)
+		if err != nil {
+			return fmt.Errorf("failed to save category: %w", err)
+		}
+		return nil
+	})
 	if err != nil {
-		return uuid.Nil, fmt.Errorf("failed to save category: %w", err)
+		return uuid.Nil, err
 	}
 
 	return generatedID, nil
 }
 
+// MoveCategory places a category under parentID, or at the top level when
+// parentID is nil. Its subcategories move with it. The user's categories stay
+// locked until the move commits, so two concurrent moves cannot form a cycle.
+func (uc *CategoryUseCase) MoveCategory(ctx context.Context, userID uuid.UUID, categoryID uuid.UUID, parentID *uuid.UUID) error {
+	return uc.txManager.RunInTransaction(ctx, func(ctx context.Context) error {
+		cat, err := uc.catRepo.GetCategoryByID(ctx, userID, categoryID)
+		if err != nil {
+			return fmt.Errorf("category not found: %w", err)
+		}
+
+		categories, err := uc.catRepo.GetCategoriesByUserForUpdate(ctx, userID)
+		if err != nil {
+			return fmt.Errorf("failed to fetch categories: %w", err)
+		}
+		if err := domain.ValidateParent(categories, *cat, parentID); err != nil {
+			return err
+		}
+
+		if err := uc.catRepo.UpdateCategoryParent(ctx, userID, categoryID, parentID); err != nil {
+			return fmt.Errorf("failed to move category: %w", err)
+		}
+		return nil
+	})
+}
+
+func (uc *CategoryUseCase) GetCategoryTree(ctx context.Context, userID uuid.UUID) ([]domain.CategoryNode, error) {
+	categories, err := uc.GetUserCategories(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return domain.BuildCategoryTree(categories), nil
+}
+
 func (uc *CategoryUseCase) GetUserCategories(ctx context.Context, userID uuid.UUID) ([]domain.Category, error) {
 	if userID == uuid.Nil {
 		return nil, domain.ErrCatEmptyUserID
@@ -117,6 +171,10 @@ func (uc *CategoryUseCase) DeleteCategory(ctx context.Context, userID uuid.UUID,
 			return fmt.Errorf("failed to move transactions: %w", err)
 		}
 
+		if err := uc.catRepo.ReparentChildren(ctx, userID, categoryID, cat.ParentID); err != nil {
+			return fmt.Errorf("failed to move subcategories: %w", err)
+		}
+
 		if err := uc.catRepo.DeleteCategory(ctx, userID, categoryID); err != nil {
 			return fmt.Errorf("failed to delete category: %w", err)
 		}
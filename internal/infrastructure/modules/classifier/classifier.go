@@ -0,0 +1,181 @@
+// Package classifier implements a per-user naive Bayes categoriser trained on
+// the user's own categorised transactions.
+package classifier
+
+import (
+	"math"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// DefaultThreshold is the confidence below which a prediction is not
+	// trusted and the transaction is left for review.
+	DefaultThreshold = 0.6
+	// MinSamples is the number of categorised transactions of one direction
+	// needed before the model is used for that direction.
+	MinSamples = 20
+	// MaxSamples bounds the history loaded for training.
+	MaxSamples = 5000
+
+	smoothing = 1.0
+)
+
+// Sample is a categorised transaction the model learns from.
+type Sample struct {
+	CategoryID  uuid.UUID `db:"category_id"`
+	Description string    `db:"name_transaction"`
+	MCCCode     *string   `db:"mcc_code"`
+	Amount      int64     `db:"amount"`
+	IsIncome    bool      `db:"is_income"`
+}
+
+type Prediction struct {
+	CategoryID uuid.UUID
+	Confidence float64
+}
+
+type classStats struct {
+	samples int
+	tokens  int
+	counts  map[string]int
+}
+
+// directionModel holds the classes of income or of expense transactions, which
+// never share categories.
+type directionModel struct {
+	samples int
+	classes map[uuid.UUID]*classStats
+	vocab   map[string]struct{}
+}
+
+type Model struct {
+	income  directionModel
+	expense directionModel
+}
+
+// Train builds a multinomial naive Bayes model over description tokens, the
+// MCC code and the amount bucket.
+func Train(samples []Sample) *Model {
+	m := &Model{
+		income:  directionModel{classes: make(map[uuid.UUID]*classStats), vocab: make(map[string]struct{})},
+		expense: directionModel{classes: make(map[uuid.UUID]*classStats), vocab: make(map[string]struct{})},
+	}
+	for _, s := range samples {
+		if s.CategoryID == uuid.Nil {
+			continue
+		}
+		d := m.direction(s.IsIncome)
+		stats, ok := d.classes[s.CategoryID]
+		if !ok {
+			stats = &classStats{counts: make(map[string]int)}
+			d.classes[s.CategoryID] = stats
+		}
+		d.samples++
+		stats.samples++
+		for _, f := range Features(s.Description, s.MCCCode, s.Amount) {
+			stats.counts[f]++
+			stats.tokens++
+			d.vocab[f] = struct{}{}
+		}
+	}
+	return m
+}
+
+// Ready reports whether there is enough history to classify transactions of
+// the given direction.
+func (m *Model) Ready(isIncome bool) bool {
+	d := m.direction(isIncome)
+	return d.samples >= MinSamples && len(d.classes) >= 2
+}
+
+// Predict returns the most likely category with its posterior probability.
+// ok is false when the model is not ready for the direction or the
+// transaction has no features.
+func (m *Model) Predict(description string, mccCode *string, amount int64, isIncome bool) (Prediction, bool) {
+	if !m.Ready(isIncome) {
+		return Prediction{}, false
+	}
+	features := Features(description, mccCode, amount)
+	if len(features) == 0 {
+		return Prediction{}, false
+	}
+
+	d := m.direction(isIncome)
+	vocab := float64(len(d.vocab))
+	scores := make(map[uuid.UUID]float64, len(d.classes))
+	best := math.Inf(-1)
+	var bestID uuid.UUID
+	for id, stats := range d.classes {
+		score := math.Log(float64(stats.samples) / float64(d.samples))
+		denominator := float64(stats.tokens) + smoothing*vocab
+		for _, f := range features {
+			score += math.Log((float64(stats.counts[f]) + smoothing) / denominator)
+		}
+		scores[id] = score
+		// Ties are broken by id so predictions do not depend on map order.
+		if score > best || (score == best && id.String() < bestID.String()) {
+			best = score
+			bestID = id
+		}
+	}
+
+	var total float64
+	for _, score := range scores {
+		total += math.Exp(score - best)
+	}
+	return Prediction{CategoryID: bestID, Confidence: 1 / total}, true
+}
+
+func (m *Model) direction(isIncome bool) *directionModel {
+	if isIncome {
+		return &m.income
+	}
+	return &m.expense
+}
+
+// Features turns a transaction into the tokens the model counts. Digits-only
+// words such as card numbers and dates are dropped from the description.
+func Features(description string, mccCode *string, amount int64) []string {
+	features := make([]string, 0, 8)
+	words := strings.FieldsFunc(strings.ToLower(description), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, word := range words {
+		if utf8.RuneCountInString(word) < 2 || isDigits(word) {
+			continue
+		}
+		features = append(features, "w:"+word)
+	}
+	if mccCode != nil {
+		if code := strings.TrimSpace(*mccCode); code != "" {
+			features = append(features, "mcc:"+code)
+		}
+	}
+	if bucket, ok := amountBucket(amount); ok {
+		features = append(features, bucket)
+	}
+	return features
+}
+
+// amountBucket groups amounts (in minor units) by half an order of magnitude
+// of the major unit: 1-3, 3-10, 10-31 and so on.
+func amountBucket(amount int64) (string, bool) {
+	if amount < 100 {
+		return "", false
+	}
+	bucket := int(math.Floor(2 * math.Log10(float64(amount)/100)))
+	return "amt:" + string(rune('a'+bucket)), true
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
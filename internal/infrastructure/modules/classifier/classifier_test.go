@@ -0,0 +1,70 @@
+package classifier
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func strPtr(value string) *string { return &value }
+
+func trainingSet(food, taxi, salary uuid.UUID) []Sample {
+	samples := make([]Sample, 0, 30)
+	for i := 0; i < 12; i++ {
+		samples = append(samples,
+			Sample{CategoryID: food, Description: "PEREKRESTOK MOSCOW 1234", MCCCode: strPtr("5411"), Amount: 150000},
+			Sample{CategoryID: taxi, Description: "YANDEX.GO ride", MCCCode: strPtr("4121"), Amount: 45000},
+		)
+	}
+	for i := 0; i < 5; i++ {
+		samples = append(samples, Sample{CategoryID: salary, Description: "Зарплата", Amount: 10000000, IsIncome: true})
+	}
+	return samples
+}
+
+func TestPredict(t *testing.T) {
+	food, taxi, salary := uuid.New(), uuid.New(), uuid.New()
+	model := Train(trainingSet(food, taxi, salary))
+
+	if !model.Ready(false) {
+		t.Fatalf("expense model must be ready")
+	}
+	if model.Ready(true) {
+		t.Fatalf("income model with one class and few samples must not be ready")
+	}
+	if _, ok := model.Predict("Зарплата", nil, 10000000, true); ok {
+		t.Fatalf("prediction must be refused while the model is not ready")
+	}
+
+	prediction, ok := model.Predict("Perekrestok Tverskaya 99", nil, 120000, false)
+	if !ok || prediction.CategoryID != food || prediction.Confidence < DefaultThreshold {
+		t.Fatalf("expected a confident food prediction, got %+v %v", prediction, ok)
+	}
+
+	prediction, ok = model.Predict("YANDEX.GO", strPtr("4121"), 30000, false)
+	if !ok || prediction.CategoryID != taxi || prediction.Confidence < 0.9 {
+		t.Fatalf("expected a confident taxi prediction, got %+v %v", prediction, ok)
+	}
+
+	prediction, ok = model.Predict("Unknown merchant", nil, 0, false)
+	if !ok || prediction.Confidence >= DefaultThreshold {
+		t.Fatalf("unseen merchant must have low confidence, got %+v %v", prediction, ok)
+	}
+
+	if _, ok := model.Predict("1234 5678", nil, 0, false); ok {
+		t.Fatalf("transaction without features must not be classified")
+	}
+}
+
+func TestFeatures(t *testing.T) {
+	got := Features("Оплата в KOFEMANIA 12.05 *4411", strPtr(" 5814 "), 35000)
+	want := []string{"w:оплата", "w:kofemania", "mcc:5814", "amt:f"}
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+	}
+}
@@ -0,0 +1,624 @@
+package csvstatement
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/charmap"
+
+	"Finance-Manager-System/internal/infrastructure/modules/statement"
+)
+
+const (
+	FormatName    = "csv"
+	MappingOption = "mapping"
+)
+
+var (
+	ErrInvalidCSV     = errors.New("invalid csv statement")
+	ErrInvalidMapping = errors.New("invalid csv column mapping")
+	ErrColumnNotFound = errors.New("csv column not found")
+)
+
+type SignConvention string
+
+const (
+	// SignNegativeExpense: negative amounts are expenses, positive are income.
+	SignNegativeExpense SignConvention = "negative_expense"
+	// SignNegativeIncome: negative amounts are income, positive are expenses.
+	SignNegativeIncome SignConvention = "negative_income"
+	// SignDirectionColumn: amounts are unsigned, DirectionColumn tells the type.
+	SignDirectionColumn SignConvention = "direction_column"
+)
+
+// Mapping describes how CSV columns map onto statement fields. A column is
+// referenced by its header name (case-insensitive) or by a 1-based index.
+type Mapping struct {
+	Delimiter         string         `json:"delimiter" example:";"`
+	HasHeader         *bool          `json:"has_header" example:"true"`
+	SkipRows          int            `json:"skip_rows" example:"0"`
+	DateColumn        string         `json:"date_column" example:"Дата операции"`
+	DateFormat        string         `json:"date_format" example:"DD.MM.YYYY HH:mm:ss"`
+	Timezone          string         `json:"timezone" example:"Europe/Moscow"`
+	AmountColumn      string         `json:"amount_column" example:"Сумма операции"`
+	IncomeColumn      string         `json:"income_column"`
+	ExpenseColumn     string         `json:"expense_column"`
+	SignConvention    SignConvention `json:"sign_convention" example:"negative_expense"`
+	DirectionColumn   string         `json:"direction_column"`
+	IncomeValues      []string       `json:"income_values"`
+	DescriptionColumn string         `json:"description_column" example:"Описание"`
+	MCCColumn         string         `json:"mcc_column" example:"MCC"`
+	CurrencyColumn    string         `json:"currency_column" example:"Валюта операции"`
+	Currency          string         `json:"currency" example:"RUB"`
+	DecimalSeparator  string         `json:"decimal_separator" example:","`
+	ExternalIDColumn  string         `json:"external_id_column"`
+	BalanceColumn     string         `json:"balance_column"`
+	Balance           *int64         `json:"balance"`
+	AccountNumber     string         `json:"account_number"`
+	BankName          string         `json:"bank_name"`
+}
+
+var defaultIncomeValues = []string{"income", "credit", "cr", "+", "доход", "пополнение", "зачисление", "приход"}
+
+var columnSynonyms = map[string][]string{
+	"date":        {"date", "transaction date", "posted date", "booking date", "дата", "дата операции", "дата платежа"},
+	"amount":      {"amount", "sum", "сумма", "сумма операции", "сумма платежа"},
+	"description": {"description", "details", "payee", "memo", "name", "описание", "назначение платежа", "комментарий"},
+	"mcc":         {"mcc", "mcc code", "мсс"},
+	"currency":    {"currency", "валюта", "валюта операции"},
+}
+
+var defaultDateLayouts = []string{
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05Z07:00",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"02.01.2006 15:04:05",
+	"02.01.2006 15:04",
+	"02.01.2006",
+	"02.01.06",
+	"2006/01/02",
+	"02/01/2006",
+}
+
+type Parser struct{}
+
+func NewParser() *Parser {
+	return &Parser{}
+}
+
+func (p *Parser) Name() string {
+	return FormatName
+}
+
+// Detect accepts delimited text whose date and amount columns can be resolved
+// either from the supplied mapping or from well-known header names.
+func (p *Parser) Detect(src statement.Source) bool {
+	if len(src.Data) == 0 || bytes.IndexByte(src.Data, 0) >= 0 || bytes.HasPrefix(src.Data, []byte("%PDF-")) {
+		return false
+	}
+	head := strings.ToUpper(string(src.Data[:min(len(src.Data), 1024)]))
+	if strings.Contains(head, "OFXHEADER") || strings.Contains(head, "<OFX>") {
+		return false
+	}
+	mapping, err := parseMapping(src.Options)
+	if err != nil {
+		return false
+	}
+	records, _, err := readRecords(src.Data, mapping)
+	if err != nil || len(records) == 0 {
+		return false
+	}
+	_, err = resolveColumns(records[0], mapping)
+	return err == nil
+}
+
+func (p *Parser) Parse(src statement.Source) (*statement.Statement, error) {
+	mapping, err := parseMapping(src.Options)
+	if err != nil {
+		return nil, err
+	}
+	return ParseStatement(src.Data, mapping)
+}
+
+func ParseStatement(data []byte, mapping Mapping) (*statement.Statement, error) {
+	if len(data) == 0 {
+		return nil, ErrInvalidCSV
+	}
+	loc := time.UTC
+	if mapping.Timezone != "" {
+		parsedLoc, err := time.LoadLocation(mapping.Timezone)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unknown timezone %q", ErrInvalidMapping, mapping.Timezone)
+		}
+		loc = parsedLoc
+	}
+
+	records, firstLine, err := readRecords(data, mapping)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("%w: file has no rows", ErrInvalidCSV)
+	}
+	cols, err := resolveColumns(records[0], mapping)
+	if err != nil {
+		return nil, err
+	}
+	if hasHeader(mapping) {
+		records = records[1:]
+		firstLine++
+	}
+
+	stmt := &statement.Statement{
+		Format:        FormatName,
+		BankName:      mapping.BankName,
+		AccountType:   "imported_csv",
+		AccountNumber: strings.TrimSpace(mapping.AccountNumber),
+		Currency:      normalizeCurrency(mapping.Currency),
+	}
+	if mapping.Balance != nil {
+		stmt.Balance = *mapping.Balance
+		stmt.HasBalance = true
+	}
+
+	occurrences := make(map[string]int)
+	var latestBalanceAt time.Time
+	for i, record := range records {
+		line := firstLine + i
+		if isBlank(record) {
+			continue
+		}
+		entry, rowBalance, rowErr := parseRow(record, cols, mapping, loc)
+		if rowErr != nil {
+			stmt.Warnings = append(stmt.Warnings, fmt.Sprintf("line %d: %v", line, rowErr))
+			continue
+		}
+
+		if cols.currency >= 0 {
+			rowCurrency := normalizeCurrency(cell(record, cols.currency))
+			if stmt.Currency == "" {
+				stmt.Currency = rowCurrency
+			}
+			if rowCurrency != "" && rowCurrency != stmt.Currency {
+				stmt.Warnings = append(stmt.Warnings, fmt.Sprintf("line %d: currency %s differs from statement currency %s", line, rowCurrency, stmt.Currency))
+				continue
+			}
+		}
+
+		if entry.ExternalID == nil {
+			key := buildExternalID(entry.CompletedAt, entry.Amount, entry.IsIncome, entry.Description)
+			occurrences[key]++
+			if n := occurrences[key]; n > 1 {
+				key = buildExternalID(entry.CompletedAt, entry.Amount, entry.IsIncome, entry.Description+"#"+strconv.Itoa(n))
+			}
+			entry.ExternalID = &key
+		}
+
+		if rowBalance != nil && mapping.Balance == nil && (!stmt.HasBalance || entry.CompletedAt.After(latestBalanceAt)) {
+			stmt.Balance = *rowBalance
+			stmt.HasBalance = true
+			latestBalanceAt = entry.CompletedAt
+		}
+
+		stmt.Transactions = append(stmt.Transactions, entry)
+	}
+
+	if len(stmt.Transactions) == 0 {
+		return nil, fmt.Errorf("%w: no transactions found", ErrInvalidCSV)
+	}
+	return stmt, nil
+}
+
+type columns struct {
+	date, amount, income, expense, direction, description, mcc, currency, externalID, balance int
+}
+
+func parseMapping(options map[string]string) (Mapping, error) {
+	var mapping Mapping
+	raw := strings.TrimSpace(options[MappingOption])
+	if raw == "" {
+		return mapping, nil
+	}
+	if err := json.Unmarshal([]byte(raw), &mapping); err != nil {
+		return mapping, fmt.Errorf("%w: %v", ErrInvalidMapping, err)
+	}
+	return mapping, nil
+}
+
+func hasHeader(mapping Mapping) bool {
+	return mapping.HasHeader == nil || *mapping.HasHeader
+}
+
+// readRecords decodes the file (UTF-8 or Windows-1251), drops SkipRows lines
+// and splits the rest. It returns the 1-based line number of the first record.
+func readRecords(data []byte, mapping Mapping) ([][]string, int, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	if !utf8.Valid(data) {
+		decoded, err := charmap.Windows1251.NewDecoder().Bytes(data)
+		if err != nil {
+			return nil, 0, fmt.Errorf("%w: unsupported encoding", ErrInvalidCSV)
+		}
+		data = decoded
+	}
+	text := strings.ReplaceAll(string(data), "\r\n", "\n")
+
+	lines := strings.SplitAfter(text, "\n")
+	if mapping.SkipRows > 0 {
+		if mapping.SkipRows >= len(lines) {
+			return nil, 0, fmt.Errorf("%w: skip_rows exceeds file length", ErrInvalidMapping)
+		}
+		lines = lines[mapping.SkipRows:]
+	}
+	body := strings.Join(lines, "")
+
+	delimiter, err := resolveDelimiter(mapping.Delimiter, body)
+	if err != nil {
+		return nil, 0, err
+	}
+	reader := csv.NewReader(strings.NewReader(body))
+	reader.Comma = delimiter
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, 0, fmt.Errorf("%w: %v", ErrInvalidCSV, err)
+	}
+	return records, mapping.SkipRows + 1, nil
+}
+
+func resolveDelimiter(configured string, body string) (rune, error) {
+	switch configured {
+	case "":
+	case "\\t", "\t", "tab":
+		return '\t', nil
+	default:
+		r, size := utf8.DecodeRuneInString(configured)
+		if size != len(configured) || r == '"' || r == '\n' {
+			return 0, fmt.Errorf("%w: delimiter must be a single character", ErrInvalidMapping)
+		}
+		return r, nil
+	}
+
+	firstLine, _, _ := strings.Cut(body, "\n")
+	best, bestCount := ',', 0
+	for _, candidate := range []rune{';', '\t', ',', '|'} {
+		if count := strings.Count(firstLine, string(candidate)); count > bestCount {
+			best, bestCount = candidate, count
+		}
+	}
+	return best, nil
+}
+
+func resolveColumns(header []string, mapping Mapping) (columns, error) {
+	withHeader := hasHeader(mapping)
+	find := func(ref string, synonyms []string, required bool, name string) (int, error) {
+		ref = strings.TrimSpace(ref)
+		if ref == "" {
+			if !withHeader || len(synonyms) == 0 {
+				if required {
+					return -1, fmt.Errorf("%w: %s column is not mapped", ErrColumnNotFound, name)
+				}
+				return -1, nil
+			}
+			for _, synonym := range synonyms {
+				if idx := headerIndex(header, synonym); idx >= 0 {
+					return idx, nil
+				}
+			}
+			if required {
+				return -1, fmt.Errorf("%w: %s", ErrColumnNotFound, name)
+			}
+			return -1, nil
+		}
+		if n, err := strconv.Atoi(ref); err == nil {
+			if n < 1 || n > len(header) {
+				return -1, fmt.Errorf("%w: column index %d is out of range", ErrColumnNotFound, n)
+			}
+			return n - 1, nil
+		}
+		if withHeader {
+			if idx := headerIndex(header, ref); idx >= 0 {
+				return idx, nil
+			}
+		}
+		return -1, fmt.Errorf("%w: %q", ErrColumnNotFound, ref)
+	}
+
+	var (
+		cols columns
+		err  error
+	)
+	if cols.date, err = find(mapping.DateColumn, columnSynonyms["date"], true, "date"); err != nil {
+		return cols, err
+	}
+	if cols.income, err = find(mapping.IncomeColumn, nil, false, "income"); err != nil {
+		return cols, err
+	}
+	if cols.expense, err = find(mapping.ExpenseColumn, nil, false, "expense"); err != nil {
+		return cols, err
+	}
+	splitColumns := cols.income >= 0 || cols.expense >= 0
+	if cols.amount, err = find(mapping.AmountColumn, columnSynonyms["amount"], !splitColumns, "amount"); err != nil {
+		return cols, err
+	}
+	if cols.direction, err = find(mapping.DirectionColumn, nil, mapping.SignConvention == SignDirectionColumn, "direction"); err != nil {
+		return cols, err
+	}
+	if cols.description, err = find(mapping.DescriptionColumn, columnSynonyms["description"], false, "description"); err != nil {
+		return cols, err
+	}
+	if cols.mcc, err = find(mapping.MCCColumn, columnSynonyms["mcc"], false, "mcc"); err != nil {
+		return cols, err
+	}
+	if cols.currency, err = find(mapping.CurrencyColumn, columnSynonyms["currency"], false, "currency"); err != nil {
+		return cols, err
+	}
+	if cols.externalID, err = find(mapping.ExternalIDColumn, nil, false, "external_id"); err != nil {
+		return cols, err
+	}
+	if cols.balance, err = find(mapping.BalanceColumn, nil, false, "balance"); err != nil {
+		return cols, err
+	}
+
+	switch mapping.SignConvention {
+	case "", SignNegativeExpense, SignNegativeIncome, SignDirectionColumn:
+	default:
+		return cols, fmt.Errorf("%w: unknown sign_convention %q", ErrInvalidMapping, mapping.SignConvention)
+	}
+	switch mapping.DecimalSeparator {
+	case "", ".", ",":
+	default:
+		return cols, fmt.Errorf("%w: decimal_separator must be \".\" or \",\"", ErrInvalidMapping)
+	}
+	return cols, nil
+}
+
+func headerIndex(header []string, name string) int {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for i, h := range header {
+		if strings.ToLower(strings.TrimSpace(h)) == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func parseRow(record []string, cols columns, mapping Mapping, loc *time.Location) (statement.TransactionEntry, *int64, error) {
+	var entry statement.TransactionEntry
+
+	completedAt, err := parseDate(cell(record, cols.date), mapping.DateFormat, loc)
+	if err != nil {
+		return entry, nil, err
+	}
+	entry.CompletedAt = completedAt.UTC()
+
+	amount, isIncome, err := parseSignedAmount(record, cols, mapping)
+	if err != nil {
+		return entry, nil, err
+	}
+	if amount == 0 {
+		return entry, nil, errors.New("zero amount")
+	}
+	entry.Amount = amount
+	entry.IsIncome = isIncome
+
+	entry.Description = strings.Join(strings.Fields(cell(record, cols.description)), " ")
+	if entry.Description == "" {
+		entry.Description = "Операция по выписке"
+		entry.Warnings = append(entry.Warnings, "empty description")
+	}
+	if mcc := strings.TrimSpace(cell(record, cols.mcc)); mcc != "" {
+		if len(mcc) == 4 && isDigits(mcc) {
+			entry.MCCCode = &mcc
+		} else {
+			entry.Warnings = append(entry.Warnings, fmt.Sprintf("invalid MCC %q ignored", mcc))
+		}
+	}
+	if externalID := strings.TrimSpace(cell(record, cols.externalID)); externalID != "" {
+		entry.ExternalID = &externalID
+	}
+
+	var balance *int64
+	if raw := cell(record, cols.balance); strings.TrimSpace(raw) != "" {
+		value, err := parseAmount(raw, mapping.DecimalSeparator)
+		if err != nil {
+			return entry, nil, fmt.Errorf("invalid balance %q", raw)
+		}
+		balance = &value
+	}
+	return entry, balance, nil
+}
+
+func parseSignedAmount(record []string, cols columns, mapping Mapping) (int64, bool, error) {
+	if cols.income >= 0 || cols.expense >= 0 {
+		for _, side := range []struct {
+			idx      int
+			isIncome bool
+		}{{cols.income, true}, {cols.expense, false}} {
+			raw := strings.TrimSpace(cell(record, side.idx))
+			if raw == "" {
+				continue
+			}
+			value, err := parseAmount(raw, mapping.DecimalSeparator)
+			if err != nil {
+				return 0, false, fmt.Errorf("invalid amount %q", raw)
+			}
+			if value != 0 {
+				return abs(value), side.isIncome, nil
+			}
+		}
+		return 0, false, nil
+	}
+
+	raw := cell(record, cols.amount)
+	value, err := parseAmount(raw, mapping.DecimalSeparator)
+	if err != nil {
+		return 0, false, fmt.Errorf("invalid amount %q", raw)
+	}
+
+	switch mapping.SignConvention {
+	case SignNegativeIncome:
+		return abs(value), value < 0, nil
+	case SignDirectionColumn:
+		incomeValues := mapping.IncomeValues
+		if len(incomeValues) == 0 {
+			incomeValues = defaultIncomeValues
+		}
+		direction := strings.ToLower(strings.TrimSpace(cell(record, cols.direction)))
+		for _, v := range incomeValues {
+			if direction == strings.ToLower(strings.TrimSpace(v)) {
+				return abs(value), true, nil
+			}
+		}
+		return abs(value), false, nil
+	default:
+		return abs(value), value > 0, nil
+	}
+}
+
+func parseDate(raw string, format string, loc *time.Location) (time.Time, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return time.Time{}, errors.New("empty date")
+	}
+	layouts := defaultDateLayouts
+	if format != "" {
+		layouts = []string{dateLayout(format)}
+	}
+	for _, layout := range layouts {
+		if t, err := time.ParseInLocation(layout, raw, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q", raw)
+}
+
+// dateLayout converts formats like "DD.MM.YYYY HH:mm" to Go layouts. Strings
+// without such tokens are used as Go layouts as is.
+func dateLayout(format string) string {
+	replacer := strings.NewReplacer(
+		"YYYY", "2006",
+		"YY", "06",
+		"MM", "01",
+		"DD", "02",
+		"HH", "15",
+		"mm", "04",
+		"ss", "05",
+	)
+	return replacer.Replace(format)
+}
+
+// parseAmount parses money into minor units. Spaces, currency signs and
+// thousand separators are ignored; "(1.00)" and "1.00-" are negative. With an
+// empty separator the decimal mark is the last of "." and "," followed by at
+// most two digits.
+func parseAmount(raw string, decimalSeparator string) (int64, error) {
+	s := strings.TrimSpace(raw)
+	negative := false
+	if strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")") {
+		negative = true
+		s = s[1 : len(s)-1]
+	}
+	if strings.HasSuffix(s, "-") {
+		negative = true
+		s = strings.TrimSuffix(s, "-")
+	}
+	s = strings.Map(func(r rune) rune {
+		if unicode.IsDigit(r) || r == '.' || r == ',' || r == '-' || r == '+' {
+			return r
+		}
+		return -1
+	}, s)
+	if s == "" {
+		return 0, errors.New("empty amount")
+	}
+
+	if decimalSeparator == "" {
+		decimalSeparator = "."
+		if i := strings.LastIndexAny(s, ".,"); i >= 0 && len(s)-i-1 <= 2 {
+			decimalSeparator = string(s[i])
+		}
+	}
+	if decimalSeparator == "," {
+		s = strings.ReplaceAll(s, ".", "")
+		s = strings.ReplaceAll(s, ",", ".")
+	} else {
+		s = strings.ReplaceAll(s, ",", "")
+	}
+
+	value, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, err
+	}
+	minor := int64(math.Round(value * 100))
+	if negative {
+		minor = -abs(minor)
+	}
+	return minor, nil
+}
+
+func buildExternalID(ts time.Time, amount int64, isIncome bool, description string) string {
+	sign := "expense"
+	if isIncome {
+		sign = "income"
+	}
+	raw := "csv|" + ts.Format(time.RFC3339Nano) + "|" + strconv.FormatInt(amount, 10) + "|" + sign + "|" + strings.TrimSpace(description)
+	sum := sha1.Sum([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
+
+func normalizeCurrency(code string) string {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	switch code {
+	case "РУБ", "РУБ.", "RUR", "₽":
+		return "RUB"
+	}
+	return code
+}
+
+func cell(record []string, idx int) string {
+	if idx < 0 || idx >= len(record) {
+		return ""
+	}
+	return record[idx]
+}
+
+func isBlank(record []string) bool {
+	for _, v := range record {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func abs(v int64) int64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
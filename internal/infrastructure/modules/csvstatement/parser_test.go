@@ -0,0 +1,136 @@
+package csvstatement
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"golang.org/x/text/encoding/charmap"
+
+	"Finance-Manager-System/internal/infrastructure/modules/statement"
+)
+
+func TestParseStatementWithDefaultHeaders(t *testing.T) {
+	data := []byte("Дата операции;Сумма операции;Валюта операции;Описание;MCC\n" +
+		"10.05.2026 12:30:00;-1 250,50;RUB;Кофейня;5814\n" +
+		"11.05.2026 09:00:00;50 000,00;RUB;Зарплата;\n" +
+		"11.05.2026 09:00:00;50 000,00;RUB;Зарплата;\n")
+
+	stmt, err := ParseStatement(data, Mapping{})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if stmt.Currency != "RUB" || len(stmt.Transactions) != 3 || stmt.HasBalance {
+		t.Fatalf("unexpected statement: %#v", stmt)
+	}
+	coffee := stmt.Transactions[0]
+	if coffee.Amount != 125050 || coffee.IsIncome || coffee.MCCCode == nil || *coffee.MCCCode != "5814" {
+		t.Fatalf("unexpected expense row: %#v", coffee)
+	}
+	if !coffee.CompletedAt.Equal(time.Date(2026, 5, 10, 12, 30, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected date: %v", coffee.CompletedAt)
+	}
+	if !stmt.Transactions[1].IsIncome || stmt.Transactions[1].Amount != 5000000 {
+		t.Fatalf("unexpected income row: %#v", stmt.Transactions[1])
+	}
+	if *stmt.Transactions[1].ExternalID == *stmt.Transactions[2].ExternalID {
+		t.Fatalf("identical rows must get distinct external IDs")
+	}
+
+	again, _ := ParseStatement(data, Mapping{})
+	if *again.Transactions[2].ExternalID != *stmt.Transactions[2].ExternalID {
+		t.Fatalf("external IDs must be stable between imports")
+	}
+}
+
+func TestParseStatementWithMapping(t *testing.T) {
+	noHeader := false
+	data := []byte("skip me\n2026/05/10|Taxi|12.40|D|1,000.00\n2026/05/12|Refund|2.00|C|1,002.00\nbad-date|x|1.00|D|0\n")
+	mapping := Mapping{
+		Delimiter:         "|",
+		HasHeader:         &noHeader,
+		SkipRows:          1,
+		DateColumn:        "1",
+		DateFormat:        "YYYY/MM/DD",
+		DescriptionColumn: "2",
+		AmountColumn:      "3",
+		SignConvention:    SignDirectionColumn,
+		DirectionColumn:   "4",
+		IncomeValues:      []string{"C"},
+		BalanceColumn:     "5",
+		Currency:          "usd",
+	}
+
+	stmt, err := ParseStatement(data, mapping)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if len(stmt.Transactions) != 2 || len(stmt.Warnings) != 1 {
+		t.Fatalf("expected 2 rows and 1 warning, got %d rows, warnings %v", len(stmt.Transactions), stmt.Warnings)
+	}
+	if stmt.Transactions[0].IsIncome || !stmt.Transactions[1].IsIncome || stmt.Transactions[0].Amount != 1240 {
+		t.Fatalf("direction column was not applied: %#v", stmt.Transactions)
+	}
+	if !stmt.HasBalance || stmt.Balance != 100200 || stmt.Currency != "USD" {
+		t.Fatalf("balance must come from the latest row: %d %s", stmt.Balance, stmt.Currency)
+	}
+}
+
+func TestParseStatementSplitColumnsAndCP1251(t *testing.T) {
+	utf := "Дата,Приход,Расход,Назначение платежа\n2026-05-10,,300.00,Аптека\n2026-05-11,1000.00,,Возврат\n"
+	data, err := charmap.Windows1251.NewEncoder().Bytes([]byte(utf))
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+
+	stmt, err := ParseStatement(data, Mapping{IncomeColumn: "Приход", ExpenseColumn: "Расход"})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if stmt.Transactions[0].Description != "Аптека" || stmt.Transactions[0].IsIncome || stmt.Transactions[0].Amount != 30000 {
+		t.Fatalf("unexpected expense row: %#v", stmt.Transactions[0])
+	}
+	if !stmt.Transactions[1].IsIncome || stmt.Transactions[1].Amount != 100000 {
+		t.Fatalf("unexpected income row: %#v", stmt.Transactions[1])
+	}
+}
+
+func TestParseStatementRejectsUnknownColumn(t *testing.T) {
+	_, err := ParseStatement([]byte("a,b\n1,2\n"), Mapping{DateColumn: "when"})
+	if !errors.Is(err, ErrColumnNotFound) {
+		t.Fatalf("expected ErrColumnNotFound, got %v", err)
+	}
+}
+
+func TestParseAmount(t *testing.T) {
+	cases := map[string]int64{
+		"1 234,56 ₽": 123456,
+		"-1,234.56":  -123456,
+		"1,234":      123400,
+		"(15.00)":    -1500,
+		"15.5-":      -1550,
+		"+7":         700,
+	}
+	for raw, want := range cases {
+		got, err := parseAmount(raw, "")
+		if err != nil || got != want {
+			t.Fatalf("parseAmount(%q) = %d, %v; want %d", raw, got, err, want)
+		}
+	}
+}
+
+func TestParserDetect(t *testing.T) {
+	p := NewParser()
+	if !p.Detect(statement.Source{Data: []byte("date,amount,description\n2026-01-01,-5,x\n")}) {
+		t.Fatalf("csv with known headers must be detected")
+	}
+	if p.Detect(statement.Source{Data: []byte("foo,bar\n1,2\n")}) {
+		t.Fatalf("csv without date/amount columns must not be detected without mapping")
+	}
+	if !p.Detect(statement.Source{Data: []byte("foo,bar\n2026-01-01,2\n"), Options: map[string]string{MappingOption: `{"date_column":"foo","amount_column":"bar"}`}}) {
+		t.Fatalf("csv must be detected with an explicit mapping")
+	}
+	if p.Detect(statement.Source{Data: []byte("OFXHEADER:100\n<OFX>")}) {
+		t.Fatalf("ofx must not be detected as csv")
+	}
+}
@@ -0,0 +1,83 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const DefaultBaseCurrency = "RUB"
+
+var (
+	ErrEmptyUserID      = errors.New("user ID cannot be empty")
+	ErrInvalidCurrency  = errors.New("currency must be a 3-letter ISO 4217 code")
+	ErrSameCurrencyPair = errors.New("rate currencies must be different")
+	ErrInvalidRate      = errors.New("rate must be strictly greater than zero")
+	ErrInvalidRateDate  = errors.New("rate date cannot be empty")
+	ErrRateNotFound     = errors.New("exchange rate not found")
+)
+
+type ExchangeRate struct {
+	RateID       uuid.UUID `db:"rate_id" json:"rate_id"`
+	UserID       uuid.UUID `db:"user_id" json:"user_id"`
+	FromCurrency string    `db:"from_currency" json:"from_currency"`
+	ToCurrency   string    `db:"to_currency" json:"to_currency"`
+	RateDate     time.Time `db:"rate_date" json:"rate_date"`
+	Rate         float64   `db:"rate" json:"rate"`
+	CreatedAt    time.Time `db:"created_at" json:"created_at"`
+}
+
+type RateFilter struct {
+	FromCurrency string
+	ToCurrency   string
+	StartDate    *time.Time
+	EndDate      *time.Time
+}
+
+func NormalizeCurrency(code string) (string, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if len(code) != 3 {
+		return "", ErrInvalidCurrency
+	}
+	for _, r := range code {
+		if r < 'A' || r > 'Z' {
+			return "", ErrInvalidCurrency
+		}
+	}
+	return code, nil
+}
+
+func NewExchangeRate(userID uuid.UUID, fromCurrency, toCurrency string, rateDate time.Time, rate float64) (*ExchangeRate, error) {
+	if userID == uuid.Nil {
+		return nil, ErrEmptyUserID
+	}
+	from, err := NormalizeCurrency(fromCurrency)
+	if err != nil {
+		return nil, err
+	}
+	to, err := NormalizeCurrency(toCurrency)
+	if err != nil {
+		return nil, err
+	}
+	if from == to {
+		return nil, ErrSameCurrencyPair
+	}
+	if rate <= 0 {
+		return nil, ErrInvalidRate
+	}
+	if rateDate.IsZero() {
+		return nil, ErrInvalidRateDate
+	}
+
+	return &ExchangeRate{
+		RateID:       uuid.New(),
+		UserID:       userID,
+		FromCurrency: from,
+		ToCurrency:   to,
+		RateDate:     time.Date(rateDate.Year(), rateDate.Month(), rateDate.Day(), 0, 0, 0, 0, time.UTC),
+		Rate:         rate,
+		CreatedAt:    time.Now().UTC(),
+	}, nil
+}
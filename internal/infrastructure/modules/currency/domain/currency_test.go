@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestNewExchangeRateNormalizesInput(t *testing.T) {
+	rate, err := NewExchangeRate(uuid.New(), " usd", "rub ", time.Date(2025, 3, 14, 17, 30, 0, 0, time.UTC), 91.5)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if rate.FromCurrency != "USD" || rate.ToCurrency != "RUB" {
+		t.Fatalf("unexpected pair: %s/%s", rate.FromCurrency, rate.ToCurrency)
+	}
+	if !rate.RateDate.Equal(time.Date(2025, 3, 14, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("rate date must be truncated to a day: %v", rate.RateDate)
+	}
+}
+
+func TestNewExchangeRateValidation(t *testing.T) {
+	userID := uuid.New()
+	now := time.Now()
+
+	if _, err := NewExchangeRate(userID, "USD", "usd", now, 1); !errors.Is(err, ErrSameCurrencyPair) {
+		t.Fatalf("expected ErrSameCurrencyPair, got %v", err)
+	}
+	if _, err := NewExchangeRate(userID, "US", "RUB", now, 1); !errors.Is(err, ErrInvalidCurrency) {
+		t.Fatalf("expected ErrInvalidCurrency, got %v", err)
+	}
+	if _, err := NewExchangeRate(userID, "USD", "RUB", now, 0); !errors.Is(err, ErrInvalidRate) {
+		t.Fatalf("expected ErrInvalidRate, got %v", err)
+	}
+}
@@ -0,0 +1,278 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"Finance-Manager-System/internal/infrastructure/middleware"
+	"Finance-Manager-System/internal/infrastructure/modules/currency/domain"
+	"Finance-Manager-System/internal/infrastructure/modules/currency/usecase"
+)
+
+type CurrencyRouter struct {
+	currencyUC *usecase.CurrencyUseCase
+}
+
+func NewCurrencyRouter(currencyUC *usecase.CurrencyUseCase) *CurrencyRouter {
+	return &CurrencyRouter{currencyUC: currencyUC}
+}
+
+func (h *CurrencyRouter) Route() chi.Router {
+	r := chi.NewRouter()
+	r.Get("/base", h.GetBaseCurrency)
+	r.Put("/base", h.SetBaseCurrency)
+	r.Get("/rates", h.GetRates)
+	r.Post("/rates", h.AddRates)
+	r.Post("/rates/import", h.ImportRates)
+	r.Delete("/rates/{id}", h.DeleteRate)
+	return r
+}
+
+type SetBaseCurrencyReq struct {
+	Currency string `json:"currency"`
+}
+
+type RateReq struct {
+	FromCurrency string  `json:"from_currency"`
+	ToCurrency   string  `json:"to_currency"`
+	Date         string  `json:"date"`
+	Rate         float64 `json:"rate"`
+}
+
+type AddRatesReq struct {
+	Rates []RateReq `json:"rates"`
+}
+
+// @Summary Получить базовую валюту пользователя
+// @Tags currencies
+// @Security ApiKeyAuth
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/currencies/base [get]
+func (h *CurrencyRouter) GetBaseCurrency(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	currency, err := h.currencyUC.GetBaseCurrency(r.Context(), userID)
+	if err != nil {
+		h.mapError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"base_currency": currency})
+}
+
+// @Summary Изменить базовую валюту пользователя
+// @Tags currencies
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param request body SetBaseCurrencyReq true "Код валюты (ISO 4217)"
+// @Success 202 {object} map[string]interface{}
+// @Router /api/v1/currencies/base [put]
+func (h *CurrencyRouter) SetBaseCurrency(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req SetBaseCurrencyReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid json", http.StatusBadRequest)
+		return
+	}
+
+	currency, err := h.currencyUC.SetBaseCurrency(r.Context(), userID, req.Currency)
+	if err != nil {
+		h.mapError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]interface{}{"status": "success", "base_currency": currency})
+}
+
+// @Summary Получить курсы валют
+// @Tags currencies
+// @Security ApiKeyAuth
+// @Produce json
+// @Param from query string false "Исходная валюта"
+// @Param to query string false "Целевая валюта"
+// @Param start_date query string false "Начальная дата (YYYY-MM-DD)"
+// @Param end_date query string false "Конечная дата (YYYY-MM-DD)"
+// @Success 200 {array} domain.ExchangeRate
+// @Router /api/v1/currencies/rates [get]
+func (h *CurrencyRouter) GetRates(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	filter := domain.RateFilter{
+		FromCurrency: r.URL.Query().Get("from"),
+		ToCurrency:   r.URL.Query().Get("to"),
+	}
+	if start := r.URL.Query().Get("start_date"); start != "" {
+		parsed, err := time.Parse("2006-01-02", start)
+		if err != nil {
+			http.Error(w, "Invalid start_date", http.StatusBadRequest)
+			return
+		}
+		filter.StartDate = &parsed
+	}
+	if end := r.URL.Query().Get("end_date"); end != "" {
+		parsed, err := time.Parse("2006-01-02", end)
+		if err != nil {
+			http.Error(w, "Invalid end_date", http.StatusBadRequest)
+			return
+		}
+		filter.EndDate = &parsed
+	}
+
+	rates, err := h.currencyUC.GetRates(r.Context(), userID, filter)
+	if err != nil {
+		h.mapError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rates)
+}
+
+// @Summary Загрузить курсы валют
+// @Tags currencies
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param request body AddRatesReq true "Курсы на даты"
+// @Success 202 {object} map[string]interface{}
+// @Router /api/v1/currencies/rates [post]
+func (h *CurrencyRouter) AddRates(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req AddRatesReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid json", http.StatusBadRequest)
+		return
+	}
+
+	inputs := make([]usecase.RateInput, 0, len(req.Rates))
+	for _, rate := range req.Rates {
+		inputs = append(inputs, usecase.RateInput{
+			FromCurrency: rate.FromCurrency,
+			ToCurrency:   rate.ToCurrency,
+			Date:         rate.Date,
+			Rate:         rate.Rate,
+		})
+	}
+
+	saved, err := h.currencyUC.AddRates(r.Context(), userID, inputs)
+	if err != nil {
+		h.mapError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]interface{}{"status": "success", "saved_rates": saved})
+}
+
+// @Summary Загрузить курсы валют из CSV (date,from,to,rate)
+// @Tags currencies
+// @Security ApiKeyAuth
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "CSV файл с курсами"
+// @Success 202 {object} map[string]interface{}
+// @Router /api/v1/currencies/rates/import [post]
+func (h *CurrencyRouter) ImportRates(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	if err := r.ParseMultipartForm(25 << 20); err != nil {
+		http.Error(w, "Invalid multipart form", http.StatusBadRequest)
+		return
+	}
+
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		http.Error(w, "file is required", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	saved, err := h.currencyUC.ImportRatesCSV(r.Context(), userID, file)
+	if err != nil {
+		h.mapError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]interface{}{"status": "success", "saved_rates": saved})
+}
+
+// @Summary Удалить курс валюты
+// @Tags currencies
+// @Security ApiKeyAuth
+// @Produce json
+// @Param id path string true "ID курса"
+// @Success 202 {object} map[string]interface{}
+// @Router /api/v1/currencies/rates/{id} [delete]
+func (h *CurrencyRouter) DeleteRate(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	rateID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid rate ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.currencyUC.DeleteRate(r.Context(), userID, rateID); err != nil {
+		h.mapError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]interface{}{"status": "success"})
+}
+
+func (h *CurrencyRouter) mapError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, domain.ErrRateNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, domain.ErrInvalidCurrency),
+		errors.Is(err, domain.ErrSameCurrencyPair),
+		errors.Is(err, domain.ErrInvalidRate),
+		errors.Is(err, domain.ErrInvalidRateDate),
+		errors.Is(err, usecase.ErrInvalidRatesFile),
+		errors.Is(err, usecase.ErrNoRates):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
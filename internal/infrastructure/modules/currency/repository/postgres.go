@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"Finance-Manager-System/internal/infrastructure/database"
+	"Finance-Manager-System/internal/infrastructure/modules/currency/domain"
+)
+
+type CurrencyRepo struct {
+	db *sqlx.DB
+}
+
+func NewCurrencyRepo(db *sqlx.DB) *CurrencyRepo {
+	return &CurrencyRepo{db: db}
+}
+
+func (r *CurrencyRepo) GetBaseCurrency(ctx context.Context, userID uuid.UUID) (string, error) {
+	q := database.GetQueryer(ctx, r.db)
+	var currency string
+	if err := q.GetContext(ctx, &currency, `SELECT base_currency FROM Users WHERE user_id = $1`, userID); err != nil {
+		return "", fmt.Errorf("failed to get base currency: %w", err)
+	}
+	return currency, nil
+}
+
+func (r *CurrencyRepo) SetBaseCurrency(ctx context.Context, userID uuid.UUID, currency string) error {
+	q := database.GetQueryer(ctx, r.db)
+	query := `UPDATE Users SET base_currency = $1, updated_at = $2 WHERE user_id = $3`
+	if _, err := q.ExecContext(ctx, query, currency, time.Now().UTC(), userID); err != nil {
+		return fmt.Errorf("failed to set base currency: %w", err)
+	}
+	return nil
+}
+
+func (r *CurrencyRepo) UpsertRates(ctx context.Context, rates []*domain.ExchangeRate) (int, error) {
+	if len(rates) == 0 {
+		return 0, nil
+	}
+	q := database.GetQueryer(ctx, r.db)
+	query := `
+		INSERT INTO ExchangeRates (rate_id, user_id, from_currency, to_currency, rate_date, rate, created_at)
+		VALUES (:rate_id, :user_id, :from_currency, :to_currency, :rate_date, :rate, :created_at)
+		ON CONFLICT (user_id, from_currency, to_currency, rate_date)
+		DO UPDATE SET rate = EXCLUDED.rate, created_at = EXCLUDED.created_at
+	`
+	res, err := q.NamedExecContext(ctx, query, rates)
+	if err != nil {
+		return 0, fmt.Errorf("failed to save exchange rates: %w", err)
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	return int(rowsAffected), nil
+}
+
+func (r *CurrencyRepo) GetRates(ctx context.Context, userID uuid.UUID, filter domain.RateFilter) ([]domain.ExchangeRate, error) {
+	q := database.GetQueryer(ctx, r.db)
+	rates := make([]domain.ExchangeRate, 0)
+
+	query := `SELECT * FROM ExchangeRates WHERE user_id = $1`
+	args := []interface{}{userID}
+	argID := 2
+
+	if filter.FromCurrency != "" {
+		query += fmt.Sprintf(` AND from_currency = $%d`, argID)
+		args = append(args, filter.FromCurrency)
+		argID++
+	}
+	if filter.ToCurrency != "" {
+		query += fmt.Sprintf(` AND to_currency = $%d`, argID)
+		args = append(args, filter.ToCurrency)
+		argID++
+	}
+	if filter.StartDate != nil {
+		query += fmt.Sprintf(` AND rate_date >= $%d`, argID)
+		args = append(args, *filter.StartDate)
+		argID++
+	}
+	if filter.EndDate != nil {
+		query += fmt.Sprintf(` AND rate_date <= $%d`, argID)
+		args = append(args, *filter.EndDate)
+		argID++
+	}
+	query += ` ORDER BY rate_date DESC, from_currency ASC, to_currency ASC`
+
+	if err := q.SelectContext(ctx, &rates, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to get exchange rates: %w", err)
+	}
+	return rates, nil
+}
+
+func (r *CurrencyRepo) DeleteRate(ctx context.Context, userID uuid.UUID, rateID uuid.UUID) error {
+	q := database.GetQueryer(ctx, r.db)
+	res, err := q.ExecContext(ctx, `DELETE FROM ExchangeRates WHERE user_id = $1 AND rate_id = $2`, userID, rateID)
+	if err != nil {
+		return fmt.Errorf("failed to delete exchange rate: %w", err)
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check affected rows: %w", err)
+	}
+	if rowsAffected == 0 {
+		return domain.ErrRateNotFound
+	}
+	return nil
+}
@@ -0,0 +1,194 @@
+package usecase
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"Finance-Manager-System/internal/infrastructure/modules/currency/domain"
+)
+
+var (
+	ErrInvalidRatesFile = errors.New("invalid exchange rates file")
+	ErrNoRates          = errors.New("no exchange rates provided")
+)
+
+var rateDateLayouts = []string{"2006-01-02", "02.01.2006", "2006/01/02", time.RFC3339}
+
+type CurrencyRepository interface {
+	GetBaseCurrency(ctx context.Context, userID uuid.UUID) (string, error)
+	SetBaseCurrency(ctx context.Context, userID uuid.UUID, currency string) error
+	UpsertRates(ctx context.Context, rates []*domain.ExchangeRate) (int, error)
+	GetRates(ctx context.Context, userID uuid.UUID, filter domain.RateFilter) ([]domain.ExchangeRate, error)
+	DeleteRate(ctx context.Context, userID uuid.UUID, rateID uuid.UUID) error
+}
+
+type RateInput struct {
+	FromCurrency string
+	ToCurrency   string
+	Date         string
+	Rate         float64
+}
+
+type CurrencyUseCase struct {
+	repo CurrencyRepository
+}
+
+func NewCurrencyUseCase(repo CurrencyRepository) *CurrencyUseCase {
+	return &CurrencyUseCase{repo: repo}
+}
+
+func (uc *CurrencyUseCase) GetBaseCurrency(ctx context.Context, userID uuid.UUID) (string, error) {
+	return uc.repo.GetBaseCurrency(ctx, userID)
+}
+
+func (uc *CurrencyUseCase) SetBaseCurrency(ctx context.Context, userID uuid.UUID, currency string) (string, error) {
+	normalized, err := domain.NormalizeCurrency(currency)
+	if err != nil {
+		return "", err
+	}
+	if err := uc.repo.SetBaseCurrency(ctx, userID, normalized); err != nil {
+		return "", err
+	}
+	return normalized, nil
+}
+
+func (uc *CurrencyUseCase) AddRates(ctx context.Context, userID uuid.UUID, inputs []RateInput) (int, error) {
+	if len(inputs) == 0 {
+		return 0, ErrNoRates
+	}
+	rates := make([]*domain.ExchangeRate, 0, len(inputs))
+	for _, input := range inputs {
+		rateDate, err := parseRateDate(input.Date)
+		if err != nil {
+			return 0, err
+		}
+		rate, err := domain.NewExchangeRate(userID, input.FromCurrency, input.ToCurrency, rateDate, input.Rate)
+		if err != nil {
+			return 0, err
+		}
+		rates = append(rates, rate)
+	}
+	return uc.repo.UpsertRates(ctx, dedupeRates(rates))
+}
+
+// ImportRatesCSV loads rows of "date,from,to,rate". A header row is optional
+// and ";" is accepted as a separator, in which case "," may be the decimal mark.
+func (uc *CurrencyUseCase) ImportRatesCSV(ctx context.Context, userID uuid.UUID, data io.Reader) (int, error) {
+	inputs, err := parseRatesCSV(data)
+	if err != nil {
+		return 0, err
+	}
+	return uc.AddRates(ctx, userID, inputs)
+}
+
+func (uc *CurrencyUseCase) GetRates(ctx context.Context, userID uuid.UUID, filter domain.RateFilter) ([]domain.ExchangeRate, error) {
+	if filter.FromCurrency != "" {
+		from, err := domain.NormalizeCurrency(filter.FromCurrency)
+		if err != nil {
+			return nil, err
+		}
+		filter.FromCurrency = from
+	}
+	if filter.ToCurrency != "" {
+		to, err := domain.NormalizeCurrency(filter.ToCurrency)
+		if err != nil {
+			return nil, err
+		}
+		filter.ToCurrency = to
+	}
+	return uc.repo.GetRates(ctx, userID, filter)
+}
+
+func (uc *CurrencyUseCase) DeleteRate(ctx context.Context, userID uuid.UUID, rateID uuid.UUID) error {
+	return uc.repo.DeleteRate(ctx, userID, rateID)
+}
+
+func parseRatesCSV(data io.Reader) ([]RateInput, error) {
+	content, err := io.ReadAll(bufio.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRatesFile, err)
+	}
+	content = bytes.TrimPrefix(content, []byte("\xef\xbb\xbf"))
+
+	reader := csv.NewReader(bytes.NewReader(content))
+	firstLine, _, _ := strings.Cut(string(content), "\n")
+	if strings.Contains(firstLine, ";") {
+		reader.Comma = ';'
+	}
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRatesFile, err)
+	}
+
+	inputs := make([]RateInput, 0, len(records))
+	for i, record := range records {
+		if len(record) == 1 && strings.TrimSpace(record[0]) == "" {
+			continue
+		}
+		if len(record) < 4 {
+			return nil, fmt.Errorf("%w: line %d: expected date, from, to, rate", ErrInvalidRatesFile, i+1)
+		}
+		rawRate := strings.ReplaceAll(strings.TrimSpace(record[3]), " ", "")
+		if reader.Comma == ';' {
+			rawRate = strings.ReplaceAll(rawRate, ",", ".")
+		}
+		rate, parseErr := strconv.ParseFloat(rawRate, 64)
+		if parseErr != nil {
+			if i == 0 {
+				continue
+			}
+			return nil, fmt.Errorf("%w: line %d: invalid rate %q", ErrInvalidRatesFile, i+1, record[3])
+		}
+		inputs = append(inputs, RateInput{
+			Date:         strings.TrimSpace(record[0]),
+			FromCurrency: record[1],
+			ToCurrency:   record[2],
+			Rate:         rate,
+		})
+	}
+	if len(inputs) == 0 {
+		return nil, ErrNoRates
+	}
+	return inputs, nil
+}
+
+func parseRateDate(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, domain.ErrInvalidRateDate
+	}
+	for _, layout := range rateDateLayouts {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			return parsed, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%w: %q", domain.ErrInvalidRateDate, value)
+}
+
+func dedupeRates(rates []*domain.ExchangeRate) []*domain.ExchangeRate {
+	byKey := make(map[string]int, len(rates))
+	result := make([]*domain.ExchangeRate, 0, len(rates))
+	for _, rate := range rates {
+		key := rate.FromCurrency + rate.ToCurrency + rate.RateDate.Format("2006-01-02")
+		if idx, ok := byKey[key]; ok {
+			result[idx] = rate
+			continue
+		}
+		byKey[key] = len(result)
+		result = append(result, rate)
+	}
+	return result
+}
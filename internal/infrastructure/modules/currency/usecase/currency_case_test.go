@@ -0,0 +1,97 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"Finance-Manager-System/internal/infrastructure/modules/currency/domain"
+)
+
+type fakeCurrencyRepo struct {
+	base  map[uuid.UUID]string
+	rates []*domain.ExchangeRate
+}
+
+func (r *fakeCurrencyRepo) GetBaseCurrency(ctx context.Context, userID uuid.UUID) (string, error) {
+	if currency, ok := r.base[userID]; ok {
+		return currency, nil
+	}
+	return domain.DefaultBaseCurrency, nil
+}
+
+func (r *fakeCurrencyRepo) SetBaseCurrency(ctx context.Context, userID uuid.UUID, currency string) error {
+	if r.base == nil {
+		r.base = make(map[uuid.UUID]string)
+	}
+	r.base[userID] = currency
+	return nil
+}
+
+func (r *fakeCurrencyRepo) UpsertRates(ctx context.Context, rates []*domain.ExchangeRate) (int, error) {
+	r.rates = append(r.rates, rates...)
+	return len(rates), nil
+}
+
+func (r *fakeCurrencyRepo) GetRates(ctx context.Context, userID uuid.UUID, filter domain.RateFilter) ([]domain.ExchangeRate, error) {
+	out := make([]domain.ExchangeRate, 0, len(r.rates))
+	for _, rate := range r.rates {
+		out = append(out, *rate)
+	}
+	return out, nil
+}
+
+func (r *fakeCurrencyRepo) DeleteRate(ctx context.Context, userID uuid.UUID, rateID uuid.UUID) error {
+	return domain.ErrRateNotFound
+}
+
+func TestImportRatesCSVSemicolonWithDecimalComma(t *testing.T) {
+	repo := &fakeCurrencyRepo{}
+	uc := NewCurrencyUseCase(repo)
+
+	data := "\ufeffdate;from;to;rate\n14.03.2025;USD;RUB;91,5\n2025-03-15;eur;rub;99,25\n14.03.2025;USD;RUB;92,0\n"
+	saved, err := uc.ImportRatesCSV(context.Background(), uuid.New(), strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if saved != 2 {
+		t.Fatalf("duplicated date/pair must be collapsed, saved %d", saved)
+	}
+	for _, rate := range repo.rates {
+		if rate.FromCurrency == "USD" && rate.Rate != 92 {
+			t.Fatalf("last rate for a date must win, got %v", rate.Rate)
+		}
+		if rate.FromCurrency == "EUR" && rate.Rate != 99.25 {
+			t.Fatalf("unexpected EUR rate: %v", rate.Rate)
+		}
+	}
+}
+
+func TestImportRatesCSVRejectsBrokenFile(t *testing.T) {
+	uc := NewCurrencyUseCase(&fakeCurrencyRepo{})
+
+	_, err := uc.ImportRatesCSV(context.Background(), uuid.New(), strings.NewReader("2025-03-14,USD,RUB\n"))
+	if !errors.Is(err, ErrInvalidRatesFile) {
+		t.Fatalf("expected ErrInvalidRatesFile, got %v", err)
+	}
+}
+
+func TestSetBaseCurrencyNormalizes(t *testing.T) {
+	repo := &fakeCurrencyRepo{}
+	uc := NewCurrencyUseCase(repo)
+	userID := uuid.New()
+
+	currency, err := uc.SetBaseCurrency(context.Background(), userID, " eur ")
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if currency != "EUR" || repo.base[userID] != "EUR" {
+		t.Fatalf("unexpected base currency: %s", currency)
+	}
+	if _, err := uc.SetBaseCurrency(context.Background(), userID, "euro"); !errors.Is(err, domain.ErrInvalidCurrency) {
+		t.Fatalf("expected ErrInvalidCurrency, got %v", err)
+	}
+}
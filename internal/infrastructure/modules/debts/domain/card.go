@@ -0,0 +1,106 @@
+package domain
+
+import (
+	"math"
+	"time"
+)
+
+type CardStatus string
+
+const (
+	CardNoDebt CardStatus = "no_debt"
+	CardDue    CardStatus = "due"
+	// CardMinimumPaid keeps the card in good standing, but the grace period
+	// is kept only if the whole statement balance is paid by the due date.
+	CardMinimumPaid CardStatus = "minimum_paid"
+	CardPaid        CardStatus = "paid"
+	// CardGraceLost means the minimum was paid but not the whole statement
+	// balance, so interest is charged.
+	CardGraceLost CardStatus = "grace_lost"
+	CardOverdue   CardStatus = "overdue"
+)
+
+type CardStatement struct {
+	Debt Debt `json:"debt"`
+	// The statement period is [PeriodStart, PeriodEnd], closing at the end
+	// of PeriodEnd.
+	PeriodStart       time.Time `json:"period_start"`
+	PeriodEnd         time.Time `json:"period_end"`
+	NextStatementDate time.Time `json:"next_statement_date"`
+	// StatementBalance is what was owed when the statement closed.
+	StatementBalance int64 `json:"statement_balance"`
+	MinimumPayment   int64 `json:"minimum_payment"`
+	// DueDate is the end of the grace period: pay the statement balance by
+	// then to avoid interest and at least the minimum to avoid being late.
+	DueDate            time.Time  `json:"due_date"`
+	PaidSinceStatement int64      `json:"paid_since_statement"`
+	MinimumRemaining   int64      `json:"minimum_remaining"`
+	GraceRemaining     int64      `json:"grace_remaining"`
+	CurrentDebt        int64      `json:"current_debt"`
+	AvailableCredit    int64      `json:"available_credit"`
+	Status             CardStatus `json:"status"`
+}
+
+// StatementPeriodAt returns the last statement period closed on or before
+// now's day.
+func (d *Debt) StatementPeriodAt(now time.Time) (time.Time, time.Time) {
+	today := dateOnly(now.UTC())
+	end := onDay(today, 0, d.StatementDay)
+	if end.After(today) {
+		end = onDay(today, -1, d.StatementDay)
+	}
+	start := onDay(end, -1, d.StatementDay).AddDate(0, 0, 1)
+	return start, end
+}
+
+// MinimumPaymentFor is the share of the statement balance due, at least the
+// floor and at most the balance itself.
+func (d *Debt) MinimumPaymentFor(statementBalance int64) int64 {
+	if statementBalance <= 0 {
+		return 0
+	}
+	minimum := int64(math.Ceil(float64(statementBalance) * d.MinPaymentPercent / 100))
+	minimum = max(minimum, d.MinPaymentFloor)
+	return min(minimum, statementBalance)
+}
+
+// NewCardStatement describes the last closed statement. balance is the
+// current account balance, negative while money is owed; sinceClose is how
+// the balance moved after the statement closed and paid is what was paid
+// in that time.
+func NewCardStatement(d Debt, now time.Time, balance int64, sinceClose int64, paid int64) *CardStatement {
+	start, end := d.StatementPeriodAt(now)
+	statement := &CardStatement{
+		Debt:               d,
+		PeriodStart:        start,
+		PeriodEnd:          end,
+		NextStatementDate:  onDay(end, 1, d.StatementDay),
+		StatementBalance:   max(0, -(balance - sinceClose)),
+		DueDate:            end.AddDate(0, 0, d.GracePeriodDays),
+		PaidSinceStatement: paid,
+		CurrentDebt:        max(0, -balance),
+	}
+	statement.MinimumPayment = d.MinimumPaymentFor(statement.StatementBalance)
+	statement.MinimumRemaining = max(0, statement.MinimumPayment-paid)
+	statement.GraceRemaining = max(0, statement.StatementBalance-paid)
+	if d.CreditLimit > 0 {
+		statement.AvailableCredit = max(0, d.CreditLimit-statement.CurrentDebt)
+	}
+
+	late := dateOnly(now.UTC()).After(statement.DueDate)
+	switch {
+	case statement.StatementBalance == 0:
+		statement.Status = CardNoDebt
+	case statement.GraceRemaining == 0:
+		statement.Status = CardPaid
+	case statement.MinimumRemaining > 0 && late:
+		statement.Status = CardOverdue
+	case late:
+		statement.Status = CardGraceLost
+	case statement.MinimumRemaining == 0:
+		statement.Status = CardMinimumPaid
+	default:
+		statement.Status = CardDue
+	}
+	return statement
+}
@@ -0,0 +1,191 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrDebtEmptyUserID       = errors.New("user ID cannot be empty (nil UUID)")
+	ErrDebtInvalidType       = errors.New("debt type must be one of loan, credit_card")
+	ErrDebtInvalidPrincipal  = errors.New("loan principal must be strictly greater than zero")
+	ErrDebtInvalidRate       = errors.New("interest rate must be between 0 and 100 percent")
+	ErrDebtInvalidTerm       = errors.New("loan term must be between 1 and 600 months")
+	ErrDebtInvalidDay        = errors.New("payment and statement day must be between 1 and 31")
+	ErrDebtInvalidGrace      = errors.New("grace period must be between 0 and 120 days")
+	ErrDebtInvalidLimit      = errors.New("credit limit cannot be negative")
+	ErrDebtInvalidMinPayment = errors.New("minimum payment percent must be between 0 and 100 and its floor cannot be negative")
+	ErrDebtNotFound          = errors.New("debt not found")
+	ErrDebtAccountNotFound   = errors.New("account not found")
+	ErrDebtAccountTaken      = errors.New("account already has debt terms")
+	ErrNotLoan               = errors.New("debt is not a loan")
+	ErrNotCreditCard         = errors.New("debt is not a credit card")
+)
+
+type DebtType string
+
+const (
+	DebtLoan       DebtType = "loan"
+	DebtCreditCard DebtType = "credit_card"
+)
+
+const (
+	MaxTermMonths = 600
+	MaxGraceDays  = 120
+)
+
+// Debt holds the terms of a loan or a credit card kept on a liability
+// account. Payments are the income transactions of that account and, when
+// PaymentPattern is set, the expenses of other accounts whose description
+// contains it, such as "Погашение кредита" lines of an imported statement.
+//
+// For a loan GracePeriodDays is how late an instalment may be paid without
+// becoming overdue. For a card it is the number of days after the statement
+// closes on StatementDay until the payment is due.
+type Debt struct {
+	DebtID          uuid.UUID `db:"debt_id" json:"debt_id"`
+	UserID          uuid.UUID `db:"user_id" json:"-"`
+	AccountID       uuid.UUID `db:"account_id" json:"account_id"`
+	DebtType        DebtType  `db:"debt_type" json:"debt_type"`
+	GracePeriodDays int       `db:"grace_period_days" json:"grace_period_days"`
+	PaymentPattern  *string   `db:"payment_pattern" json:"payment_pattern,omitempty"`
+
+	Principal  int64     `db:"principal" json:"principal,omitempty"`
+	AnnualRate float64   `db:"annual_rate" json:"annual_rate"`
+	TermMonths int       `db:"term_months" json:"term_months,omitempty"`
+	StartDate  time.Time `db:"start_date" json:"start_date"`
+	PaymentDay int       `db:"payment_day" json:"payment_day,omitempty"`
+	// TrackFrom is when the loan started to be tracked here. Instalments due
+	// before it are taken as paid outside the system.
+	TrackFrom time.Time `db:"track_from" json:"track_from"`
+
+	CreditLimit       int64   `db:"credit_limit" json:"credit_limit,omitempty"`
+	StatementDay      int     `db:"statement_day" json:"statement_day,omitempty"`
+	MinPaymentPercent float64 `db:"min_payment_percent" json:"min_payment_percent,omitempty"`
+	MinPaymentFloor   int64   `db:"min_payment_floor" json:"min_payment_floor,omitempty"`
+
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
+
+type DebtParams struct {
+	AccountID       uuid.UUID
+	DebtType        string
+	GracePeriodDays int
+	PaymentPattern  *string
+
+	Principal  int64
+	AnnualRate float64
+	TermMonths int
+	StartDate  *time.Time
+	PaymentDay int
+	TrackFrom  *time.Time
+
+	CreditLimit       int64
+	StatementDay      int
+	MinPaymentPercent float64
+	MinPaymentFloor   int64
+}
+
+// NewDebt validates the terms of the given type and drops the fields of the
+// other one. A loan starts today and pays on its start day unless told
+// otherwise.
+func NewDebt(userID uuid.UUID, params DebtParams) (*Debt, error) {
+	if userID == uuid.Nil {
+		return nil, ErrDebtEmptyUserID
+	}
+	if params.GracePeriodDays < 0 || params.GracePeriodDays > MaxGraceDays {
+		return nil, ErrDebtInvalidGrace
+	}
+	if params.AnnualRate < 0 || params.AnnualRate > 100 {
+		return nil, ErrDebtInvalidRate
+	}
+	if params.PaymentPattern != nil {
+		pattern := strings.TrimSpace(*params.PaymentPattern)
+		params.PaymentPattern = &pattern
+		if pattern == "" {
+			params.PaymentPattern = nil
+		}
+	}
+
+	now := time.Now().UTC()
+	debt := &Debt{
+		UserID:          userID,
+		AccountID:       params.AccountID,
+		DebtType:        DebtType(params.DebtType),
+		GracePeriodDays: params.GracePeriodDays,
+		PaymentPattern:  params.PaymentPattern,
+		AnnualRate:      params.AnnualRate,
+		StartDate:       dateOnly(now),
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	if params.StartDate != nil {
+		debt.StartDate = dateOnly(params.StartDate.UTC())
+	}
+	debt.TrackFrom = debt.StartDate
+
+	switch debt.DebtType {
+	case DebtLoan:
+		if params.Principal <= 0 {
+			return nil, ErrDebtInvalidPrincipal
+		}
+		if params.TermMonths < 1 || params.TermMonths > MaxTermMonths {
+			return nil, ErrDebtInvalidTerm
+		}
+		if params.PaymentDay == 0 {
+			params.PaymentDay = debt.StartDate.Day()
+		}
+		if params.PaymentDay < 1 || params.PaymentDay > 31 {
+			return nil, ErrDebtInvalidDay
+		}
+		debt.Principal = params.Principal
+		debt.TermMonths = params.TermMonths
+		debt.PaymentDay = params.PaymentDay
+		if params.TrackFrom != nil && params.TrackFrom.After(debt.StartDate) {
+			debt.TrackFrom = dateOnly(params.TrackFrom.UTC())
+		}
+	case DebtCreditCard:
+		if params.CreditLimit < 0 {
+			return nil, ErrDebtInvalidLimit
+		}
+		if params.StatementDay < 1 || params.StatementDay > 31 {
+			return nil, ErrDebtInvalidDay
+		}
+		if params.MinPaymentPercent < 0 || params.MinPaymentPercent > 100 || params.MinPaymentFloor < 0 {
+			return nil, ErrDebtInvalidMinPayment
+		}
+		debt.CreditLimit = params.CreditLimit
+		debt.StatementDay = params.StatementDay
+		debt.MinPaymentPercent = params.MinPaymentPercent
+		debt.MinPaymentFloor = params.MinPaymentFloor
+	default:
+		return nil, ErrDebtInvalidType
+	}
+	return debt, nil
+}
+
+// Payment is a transaction that pays the debt off.
+type Payment struct {
+	TransactionID uuid.UUID `db:"transaction_id" json:"transaction_id"`
+	CompletedAt   time.Time `db:"completed_at" json:"completed_at"`
+	Amount        int64     `db:"amount" json:"amount"`
+	Description   string    `db:"name_transaction" json:"description"`
+}
+
+// onDay returns the given day of the month that is months after t's month,
+// moved to the last day of shorter months.
+func onDay(t time.Time, months int, day int) time.Time {
+	first := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, months, 0)
+	if last := first.AddDate(0, 1, -1).Day(); day > last {
+		day = last
+	}
+	return first.AddDate(0, 0, day-1)
+}
+
+func dateOnly(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,137 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func day(year int, month time.Month, d int) time.Time {
+	return time.Date(year, month, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestLoanSchedule(t *testing.T) {
+	start := day(2026, 1, 15)
+	loan, err := NewDebt(uuid.New(), DebtParams{AccountID: uuid.New(), DebtType: "loan", Principal: 120000, AnnualRate: 12, TermMonths: 12, StartDate: &start})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if loan.PaymentDay != 15 || !loan.TrackFrom.Equal(start) {
+		t.Fatalf("payment day and tracking should default to the start date, got %+v", loan)
+	}
+	if loan.MonthlyPayment() != 10662 {
+		t.Fatalf("expected annuity payment 10662, got %d", loan.MonthlyPayment())
+	}
+
+	schedule := loan.Schedule()
+	if len(schedule) != 12 || schedule[0].Interest != 1200 || !schedule[0].DueDate.Equal(day(2026, 2, 15)) {
+		t.Fatalf("unexpected first instalment: %+v", schedule[0])
+	}
+	var principal int64
+	for _, inst := range schedule {
+		principal += inst.Principal
+	}
+	if principal != 120000 || schedule[11].RemainingPrincipal != 0 {
+		t.Fatalf("schedule must repay the whole principal, got %d", principal)
+	}
+
+	report := NewLoanReport(*loan, []Payment{{TransactionID: uuid.New(), CompletedAt: day(2026, 2, 14), Amount: 10662}}, day(2026, 3, 1))
+	if report.InterestPaid != 1200 || report.PrincipalPaid != 9462 || report.RemainingPrincipal != 110538 {
+		t.Fatalf("payment should cover interest first, got %+v", report)
+	}
+	if report.IsPaidOff || !report.PayoffDate.Equal(day(2027, 1, 15)) {
+		t.Fatalf("unexpected payoff: %v %v", report.IsPaidOff, report.PayoffDate)
+	}
+
+	if _, err := NewDebt(uuid.New(), DebtParams{DebtType: "loan", Principal: 1000, TermMonths: 0}); err != ErrDebtInvalidTerm {
+		t.Fatalf("expected ErrDebtInvalidTerm, got %v", err)
+	}
+	if _, err := NewDebt(uuid.New(), DebtParams{DebtType: "mortgage"}); err != ErrDebtInvalidType {
+		t.Fatalf("expected ErrDebtInvalidType, got %v", err)
+	}
+}
+
+func TestLoanReportMatchesPayments(t *testing.T) {
+	start := day(2026, 1, 31)
+	loan, err := NewDebt(uuid.New(), DebtParams{DebtType: "loan", Principal: 120000, TermMonths: 12, StartDate: &start, GracePeriodDays: 5})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	payments := []Payment{
+		{TransactionID: uuid.New(), CompletedAt: day(2026, 4, 10), Amount: 15000},
+		{TransactionID: uuid.New(), CompletedAt: day(2026, 2, 27), Amount: 10000},
+	}
+	report := NewLoanReport(*loan, payments, day(2026, 5, 10))
+
+	if !report.Schedule[0].DueDate.Equal(day(2026, 2, 28)) {
+		t.Fatalf("due date should move to the end of short months, got %v", report.Schedule[0].DueDate)
+	}
+	want := []InstalmentStatus{InstalmentPaid, InstalmentPaidLate, InstalmentOverdue, InstalmentUpcoming}
+	for i, status := range want {
+		if report.Schedule[i].Status != status {
+			t.Fatalf("instalment %d: expected %s, got %s", i+1, status, report.Schedule[i].Status)
+		}
+	}
+	if report.Schedule[2].Paid != 5000 || report.OverdueAmount != 5000 {
+		t.Fatalf("expected 5000 overdue on the third instalment, got %+v", report.Schedule[2])
+	}
+	if report.NextDueDate == nil || !report.NextDueDate.Equal(day(2026, 4, 30)) || report.NextPayment != 5000 {
+		t.Fatalf("unexpected next payment: %v %d", report.NextDueDate, report.NextPayment)
+	}
+	if report.RemainingPrincipal != 95000 {
+		t.Fatalf("expected 95000 remaining, got %d", report.RemainingPrincipal)
+	}
+
+	trackFrom := day(2026, 6, 1)
+	loan.TrackFrom = trackFrom
+	report = NewLoanReport(*loan, nil, day(2026, 6, 10))
+	for i := 0; i < 4; i++ {
+		if report.Schedule[i].Status != InstalmentPaidBefore {
+			t.Fatalf("instalment %d due before tracking should count as paid, got %s", i+1, report.Schedule[i].Status)
+		}
+	}
+	if report.OverdueAmount != 0 || report.RemainingPrincipal != 80000 {
+		t.Fatalf("unexpected report for a loan tracked mid-term: %+v", report)
+	}
+}
+
+func TestCardStatement(t *testing.T) {
+	card, err := NewDebt(uuid.New(), DebtParams{DebtType: "credit_card", CreditLimit: 100000, StatementDay: 20, GracePeriodDays: 25, MinPaymentPercent: 5, MinPaymentFloor: 3000})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+
+	start, end := card.StatementPeriodAt(day(2026, 5, 10))
+	if !start.Equal(day(2026, 3, 21)) || !end.Equal(day(2026, 4, 20)) {
+		t.Fatalf("unexpected statement period: %v - %v", start, end)
+	}
+
+	// 12000 spent and 2000 paid since the statement closed.
+	statement := NewCardStatement(*card, day(2026, 5, 10), -50000, -10000, 2000)
+	if statement.StatementBalance != 40000 || statement.MinimumPayment != 3000 || !statement.DueDate.Equal(day(2026, 5, 15)) {
+		t.Fatalf("unexpected statement: %+v", statement)
+	}
+	if statement.MinimumRemaining != 1000 || statement.GraceRemaining != 38000 || statement.AvailableCredit != 50000 || statement.Status != CardDue {
+		t.Fatalf("unexpected statement: %+v", statement)
+	}
+
+	cases := []struct {
+		now  time.Time
+		paid int64
+		want CardStatus
+	}{
+		{day(2026, 5, 12), 3000, CardMinimumPaid},
+		{day(2026, 5, 16), 2000, CardOverdue},
+		{day(2026, 5, 16), 3000, CardGraceLost},
+		{day(2026, 5, 16), 40000, CardPaid},
+	}
+	for _, tc := range cases {
+		if got := NewCardStatement(*card, tc.now, -50000, -10000, tc.paid).Status; got != tc.want {
+			t.Fatalf("paid %d by %v: expected %s, got %s", tc.paid, tc.now, tc.want, got)
+		}
+	}
+	if got := NewCardStatement(*card, day(2026, 5, 10), -5000, -5000, 0).Status; got != CardNoDebt {
+		t.Fatalf("expected no debt for spending after the statement, got %s", got)
+	}
+}
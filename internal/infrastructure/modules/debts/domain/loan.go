@@ -0,0 +1,171 @@
+package domain
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type InstalmentStatus string
+
+const (
+	InstalmentUpcoming InstalmentStatus = "upcoming"
+	InstalmentPartial  InstalmentStatus = "partial"
+	InstalmentOverdue  InstalmentStatus = "overdue"
+	InstalmentPaid     InstalmentStatus = "paid"
+	InstalmentPaidLate InstalmentStatus = "paid_late"
+	// InstalmentPaidBefore was due before the loan was tracked here.
+	InstalmentPaidBefore InstalmentStatus = "paid_before_tracking"
+)
+
+type Instalment struct {
+	Number             int              `json:"number"`
+	DueDate            time.Time        `json:"due_date"`
+	Payment            int64            `json:"payment"`
+	Interest           int64            `json:"interest"`
+	Principal          int64            `json:"principal"`
+	RemainingPrincipal int64            `json:"remaining_principal"`
+	Paid               int64            `json:"paid"`
+	PaidAt             *time.Time       `json:"paid_at,omitempty"`
+	TransactionIDs     []uuid.UUID      `json:"transaction_ids,omitempty"`
+	Status             InstalmentStatus `json:"status"`
+}
+
+type LoanReport struct {
+	Debt           Debt         `json:"debt"`
+	MonthlyPayment int64        `json:"monthly_payment"`
+	TotalInterest  int64        `json:"total_interest"`
+	Schedule       []Instalment `json:"schedule"`
+	// PrincipalPaid and InterestPaid split the payments made so far, each
+	// instalment paying its interest first.
+	PrincipalPaid      int64 `json:"principal_paid"`
+	InterestPaid       int64 `json:"interest_paid"`
+	RemainingPrincipal int64 `json:"remaining_principal"`
+	OverdueAmount      int64 `json:"overdue_amount"`
+	// Overpaid is paid beyond the whole schedule.
+	Overpaid    int64      `json:"overpaid"`
+	NextDueDate *time.Time `json:"next_due_date,omitempty"`
+	NextPayment int64      `json:"next_payment"`
+	IsPaidOff   bool       `json:"is_paid_off"`
+	PayoffDate  time.Time  `json:"payoff_date"`
+	Payments    []Payment  `json:"payments"`
+}
+
+// MonthlyPayment is the annuity payment of the loan. Interest accrues monthly
+// at a twelfth of the annual rate.
+func (d *Debt) MonthlyPayment() int64 {
+	rate := d.AnnualRate / 100 / 12
+	if rate == 0 {
+		return int64(math.Ceil(float64(d.Principal) / float64(d.TermMonths)))
+	}
+	return int64(math.Round(float64(d.Principal) * rate / (1 - math.Pow(1+rate, -float64(d.TermMonths)))))
+}
+
+// Schedule builds the annuity schedule. The first instalment is due in the
+// month after the start date, on the payment day, and the last one clears
+// whatever principal rounding left over.
+func (d *Debt) Schedule() []Instalment {
+	rate := d.AnnualRate / 100 / 12
+	payment := d.MonthlyPayment()
+	remaining := d.Principal
+	schedule := make([]Instalment, 0, d.TermMonths)
+	for i := 1; i <= d.TermMonths && remaining > 0; i++ {
+		interest := int64(math.Round(float64(remaining) * rate))
+		principal := payment - interest
+		if i == d.TermMonths || principal > remaining {
+			principal = remaining
+		}
+		remaining -= principal
+		schedule = append(schedule, Instalment{
+			Number:             i,
+			DueDate:            onDay(d.StartDate, i, d.PaymentDay),
+			Payment:            principal + interest,
+			Interest:           interest,
+			Principal:          principal,
+			RemainingPrincipal: remaining,
+		})
+	}
+	return schedule
+}
+
+// NewLoanReport matches payments to the schedule oldest first: every payment
+// fills the earliest instalment that is not fully paid yet and the rest moves
+// on to the next one.
+func NewLoanReport(d Debt, payments []Payment, now time.Time) *LoanReport {
+	report := &LoanReport{
+		Debt:           d,
+		MonthlyPayment: d.MonthlyPayment(),
+		Schedule:       d.Schedule(),
+		Payments:       payments,
+	}
+	if report.Payments == nil {
+		report.Payments = make([]Payment, 0)
+	}
+	sort.SliceStable(payments, func(i, j int) bool { return payments[i].CompletedAt.Before(payments[j].CompletedAt) })
+
+	next := 0
+	for next < len(report.Schedule) && report.Schedule[next].DueDate.Before(d.TrackFrom) {
+		inst := &report.Schedule[next]
+		inst.Paid = inst.Payment
+		inst.Status = InstalmentPaidBefore
+		next++
+	}
+	for _, payment := range payments {
+		left := payment.Amount
+		for left > 0 && next < len(report.Schedule) {
+			inst := &report.Schedule[next]
+			take := min(left, inst.Payment-inst.Paid)
+			inst.Paid += take
+			inst.TransactionIDs = append(inst.TransactionIDs, payment.TransactionID)
+			left -= take
+			if inst.Paid == inst.Payment {
+				paidAt := payment.CompletedAt
+				inst.PaidAt = &paidAt
+				next++
+			}
+		}
+		report.Overpaid += left
+	}
+
+	today := dateOnly(now.UTC())
+	for i := range report.Schedule {
+		inst := &report.Schedule[i]
+		report.TotalInterest += inst.Interest
+		interestPaid := min(inst.Paid, inst.Interest)
+		report.InterestPaid += interestPaid
+		report.PrincipalPaid += inst.Paid - interestPaid
+
+		deadline := inst.DueDate.AddDate(0, 0, d.GracePeriodDays)
+		switch {
+		case inst.Status == InstalmentPaidBefore:
+		case inst.Paid == inst.Payment:
+			inst.Status = InstalmentPaid
+			if dateOnly(*inst.PaidAt).After(deadline) {
+				inst.Status = InstalmentPaidLate
+			}
+		case today.After(deadline):
+			inst.Status = InstalmentOverdue
+			report.OverdueAmount += inst.Payment - inst.Paid
+		case inst.Paid > 0:
+			inst.Status = InstalmentPartial
+		default:
+			inst.Status = InstalmentUpcoming
+		}
+		if report.NextDueDate == nil && inst.Paid < inst.Payment {
+			due := inst.DueDate
+			report.NextDueDate = &due
+			report.NextPayment = inst.Payment - inst.Paid
+		}
+	}
+	report.RemainingPrincipal = d.Principal - report.PrincipalPaid
+
+	last := report.Schedule[len(report.Schedule)-1]
+	report.IsPaidOff = last.Paid == last.Payment
+	report.PayoffDate = last.DueDate
+	if last.PaidAt != nil {
+		report.PayoffDate = *last.PaidAt
+	}
+	return report
+}
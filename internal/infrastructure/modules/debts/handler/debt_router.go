@@ -0,0 +1,313 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"Finance-Manager-System/internal/infrastructure/middleware"
+	"Finance-Manager-System/internal/infrastructure/modules/debts/domain"
+	"Finance-Manager-System/internal/infrastructure/modules/debts/usecase"
+)
+
+type DebtRouter struct {
+	debtUC *usecase.DebtUseCase
+}
+
+func NewDebtRouter(debtUC *usecase.DebtUseCase) *DebtRouter {
+	return &DebtRouter{debtUC: debtUC}
+}
+
+func (h *DebtRouter) Route() chi.Router {
+	r := chi.NewRouter()
+	r.Post("/", h.CreateDebt)
+	r.Get("/", h.GetDebts)
+	r.Get("/{id}", h.GetDebt)
+	r.Put("/{id}", h.UpdateDebt)
+	r.Delete("/{id}", h.DeleteDebt)
+	r.Get("/{id}/schedule", h.GetLoanSchedule)
+	r.Get("/{id}/statement", h.GetCardStatement)
+	return r
+}
+
+type DebtReq struct {
+	AccountID       uuid.UUID `json:"account_id"`
+	DebtType        string    `json:"debt_type" example:"loan"`
+	GracePeriodDays int       `json:"grace_period_days" example:"0"`
+	PaymentPattern  *string   `json:"payment_pattern" example:"Погашение кредита"`
+
+	Principal  int64      `json:"principal" example:"50000000"`
+	AnnualRate float64    `json:"annual_rate" example:"12.5"`
+	TermMonths int        `json:"term_months" example:"36"`
+	StartDate  *time.Time `json:"start_date"`
+	PaymentDay int        `json:"payment_day" example:"15"`
+	TrackFrom  *time.Time `json:"track_from"`
+
+	CreditLimit       int64   `json:"credit_limit" example:"15000000"`
+	StatementDay      int     `json:"statement_day" example:"20"`
+	MinPaymentPercent float64 `json:"min_payment_percent" example:"5"`
+	MinPaymentFloor   int64   `json:"min_payment_floor" example:"30000"`
+}
+
+func (req DebtReq) params() domain.DebtParams {
+	return domain.DebtParams{
+		AccountID:         req.AccountID,
+		DebtType:          req.DebtType,
+		GracePeriodDays:   req.GracePeriodDays,
+		PaymentPattern:    req.PaymentPattern,
+		Principal:         req.Principal,
+		AnnualRate:        req.AnnualRate,
+		TermMonths:        req.TermMonths,
+		StartDate:         req.StartDate,
+		PaymentDay:        req.PaymentDay,
+		TrackFrom:         req.TrackFrom,
+		CreditLimit:       req.CreditLimit,
+		StatementDay:      req.StatementDay,
+		MinPaymentPercent: req.MinPaymentPercent,
+		MinPaymentFloor:   req.MinPaymentFloor,
+	}
+}
+
+// @Summary Добавить условия кредита или кредитной карты
+// @Description Условия привязываются к счёту, который становится пассивом. Платежами считаются поступления на этот счёт и расходы с других счетов, описание которых содержит payment_pattern
+// @Tags debts
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param request body DebtReq true "Тип (loan или credit_card) и условия долга"
+// @Success 202 {object} map[string]interface{}
+// @Router /api/v1/debts [post]
+func (h *DebtRouter) CreateDebt(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req DebtReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid json", http.StatusBadRequest)
+		return
+	}
+
+	debtID, err := h.debtUC.CreateDebt(r.Context(), userID, req.params())
+	if err != nil {
+		h.mapError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]interface{}{"status": "success", "debt_id": debtID})
+}
+
+// @Summary Получить список долгов
+// @Tags debts
+// @Security ApiKeyAuth
+// @Produce json
+// @Success 200 {array} domain.Debt
+// @Router /api/v1/debts [get]
+func (h *DebtRouter) GetDebts(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	debts, err := h.debtUC.GetDebts(r.Context(), userID)
+	if err != nil {
+		h.mapError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(debts)
+}
+
+// @Summary Получить долг
+// @Tags debts
+// @Security ApiKeyAuth
+// @Produce json
+// @Param id path string true "ID долга"
+// @Success 200 {object} domain.Debt
+// @Router /api/v1/debts/{id} [get]
+func (h *DebtRouter) GetDebt(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	debtID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid debt ID", http.StatusBadRequest)
+		return
+	}
+
+	debt, err := h.debtUC.GetDebt(r.Context(), userID, debtID)
+	if err != nil {
+		h.mapError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(debt)
+}
+
+// @Summary Обновить условия долга
+// @Tags debts
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "ID долга"
+// @Param request body DebtReq true "Новые условия долга"
+// @Success 202 {object} map[string]interface{}
+// @Router /api/v1/debts/{id} [put]
+func (h *DebtRouter) UpdateDebt(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	debtID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid debt ID", http.StatusBadRequest)
+		return
+	}
+
+	var req DebtReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid json", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.debtUC.UpdateDebt(r.Context(), userID, debtID, req.params()); err != nil {
+		h.mapError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]interface{}{"status": "success"})
+}
+
+// @Summary Удалить условия долга
+// @Description Счёт и его транзакции остаются
+// @Tags debts
+// @Security ApiKeyAuth
+// @Produce json
+// @Param id path string true "ID долга"
+// @Success 202 {object} map[string]interface{}
+// @Router /api/v1/debts/{id} [delete]
+func (h *DebtRouter) DeleteDebt(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	debtID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid debt ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.debtUC.DeleteDebt(r.Context(), userID, debtID); err != nil {
+		h.mapError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]interface{}{"status": "success"})
+}
+
+// @Summary График платежей по кредиту
+// @Description Аннуитетный график с разнесёнными по взносам платежами, остатком основного долга, выплаченными процентами и датой погашения
+// @Tags debts
+// @Security ApiKeyAuth
+// @Produce json
+// @Param id path string true "ID долга"
+// @Success 200 {object} domain.LoanReport
+// @Router /api/v1/debts/{id}/schedule [get]
+func (h *DebtRouter) GetLoanSchedule(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	debtID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid debt ID", http.StatusBadRequest)
+		return
+	}
+
+	report, err := h.debtUC.GetLoanSchedule(r.Context(), userID, debtID, time.Now().UTC())
+	if err != nil {
+		h.mapError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// @Summary Выписка по кредитной карте
+// @Description Последний закрытый расчётный период, минимальный платёж и окончание льготного периода
+// @Tags debts
+// @Security ApiKeyAuth
+// @Produce json
+// @Param id path string true "ID долга"
+// @Success 200 {object} domain.CardStatement
+// @Router /api/v1/debts/{id}/statement [get]
+func (h *DebtRouter) GetCardStatement(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	debtID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid debt ID", http.StatusBadRequest)
+		return
+	}
+
+	statement, err := h.debtUC.GetCardStatement(r.Context(), userID, debtID, time.Now().UTC())
+	if err != nil {
+		h.mapError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(statement)
+}
+
+func (h *DebtRouter) mapError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, domain.ErrDebtNotFound), errors.Is(err, domain.ErrDebtAccountNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, domain.ErrDebtAccountTaken):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, domain.ErrDebtEmptyUserID),
+		errors.Is(err, domain.ErrDebtInvalidType),
+		errors.Is(err, domain.ErrDebtInvalidPrincipal),
+		errors.Is(err, domain.ErrDebtInvalidRate),
+		errors.Is(err, domain.ErrDebtInvalidTerm),
+		errors.Is(err, domain.ErrDebtInvalidDay),
+		errors.Is(err, domain.ErrDebtInvalidGrace),
+		errors.Is(err, domain.ErrDebtInvalidLimit),
+		errors.Is(err, domain.ErrDebtInvalidMinPayment),
+		errors.Is(err, domain.ErrNotLoan),
+		errors.Is(err, domain.ErrNotCreditCard):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,179 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"Finance-Manager-System/internal/infrastructure/database"
+	"Finance-Manager-System/internal/infrastructure/modules/debts/domain"
+)
+
+type DebtRepo struct {
+	db *sqlx.DB
+}
+
+func NewDebtRepo(db *sqlx.DB) *DebtRepo {
+	return &DebtRepo{db: db}
+}
+
+func (r *DebtRepo) AddDebt(ctx context.Context, debt *domain.Debt) (uuid.UUID, error) {
+	q := database.GetQueryer(ctx, r.db)
+	query := `
+		INSERT INTO Debts (
+			user_id, account_id, debt_type, grace_period_days, payment_pattern,
+			principal, annual_rate, term_months, start_date, payment_day, track_from,
+			credit_limit, statement_day, min_payment_percent, min_payment_floor,
+			created_at, updated_at
+		)
+		VALUES (
+			:user_id, :account_id, :debt_type, :grace_period_days, :payment_pattern,
+			:principal, :annual_rate, :term_months, :start_date, :payment_day, :track_from,
+			:credit_limit, :statement_day, :min_payment_percent, :min_payment_floor,
+			:created_at, :updated_at
+		)
+		RETURNING debt_id
+	`
+	queryStr, args, err := sqlx.Named(query, debt)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to process named query: %w", err)
+	}
+	queryStr = q.Rebind(queryStr)
+
+	var debtID uuid.UUID
+	if err := q.QueryRowContext(ctx, queryStr, args...).Scan(&debtID); err != nil {
+		return uuid.Nil, fmt.Errorf("failed to add debt: %w", err)
+	}
+	return debtID, nil
+}
+
+func (r *DebtRepo) GetDebts(ctx context.Context, userID uuid.UUID) ([]domain.Debt, error) {
+	q := database.GetQueryer(ctx, r.db)
+	debts := make([]domain.Debt, 0)
+	query := `SELECT * FROM Debts WHERE user_id = $1 ORDER BY created_at ASC`
+	if err := q.SelectContext(ctx, &debts, query, userID); err != nil {
+		return nil, fmt.Errorf("failed to get debts: %w", err)
+	}
+	return debts, nil
+}
+
+func (r *DebtRepo) GetDebt(ctx context.Context, userID uuid.UUID, debtID uuid.UUID) (*domain.Debt, error) {
+	q := database.GetQueryer(ctx, r.db)
+	var debt domain.Debt
+	query := `SELECT * FROM Debts WHERE user_id = $1 AND debt_id = $2`
+	if err := q.GetContext(ctx, &debt, query, userID, debtID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrDebtNotFound
+		}
+		return nil, fmt.Errorf("failed to get debt: %w", err)
+	}
+	return &debt, nil
+}
+
+func (r *DebtRepo) GetDebtByAccount(ctx context.Context, userID uuid.UUID, accountID uuid.UUID) (*domain.Debt, error) {
+	q := database.GetQueryer(ctx, r.db)
+	var debt domain.Debt
+	query := `SELECT * FROM Debts WHERE user_id = $1 AND account_id = $2`
+	if err := q.GetContext(ctx, &debt, query, userID, accountID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrDebtNotFound
+		}
+		return nil, fmt.Errorf("failed to get debt: %w", err)
+	}
+	return &debt, nil
+}
+
+func (r *DebtRepo) UpdateDebt(ctx context.Context, debt *domain.Debt) error {
+	q := database.GetQueryer(ctx, r.db)
+	query := `
+		UPDATE Debts
+		SET account_id = :account_id, debt_type = :debt_type, grace_period_days = :grace_period_days,
+			payment_pattern = :payment_pattern, principal = :principal, annual_rate = :annual_rate,
+			term_months = :term_months, start_date = :start_date, payment_day = :payment_day,
+			track_from = :track_from, credit_limit = :credit_limit, statement_day = :statement_day,
+			min_payment_percent = :min_payment_percent, min_payment_floor = :min_payment_floor,
+			updated_at = :updated_at
+		WHERE debt_id = :debt_id AND user_id = :user_id
+	`
+	res, err := q.NamedExecContext(ctx, query, debt)
+	if err != nil {
+		return fmt.Errorf("failed to update debt: %w", err)
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check affected rows: %w", err)
+	}
+	if rowsAffected == 0 {
+		return domain.ErrDebtNotFound
+	}
+	return nil
+}
+
+func (r *DebtRepo) DeleteDebt(ctx context.Context, userID uuid.UUID, debtID uuid.UUID) error {
+	q := database.GetQueryer(ctx, r.db)
+	res, err := q.ExecContext(ctx, `DELETE FROM Debts WHERE user_id = $1 AND debt_id = $2`, userID, debtID)
+	if err != nil {
+		return fmt.Errorf("failed to delete debt: %w", err)
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check affected rows: %w", err)
+	}
+	if rowsAffected == 0 {
+		return domain.ErrDebtNotFound
+	}
+	return nil
+}
+
+// GetDebtPayments returns the visible payments completed at or after from,
+// oldest first: income of the debt account and, with a pattern, expenses of
+// other accounts whose description contains it. Transfers into the debt
+// account already show up as its income, so their outgoing legs are not
+// matched by the pattern. Balance adjustments are not payments.
+func (r *DebtRepo) GetDebtPayments(ctx context.Context, userID uuid.UUID, accountID uuid.UUID, pattern *string, from time.Time) ([]domain.Payment, error) {
+	q := database.GetQueryer(ctx, r.db)
+	payments := make([]domain.Payment, 0)
+	query := `
+		SELECT transaction_id, completed_at, amount, name_transaction
+		FROM Transactions
+		WHERE user_id = $1 AND is_hidden = false AND is_adjustment = false AND completed_at >= $4
+		  AND (
+			(account_id = $2 AND is_income = true)
+			OR ($3::text IS NOT NULL AND account_id <> $2 AND is_income = false
+				AND transfer_id IS NULL
+				AND name_transaction ILIKE '%' || $3::text || '%' ESCAPE '\')
+		  )
+		ORDER BY completed_at ASC
+	`
+	var escaped *string
+	if pattern != nil {
+		value := database.EscapeLike(*pattern)
+		escaped = &value
+	}
+	if err := q.SelectContext(ctx, &payments, query, userID, accountID, escaped, from); err != nil {
+		return nil, fmt.Errorf("failed to get debt payments: %w", err)
+	}
+	return payments, nil
+}
+
+// GetAccountFlowSince sums how the visible transactions completed at or after
+// from moved the account balance. Balance adjustments never moved it.
+func (r *DebtRepo) GetAccountFlowSince(ctx context.Context, userID uuid.UUID, accountID uuid.UUID, from time.Time) (int64, error) {
+	q := database.GetQueryer(ctx, r.db)
+	query := `
+		SELECT COALESCE(SUM(CASE WHEN is_income THEN amount ELSE -amount END), 0)
+		FROM Transactions
+		WHERE user_id = $1 AND account_id = $2 AND is_hidden = false AND is_adjustment = false
+		  AND completed_at >= $3
+	`
+	var sum int64
+	if err := q.GetContext(ctx, &sum, query, userID, accountID, from); err != nil {
+		return 0, fmt.Errorf("failed to sum account transactions: %w", err)
+	}
+	return sum, nil
+}
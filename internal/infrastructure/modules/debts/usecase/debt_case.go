@@ -0,0 +1,179 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"Finance-Manager-System/internal/infrastructure/database"
+	accountDomain "Finance-Manager-System/internal/infrastructure/modules/account/domain"
+	"Finance-Manager-System/internal/infrastructure/modules/debts/domain"
+)
+
+type DebtRepository interface {
+	AddDebt(ctx context.Context, debt *domain.Debt) (uuid.UUID, error)
+	GetDebts(ctx context.Context, userID uuid.UUID) ([]domain.Debt, error)
+	GetDebt(ctx context.Context, userID uuid.UUID, debtID uuid.UUID) (*domain.Debt, error)
+	GetDebtByAccount(ctx context.Context, userID uuid.UUID, accountID uuid.UUID) (*domain.Debt, error)
+	UpdateDebt(ctx context.Context, debt *domain.Debt) error
+	DeleteDebt(ctx context.Context, userID uuid.UUID, debtID uuid.UUID) error
+	GetDebtPayments(ctx context.Context, userID uuid.UUID, accountID uuid.UUID, pattern *string, from time.Time) ([]domain.Payment, error)
+	GetAccountFlowSince(ctx context.Context, userID uuid.UUID, accountID uuid.UUID, from time.Time) (int64, error)
+}
+
+type DebtAccountRepository interface {
+	GetAccountByID(ctx context.Context, userID uuid.UUID, accountID uuid.UUID) (*accountDomain.Account, error)
+	SetAccountKind(ctx context.Context, userID uuid.UUID, accountID uuid.UUID, kind accountDomain.AccountKind) error
+}
+
+type DebtUseCase struct {
+	repo        DebtRepository
+	accountRepo DebtAccountRepository
+	txManager   database.TxManager
+}
+
+func NewDebtUseCase(repo DebtRepository, accountRepo DebtAccountRepository, txManager database.TxManager) *DebtUseCase {
+	return &DebtUseCase{repo: repo, accountRepo: accountRepo, txManager: txManager}
+}
+
+// CreateDebt attaches loan or card terms to an account and makes the account
+// a liability.
+func (uc *DebtUseCase) CreateDebt(ctx context.Context, userID uuid.UUID, params domain.DebtParams) (uuid.UUID, error) {
+	debt, err := domain.NewDebt(userID, params)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	var debtID uuid.UUID
+	err = uc.txManager.RunInTransaction(ctx, func(ctx context.Context) error {
+		if err := uc.prepareAccount(ctx, userID, debt.AccountID, uuid.Nil); err != nil {
+			return err
+		}
+		debtID, err = uc.repo.AddDebt(ctx, debt)
+		return err
+	})
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return debtID, nil
+}
+
+func (uc *DebtUseCase) GetDebts(ctx context.Context, userID uuid.UUID) ([]domain.Debt, error) {
+	return uc.repo.GetDebts(ctx, userID)
+}
+
+func (uc *DebtUseCase) GetDebt(ctx context.Context, userID uuid.UUID, debtID uuid.UUID) (*domain.Debt, error) {
+	return uc.repo.GetDebt(ctx, userID, debtID)
+}
+
+func (uc *DebtUseCase) UpdateDebt(ctx context.Context, userID uuid.UUID, debtID uuid.UUID, params domain.DebtParams) error {
+	current, err := uc.repo.GetDebt(ctx, userID, debtID)
+	if err != nil {
+		return err
+	}
+	if params.StartDate == nil {
+		params.StartDate = &current.StartDate
+	}
+	if params.TrackFrom == nil {
+		params.TrackFrom = &current.TrackFrom
+	}
+	updated, err := domain.NewDebt(userID, params)
+	if err != nil {
+		return err
+	}
+	updated.DebtID = current.DebtID
+	updated.CreatedAt = current.CreatedAt
+
+	return uc.txManager.RunInTransaction(ctx, func(ctx context.Context) error {
+		if err := uc.prepareAccount(ctx, userID, updated.AccountID, debtID); err != nil {
+			return err
+		}
+		return uc.repo.UpdateDebt(ctx, updated)
+	})
+}
+
+func (uc *DebtUseCase) DeleteDebt(ctx context.Context, userID uuid.UUID, debtID uuid.UUID) error {
+	return uc.repo.DeleteDebt(ctx, userID, debtID)
+}
+
+// GetLoanSchedule returns the amortisation schedule of a loan with the
+// payments made so far matched to its instalments.
+func (uc *DebtUseCase) GetLoanSchedule(ctx context.Context, userID uuid.UUID, debtID uuid.UUID, now time.Time) (*domain.LoanReport, error) {
+	debt, err := uc.repo.GetDebt(ctx, userID, debtID)
+	if err != nil {
+		return nil, err
+	}
+	if debt.DebtType != domain.DebtLoan {
+		return nil, domain.ErrNotLoan
+	}
+	payments, err := uc.repo.GetDebtPayments(ctx, userID, debt.AccountID, debt.PaymentPattern, debt.TrackFrom)
+	if err != nil {
+		return nil, err
+	}
+	return domain.NewLoanReport(*debt, payments, now), nil
+}
+
+// GetCardStatement returns the last closed statement of a credit card with
+// its minimum payment, due date and what is left to pay.
+func (uc *DebtUseCase) GetCardStatement(ctx context.Context, userID uuid.UUID, debtID uuid.UUID, now time.Time) (*domain.CardStatement, error) {
+	debt, err := uc.repo.GetDebt(ctx, userID, debtID)
+	if err != nil {
+		return nil, err
+	}
+	if debt.DebtType != domain.DebtCreditCard {
+		return nil, domain.ErrNotCreditCard
+	}
+	acc, err := uc.accountRepo.GetAccountByID(ctx, userID, debt.AccountID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %w", domain.ErrDebtAccountNotFound, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	_, closedOn := debt.StatementPeriodAt(now)
+	closedAt := closedOn.AddDate(0, 0, 1)
+	sinceClose, err := uc.repo.GetAccountFlowSince(ctx, userID, debt.AccountID, closedAt)
+	if err != nil {
+		return nil, err
+	}
+	payments, err := uc.repo.GetDebtPayments(ctx, userID, debt.AccountID, debt.PaymentPattern, closedAt)
+	if err != nil {
+		return nil, err
+	}
+	var paid int64
+	for _, payment := range payments {
+		paid += payment.Amount
+	}
+	return domain.NewCardStatement(*debt, now, acc.Balance, sinceClose, paid), nil
+}
+
+// prepareAccount checks that the account exists and carries no other debt,
+// and marks it as a liability.
+func (uc *DebtUseCase) prepareAccount(ctx context.Context, userID uuid.UUID, accountID uuid.UUID, debtID uuid.UUID) error {
+	acc, err := uc.accountRepo.GetAccountByID(ctx, userID, accountID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w: %w", domain.ErrDebtAccountNotFound, err)
+	}
+	if err != nil {
+		return err
+	}
+	existing, err := uc.repo.GetDebtByAccount(ctx, userID, accountID)
+	switch {
+	case err == nil && existing.DebtID != debtID:
+		return domain.ErrDebtAccountTaken
+	case err != nil && !errors.Is(err, domain.ErrDebtNotFound):
+		return err
+	}
+	if acc.Kind == accountDomain.KindLiability {
+		return nil
+	}
+	if err := uc.accountRepo.SetAccountKind(ctx, userID, accountID, accountDomain.KindLiability); err != nil {
+		return fmt.Errorf("failed to mark account as liability: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,179 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	accountDomain "Finance-Manager-System/internal/infrastructure/modules/account/domain"
+	"Finance-Manager-System/internal/infrastructure/modules/debts/domain"
+)
+
+type fakeDebtRepo struct {
+	debts    map[uuid.UUID]*domain.Debt
+	payments []domain.Payment
+	flow     int64
+}
+
+func newFakeDebtRepo() *fakeDebtRepo {
+	return &fakeDebtRepo{debts: make(map[uuid.UUID]*domain.Debt)}
+}
+
+func (r *fakeDebtRepo) AddDebt(ctx context.Context, debt *domain.Debt) (uuid.UUID, error) {
+	debt.DebtID = uuid.New()
+	stored := *debt
+	r.debts[debt.DebtID] = &stored
+	return debt.DebtID, nil
+}
+func (r *fakeDebtRepo) GetDebts(ctx context.Context, userID uuid.UUID) ([]domain.Debt, error) {
+	out := make([]domain.Debt, 0)
+	for _, d := range r.debts {
+		if d.UserID == userID {
+			out = append(out, *d)
+		}
+	}
+	return out, nil
+}
+func (r *fakeDebtRepo) GetDebt(ctx context.Context, userID uuid.UUID, debtID uuid.UUID) (*domain.Debt, error) {
+	d, ok := r.debts[debtID]
+	if !ok || d.UserID != userID {
+		return nil, domain.ErrDebtNotFound
+	}
+	stored := *d
+	return &stored, nil
+}
+func (r *fakeDebtRepo) GetDebtByAccount(ctx context.Context, userID uuid.UUID, accountID uuid.UUID) (*domain.Debt, error) {
+	for _, d := range r.debts {
+		if d.UserID == userID && d.AccountID == accountID {
+			return d, nil
+		}
+	}
+	return nil, domain.ErrDebtNotFound
+}
+func (r *fakeDebtRepo) UpdateDebt(ctx context.Context, debt *domain.Debt) error {
+	if _, ok := r.debts[debt.DebtID]; !ok {
+		return domain.ErrDebtNotFound
+	}
+	stored := *debt
+	r.debts[debt.DebtID] = &stored
+	return nil
+}
+func (r *fakeDebtRepo) DeleteDebt(ctx context.Context, userID uuid.UUID, debtID uuid.UUID) error {
+	delete(r.debts, debtID)
+	return nil
+}
+func (r *fakeDebtRepo) GetDebtPayments(ctx context.Context, userID uuid.UUID, accountID uuid.UUID, pattern *string, from time.Time) ([]domain.Payment, error) {
+	out := make([]domain.Payment, 0)
+	for _, p := range r.payments {
+		if !p.CompletedAt.Before(from) {
+			out = append(out, p)
+		}
+	}
+	return out, nil
+}
+func (r *fakeDebtRepo) GetAccountFlowSince(ctx context.Context, userID uuid.UUID, accountID uuid.UUID, from time.Time) (int64, error) {
+	return r.flow, nil
+}
+
+type fakeDebtAccountRepo struct {
+	accounts map[uuid.UUID]*accountDomain.Account
+}
+
+func (r *fakeDebtAccountRepo) GetAccountByID(ctx context.Context, userID uuid.UUID, accountID uuid.UUID) (*accountDomain.Account, error) {
+	acc, ok := r.accounts[accountID]
+	if !ok || acc.UserID != userID {
+		return nil, fmt.Errorf("account not found: %w", sql.ErrNoRows)
+	}
+	return acc, nil
+}
+func (r *fakeDebtAccountRepo) SetAccountKind(ctx context.Context, userID uuid.UUID, accountID uuid.UUID, kind accountDomain.AccountKind) error {
+	r.accounts[accountID].Kind = kind
+	return nil
+}
+
+type fakeDebtTxManager struct{}
+
+func (m *fakeDebtTxManager) RunInTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func TestCreateDebtAndReports(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	loanAccount := &accountDomain.Account{AccountID: uuid.New(), UserID: userID, AccountType: "CUSTOM", Kind: accountDomain.KindAsset}
+	cardAccount := &accountDomain.Account{AccountID: uuid.New(), UserID: userID, AccountType: "CREDIT_CARD", Kind: accountDomain.KindLiability, Balance: -50000}
+	accounts := &fakeDebtAccountRepo{accounts: map[uuid.UUID]*accountDomain.Account{
+		loanAccount.AccountID: loanAccount,
+		cardAccount.AccountID: cardAccount,
+	}}
+	repo := newFakeDebtRepo()
+	uc := NewDebtUseCase(repo, accounts, &fakeDebtTxManager{})
+
+	start := time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC)
+	trackFrom := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+	loanParams := domain.DebtParams{AccountID: loanAccount.AccountID, DebtType: "loan", Principal: 120000, TermMonths: 12, StartDate: &start, TrackFrom: &trackFrom}
+	loanID, err := uc.CreateDebt(ctx, userID, loanParams)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if loanAccount.Kind != accountDomain.KindLiability {
+		t.Fatalf("debt account should become a liability, got %s", loanAccount.Kind)
+	}
+	if _, err := uc.CreateDebt(ctx, userID, loanParams); !errors.Is(err, domain.ErrDebtAccountTaken) {
+		t.Fatalf("expected ErrDebtAccountTaken, got %v", err)
+	}
+	loanParams.AccountID = uuid.New()
+	if _, err := uc.CreateDebt(ctx, userID, loanParams); !errors.Is(err, domain.ErrDebtAccountNotFound) {
+		t.Fatalf("expected ErrDebtAccountNotFound, got %v", err)
+	}
+
+	// Updating keeps the start and tracking dates and may stay on the same
+	// account.
+	loanParams.AccountID = loanAccount.AccountID
+	loanParams.StartDate = nil
+	loanParams.TrackFrom = nil
+	loanParams.GracePeriodDays = 3
+	if err := uc.UpdateDebt(ctx, userID, loanID, loanParams); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if loan := repo.debts[loanID]; !loan.StartDate.Equal(start) || !loan.TrackFrom.Equal(trackFrom) || loan.GracePeriodDays != 3 {
+		t.Fatalf("unexpected updated loan: %+v", loan)
+	}
+
+	repo.payments = []domain.Payment{{TransactionID: uuid.New(), CompletedAt: time.Date(2026, 2, 14, 9, 0, 0, 0, time.UTC), Amount: 10000}}
+	report, err := uc.GetLoanSchedule(ctx, userID, loanID, time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if report.RemainingPrincipal != 110000 || report.Schedule[0].Status != domain.InstalmentPaid {
+		t.Fatalf("unexpected loan report: %+v", report)
+	}
+	if _, err := uc.GetCardStatement(ctx, userID, loanID, time.Now()); !errors.Is(err, domain.ErrNotCreditCard) {
+		t.Fatalf("expected ErrNotCreditCard, got %v", err)
+	}
+
+	cardID, err := uc.CreateDebt(ctx, userID, domain.DebtParams{AccountID: cardAccount.AccountID, DebtType: "credit_card", StatementDay: 20, GracePeriodDays: 25, MinPaymentPercent: 5})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	repo.flow = -10000
+	repo.payments = []domain.Payment{
+		{TransactionID: uuid.New(), CompletedAt: time.Date(2026, 4, 18, 0, 0, 0, 0, time.UTC), Amount: 7000},
+		{TransactionID: uuid.New(), CompletedAt: time.Date(2026, 4, 25, 0, 0, 0, 0, time.UTC), Amount: 2000},
+	}
+	statement, err := uc.GetCardStatement(ctx, userID, cardID, time.Date(2026, 5, 10, 12, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if statement.StatementBalance != 40000 || statement.PaidSinceStatement != 2000 || statement.MinimumRemaining != 0 || statement.Status != domain.CardMinimumPaid {
+		t.Fatalf("unexpected card statement: %+v", statement)
+	}
+	if _, err := uc.GetLoanSchedule(ctx, userID, cardID, time.Now()); !errors.Is(err, domain.ErrNotLoan) {
+		t.Fatalf("expected ErrNotLoan, got %v", err)
+	}
+}
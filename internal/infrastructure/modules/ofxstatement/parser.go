@@ -0,0 +1,274 @@
+package ofxstatement
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"Finance-Manager-System/internal/infrastructure/modules/statement"
+)
+
+const FormatName = "ofx"
+
+var ErrInvalidOFX = errors.New("invalid ofx statement")
+
+var (
+	reTag    = regexp.MustCompile(`<(/?)([A-Za-z0-9.]+)>([^<]*)`)
+	reOFXTZ  = regexp.MustCompile(`\[([+\-]?\d+(?:\.\d+)?)(?::[^\]]*)?\]$`)
+	reMarker = regexp.MustCompile(`(?i)OFXHEADER|<OFX>`)
+)
+
+// Parser reads OFX 1.x (SGML) and 2.x (XML) bank and credit card statements.
+// QFX files are OFX with extra Intuit tags and are handled the same way.
+type Parser struct{}
+
+func NewParser() *Parser {
+	return &Parser{}
+}
+
+func (p *Parser) Name() string {
+	return FormatName
+}
+
+func (p *Parser) Detect(src statement.Source) bool {
+	head := src.Data[:min(len(src.Data), 4096)]
+	return reMarker.Match(head)
+}
+
+func (p *Parser) Parse(src statement.Source) (*statement.Statement, error) {
+	return ParseStatement(src.Data)
+}
+
+func ParseStatement(data []byte) (*statement.Statement, error) {
+	start := bytes.Index(bytes.ToUpper(data), []byte("<OFX>"))
+	if start < 0 {
+		return nil, ErrInvalidOFX
+	}
+
+	stmt := &statement.Statement{
+		Format:      FormatName,
+		AccountType: "imported_ofx",
+	}
+
+	var (
+		current       *statement.TransactionEntry
+		trnType       string
+		name, memo    string
+		path          []string
+		statements    int
+		balanceAsOf   time.Time
+		balanceParsed bool
+	)
+
+	for _, m := range reTag.FindAllSubmatch(data[start:], -1) {
+		el := struct {
+			closing bool
+			name    string
+			value   string
+		}{
+			closing: len(m[1]) > 0,
+			name:    strings.ToUpper(string(m[2])),
+			value:   strings.TrimSpace(unescape(string(m[3]))),
+		}
+
+		if el.closing {
+			// SGML leaf elements are usually left unclosed, so unwind to the
+			// matching aggregate instead of popping a single level.
+			for i := len(path) - 1; i >= 0; i-- {
+				if path[i] == el.name {
+					path = path[:i]
+					break
+				}
+			}
+			if el.name == "STMTTRN" && current != nil {
+				if err := finishEntry(current, trnType, name, memo); err != nil {
+					stmt.Warnings = append(stmt.Warnings, err.Error())
+				} else {
+					stmt.Transactions = append(stmt.Transactions, *current)
+				}
+				current = nil
+			}
+			continue
+		}
+
+		if el.value == "" {
+			path = append(path, el.name)
+			switch el.name {
+			case "STMTTRN":
+				if statements <= 1 {
+					current = &statement.TransactionEntry{}
+					trnType, name, memo = "", "", ""
+				}
+			case "STMTRS", "CCSTMTRS":
+				statements++
+			}
+			continue
+		}
+		if statements > 1 {
+			continue
+		}
+
+		parent := ""
+		if len(path) > 0 {
+			parent = path[len(path)-1]
+		}
+
+		if current != nil {
+			switch el.name {
+			case "TRNTYPE":
+				trnType = strings.ToUpper(el.value)
+			case "DTPOSTED":
+				if ts, err := parseOFXTime(el.value); err == nil {
+					current.CompletedAt = ts
+				}
+			case "TRNAMT":
+				amount, err := parseOFXAmount(el.value)
+				if err != nil {
+					stmt.Warnings = append(stmt.Warnings, fmt.Sprintf("invalid amount %q", el.value))
+					continue
+				}
+				current.IsIncome = amount > 0
+				if amount < 0 {
+					amount = -amount
+				}
+				current.Amount = amount
+			case "FITID":
+				fitID := el.value
+				current.ExternalID = &fitID
+			case "NAME", "PAYEE":
+				name = el.value
+			case "MEMO":
+				memo = el.value
+			case "SIC":
+				if len(el.value) == 4 {
+					mcc := el.value
+					current.MCCCode = &mcc
+				}
+			}
+			continue
+		}
+
+		switch {
+		case el.name == "CURDEF":
+			stmt.Currency = strings.ToUpper(el.value)
+		case el.name == "ACCTID" && (parent == "BANKACCTFROM" || parent == "CCACCTFROM"):
+			stmt.AccountNumber = el.value
+		case el.name == "ORG" && parent == "FI":
+			stmt.BankName = el.value
+		case el.name == "BALAMT" && parent == "LEDGERBAL":
+			balance, err := parseOFXAmount(el.value)
+			if err != nil {
+				return nil, fmt.Errorf("%w: invalid ledger balance", ErrInvalidOFX)
+			}
+			stmt.Balance = balance
+			stmt.HasBalance = true
+			balanceParsed = true
+		case el.name == "DTASOF" && parent == "LEDGERBAL":
+			if ts, err := parseOFXTime(el.value); err == nil {
+				balanceAsOf = ts
+			}
+		}
+	}
+
+	if statements > 1 {
+		stmt.Warnings = append(stmt.Warnings, fmt.Sprintf("file contains %d statements, only the first one was imported", statements))
+	}
+	if balanceParsed && !balanceAsOf.IsZero() {
+		for _, tx := range stmt.Transactions {
+			if tx.CompletedAt.After(balanceAsOf) {
+				stmt.Warnings = append(stmt.Warnings, "ledger balance is older than the latest transaction")
+				break
+			}
+		}
+	}
+	if len(stmt.Transactions) == 0 {
+		return nil, fmt.Errorf("%w: no transactions found", ErrInvalidOFX)
+	}
+	return stmt, nil
+}
+
+func finishEntry(entry *statement.TransactionEntry, trnType, name, memo string) error {
+	if entry.CompletedAt.IsZero() {
+		return errors.New("transaction without DTPOSTED skipped")
+	}
+	if entry.Amount == 0 {
+		return errors.New("transaction with zero amount skipped")
+	}
+	if entry.ExternalID == nil {
+		return errors.New("transaction without FITID skipped")
+	}
+	switch {
+	case name != "" && memo != "" && !strings.Contains(name, memo):
+		entry.Description = name + " " + memo
+	case name != "":
+		entry.Description = name
+	case memo != "":
+		entry.Description = memo
+	default:
+		entry.Description = trnType
+	}
+	if entry.MCCCode == nil {
+		if mcc := extractMCC(entry.Description); mcc != "" {
+			entry.MCCCode = &mcc
+		}
+	}
+	return nil
+}
+
+// parseOFXTime parses YYYYMMDD[HHMMSS[.XXX]][[offset:TZ]]. Without an offset
+// the time is taken as UTC, as the OFX spec requires.
+func parseOFXTime(value string) (time.Time, error) {
+	loc := time.UTC
+	if m := reOFXTZ.FindStringSubmatch(value); len(m) > 1 {
+		hours, err := strconv.ParseFloat(m[1], 64)
+		if err != nil {
+			return time.Time{}, err
+		}
+		loc = time.FixedZone("", int(hours*3600))
+		value = strings.TrimSpace(value[:len(value)-len(m[0])])
+	}
+	if i := strings.IndexByte(value, '.'); i >= 0 {
+		value = value[:i]
+	}
+
+	layouts := map[int]string{8: "20060102", 12: "200601021504", 14: "20060102150405"}
+	layout, ok := layouts[len(value)]
+	if !ok {
+		return time.Time{}, fmt.Errorf("invalid OFX date %q", value)
+	}
+	ts, err := time.ParseInLocation(layout, value, loc)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return ts.UTC(), nil
+}
+
+func parseOFXAmount(value string) (int64, error) {
+	value = strings.ReplaceAll(strings.TrimSpace(value), " ", "")
+	if strings.Contains(value, ",") && !strings.Contains(value, ".") {
+		value = strings.ReplaceAll(value, ",", ".")
+	}
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, err
+	}
+	return int64(math.Round(parsed * 100)), nil
+}
+
+var reMCC = regexp.MustCompile(`(?i)\bmcc[:\s]*([0-9]{4})\b`)
+
+func extractMCC(description string) string {
+	if m := reMCC.FindStringSubmatch(description); len(m) > 1 {
+		return m[1]
+	}
+	return ""
+}
+
+func unescape(s string) string {
+	return strings.NewReplacer("&lt;", "<", "&gt;", ">", "&quot;", `"`, "&apos;", "'", "&nbsp;", " ", "&amp;", "&").Replace(s)
+}
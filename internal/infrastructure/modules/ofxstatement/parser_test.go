@@ -0,0 +1,115 @@
+package ofxstatement
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"Finance-Manager-System/internal/infrastructure/modules/statement"
+)
+
+const sgmlStatement = `OFXHEADER:100
+DATA:OFXSGML
+VERSION:102
+
+<OFX>
+<SIGNONMSGSRSV1><SONRS><FI><ORG>Demo Bank<FID>1</FI></SONRS></SIGNONMSGSRSV1>
+<BANKMSGSRSV1><STMTTRNRS><STMTRS>
+<CURDEF>USD
+<BANKACCTFROM><BANKID>0001<ACCTID>123456789<ACCTTYPE>CHECKING</BANKACCTFROM>
+<BANKTRANLIST>
+<DTSTART>20260501<DTEND>20260531
+<STMTTRN><TRNTYPE>DEBIT<DTPOSTED>20260510120000.000[-5:EST]<TRNAMT>-12.34<FITID>T1<NAME>COFFEE SHOP<MEMO>MCC 5814</STMTTRN>
+<STMTTRN><TRNTYPE>CREDIT<DTPOSTED>20260515<TRNAMT>1500.00<FITID>T2<NAME>PAYROLL</STMTTRN>
+<STMTTRN><TRNTYPE>DEBIT<DTPOSTED>20260516<TRNAMT>-1.00<NAME>NO FITID</STMTTRN>
+</BANKTRANLIST>
+<LEDGERBAL><BALAMT>2487.66<DTASOF>20260531</LEDGERBAL>
+</STMTRS></STMTTRNRS></BANKMSGSRSV1>
+</OFX>
+`
+
+const xmlStatement = `<?xml version="1.0" encoding="UTF-8"?>
+<?OFX OFXHEADER="200" VERSION="220"?>
+<OFX>
+  <CREDITCARDMSGSRSV1><CCSTMTTRNRS><CCSTMTRS>
+    <CURDEF>EUR</CURDEF>
+    <CCACCTFROM><ACCTID>4111</ACCTID></CCACCTFROM>
+    <BANKTRANLIST>
+      <STMTTRN>
+        <TRNTYPE>DEBIT</TRNTYPE>
+        <DTPOSTED>20260502083000</DTPOSTED>
+        <TRNAMT>-20.00</TRNAMT>
+        <FITID>X1</FITID>
+        <NAME>Books &amp; More</NAME>
+        <SIC>5942</SIC>
+      </STMTTRN>
+    </BANKTRANLIST>
+    <LEDGERBAL><BALAMT>-20.00</BALAMT><DTASOF>20260503</DTASOF></LEDGERBAL>
+  </CCSTMTRS></CCSTMTTRNRS></CREDITCARDMSGSRSV1>
+</OFX>
+`
+
+func TestParseStatementSGML(t *testing.T) {
+	stmt, err := ParseStatement([]byte(sgmlStatement))
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if stmt.Currency != "USD" || stmt.AccountNumber != "123456789" || stmt.BankName != "Demo Bank" {
+		t.Fatalf("unexpected statement header: %#v", stmt)
+	}
+	if !stmt.HasBalance || stmt.Balance != 248766 {
+		t.Fatalf("unexpected balance: %d", stmt.Balance)
+	}
+	if len(stmt.Transactions) != 2 || len(stmt.Warnings) != 1 {
+		t.Fatalf("expected 2 transactions and 1 warning, got %d, %v", len(stmt.Transactions), stmt.Warnings)
+	}
+
+	coffee := stmt.Transactions[0]
+	if coffee.IsIncome || coffee.Amount != 1234 || *coffee.ExternalID != "T1" {
+		t.Fatalf("unexpected debit: %#v", coffee)
+	}
+	if coffee.MCCCode == nil || *coffee.MCCCode != "5814" || coffee.Description != "COFFEE SHOP MCC 5814" {
+		t.Fatalf("unexpected description or mcc: %q", coffee.Description)
+	}
+	if !coffee.CompletedAt.Equal(time.Date(2026, 5, 10, 17, 0, 0, 0, time.UTC)) {
+		t.Fatalf("timezone offset must be applied: %v", coffee.CompletedAt)
+	}
+	if !stmt.Transactions[1].IsIncome || stmt.Transactions[1].Amount != 150000 {
+		t.Fatalf("unexpected credit: %#v", stmt.Transactions[1])
+	}
+}
+
+func TestParseStatementWithoutFIKeepsBankNameEmpty(t *testing.T) {
+	// BANKID is a routing number, not a name.
+	data := strings.Replace(sgmlStatement, "<SIGNONMSGSRSV1><SONRS><FI><ORG>Demo Bank<FID>1</FI></SONRS></SIGNONMSGSRSV1>\n", "", 1)
+	stmt, err := ParseStatement([]byte(data))
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if stmt.BankName != "" {
+		t.Fatalf("expected empty bank name, got %q", stmt.BankName)
+	}
+}
+
+func TestParseStatementXML(t *testing.T) {
+	stmt, err := ParseStatement([]byte(xmlStatement))
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if stmt.Currency != "EUR" || stmt.AccountNumber != "4111" || stmt.Balance != -2000 {
+		t.Fatalf("unexpected statement header: %#v", stmt)
+	}
+	if len(stmt.Transactions) != 1 || stmt.Transactions[0].Description != "Books & More" || *stmt.Transactions[0].MCCCode != "5942" {
+		t.Fatalf("unexpected transactions: %#v", stmt.Transactions)
+	}
+}
+
+func TestParserDetect(t *testing.T) {
+	p := NewParser()
+	if !p.Detect(statement.Source{Data: []byte(sgmlStatement)}) || !p.Detect(statement.Source{Data: []byte(xmlStatement)}) {
+		t.Fatalf("ofx must be detected")
+	}
+	if p.Detect(statement.Source{Data: []byte("date,amount\n")}) {
+		t.Fatalf("csv must not be detected as ofx")
+	}
+}
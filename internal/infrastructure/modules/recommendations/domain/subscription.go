@@ -0,0 +1,54 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type SubscriptionCadence string
+
+const (
+	CadenceWeekly    SubscriptionCadence = "weekly"
+	CadenceBiweekly  SubscriptionCadence = "biweekly"
+	CadenceMonthly   SubscriptionCadence = "monthly"
+	CadenceQuarterly SubscriptionCadence = "quarterly"
+	CadenceYearly    SubscriptionCadence = "yearly"
+)
+
+// ExpenseCharge is a single expense used for subscription detection.
+type ExpenseCharge struct {
+	TransactionID   uuid.UUID  `db:"transaction_id"`
+	NameTransaction string     `db:"name_transaction"`
+	Amount          int64      `db:"amount"`
+	Currency        string     `db:"currency"`
+	CompletedAt     time.Time  `db:"completed_at"`
+	CategoryID      *uuid.UUID `db:"category_id"`
+}
+
+type PriceChange struct {
+	ChangedAt      time.Time `json:"changed_at"`
+	PreviousAmount int64     `json:"previous_amount"`
+	NewAmount      int64     `json:"new_amount"`
+	ChangePercent  float64   `json:"change_percent"`
+}
+
+type Subscription struct {
+	MerchantKey    string              `json:"merchant_key"`
+	Name           string              `json:"name"`
+	CategoryID     *uuid.UUID          `json:"category_id,omitempty"`
+	Currency       string              `json:"currency"`
+	Cadence        SubscriptionCadence `json:"cadence"`
+	IntervalDays   float64             `json:"interval_days"`
+	ChargesCount   int                 `json:"charges_count"`
+	AverageAmount  int64               `json:"average_amount"`
+	LastAmount     int64               `json:"last_amount"`
+	FirstChargedAt time.Time           `json:"first_charged_at"`
+	LastChargedAt  time.Time           `json:"last_charged_at"`
+	NextExpectedAt time.Time           `json:"next_expected_at"`
+	AnnualCost     int64               `json:"annual_cost"`
+	IsActive       bool                `json:"is_active"`
+	PriceChanges   []PriceChange       `json:"price_changes,omitempty"`
+	// PriceAlert is set when the latest charge differs from the previous one.
+	PriceAlert *PriceChange `json:"price_alert,omitempty"`
+}
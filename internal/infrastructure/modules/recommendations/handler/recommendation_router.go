@@ -11,6 +11,7 @@ import (
 	"github.com/google/uuid"
 
 	"Finance-Manager-System/internal/infrastructure/middleware"
+	categoryDomain "Finance-Manager-System/internal/infrastructure/modules/category/domain"
 	"Finance-Manager-System/internal/infrastructure/modules/recommendations/usecase"
 )
 
@@ -25,6 +26,7 @@ func NewRecommendationRouter(uc *usecase.RecommendationUseCase) *RecommendationR
 func (h *RecommendationRouter) Route() chi.Router {
 	r := chi.NewRouter()
 	r.Get("/budget", h.GetBudgetRecommendations)
+	r.Get("/subscriptions", h.GetSubscriptions)
 	return r
 }
 
@@ -34,6 +36,7 @@ func (h *RecommendationRouter) Route() chi.Router {
 // @Produce json
 // @Param planned_total query integer true "Планируемый общий бюджет расходов (в копейках)"
 // @Param months query integer false "Сколько последних месяцев анализировать (1..12, по умолчанию 3)"
+// @Param depth query integer false "Уровень свёртки категорий: 0 — листовые категории, 1 — верхний уровень и т.д."
 // @Param include_hidden query boolean false "Учитывать скрытые транзакции"
 // @Param account_ids query string false "CSV список account_id для фильтра"
 // @Success 200 {object} map[string]interface{}
@@ -66,6 +69,16 @@ func (h *RecommendationRouter) GetBudgetRecommendations(w http.ResponseWriter, r
 		months = parsedMonths
 	}
 
+	depth := 0
+	if depthStr := r.URL.Query().Get("depth"); depthStr != "" {
+		parsedDepth, parseErr := strconv.Atoi(depthStr)
+		if parseErr != nil {
+			http.Error(w, "depth must be an integer", http.StatusBadRequest)
+			return
+		}
+		depth = parsedDepth
+	}
+
 	includeHidden := false
 	if includeHiddenStr := r.URL.Query().Get("include_hidden"); includeHiddenStr != "" {
 		includeHidden = includeHiddenStr == "true"
@@ -77,10 +90,10 @@ func (h *RecommendationRouter) GetBudgetRecommendations(w http.ResponseWriter, r
 		return
 	}
 
-	result, err := h.uc.GetBudgetRecommendations(r.Context(), userID, plannedTotal, months, includeHidden, accountIDs)
+	result, err := h.uc.GetBudgetRecommendations(r.Context(), userID, plannedTotal, months, depth, includeHidden, accountIDs)
 	if err != nil {
 		switch {
-		case errors.Is(err, usecase.ErrInvalidPlannedTotal), errors.Is(err, usecase.ErrInvalidMonths):
+		case errors.Is(err, usecase.ErrInvalidPlannedTotal), errors.Is(err, usecase.ErrInvalidMonths), errors.Is(err, categoryDomain.ErrInvalidDepth):
 			http.Error(w, err.Error(), http.StatusBadRequest)
 		default:
 			http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -101,6 +114,7 @@ func (h *RecommendationRouter) GetBudgetRecommendations(w http.ResponseWriter, r
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"planned_total":            plannedTotal,
 		"months":                   months,
+		"depth":                    depth,
 		"include_hidden":           includeHidden,
 		"account_ids":              accountIDs,
 		"last_month_total_expense": lastMonthTotalExpense,
@@ -109,6 +123,73 @@ func (h *RecommendationRouter) GetBudgetRecommendations(w http.ResponseWriter, r
 	})
 }
 
+// @Summary Найти регулярные подписки по истории транзакций
+// @Tags recommendations
+// @Security ApiKeyAuth
+// @Produce json
+// @Param months query integer false "Сколько последних месяцев анализировать (1..24, по умолчанию 12)"
+// @Param include_hidden query boolean false "Учитывать скрытые транзакции"
+// @Param account_ids query string false "CSV список account_id для фильтра"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/recommendations/subscriptions [get]
+func (h *RecommendationRouter) GetSubscriptions(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	months := 12
+	if monthsStr := r.URL.Query().Get("months"); monthsStr != "" {
+		parsedMonths, parseErr := strconv.Atoi(monthsStr)
+		if parseErr != nil {
+			http.Error(w, "months must be an integer", http.StatusBadRequest)
+			return
+		}
+		months = parsedMonths
+	}
+
+	includeHidden := r.URL.Query().Get("include_hidden") == "true"
+
+	accountIDs, err := parseAccountIDs(r.URL.Query().Get("account_ids"))
+	if err != nil {
+		http.Error(w, "account_ids must contain valid UUIDs", http.StatusBadRequest)
+		return
+	}
+
+	subscriptions, err := h.uc.GetSubscriptions(r.Context(), userID, months, includeHidden, accountIDs)
+	if err != nil {
+		switch {
+		case errors.Is(err, usecase.ErrInvalidLookbackMonths):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	priceAlerts := 0
+	annualCost := make(map[string]int64)
+	for _, sub := range subscriptions {
+		if sub.PriceAlert != nil {
+			priceAlerts++
+		}
+		if sub.IsActive {
+			annualCost[sub.Currency] += sub.AnnualCost
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"months":         months,
+		"include_hidden": includeHidden,
+		"account_ids":    accountIDs,
+		"price_alerts":   priceAlerts,
+		"annual_cost":    annualCost,
+		"subscriptions":  subscriptions,
+	})
+}
+
 func parseAccountIDs(raw string) ([]uuid.UUID, error) {
 	if strings.TrimSpace(raw) == "" {
 		return nil, nil
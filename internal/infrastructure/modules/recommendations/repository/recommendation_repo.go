@@ -9,6 +9,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
 
+	"Finance-Manager-System/internal/infrastructure/database"
 	"Finance-Manager-System/internal/infrastructure/modules/recommendations/domain"
 )
 
@@ -25,22 +26,33 @@ func (r *RecommendationRepository) GetMonthlyCategoryExpenses(
 	userID uuid.UUID,
 	start time.Time,
 	end time.Time,
+	depth int,
 	includeHidden bool,
 	accountIDs []uuid.UUID,
 ) ([]domain.MonthlyCategoryExpense, error) {
-	query := `
+	// Split transactions are counted by their parts.
+	category := database.SplitCategory("t")
+	cte, joins := "", database.BaseCurrencyJoin("t")+database.SplitJoin("t")
+	if depth > 0 {
+		cte = database.CategoryPathsCTE("$1")
+		joins += database.CategoryPathJoin(category)
+		category = database.CategoryAtDepth(category, depth)
+	}
+	query := cte + `
 		SELECT
 			date_trunc('month', t.completed_at)::date AS month,
-			t.category_id,
+			` + category + ` AS category_id,
 			COALESCE(c.name_category, 'Без категории') AS category_name,
 			c.icon_url,
-			COALESCE(SUM(t.amount), 0) AS amount
-		FROM Transactions t
-		LEFT JOIN Category c ON c.category_id = t.category_id
+			COALESCE(SUM(` + database.BaseAmount(database.SplitAmount("t")) + `), 0) AS amount
+		FROM Transactions t` + joins + `
+		LEFT JOIN Category c ON c.category_id = ` + category + `
 		WHERE t.user_id = $1
 		  AND t.is_income = false
 		  AND t.completed_at >= $2
 		  AND t.completed_at < $3
+		  AND t.transfer_id IS NULL
+		  AND t.is_adjustment = false
 		  AND ($4 OR t.is_hidden = false)
 	`
 
@@ -58,7 +70,7 @@ func (r *RecommendationRepository) GetMonthlyCategoryExpenses(
 	}
 
 	query += `
-		GROUP BY date_trunc('month', t.completed_at)::date, t.category_id, c.name_category, c.icon_url
+		GROUP BY date_trunc('month', t.completed_at)::date, ` + category + `, c.name_category, c.icon_url
 		ORDER BY month ASC, amount DESC
 	`
 
@@ -69,3 +81,44 @@ func (r *RecommendationRepository) GetMonthlyCategoryExpenses(
 
 	return rows, nil
 }
+
+func (r *RecommendationRepository) GetExpenseCharges(
+	ctx context.Context,
+	userID uuid.UUID,
+	start time.Time,
+	includeHidden bool,
+	accountIDs []uuid.UUID,
+) ([]domain.ExpenseCharge, error) {
+	query := `
+		SELECT t.transaction_id, t.name_transaction, t.amount, t.currency, t.completed_at, t.category_id
+		FROM Transactions t
+		WHERE t.user_id = $1
+		  AND t.is_income = false
+		  AND t.completed_at >= $2
+		  AND t.transfer_id IS NULL
+		  AND t.is_adjustment = false
+		  AND ($3 OR t.is_hidden = false)
+	`
+
+	args := []interface{}{userID, start, includeHidden}
+	nextArg := 4
+
+	if len(accountIDs) > 0 {
+		placeholders := make([]string, len(accountIDs))
+		for i, id := range accountIDs {
+			placeholders[i] = fmt.Sprintf("$%d", nextArg)
+			args = append(args, id)
+			nextArg++
+		}
+		query += " AND t.account_id IN (" + strings.Join(placeholders, ", ") + ")"
+	}
+
+	query += " ORDER BY t.completed_at ASC"
+
+	rows := make([]domain.ExpenseCharge, 0)
+	if err := r.db.SelectContext(ctx, &rows, query, args...); err != nil {
+		return nil, err
+	}
+
+	return rows, nil
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/google/uuid"
 
+	categoryDomain "Finance-Manager-System/internal/infrastructure/modules/category/domain"
 	"Finance-Manager-System/internal/infrastructure/modules/recommendations/domain"
 	"Finance-Manager-System/internal/infrastructure/modules/recommendations/repository"
 )
@@ -31,6 +32,7 @@ func (uc *RecommendationUseCase) GetBudgetRecommendations(
 	userID uuid.UUID,
 	plannedTotal int64,
 	months int,
+	depth int,
 	includeHidden bool,
 	accountIDs []uuid.UUID,
 ) ([]domain.BudgetRecommendation, error) {
@@ -40,13 +42,16 @@ func (uc *RecommendationUseCase) GetBudgetRecommendations(
 	if months < 1 || months > 12 {
 		return nil, ErrInvalidMonths
 	}
+	if err := categoryDomain.ValidateDepth(depth); err != nil {
+		return nil, err
+	}
 
 	now := time.Now().UTC()
 	currentMonthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
 	windowStart := currentMonthStart.AddDate(0, -months, 0)
 	lastMonthStart := currentMonthStart.AddDate(0, -1, 0)
 
-	rows, err := uc.repo.GetMonthlyCategoryExpenses(ctx, userID, windowStart, currentMonthStart, includeHidden, accountIDs)
+	rows, err := uc.repo.GetMonthlyCategoryExpenses(ctx, userID, windowStart, currentMonthStart, depth, includeHidden, accountIDs)
 	if err != nil {
 		return nil, err
 	}
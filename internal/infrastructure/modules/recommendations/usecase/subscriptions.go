@@ -0,0 +1,210 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+
+	"Finance-Manager-System/internal/infrastructure/modules/recommendations/domain"
+	transactionDomain "Finance-Manager-System/internal/infrastructure/modules/transactions/domain"
+)
+
+var ErrInvalidLookbackMonths = errors.New("months must be between 1 and 24")
+
+const (
+	// At least this share of intervals and amounts must fit the cadence and
+	// the typical price for a merchant to count as a subscription.
+	subscriptionRegularShare = 0.75
+	// Charges within this relative distance of the median price are similar.
+	subscriptionAmountTolerance = 0.3
+	// Consecutive charges that differ by at least this share are reported as
+	// a price change.
+	subscriptionPriceChangeShare = 0.01
+)
+
+type cadenceSpec struct {
+	cadence    domain.SubscriptionCadence
+	days       float64
+	tolerance  float64
+	perYear    int64
+	minCharges int
+	years      int
+	months     int
+	dayStep    int
+}
+
+var cadenceSpecs = []cadenceSpec{
+	{cadence: domain.CadenceWeekly, days: 7, tolerance: 2, perYear: 52, minCharges: 3, dayStep: 7},
+	{cadence: domain.CadenceBiweekly, days: 14, tolerance: 3, perYear: 26, minCharges: 3, dayStep: 14},
+	{cadence: domain.CadenceMonthly, days: 30.44, tolerance: 5, perYear: 12, minCharges: 3, months: 1},
+	{cadence: domain.CadenceQuarterly, days: 91.31, tolerance: 12, perYear: 4, minCharges: 3, months: 3},
+	{cadence: domain.CadenceYearly, days: 365.25, tolerance: 20, perYear: 1, minCharges: 2, years: 1},
+}
+
+func (s cadenceSpec) next(t time.Time) time.Time {
+	return t.AddDate(s.years, s.months, s.dayStep)
+}
+
+// GetSubscriptions finds merchants that charge the user on a regular cadence
+// with similar amounts over the last months.
+func (uc *RecommendationUseCase) GetSubscriptions(
+	ctx context.Context,
+	userID uuid.UUID,
+	months int,
+	includeHidden bool,
+	accountIDs []uuid.UUID,
+) ([]domain.Subscription, error) {
+	if months < 1 || months > 24 {
+		return nil, ErrInvalidLookbackMonths
+	}
+
+	now := time.Now().UTC()
+	charges, err := uc.repo.GetExpenseCharges(ctx, userID, now.AddDate(0, -months, 0), includeHidden, accountIDs)
+	if err != nil {
+		return nil, err
+	}
+	return detectSubscriptions(charges, now), nil
+}
+
+func detectSubscriptions(charges []domain.ExpenseCharge, now time.Time) []domain.Subscription {
+	type groupKey struct {
+		merchant string
+		currency string
+	}
+	groups := make(map[groupKey][]domain.ExpenseCharge)
+	for _, charge := range charges {
+		merchant := transactionDomain.MerchantKey(charge.NameTransaction)
+		if merchant == "" || charge.Amount <= 0 {
+			continue
+		}
+		key := groupKey{merchant: merchant, currency: charge.Currency}
+		groups[key] = append(groups[key], charge)
+	}
+
+	subscriptions := make([]domain.Subscription, 0)
+	for key, group := range groups {
+		sort.Slice(group, func(i, j int) bool {
+			return group[i].CompletedAt.Before(group[j].CompletedAt)
+		})
+		sub, ok := detectSubscription(group, now)
+		if !ok {
+			continue
+		}
+		sub.MerchantKey = key.merchant
+		sub.Currency = key.currency
+		subscriptions = append(subscriptions, sub)
+	}
+
+	sort.Slice(subscriptions, func(i, j int) bool {
+		if subscriptions[i].AnnualCost != subscriptions[j].AnnualCost {
+			return subscriptions[i].AnnualCost > subscriptions[j].AnnualCost
+		}
+		return subscriptions[i].MerchantKey < subscriptions[j].MerchantKey
+	})
+	return subscriptions
+}
+
+// detectSubscription checks one merchant's charges sorted by date.
+func detectSubscription(charges []domain.ExpenseCharge, now time.Time) (domain.Subscription, bool) {
+	if len(charges) < 2 {
+		return domain.Subscription{}, false
+	}
+
+	intervals := make([]float64, 0, len(charges)-1)
+	for i := 1; i < len(charges); i++ {
+		intervals = append(intervals, charges[i].CompletedAt.Sub(charges[i-1].CompletedAt).Hours()/24)
+	}
+	medianInterval := median(intervals)
+
+	var spec *cadenceSpec
+	for i := range cadenceSpecs {
+		if math.Abs(medianInterval-cadenceSpecs[i].days) <= cadenceSpecs[i].tolerance {
+			spec = &cadenceSpecs[i]
+			break
+		}
+	}
+	if spec == nil || len(charges) < spec.minCharges {
+		return domain.Subscription{}, false
+	}
+
+	regular := 0
+	for _, interval := range intervals {
+		if math.Abs(interval-spec.days) <= spec.tolerance {
+			regular++
+		}
+	}
+	if float64(regular) < subscriptionRegularShare*float64(len(intervals)) {
+		return domain.Subscription{}, false
+	}
+
+	amounts := make([]float64, 0, len(charges))
+	total := int64(0)
+	for _, charge := range charges {
+		amounts = append(amounts, float64(charge.Amount))
+		total += charge.Amount
+	}
+	medianAmount := median(amounts)
+	similar := 0
+	for _, amount := range amounts {
+		if math.Abs(amount-medianAmount) <= subscriptionAmountTolerance*medianAmount {
+			similar++
+		}
+	}
+	if float64(similar) < subscriptionRegularShare*float64(len(amounts)) {
+		return domain.Subscription{}, false
+	}
+
+	priceChanges := make([]domain.PriceChange, 0)
+	for i := 1; i < len(charges); i++ {
+		previous, current := charges[i-1].Amount, charges[i].Amount
+		change := float64(current-previous) / float64(previous)
+		if math.Abs(change) < subscriptionPriceChangeShare {
+			continue
+		}
+		priceChanges = append(priceChanges, domain.PriceChange{
+			ChangedAt:      charges[i].CompletedAt,
+			PreviousAmount: previous,
+			NewAmount:      current,
+			ChangePercent:  change * 100,
+		})
+	}
+
+	last := charges[len(charges)-1]
+	sub := domain.Subscription{
+		Name:           last.NameTransaction,
+		CategoryID:     last.CategoryID,
+		Cadence:        spec.cadence,
+		IntervalDays:   medianInterval,
+		ChargesCount:   len(charges),
+		AverageAmount:  int64(math.Round(float64(total) / float64(len(charges)))),
+		LastAmount:     last.Amount,
+		FirstChargedAt: charges[0].CompletedAt,
+		LastChargedAt:  last.CompletedAt,
+		NextExpectedAt: spec.next(last.CompletedAt),
+		AnnualCost:     last.Amount * spec.perYear,
+		IsActive:       !now.After(last.CompletedAt.Add(time.Duration((spec.days + 2*spec.tolerance) * 24 * float64(time.Hour)))),
+		PriceChanges:   priceChanges,
+	}
+	if n := len(priceChanges); n > 0 && priceChanges[n-1].ChangedAt.Equal(last.CompletedAt) {
+		alert := priceChanges[n-1]
+		sub.PriceAlert = &alert
+	}
+	return sub, true
+}
+
+func median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
@@ -0,0 +1,61 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+
+	"Finance-Manager-System/internal/infrastructure/modules/recommendations/domain"
+)
+
+func TestDetectSubscriptions(t *testing.T) {
+	now := time.Date(2026, 6, 20, 12, 0, 0, 0, time.UTC)
+	start := time.Date(2026, 1, 15, 9, 0, 0, 0, time.UTC)
+
+	charges := make([]domain.ExpenseCharge, 0)
+	for i := 0; i < 6; i++ {
+		amount := int64(29900)
+		if i == 5 {
+			amount = 34900
+		}
+		charges = append(charges, domain.ExpenseCharge{
+			NameTransaction: "NETFLIX.COM 12345",
+			Amount:          amount,
+			Currency:        "RUB",
+			CompletedAt:     start.AddDate(0, i, 0),
+		})
+	}
+	// Irregular purchases at the same shop are not a subscription.
+	for _, day := range []int{1, 3, 20, 45, 46} {
+		charges = append(charges, domain.ExpenseCharge{
+			NameTransaction: "Pyaterochka",
+			Amount:          50000,
+			Currency:        "RUB",
+			CompletedAt:     start.AddDate(0, 0, day),
+		})
+	}
+
+	subs := detectSubscriptions(charges, now)
+	if len(subs) != 1 {
+		t.Fatalf("expected one subscription, got %d", len(subs))
+	}
+	sub := subs[0]
+	if sub.Cadence != domain.CadenceMonthly || sub.ChargesCount != 6 {
+		t.Fatalf("unexpected subscription: %+v", sub)
+	}
+	if !sub.IsActive {
+		t.Fatalf("expected subscription to be active")
+	}
+	if want := start.AddDate(0, 6, 0); !sub.NextExpectedAt.Equal(want) {
+		t.Fatalf("unexpected next expected date: %v", sub.NextExpectedAt)
+	}
+	if sub.AnnualCost != 34900*12 {
+		t.Fatalf("unexpected annual cost: %d", sub.AnnualCost)
+	}
+	if sub.PriceAlert == nil || sub.PriceAlert.PreviousAmount != 29900 || sub.PriceAlert.NewAmount != 34900 {
+		t.Fatalf("expected price alert, got %+v", sub.PriceAlert)
+	}
+
+	if subs := detectSubscriptions(charges, now.AddDate(0, 3, 0)); len(subs) != 1 || subs[0].IsActive {
+		t.Fatalf("expected lapsed subscription to be inactive")
+	}
+}
@@ -0,0 +1,314 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrRecurringEmptyUserID       = errors.New("user ID cannot be empty (nil UUID)")
+	ErrRecurringEmptyAccountID    = errors.New("account ID cannot be empty")
+	ErrRecurringEmptyName         = errors.New("template name cannot be empty")
+	ErrRecurringNameTooLong       = errors.New("template name cannot be longer than 255 characters")
+	ErrRecurringInvalidAmount     = errors.New("amount must be strictly greater than zero")
+	ErrRecurringInvalidFrequency  = errors.New("frequency must be one of daily, weekly, monthly, yearly")
+	ErrRecurringInvalidInterval   = errors.New("interval must be between 1 and 366")
+	ErrRecurringInvalidDayOfMonth = errors.New("day of month must be between 1 and 31")
+	ErrRecurringInvalidEndDate    = errors.New("end date cannot be before start date")
+	ErrRecurringInvalidCount      = errors.New("count must be strictly greater than zero")
+	ErrRecurringEmptyStartDate    = errors.New("start date cannot be empty")
+	ErrRecurringNotFound          = errors.New("recurring template not found")
+	ErrRecurringAccountNotFound   = errors.New("account not found")
+	ErrOccurrenceNotScheduled     = errors.New("no occurrence of the series at this time")
+	ErrOccurrenceAlreadyHandled   = errors.New("occurrence has already been posted or skipped")
+)
+
+type Frequency string
+
+const (
+	FrequencyDaily   Frequency = "daily"
+	FrequencyWeekly  Frequency = "weekly"
+	FrequencyMonthly Frequency = "monthly"
+	FrequencyYearly  Frequency = "yearly"
+)
+
+type OccurrenceStatus string
+
+const (
+	OccurrencePosted  OccurrenceStatus = "posted"
+	OccurrenceSkipped OccurrenceStatus = "skipped"
+)
+
+// MaxRunFailures turns a template off after that many failed runs in a row.
+// With the doubling retry delay this takes about a day and a half.
+const MaxRunFailures = 12
+
+const (
+	firstRetryDelay = time.Minute
+	maxRetryDelay   = 24 * time.Hour
+)
+
+// RetryDelay is how long a template waits after its failures-th failed run
+// in a row: a minute, doubled on each further failure up to a day.
+func RetryDelay(failures int) time.Duration {
+	delay := firstRetryDelay
+	for i := 1; i < failures && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxRetryDelay {
+		delay = maxRetryDelay
+	}
+	return delay
+}
+
+// maxScheduleSteps bounds schedule expansion so a broken rule cannot loop
+// forever; it covers a daily series for more than 250 years.
+const maxScheduleSteps = 100000
+
+// Schedule is an RRULE-like recurrence. Occurrences keep the clock time of
+// StartDate. DayOfMonth applies to monthly and yearly series and is clamped
+// to the last day of shorter months. EndDate is inclusive by calendar day.
+type Schedule struct {
+	Frequency  Frequency  `db:"frequency" json:"frequency"`
+	Interval   int        `db:"interval_count" json:"interval"`
+	DayOfMonth *int       `db:"day_of_month" json:"day_of_month,omitempty"`
+	StartDate  time.Time  `db:"start_date" json:"start_date"`
+	EndDate    *time.Time `db:"end_date" json:"end_date,omitempty"`
+	Count      *int       `db:"occurrence_count" json:"count,omitempty"`
+}
+
+type RecurringTemplate struct {
+	TemplateID uuid.UUID  `db:"template_id" json:"template_id"`
+	UserID     uuid.UUID  `db:"user_id" json:"user_id"`
+	AccountID  uuid.UUID  `db:"account_id" json:"account_id"`
+	CategoryID *uuid.UUID `db:"category_id" json:"category_id,omitempty"`
+	Name       string     `db:"name_template" json:"name"`
+	IsIncome   bool       `db:"is_income" json:"is_income"`
+	Amount     int64      `db:"amount" json:"amount"`
+	Comment    *string    `db:"comment" json:"comment,omitempty"`
+	Schedule
+	NextRunAt *time.Time `db:"next_run_at" json:"next_run_at,omitempty"`
+	IsActive  bool       `db:"is_active" json:"is_active"`
+	// FailureCount counts the runs in a row that failed to post an
+	// occurrence; the template is not due again before RetryAt.
+	FailureCount int        `db:"failure_count" json:"failure_count"`
+	LastError    *string    `db:"last_error" json:"last_error,omitempty"`
+	RetryAt      *time.Time `db:"retry_at" json:"retry_at,omitempty"`
+	CreatedAt    time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt    time.Time  `db:"updated_at" json:"updated_at"`
+}
+
+// RecurringOccurrence marks one occurrence as handled so it is never posted
+// twice.
+type RecurringOccurrence struct {
+	TemplateID   uuid.UUID        `db:"template_id" json:"template_id"`
+	UserID       uuid.UUID        `db:"user_id" json:"user_id"`
+	OccurrenceAt time.Time        `db:"occurrence_at" json:"occurrence_at"`
+	Status       OccurrenceStatus `db:"status" json:"status"`
+	CreatedAt    time.Time        `db:"created_at" json:"created_at"`
+}
+
+type UpcomingOccurrence struct {
+	TemplateID   uuid.UUID  `json:"template_id"`
+	Name         string     `json:"name"`
+	AccountID    uuid.UUID  `json:"account_id"`
+	CategoryID   *uuid.UUID `json:"category_id,omitempty"`
+	IsIncome     bool       `json:"is_income"`
+	Amount       int64      `json:"amount"`
+	OccurrenceAt time.Time  `json:"occurrence_at"`
+	Skipped      bool       `json:"skipped"`
+}
+
+func NewSchedule(frequency string, interval int, dayOfMonth *int, startDate time.Time, endDate *time.Time, count *int) (Schedule, error) {
+	freq := Frequency(strings.ToLower(strings.TrimSpace(frequency)))
+	switch freq {
+	case FrequencyDaily, FrequencyWeekly, FrequencyMonthly, FrequencyYearly:
+	default:
+		return Schedule{}, ErrRecurringInvalidFrequency
+	}
+	if interval == 0 {
+		interval = 1
+	}
+	if interval < 1 || interval > 366 {
+		return Schedule{}, ErrRecurringInvalidInterval
+	}
+	if startDate.IsZero() {
+		return Schedule{}, ErrRecurringEmptyStartDate
+	}
+	startDate = startDate.UTC().Truncate(time.Second)
+
+	var day *int
+	if dayOfMonth != nil && (freq == FrequencyMonthly || freq == FrequencyYearly) {
+		if *dayOfMonth < 1 || *dayOfMonth > 31 {
+			return Schedule{}, ErrRecurringInvalidDayOfMonth
+		}
+		d := *dayOfMonth
+		day = &d
+	}
+
+	var end *time.Time
+	if endDate != nil && !endDate.IsZero() {
+		e := endDate.UTC()
+		if dateOnly(e).Before(dateOnly(startDate)) {
+			return Schedule{}, ErrRecurringInvalidEndDate
+		}
+		end = &e
+	}
+
+	var limit *int
+	if count != nil {
+		if *count <= 0 {
+			return Schedule{}, ErrRecurringInvalidCount
+		}
+		c := *count
+		limit = &c
+	}
+
+	return Schedule{
+		Frequency:  freq,
+		Interval:   interval,
+		DayOfMonth: day,
+		StartDate:  startDate,
+		EndDate:    end,
+		Count:      limit,
+	}, nil
+}
+
+func NewRecurringTemplate(
+	userID uuid.UUID,
+	accountID uuid.UUID,
+	categoryID *uuid.UUID,
+	name string,
+	isIncome bool,
+	amount int64,
+	comment *string,
+	schedule Schedule,
+) (*RecurringTemplate, error) {
+	if userID == uuid.Nil {
+		return nil, ErrRecurringEmptyUserID
+	}
+	if accountID == uuid.Nil {
+		return nil, ErrRecurringEmptyAccountID
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, ErrRecurringEmptyName
+	}
+	if len([]rune(name)) > 255 {
+		return nil, ErrRecurringNameTooLong
+	}
+	if amount <= 0 {
+		return nil, ErrRecurringInvalidAmount
+	}
+	if comment != nil {
+		cleaned := strings.TrimSpace(*comment)
+		if cleaned == "" {
+			comment = nil
+		} else {
+			comment = &cleaned
+		}
+	}
+
+	now := time.Now().UTC()
+	return &RecurringTemplate{
+		TemplateID: uuid.Nil,
+		UserID:     userID,
+		AccountID:  accountID,
+		CategoryID: categoryID,
+		Name:       name,
+		IsIncome:   isIncome,
+		Amount:     amount,
+		Comment:    comment,
+		Schedule:   schedule,
+		IsActive:   true,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}, nil
+}
+
+// Occurrences returns the occurrences in [from, to], at most limit of them
+// when limit is positive.
+func (s Schedule) Occurrences(from time.Time, to time.Time, limit int) []time.Time {
+	out := make([]time.Time, 0)
+	emitted := 0
+	for n := 0; n < maxScheduleSteps; n++ {
+		at := s.nth(n)
+		if at.Before(s.StartDate) {
+			continue
+		}
+		if s.EndDate != nil && dateOnly(at).After(dateOnly(*s.EndDate)) {
+			break
+		}
+		if s.Count != nil && emitted >= *s.Count {
+			break
+		}
+		emitted++
+		if at.After(to) {
+			break
+		}
+		if at.Before(from) {
+			continue
+		}
+		out = append(out, at)
+		if limit > 0 && len(out) >= limit {
+			break
+		}
+	}
+	return out
+}
+
+// Next returns the first occurrence at or after from, or nil when the
+// series has ended.
+func (s Schedule) Next(from time.Time) *time.Time {
+	horizon := from.AddDate(int(maxScheduleSteps/365)+1, 0, 0)
+	next := s.Occurrences(from, horizon, 1)
+	if len(next) == 0 {
+		return nil
+	}
+	return &next[0]
+}
+
+// IsOccurrence reports whether at is exactly one of the occurrences.
+func (s Schedule) IsOccurrence(at time.Time) bool {
+	at = at.UTC()
+	next := s.Next(at)
+	return next != nil && next.Equal(at)
+}
+
+func (s Schedule) nth(n int) time.Time {
+	start := s.StartDate.UTC()
+	step := n * s.Interval
+	switch s.Frequency {
+	case FrequencyDaily:
+		return start.AddDate(0, 0, step)
+	case FrequencyWeekly:
+		return start.AddDate(0, 0, 7*step)
+	case FrequencyMonthly:
+		return s.onDay(start.Year(), start.Month()+time.Month(step))
+	default:
+		return s.onDay(start.Year()+step, start.Month())
+	}
+}
+
+// onDay builds the occurrence in the given month, clamping the day to the
+// month length. time.Date normalizes month overflow into the year.
+func (s Schedule) onDay(year int, month time.Month) time.Time {
+	start := s.StartDate.UTC()
+	first := time.Date(year, month, 1, start.Hour(), start.Minute(), start.Second(), 0, time.UTC)
+	day := start.Day()
+	if s.DayOfMonth != nil {
+		day = *s.DayOfMonth
+	}
+	if last := first.AddDate(0, 1, -1).Day(); day > last {
+		day = last
+	}
+	return first.AddDate(0, 0, day-1)
+}
+
+func dateOnly(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,90 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestMonthlyScheduleClampsDayOfMonth(t *testing.T) {
+	day := 31
+	start := time.Date(2026, 1, 15, 9, 0, 0, 0, time.UTC)
+	schedule, err := NewSchedule("monthly", 1, &day, start, nil, nil)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+
+	got := schedule.Occurrences(start, time.Date(2026, 4, 30, 23, 0, 0, 0, time.UTC), 0)
+	want := []time.Time{
+		time.Date(2026, 1, 31, 9, 0, 0, 0, time.UTC),
+		time.Date(2026, 2, 28, 9, 0, 0, 0, time.UTC),
+		time.Date(2026, 3, 31, 9, 0, 0, 0, time.UTC),
+		time.Date(2026, 4, 30, 9, 0, 0, 0, time.UTC),
+	}
+	if len(got) != len(want) {
+		t.Fatalf("unexpected occurrences: %v", got)
+	}
+	for i := range want {
+		if !got[i].Equal(want[i]) {
+			t.Fatalf("occurrence %d: expected %s, got %s", i, want[i], got[i])
+		}
+	}
+}
+
+func TestScheduleStopsAtCountAndEndDate(t *testing.T) {
+	start := time.Date(2026, 3, 2, 8, 0, 0, 0, time.UTC)
+	count := 3
+	byCount, err := NewSchedule("weekly", 2, nil, start, nil, &count)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	occurrences := byCount.Occurrences(start, start.AddDate(1, 0, 0), 0)
+	if len(occurrences) != 3 || !occurrences[2].Equal(start.AddDate(0, 0, 28)) {
+		t.Fatalf("unexpected occurrences: %v", occurrences)
+	}
+	if next := byCount.Next(start.AddDate(0, 0, 29)); next != nil {
+		t.Fatalf("series must be over after %d occurrences, got %s", count, next)
+	}
+
+	end := time.Date(2026, 3, 4, 0, 0, 0, 0, time.UTC)
+	byEnd, err := NewSchedule("daily", 1, nil, start, &end, nil)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if occurrences := byEnd.Occurrences(start, start.AddDate(0, 1, 0), 0); len(occurrences) != 3 {
+		t.Fatalf("end date must be inclusive by day, got %v", occurrences)
+	}
+	if !byEnd.IsOccurrence(start.AddDate(0, 0, 1)) || byEnd.IsOccurrence(start.Add(time.Hour)) {
+		t.Fatalf("unexpected occurrence check")
+	}
+}
+
+func TestNewRecurringTemplateValidation(t *testing.T) {
+	schedule, err := NewSchedule("yearly", 0, nil, time.Now(), nil, nil)
+	if err != nil || schedule.Interval != 1 {
+		t.Fatalf("interval must default to 1, got %d %v", schedule.Interval, err)
+	}
+	if _, err := NewSchedule("hourly", 1, nil, time.Now(), nil, nil); err != ErrRecurringInvalidFrequency {
+		t.Fatalf("expected ErrRecurringInvalidFrequency, got %v", err)
+	}
+	if _, err := NewRecurringTemplate(uuid.New(), uuid.New(), nil, "Rent", false, 0, nil, schedule); err != ErrRecurringInvalidAmount {
+		t.Fatalf("expected ErrRecurringInvalidAmount, got %v", err)
+	}
+	tmpl, err := NewRecurringTemplate(uuid.New(), uuid.New(), nil, "  Rent ", false, 50000, nil, schedule)
+	if err != nil || tmpl.Name != "Rent" || !tmpl.IsActive {
+		t.Fatalf("unexpected template: %#v %v", tmpl, err)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	if d := RetryDelay(1); d != time.Minute {
+		t.Fatalf("unexpected first delay %v", d)
+	}
+	if d := RetryDelay(3); d != 4*time.Minute {
+		t.Fatalf("unexpected third delay %v", d)
+	}
+	if d := RetryDelay(40); d != 24*time.Hour {
+		t.Fatalf("delay must be capped at a day, got %v", d)
+	}
+}
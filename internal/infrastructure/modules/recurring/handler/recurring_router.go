@@ -0,0 +1,348 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"Finance-Manager-System/internal/infrastructure/middleware"
+	"Finance-Manager-System/internal/infrastructure/modules/recurring/domain"
+	"Finance-Manager-System/internal/infrastructure/modules/recurring/usecase"
+)
+
+type RecurringRouter struct {
+	recurringUC *usecase.RecurringUseCase
+}
+
+func NewRecurringRouter(recurringUC *usecase.RecurringUseCase) *RecurringRouter {
+	return &RecurringRouter{recurringUC: recurringUC}
+}
+
+func (h *RecurringRouter) Route() chi.Router {
+	r := chi.NewRouter()
+	r.Post("/", h.CreateTemplate)
+	r.Get("/", h.GetTemplates)
+	r.Get("/upcoming", h.GetUpcoming)
+	r.Get("/{id}", h.GetTemplate)
+	r.Put("/{id}", h.UpdateTemplate)
+	r.Delete("/{id}", h.DeleteTemplate)
+	r.Get("/{id}/upcoming", h.GetTemplateUpcoming)
+	r.Post("/{id}/skip", h.SkipOccurrence)
+	return r
+}
+
+type RecurringTemplateReq struct {
+	AccountID  uuid.UUID  `json:"account_id"`
+	CategoryID *uuid.UUID `json:"category_id"`
+	Name       string     `json:"name" example:"Аренда квартиры"`
+	IsIncome   bool       `json:"is_income" example:"false"`
+	Amount     int64      `json:"amount" example:"4500000"`
+	Comment    *string    `json:"comment"`
+	Frequency  string     `json:"frequency" example:"monthly"`
+	Interval   int        `json:"interval" example:"1"`
+	DayOfMonth *int       `json:"day_of_month" example:"5"`
+	StartDate  time.Time  `json:"start_date"`
+	EndDate    *time.Time `json:"end_date"`
+	Count      *int       `json:"count"`
+	IsActive   *bool      `json:"is_active"`
+}
+
+type SkipOccurrenceReq struct {
+	OccurrenceAt time.Time `json:"occurrence_at"`
+}
+
+// @Summary Создать шаблон регулярной операции
+// @Tags recurring
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param request body RecurringTemplateReq true "Данные шаблона и правило повторения"
+// @Success 202 {object} map[string]interface{}
+// @Router /api/v1/recurring [post]
+func (h *RecurringRouter) CreateTemplate(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req RecurringTemplateReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid json", http.StatusBadRequest)
+		return
+	}
+
+	schedule, err := domain.NewSchedule(req.Frequency, req.Interval, req.DayOfMonth, req.StartDate, req.EndDate, req.Count)
+	if err != nil {
+		h.mapError(w, err)
+		return
+	}
+
+	templateID, err := h.recurringUC.CreateTemplate(r.Context(), userID, req.AccountID, req.CategoryID, req.Name, req.IsIncome, req.Amount, req.Comment, schedule)
+	if err != nil {
+		h.mapError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]interface{}{"status": "success", "template_id": templateID})
+}
+
+// @Summary Получить шаблоны регулярных операций
+// @Tags recurring
+// @Security ApiKeyAuth
+// @Produce json
+// @Success 200 {array} domain.RecurringTemplate
+// @Router /api/v1/recurring [get]
+func (h *RecurringRouter) GetTemplates(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	templates, err := h.recurringUC.GetTemplates(r.Context(), userID)
+	if err != nil {
+		h.mapError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(templates)
+}
+
+// @Summary Получить шаблон регулярной операции
+// @Tags recurring
+// @Security ApiKeyAuth
+// @Produce json
+// @Param id path string true "ID шаблона"
+// @Success 200 {object} domain.RecurringTemplate
+// @Router /api/v1/recurring/{id} [get]
+func (h *RecurringRouter) GetTemplate(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	templateID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid template ID", http.StatusBadRequest)
+		return
+	}
+
+	tmpl, err := h.recurringUC.GetTemplate(r.Context(), userID, templateID)
+	if err != nil {
+		h.mapError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tmpl)
+}
+
+// @Summary Изменить серию регулярных операций (уже проведенные операции не меняются)
+// @Tags recurring
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "ID шаблона"
+// @Param request body RecurringTemplateReq true "Новые данные шаблона"
+// @Success 202 {object} map[string]interface{}
+// @Router /api/v1/recurring/{id} [put]
+func (h *RecurringRouter) UpdateTemplate(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	templateID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid template ID", http.StatusBadRequest)
+		return
+	}
+
+	var req RecurringTemplateReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid json", http.StatusBadRequest)
+		return
+	}
+
+	schedule, err := domain.NewSchedule(req.Frequency, req.Interval, req.DayOfMonth, req.StartDate, req.EndDate, req.Count)
+	if err != nil {
+		h.mapError(w, err)
+		return
+	}
+	isActive := true
+	if req.IsActive != nil {
+		isActive = *req.IsActive
+	}
+
+	err = h.recurringUC.UpdateTemplate(r.Context(), userID, templateID, req.AccountID, req.CategoryID, req.Name, req.IsIncome, req.Amount, req.Comment, schedule, isActive)
+	if err != nil {
+		h.mapError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]interface{}{"status": "success", "message": "Recurring template updated"})
+}
+
+// @Summary Удалить шаблон регулярной операции
+// @Tags recurring
+// @Security ApiKeyAuth
+// @Produce json
+// @Param id path string true "ID шаблона"
+// @Success 202 {object} map[string]interface{}
+// @Router /api/v1/recurring/{id} [delete]
+func (h *RecurringRouter) DeleteTemplate(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	templateID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid template ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.recurringUC.DeleteTemplate(r.Context(), userID, templateID); err != nil {
+		h.mapError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]interface{}{"status": "success", "message": "Recurring template deleted"})
+}
+
+// @Summary Получить ближайшие регулярные операции по всем шаблонам
+// @Tags recurring
+// @Security ApiKeyAuth
+// @Produce json
+// @Param days query int false "Горизонт в днях (по умолчанию 30, максимум 366)"
+// @Success 200 {array} domain.UpcomingOccurrence
+// @Router /api/v1/recurring/upcoming [get]
+func (h *RecurringRouter) GetUpcoming(w http.ResponseWriter, r *http.Request) {
+	h.getUpcoming(w, r, false)
+}
+
+// @Summary Получить ближайшие операции по шаблону
+// @Tags recurring
+// @Security ApiKeyAuth
+// @Produce json
+// @Param id path string true "ID шаблона"
+// @Param days query int false "Горизонт в днях (по умолчанию 30, максимум 366)"
+// @Success 200 {array} domain.UpcomingOccurrence
+// @Router /api/v1/recurring/{id}/upcoming [get]
+func (h *RecurringRouter) GetTemplateUpcoming(w http.ResponseWriter, r *http.Request) {
+	h.getUpcoming(w, r, true)
+}
+
+func (h *RecurringRouter) getUpcoming(w http.ResponseWriter, r *http.Request, byTemplate bool) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var templateID *uuid.UUID
+	if byTemplate {
+		id, err := uuid.Parse(chi.URLParam(r, "id"))
+		if err != nil {
+			http.Error(w, "Invalid template ID", http.StatusBadRequest)
+			return
+		}
+		templateID = &id
+	}
+
+	days := 0
+	if raw := r.URL.Query().Get("days"); raw != "" {
+		days, err = strconv.Atoi(raw)
+		if err != nil {
+			http.Error(w, "Invalid days", http.StatusBadRequest)
+			return
+		}
+	}
+
+	upcoming, err := h.recurringUC.GetUpcoming(r.Context(), userID, templateID, days)
+	if err != nil {
+		h.mapError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(upcoming)
+}
+
+// @Summary Пропустить одну операцию серии
+// @Tags recurring
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "ID шаблона"
+// @Param request body SkipOccurrenceReq true "Дата и время пропускаемой операции"
+// @Success 202 {object} map[string]interface{}
+// @Router /api/v1/recurring/{id}/skip [post]
+func (h *RecurringRouter) SkipOccurrence(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	templateID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid template ID", http.StatusBadRequest)
+		return
+	}
+
+	var req SkipOccurrenceReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid json", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.recurringUC.SkipOccurrence(r.Context(), userID, templateID, req.OccurrenceAt); err != nil {
+		h.mapError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]interface{}{"status": "success", "occurrence_at": req.OccurrenceAt.UTC()})
+}
+
+func (h *RecurringRouter) mapError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, domain.ErrRecurringNotFound), errors.Is(err, domain.ErrRecurringAccountNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, domain.ErrOccurrenceAlreadyHandled):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, domain.ErrRecurringEmptyUserID),
+		errors.Is(err, domain.ErrRecurringEmptyAccountID),
+		errors.Is(err, domain.ErrRecurringEmptyName),
+		errors.Is(err, domain.ErrRecurringNameTooLong),
+		errors.Is(err, domain.ErrRecurringInvalidAmount),
+		errors.Is(err, domain.ErrRecurringInvalidFrequency),
+		errors.Is(err, domain.ErrRecurringInvalidInterval),
+		errors.Is(err, domain.ErrRecurringInvalidDayOfMonth),
+		errors.Is(err, domain.ErrRecurringInvalidEndDate),
+		errors.Is(err, domain.ErrRecurringInvalidCount),
+		errors.Is(err, domain.ErrRecurringEmptyStartDate),
+		errors.Is(err, domain.ErrOccurrenceNotScheduled):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,198 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"Finance-Manager-System/internal/infrastructure/database"
+	"Finance-Manager-System/internal/infrastructure/modules/recurring/domain"
+)
+
+type RecurringRepo struct {
+	db *sqlx.DB
+}
+
+func NewRecurringRepo(db *sqlx.DB) *RecurringRepo {
+	return &RecurringRepo{db: db}
+}
+
+func (r *RecurringRepo) AddTemplate(ctx context.Context, tmpl *domain.RecurringTemplate) (uuid.UUID, error) {
+	q := database.GetQueryer(ctx, r.db)
+	query := `
+		INSERT INTO RecurringTemplates (
+			user_id, account_id, category_id, name_template, is_income, amount, comment,
+			frequency, interval_count, day_of_month, start_date, end_date, occurrence_count,
+			next_run_at, is_active, created_at, updated_at
+		)
+		VALUES (
+			:user_id, :account_id, :category_id, :name_template, :is_income, :amount, :comment,
+			:frequency, :interval_count, :day_of_month, :start_date, :end_date, :occurrence_count,
+			:next_run_at, :is_active, :created_at, :updated_at
+		)
+		RETURNING template_id
+	`
+	queryStr, args, err := sqlx.Named(query, tmpl)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to process named query: %w", err)
+	}
+	queryStr = q.Rebind(queryStr)
+
+	var templateID uuid.UUID
+	if err := q.QueryRowContext(ctx, queryStr, args...).Scan(&templateID); err != nil {
+		return uuid.Nil, fmt.Errorf("failed to add recurring template: %w", err)
+	}
+	return templateID, nil
+}
+
+func (r *RecurringRepo) GetTemplatesByUser(ctx context.Context, userID uuid.UUID) ([]domain.RecurringTemplate, error) {
+	q := database.GetQueryer(ctx, r.db)
+	templates := make([]domain.RecurringTemplate, 0)
+	query := `SELECT * FROM RecurringTemplates WHERE user_id = $1 ORDER BY created_at DESC`
+	if err := q.SelectContext(ctx, &templates, query, userID); err != nil {
+		return nil, fmt.Errorf("failed to get recurring templates: %w", err)
+	}
+	return templates, nil
+}
+
+func (r *RecurringRepo) GetTemplateByID(ctx context.Context, userID uuid.UUID, templateID uuid.UUID) (*domain.RecurringTemplate, error) {
+	q := database.GetQueryer(ctx, r.db)
+	var tmpl domain.RecurringTemplate
+	query := `SELECT * FROM RecurringTemplates WHERE user_id = $1 AND template_id = $2`
+	if err := q.GetContext(ctx, &tmpl, query, userID, templateID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrRecurringNotFound
+		}
+		return nil, fmt.Errorf("failed to get recurring template: %w", err)
+	}
+	return &tmpl, nil
+}
+
+// GetDueTemplates returns active templates of all users whose next
+// occurrence is not after now, leaving out failed ones waiting for a retry.
+func (r *RecurringRepo) GetDueTemplates(ctx context.Context, now time.Time, limit int) ([]domain.RecurringTemplate, error) {
+	q := database.GetQueryer(ctx, r.db)
+	templates := make([]domain.RecurringTemplate, 0)
+	query := `
+		SELECT * FROM RecurringTemplates
+		WHERE is_active = true AND next_run_at IS NOT NULL AND next_run_at <= $1
+			AND (retry_at IS NULL OR retry_at <= $1)
+		ORDER BY next_run_at ASC
+		LIMIT $2
+	`
+	if err := q.SelectContext(ctx, &templates, query, now, limit); err != nil {
+		return nil, fmt.Errorf("failed to get due recurring templates: %w", err)
+	}
+	return templates, nil
+}
+
+func (r *RecurringRepo) UpdateTemplate(ctx context.Context, tmpl *domain.RecurringTemplate) error {
+	q := database.GetQueryer(ctx, r.db)
+	query := `
+		UPDATE RecurringTemplates
+		SET account_id = :account_id, category_id = :category_id, name_template = :name_template,
+			is_income = :is_income, amount = :amount, comment = :comment,
+			frequency = :frequency, interval_count = :interval_count, day_of_month = :day_of_month,
+			start_date = :start_date, end_date = :end_date, occurrence_count = :occurrence_count,
+			next_run_at = :next_run_at, is_active = :is_active, updated_at = :updated_at,
+			failure_count = 0, last_error = NULL, retry_at = NULL
+		WHERE template_id = :template_id AND user_id = :user_id
+	`
+	res, err := q.NamedExecContext(ctx, query, tmpl)
+	if err != nil {
+		return fmt.Errorf("failed to update recurring template: %w", err)
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check affected rows: %w", err)
+	}
+	if rowsAffected == 0 {
+		return domain.ErrRecurringNotFound
+	}
+	return nil
+}
+
+// SetNextRun moves a template on after a successful run and clears its
+// failures.
+func (r *RecurringRepo) SetNextRun(ctx context.Context, templateID uuid.UUID, nextRunAt *time.Time) error {
+	q := database.GetQueryer(ctx, r.db)
+	query := `
+		UPDATE RecurringTemplates
+		SET next_run_at = $1, failure_count = 0, last_error = NULL, retry_at = NULL
+		WHERE template_id = $2
+	`
+	if _, err := q.ExecContext(ctx, query, nextRunAt, templateID); err != nil {
+		return fmt.Errorf("failed to update next run: %w", err)
+	}
+	return nil
+}
+
+// RecordRunFailure keeps the error of a failed run and holds the template
+// back until retryAt, or turns it off when deactivate is set.
+func (r *RecurringRepo) RecordRunFailure(ctx context.Context, templateID uuid.UUID, message string, retryAt time.Time, deactivate bool) error {
+	q := database.GetQueryer(ctx, r.db)
+	query := `
+		UPDATE RecurringTemplates
+		SET failure_count = failure_count + 1, last_error = $2, retry_at = $3,
+			is_active = is_active AND NOT $4
+		WHERE template_id = $1
+	`
+	if _, err := q.ExecContext(ctx, query, templateID, message, retryAt, deactivate); err != nil {
+		return fmt.Errorf("failed to record recurring failure: %w", err)
+	}
+	return nil
+}
+
+func (r *RecurringRepo) DeleteTemplate(ctx context.Context, userID uuid.UUID, templateID uuid.UUID) error {
+	q := database.GetQueryer(ctx, r.db)
+	res, err := q.ExecContext(ctx, `DELETE FROM RecurringTemplates WHERE user_id = $1 AND template_id = $2`, userID, templateID)
+	if err != nil {
+		return fmt.Errorf("failed to delete recurring template: %w", err)
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check affected rows: %w", err)
+	}
+	if rowsAffected == 0 {
+		return domain.ErrRecurringNotFound
+	}
+	return nil
+}
+
+// ClaimOccurrence records the occurrence and reports whether it was not
+// handled before.
+func (r *RecurringRepo) ClaimOccurrence(ctx context.Context, occurrence *domain.RecurringOccurrence) (bool, error) {
+	q := database.GetQueryer(ctx, r.db)
+	query := `
+		INSERT INTO RecurringOccurrences (template_id, user_id, occurrence_at, status, created_at)
+		VALUES (:template_id, :user_id, :occurrence_at, :status, :created_at)
+		ON CONFLICT (template_id, occurrence_at) DO NOTHING
+	`
+	res, err := q.NamedExecContext(ctx, query, occurrence)
+	if err != nil {
+		return false, fmt.Errorf("failed to claim occurrence: %w", err)
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to check affected rows: %w", err)
+	}
+	return rowsAffected > 0, nil
+}
+
+func (r *RecurringRepo) GetOccurrences(ctx context.Context, userID uuid.UUID, from time.Time, to time.Time) ([]domain.RecurringOccurrence, error) {
+	q := database.GetQueryer(ctx, r.db)
+	occurrences := make([]domain.RecurringOccurrence, 0)
+	query := `
+		SELECT * FROM RecurringOccurrences
+		WHERE user_id = $1 AND occurrence_at >= $2 AND occurrence_at <= $3
+	`
+	if err := q.SelectContext(ctx, &occurrences, query, userID, from, to); err != nil {
+		return nil, fmt.Errorf("failed to get occurrences: %w", err)
+	}
+	return occurrences, nil
+}
@@ -0,0 +1,315 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"Finance-Manager-System/internal/infrastructure/database"
+	accountDomain "Finance-Manager-System/internal/infrastructure/modules/account/domain"
+	"Finance-Manager-System/internal/infrastructure/modules/recurring/domain"
+)
+
+const (
+	defaultUpcomingDays = 30
+	maxUpcomingDays     = 366
+	dueTemplatesBatch   = 100
+	// maxCatchUpOccurrences caps how many missed occurrences of one template
+	// are posted in a single run, e.g. after a long downtime.
+	maxCatchUpOccurrences = 100
+)
+
+type RecurringRepository interface {
+	AddTemplate(ctx context.Context, tmpl *domain.RecurringTemplate) (uuid.UUID, error)
+	GetTemplatesByUser(ctx context.Context, userID uuid.UUID) ([]domain.RecurringTemplate, error)
+	GetTemplateByID(ctx context.Context, userID uuid.UUID, templateID uuid.UUID) (*domain.RecurringTemplate, error)
+	GetDueTemplates(ctx context.Context, now time.Time, limit int) ([]domain.RecurringTemplate, error)
+	UpdateTemplate(ctx context.Context, tmpl *domain.RecurringTemplate) error
+	SetNextRun(ctx context.Context, templateID uuid.UUID, nextRunAt *time.Time) error
+	RecordRunFailure(ctx context.Context, templateID uuid.UUID, message string, retryAt time.Time, deactivate bool) error
+	DeleteTemplate(ctx context.Context, userID uuid.UUID, templateID uuid.UUID) error
+	ClaimOccurrence(ctx context.Context, occurrence *domain.RecurringOccurrence) (bool, error)
+	GetOccurrences(ctx context.Context, userID uuid.UUID, from time.Time, to time.Time) ([]domain.RecurringOccurrence, error)
+}
+
+// RecurringAccountRepository finds the account of a series; archived
+// accounts are not found.
+type RecurringAccountRepository interface {
+	GetAccountByID(ctx context.Context, userID uuid.UUID, accountID uuid.UUID) (*accountDomain.Account, error)
+}
+
+// TransactionPoster books a materialized occurrence. It is implemented by the
+// transactions use case.
+type TransactionPoster interface {
+	CreateManualTransaction(ctx context.Context, userID uuid.UUID, accountID uuid.UUID, categoryID *uuid.UUID, name string, isIncome bool, amount int64, completedAt time.Time, comment *string, currency string, bankFee int64, status string) error
+}
+
+// CacheInvalidator drops the cached API responses of a user. The HTTP cache
+// middleware does this after writes made through the API; postings of the
+// scheduler need it too.
+type CacheInvalidator interface {
+	InvalidateByUser(ctx context.Context, userID uuid.UUID) error
+}
+
+type RecurringUseCase struct {
+	repo        RecurringRepository
+	accountRepo RecurringAccountRepository
+	poster      TransactionPoster
+	txManager   database.TxManager
+	cache       CacheInvalidator
+}
+
+func NewRecurringUseCase(repo RecurringRepository, accountRepo RecurringAccountRepository, poster TransactionPoster, txManager database.TxManager, cache CacheInvalidator) *RecurringUseCase {
+	return &RecurringUseCase{repo: repo, accountRepo: accountRepo, poster: poster, txManager: txManager, cache: cache}
+}
+
+func (uc *RecurringUseCase) CreateTemplate(ctx context.Context, userID uuid.UUID, accountID uuid.UUID, categoryID *uuid.UUID, name string, isIncome bool, amount int64, comment *string, schedule domain.Schedule) (uuid.UUID, error) {
+	tmpl, err := domain.NewRecurringTemplate(userID, accountID, categoryID, name, isIncome, amount, comment, schedule)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if _, err := uc.accountRepo.GetAccountByID(ctx, userID, accountID); err != nil {
+		return uuid.Nil, fmt.Errorf("%w: %w", domain.ErrRecurringAccountNotFound, err)
+	}
+	tmpl.NextRunAt = schedule.Next(schedule.StartDate)
+	return uc.repo.AddTemplate(ctx, tmpl)
+}
+
+func (uc *RecurringUseCase) GetTemplates(ctx context.Context, userID uuid.UUID) ([]domain.RecurringTemplate, error) {
+	return uc.repo.GetTemplatesByUser(ctx, userID)
+}
+
+func (uc *RecurringUseCase) GetTemplate(ctx context.Context, userID uuid.UUID, templateID uuid.UUID) (*domain.RecurringTemplate, error) {
+	return uc.repo.GetTemplateByID(ctx, userID, templateID)
+}
+
+// UpdateTemplate edits the whole series. Occurrences that were already posted
+// stay as they are; the new terms apply from now on and missed occurrences
+// before now are not backfilled.
+func (uc *RecurringUseCase) UpdateTemplate(ctx context.Context, userID uuid.UUID, templateID uuid.UUID, accountID uuid.UUID, categoryID *uuid.UUID, name string, isIncome bool, amount int64, comment *string, schedule domain.Schedule, isActive bool) error {
+	current, err := uc.repo.GetTemplateByID(ctx, userID, templateID)
+	if err != nil {
+		return err
+	}
+	updated, err := domain.NewRecurringTemplate(userID, accountID, categoryID, name, isIncome, amount, comment, schedule)
+	if err != nil {
+		return err
+	}
+	if _, err := uc.accountRepo.GetAccountByID(ctx, userID, accountID); err != nil {
+		return fmt.Errorf("%w: %w", domain.ErrRecurringAccountNotFound, err)
+	}
+
+	updated.TemplateID = current.TemplateID
+	updated.CreatedAt = current.CreatedAt
+	updated.IsActive = isActive
+	updated.NextRunAt = schedule.Next(time.Now().UTC())
+	return uc.repo.UpdateTemplate(ctx, updated)
+}
+
+func (uc *RecurringUseCase) DeleteTemplate(ctx context.Context, userID uuid.UUID, templateID uuid.UUID) error {
+	return uc.repo.DeleteTemplate(ctx, userID, templateID)
+}
+
+// GetUpcoming lists the occurrences of the next days, optionally for one
+// template. Skipped occurrences are listed and flagged.
+func (uc *RecurringUseCase) GetUpcoming(ctx context.Context, userID uuid.UUID, templateID *uuid.UUID, days int) ([]domain.UpcomingOccurrence, error) {
+	if days <= 0 {
+		days = defaultUpcomingDays
+	}
+	if days > maxUpcomingDays {
+		days = maxUpcomingDays
+	}
+
+	var templates []domain.RecurringTemplate
+	if templateID != nil {
+		tmpl, err := uc.repo.GetTemplateByID(ctx, userID, *templateID)
+		if err != nil {
+			return nil, err
+		}
+		templates = []domain.RecurringTemplate{*tmpl}
+	} else {
+		var err error
+		templates, err = uc.repo.GetTemplatesByUser(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now().UTC()
+	to := now.AddDate(0, 0, days)
+	handled, err := uc.repo.GetOccurrences(ctx, userID, now, to)
+	if err != nil {
+		return nil, err
+	}
+	handledKeys := make(map[occurrenceKey]domain.OccurrenceStatus, len(handled))
+	for _, occ := range handled {
+		handledKeys[newOccurrenceKey(occ.TemplateID, occ.OccurrenceAt)] = occ.Status
+	}
+
+	upcoming := make([]domain.UpcomingOccurrence, 0)
+	for _, tmpl := range templates {
+		if !tmpl.IsActive || tmpl.NextRunAt == nil {
+			continue
+		}
+		from := *tmpl.NextRunAt
+		if from.Before(now) {
+			from = now
+		}
+		for _, at := range tmpl.Schedule.Occurrences(from, to, 0) {
+			status, ok := handledKeys[newOccurrenceKey(tmpl.TemplateID, at)]
+			if ok && status == domain.OccurrencePosted {
+				continue
+			}
+			upcoming = append(upcoming, domain.UpcomingOccurrence{
+				TemplateID:   tmpl.TemplateID,
+				Name:         tmpl.Name,
+				AccountID:    tmpl.AccountID,
+				CategoryID:   tmpl.CategoryID,
+				IsIncome:     tmpl.IsIncome,
+				Amount:       tmpl.Amount,
+				OccurrenceAt: at,
+				Skipped:      ok && status == domain.OccurrenceSkipped,
+			})
+		}
+	}
+
+	sort.Slice(upcoming, func(i, j int) bool {
+		return upcoming[i].OccurrenceAt.Before(upcoming[j].OccurrenceAt)
+	})
+	return upcoming, nil
+}
+
+// SkipOccurrence marks one future occurrence so the scheduler never posts it.
+func (uc *RecurringUseCase) SkipOccurrence(ctx context.Context, userID uuid.UUID, templateID uuid.UUID, occurrenceAt time.Time) error {
+	tmpl, err := uc.repo.GetTemplateByID(ctx, userID, templateID)
+	if err != nil {
+		return err
+	}
+	occurrenceAt = occurrenceAt.UTC()
+	if !tmpl.Schedule.IsOccurrence(occurrenceAt) {
+		return domain.ErrOccurrenceNotScheduled
+	}
+
+	claimed, err := uc.repo.ClaimOccurrence(ctx, &domain.RecurringOccurrence{
+		TemplateID:   tmpl.TemplateID,
+		UserID:       userID,
+		OccurrenceAt: occurrenceAt,
+		Status:       domain.OccurrenceSkipped,
+		CreatedAt:    time.Now().UTC(),
+	})
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		return domain.ErrOccurrenceAlreadyHandled
+	}
+	return nil
+}
+
+// MaterializeDue posts every due occurrence of every user up to now. Each
+// occurrence is claimed and booked in one transaction, so a retry after a
+// failure or a concurrent run never posts it twice. The cached responses of
+// every user that got new transactions are dropped afterwards. It returns the
+// number of transactions created.
+func (uc *RecurringUseCase) MaterializeDue(ctx context.Context, now time.Time) (int, error) {
+	now = now.UTC()
+	templates, err := uc.repo.GetDueTemplates(ctx, now, dueTemplatesBatch)
+	if err != nil {
+		return 0, err
+	}
+
+	posted := 0
+	var errs []error
+	touched := make(map[uuid.UUID]bool)
+	for _, tmpl := range templates {
+		count, err := uc.materializeTemplate(ctx, tmpl, now)
+		posted += count
+		if count > 0 {
+			touched[tmpl.UserID] = true
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("template %s: %w", tmpl.TemplateID, err))
+			if err := uc.recordFailure(ctx, tmpl, err, now); err != nil {
+				errs = append(errs, fmt.Errorf("template %s: %w", tmpl.TemplateID, err))
+			}
+		}
+	}
+	if uc.cache != nil {
+		for userID := range touched {
+			if err := uc.cache.InvalidateByUser(ctx, userID); err != nil {
+				zap.L().Warn("redis_cache_invalidate_failed", zap.String("user_id", userID.String()), zap.Error(err))
+			}
+		}
+	}
+	return posted, errors.Join(errs...)
+}
+
+func (uc *RecurringUseCase) materializeTemplate(ctx context.Context, tmpl domain.RecurringTemplate, now time.Time) (int, error) {
+	occurrences := tmpl.Schedule.Occurrences(*tmpl.NextRunAt, now, maxCatchUpOccurrences)
+
+	posted := 0
+	for _, at := range occurrences {
+		booked := false
+		err := uc.txManager.RunInTransaction(ctx, func(txCtx context.Context) error {
+			claimed, err := uc.repo.ClaimOccurrence(txCtx, &domain.RecurringOccurrence{
+				TemplateID:   tmpl.TemplateID,
+				UserID:       tmpl.UserID,
+				OccurrenceAt: at,
+				Status:       domain.OccurrencePosted,
+				CreatedAt:    now,
+			})
+			if err != nil || !claimed {
+				return err
+			}
+			if err := uc.poster.CreateManualTransaction(txCtx, tmpl.UserID, tmpl.AccountID, tmpl.CategoryID, tmpl.Name, tmpl.IsIncome, tmpl.Amount, at, tmpl.Comment, "", 0, ""); err != nil {
+				return err
+			}
+			booked = true
+			return nil
+		})
+		if err != nil {
+			return posted, err
+		}
+		if booked {
+			posted++
+		}
+	}
+
+	next := tmpl.Schedule.Next(now.Add(time.Nanosecond))
+	if len(occurrences) == maxCatchUpOccurrences {
+		// More missed occurrences are left; continue from the last one on
+		// the next run.
+		next = tmpl.Schedule.Next(occurrences[len(occurrences)-1].Add(time.Nanosecond))
+	}
+	if err := uc.repo.SetNextRun(ctx, tmpl.TemplateID, next); err != nil {
+		return posted, err
+	}
+	return posted, nil
+}
+
+// recordFailure holds a failed template back so it does not take a place
+// among the due templates on every run, and turns it off when it keeps
+// failing. Its next occurrence stays the same, so it is posted once the
+// cause, e.g. an archived account, is fixed.
+func (uc *RecurringUseCase) recordFailure(ctx context.Context, tmpl domain.RecurringTemplate, cause error, now time.Time) error {
+	failures := tmpl.FailureCount + 1
+	deactivate := failures >= domain.MaxRunFailures
+	if deactivate {
+		zap.L().Warn("recurring_template_deactivated", zap.String("template_id", tmpl.TemplateID.String()), zap.Error(cause))
+	}
+	return uc.repo.RecordRunFailure(ctx, tmpl.TemplateID, cause.Error(), now.Add(domain.RetryDelay(failures)), deactivate)
+}
+
+type occurrenceKey struct {
+	templateID uuid.UUID
+	at         int64
+}
+
+func newOccurrenceKey(templateID uuid.UUID, at time.Time) occurrenceKey {
+	return occurrenceKey{templateID: templateID, at: at.UTC().UnixNano()}
+}
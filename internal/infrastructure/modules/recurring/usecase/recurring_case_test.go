@@ -0,0 +1,294 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	accountDomain "Finance-Manager-System/internal/infrastructure/modules/account/domain"
+	recurringDomain "Finance-Manager-System/internal/infrastructure/modules/recurring/domain"
+)
+
+type fakeRecurringRepo struct {
+	templates   map[uuid.UUID]*recurringDomain.RecurringTemplate
+	occurrences map[occurrenceKey]recurringDomain.RecurringOccurrence
+}
+
+func newFakeRecurringRepo() *fakeRecurringRepo {
+	return &fakeRecurringRepo{
+		templates:   make(map[uuid.UUID]*recurringDomain.RecurringTemplate),
+		occurrences: make(map[occurrenceKey]recurringDomain.RecurringOccurrence),
+	}
+}
+
+func (r *fakeRecurringRepo) AddTemplate(ctx context.Context, tmpl *recurringDomain.RecurringTemplate) (uuid.UUID, error) {
+	tmpl.TemplateID = uuid.New()
+	r.templates[tmpl.TemplateID] = tmpl
+	return tmpl.TemplateID, nil
+}
+func (r *fakeRecurringRepo) GetTemplatesByUser(ctx context.Context, userID uuid.UUID) ([]recurringDomain.RecurringTemplate, error) {
+	out := make([]recurringDomain.RecurringTemplate, 0)
+	for _, tmpl := range r.templates {
+		if tmpl.UserID == userID {
+			out = append(out, *tmpl)
+		}
+	}
+	return out, nil
+}
+func (r *fakeRecurringRepo) GetTemplateByID(ctx context.Context, userID uuid.UUID, templateID uuid.UUID) (*recurringDomain.RecurringTemplate, error) {
+	tmpl, ok := r.templates[templateID]
+	if !ok || tmpl.UserID != userID {
+		return nil, recurringDomain.ErrRecurringNotFound
+	}
+	copied := *tmpl
+	return &copied, nil
+}
+func (r *fakeRecurringRepo) GetDueTemplates(ctx context.Context, now time.Time, limit int) ([]recurringDomain.RecurringTemplate, error) {
+	out := make([]recurringDomain.RecurringTemplate, 0)
+	for _, tmpl := range r.templates {
+		if tmpl.IsActive && tmpl.NextRunAt != nil && !tmpl.NextRunAt.After(now) && (tmpl.RetryAt == nil || !tmpl.RetryAt.After(now)) {
+			out = append(out, *tmpl)
+		}
+	}
+	return out, nil
+}
+func (r *fakeRecurringRepo) UpdateTemplate(ctx context.Context, tmpl *recurringDomain.RecurringTemplate) error {
+	r.templates[tmpl.TemplateID] = tmpl
+	return nil
+}
+func (r *fakeRecurringRepo) SetNextRun(ctx context.Context, templateID uuid.UUID, nextRunAt *time.Time) error {
+	tmpl := r.templates[templateID]
+	tmpl.NextRunAt = nextRunAt
+	tmpl.FailureCount, tmpl.LastError, tmpl.RetryAt = 0, nil, nil
+	return nil
+}
+func (r *fakeRecurringRepo) RecordRunFailure(ctx context.Context, templateID uuid.UUID, message string, retryAt time.Time, deactivate bool) error {
+	tmpl := r.templates[templateID]
+	tmpl.FailureCount++
+	tmpl.LastError = &message
+	tmpl.RetryAt = &retryAt
+	tmpl.IsActive = tmpl.IsActive && !deactivate
+	return nil
+}
+func (r *fakeRecurringRepo) DeleteTemplate(ctx context.Context, userID uuid.UUID, templateID uuid.UUID) error {
+	delete(r.templates, templateID)
+	return nil
+}
+func (r *fakeRecurringRepo) ClaimOccurrence(ctx context.Context, occurrence *recurringDomain.RecurringOccurrence) (bool, error) {
+	key := newOccurrenceKey(occurrence.TemplateID, occurrence.OccurrenceAt)
+	if _, ok := r.occurrences[key]; ok {
+		return false, nil
+	}
+	r.occurrences[key] = *occurrence
+	return true, nil
+}
+func (r *fakeRecurringRepo) GetOccurrences(ctx context.Context, userID uuid.UUID, from time.Time, to time.Time) ([]recurringDomain.RecurringOccurrence, error) {
+	out := make([]recurringDomain.RecurringOccurrence, 0)
+	for _, occ := range r.occurrences {
+		if occ.UserID == userID && !occ.OccurrenceAt.Before(from) && !occ.OccurrenceAt.After(to) {
+			out = append(out, occ)
+		}
+	}
+	return out, nil
+}
+
+type fakeRecurringAccountRepo struct {
+	archived map[uuid.UUID]bool
+}
+
+func (r *fakeRecurringAccountRepo) GetAccountByID(ctx context.Context, userID uuid.UUID, accountID uuid.UUID) (*accountDomain.Account, error) {
+	if r.archived[accountID] {
+		return nil, errors.New("account not found")
+	}
+	return &accountDomain.Account{AccountID: accountID, UserID: userID, Currency: "RUB"}, nil
+}
+
+type postedTransaction struct {
+	accountID   uuid.UUID
+	amount      int64
+	completedAt time.Time
+}
+
+type fakeTransactionPoster struct {
+	posted []postedTransaction
+	fail   bool
+}
+
+func (p *fakeTransactionPoster) CreateManualTransaction(ctx context.Context, userID uuid.UUID, accountID uuid.UUID, categoryID *uuid.UUID, name string, isIncome bool, amount int64, completedAt time.Time, comment *string, currency string, bankFee int64, status string) error {
+	if p.fail {
+		return errors.New("account archived")
+	}
+	p.posted = append(p.posted, postedTransaction{accountID: accountID, amount: amount, completedAt: completedAt})
+	return nil
+}
+
+// fakeRecurringTxManager rolls back occurrence claims made by a failed
+// transaction, as the database would.
+type fakeRecurringTxManager struct {
+	repo *fakeRecurringRepo
+}
+
+func (m *fakeRecurringTxManager) RunInTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	snapshot := make(map[occurrenceKey]recurringDomain.RecurringOccurrence, len(m.repo.occurrences))
+	for k, v := range m.repo.occurrences {
+		snapshot[k] = v
+	}
+	if err := fn(ctx); err != nil {
+		m.repo.occurrences = snapshot
+		return err
+	}
+	return nil
+}
+
+type fakeCacheInvalidator struct {
+	users []uuid.UUID
+}
+
+func (f *fakeCacheInvalidator) InvalidateByUser(ctx context.Context, userID uuid.UUID) error {
+	f.users = append(f.users, userID)
+	return nil
+}
+
+func TestMaterializeDueIsIdempotentAndHonoursSkips(t *testing.T) {
+	repo := newFakeRecurringRepo()
+	poster := &fakeTransactionPoster{}
+	cache := &fakeCacheInvalidator{}
+	uc := NewRecurringUseCase(repo, &fakeRecurringAccountRepo{}, poster, &fakeRecurringTxManager{repo: repo}, cache)
+	userID := uuid.New()
+	accountID := uuid.New()
+
+	start := time.Now().UTC().Truncate(time.Second).AddDate(0, 0, -2)
+	schedule, err := recurringDomain.NewSchedule("daily", 1, nil, start, nil, nil)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	templateID, err := uc.CreateTemplate(context.Background(), userID, accountID, nil, "Coffee", false, 300, nil, schedule)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+
+	tomorrow := start.AddDate(0, 0, 3)
+	if err := uc.SkipOccurrence(context.Background(), userID, templateID, tomorrow); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if err := uc.SkipOccurrence(context.Background(), userID, templateID, tomorrow); !errors.Is(err, recurringDomain.ErrOccurrenceAlreadyHandled) {
+		t.Fatalf("expected ErrOccurrenceAlreadyHandled, got %v", err)
+	}
+	if err := uc.SkipOccurrence(context.Background(), userID, templateID, tomorrow.Add(time.Hour)); !errors.Is(err, recurringDomain.ErrOccurrenceNotScheduled) {
+		t.Fatalf("expected ErrOccurrenceNotScheduled, got %v", err)
+	}
+
+	now := time.Now().UTC()
+	posted, err := uc.MaterializeDue(context.Background(), now)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if posted != 3 || len(poster.posted) != 3 || !poster.posted[0].completedAt.Equal(start) {
+		t.Fatalf("expected three catch-up postings, got %d %v", posted, poster.posted)
+	}
+	if len(cache.users) != 1 || cache.users[0] != userID {
+		t.Fatalf("the cache of the user must be dropped once, got %v", cache.users)
+	}
+
+	// A second run, e.g. a restarted scheduler with a stale next_run_at, must
+	// not post the same occurrences again.
+	repo.templates[templateID].NextRunAt = &start
+	if posted, _ := uc.MaterializeDue(context.Background(), now); posted != 0 || len(poster.posted) != 3 {
+		t.Fatalf("occurrences must be posted once, got %d more", posted)
+	}
+	if len(cache.users) != 1 {
+		t.Fatalf("a run without postings must keep the cache, got %v", cache.users)
+	}
+
+	posted, err = uc.MaterializeDue(context.Background(), tomorrow.Add(time.Minute))
+	if err != nil || posted != 0 {
+		t.Fatalf("skipped occurrence must not be posted, got %d %v", posted, err)
+	}
+
+	upcoming, err := uc.GetUpcoming(context.Background(), userID, &templateID, 3)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if len(upcoming) == 0 || upcoming[0].OccurrenceAt.Before(now) {
+		t.Fatalf("unexpected upcoming occurrences: %v", upcoming)
+	}
+}
+
+func TestMaterializeDueRetriesFailedOccurrence(t *testing.T) {
+	repo := newFakeRecurringRepo()
+	poster := &fakeTransactionPoster{fail: true}
+	uc := NewRecurringUseCase(repo, &fakeRecurringAccountRepo{}, poster, &fakeRecurringTxManager{repo: repo}, nil)
+	userID := uuid.New()
+
+	start := time.Now().UTC().Add(-time.Hour).Truncate(time.Second)
+	schedule, _ := recurringDomain.NewSchedule("monthly", 1, nil, start, nil, nil)
+	templateID, err := uc.CreateTemplate(context.Background(), userID, uuid.New(), nil, "Rent", false, 4500000, nil, schedule)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+
+	if _, err := uc.MaterializeDue(context.Background(), time.Now().UTC()); err == nil {
+		t.Fatalf("expected posting error")
+	}
+	failed := repo.templates[templateID]
+	if failed.NextRunAt == nil || !failed.NextRunAt.Equal(start) {
+		t.Fatalf("failed occurrence must stay due, next run %v", failed.NextRunAt)
+	}
+	if failed.FailureCount != 1 || failed.LastError == nil || failed.RetryAt == nil {
+		t.Fatalf("failure must be recorded: %+v", failed)
+	}
+
+	poster.fail = false
+	if posted, err := uc.MaterializeDue(context.Background(), time.Now().UTC()); err != nil || posted != 0 {
+		t.Fatalf("failed template must wait for its retry, got %d %v", posted, err)
+	}
+	posted, err := uc.MaterializeDue(context.Background(), failed.RetryAt.Add(time.Second))
+	if err != nil || posted != 1 {
+		t.Fatalf("expected the retried occurrence to be posted, got %d %v", posted, err)
+	}
+	if retried := repo.templates[templateID]; retried.FailureCount != 0 || retried.LastError != nil || retried.RetryAt != nil {
+		t.Fatalf("a successful run must clear the failure: %+v", retried)
+	}
+}
+
+func TestMaterializeDueDeactivatesFailingTemplate(t *testing.T) {
+	repo := newFakeRecurringRepo()
+	accounts := &fakeRecurringAccountRepo{archived: map[uuid.UUID]bool{}}
+	poster := &fakeTransactionPoster{}
+	uc := NewRecurringUseCase(repo, accounts, poster, &fakeRecurringTxManager{repo: repo}, nil)
+	userID := uuid.New()
+	accountID := uuid.New()
+
+	start := time.Now().UTC().Add(-time.Hour).Truncate(time.Second)
+	schedule, _ := recurringDomain.NewSchedule("monthly", 1, nil, start, nil, nil)
+	templateID, err := uc.CreateTemplate(context.Background(), userID, accountID, nil, "Rent", false, 4500000, nil, schedule)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+
+	accounts.archived[accountID] = true
+	poster.fail = true
+	if _, err := uc.CreateTemplate(context.Background(), userID, accountID, nil, "Rent", false, 4500000, nil, schedule); !errors.Is(err, recurringDomain.ErrRecurringAccountNotFound) {
+		t.Fatalf("expected ErrRecurringAccountNotFound, got %v", err)
+	}
+	if err := uc.UpdateTemplate(context.Background(), userID, templateID, accountID, nil, "Rent", false, 5000000, nil, schedule, true); !errors.Is(err, recurringDomain.ErrRecurringAccountNotFound) {
+		t.Fatalf("expected ErrRecurringAccountNotFound, got %v", err)
+	}
+
+	now := time.Now().UTC()
+	for i := 0; i < recurringDomain.MaxRunFailures; i++ {
+		if _, err := uc.MaterializeDue(context.Background(), now); err == nil {
+			t.Fatalf("run %d: expected posting error", i+1)
+		}
+		now = repo.templates[templateID].RetryAt.Add(time.Second)
+	}
+	if tmpl := repo.templates[templateID]; tmpl.IsActive || tmpl.FailureCount != recurringDomain.MaxRunFailures {
+		t.Fatalf("template must be turned off after %d failures: %+v", recurringDomain.MaxRunFailures, tmpl)
+	}
+	if posted, err := uc.MaterializeDue(context.Background(), now.Add(48*time.Hour)); err != nil || posted != 0 {
+		t.Fatalf("inactive template must not run, got %d %v", posted, err)
+	}
+}
@@ -0,0 +1,46 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Scheduler periodically materializes due recurring occurrences in process.
+type Scheduler struct {
+	uc       *RecurringUseCase
+	interval time.Duration
+}
+
+func NewScheduler(uc *RecurringUseCase, interval time.Duration) *Scheduler {
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	return &Scheduler{uc: uc, interval: interval}
+}
+
+// Run blocks until ctx is cancelled. The first run happens immediately.
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		s.runOnce(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Scheduler) runOnce(ctx context.Context) {
+	posted, err := s.uc.MaterializeDue(ctx, time.Now().UTC())
+	if err != nil {
+		zap.L().Error("recurring_materialize_failed", zap.Error(err))
+	}
+	if posted > 0 {
+		zap.L().Info("recurring_materialized", zap.Int("transactions", posted))
+	}
+}
@@ -0,0 +1,115 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ApplyFilter selects the existing transactions rules are re-applied to.
+type ApplyFilter struct {
+	AccountIDs []uuid.UUID `json:"account_ids"`
+	StartDate  *time.Time  `json:"start_date"`
+	EndDate    *time.Time  `json:"end_date"`
+	// RuleIDs limits the run to these rules; empty means every enabled rule.
+	RuleIDs []uuid.UUID `json:"rule_ids"`
+	// OverwriteCategories replaces categories that are already set. By
+	// default only uncategorised transactions get a category.
+	OverwriteCategories bool `json:"overwrite_categories"`
+}
+
+// Candidate is an existing transaction considered by a re-apply run.
+type Candidate struct {
+	TransactionID   uuid.UUID   `db:"transaction_id"`
+	AccountID       uuid.UUID   `db:"account_id"`
+	NameTransaction string      `db:"name_transaction"`
+	MCCCode         *string     `db:"mcc_code"`
+	Amount          int64       `db:"amount"`
+	IsIncome        bool        `db:"is_income"`
+	CategoryID      *uuid.UUID  `db:"category_id"`
+	IsHidden        bool        `db:"is_hidden"`
+	IsSplit         bool        `db:"is_split"`
+	CompletedAt     time.Time   `db:"completed_at"`
+	TagIDs          []uuid.UUID `db:"-"`
+}
+
+type RuleChange struct {
+	TransactionID uuid.UUID   `json:"transaction_id"`
+	AccountID     uuid.UUID   `json:"account_id"`
+	Name          string      `json:"name"`
+	Amount        int64       `json:"amount"`
+	IsIncome      bool        `json:"is_income"`
+	CompletedAt   time.Time   `json:"completed_at"`
+	OldCategoryID *uuid.UUID  `json:"old_category_id,omitempty"`
+	NewCategoryID *uuid.UUID  `json:"new_category_id,omitempty"`
+	Hide          bool        `json:"hide"`
+	AddTagIDs     []uuid.UUID `json:"add_tag_ids,omitempty"`
+	RuleIDs       []uuid.UUID `json:"rule_ids"`
+}
+
+type ApplyResult struct {
+	Applied       bool         `json:"applied"`
+	Scanned       int          `json:"scanned"`
+	Recategorized int          `json:"recategorized"`
+	Hidden        int          `json:"hidden"`
+	Tagged        int          `json:"tagged"`
+	Changes       []RuleChange `json:"changes"`
+}
+
+// PlanChanges evaluates rules against candidates and returns only the
+// transactions that would actually change. Split transactions keep their
+// category because it is defined by their parts.
+func PlanChanges(rules []Rule, candidates []Candidate, overwriteCategories bool) *ApplyResult {
+	result := &ApplyResult{Scanned: len(candidates), Changes: make([]RuleChange, 0)}
+	for _, c := range candidates {
+		outcome := Evaluate(rules, Target{
+			TransactionID: c.TransactionID,
+			AccountID:     c.AccountID,
+			Description:   c.NameTransaction,
+			MCCCode:       c.MCCCode,
+			Amount:        c.Amount,
+			IsIncome:      c.IsIncome,
+		})
+		if len(outcome.RuleIDs) == 0 {
+			continue
+		}
+
+		change := RuleChange{
+			TransactionID: c.TransactionID,
+			AccountID:     c.AccountID,
+			Name:          c.NameTransaction,
+			Amount:        c.Amount,
+			IsIncome:      c.IsIncome,
+			CompletedAt:   c.CompletedAt,
+			RuleIDs:       outcome.RuleIDs,
+		}
+		changed := false
+
+		if outcome.CategoryID != nil && !c.IsSplit &&
+			(c.CategoryID == nil || (overwriteCategories && *c.CategoryID != *outcome.CategoryID)) {
+			change.OldCategoryID = c.CategoryID
+			change.NewCategoryID = outcome.CategoryID
+			result.Recategorized++
+			changed = true
+		}
+		if outcome.Hide && !c.IsHidden {
+			change.Hide = true
+			result.Hidden++
+			changed = true
+		}
+		for _, tagID := range outcome.TagIDs {
+			if !containsID(c.TagIDs, tagID) {
+				change.AddTagIDs = append(change.AddTagIDs, tagID)
+			}
+		}
+		if len(change.AddTagIDs) > 0 {
+			result.Tagged++
+			changed = true
+		}
+
+		if changed {
+			result.Changes = append(result.Changes, change)
+		}
+	}
+	return result
+}
@@ -0,0 +1,286 @@
+package domain
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	transactionDomain "Finance-Manager-System/internal/infrastructure/modules/transactions/domain"
+)
+
+type RuleSource string
+
+const (
+	// SourceLearned rules are recorded when the user recategorises a transaction.
+	SourceLearned RuleSource = "learned"
+	SourceUser    RuleSource = "user"
+)
+
+const (
+	DefaultRulePriority = 100
+	MaxRulePriority     = 9999
+	// Learned rules run after the user's own rules, MCC rules first.
+	LearnedMCCPriority      = 1000
+	LearnedMerchantPriority = 1001
+
+	maxRuleNameLength = 128
+	maxPatternLength  = 256
+)
+
+var (
+	ErrRuleEmptyUserID       = errors.New("user ID cannot be empty (nil UUID)")
+	ErrRuleNameTooLong       = errors.New("rule name cannot be longer than 128 characters")
+	ErrRuleInvalidPriority   = errors.New("priority must be between 0 and 9999")
+	ErrRuleNoConditions      = errors.New("rule must have at least one condition")
+	ErrRuleNoActions         = errors.New("rule must set a category, add a tag or hide the transaction")
+	ErrRuleInvalidMCC        = errors.New("mcc_code must be 4 digits")
+	ErrRuleInvalidRegex      = errors.New("description_regex is not a valid regular expression")
+	ErrRulePatternTooLong    = errors.New("description pattern cannot be longer than 256 characters")
+	ErrRuleInvalidAmount     = errors.New("min_amount and max_amount must be non-negative and min_amount <= max_amount")
+	ErrRuleNotFound          = errors.New("rule not found")
+	ErrRuleCategoryNotFound  = errors.New("rule category not found")
+	ErrRuleCategoryMismatch  = errors.New("rule category type does not match is_income")
+	ErrRuleTagNotFound       = errors.New("rule tag not found")
+	ErrRuleAccountNotFound   = errors.New("rule account not found")
+	ErrInvalidApplyDateRange = errors.New("start_date must be before end_date")
+)
+
+var mccPattern = regexp.MustCompile(`^[0-9]{4}$`)
+
+// Rule matches transactions by all of its set conditions and applies its
+// actions to them. Rules run in ascending priority order.
+type Rule struct {
+	RuleID    uuid.UUID  `db:"rule_id" json:"rule_id"`
+	UserID    uuid.UUID  `db:"user_id" json:"-"`
+	Name      string     `db:"name" json:"name"`
+	Source    RuleSource `db:"source" json:"source"`
+	Priority  int        `db:"priority" json:"priority"`
+	IsEnabled bool       `db:"is_enabled" json:"is_enabled"`
+
+	IsIncome            *bool      `db:"is_income" json:"is_income,omitempty"`
+	MCCCode             *string    `db:"mcc_code" json:"mcc_code,omitempty"`
+	MerchantKey         string     `db:"merchant_key" json:"merchant_key,omitempty"`
+	DescriptionContains *string    `db:"description_contains" json:"description_contains,omitempty"`
+	DescriptionRegex    *string    `db:"description_regex" json:"description_regex,omitempty"`
+	MinAmount           *int64     `db:"min_amount" json:"min_amount,omitempty"`
+	MaxAmount           *int64     `db:"max_amount" json:"max_amount,omitempty"`
+	AccountID           *uuid.UUID `db:"account_id" json:"account_id,omitempty"`
+
+	CategoryID *uuid.UUID `db:"category_id" json:"category_id,omitempty"`
+	TagID      *uuid.UUID `db:"tag_id" json:"tag_id,omitempty"`
+	Hide       bool       `db:"hide" json:"hide"`
+
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+
+	regex *regexp.Regexp
+}
+
+// RuleParams carries the user-editable part of a rule.
+type RuleParams struct {
+	Name                string     `json:"name"`
+	Priority            *int       `json:"priority"`
+	IsEnabled           *bool      `json:"is_enabled"`
+	IsIncome            *bool      `json:"is_income"`
+	MCCCode             *string    `json:"mcc_code"`
+	MerchantKey         *string    `json:"merchant_key"`
+	DescriptionContains *string    `json:"description_contains"`
+	DescriptionRegex    *string    `json:"description_regex"`
+	MinAmount           *int64     `json:"min_amount"`
+	MaxAmount           *int64     `json:"max_amount"`
+	AccountID           *uuid.UUID `json:"account_id"`
+	CategoryID          *uuid.UUID `json:"category_id"`
+	TagID               *uuid.UUID `json:"tag_id"`
+	Hide                bool       `json:"hide"`
+}
+
+// NewRule validates params and builds a user rule.
+func NewRule(userID uuid.UUID, params RuleParams) (*Rule, error) {
+	if userID == uuid.Nil {
+		return nil, ErrRuleEmptyUserID
+	}
+
+	name := strings.TrimSpace(params.Name)
+	if len([]rune(name)) > maxRuleNameLength {
+		return nil, ErrRuleNameTooLong
+	}
+
+	priority := DefaultRulePriority
+	if params.Priority != nil {
+		priority = *params.Priority
+	}
+	if priority < 0 || priority > MaxRulePriority {
+		return nil, ErrRuleInvalidPriority
+	}
+
+	isEnabled := true
+	if params.IsEnabled != nil {
+		isEnabled = *params.IsEnabled
+	}
+
+	now := time.Now().UTC()
+	rule := &Rule{
+		UserID:              userID,
+		Name:                name,
+		Source:              SourceUser,
+		Priority:            priority,
+		IsEnabled:           isEnabled,
+		IsIncome:            params.IsIncome,
+		MCCCode:             trimmedOrNil(params.MCCCode),
+		DescriptionContains: trimmedOrNil(params.DescriptionContains),
+		DescriptionRegex:    trimmedOrNil(params.DescriptionRegex),
+		MinAmount:           params.MinAmount,
+		MaxAmount:           params.MaxAmount,
+		AccountID:           params.AccountID,
+		CategoryID:          params.CategoryID,
+		TagID:               params.TagID,
+		Hide:                params.Hide,
+		CreatedAt:           now,
+		UpdatedAt:           now,
+	}
+	if params.MerchantKey != nil {
+		rule.MerchantKey = transactionDomain.MerchantKey(*params.MerchantKey)
+	}
+
+	if err := rule.validate(); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+func (r *Rule) validate() error {
+	if r.MCCCode != nil && !mccPattern.MatchString(*r.MCCCode) {
+		return ErrRuleInvalidMCC
+	}
+	for _, pattern := range []*string{r.DescriptionContains, r.DescriptionRegex} {
+		if pattern != nil && len([]rune(*pattern)) > maxPatternLength {
+			return ErrRulePatternTooLong
+		}
+	}
+	if r.DescriptionRegex != nil {
+		if _, err := regexp.Compile(*r.DescriptionRegex); err != nil {
+			return ErrRuleInvalidRegex
+		}
+	}
+	if (r.MinAmount != nil && *r.MinAmount < 0) || (r.MaxAmount != nil && *r.MaxAmount < 0) ||
+		(r.MinAmount != nil && r.MaxAmount != nil && *r.MinAmount > *r.MaxAmount) {
+		return ErrRuleInvalidAmount
+	}
+
+	if r.IsIncome == nil && r.MCCCode == nil && r.MerchantKey == "" && r.DescriptionContains == nil &&
+		r.DescriptionRegex == nil && r.MinAmount == nil && r.MaxAmount == nil && r.AccountID == nil {
+		return ErrRuleNoConditions
+	}
+	if r.CategoryID == nil && r.TagID == nil && !r.Hide {
+		return ErrRuleNoActions
+	}
+	return nil
+}
+
+// Target is the part of a transaction rules look at.
+type Target struct {
+	TransactionID uuid.UUID
+	AccountID     uuid.UUID
+	Description   string
+	MCCCode       *string
+	Amount        int64
+	IsIncome      bool
+}
+
+// Matches reports whether every condition of the rule holds for t.
+func (r *Rule) Matches(t Target) bool {
+	if !r.IsEnabled {
+		return false
+	}
+	if r.IsIncome != nil && *r.IsIncome != t.IsIncome {
+		return false
+	}
+	if r.MCCCode != nil && (t.MCCCode == nil || strings.TrimSpace(*t.MCCCode) != *r.MCCCode) {
+		return false
+	}
+	if r.MerchantKey != "" && transactionDomain.MerchantKey(t.Description) != r.MerchantKey {
+		return false
+	}
+	if r.DescriptionContains != nil && !strings.Contains(strings.ToLower(t.Description), strings.ToLower(*r.DescriptionContains)) {
+		return false
+	}
+	if r.DescriptionRegex != nil {
+		if r.regex == nil {
+			compiled, err := regexp.Compile(*r.DescriptionRegex)
+			if err != nil {
+				return false
+			}
+			r.regex = compiled
+		}
+		if !r.regex.MatchString(t.Description) {
+			return false
+		}
+	}
+	if r.MinAmount != nil && t.Amount < *r.MinAmount {
+		return false
+	}
+	if r.MaxAmount != nil && t.Amount > *r.MaxAmount {
+		return false
+	}
+	if r.AccountID != nil && *r.AccountID != t.AccountID {
+		return false
+	}
+	return true
+}
+
+// Outcome is the combined effect of all rules matching a transaction. The
+// category comes from the first matching rule that sets one; tags and hiding
+// accumulate over every match.
+type Outcome struct {
+	CategoryID     *uuid.UUID
+	CategoryRuleID *uuid.UUID
+	Hide           bool
+	TagIDs         []uuid.UUID
+	RuleIDs        []uuid.UUID
+}
+
+// Evaluate runs rules, which must be ordered by priority, against t.
+func Evaluate(rules []Rule, t Target) Outcome {
+	var outcome Outcome
+	for i := range rules {
+		rule := &rules[i]
+		if !rule.Matches(t) {
+			continue
+		}
+		outcome.RuleIDs = append(outcome.RuleIDs, rule.RuleID)
+		if rule.CategoryID != nil && outcome.CategoryID == nil {
+			outcome.CategoryID = rule.CategoryID
+			outcome.CategoryRuleID = &rule.RuleID
+		}
+		if rule.Hide {
+			outcome.Hide = true
+		}
+		if rule.TagID != nil && !containsID(outcome.TagIDs, *rule.TagID) {
+			outcome.TagIDs = append(outcome.TagIDs, *rule.TagID)
+		}
+	}
+	return outcome
+}
+
+func containsID(ids []uuid.UUID, id uuid.UUID) bool {
+	for _, existing := range ids {
+		if existing == id {
+			return true
+		}
+	}
+	return false
+}
+
+func trimmedOrNil(value *string) *string {
+	if value == nil {
+		return nil
+	}
+	trimmed := strings.TrimSpace(*value)
+	if trimmed == "" {
+		return nil
+	}
+	return &trimmed
+}
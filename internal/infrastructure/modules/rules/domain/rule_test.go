@@ -0,0 +1,108 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func strPtr(value string) *string { return &value }
+
+func TestNewRuleValidation(t *testing.T) {
+	userID := uuid.New()
+	categoryID := uuid.New()
+
+	cases := []struct {
+		name   string
+		params RuleParams
+		want   error
+	}{
+		{"no conditions", RuleParams{CategoryID: &categoryID}, ErrRuleNoConditions},
+		{"no actions", RuleParams{DescriptionContains: strPtr("uber")}, ErrRuleNoActions},
+		{"bad mcc", RuleParams{MCCCode: strPtr("12a4"), CategoryID: &categoryID}, ErrRuleInvalidMCC},
+		{"bad regex", RuleParams{DescriptionRegex: strPtr("(uber"), CategoryID: &categoryID}, ErrRuleInvalidRegex},
+		{"bad priority", RuleParams{Priority: func() *int { p := -1; return &p }(), DescriptionContains: strPtr("uber"), Hide: true}, ErrRuleInvalidPriority},
+	}
+	for _, tc := range cases {
+		if _, err := NewRule(userID, tc.params); err != tc.want {
+			t.Fatalf("%s: expected %v, got %v", tc.name, tc.want, err)
+		}
+	}
+
+	min, max := int64(500), int64(100)
+	if _, err := NewRule(userID, RuleParams{MinAmount: &min, MaxAmount: &max, Hide: true}); err != ErrRuleInvalidAmount {
+		t.Fatalf("expected ErrRuleInvalidAmount, got %v", err)
+	}
+
+	rule, err := NewRule(userID, RuleParams{Name: "  Такси  ", MerchantKey: strPtr("YANDEX*GO 1234"), CategoryID: &categoryID})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if rule.Name != "Такси" || rule.Source != SourceUser || rule.Priority != DefaultRulePriority || !rule.IsEnabled {
+		t.Fatalf("unexpected defaults: %+v", rule)
+	}
+	if rule.MerchantKey == "" || rule.MerchantKey == "YANDEX*GO 1234" {
+		t.Fatalf("merchant key must be normalised, got %q", rule.MerchantKey)
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	userID := uuid.New()
+	taxi, food := uuid.New(), uuid.New()
+	tagID := uuid.New()
+	min := int64(1000)
+
+	first, _ := NewRule(userID, RuleParams{DescriptionContains: strPtr("uber"), MinAmount: &min, CategoryID: &taxi})
+	second, _ := NewRule(userID, RuleParams{DescriptionRegex: strPtr(`(?i)^uber`), CategoryID: &food, TagID: &tagID})
+	third, _ := NewRule(userID, RuleParams{MCCCode: strPtr("4121"), Hide: true})
+	rules := []Rule{*first, *second, *third}
+
+	outcome := Evaluate(rules, Target{Description: "UBER trip", Amount: 1500, MCCCode: strPtr("4121")})
+	if outcome.CategoryID == nil || *outcome.CategoryID != taxi {
+		t.Fatalf("first matching rule must set the category, got %+v", outcome)
+	}
+	if !outcome.Hide || len(outcome.TagIDs) != 1 || len(outcome.RuleIDs) != 3 {
+		t.Fatalf("hide and tags must accumulate over matches, got %+v", outcome)
+	}
+
+	outcome = Evaluate(rules, Target{Description: "Uber trip", Amount: 500})
+	if outcome.CategoryID == nil || *outcome.CategoryID != food || outcome.Hide {
+		t.Fatalf("amount condition must skip the first rule, got %+v", outcome)
+	}
+
+	disabled := *first
+	disabled.IsEnabled = false
+	if outcome := Evaluate([]Rule{disabled}, Target{Description: "uber", Amount: 5000}); len(outcome.RuleIDs) != 0 {
+		t.Fatalf("disabled rule must not match, got %+v", outcome)
+	}
+}
+
+func TestPlanChanges(t *testing.T) {
+	userID := uuid.New()
+	taxi, other := uuid.New(), uuid.New()
+	rule, _ := NewRule(userID, RuleParams{DescriptionContains: strPtr("taxi"), CategoryID: &taxi, Hide: true})
+	now := time.Now().UTC()
+
+	uncategorised := Candidate{TransactionID: uuid.New(), NameTransaction: "City taxi", Amount: 300, CompletedAt: now}
+	categorised := Candidate{TransactionID: uuid.New(), NameTransaction: "Taxi", Amount: 300, CategoryID: &other, IsHidden: true, CompletedAt: now}
+	split := Candidate{TransactionID: uuid.New(), NameTransaction: "taxi", Amount: 300, IsSplit: true, IsHidden: true, CompletedAt: now}
+	unrelated := Candidate{TransactionID: uuid.New(), NameTransaction: "Coffee", Amount: 300, CompletedAt: now}
+	candidates := []Candidate{uncategorised, categorised, split, unrelated}
+
+	result := PlanChanges([]Rule{*rule}, candidates, false)
+	if result.Scanned != 4 || len(result.Changes) != 1 || result.Recategorized != 1 || result.Hidden != 1 {
+		t.Fatalf("unexpected plan without overwrite: %+v", result)
+	}
+	if result.Changes[0].TransactionID != uncategorised.TransactionID {
+		t.Fatalf("only the uncategorised transaction must change, got %+v", result.Changes)
+	}
+
+	result = PlanChanges([]Rule{*rule}, candidates, true)
+	if len(result.Changes) != 2 || result.Recategorized != 2 {
+		t.Fatalf("overwrite must recategorise set categories but keep splits, got %+v", result)
+	}
+	if old := result.Changes[1].OldCategoryID; old == nil || *old != other {
+		t.Fatalf("expected old category to be reported, got %+v", result.Changes[1])
+	}
+}
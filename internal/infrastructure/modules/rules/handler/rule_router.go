@@ -0,0 +1,271 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"Finance-Manager-System/internal/infrastructure/middleware"
+	"Finance-Manager-System/internal/infrastructure/modules/rules/domain"
+	"Finance-Manager-System/internal/infrastructure/modules/rules/usecase"
+)
+
+type RuleRouter struct {
+	ruleUC *usecase.RuleUseCase
+}
+
+func NewRuleRouter(ruleUC *usecase.RuleUseCase) *RuleRouter {
+	return &RuleRouter{ruleUC: ruleUC}
+}
+
+func (h *RuleRouter) Route() chi.Router {
+	r := chi.NewRouter()
+	r.Post("/", h.CreateRule)
+	r.Get("/", h.GetRules)
+	r.Post("/preview", h.PreviewRules)
+	r.Post("/apply", h.ApplyRules)
+	r.Get("/{id}", h.GetRule)
+	r.Put("/{id}", h.UpdateRule)
+	r.Delete("/{id}", h.DeleteRule)
+	return r
+}
+
+// @Summary Создать правило категоризации
+// @Description Условия (MCC, мерчант, подстрока или regex описания, диапазон суммы, счет) объединяются через И; действия: категория, тег, скрытие
+// @Tags rules
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param request body domain.RuleParams true "Условия, действия и приоритет правила"
+// @Success 202 {object} map[string]interface{}
+// @Router /api/v1/rules [post]
+func (h *RuleRouter) CreateRule(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req domain.RuleParams
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid json", http.StatusBadRequest)
+		return
+	}
+
+	ruleID, err := h.ruleUC.CreateRule(r.Context(), userID, req)
+	if err != nil {
+		h.mapError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]interface{}{"status": "success", "rule_id": ruleID})
+}
+
+// @Summary Получить список правил
+// @Description Правила пользователя и выученные правила в порядке приоритета
+// @Tags rules
+// @Security ApiKeyAuth
+// @Produce json
+// @Success 200 {array} domain.Rule
+// @Router /api/v1/rules [get]
+func (h *RuleRouter) GetRules(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	rules, err := h.ruleUC.GetRules(r.Context(), userID)
+	if err != nil {
+		h.mapError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rules)
+}
+
+// @Summary Предпросмотр применения правил
+// @Description Показывает, какие существующие транзакции изменятся, ничего не сохраняя
+// @Tags rules
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param request body domain.ApplyFilter true "Счета, период и правила"
+// @Success 200 {object} domain.ApplyResult
+// @Router /api/v1/rules/preview [post]
+func (h *RuleRouter) PreviewRules(w http.ResponseWriter, r *http.Request) {
+	h.runRules(w, r, false)
+}
+
+// @Summary Применить правила к существующим транзакциям
+// @Description Без overwrite_categories категория назначается только транзакциям без категории
+// @Tags rules
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param request body domain.ApplyFilter true "Счета, период и правила"
+// @Success 202 {object} domain.ApplyResult
+// @Router /api/v1/rules/apply [post]
+func (h *RuleRouter) ApplyRules(w http.ResponseWriter, r *http.Request) {
+	h.runRules(w, r, true)
+}
+
+func (h *RuleRouter) runRules(w http.ResponseWriter, r *http.Request, apply bool) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req domain.ApplyFilter
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid json", http.StatusBadRequest)
+		return
+	}
+
+	var result *domain.ApplyResult
+	if apply {
+		result, err = h.ruleUC.ApplyRules(r.Context(), userID, req)
+	} else {
+		result, err = h.ruleUC.PreviewRules(r.Context(), userID, req)
+	}
+	if err != nil {
+		h.mapError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if apply {
+		w.WriteHeader(http.StatusAccepted)
+	}
+	json.NewEncoder(w).Encode(result)
+}
+
+// @Summary Получить правило
+// @Tags rules
+// @Security ApiKeyAuth
+// @Produce json
+// @Param id path string true "ID правила"
+// @Success 200 {object} domain.Rule
+// @Router /api/v1/rules/{id} [get]
+func (h *RuleRouter) GetRule(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	ruleID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid rule ID", http.StatusBadRequest)
+		return
+	}
+
+	rule, err := h.ruleUC.GetRule(r.Context(), userID, ruleID)
+	if err != nil {
+		h.mapError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rule)
+}
+
+// @Summary Обновить правило
+// @Description Отредактированное выученное правило становится пользовательским
+// @Tags rules
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "ID правила"
+// @Param request body domain.RuleParams true "Новые условия и действия"
+// @Success 202 {object} map[string]interface{}
+// @Router /api/v1/rules/{id} [put]
+func (h *RuleRouter) UpdateRule(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	ruleID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid rule ID", http.StatusBadRequest)
+		return
+	}
+
+	var req domain.RuleParams
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid json", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.ruleUC.UpdateRule(r.Context(), userID, ruleID, req); err != nil {
+		h.mapError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]interface{}{"status": "success"})
+}
+
+// @Summary Удалить правило
+// @Tags rules
+// @Security ApiKeyAuth
+// @Produce json
+// @Param id path string true "ID правила"
+// @Success 202 {object} map[string]interface{}
+// @Router /api/v1/rules/{id} [delete]
+func (h *RuleRouter) DeleteRule(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	ruleID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid rule ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.ruleUC.DeleteRule(r.Context(), userID, ruleID); err != nil {
+		h.mapError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]interface{}{"status": "success"})
+}
+
+func (h *RuleRouter) mapError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, domain.ErrRuleNotFound),
+		errors.Is(err, domain.ErrRuleCategoryNotFound),
+		errors.Is(err, domain.ErrRuleTagNotFound),
+		errors.Is(err, domain.ErrRuleAccountNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, domain.ErrRuleEmptyUserID),
+		errors.Is(err, domain.ErrRuleNameTooLong),
+		errors.Is(err, domain.ErrRuleInvalidPriority),
+		errors.Is(err, domain.ErrRuleNoConditions),
+		errors.Is(err, domain.ErrRuleNoActions),
+		errors.Is(err, domain.ErrRuleInvalidMCC),
+		errors.Is(err, domain.ErrRuleInvalidRegex),
+		errors.Is(err, domain.ErrRulePatternTooLong),
+		errors.Is(err, domain.ErrRuleInvalidAmount),
+		errors.Is(err, domain.ErrRuleCategoryMismatch),
+		errors.Is(err, domain.ErrInvalidApplyDateRange):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,229 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"Finance-Manager-System/internal/infrastructure/database"
+	"Finance-Manager-System/internal/infrastructure/modules/rules/domain"
+	transactionDomain "Finance-Manager-System/internal/infrastructure/modules/transactions/domain"
+)
+
+type RuleRepo struct {
+	db *sqlx.DB
+}
+
+func NewRuleRepo(db *sqlx.DB) *RuleRepo {
+	return &RuleRepo{db: db}
+}
+
+func (r *RuleRepo) AddRule(ctx context.Context, rule *domain.Rule) (uuid.UUID, error) {
+	q := database.GetQueryer(ctx, r.db)
+	query := `
+		INSERT INTO AutoCategoryRules (
+			user_id, name, source, priority, is_enabled,
+			is_income, mcc_code, merchant_key, description_contains, description_regex,
+			min_amount, max_amount, account_id,
+			category_id, tag_id, hide, created_at, updated_at
+		) VALUES (
+			:user_id, :name, :source, :priority, :is_enabled,
+			:is_income, :mcc_code, :merchant_key, :description_contains, :description_regex,
+			:min_amount, :max_amount, :account_id,
+			:category_id, :tag_id, :hide, :created_at, :updated_at
+		)
+		RETURNING rule_id
+	`
+	queryStr, args, err := sqlx.Named(query, rule)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to process named query: %w", err)
+	}
+	queryStr = q.Rebind(queryStr)
+
+	var ruleID uuid.UUID
+	if err := q.QueryRowContext(ctx, queryStr, args...).Scan(&ruleID); err != nil {
+		return uuid.Nil, fmt.Errorf("failed to add rule: %w", err)
+	}
+	return ruleID, nil
+}
+
+func (r *RuleRepo) GetRulesByUser(ctx context.Context, userID uuid.UUID) ([]domain.Rule, error) {
+	q := database.GetQueryer(ctx, r.db)
+	rules := make([]domain.Rule, 0)
+	query := `SELECT * FROM AutoCategoryRules WHERE user_id = $1 ORDER BY priority ASC, created_at ASC`
+	if err := q.SelectContext(ctx, &rules, query, userID); err != nil {
+		return nil, fmt.Errorf("failed to get rules: %w", err)
+	}
+	return rules, nil
+}
+
+// GetEnabledRules returns the rules to evaluate, in priority order.
+func (r *RuleRepo) GetEnabledRules(ctx context.Context, userID uuid.UUID) ([]domain.Rule, error) {
+	q := database.GetQueryer(ctx, r.db)
+	rules := make([]domain.Rule, 0)
+	query := `
+		SELECT * FROM AutoCategoryRules
+		WHERE user_id = $1 AND is_enabled = true
+		ORDER BY priority ASC, created_at ASC
+	`
+	if err := q.SelectContext(ctx, &rules, query, userID); err != nil {
+		return nil, fmt.Errorf("failed to get rules: %w", err)
+	}
+	return rules, nil
+}
+
+func (r *RuleRepo) GetRuleByID(ctx context.Context, userID uuid.UUID, ruleID uuid.UUID) (*domain.Rule, error) {
+	q := database.GetQueryer(ctx, r.db)
+	var rule domain.Rule
+	query := `SELECT * FROM AutoCategoryRules WHERE user_id = $1 AND rule_id = $2`
+	if err := q.GetContext(ctx, &rule, query, userID, ruleID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrRuleNotFound
+		}
+		return nil, fmt.Errorf("failed to get rule: %w", err)
+	}
+	return &rule, nil
+}
+
+func (r *RuleRepo) UpdateRule(ctx context.Context, rule *domain.Rule) error {
+	q := database.GetQueryer(ctx, r.db)
+	query := `
+		UPDATE AutoCategoryRules SET
+			name = :name, source = :source, priority = :priority, is_enabled = :is_enabled,
+			is_income = :is_income, mcc_code = :mcc_code, merchant_key = :merchant_key,
+			description_contains = :description_contains, description_regex = :description_regex,
+			min_amount = :min_amount, max_amount = :max_amount, account_id = :account_id,
+			category_id = :category_id, tag_id = :tag_id, hide = :hide, updated_at = :updated_at
+		WHERE rule_id = :rule_id AND user_id = :user_id
+	`
+	res, err := q.NamedExecContext(ctx, query, rule)
+	if err != nil {
+		return fmt.Errorf("failed to update rule: %w", err)
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check affected rows: %w", err)
+	}
+	if rowsAffected == 0 {
+		return domain.ErrRuleNotFound
+	}
+	return nil
+}
+
+func (r *RuleRepo) DeleteRule(ctx context.Context, userID uuid.UUID, ruleID uuid.UUID) error {
+	q := database.GetQueryer(ctx, r.db)
+	res, err := q.ExecContext(ctx, `DELETE FROM AutoCategoryRules WHERE user_id = $1 AND rule_id = $2`, userID, ruleID)
+	if err != nil {
+		return fmt.Errorf("failed to delete rule: %w", err)
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check affected rows: %w", err)
+	}
+	if rowsAffected == 0 {
+		return domain.ErrRuleNotFound
+	}
+	return nil
+}
+
+// GetCandidates loads the non-transfer transactions selected by filter
+// together with their current tags.
+func (r *RuleRepo) GetCandidates(ctx context.Context, userID uuid.UUID, filter domain.ApplyFilter) ([]domain.Candidate, error) {
+	q := database.GetQueryer(ctx, r.db)
+	where, args := candidateConditions(userID, filter)
+
+	candidates := make([]domain.Candidate, 0)
+	query := `
+		SELECT t.transaction_id, t.account_id, t.name_transaction, t.mcc_code, t.amount, t.is_income,
+			t.category_id, t.is_hidden, t.completed_at,
+			EXISTS (SELECT 1 FROM TransactionSplits sp WHERE sp.transaction_id = t.transaction_id) AS is_split
+		FROM Transactions t
+		WHERE ` + where + `
+		ORDER BY t.completed_at DESC, t.transaction_id DESC
+	`
+	if err := q.SelectContext(ctx, &candidates, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to get transactions for rules: %w", err)
+	}
+
+	var links []struct {
+		TransactionID uuid.UUID `db:"transaction_id"`
+		TagID         uuid.UUID `db:"tag_id"`
+	}
+	tagQuery := `
+		SELECT tt.transaction_id, tt.tag_id
+		FROM TransactionTags tt
+		JOIN Transactions t ON t.transaction_id = tt.transaction_id
+		WHERE ` + where
+	if err := q.SelectContext(ctx, &links, tagQuery, args...); err != nil {
+		return nil, fmt.Errorf("failed to get transaction tags: %w", err)
+	}
+
+	index := make(map[uuid.UUID]int, len(candidates))
+	for i, c := range candidates {
+		index[c.TransactionID] = i
+	}
+	for _, link := range links {
+		if i, ok := index[link.TransactionID]; ok {
+			candidates[i].TagIDs = append(candidates[i].TagIDs, link.TagID)
+		}
+	}
+	return candidates, nil
+}
+
+func candidateConditions(userID uuid.UUID, filter domain.ApplyFilter) (string, []interface{}) {
+	conditions := []string{"t.user_id = $1", "t.transfer_id IS NULL", "t.is_adjustment = false"}
+	args := []interface{}{userID}
+	if filter.StartDate != nil {
+		args = append(args, *filter.StartDate)
+		conditions = append(conditions, fmt.Sprintf("t.completed_at >= $%d", len(args)))
+	}
+	if filter.EndDate != nil {
+		args = append(args, *filter.EndDate)
+		conditions = append(conditions, fmt.Sprintf("t.completed_at <= $%d", len(args)))
+	}
+	if len(filter.AccountIDs) > 0 {
+		placeholders := make([]string, len(filter.AccountIDs))
+		for i, id := range filter.AccountIDs {
+			args = append(args, id)
+			placeholders[i] = fmt.Sprintf("$%d", len(args))
+		}
+		conditions = append(conditions, "t.account_id IN ("+strings.Join(placeholders, ", ")+")")
+	}
+	return strings.Join(conditions, " AND "), args
+}
+
+func (r *RuleRepo) SetTransactionsCategory(ctx context.Context, userID uuid.UUID, transactionIDs []uuid.UUID, categoryID uuid.UUID) error {
+	q := database.GetQueryer(ctx, r.db)
+	query, args, err := sqlx.In(
+		`UPDATE Transactions SET category_id = ?, category_source = ?, category_confidence = NULL
+		 WHERE user_id = ? AND transaction_id IN (?)`,
+		categoryID, transactionDomain.SourceRule, userID, transactionIDs,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to build IN query: %w", err)
+	}
+	if _, err := q.ExecContext(ctx, q.Rebind(query), args...); err != nil {
+		return fmt.Errorf("failed to set category: %w", err)
+	}
+	return nil
+}
+
+func (r *RuleRepo) HideTransactions(ctx context.Context, userID uuid.UUID, transactionIDs []uuid.UUID) error {
+	q := database.GetQueryer(ctx, r.db)
+	query, args, err := sqlx.In(
+		`UPDATE Transactions SET is_hidden = true WHERE user_id = ? AND transaction_id IN (?)`,
+		userID, transactionIDs,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to build IN query: %w", err)
+	}
+	if _, err := q.ExecContext(ctx, q.Rebind(query), args...); err != nil {
+		return fmt.Errorf("failed to hide transactions: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,297 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"Finance-Manager-System/internal/infrastructure/database"
+	accountDomain "Finance-Manager-System/internal/infrastructure/modules/account/domain"
+	categoryDomain "Finance-Manager-System/internal/infrastructure/modules/category/domain"
+	"Finance-Manager-System/internal/infrastructure/modules/rules/domain"
+	tagDomain "Finance-Manager-System/internal/infrastructure/modules/tags/domain"
+)
+
+// applyChunkSize bounds the number of ids bound into one statement.
+const applyChunkSize = 1000
+
+type RuleRepository interface {
+	AddRule(ctx context.Context, rule *domain.Rule) (uuid.UUID, error)
+	GetRulesByUser(ctx context.Context, userID uuid.UUID) ([]domain.Rule, error)
+	GetEnabledRules(ctx context.Context, userID uuid.UUID) ([]domain.Rule, error)
+	GetRuleByID(ctx context.Context, userID uuid.UUID, ruleID uuid.UUID) (*domain.Rule, error)
+	UpdateRule(ctx context.Context, rule *domain.Rule) error
+	DeleteRule(ctx context.Context, userID uuid.UUID, ruleID uuid.UUID) error
+	GetCandidates(ctx context.Context, userID uuid.UUID, filter domain.ApplyFilter) ([]domain.Candidate, error)
+	SetTransactionsCategory(ctx context.Context, userID uuid.UUID, transactionIDs []uuid.UUID, categoryID uuid.UUID) error
+	HideTransactions(ctx context.Context, userID uuid.UUID, transactionIDs []uuid.UUID) error
+}
+
+type RuleCategoryRepository interface {
+	GetCategoryByID(ctx context.Context, userID uuid.UUID, categoryID uuid.UUID) (*categoryDomain.Category, error)
+}
+
+type RuleTagRepository interface {
+	GetTagByID(ctx context.Context, userID uuid.UUID, tagID uuid.UUID) (*tagDomain.Tag, error)
+	AddTagsToTransactions(ctx context.Context, userID uuid.UUID, transactionIDs []uuid.UUID, tagIDs []uuid.UUID) (int, error)
+}
+
+type RuleAccountRepository interface {
+	GetAccountByID(ctx context.Context, userID uuid.UUID, accountID uuid.UUID) (*accountDomain.Account, error)
+	UpdateBalance(ctx context.Context, userID uuid.UUID, accountID uuid.UUID, amountDelta int64) error
+}
+
+type RuleUseCase struct {
+	repo         RuleRepository
+	categoryRepo RuleCategoryRepository
+	tagRepo      RuleTagRepository
+	accountRepo  RuleAccountRepository
+	txManager    database.TxManager
+}
+
+func NewRuleUseCase(repo RuleRepository, categoryRepo RuleCategoryRepository, tagRepo RuleTagRepository, accountRepo RuleAccountRepository, txManager database.TxManager) *RuleUseCase {
+	return &RuleUseCase{repo: repo, categoryRepo: categoryRepo, tagRepo: tagRepo, accountRepo: accountRepo, txManager: txManager}
+}
+
+func (uc *RuleUseCase) CreateRule(ctx context.Context, userID uuid.UUID, params domain.RuleParams) (uuid.UUID, error) {
+	rule, err := domain.NewRule(userID, params)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if err := uc.checkReferences(ctx, rule); err != nil {
+		return uuid.Nil, err
+	}
+	return uc.repo.AddRule(ctx, rule)
+}
+
+func (uc *RuleUseCase) GetRules(ctx context.Context, userID uuid.UUID) ([]domain.Rule, error) {
+	return uc.repo.GetRulesByUser(ctx, userID)
+}
+
+func (uc *RuleUseCase) GetRule(ctx context.Context, userID uuid.UUID, ruleID uuid.UUID) (*domain.Rule, error) {
+	return uc.repo.GetRuleByID(ctx, userID, ruleID)
+}
+
+// UpdateRule replaces the rule's conditions and actions. An edited learned
+// rule becomes a user rule, so later recategorisations no longer overwrite it.
+func (uc *RuleUseCase) UpdateRule(ctx context.Context, userID uuid.UUID, ruleID uuid.UUID, params domain.RuleParams) error {
+	updated, err := domain.NewRule(userID, params)
+	if err != nil {
+		return err
+	}
+	if err := uc.checkReferences(ctx, updated); err != nil {
+		return err
+	}
+
+	return uc.txManager.RunInTransaction(ctx, func(ctx context.Context) error {
+		current, err := uc.repo.GetRuleByID(ctx, userID, ruleID)
+		if err != nil {
+			return err
+		}
+		updated.RuleID = current.RuleID
+		updated.CreatedAt = current.CreatedAt
+		return uc.repo.UpdateRule(ctx, updated)
+	})
+}
+
+func (uc *RuleUseCase) DeleteRule(ctx context.Context, userID uuid.UUID, ruleID uuid.UUID) error {
+	return uc.repo.DeleteRule(ctx, userID, ruleID)
+}
+
+// PreviewRules reports what ApplyRules would change without writing anything.
+func (uc *RuleUseCase) PreviewRules(ctx context.Context, userID uuid.UUID, filter domain.ApplyFilter) (*domain.ApplyResult, error) {
+	return uc.planChanges(ctx, userID, filter)
+}
+
+// ApplyRules re-runs the rules on existing transactions in one database
+// transaction.
+func (uc *RuleUseCase) ApplyRules(ctx context.Context, userID uuid.UUID, filter domain.ApplyFilter) (*domain.ApplyResult, error) {
+	var result *domain.ApplyResult
+	err := uc.txManager.RunInTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		result, err = uc.planChanges(ctx, userID, filter)
+		if err != nil {
+			return err
+		}
+
+		byCategory := make(map[uuid.UUID][]uuid.UUID)
+		hidden := make([]uuid.UUID, 0)
+		accountDeltas := make(map[uuid.UUID]int64)
+		tags := make(map[uuid.UUID][]uuid.UUID)
+		for _, change := range result.Changes {
+			if change.NewCategoryID != nil {
+				byCategory[*change.NewCategoryID] = append(byCategory[*change.NewCategoryID], change.TransactionID)
+			}
+			if change.Hide {
+				hidden = append(hidden, change.TransactionID)
+				// Hidden transactions stop counting towards the balance,
+				// as with ToggleTransactionsVisibility.
+				if change.IsIncome {
+					accountDeltas[change.AccountID] -= change.Amount
+				} else {
+					accountDeltas[change.AccountID] += change.Amount
+				}
+			}
+			if len(change.AddTagIDs) > 0 {
+				tags[change.TransactionID] = change.AddTagIDs
+			}
+		}
+
+		for categoryID, ids := range byCategory {
+			for _, chunk := range chunkIDs(ids) {
+				if err := uc.repo.SetTransactionsCategory(ctx, userID, chunk, categoryID); err != nil {
+					return err
+				}
+			}
+		}
+		for _, chunk := range chunkIDs(hidden) {
+			if err := uc.repo.HideTransactions(ctx, userID, chunk); err != nil {
+				return err
+			}
+		}
+		for accountID, delta := range accountDeltas {
+			if delta == 0 {
+				continue
+			}
+			if err := uc.accountRepo.UpdateBalance(ctx, userID, accountID, delta); err != nil {
+				return fmt.Errorf("failed to update balance for account %s: %w", accountID, err)
+			}
+		}
+		return uc.TagTransactions(ctx, userID, tags)
+	})
+	if err != nil {
+		return nil, err
+	}
+	result.Applied = true
+	return result, nil
+}
+
+// EvaluateTargets runs the user's enabled rules on transactions that are not
+// stored yet, such as rows of a statement being imported.
+func (uc *RuleUseCase) EvaluateTargets(ctx context.Context, userID uuid.UUID, targets []domain.Target) ([]domain.Outcome, error) {
+	rules, err := uc.repo.GetEnabledRules(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	outcomes := make([]domain.Outcome, len(targets))
+	for i, target := range targets {
+		outcomes[i] = domain.Evaluate(rules, target)
+	}
+	return outcomes, nil
+}
+
+// TagTransactions adds tags per transaction. Transactions that do not exist
+// are skipped.
+func (uc *RuleUseCase) TagTransactions(ctx context.Context, userID uuid.UUID, tags map[uuid.UUID][]uuid.UUID) error {
+	byTag := make(map[uuid.UUID][]uuid.UUID)
+	for transactionID, tagIDs := range tags {
+		for _, tagID := range tagIDs {
+			byTag[tagID] = append(byTag[tagID], transactionID)
+		}
+	}
+	for tagID, ids := range byTag {
+		for _, chunk := range chunkIDs(ids) {
+			if _, err := uc.tagRepo.AddTagsToTransactions(ctx, userID, chunk, []uuid.UUID{tagID}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (uc *RuleUseCase) planChanges(ctx context.Context, userID uuid.UUID, filter domain.ApplyFilter) (*domain.ApplyResult, error) {
+	if filter.StartDate != nil && filter.EndDate != nil && filter.EndDate.Before(*filter.StartDate) {
+		return nil, domain.ErrInvalidApplyDateRange
+	}
+
+	rules, err := uc.repo.GetEnabledRules(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(filter.RuleIDs) > 0 {
+		selected := make([]domain.Rule, 0, len(filter.RuleIDs))
+		for _, ruleID := range filter.RuleIDs {
+			found := false
+			for _, rule := range rules {
+				if rule.RuleID == ruleID {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return nil, domain.ErrRuleNotFound
+			}
+		}
+		// Keep priority order.
+		for _, rule := range rules {
+			for _, ruleID := range filter.RuleIDs {
+				if rule.RuleID == ruleID {
+					selected = append(selected, rule)
+					break
+				}
+			}
+		}
+		rules = selected
+	}
+
+	candidates, err := uc.repo.GetCandidates(ctx, userID, filter)
+	if err != nil {
+		return nil, err
+	}
+	return domain.PlanChanges(rules, candidates, filter.OverwriteCategories), nil
+}
+
+// checkReferences makes sure the rule only points at the user's own
+// category, tag and account. A category action also pins the rule to the
+// category's type.
+func (uc *RuleUseCase) checkReferences(ctx context.Context, rule *domain.Rule) error {
+	if rule.CategoryID != nil {
+		category, err := uc.categoryRepo.GetCategoryByID(ctx, rule.UserID, *rule.CategoryID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%w: %w", domain.ErrRuleCategoryNotFound, err)
+		}
+		if err != nil {
+			return err
+		}
+		if rule.IsIncome == nil {
+			isIncome := category.IsIncome
+			rule.IsIncome = &isIncome
+		} else if *rule.IsIncome != category.IsIncome {
+			return domain.ErrRuleCategoryMismatch
+		}
+	}
+	if rule.TagID != nil {
+		_, err := uc.tagRepo.GetTagByID(ctx, rule.UserID, *rule.TagID)
+		if errors.Is(err, tagDomain.ErrTagNotFound) {
+			return fmt.Errorf("%w: %w", domain.ErrRuleTagNotFound, err)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if rule.AccountID != nil {
+		_, err := uc.accountRepo.GetAccountByID(ctx, rule.UserID, *rule.AccountID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%w: %w", domain.ErrRuleAccountNotFound, err)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func chunkIDs(ids []uuid.UUID) [][]uuid.UUID {
+	chunks := make([][]uuid.UUID, 0, len(ids)/applyChunkSize+1)
+	for start := 0; start < len(ids); start += applyChunkSize {
+		end := start + applyChunkSize
+		if end > len(ids) {
+			end = len(ids)
+		}
+		chunks = append(chunks, ids[start:end])
+	}
+	return chunks
+}
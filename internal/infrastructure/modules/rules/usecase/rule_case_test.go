@@ -0,0 +1,203 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	accountDomain "Finance-Manager-System/internal/infrastructure/modules/account/domain"
+	categoryDomain "Finance-Manager-System/internal/infrastructure/modules/category/domain"
+	"Finance-Manager-System/internal/infrastructure/modules/rules/domain"
+	tagDomain "Finance-Manager-System/internal/infrastructure/modules/tags/domain"
+)
+
+type fakeRuleRepo struct {
+	rules      []domain.Rule
+	candidates []domain.Candidate
+	categories map[uuid.UUID][]uuid.UUID
+	hidden     []uuid.UUID
+}
+
+func (r *fakeRuleRepo) AddRule(ctx context.Context, rule *domain.Rule) (uuid.UUID, error) {
+	rule.RuleID = uuid.New()
+	r.rules = append(r.rules, *rule)
+	return rule.RuleID, nil
+}
+func (r *fakeRuleRepo) GetRulesByUser(ctx context.Context, userID uuid.UUID) ([]domain.Rule, error) {
+	return r.rules, nil
+}
+func (r *fakeRuleRepo) GetEnabledRules(ctx context.Context, userID uuid.UUID) ([]domain.Rule, error) {
+	return r.rules, nil
+}
+func (r *fakeRuleRepo) GetRuleByID(ctx context.Context, userID uuid.UUID, ruleID uuid.UUID) (*domain.Rule, error) {
+	for i := range r.rules {
+		if r.rules[i].RuleID == ruleID {
+			rule := r.rules[i]
+			return &rule, nil
+		}
+	}
+	return nil, domain.ErrRuleNotFound
+}
+func (r *fakeRuleRepo) UpdateRule(ctx context.Context, rule *domain.Rule) error {
+	for i := range r.rules {
+		if r.rules[i].RuleID == rule.RuleID {
+			r.rules[i] = *rule
+			return nil
+		}
+	}
+	return domain.ErrRuleNotFound
+}
+func (r *fakeRuleRepo) DeleteRule(ctx context.Context, userID uuid.UUID, ruleID uuid.UUID) error {
+	return nil
+}
+func (r *fakeRuleRepo) GetCandidates(ctx context.Context, userID uuid.UUID, filter domain.ApplyFilter) ([]domain.Candidate, error) {
+	return r.candidates, nil
+}
+func (r *fakeRuleRepo) SetTransactionsCategory(ctx context.Context, userID uuid.UUID, transactionIDs []uuid.UUID, categoryID uuid.UUID) error {
+	if r.categories == nil {
+		r.categories = make(map[uuid.UUID][]uuid.UUID)
+	}
+	r.categories[categoryID] = append(r.categories[categoryID], transactionIDs...)
+	return nil
+}
+func (r *fakeRuleRepo) HideTransactions(ctx context.Context, userID uuid.UUID, transactionIDs []uuid.UUID) error {
+	r.hidden = append(r.hidden, transactionIDs...)
+	return nil
+}
+
+type fakeRuleCategoryRepo struct {
+	categories map[uuid.UUID]categoryDomain.Category
+	err        error
+}
+
+func (r *fakeRuleCategoryRepo) GetCategoryByID(ctx context.Context, userID uuid.UUID, categoryID uuid.UUID) (*categoryDomain.Category, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	category, ok := r.categories[categoryID]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return &category, nil
+}
+
+type fakeRuleTagRepo struct {
+	tagged map[uuid.UUID][]uuid.UUID
+}
+
+func (r *fakeRuleTagRepo) GetTagByID(ctx context.Context, userID uuid.UUID, tagID uuid.UUID) (*tagDomain.Tag, error) {
+	return &tagDomain.Tag{TagID: tagID, UserID: userID}, nil
+}
+func (r *fakeRuleTagRepo) AddTagsToTransactions(ctx context.Context, userID uuid.UUID, transactionIDs []uuid.UUID, tagIDs []uuid.UUID) (int, error) {
+	if r.tagged == nil {
+		r.tagged = make(map[uuid.UUID][]uuid.UUID)
+	}
+	for _, tagID := range tagIDs {
+		r.tagged[tagID] = append(r.tagged[tagID], transactionIDs...)
+	}
+	return len(transactionIDs) * len(tagIDs), nil
+}
+
+type fakeRuleAccountRepo struct {
+	deltas map[uuid.UUID]int64
+}
+
+func (r *fakeRuleAccountRepo) GetAccountByID(ctx context.Context, userID uuid.UUID, accountID uuid.UUID) (*accountDomain.Account, error) {
+	return &accountDomain.Account{AccountID: accountID, UserID: userID}, nil
+}
+func (r *fakeRuleAccountRepo) UpdateBalance(ctx context.Context, userID uuid.UUID, accountID uuid.UUID, amountDelta int64) error {
+	if r.deltas == nil {
+		r.deltas = make(map[uuid.UUID]int64)
+	}
+	r.deltas[accountID] += amountDelta
+	return nil
+}
+
+type fakeRuleTxManager struct{}
+
+func (f *fakeRuleTxManager) RunInTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func TestCreateRuleChecksCategory(t *testing.T) {
+	userID := uuid.New()
+	salaryID := uuid.New()
+	categoryRepo := &fakeRuleCategoryRepo{categories: map[uuid.UUID]categoryDomain.Category{
+		salaryID: {CategoryID: salaryID, UserID: userID, IsIncome: true},
+	}}
+	repo := &fakeRuleRepo{}
+	uc := NewRuleUseCase(repo, categoryRepo, &fakeRuleTagRepo{}, &fakeRuleAccountRepo{}, &fakeRuleTxManager{})
+
+	contains := "зарплата"
+	if _, err := uc.CreateRule(context.Background(), userID, domain.RuleParams{DescriptionContains: &contains, CategoryID: &salaryID}); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if repo.rules[0].IsIncome == nil || !*repo.rules[0].IsIncome {
+		t.Fatalf("rule must take the category type, got %+v", repo.rules[0])
+	}
+
+	expense := false
+	_, err := uc.CreateRule(context.Background(), userID, domain.RuleParams{DescriptionContains: &contains, CategoryID: &salaryID, IsIncome: &expense})
+	if err != domain.ErrRuleCategoryMismatch {
+		t.Fatalf("expected ErrRuleCategoryMismatch, got %v", err)
+	}
+	unknown := uuid.New()
+	_, err = uc.CreateRule(context.Background(), userID, domain.RuleParams{DescriptionContains: &contains, CategoryID: &unknown})
+	if !errors.Is(err, domain.ErrRuleCategoryNotFound) {
+		t.Fatalf("expected ErrRuleCategoryNotFound, got %v", err)
+	}
+
+	// A failing lookup is not reported as a missing category.
+	categoryRepo.err = errors.New("connection reset")
+	_, err = uc.CreateRule(context.Background(), userID, domain.RuleParams{DescriptionContains: &contains, CategoryID: &salaryID})
+	if err == nil || errors.Is(err, domain.ErrRuleCategoryNotFound) {
+		t.Fatalf("expected the repository error, got %v", err)
+	}
+}
+
+func TestApplyRules(t *testing.T) {
+	userID := uuid.New()
+	taxiID, tagID := uuid.New(), uuid.New()
+	accountID := uuid.New()
+	contains := "taxi"
+	rule, err := domain.NewRule(userID, domain.RuleParams{DescriptionContains: &contains, CategoryID: &taxiID, TagID: &tagID, Hide: true})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	rule.RuleID = uuid.New()
+
+	now := time.Now().UTC()
+	expense := domain.Candidate{TransactionID: uuid.New(), AccountID: accountID, NameTransaction: "Taxi", Amount: 500, CompletedAt: now}
+	refund := domain.Candidate{TransactionID: uuid.New(), AccountID: accountID, NameTransaction: "Taxi refund", Amount: 200, IsIncome: true, CompletedAt: now}
+	repo := &fakeRuleRepo{rules: []domain.Rule{*rule}, candidates: []domain.Candidate{expense, refund}}
+	tagRepo := &fakeRuleTagRepo{}
+	accountRepo := &fakeRuleAccountRepo{}
+	uc := NewRuleUseCase(repo, &fakeRuleCategoryRepo{}, tagRepo, accountRepo, &fakeRuleTxManager{})
+
+	preview, err := uc.PreviewRules(context.Background(), userID, domain.ApplyFilter{})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if preview.Applied || len(preview.Changes) != 2 || len(repo.hidden) != 0 {
+		t.Fatalf("preview must not write anything: %+v", preview)
+	}
+
+	if _, err := uc.ApplyRules(context.Background(), userID, domain.ApplyFilter{RuleIDs: []uuid.UUID{uuid.New()}}); err != domain.ErrRuleNotFound {
+		t.Fatalf("expected ErrRuleNotFound, got %v", err)
+	}
+
+	result, err := uc.ApplyRules(context.Background(), userID, domain.ApplyFilter{})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if !result.Applied || len(repo.categories[taxiID]) != 2 || len(repo.hidden) != 2 || len(tagRepo.tagged[tagID]) != 2 {
+		t.Fatalf("unexpected apply: %+v", result)
+	}
+	if accountRepo.deltas[accountID] != 300 {
+		t.Fatalf("hiding must take the transactions out of the balance, got %d", accountRepo.deltas[accountID])
+	}
+}
@@ -0,0 +1,153 @@
+package statement
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var (
+	ErrEmptySource     = errors.New("statement file is empty")
+	ErrUnknownFormat   = errors.New("statement format is not recognized")
+	ErrParserNotFound  = errors.New("statement parser not found")
+	ErrDuplicateParser = errors.New("statement parser already registered")
+)
+
+type Statement struct {
+	Format         string
+	BankName       string
+	AccountType    string
+	ColorHex       string
+	ContractNumber string
+	AccountNumber  string
+	Currency       string
+	Balance        int64
+	HasBalance     bool
+	Transactions   []TransactionEntry
+	Warnings       []string
+}
+
+type TransactionEntry struct {
+	CompletedAt     time.Time
+	Amount          int64
+	IsIncome        bool
+	Description     string
+	CardNumber      string
+	BankFee         int64
+	SenderAccount   *string
+	ReceiverAccount *string
+	MCCCode         *string
+	ExternalID      *string
+	Warnings        []string
+}
+
+// Source is an uploaded statement file. Options carries parser specific
+// settings, e.g. the CSV column mapping.
+type Source struct {
+	Filename string
+	Data     []byte
+	Options  map[string]string
+}
+
+// StatementParser is implemented by every bank statement plugin. Detect must be
+// cheap enough to be called for each registered parser on every upload.
+type StatementParser interface {
+	Name() string
+	Detect(src Source) bool
+	Parse(src Source) (*Statement, error)
+}
+
+type Registry struct {
+	mu      sync.RWMutex
+	parsers []StatementParser
+}
+
+func NewRegistry(parsers ...StatementParser) *Registry {
+	r := &Registry{}
+	for _, p := range parsers {
+		r.MustRegister(p)
+	}
+	return r
+}
+
+func (r *Registry) Register(parser StatementParser) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, p := range r.parsers {
+		if p.Name() == parser.Name() {
+			return fmt.Errorf("%w: %s", ErrDuplicateParser, parser.Name())
+		}
+	}
+	r.parsers = append(r.parsers, parser)
+	return nil
+}
+
+func (r *Registry) MustRegister(parser StatementParser) {
+	if err := r.Register(parser); err != nil {
+		panic(err)
+	}
+}
+
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.parsers))
+	for _, p := range r.parsers {
+		names = append(names, p.Name())
+	}
+	return names
+}
+
+func (r *Registry) Get(name string) (StatementParser, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, p := range r.parsers {
+		if p.Name() == name {
+			return p, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrParserNotFound, name)
+}
+
+// Detect returns the first registered parser that recognizes the source.
+// Parsers are tried in registration order.
+func (r *Registry) Detect(src Source) (StatementParser, error) {
+	if len(src.Data) == 0 {
+		return nil, ErrEmptySource
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, p := range r.parsers {
+		if p.Detect(src) {
+			return p, nil
+		}
+	}
+	return nil, ErrUnknownFormat
+}
+
+// Parse parses the source with the named parser, or auto-detects the format
+// when format is empty.
+func (r *Registry) Parse(src Source, format string) (*Statement, error) {
+	var (
+		parser StatementParser
+		err    error
+	)
+	if format == "" {
+		parser, err = r.Detect(src)
+	} else {
+		parser, err = r.Get(format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	stmt, err := parser.Parse(src)
+	if err != nil {
+		return nil, err
+	}
+	if stmt.Format == "" {
+		stmt.Format = parser.Name()
+	}
+	return stmt, nil
+}
@@ -0,0 +1,54 @@
+package statement
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+type stubParser struct {
+	name   string
+	suffix string
+}
+
+func (p *stubParser) Name() string { return p.name }
+func (p *stubParser) Detect(src Source) bool {
+	return strings.HasSuffix(src.Filename, p.suffix)
+}
+func (p *stubParser) Parse(src Source) (*Statement, error) {
+	return &Statement{AccountNumber: p.name}, nil
+}
+
+func TestRegistryDetectsAndParses(t *testing.T) {
+	r := NewRegistry(&stubParser{name: "a", suffix: ".pdf"}, &stubParser{name: "b", suffix: ".csv"})
+
+	stmt, err := r.Parse(Source{Filename: "x.csv", Data: []byte("1")}, "")
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if stmt.Format != "b" {
+		t.Fatalf("format must default to the parser name, got %q", stmt.Format)
+	}
+
+	stmt, err = r.Parse(Source{Filename: "x.csv", Data: []byte("1")}, "a")
+	if err != nil || stmt.Format != "a" {
+		t.Fatalf("explicit format must bypass detection: %v %#v", err, stmt)
+	}
+
+	if _, err := r.Parse(Source{Filename: "x.ofx", Data: []byte("1")}, ""); !errors.Is(err, ErrUnknownFormat) {
+		t.Fatalf("expected ErrUnknownFormat, got %v", err)
+	}
+	if _, err := r.Parse(Source{Filename: "x.csv"}, ""); !errors.Is(err, ErrEmptySource) {
+		t.Fatalf("expected ErrEmptySource, got %v", err)
+	}
+	if _, err := r.Parse(Source{Filename: "x.csv", Data: []byte("1")}, "c"); !errors.Is(err, ErrParserNotFound) {
+		t.Fatalf("expected ErrParserNotFound, got %v", err)
+	}
+}
+
+func TestRegistryRejectsDuplicateNames(t *testing.T) {
+	r := NewRegistry(&stubParser{name: "a"})
+	if err := r.Register(&stubParser{name: "a"}); !errors.Is(err, ErrDuplicateParser) {
+		t.Fatalf("expected ErrDuplicateParser, got %v", err)
+	}
+}
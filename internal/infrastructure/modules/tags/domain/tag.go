@@ -0,0 +1,73 @@
+package domain
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MaxBulkTransactions caps how many transactions one bulk request may touch.
+const MaxBulkTransactions = 1000
+
+var (
+	ErrTagEmptyUserID        = errors.New("user ID cannot be empty (nil UUID)")
+	ErrTagEmptyName          = errors.New("tag name cannot be empty")
+	ErrTagNameTooLong        = errors.New("tag name cannot be longer than 64 characters")
+	ErrTagInvalidColor       = errors.New("color must be a hex value like #1e90ff")
+	ErrTagNotFound           = errors.New("tag not found")
+	ErrTagAlreadyExists      = errors.New("tag with this name already exists")
+	ErrBulkEmptyTransactions = errors.New("transaction_ids cannot be empty")
+	ErrBulkTooManyItems      = errors.New("too many transactions in one request")
+	ErrBulkEmptyTags         = errors.New("add_tag_ids or remove_tag_ids must not be empty")
+)
+
+var colorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+type Tag struct {
+	TagID             uuid.UUID `db:"tag_id" json:"tag_id"`
+	UserID            uuid.UUID `db:"user_id" json:"-"`
+	NameTag           string    `db:"name_tag" json:"name_tag"`
+	Color             *string   `db:"color" json:"color,omitempty"`
+	CreatedAt         time.Time `db:"created_at" json:"created_at"`
+	TransactionsCount int64     `db:"transactions_count" json:"transactions_count"`
+}
+
+type BulkTagResult struct {
+	Added   int `json:"added"`
+	Removed int `json:"removed"`
+}
+
+func NewTag(userID uuid.UUID, name string, color *string) (*Tag, error) {
+	if userID == uuid.Nil {
+		return nil, ErrTagEmptyUserID
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, ErrTagEmptyName
+	}
+	if len([]rune(name)) > 64 {
+		return nil, ErrTagNameTooLong
+	}
+
+	var finalColor *string
+	if color != nil {
+		cleaned := strings.ToLower(strings.TrimSpace(*color))
+		if cleaned != "" {
+			if !colorPattern.MatchString(cleaned) {
+				return nil, ErrTagInvalidColor
+			}
+			finalColor = &cleaned
+		}
+	}
+
+	return &Tag{
+		UserID:    userID,
+		NameTag:   name,
+		Color:     finalColor,
+		CreatedAt: time.Now().UTC(),
+	}, nil
+}
@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestNewTag(t *testing.T) {
+	color := " #1E90FF "
+	tag, err := NewTag(uuid.New(), "  отпуск ", &color)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if tag.NameTag != "отпуск" {
+		t.Fatalf("name must be trimmed, got %q", tag.NameTag)
+	}
+	if tag.Color == nil || *tag.Color != "#1e90ff" {
+		t.Fatalf("color must be normalized, got %v", tag.Color)
+	}
+
+	empty := ""
+	tag, err = NewTag(uuid.New(), "work", &empty)
+	if err != nil || tag.Color != nil {
+		t.Fatalf("empty color must be dropped, got %v, %v", tag.Color, err)
+	}
+
+	invalid := "blue"
+	if _, err := NewTag(uuid.New(), "work", &invalid); err != ErrTagInvalidColor {
+		t.Fatalf("expected ErrTagInvalidColor, got %v", err)
+	}
+	if _, err := NewTag(uuid.New(), "   ", nil); err != ErrTagEmptyName {
+		t.Fatalf("expected ErrTagEmptyName, got %v", err)
+	}
+	if _, err := NewTag(uuid.New(), strings.Repeat("я", 65), nil); err != ErrTagNameTooLong {
+		t.Fatalf("expected ErrTagNameTooLong, got %v", err)
+	}
+	if _, err := NewTag(uuid.Nil, "work", nil); err != ErrTagEmptyUserID {
+		t.Fatalf("expected ErrTagEmptyUserID, got %v", err)
+	}
+}
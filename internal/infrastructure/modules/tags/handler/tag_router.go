@@ -0,0 +1,250 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"Finance-Manager-System/internal/infrastructure/middleware"
+	"Finance-Manager-System/internal/infrastructure/modules/tags/domain"
+	"Finance-Manager-System/internal/infrastructure/modules/tags/usecase"
+)
+
+type TagRouter struct {
+	tagUC *usecase.TagUseCase
+}
+
+func NewTagRouter(tagUC *usecase.TagUseCase) *TagRouter {
+	return &TagRouter{tagUC: tagUC}
+}
+
+func (h *TagRouter) Route() chi.Router {
+	r := chi.NewRouter()
+	r.Post("/", h.CreateTag)
+	r.Get("/", h.GetTags)
+	r.Post("/bulk", h.BulkTag)
+	r.Get("/{id}", h.GetTag)
+	r.Put("/{id}", h.UpdateTag)
+	r.Delete("/{id}", h.DeleteTag)
+	return r
+}
+
+type TagReq struct {
+	Name  string  `json:"name" example:"отпуск"`
+	Color *string `json:"color" example:"#1e90ff"`
+}
+
+type BulkTagReq struct {
+	TransactionIDs []uuid.UUID `json:"transaction_ids"`
+	AddTagIDs      []uuid.UUID `json:"add_tag_ids"`
+	RemoveTagIDs   []uuid.UUID `json:"remove_tag_ids"`
+}
+
+// @Summary Создать тег
+// @Tags tags
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param request body TagReq true "Название и цвет тега"
+// @Success 202 {object} map[string]interface{}
+// @Router /api/v1/tags [post]
+func (h *TagRouter) CreateTag(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req TagReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid json", http.StatusBadRequest)
+		return
+	}
+
+	tagID, err := h.tagUC.CreateTag(r.Context(), userID, req.Name, req.Color)
+	if err != nil {
+		h.mapError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]interface{}{"status": "success", "tag_id": tagID})
+}
+
+// @Summary Получить список тегов
+// @Tags tags
+// @Security ApiKeyAuth
+// @Produce json
+// @Success 200 {array} domain.Tag
+// @Router /api/v1/tags [get]
+func (h *TagRouter) GetTags(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	tags, err := h.tagUC.GetTags(r.Context(), userID)
+	if err != nil {
+		h.mapError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tags)
+}
+
+// @Summary Массово добавить или снять теги с транзакций
+// @Description Все изменения выполняются в одной транзакции БД; чужие транзакции игнорируются
+// @Tags tags
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param request body BulkTagReq true "Транзакции и теги"
+// @Success 202 {object} map[string]interface{}
+// @Router /api/v1/tags/bulk [post]
+func (h *TagRouter) BulkTag(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req BulkTagReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid json", http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.tagUC.BulkTag(r.Context(), userID, req.TransactionIDs, req.AddTagIDs, req.RemoveTagIDs)
+	if err != nil {
+		h.mapError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]interface{}{"status": "success", "added": result.Added, "removed": result.Removed})
+}
+
+// @Summary Получить тег
+// @Tags tags
+// @Security ApiKeyAuth
+// @Produce json
+// @Param id path string true "ID тега"
+// @Success 200 {object} domain.Tag
+// @Router /api/v1/tags/{id} [get]
+func (h *TagRouter) GetTag(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	tagID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid tag ID", http.StatusBadRequest)
+		return
+	}
+
+	tag, err := h.tagUC.GetTag(r.Context(), userID, tagID)
+	if err != nil {
+		h.mapError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tag)
+}
+
+// @Summary Обновить тег
+// @Tags tags
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "ID тега"
+// @Param request body TagReq true "Новые название и цвет"
+// @Success 202 {object} map[string]interface{}
+// @Router /api/v1/tags/{id} [put]
+func (h *TagRouter) UpdateTag(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	tagID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid tag ID", http.StatusBadRequest)
+		return
+	}
+
+	var req TagReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid json", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.tagUC.UpdateTag(r.Context(), userID, tagID, req.Name, req.Color); err != nil {
+		h.mapError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]interface{}{"status": "success"})
+}
+
+// @Summary Удалить тег
+// @Description Тег снимается со всех транзакций
+// @Tags tags
+// @Security ApiKeyAuth
+// @Produce json
+// @Param id path string true "ID тега"
+// @Success 202 {object} map[string]interface{}
+// @Router /api/v1/tags/{id} [delete]
+func (h *TagRouter) DeleteTag(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	tagID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid tag ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.tagUC.DeleteTag(r.Context(), userID, tagID); err != nil {
+		h.mapError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]interface{}{"status": "success"})
+}
+
+func (h *TagRouter) mapError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, domain.ErrTagNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, domain.ErrTagAlreadyExists):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, domain.ErrTagEmptyUserID),
+		errors.Is(err, domain.ErrTagEmptyName),
+		errors.Is(err, domain.ErrTagNameTooLong),
+		errors.Is(err, domain.ErrTagInvalidColor),
+		errors.Is(err, domain.ErrBulkEmptyTransactions),
+		errors.Is(err, domain.ErrBulkTooManyItems),
+		errors.Is(err, domain.ErrBulkEmptyTags):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"Finance-Manager-System/internal/infrastructure/database"
+	"Finance-Manager-System/internal/infrastructure/modules/tags/domain"
+)
+
+const selectTags = `
+	SELECT g.tag_id, g.user_id, g.name_tag, g.color, g.created_at,
+		(SELECT COUNT(*) FROM TransactionTags tt WHERE tt.tag_id = g.tag_id) AS transactions_count
+	FROM Tags g
+`
+
+type TagRepo struct {
+	db *sqlx.DB
+}
+
+func NewTagRepo(db *sqlx.DB) *TagRepo {
+	return &TagRepo{db: db}
+}
+
+func (r *TagRepo) AddTag(ctx context.Context, tag *domain.Tag) (uuid.UUID, error) {
+	q := database.GetQueryer(ctx, r.db)
+	query := `
+		INSERT INTO Tags (user_id, name_tag, color, created_at)
+		VALUES (:user_id, :name_tag, :color, :created_at)
+		RETURNING tag_id
+	`
+	queryStr, args, err := sqlx.Named(query, tag)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to process named query: %w", err)
+	}
+	queryStr = q.Rebind(queryStr)
+
+	var tagID uuid.UUID
+	if err := q.QueryRowContext(ctx, queryStr, args...).Scan(&tagID); err != nil {
+		return uuid.Nil, fmt.Errorf("failed to add tag: %w", err)
+	}
+	return tagID, nil
+}
+
+func (r *TagRepo) GetTagsByUser(ctx context.Context, userID uuid.UUID) ([]domain.Tag, error) {
+	q := database.GetQueryer(ctx, r.db)
+	tags := make([]domain.Tag, 0)
+	query := selectTags + ` WHERE g.user_id = $1 ORDER BY lower(g.name_tag) ASC`
+	if err := q.SelectContext(ctx, &tags, query, userID); err != nil {
+		return nil, fmt.Errorf("failed to get tags: %w", err)
+	}
+	return tags, nil
+}
+
+func (r *TagRepo) GetTagByID(ctx context.Context, userID uuid.UUID, tagID uuid.UUID) (*domain.Tag, error) {
+	q := database.GetQueryer(ctx, r.db)
+	var tag domain.Tag
+	query := selectTags + ` WHERE g.user_id = $1 AND g.tag_id = $2`
+	if err := q.GetContext(ctx, &tag, query, userID, tagID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrTagNotFound
+		}
+		return nil, fmt.Errorf("failed to get tag: %w", err)
+	}
+	return &tag, nil
+}
+
+// GetTagByName looks a tag up case-insensitively.
+func (r *TagRepo) GetTagByName(ctx context.Context, userID uuid.UUID, name string) (*domain.Tag, error) {
+	q := database.GetQueryer(ctx, r.db)
+	var tag domain.Tag
+	query := selectTags + ` WHERE g.user_id = $1 AND lower(g.name_tag) = lower($2)`
+	if err := q.GetContext(ctx, &tag, query, userID, name); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrTagNotFound
+		}
+		return nil, fmt.Errorf("failed to get tag: %w", err)
+	}
+	return &tag, nil
+}
+
+func (r *TagRepo) UpdateTag(ctx context.Context, tag *domain.Tag) error {
+	q := database.GetQueryer(ctx, r.db)
+	query := `UPDATE Tags SET name_tag = :name_tag, color = :color WHERE tag_id = :tag_id AND user_id = :user_id`
+	res, err := q.NamedExecContext(ctx, query, tag)
+	if err != nil {
+		return fmt.Errorf("failed to update tag: %w", err)
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check affected rows: %w", err)
+	}
+	if rowsAffected == 0 {
+		return domain.ErrTagNotFound
+	}
+	return nil
+}
+
+func (r *TagRepo) DeleteTag(ctx context.Context, userID uuid.UUID, tagID uuid.UUID) error {
+	q := database.GetQueryer(ctx, r.db)
+	res, err := q.ExecContext(ctx, `DELETE FROM Tags WHERE user_id = $1 AND tag_id = $2`, userID, tagID)
+	if err != nil {
+		return fmt.Errorf("failed to delete tag: %w", err)
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check affected rows: %w", err)
+	}
+	if rowsAffected == 0 {
+		return domain.ErrTagNotFound
+	}
+	return nil
+}
+
+// AddTagsToTransactions links every tag to every transaction of the user.
+// Transactions of other users are skipped and existing links are kept.
+func (r *TagRepo) AddTagsToTransactions(ctx context.Context, userID uuid.UUID, transactionIDs []uuid.UUID, tagIDs []uuid.UUID) (int, error) {
+	q := database.GetQueryer(ctx, r.db)
+	query := `
+		INSERT INTO TransactionTags (transaction_id, tag_id, user_id)
+		SELECT t.transaction_id, g.tag_id, t.user_id
+		FROM Transactions t
+		JOIN Tags g ON g.user_id = t.user_id
+		WHERE t.user_id = ? AND t.transaction_id IN (?) AND g.tag_id IN (?)
+		ON CONFLICT (transaction_id, tag_id) DO NOTHING
+	`
+	query, args, err := sqlx.In(query, userID, transactionIDs, tagIDs)
+	if err != nil {
+		return 0, fmt.Errorf("failed to build tag query: %w", err)
+	}
+	res, err := q.ExecContext(ctx, q.Rebind(query), args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to add tags: %w", err)
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to check affected rows: %w", err)
+	}
+	return int(rowsAffected), nil
+}
+
+func (r *TagRepo) RemoveTagsFromTransactions(ctx context.Context, userID uuid.UUID, transactionIDs []uuid.UUID, tagIDs []uuid.UUID) (int, error) {
+	q := database.GetQueryer(ctx, r.db)
+	query := `DELETE FROM TransactionTags WHERE user_id = ? AND transaction_id IN (?) AND tag_id IN (?)`
+	query, args, err := sqlx.In(query, userID, transactionIDs, tagIDs)
+	if err != nil {
+		return 0, fmt.Errorf("failed to build tag query: %w", err)
+	}
+	res, err := q.ExecContext(ctx, q.Rebind(query), args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to remove tags: %w", err)
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to check affected rows: %w", err)
+	}
+	return int(rowsAffected), nil
+}
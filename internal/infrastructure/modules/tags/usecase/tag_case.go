@@ -0,0 +1,159 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+
+	"Finance-Manager-System/internal/infrastructure/database"
+	"Finance-Manager-System/internal/infrastructure/modules/tags/domain"
+)
+
+type TagRepository interface {
+	AddTag(ctx context.Context, tag *domain.Tag) (uuid.UUID, error)
+	GetTagsByUser(ctx context.Context, userID uuid.UUID) ([]domain.Tag, error)
+	GetTagByID(ctx context.Context, userID uuid.UUID, tagID uuid.UUID) (*domain.Tag, error)
+	GetTagByName(ctx context.Context, userID uuid.UUID, name string) (*domain.Tag, error)
+	UpdateTag(ctx context.Context, tag *domain.Tag) error
+	DeleteTag(ctx context.Context, userID uuid.UUID, tagID uuid.UUID) error
+	AddTagsToTransactions(ctx context.Context, userID uuid.UUID, transactionIDs []uuid.UUID, tagIDs []uuid.UUID) (int, error)
+	RemoveTagsFromTransactions(ctx context.Context, userID uuid.UUID, transactionIDs []uuid.UUID, tagIDs []uuid.UUID) (int, error)
+}
+
+type TagUseCase struct {
+	repo      TagRepository
+	txManager database.TxManager
+}
+
+func NewTagUseCase(repo TagRepository, txManager database.TxManager) *TagUseCase {
+	return &TagUseCase{repo: repo, txManager: txManager}
+}
+
+func (uc *TagUseCase) CreateTag(ctx context.Context, userID uuid.UUID, name string, color *string) (uuid.UUID, error) {
+	tag, err := domain.NewTag(userID, name, color)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	var tagID uuid.UUID
+	err = uc.txManager.RunInTransaction(ctx, func(ctx context.Context) error {
+		if err := uc.ensureNameFree(ctx, userID, tag.NameTag, uuid.Nil); err != nil {
+			return err
+		}
+		tagID, err = uc.repo.AddTag(ctx, tag)
+		return err
+	})
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return tagID, nil
+}
+
+func (uc *TagUseCase) GetTags(ctx context.Context, userID uuid.UUID) ([]domain.Tag, error) {
+	return uc.repo.GetTagsByUser(ctx, userID)
+}
+
+func (uc *TagUseCase) GetTag(ctx context.Context, userID uuid.UUID, tagID uuid.UUID) (*domain.Tag, error) {
+	return uc.repo.GetTagByID(ctx, userID, tagID)
+}
+
+func (uc *TagUseCase) UpdateTag(ctx context.Context, userID uuid.UUID, tagID uuid.UUID, name string, color *string) error {
+	updated, err := domain.NewTag(userID, name, color)
+	if err != nil {
+		return err
+	}
+
+	return uc.txManager.RunInTransaction(ctx, func(ctx context.Context) error {
+		current, err := uc.repo.GetTagByID(ctx, userID, tagID)
+		if err != nil {
+			return err
+		}
+		if err := uc.ensureNameFree(ctx, userID, updated.NameTag, tagID); err != nil {
+			return err
+		}
+		current.NameTag = updated.NameTag
+		current.Color = updated.Color
+		return uc.repo.UpdateTag(ctx, current)
+	})
+}
+
+func (uc *TagUseCase) DeleteTag(ctx context.Context, userID uuid.UUID, tagID uuid.UUID) error {
+	return uc.repo.DeleteTag(ctx, userID, tagID)
+}
+
+// BulkTag adds and removes tags on a set of transactions in one database
+// transaction. Transactions that do not belong to the user are ignored.
+func (uc *TagUseCase) BulkTag(ctx context.Context, userID uuid.UUID, transactionIDs []uuid.UUID, addTagIDs []uuid.UUID, removeTagIDs []uuid.UUID) (*domain.BulkTagResult, error) {
+	transactionIDs = uniqueIDs(transactionIDs)
+	addTagIDs = uniqueIDs(addTagIDs)
+	removeTagIDs = uniqueIDs(removeTagIDs)
+
+	if len(transactionIDs) == 0 {
+		return nil, domain.ErrBulkEmptyTransactions
+	}
+	if len(transactionIDs) > domain.MaxBulkTransactions {
+		return nil, domain.ErrBulkTooManyItems
+	}
+	if len(addTagIDs) == 0 && len(removeTagIDs) == 0 {
+		return nil, domain.ErrBulkEmptyTags
+	}
+
+	result := &domain.BulkTagResult{}
+	err := uc.txManager.RunInTransaction(ctx, func(ctx context.Context) error {
+		for _, tagID := range append(append([]uuid.UUID{}, addTagIDs...), removeTagIDs...) {
+			if _, err := uc.repo.GetTagByID(ctx, userID, tagID); err != nil {
+				return err
+			}
+		}
+
+		var err error
+		if len(removeTagIDs) > 0 {
+			result.Removed, err = uc.repo.RemoveTagsFromTransactions(ctx, userID, transactionIDs, removeTagIDs)
+			if err != nil {
+				return err
+			}
+		}
+		if len(addTagIDs) > 0 {
+			result.Added, err = uc.repo.AddTagsToTransactions(ctx, userID, transactionIDs, addTagIDs)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (uc *TagUseCase) ensureNameFree(ctx context.Context, userID uuid.UUID, name string, selfID uuid.UUID) error {
+	existing, err := uc.repo.GetTagByName(ctx, userID, name)
+	if err != nil {
+		if errors.Is(err, domain.ErrTagNotFound) {
+			return nil
+		}
+		return err
+	}
+	if existing.TagID != selfID {
+		return domain.ErrTagAlreadyExists
+	}
+	return nil
+}
+
+func uniqueIDs(ids []uuid.UUID) []uuid.UUID {
+	seen := make(map[uuid.UUID]struct{}, len(ids))
+	out := make([]uuid.UUID, 0, len(ids))
+	for _, id := range ids {
+		if id == uuid.Nil {
+			continue
+		}
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		out = append(out, id)
+	}
+	return out
+}
@@ -0,0 +1,158 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"Finance-Manager-System/internal/infrastructure/modules/tags/domain"
+)
+
+type tagLink struct {
+	transactionID uuid.UUID
+	tagID         uuid.UUID
+}
+
+type fakeTagRepo struct {
+	tags  map[uuid.UUID]*domain.Tag
+	links map[tagLink]bool
+}
+
+func newFakeTagRepo() *fakeTagRepo {
+	return &fakeTagRepo{tags: make(map[uuid.UUID]*domain.Tag), links: make(map[tagLink]bool)}
+}
+
+func (r *fakeTagRepo) AddTag(ctx context.Context, tag *domain.Tag) (uuid.UUID, error) {
+	tag.TagID = uuid.New()
+	r.tags[tag.TagID] = tag
+	return tag.TagID, nil
+}
+func (r *fakeTagRepo) GetTagsByUser(ctx context.Context, userID uuid.UUID) ([]domain.Tag, error) {
+	out := make([]domain.Tag, 0)
+	for _, tag := range r.tags {
+		if tag.UserID == userID {
+			out = append(out, *tag)
+		}
+	}
+	return out, nil
+}
+func (r *fakeTagRepo) GetTagByID(ctx context.Context, userID uuid.UUID, tagID uuid.UUID) (*domain.Tag, error) {
+	tag, ok := r.tags[tagID]
+	if !ok || tag.UserID != userID {
+		return nil, domain.ErrTagNotFound
+	}
+	copied := *tag
+	return &copied, nil
+}
+func (r *fakeTagRepo) GetTagByName(ctx context.Context, userID uuid.UUID, name string) (*domain.Tag, error) {
+	for _, tag := range r.tags {
+		if tag.UserID == userID && strings.EqualFold(tag.NameTag, name) {
+			copied := *tag
+			return &copied, nil
+		}
+	}
+	return nil, domain.ErrTagNotFound
+}
+func (r *fakeTagRepo) UpdateTag(ctx context.Context, tag *domain.Tag) error {
+	r.tags[tag.TagID] = tag
+	return nil
+}
+func (r *fakeTagRepo) DeleteTag(ctx context.Context, userID uuid.UUID, tagID uuid.UUID) error {
+	delete(r.tags, tagID)
+	return nil
+}
+func (r *fakeTagRepo) AddTagsToTransactions(ctx context.Context, userID uuid.UUID, transactionIDs []uuid.UUID, tagIDs []uuid.UUID) (int, error) {
+	added := 0
+	for _, txID := range transactionIDs {
+		for _, tagID := range tagIDs {
+			link := tagLink{txID, tagID}
+			if !r.links[link] {
+				r.links[link] = true
+				added++
+			}
+		}
+	}
+	return added, nil
+}
+func (r *fakeTagRepo) RemoveTagsFromTransactions(ctx context.Context, userID uuid.UUID, transactionIDs []uuid.UUID, tagIDs []uuid.UUID) (int, error) {
+	removed := 0
+	for _, txID := range transactionIDs {
+		for _, tagID := range tagIDs {
+			link := tagLink{txID, tagID}
+			if r.links[link] {
+				delete(r.links, link)
+				removed++
+			}
+		}
+	}
+	return removed, nil
+}
+
+type fakeTagTxManager struct{}
+
+func (m *fakeTagTxManager) RunInTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func TestCreateTagRejectsDuplicateName(t *testing.T) {
+	repo := newFakeTagRepo()
+	uc := NewTagUseCase(repo, &fakeTagTxManager{})
+	userID := uuid.New()
+
+	if _, err := uc.CreateTag(context.Background(), userID, "Travel", nil); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if _, err := uc.CreateTag(context.Background(), userID, "travel", nil); err != domain.ErrTagAlreadyExists {
+		t.Fatalf("expected ErrTagAlreadyExists, got %v", err)
+	}
+	if _, err := uc.CreateTag(context.Background(), uuid.New(), "travel", nil); err != nil {
+		t.Fatalf("another user may reuse the name, got %v", err)
+	}
+}
+
+func TestBulkTag(t *testing.T) {
+	repo := newFakeTagRepo()
+	uc := NewTagUseCase(repo, &fakeTagTxManager{})
+	ctx := context.Background()
+	userID := uuid.New()
+
+	travel, _ := uc.CreateTag(ctx, userID, "travel", nil)
+	work, _ := uc.CreateTag(ctx, userID, "work", nil)
+	foreign, _ := uc.CreateTag(ctx, uuid.New(), "foreign", nil)
+	tx1, tx2 := uuid.New(), uuid.New()
+
+	result, err := uc.BulkTag(ctx, userID, []uuid.UUID{tx1, tx2, tx1}, []uuid.UUID{travel, work}, nil)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if result.Added != 4 || result.Removed != 0 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+
+	result, err = uc.BulkTag(ctx, userID, []uuid.UUID{tx1}, []uuid.UUID{travel}, []uuid.UUID{work})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if result.Added != 0 || result.Removed != 1 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+
+	if _, err := uc.BulkTag(ctx, userID, []uuid.UUID{tx1}, []uuid.UUID{foreign}, nil); err != domain.ErrTagNotFound {
+		t.Fatalf("expected ErrTagNotFound for a foreign tag, got %v", err)
+	}
+	if _, err := uc.BulkTag(ctx, userID, nil, []uuid.UUID{travel}, nil); err != domain.ErrBulkEmptyTransactions {
+		t.Fatalf("expected ErrBulkEmptyTransactions, got %v", err)
+	}
+	if _, err := uc.BulkTag(ctx, userID, []uuid.UUID{tx1}, nil, nil); err != domain.ErrBulkEmptyTags {
+		t.Fatalf("expected ErrBulkEmptyTags, got %v", err)
+	}
+	tooMany := make([]uuid.UUID, domain.MaxBulkTransactions+1)
+	for i := range tooMany {
+		tooMany[i] = uuid.New()
+	}
+	if _, err := uc.BulkTag(ctx, userID, tooMany, []uuid.UUID{travel}, nil); err != domain.ErrBulkTooManyItems {
+		t.Fatalf("expected ErrBulkTooManyItems, got %v", err)
+	}
+}
@@ -13,6 +13,8 @@ import (
 	"time"
 
 	"github.com/ledongthuc/pdf"
+
+	"Finance-Manager-System/internal/infrastructure/modules/statement"
 )
 
 var (
@@ -20,70 +22,46 @@ var (
 	ErrStatementNotSupported = errors.New("unsupported statement format")
 )
 
-type Statement struct {
-	ContractNumber string
-	AccountNumber  string
-	Balance        int64
-	Transactions   []TransactionEntry
-}
-
-type TransactionEntry struct {
-	CompletedAt     time.Time
-	Amount          int64
-	IsIncome        bool
-	Description     string
-	CardNumber      string
-	BankFee         int64
-	SenderAccount   *string
-	ReceiverAccount *string
-	MCCCode         *string
-	ExternalID      *string
-}
-
 var (
 	reSpaces         = regexp.MustCompile(`\s+`)
 	reContractNumber = regexp.MustCompile(`Номер\s+договора:\s*([0-9]+)`)
 	reAccountNumber  = regexp.MustCompile(`Номер\s+лицевого\s+счета:\s*([0-9]+)`)
-	reBalance        = regexp.MustCompile(`Сумма\s+доступного\s+остатка\s+на\s+[0-9.]+:\s*([+\-]?[0-9\s]+[.,][0-9]{2})\s*₽`)
+	reBalance        = regexp.MustCompile(`Сумма\s+доступного\s+остатка\s+на\s+[0-9.]+:\s*([+\-]?[0-9\s]+[.,][0-9]{2})\s*(₽|\$|€|¥|RUB|USD|EUR|CNY)`)
 	reDate           = regexp.MustCompile(`^\d{2}\.\d{2}\.\d{4}$`)
 	reTime           = regexp.MustCompile(`^\d{2}:\d{2}$`)
 	reCard           = regexp.MustCompile(`^(—|\d{4})$`)
 	reMCC            = regexp.MustCompile(`(?i)\bmcc[:\s]*([0-9]{4})\b`)
 )
 
-func ParseStatement(data []byte) (*Statement, error) {
-	if len(data) == 0 {
-		return nil, ErrInvalidPDF
-	}
-
-	reader, err := pdf.NewReader(bytes.NewReader(data), int64(len(data)))
-	if err != nil {
-		return nil, ErrInvalidPDF
-	}
-
-	plainText, err := reader.GetPlainText()
-	if err != nil {
-		return nil, ErrInvalidPDF
-	}
+var currencySymbols = map[string]string{
+	"₽": "RUB",
+	"$": "USD",
+	"€": "EUR",
+	"¥": "CNY",
+}
 
-	raw, err := io.ReadAll(plainText)
+func ParseStatement(data []byte) (*statement.Statement, error) {
+	text, err := extractText(data)
 	if err != nil {
-		return nil, ErrInvalidPDF
+		return nil, err
 	}
-
-	text := strings.ReplaceAll(string(raw), "\u00a0", " ")
 	compact := strings.TrimSpace(reSpaces.ReplaceAllString(text, " "))
 	if compact == "" {
 		return nil, ErrStatementNotSupported
 	}
 
-	statement := &Statement{}
+	stmt := &statement.Statement{
+		Format:      FormatName,
+		BankName:    BankName,
+		AccountType: "imported_pdf",
+		ColorHex:    "#FFDD2D",
+	}
 
 	if m := reContractNumber.FindStringSubmatch(compact); len(m) > 1 {
-		statement.ContractNumber = strings.TrimSpace(m[1])
+		stmt.ContractNumber = strings.TrimSpace(m[1])
 	}
 	if m := reAccountNumber.FindStringSubmatch(compact); len(m) > 1 {
-		statement.AccountNumber = strings.TrimSpace(m[1])
+		stmt.AccountNumber = strings.TrimSpace(m[1])
 	}
 
 	balanceMatch := reBalance.FindStringSubmatch(compact)
@@ -94,21 +72,48 @@ func ParseStatement(data []byte) (*Statement, error) {
 	if err != nil {
 		return nil, ErrStatementNotSupported
 	}
-	statement.Balance = balance
+	stmt.Balance = balance
+	stmt.HasBalance = true
+	stmt.Currency = currencyCode(balanceMatch[2])
 
 	txs, txErr := parseTransactions(text)
 	if txErr != nil || len(txs) == 0 {
 		return nil, ErrStatementNotSupported
 	}
 
-	statement.Transactions = txs
-	return statement, nil
+	stmt.Transactions = txs
+	return stmt, nil
+}
+
+func extractText(data []byte) (string, error) {
+	if len(data) == 0 {
+		return "", ErrInvalidPDF
+	}
+
+	reader, err := pdf.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", ErrInvalidPDF
+	}
+
+	plainText, err := reader.GetPlainText()
+	if err != nil {
+		return "", ErrInvalidPDF
+	}
+
+	raw, err := io.ReadAll(plainText)
+	if err != nil {
+		return "", ErrInvalidPDF
+	}
+
+	return strings.ReplaceAll(string(raw), "\u00a0", " "), nil
 }
 
 func parseMoneyToMinor(input string) (int64, error) {
 	s := strings.TrimSpace(input)
 	s = strings.ReplaceAll(s, " ", "")
-	s = strings.ReplaceAll(s, "₽", "")
+	for symbol := range currencySymbols {
+		s = strings.ReplaceAll(s, symbol, "")
+	}
 	s = strings.ReplaceAll(s, ",", ".")
 	if s == "" {
 		return 0, errors.New("empty amount")
@@ -121,10 +126,10 @@ func parseMoneyToMinor(input string) (int64, error) {
 	return int64(math.Round(value * 100)), nil
 }
 
-func parseTransactions(raw string) ([]TransactionEntry, error) {
+func parseTransactions(raw string) ([]statement.TransactionEntry, error) {
 	lines := splitCleanLines(raw)
 	loc := time.FixedZone("MSK", 3*60*60)
-	transactions := make([]TransactionEntry, 0)
+	transactions := make([]statement.TransactionEntry, 0)
 
 	for i := 0; i < len(lines); i++ {
 		if i+5 >= len(lines) {
@@ -182,7 +187,7 @@ func parseTransactions(raw string) ([]TransactionEntry, error) {
 		}
 
 		externalID := buildExternalID(dt.UTC(), absAmount, isIncome, description, card)
-		transactions = append(transactions, TransactionEntry{
+		transactions = append(transactions, statement.TransactionEntry{
 			CompletedAt: dt.UTC(),
 			Amount:      absAmount,
 			IsIncome:    isIncome,
@@ -206,6 +211,13 @@ func parseTransactions(raw string) ([]TransactionEntry, error) {
 	return transactions, nil
 }
 
+func currencyCode(symbol string) string {
+	if code, ok := currencySymbols[symbol]; ok {
+		return code
+	}
+	return symbol
+}
+
 func extractMCC(description string) *string {
 	match := reMCC.FindStringSubmatch(description)
 	if len(match) < 2 {
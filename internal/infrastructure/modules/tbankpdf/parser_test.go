@@ -3,6 +3,8 @@ package tbankpdf
 import (
 	"testing"
 	"time"
+
+	"Finance-Manager-System/internal/infrastructure/modules/statement"
 )
 
 func TestParseMoneyToMinor(t *testing.T) {
@@ -31,3 +33,25 @@ func TestParseStatementInvalidData(t *testing.T) {
 	}
 }
 
+func TestParseMoneyToMinorForeignCurrency(t *testing.T) {
+	v, err := parseMoneyToMinor("-12,50 $")
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if v != -1250 {
+		t.Fatalf("unexpected value: %d", v)
+	}
+	if currencyCode("$") != "USD" || currencyCode("EUR") != "EUR" {
+		t.Fatalf("unexpected currency mapping")
+	}
+}
+
+func TestParserDetectRejectsNonPDF(t *testing.T) {
+	p := NewParser()
+	if p.Detect(statement.Source{Filename: "statement.csv", Data: []byte("date,amount\n")}) {
+		t.Fatalf("csv data must not be detected as tbank pdf")
+	}
+	if p.Name() != FormatName {
+		t.Fatalf("unexpected parser name: %s", p.Name())
+	}
+}
@@ -0,0 +1,42 @@
+package tbankpdf
+
+import (
+	"bytes"
+	"strings"
+
+	"Finance-Manager-System/internal/infrastructure/modules/statement"
+)
+
+const (
+	FormatName = "tbank_pdf"
+	BankName   = "Т-Банк"
+)
+
+var pdfMagic = []byte("%PDF-")
+
+type Parser struct{}
+
+func NewParser() *Parser {
+	return &Parser{}
+}
+
+func (p *Parser) Name() string {
+	return FormatName
+}
+
+// Detect accepts PDFs whose text carries the T-Bank available balance line.
+func (p *Parser) Detect(src statement.Source) bool {
+	if !bytes.HasPrefix(bytes.TrimLeft(src.Data, " \t\r\n"), pdfMagic) {
+		return false
+	}
+	text, err := extractText(src.Data)
+	if err != nil {
+		return false
+	}
+	compact := strings.TrimSpace(reSpaces.ReplaceAllString(text, " "))
+	return reBalance.MatchString(compact)
+}
+
+func (p *Parser) Parse(src statement.Source) (*statement.Statement, error) {
+	return ParseStatement(src.Data)
+}
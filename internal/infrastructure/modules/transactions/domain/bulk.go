@@ -0,0 +1,191 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+const MaxBulkItems = 1000
+
+type BulkOperation string
+
+const (
+	BulkRecategorize BulkOperation = "recategorize"
+	BulkSetComment   BulkOperation = "set_comment"
+	BulkAddTags      BulkOperation = "add_tags"
+	BulkRemoveTags   BulkOperation = "remove_tags"
+	// BulkMove moves manual transactions to another account of the same
+	// currency.
+	BulkMove   BulkOperation = "move"
+	BulkDelete BulkOperation = "delete"
+)
+
+var (
+	ErrInvalidBulkOperation = errors.New("operation must be one of recategorize, set_comment, add_tags, remove_tags, move, delete")
+	ErrBulkSelection        = errors.New("exactly one of transaction_ids or filter must be set")
+	ErrBulkTooManyItems     = errors.New("too many transactions in one bulk request")
+	ErrBulkCategoryRequired = errors.New("category_id is required to recategorize")
+	ErrBulkTagsRequired     = errors.New("tag_ids is required to add or remove tags")
+	ErrBulkAccountRequired  = errors.New("account_id is required to move transactions")
+	ErrBulkTagNotFound      = errors.New("tag not found")
+	ErrBulkAccountNotFound  = errors.New("target account not found")
+)
+
+// BulkParams selects transactions either by ID or by filter and describes
+// the one operation applied to all of them.
+type BulkParams struct {
+	Operation      BulkOperation
+	TransactionIDs []uuid.UUID
+	Filter         *TransactionFilter
+	CategoryID     *uuid.UUID
+	// Comment replaces the comment; nil clears it.
+	Comment   *string
+	TagIDs    []uuid.UUID
+	AccountID *uuid.UUID
+}
+
+func (p BulkParams) Validate() error {
+	switch p.Operation {
+	case BulkRecategorize:
+		if p.CategoryID == nil {
+			return ErrBulkCategoryRequired
+		}
+	case BulkAddTags, BulkRemoveTags:
+		if len(p.TagIDs) == 0 {
+			return ErrBulkTagsRequired
+		}
+	case BulkMove:
+		if p.AccountID == nil || *p.AccountID == uuid.Nil {
+			return ErrBulkAccountRequired
+		}
+	case BulkSetComment, BulkDelete:
+	default:
+		return ErrInvalidBulkOperation
+	}
+
+	if (len(p.TransactionIDs) == 0) == (p.Filter == nil) {
+		return ErrBulkSelection
+	}
+	if len(p.TransactionIDs) > MaxBulkItems {
+		return ErrBulkTooManyItems
+	}
+	if p.Filter != nil {
+		if p.Filter.MinAmount != nil && p.Filter.MaxAmount != nil && *p.Filter.MinAmount > *p.Filter.MaxAmount {
+			return ErrInvalidAmountRange
+		}
+		if p.Filter.Uncategorized && p.Filter.CategoryID != nil {
+			return ErrConflictingCategory
+		}
+	}
+	return nil
+}
+
+type BulkItemStatus string
+
+const (
+	BulkItemApplied BulkItemStatus = "applied"
+	BulkItemSkipped BulkItemStatus = "skipped"
+)
+
+// BulkSkipReason explains why an operation was not applied to a transaction.
+type BulkSkipReason string
+
+const (
+	SkipNotFound         BulkSkipReason = "not_found"
+	SkipTransfer         BulkSkipReason = "transfer"
+	SkipSplit            BulkSkipReason = "split"
+	SkipImported         BulkSkipReason = "imported"
+	SkipSameAccount      BulkSkipReason = "same_account"
+	SkipCurrencyMismatch BulkSkipReason = "currency_mismatch"
+	SkipAdjustment       BulkSkipReason = "adjustment"
+)
+
+type BulkItemResult struct {
+	TransactionID uuid.UUID      `json:"transaction_id"`
+	Status        BulkItemStatus `json:"status"`
+	Reason        BulkSkipReason `json:"reason,omitempty"`
+}
+
+type BulkResult struct {
+	Operation BulkOperation    `json:"operation"`
+	Applied   int              `json:"applied"`
+	Skipped   int              `json:"skipped"`
+	Items     []BulkItemResult `json:"items"`
+}
+
+func (r *BulkResult) apply(id uuid.UUID) {
+	r.Applied++
+	r.Items = append(r.Items, BulkItemResult{TransactionID: id, Status: BulkItemApplied})
+}
+
+func (r *BulkResult) skip(id uuid.UUID, reason BulkSkipReason) {
+	r.Skipped++
+	r.Items = append(r.Items, BulkItemResult{TransactionID: id, Status: BulkItemSkipped, Reason: reason})
+}
+
+// PlanBulk splits the selected transactions into the ones the operation
+// applies to and the skipped ones. requested lists the IDs asked for by the
+// caller, if any, so that missing ones are reported as not found. For a move,
+// currency is the target account currency.
+func PlanBulk(params BulkParams, requested []uuid.UUID, transactions []Transaction, split map[uuid.UUID]bool, currency string) ([]Transaction, *BulkResult) {
+	result := &BulkResult{Operation: params.Operation, Items: make([]BulkItemResult, 0, len(transactions))}
+	eligible := make([]Transaction, 0, len(transactions))
+
+	byID := make(map[uuid.UUID]Transaction, len(transactions))
+	order := make([]uuid.UUID, 0, len(transactions))
+	for _, t := range transactions {
+		byID[t.TransactionID] = t
+		order = append(order, t.TransactionID)
+	}
+	if requested != nil {
+		order = requested
+	}
+
+	for _, id := range order {
+		t, ok := byID[id]
+		if !ok {
+			result.skip(id, SkipNotFound)
+			continue
+		}
+		if reason := bulkSkipReason(params, t, split[id], currency); reason != "" {
+			result.skip(id, reason)
+			continue
+		}
+		result.apply(id)
+		eligible = append(eligible, t)
+	}
+	return eligible, result
+}
+
+func bulkSkipReason(params BulkParams, t Transaction, isSplit bool, currency string) BulkSkipReason {
+	switch params.Operation {
+	case BulkRecategorize:
+		if t.TransferID != nil {
+			return SkipTransfer
+		}
+		if isSplit {
+			return SkipSplit
+		}
+	case BulkMove, BulkDelete:
+		if t.TransferID != nil {
+			return SkipTransfer
+		}
+		if t.IsImported {
+			return SkipImported
+		}
+		if params.Operation == BulkMove {
+			if t.IsAdjustment {
+				return SkipAdjustment
+			}
+			if t.AccountID == *params.AccountID {
+				return SkipSameAccount
+			}
+			if !strings.EqualFold(t.Currency, currency) {
+				return SkipCurrencyMismatch
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,19 @@
+package domain
+
+import (
+	"strings"
+	"unicode"
+)
+
+// MerchantKey normalizes a transaction description so that charges of the
+// same merchant compare equal. It is the key of auto-category rules.
+func MerchantKey(s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	var b strings.Builder
+	for _, r := range s {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == ' ' || r == '.' || r == '*' {
+			b.WriteRune(r)
+		}
+	}
+	return strings.Join(strings.Fields(b.String()), " ")
+}
@@ -0,0 +1,59 @@
+package domain
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+// CategorySource records how a transaction got its category.
+type CategorySource string
+
+const (
+	// SourceUnknown marks transactions categorised before sources were
+	// recorded.
+	SourceUnknown CategorySource = ""
+	SourceManual  CategorySource = "manual"
+	// SourceRule is a user-defined categorisation rule.
+	SourceRule CategorySource = "rule"
+	// SourceLearned is a rule learned from the user's earlier corrections.
+	SourceLearned    CategorySource = "learned"
+	SourceClassifier CategorySource = "classifier"
+	// SourceMCC is the built-in MCC code map.
+	SourceMCC     CategorySource = "mcc"
+	SourceKeyword CategorySource = "keyword"
+	// SourceFallback is the "Другое" category or any category of the right
+	// type picked when nothing matched.
+	SourceFallback CategorySource = "fallback"
+)
+
+const (
+	// ReviewConfidence is the classifier confidence below which a predicted
+	// category still goes to the review inbox.
+	ReviewConfidence = 0.8
+	MaxReviewItems   = 1000
+)
+
+type ReviewAction string
+
+const (
+	// ReviewAccept confirms the current category, or that the transaction
+	// stays uncategorised.
+	ReviewAccept       ReviewAction = "accept"
+	ReviewRecategorize ReviewAction = "recategorize"
+)
+
+var (
+	ErrInvalidReviewAction     = errors.New("action must be accept or recategorize")
+	ErrReviewEmpty             = errors.New("transaction_ids cannot be empty")
+	ErrReviewTooManyItems      = errors.New("too many transactions in one review request")
+	ErrReviewCategoryRequired  = errors.New("category_id is required to recategorize")
+	ErrReviewCategoryForAccept = errors.New("category_id cannot be set when accepting")
+)
+
+type ReviewResult struct {
+	Updated int `json:"updated"`
+	// Skipped lists transactions that were not found or cannot be
+	// recategorised as a whole, such as transfers and split transactions.
+	Skipped []uuid.UUID `json:"skipped"`
+}
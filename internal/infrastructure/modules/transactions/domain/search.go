@@ -0,0 +1,113 @@
+package domain
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	DefaultSearchLimit = 50
+	MaxSearchLimit     = 200
+)
+
+var (
+	ErrInvalidSearchSort   = errors.New("sort must be one of date_desc, date_asc, amount_desc, amount_asc")
+	ErrInvalidSearchCursor = errors.New("invalid cursor")
+	ErrInvalidSearchLimit  = errors.New("limit must be between 1 and 200")
+	ErrInvalidAmountRange  = errors.New("min_amount cannot be greater than max_amount")
+	ErrConflictingCategory = errors.New("category_id cannot be combined with uncategorized")
+)
+
+type SearchSort string
+
+const (
+	SortDateDesc   SearchSort = "date_desc"
+	SortDateAsc    SearchSort = "date_asc"
+	SortAmountDesc SearchSort = "amount_desc"
+	SortAmountAsc  SearchSort = "amount_asc"
+)
+
+func ParseSearchSort(value string) (SearchSort, error) {
+	switch sort := SearchSort(strings.ToLower(strings.TrimSpace(value))); sort {
+	case "":
+		return SortDateDesc, nil
+	case SortDateDesc, SortDateAsc, SortAmountDesc, SortAmountAsc:
+		return sort, nil
+	default:
+		return "", ErrInvalidSearchSort
+	}
+}
+
+func (s SearchSort) ByAmount() bool {
+	return s == SortAmountDesc || s == SortAmountAsc
+}
+
+func (s SearchSort) Descending() bool {
+	return s == SortDateDesc || s == SortAmountDesc
+}
+
+// SearchCursor points at the last transaction of a page. Pages are keyed on
+// the sort column and transaction_id, so rows inserted between requests do
+// not shift the following pages.
+type SearchCursor struct {
+	Sort          SearchSort `json:"s"`
+	CompletedAt   time.Time  `json:"d"`
+	Amount        int64      `json:"a"`
+	TransactionID uuid.UUID  `json:"id"`
+}
+
+func NewSearchCursor(sort SearchSort, last Transaction) SearchCursor {
+	return SearchCursor{
+		Sort:          sort,
+		CompletedAt:   last.CompletedAt,
+		Amount:        last.Amount,
+		TransactionID: last.TransactionID,
+	}
+}
+
+func (c SearchCursor) Encode() string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// DecodeSearchCursor parses a cursor issued for the given sort.
+func DecodeSearchCursor(value string, sort SearchSort) (*SearchCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, ErrInvalidSearchCursor
+	}
+	var cursor SearchCursor
+	if err := json.Unmarshal(raw, &cursor); err != nil || cursor.TransactionID == uuid.Nil {
+		return nil, ErrInvalidSearchCursor
+	}
+	if cursor.Sort != sort {
+		return nil, ErrInvalidSearchCursor
+	}
+	return &cursor, nil
+}
+
+type TransactionSearch struct {
+	Filter TransactionFilter
+	Sort   SearchSort
+	Limit  int
+	Cursor *SearchCursor
+}
+
+// TransactionPage is one page of search results. Totals cover the whole
+// filtered set, not only the page, and are converted into the base currency.
+type TransactionPage struct {
+	Items        []Transaction `json:"items"`
+	NextCursor   *string       `json:"next_cursor"`
+	TotalCount   int64         `json:"total_count" db:"total_count"`
+	TotalIncome  int64         `json:"total_income" db:"total_income"`
+	TotalExpense int64         `json:"total_expense" db:"total_expense"`
+	TotalAmount  int64         `json:"total_amount" db:"-"`
+	// UnconvertedCurrencies had no rate to the base currency and were left
+	// out of the totals.
+	UnconvertedCurrencies []string `json:"unconverted_currencies,omitempty" db:"-"`
+}
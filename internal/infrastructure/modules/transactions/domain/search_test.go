@@ -0,0 +1,35 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestSearchCursorRoundTrip(t *testing.T) {
+	last := Transaction{
+		TransactionID: uuid.New(),
+		Amount:        12345,
+		CompletedAt:   time.Date(2026, 2, 3, 4, 5, 6, 0, time.UTC),
+	}
+	encoded := NewSearchCursor(SortAmountDesc, last).Encode()
+
+	cursor, err := DecodeSearchCursor(encoded, SortAmountDesc)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if cursor.TransactionID != last.TransactionID || cursor.Amount != last.Amount || !cursor.CompletedAt.Equal(last.CompletedAt) {
+		t.Fatalf("unexpected cursor: %+v", cursor)
+	}
+
+	if _, err := DecodeSearchCursor(encoded, SortDateDesc); err != ErrInvalidSearchCursor {
+		t.Fatalf("expected cursor of another sort to be rejected, got %v", err)
+	}
+	if _, err := DecodeSearchCursor("not a cursor", SortDateDesc); err != ErrInvalidSearchCursor {
+		t.Fatalf("expected ErrInvalidSearchCursor, got %v", err)
+	}
+	if _, err := ParseSearchSort("price"); err != ErrInvalidSearchSort {
+		t.Fatalf("expected ErrInvalidSearchSort, got %v", err)
+	}
+}
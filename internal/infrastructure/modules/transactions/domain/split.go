@@ -0,0 +1,72 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrSplitTooFewParts   = errors.New("split must have at least two parts")
+	ErrSplitInvalidAmount = errors.New("split part amount must be strictly greater than zero")
+	ErrSplitSumMismatch   = errors.New("split parts must sum up to the transaction amount")
+	ErrSplitTransfer      = errors.New("transfers cannot be split")
+	ErrTransactionIsSplit = errors.New("cannot change the amount of a split transaction; remove the split first")
+)
+
+// TransactionSplit is a part of a transaction with its own category. Parts
+// only matter for category analytics; the account balance follows the parent.
+type TransactionSplit struct {
+	SplitID       uuid.UUID  `db:"split_id" json:"split_id"`
+	TransactionID uuid.UUID  `db:"transaction_id" json:"transaction_id"`
+	UserID        uuid.UUID  `db:"user_id" json:"-"`
+	CategoryID    *uuid.UUID `db:"category_id" json:"category_id"`
+	Amount        int64      `db:"amount" json:"amount"`
+	Comment       *string    `db:"comment" json:"comment,omitempty"`
+	Position      int        `db:"position" json:"position"`
+	CreatedAt     time.Time  `db:"created_at" json:"created_at"`
+}
+
+// NewTransactionSplits validates the parts of parent and fills their keys.
+func NewTransactionSplits(parent *Transaction, parts []TransactionSplit) ([]TransactionSplit, error) {
+	if parent.TransferID != nil {
+		return nil, ErrSplitTransfer
+	}
+	if len(parts) < 2 {
+		return nil, ErrSplitTooFewParts
+	}
+
+	now := time.Now().UTC()
+	splits := make([]TransactionSplit, 0, len(parts))
+	total := int64(0)
+	for i, part := range parts {
+		if part.Amount <= 0 {
+			return nil, ErrSplitInvalidAmount
+		}
+		total += part.Amount
+
+		if part.Comment != nil {
+			comment := strings.TrimSpace(*part.Comment)
+			part.Comment = &comment
+			if comment == "" {
+				part.Comment = nil
+			}
+		}
+		splits = append(splits, TransactionSplit{
+			SplitID:       uuid.New(),
+			TransactionID: parent.TransactionID,
+			UserID:        parent.UserID,
+			CategoryID:    part.CategoryID,
+			Amount:        part.Amount,
+			Comment:       part.Comment,
+			Position:      i,
+			CreatedAt:     now,
+		})
+	}
+	if total != parent.Amount {
+		return nil, ErrSplitSumMismatch
+	}
+	return splits, nil
+}
@@ -15,27 +15,33 @@ var (
 	ErrTransInvalidAmount   = errors.New("amount must be strictly greater than zero")
 	ErrTransNotFound        = errors.New("transaction not found")
 	ErrCannotModifyImported = errors.New("cannot modify amount, date, or type of imported transactions")
+	ErrAdjustmentChange     = errors.New("cannot modify amount, date, or type of a balance adjustment")
 )
 
 type Transaction struct {
-	TransactionID         uuid.UUID  `db:"transaction_id" json:"transaction_id"`
-	UserID                uuid.UUID  `db:"user_id" json:"user_id"`
-	AccountID             uuid.UUID  `db:"account_id" json:"account_id"`
-	CategoryID            *uuid.UUID `db:"category_id" json:"category_id"`
-	NameTransaction       string     `db:"name_transaction" json:"name_transaction"`
-	IsIncome              bool       `db:"is_income" json:"is_income"`
-	Amount                int64      `db:"amount" json:"amount"`
-	CompletedAt           time.Time  `db:"completed_at" json:"completed_at"`
-	IsHidden              bool       `db:"is_hidden" json:"is_hidden"`
-	IsImported            bool       `db:"is_imported" json:"is_imported"`
-	Comment               *string    `db:"comment" json:"comment,omitempty"`
-	SenderAccount         *string    `db:"sender_account" json:"sender_account,omitempty"`
-	ReceiverAccount       *string    `db:"receiver_account" json:"receiver_account,omitempty"`
-	Currency              string     `db:"currency" json:"currency"`
-	BankFee               int64      `db:"bank_fee" json:"bank_fee"`
-	Status                string     `db:"status" json:"status"`
-	ExternalTransactionID *string    `db:"external_transaction_id" json:"external_transaction_id,omitempty"`
-	MCCCode               *string    `db:"mcc_code" json:"mcc_code,omitempty"`
+	TransactionID         uuid.UUID      `db:"transaction_id" json:"transaction_id"`
+	UserID                uuid.UUID      `db:"user_id" json:"user_id"`
+	AccountID             uuid.UUID      `db:"account_id" json:"account_id"`
+	CategoryID            *uuid.UUID     `db:"category_id" json:"category_id"`
+	NameTransaction       string         `db:"name_transaction" json:"name_transaction"`
+	IsIncome              bool           `db:"is_income" json:"is_income"`
+	Amount                int64          `db:"amount" json:"amount"`
+	CompletedAt           time.Time      `db:"completed_at" json:"completed_at"`
+	IsHidden              bool           `db:"is_hidden" json:"is_hidden"`
+	IsImported            bool           `db:"is_imported" json:"is_imported"`
+	Comment               *string        `db:"comment" json:"comment,omitempty"`
+	SenderAccount         *string        `db:"sender_account" json:"sender_account,omitempty"`
+	ReceiverAccount       *string        `db:"receiver_account" json:"receiver_account,omitempty"`
+	Currency              string         `db:"currency" json:"currency"`
+	BankFee               int64          `db:"bank_fee" json:"bank_fee"`
+	Status                string         `db:"status" json:"status"`
+	ExternalTransactionID *string        `db:"external_transaction_id" json:"external_transaction_id,omitempty"`
+	MCCCode               *string        `db:"mcc_code" json:"mcc_code,omitempty"`
+	TransferID            *uuid.UUID     `db:"transfer_id" json:"transfer_id,omitempty"`
+	ImportBatchID         *uuid.UUID     `db:"import_batch_id" json:"import_batch_id,omitempty"`
+	CategorySource        CategorySource `db:"category_source" json:"category_source,omitempty"`
+	CategoryConfidence    *float64       `db:"category_confidence" json:"category_confidence,omitempty"`
+	IsAdjustment          bool           `db:"is_adjustment" json:"is_adjustment"`
 }
 
 type TransactionFilter struct {
@@ -48,6 +54,18 @@ type TransactionFilter struct {
 	AccountIDs     []uuid.UUID
 	IncludeHidden  bool
 	HasIsHiddenSet bool
+	MinAmount      *int64
+	MaxAmount      *int64
+	Query          string
+	MCCCodes       []string
+	Statuses       []string
+	Currencies     []string
+	Uncategorized  bool
+	// TagIDs matches transactions carrying any of the tags.
+	TagIDs []uuid.UUID
+	// NeedsReview matches the review inbox: uncategorised transactions and
+	// categories picked by fallback or by an unsure classifier.
+	NeedsReview bool
 }
 
 func NewTransaction(
@@ -91,6 +109,11 @@ func NewTransaction(
 		}
 	}
 
+	var source CategorySource
+	if categoryID != nil {
+		source = SourceManual
+	}
+
 	return &Transaction{
 		TransactionID:         uuid.Nil,
 		UserID:                userID,
@@ -108,5 +131,6 @@ func NewTransaction(
 		Status:                "completed",
 		ExternalTransactionID: nil,
 		MCCCode:               nil,
+		CategorySource:        source,
 	}, nil
 }
@@ -30,3 +30,28 @@ func TestNewTransactionInvalidAmount(t *testing.T) {
 	}
 }
 
+func TestNewTransferRejectsSameAccount(t *testing.T) {
+	accountID := uuid.New()
+	_, err := NewTransfer(uuid.New(), accountID, accountID, 1000, 0, time.Now(), nil)
+	if err != ErrTransferSameAccount {
+		t.Fatalf("expected ErrTransferSameAccount, got %v", err)
+	}
+}
+
+func TestCheckTransferAmounts(t *testing.T) {
+	if err := CheckTransferAmounts("RUB", "RUB", 1000, 1200); err != ErrTransferAmountMismatch {
+		t.Fatalf("expected ErrTransferAmountMismatch, got %v", err)
+	}
+	if err := CheckTransferAmounts("RUB", "RUB", 1000, 0); err != nil {
+		t.Fatalf("an omitted to_amount should mean the same amount, got %v", err)
+	}
+	if err := CheckTransferAmounts("RUB", "RUB", 1000, 1000); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if err := CheckTransferAmounts("RUB", "USD", 90000, 0); err != ErrTransferToAmount {
+		t.Fatalf("expected ErrTransferToAmount, got %v", err)
+	}
+	if err := CheckTransferAmounts("RUB", "USD", 90000, 1000); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+}
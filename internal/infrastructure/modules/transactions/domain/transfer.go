@@ -0,0 +1,120 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const DefaultTransferName = "Перевод между счетами"
+
+var (
+	ErrTransferSameAccount    = errors.New("source and destination accounts must be different")
+	ErrTransferNotFound       = errors.New("transfer not found")
+	ErrTransferLegChange      = errors.New("cannot change type or category of a transfer leg")
+	ErrTransferToAmount       = errors.New("to_amount is required for transfers between accounts in different currencies")
+	ErrTransferAmountMismatch = errors.New("to_amount must equal amount for transfers between accounts in the same currency")
+)
+
+type Transfer struct {
+	TransferID    uuid.UUID `db:"transfer_id" json:"transfer_id"`
+	UserID        uuid.UUID `db:"user_id" json:"user_id"`
+	FromAccountID uuid.UUID `db:"from_account_id" json:"from_account_id"`
+	ToAccountID   uuid.UUID `db:"to_account_id" json:"to_account_id"`
+	Amount        int64     `db:"amount" json:"amount"`
+	ToAmount      int64     `db:"to_amount" json:"to_amount"`
+	CompletedAt   time.Time `db:"completed_at" json:"completed_at"`
+	Comment       *string   `db:"comment" json:"comment,omitempty"`
+	CreatedAt     time.Time `db:"created_at" json:"created_at"`
+}
+
+func NewTransfer(
+	userID uuid.UUID,
+	fromAccountID uuid.UUID,
+	toAccountID uuid.UUID,
+	amount int64,
+	toAmount int64,
+	completedAt time.Time,
+	comment *string,
+) (*Transfer, error) {
+	if userID == uuid.Nil {
+		return nil, ErrTransEmptyUserID
+	}
+	if fromAccountID == uuid.Nil || toAccountID == uuid.Nil {
+		return nil, ErrTransEmptyAccountID
+	}
+	if fromAccountID == toAccountID {
+		return nil, ErrTransferSameAccount
+	}
+	if amount <= 0 || toAmount < 0 {
+		return nil, ErrTransInvalidAmount
+	}
+	if toAmount == 0 {
+		toAmount = amount
+	}
+	if completedAt.IsZero() {
+		completedAt = time.Now().UTC()
+	}
+	if comment != nil {
+		cleanedComment := strings.TrimSpace(*comment)
+		if cleanedComment == "" {
+			comment = nil
+		} else {
+			comment = &cleanedComment
+		}
+	}
+
+	return &Transfer{
+		TransferID:    uuid.New(),
+		UserID:        userID,
+		FromAccountID: fromAccountID,
+		ToAccountID:   toAccountID,
+		Amount:        amount,
+		ToAmount:      toAmount,
+		CompletedAt:   completedAt,
+		Comment:       comment,
+		CreatedAt:     time.Now().UTC(),
+	}, nil
+}
+
+// CheckTransferAmounts keeps a transfer from creating or destroying money:
+// within one currency both legs carry the same amount, across currencies the
+// received amount has to be given. A zero toAmount means "same as amount".
+func CheckTransferAmounts(fromCurrency, toCurrency string, amount, toAmount int64) error {
+	if fromCurrency != toCurrency {
+		if toAmount == 0 {
+			return ErrTransferToAmount
+		}
+		return nil
+	}
+	if toAmount != 0 && toAmount != amount {
+		return ErrTransferAmountMismatch
+	}
+	return nil
+}
+
+// Legs builds the expense leg on the source account and the income leg on the
+// destination account. Both legs carry the transfer ID and stay uncategorized;
+// the income leg is booked with ToAmount in the destination account currency.
+func (t *Transfer) Legs(name string) (*Transaction, *Transaction, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = DefaultTransferName
+	}
+
+	outgoing, err := NewTransaction(t.UserID, t.FromAccountID, nil, name, false, t.Amount, t.CompletedAt, false, t.Comment)
+	if err != nil {
+		return nil, nil, err
+	}
+	incoming, err := NewTransaction(t.UserID, t.ToAccountID, nil, name, true, t.ToAmount, t.CompletedAt, false, t.Comment)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	transferID := t.TransferID
+	outgoing.TransferID = &transferID
+	incoming.TransferID = &transferID
+	return outgoing, incoming, nil
+}
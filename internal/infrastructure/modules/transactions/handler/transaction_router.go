@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -30,10 +31,21 @@ func (t *TransactionRouter) Route() chi.Router {
 
 	r.Post("/", t.CreateTransaction)
 	r.Get("/", t.GetTransactions)
+	r.Get("/search", t.SearchTransactions)
+	r.Get("/review", t.GetReviewQueue)
+	r.Post("/review", t.ResolveReview)
 	r.Put("/{id}", t.UpdateTransaction)
 	r.Patch("/{id}/imported", t.UpdateImportedTransactionMeta)
 	r.Delete("/{id}", t.DeleteTransaction)
+	r.Get("/{id}/splits", t.GetSplits)
+	r.Put("/{id}/splits", t.SplitTransaction)
+	r.Delete("/{id}/splits", t.RemoveSplits)
 	r.Patch("/visibility", t.ToggleVisibility)
+	r.Post("/bulk", t.BulkUpdate)
+	r.Post("/transfers", t.CreateTransfer)
+	r.Get("/transfers/{id}", t.GetTransfer)
+	r.Put("/transfers/{id}", t.UpdateTransfer)
+	r.Delete("/transfers/{id}", t.DeleteTransfer)
 
 	return r
 }
@@ -68,12 +80,105 @@ type ToggleVisibilityReq struct {
 	Hide           bool        `json:"hide"`
 }
 
+type ReviewReq struct {
+	TransactionIDs []uuid.UUID `json:"transaction_ids"`
+	Action         string      `json:"action" example:"recategorize"`
+	CategoryID     *uuid.UUID  `json:"category_id"`
+}
+
+type BulkReq struct {
+	Operation      string         `json:"operation" example:"recategorize"`
+	TransactionIDs []uuid.UUID    `json:"transaction_ids"`
+	Filter         *BulkFilterReq `json:"filter"`
+	CategoryID     *uuid.UUID     `json:"category_id"`
+	Comment        *string        `json:"comment"`
+	TagIDs         []uuid.UUID    `json:"tag_ids"`
+	AccountID      *uuid.UUID     `json:"account_id"`
+}
+
+// BulkFilterReq mirrors the query filters of GET /transactions.
+type BulkFilterReq struct {
+	AccountIDs    []uuid.UUID `json:"account_ids"`
+	CategoryID    *uuid.UUID  `json:"category_id"`
+	IsIncome      *bool       `json:"is_income"`
+	StartDate     *time.Time  `json:"start_date"`
+	EndDate       *time.Time  `json:"end_date"`
+	IncludeHidden bool        `json:"include_hidden"`
+	MinAmount     *int64      `json:"min_amount"`
+	MaxAmount     *int64      `json:"max_amount"`
+	Query         string      `json:"q"`
+	MCCCodes      []string    `json:"mcc"`
+	Statuses      []string    `json:"status"`
+	Currencies    []string    `json:"currency"`
+	Uncategorized bool        `json:"uncategorized"`
+	TagIDs        []uuid.UUID `json:"tag_ids"`
+}
+
+func (f *BulkFilterReq) toDomain() *domain.TransactionFilter {
+	if f == nil {
+		return nil
+	}
+	currencies := make([]string, 0, len(f.Currencies))
+	for _, currency := range f.Currencies {
+		currencies = append(currencies, strings.ToUpper(strings.TrimSpace(currency)))
+	}
+	return &domain.TransactionFilter{
+		AccountIDs:    f.AccountIDs,
+		CategoryID:    f.CategoryID,
+		IsIncome:      f.IsIncome,
+		StartDate:     f.StartDate,
+		EndDate:       f.EndDate,
+		IncludeHidden: f.IncludeHidden,
+		MinAmount:     f.MinAmount,
+		MaxAmount:     f.MaxAmount,
+		Query:         strings.TrimSpace(f.Query),
+		MCCCodes:      f.MCCCodes,
+		Statuses:      f.Statuses,
+		Currencies:    currencies,
+		Uncategorized: f.Uncategorized,
+		TagIDs:        f.TagIDs,
+	}
+}
+
 type UpdateImportedTransReq struct {
 	CategoryID *uuid.UUID `json:"category_id"`
 	Comment    *string    `json:"comment"`
 	IsHidden   *bool      `json:"is_hidden"`
 }
 
+type SplitPartReq struct {
+	CategoryID *uuid.UUID `json:"category_id"`
+	Amount     int64      `json:"amount"`
+	Comment    *string    `json:"comment"`
+}
+
+type SplitTransactionReq struct {
+	Parts []SplitPartReq `json:"parts"`
+}
+
+type CreateTransferReq struct {
+	FromAccountID uuid.UUID `json:"from_account_id"`
+	ToAccountID   uuid.UUID `json:"to_account_id"`
+	Name          string    `json:"name"`
+	Amount        int64     `json:"amount"`
+	ToAmount      int64     `json:"to_amount"`
+	CompletedAt   time.Time `json:"completed_at"`
+	Comment       *string   `json:"comment"`
+}
+
+type UpdateTransferReq struct {
+	Name        string    `json:"name"`
+	Amount      int64     `json:"amount"`
+	ToAmount    int64     `json:"to_amount"`
+	CompletedAt time.Time `json:"completed_at"`
+	Comment     *string   `json:"comment"`
+}
+
+type TransferResp struct {
+	Transfer *domain.Transfer     `json:"transfer"`
+	Legs     []domain.Transaction `json:"legs"`
+}
+
 // @Summary Создать транзакцию
 // @Tags transactions
 // @Security ApiKeyAuth
@@ -120,6 +225,14 @@ func (t *TransactionRouter) CreateTransaction(w http.ResponseWriter, r *http.Req
 // @Param start_date query string false "Начальная дата (RFC3339)"
 // @Param end_date query string false "Конечная дата (RFC3339)"
 // @Param is_hidden query boolean false "Показать скрытые"
+// @Param min_amount query integer false "Минимальная сумма (в копейках)"
+// @Param max_amount query integer false "Максимальная сумма (в копейках)"
+// @Param q query string false "Поиск по названию и комментарию"
+// @Param mcc query string false "CSV список MCC-кодов"
+// @Param status query string false "CSV список статусов"
+// @Param currency query string false "CSV список валют"
+// @Param uncategorized query boolean false "Только без категории"
+// @Param tag_ids query string false "CSV список tag_id (любой из тегов)"
 // @Success 200 {array} domain.Transaction
 // @Router /api/v1/transactions [get]
 func (t *TransactionRouter) GetTransactions(w http.ResponseWriter, r *http.Request) {
@@ -129,94 +242,164 @@ func (t *TransactionRouter) GetTransactions(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	var filter domain.TransactionFilter
+	filter, err := parseTransactionFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
-	if accID := r.URL.Query().Get("account_id"); accID != "" {
-		if id, err := uuid.Parse(accID); err == nil {
-			filter.AccountID = &id
-		}
+	transactions, err := t.transUC.GetUserTransactions(r.Context(), userID, filter)
+	if err != nil {
+		t.mapError(w, err)
+		return
 	}
-	if catID := r.URL.Query().Get("category_id"); catID != "" {
-		if id, err := uuid.Parse(catID); err == nil {
-			filter.CategoryID = &id
-		}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(transactions)
+}
+
+// @Summary Поиск транзакций с постраничной выдачей
+// @Description Принимает те же фильтры, что и GET /transactions. Итоги считаются по всей выборке в базовой валюте.
+// @Tags transactions
+// @Security ApiKeyAuth
+// @Produce json
+// @Param account_id query string false "ID счета"
+// @Param category_id query string false "ID категории"
+// @Param is_income query boolean false "Тип (доход/расход)"
+// @Param start_date query string false "Начальная дата (RFC3339)"
+// @Param end_date query string false "Конечная дата (RFC3339)"
+// @Param is_hidden query boolean false "Показать скрытые"
+// @Param min_amount query integer false "Минимальная сумма (в копейках)"
+// @Param max_amount query integer false "Максимальная сумма (в копейках)"
+// @Param q query string false "Поиск по названию и комментарию"
+// @Param mcc query string false "CSV список MCC-кодов"
+// @Param status query string false "CSV список статусов"
+// @Param currency query string false "CSV список валют"
+// @Param uncategorized query boolean false "Только без категории"
+// @Param tag_ids query string false "CSV список tag_id (любой из тегов)"
+// @Param sort query string false "date_desc (по умолчанию), date_asc, amount_desc, amount_asc"
+// @Param limit query integer false "Размер страницы (1..200, по умолчанию 50)"
+// @Param cursor query string false "Курсор следующей страницы из next_cursor"
+// @Success 200 {object} domain.TransactionPage
+// @Router /api/v1/transactions/search [get]
+func (t *TransactionRouter) SearchTransactions(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
 	}
-	if isInc := r.URL.Query().Get("is_income"); isInc != "" {
-		val := isInc == "true"
-		filter.IsIncome = &val
+
+	search, ok := t.parseSearch(w, r)
+	if !ok {
+		return
 	}
-	if isHid := r.URL.Query().Get("is_hidden"); isHid != "" {
-		val := isHid == "true"
-		filter.IsHidden = &val
-		filter.HasIsHiddenSet = true
-	} else {
-		filter.IncludeHidden = r.URL.Query().Get("include_hidden") == "true"
+
+	page, err := t.transUC.SearchTransactions(r.Context(), userID, search)
+	if err != nil {
+		t.mapError(w, err)
+		return
 	}
-	if start := r.URL.Query().Get("start_date"); start != "" {
-		if parsed, err := time.Parse(time.RFC3339, start); err == nil {
-			filter.StartDate = &parsed
-		}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(page)
+}
+
+// @Summary Транзакции на проверку категории
+// @Description Транзакции без категории, с категорией «Другое» по умолчанию или с неуверенным прогнозом классификатора. Принимает фильтры и пагинацию GET /transactions/search.
+// @Tags transactions
+// @Security ApiKeyAuth
+// @Produce json
+// @Param account_id query string false "ID счета"
+// @Param start_date query string false "Начальная дата (RFC3339)"
+// @Param end_date query string false "Конечная дата (RFC3339)"
+// @Param is_income query boolean false "Тип (доход/расход)"
+// @Param sort query string false "date_desc (по умолчанию), date_asc, amount_desc, amount_asc"
+// @Param limit query integer false "Размер страницы (1..200, по умолчанию 50)"
+// @Param cursor query string false "Курсор следующей страницы из next_cursor"
+// @Success 200 {object} domain.TransactionPage
+// @Router /api/v1/transactions/review [get]
+func (t *TransactionRouter) GetReviewQueue(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
 	}
-	if end := r.URL.Query().Get("end_date"); end != "" {
-		if parsed, err := time.Parse(time.RFC3339, end); err == nil {
-			filter.EndDate = &parsed
-		}
+
+	search, ok := t.parseSearch(w, r)
+	if !ok {
+		return
 	}
-	if accountIDsRaw := r.URL.Query().Get("account_ids"); accountIDsRaw != "" {
-		accountIDs := make([]uuid.UUID, 0)
-		for _, token := range strings.Split(accountIDsRaw, ",") {
-			token = strings.TrimSpace(token)
-			if token == "" {
-				continue
-			}
-			id, parseErr := uuid.Parse(token)
-			if parseErr != nil {
-				http.Error(w, "Invalid account_ids", http.StatusBadRequest)
-				return
-			}
-			accountIDs = append(accountIDs, id)
-		}
-		filter.AccountIDs = accountIDs
+
+	page, err := t.transUC.GetReviewQueue(r.Context(), userID, search)
+	if err != nil {
+		t.mapError(w, err)
+		return
 	}
-	if filter.StartDate == nil && filter.EndDate == nil {
-		period := strings.TrimSpace(strings.ToLower(r.URL.Query().Get("period")))
-		if period != "" {
-			now := time.Now().UTC()
-			switch period {
-			case "day":
-				start := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
-				end := start.AddDate(0, 0, 1).Add(-time.Nanosecond)
-				filter.StartDate = &start
-				filter.EndDate = &end
-			case "week":
-				weekday := int(now.Weekday())
-				if weekday == 0 {
-					weekday = 7
-				}
-				start := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, -(weekday - 1))
-				end := start.AddDate(0, 0, 7).Add(-time.Nanosecond)
-				filter.StartDate = &start
-				filter.EndDate = &end
-			case "month":
-				start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
-				end := start.AddDate(0, 1, 0).Add(-time.Nanosecond)
-				filter.StartDate = &start
-				filter.EndDate = &end
-			default:
-				http.Error(w, "Invalid period", http.StatusBadRequest)
-				return
-			}
-		}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(page)
+}
+
+// @Summary Подтвердить или изменить категории транзакций на проверке
+// @Description accept подтверждает текущие категории, recategorize назначает category_id. Для импортных транзакций выбор запоминается для следующих импортов.
+// @Tags transactions
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param request body ReviewReq true "IDs транзакций, действие и категория"
+// @Success 202 {object} domain.ReviewResult
+// @Router /api/v1/transactions/review [post]
+func (t *TransactionRouter) ResolveReview(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
 	}
 
-	transactions, err := t.transUC.GetUserTransactions(r.Context(), userID, filter)
+	var req ReviewReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid json", http.StatusBadRequest)
+		return
+	}
+
+	result, err := t.transUC.ResolveReview(r.Context(), userID, req.TransactionIDs, domain.ReviewAction(req.Action), req.CategoryID)
 	if err != nil {
 		t.mapError(w, err)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(transactions)
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(result)
+}
+
+// parseSearch reads the filter, sort and paging parameters. On failure it
+// writes the error response and returns false.
+func (t *TransactionRouter) parseSearch(w http.ResponseWriter, r *http.Request) (domain.TransactionSearch, bool) {
+	filter, err := parseTransactionFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return domain.TransactionSearch{}, false
+	}
+
+	search := domain.TransactionSearch{Filter: filter}
+	if search.Sort, err = domain.ParseSearchSort(r.URL.Query().Get("sort")); err != nil {
+		t.mapError(w, err)
+		return domain.TransactionSearch{}, false
+	}
+	if limit := r.URL.Query().Get("limit"); limit != "" {
+		if search.Limit, err = strconv.Atoi(limit); err != nil {
+			http.Error(w, "limit must be an integer", http.StatusBadRequest)
+			return domain.TransactionSearch{}, false
+		}
+	}
+	if cursor := r.URL.Query().Get("cursor"); cursor != "" {
+		if search.Cursor, err = domain.DecodeSearchCursor(cursor, search.Sort); err != nil {
+			t.mapError(w, err)
+			return domain.TransactionSearch{}, false
+		}
+	}
+	return search, true
 }
 
 // @Summary Обновить транзакцию
@@ -325,6 +508,47 @@ func (t *TransactionRouter) ToggleVisibility(w http.ResponseWriter, r *http.Requ
 	json.NewEncoder(w).Encode(map[string]interface{}{"status": "success"})
 }
 
+// @Summary Массовая операция над транзакциями
+// @Description Выбор по transaction_ids или по filter (не более 1000 транзакций). Операции: recategorize, set_comment, add_tags, remove_tags, move и delete (только ручные транзакции). Всё выполняется в одной транзакции БД, балансы счетов пересчитываются; неподходящие транзакции пропускаются с указанием причины.
+// @Tags transactions
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param request body BulkReq true "Операция, выборка и её параметры"
+// @Success 202 {object} domain.BulkResult
+// @Router /api/v1/transactions/bulk [post]
+func (t *TransactionRouter) BulkUpdate(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req BulkReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid json", http.StatusBadRequest)
+		return
+	}
+
+	result, err := t.transUC.BulkUpdate(r.Context(), userID, domain.BulkParams{
+		Operation:      domain.BulkOperation(req.Operation),
+		TransactionIDs: req.TransactionIDs,
+		Filter:         req.Filter.toDomain(),
+		CategoryID:     req.CategoryID,
+		Comment:        req.Comment,
+		TagIDs:         req.TagIDs,
+		AccountID:      req.AccountID,
+	})
+	if err != nil {
+		t.mapError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(result)
+}
+
 // @Summary Обновить импортную транзакцию (категория, комментарий, скрытность)
 // @Tags transactions
 // @Security ApiKeyAuth
@@ -371,16 +595,409 @@ func (t *TransactionRouter) UpdateImportedTransactionMeta(w http.ResponseWriter,
 	json.NewEncoder(w).Encode(map[string]interface{}{"status": "success"})
 }
 
+// @Summary Перевод между своими счетами
+// @Tags transactions
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param request body CreateTransferReq true "Данные перевода"
+// @Success 202 {object} map[string]interface{}
+// @Router /api/v1/transactions/transfers [post]
+func (t *TransactionRouter) CreateTransfer(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req CreateTransferReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid json", http.StatusBadRequest)
+		return
+	}
+
+	transfer, err := t.transUC.CreateTransfer(
+		r.Context(), userID, req.FromAccountID, req.ToAccountID,
+		req.Name, req.Amount, req.ToAmount, req.CompletedAt, req.Comment,
+	)
+	if err != nil {
+		t.mapError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]interface{}{"status": "success", "transfer": transfer})
+}
+
+// @Summary Получить перевод вместе с обеими проводками
+// @Tags transactions
+// @Security ApiKeyAuth
+// @Produce json
+// @Param id path string true "ID перевода"
+// @Success 200 {object} TransferResp
+// @Router /api/v1/transactions/transfers/{id} [get]
+func (t *TransactionRouter) GetTransfer(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	transferID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid transfer ID", http.StatusBadRequest)
+		return
+	}
+
+	transfer, legs, err := t.transUC.GetTransfer(r.Context(), userID, transferID)
+	if err != nil {
+		t.mapError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(TransferResp{Transfer: transfer, Legs: legs})
+}
+
+// @Summary Обновить перевод (обе проводки и балансы)
+// @Tags transactions
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "ID перевода"
+// @Param request body UpdateTransferReq true "Данные для обновления"
+// @Success 202 {object} map[string]interface{}
+// @Router /api/v1/transactions/transfers/{id} [put]
+func (t *TransactionRouter) UpdateTransfer(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	transferID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid transfer ID", http.StatusBadRequest)
+		return
+	}
+
+	var req UpdateTransferReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid json", http.StatusBadRequest)
+		return
+	}
+
+	err = t.transUC.UpdateTransfer(r.Context(), userID, transferID, req.Name, req.Amount, req.ToAmount, req.CompletedAt, req.Comment)
+	if err != nil {
+		t.mapError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]interface{}{"status": "success"})
+}
+
+// @Summary Удалить перевод (обе проводки)
+// @Tags transactions
+// @Security ApiKeyAuth
+// @Produce json
+// @Param id path string true "ID перевода"
+// @Success 202 {object} map[string]interface{}
+// @Router /api/v1/transactions/transfers/{id} [delete]
+func (t *TransactionRouter) DeleteTransfer(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	transferID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid transfer ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := t.transUC.DeleteTransfer(r.Context(), userID, transferID); err != nil {
+		t.mapError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]interface{}{"status": "success"})
+}
+
+// parseTransactionFilter reads the filter query parameters shared by the list
+// and search endpoints.
+func parseTransactionFilter(r *http.Request) (domain.TransactionFilter, error) {
+	var filter domain.TransactionFilter
+
+	if accID := r.URL.Query().Get("account_id"); accID != "" {
+		if id, err := uuid.Parse(accID); err == nil {
+			filter.AccountID = &id
+		}
+	}
+	if catID := r.URL.Query().Get("category_id"); catID != "" {
+		if id, err := uuid.Parse(catID); err == nil {
+			filter.CategoryID = &id
+		}
+	}
+	if isInc := r.URL.Query().Get("is_income"); isInc != "" {
+		val := isInc == "true"
+		filter.IsIncome = &val
+	}
+	if isHid := r.URL.Query().Get("is_hidden"); isHid != "" {
+		val := isHid == "true"
+		filter.IsHidden = &val
+		filter.HasIsHiddenSet = true
+	} else {
+		filter.IncludeHidden = r.URL.Query().Get("include_hidden") == "true"
+	}
+	if start := r.URL.Query().Get("start_date"); start != "" {
+		if parsed, err := time.Parse(time.RFC3339, start); err == nil {
+			filter.StartDate = &parsed
+		}
+	}
+	if end := r.URL.Query().Get("end_date"); end != "" {
+		if parsed, err := time.Parse(time.RFC3339, end); err == nil {
+			filter.EndDate = &parsed
+		}
+	}
+	if accountIDsRaw := r.URL.Query().Get("account_ids"); accountIDsRaw != "" {
+		accountIDs := make([]uuid.UUID, 0)
+		for _, token := range strings.Split(accountIDsRaw, ",") {
+			token = strings.TrimSpace(token)
+			if token == "" {
+				continue
+			}
+			id, parseErr := uuid.Parse(token)
+			if parseErr != nil {
+				return filter, errors.New("Invalid account_ids")
+			}
+			accountIDs = append(accountIDs, id)
+		}
+		filter.AccountIDs = accountIDs
+	}
+	for _, token := range splitCSV(r.URL.Query().Get("tag_ids")) {
+		id, parseErr := uuid.Parse(token)
+		if parseErr != nil {
+			return filter, errors.New("Invalid tag_ids")
+		}
+		filter.TagIDs = append(filter.TagIDs, id)
+	}
+	if filter.StartDate == nil && filter.EndDate == nil {
+		period := strings.TrimSpace(strings.ToLower(r.URL.Query().Get("period")))
+		if period != "" {
+			now := time.Now().UTC()
+			switch period {
+			case "day":
+				start := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+				end := start.AddDate(0, 0, 1).Add(-time.Nanosecond)
+				filter.StartDate = &start
+				filter.EndDate = &end
+			case "week":
+				weekday := int(now.Weekday())
+				if weekday == 0 {
+					weekday = 7
+				}
+				start := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, -(weekday - 1))
+				end := start.AddDate(0, 0, 7).Add(-time.Nanosecond)
+				filter.StartDate = &start
+				filter.EndDate = &end
+			case "month":
+				start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+				end := start.AddDate(0, 1, 0).Add(-time.Nanosecond)
+				filter.StartDate = &start
+				filter.EndDate = &end
+			default:
+				return filter, errors.New("Invalid period")
+			}
+		}
+	}
+
+	if minAmount := r.URL.Query().Get("min_amount"); minAmount != "" {
+		value, err := strconv.ParseInt(minAmount, 10, 64)
+		if err != nil {
+			return filter, errors.New("min_amount must be an integer")
+		}
+		filter.MinAmount = &value
+	}
+	if maxAmount := r.URL.Query().Get("max_amount"); maxAmount != "" {
+		value, err := strconv.ParseInt(maxAmount, 10, 64)
+		if err != nil {
+			return filter, errors.New("max_amount must be an integer")
+		}
+		filter.MaxAmount = &value
+	}
+	filter.Query = r.URL.Query().Get("q")
+	filter.MCCCodes = splitCSV(r.URL.Query().Get("mcc"))
+	filter.Statuses = splitCSV(r.URL.Query().Get("status"))
+	filter.Currencies = splitCSV(r.URL.Query().Get("currency"))
+	filter.Uncategorized = r.URL.Query().Get("uncategorized") == "true"
+
+	return filter, nil
+}
+
+func splitCSV(raw string) []string {
+	values := make([]string, 0)
+	for _, token := range strings.Split(raw, ",") {
+		if token = strings.TrimSpace(token); token != "" {
+			values = append(values, token)
+		}
+	}
+	if len(values) == 0 {
+		return nil
+	}
+	return values
+}
+
+// @Summary Получить части разделённой транзакции
+// @Tags transactions
+// @Security ApiKeyAuth
+// @Produce json
+// @Param id path string true "ID транзакции"
+// @Success 200 {array} domain.TransactionSplit
+// @Router /api/v1/transactions/{id}/splits [get]
+func (t *TransactionRouter) GetSplits(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	transID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid transaction ID", http.StatusBadRequest)
+		return
+	}
+
+	splits, err := t.transUC.GetSplits(r.Context(), userID, transID)
+	if err != nil {
+		t.mapError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(splits)
+}
+
+// @Summary Разделить транзакцию по категориям
+// @Description Заменяет части транзакции. Сумма частей должна совпадать с суммой транзакции; баланс счёта не меняется.
+// @Tags transactions
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "ID транзакции"
+// @Param request body SplitTransactionReq true "Части транзакции"
+// @Success 202 {object} map[string]interface{}
+// @Router /api/v1/transactions/{id}/splits [put]
+func (t *TransactionRouter) SplitTransaction(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	transID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid transaction ID", http.StatusBadRequest)
+		return
+	}
+
+	var req SplitTransactionReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid json", http.StatusBadRequest)
+		return
+	}
+
+	parts := make([]domain.TransactionSplit, 0, len(req.Parts))
+	for _, part := range req.Parts {
+		parts = append(parts, domain.TransactionSplit{CategoryID: part.CategoryID, Amount: part.Amount, Comment: part.Comment})
+	}
+
+	splits, err := t.transUC.SplitTransaction(r.Context(), userID, transID, parts)
+	if err != nil {
+		t.mapError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]interface{}{"status": "success", "splits": splits})
+}
+
+// @Summary Отменить разделение транзакции
+// @Tags transactions
+// @Security ApiKeyAuth
+// @Produce json
+// @Param id path string true "ID транзакции"
+// @Success 202 {object} map[string]interface{}
+// @Router /api/v1/transactions/{id}/splits [delete]
+func (t *TransactionRouter) RemoveSplits(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	transID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid transaction ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := t.transUC.RemoveSplits(r.Context(), userID, transID); err != nil {
+		t.mapError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]interface{}{"status": "success"})
+}
+
 func (t *TransactionRouter) mapError(w http.ResponseWriter, err error) {
 	switch {
-	case errors.Is(err, domain.ErrTransNotFound):
+	case errors.Is(err, domain.ErrTransNotFound),
+		errors.Is(err, domain.ErrTransferNotFound),
+		errors.Is(err, domain.ErrBulkTagNotFound),
+		errors.Is(err, domain.ErrBulkAccountNotFound):
 		http.Error(w, err.Error(), http.StatusNotFound)
 	case errors.Is(err, domain.ErrCannotModifyImported):
 		http.Error(w, err.Error(), http.StatusForbidden)
 	case errors.Is(err, domain.ErrTransInvalidAmount),
 		errors.Is(err, domain.ErrTransEmptyName),
-		errors.Is(err, domain.ErrTransEmptyAccountID):
+		errors.Is(err, domain.ErrTransEmptyAccountID),
+		errors.Is(err, domain.ErrTransferSameAccount),
+		errors.Is(err, domain.ErrTransferLegChange),
+		errors.Is(err, domain.ErrTransferToAmount),
+		errors.Is(err, domain.ErrTransferAmountMismatch),
+		errors.Is(err, domain.ErrInvalidSearchSort),
+		errors.Is(err, domain.ErrInvalidSearchCursor),
+		errors.Is(err, domain.ErrInvalidSearchLimit),
+		errors.Is(err, domain.ErrInvalidAmountRange),
+		errors.Is(err, domain.ErrConflictingCategory),
+		errors.Is(err, domain.ErrSplitTooFewParts),
+		errors.Is(err, domain.ErrSplitInvalidAmount),
+		errors.Is(err, domain.ErrSplitSumMismatch),
+		errors.Is(err, domain.ErrSplitTransfer),
+		errors.Is(err, domain.ErrInvalidReviewAction),
+		errors.Is(err, domain.ErrReviewEmpty),
+		errors.Is(err, domain.ErrReviewTooManyItems),
+		errors.Is(err, domain.ErrReviewCategoryRequired),
+		errors.Is(err, domain.ErrReviewCategoryForAccept),
+		errors.Is(err, domain.ErrInvalidBulkOperation),
+		errors.Is(err, domain.ErrBulkSelection),
+		errors.Is(err, domain.ErrBulkTooManyItems),
+		errors.Is(err, domain.ErrBulkCategoryRequired),
+		errors.Is(err, domain.ErrBulkTagsRequired),
+		errors.Is(err, domain.ErrBulkAccountRequired):
 		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, domain.ErrTransactionIsSplit),
+		errors.Is(err, domain.ErrAdjustmentChange):
+		http.Error(w, err.Error(), http.StatusConflict)
 	default:
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 	}
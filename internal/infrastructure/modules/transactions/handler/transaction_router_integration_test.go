@@ -17,11 +17,15 @@ import (
 )
 
 type integrationTransRepo struct {
-	items map[uuid.UUID]*transactionDomain.Transaction
+	items     map[uuid.UUID]*transactionDomain.Transaction
+	transfers map[uuid.UUID]*transactionDomain.Transfer
 }
 
 func newIntegrationTransRepo() *integrationTransRepo {
-	return &integrationTransRepo{items: make(map[uuid.UUID]*transactionDomain.Transaction)}
+	return &integrationTransRepo{
+		items:     make(map[uuid.UUID]*transactionDomain.Transaction),
+		transfers: make(map[uuid.UUID]*transactionDomain.Transfer),
+	}
 }
 
 func (r *integrationTransRepo) GetTransaction(ctx context.Context, userID uuid.UUID, transactionID uuid.UUID) (*transactionDomain.Transaction, error) {
@@ -65,6 +69,19 @@ func (r *integrationTransRepo) GetTransactionsWithFilter(ctx context.Context, us
 	}
 	return out, nil
 }
+func (r *integrationTransRepo) SearchTransactions(ctx context.Context, userID uuid.UUID, search transactionDomain.TransactionSearch) (*transactionDomain.TransactionPage, error) {
+	items, _ := r.GetTransactionsWithFilter(ctx, userID, search.Filter)
+	return &transactionDomain.TransactionPage{Items: items, TotalCount: int64(len(items))}, nil
+}
+func (r *integrationTransRepo) GetSplits(ctx context.Context, userID uuid.UUID, transactionID uuid.UUID) ([]transactionDomain.TransactionSplit, error) {
+	return nil, nil
+}
+func (r *integrationTransRepo) ReplaceSplits(ctx context.Context, userID uuid.UUID, transactionID uuid.UUID, splits []transactionDomain.TransactionSplit) error {
+	return nil
+}
+func (r *integrationTransRepo) DeleteSplits(ctx context.Context, userID uuid.UUID, transactionID uuid.UUID) error {
+	return nil
+}
 func (r *integrationTransRepo) ShowTransactions(ctx context.Context, userID uuid.UUID, transactionIds []uuid.UUID) error {
 	for _, id := range transactionIds {
 		if tx, ok := r.items[id]; ok {
@@ -96,6 +113,86 @@ func (r *integrationTransRepo) ResolveAutoCategoryID(ctx context.Context, userID
 func (r *integrationTransRepo) UpsertAutoCategoryRule(ctx context.Context, userID uuid.UUID, isIncome bool, mccCode *string, description string, categoryID uuid.UUID) error {
 	return nil
 }
+func (r *integrationTransRepo) SetReviewedCategory(ctx context.Context, userID uuid.UUID, transactionIDs []uuid.UUID, categoryID uuid.UUID) error {
+	for _, id := range transactionIDs {
+		if tx, ok := r.items[id]; ok {
+			category := categoryID
+			tx.CategoryID = &category
+			tx.CategorySource = transactionDomain.SourceManual
+		}
+	}
+	return nil
+}
+func (r *integrationTransRepo) AcceptCategories(ctx context.Context, userID uuid.UUID, transactionIDs []uuid.UUID) error {
+	for _, id := range transactionIDs {
+		if tx, ok := r.items[id]; ok {
+			tx.CategorySource = transactionDomain.SourceManual
+		}
+	}
+	return nil
+}
+func (r *integrationTransRepo) SetTransactionsComment(ctx context.Context, userID uuid.UUID, transactionIDs []uuid.UUID, comment *string) error {
+	for _, id := range transactionIDs {
+		if tx, ok := r.items[id]; ok {
+			tx.Comment = comment
+		}
+	}
+	return nil
+}
+func (r *integrationTransRepo) MoveTransactions(ctx context.Context, userID uuid.UUID, transactionIDs []uuid.UUID, accountID uuid.UUID) error {
+	for _, id := range transactionIDs {
+		if tx, ok := r.items[id]; ok {
+			tx.AccountID = accountID
+		}
+	}
+	return nil
+}
+func (r *integrationTransRepo) DeleteTransactions(ctx context.Context, userID uuid.UUID, transactionIDs []uuid.UUID) error {
+	for _, id := range transactionIDs {
+		delete(r.items, id)
+	}
+	return nil
+}
+func (r *integrationTransRepo) GetSplitTransactionIDs(ctx context.Context, userID uuid.UUID, transactionIDs []uuid.UUID) (map[uuid.UUID]bool, error) {
+	return map[uuid.UUID]bool{}, nil
+}
+
+func (r *integrationTransRepo) AddTransfer(ctx context.Context, transfer *transactionDomain.Transfer) error {
+	r.transfers[transfer.TransferID] = transfer
+	return nil
+}
+func (r *integrationTransRepo) GetTransfer(ctx context.Context, userID uuid.UUID, transferID uuid.UUID) (*transactionDomain.Transfer, error) {
+	transfer, ok := r.transfers[transferID]
+	if !ok || transfer.UserID != userID {
+		return nil, transactionDomain.ErrTransferNotFound
+	}
+	return transfer, nil
+}
+func (r *integrationTransRepo) UpdateTransfer(ctx context.Context, transfer *transactionDomain.Transfer) error {
+	r.transfers[transfer.TransferID] = transfer
+	return nil
+}
+func (r *integrationTransRepo) DeleteTransfer(ctx context.Context, userID uuid.UUID, transferID uuid.UUID) error {
+	if _, ok := r.transfers[transferID]; !ok {
+		return transactionDomain.ErrTransferNotFound
+	}
+	delete(r.transfers, transferID)
+	for id, tx := range r.items {
+		if tx.TransferID != nil && *tx.TransferID == transferID {
+			delete(r.items, id)
+		}
+	}
+	return nil
+}
+func (r *integrationTransRepo) GetTransferLegs(ctx context.Context, userID uuid.UUID, transferID uuid.UUID) ([]transactionDomain.Transaction, error) {
+	legs := make([]transactionDomain.Transaction, 0, 2)
+	for _, tx := range r.items {
+		if tx.TransferID != nil && *tx.TransferID == transferID {
+			legs = append(legs, *tx)
+		}
+	}
+	return legs, nil
+}
 
 type integrationBalanceRepo struct{}
 
@@ -103,6 +200,10 @@ func (r *integrationBalanceRepo) UpdateBalance(ctx context.Context, userID uuid.
 	return nil
 }
 
+func (r *integrationBalanceRepo) GetAccountCurrency(ctx context.Context, userID uuid.UUID, accountID uuid.UUID) (string, error) {
+	return "RUB", nil
+}
+
 type integrationTxManager struct{}
 
 func (m *integrationTxManager) RunInTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
@@ -116,7 +217,7 @@ func withUser(req *http.Request, userID uuid.UUID) *http.Request {
 
 func TestTransactionRouterCreateAndGet(t *testing.T) {
 	repo := newIntegrationTransRepo()
-	uc := transactionUsecase.NewTransactionUseCase(repo, &integrationBalanceRepo{}, &integrationTxManager{})
+	uc := transactionUsecase.NewTransactionUseCase(repo, &integrationBalanceRepo{}, nil, &integrationTxManager{})
 	router := NewTransactionRouter(uc).Route()
 	userID := uuid.New()
 	accountID := uuid.New()
@@ -150,7 +251,7 @@ func TestTransactionRouterCreateAndGet(t *testing.T) {
 
 func TestTransactionRouterPatchImported(t *testing.T) {
 	repo := newIntegrationTransRepo()
-	uc := transactionUsecase.NewTransactionUseCase(repo, &integrationBalanceRepo{}, &integrationTxManager{})
+	uc := transactionUsecase.NewTransactionUseCase(repo, &integrationBalanceRepo{}, nil, &integrationTxManager{})
 	router := NewTransactionRouter(uc).Route()
 	userID := uuid.New()
 	accountID := uuid.New()
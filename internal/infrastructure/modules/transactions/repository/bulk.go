@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"Finance-Manager-System/internal/infrastructure/database"
+)
+
+func (tr *TransRepository) SetTransactionsComment(ctx context.Context, userID uuid.UUID, transactionIDs []uuid.UUID, comment *string) error {
+	q := database.GetQueryer(ctx, tr.db)
+	query, args, err := sqlx.In(
+		`UPDATE Transactions SET comment = ? WHERE user_id = ? AND transaction_id IN (?)`,
+		comment, userID, transactionIDs,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to build IN query: %w", err)
+	}
+	if _, err := q.ExecContext(ctx, q.Rebind(query), args...); err != nil {
+		return fmt.Errorf("failed to set comment: %w", err)
+	}
+	return nil
+}
+
+func (tr *TransRepository) MoveTransactions(ctx context.Context, userID uuid.UUID, transactionIDs []uuid.UUID, accountID uuid.UUID) error {
+	q := database.GetQueryer(ctx, tr.db)
+	query, args, err := sqlx.In(
+		`UPDATE Transactions SET account_id = ? WHERE user_id = ? AND transaction_id IN (?)`,
+		accountID, userID, transactionIDs,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to build IN query: %w", err)
+	}
+	if _, err := q.ExecContext(ctx, q.Rebind(query), args...); err != nil {
+		return fmt.Errorf("failed to move transactions: %w", err)
+	}
+	return nil
+}
+
+// DeleteTransactions removes the transactions together with their splits and
+// tag links.
+func (tr *TransRepository) DeleteTransactions(ctx context.Context, userID uuid.UUID, transactionIDs []uuid.UUID) error {
+	q := database.GetQueryer(ctx, tr.db)
+	query, args, err := sqlx.In(
+		`DELETE FROM Transactions WHERE user_id = ? AND transaction_id IN (?)`,
+		userID, transactionIDs,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to build IN query: %w", err)
+	}
+	if _, err := q.ExecContext(ctx, q.Rebind(query), args...); err != nil {
+		return fmt.Errorf("failed to delete transactions: %w", err)
+	}
+	return nil
+}
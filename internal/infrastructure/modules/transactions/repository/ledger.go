@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"Finance-Manager-System/internal/infrastructure/database"
+)
+
+// GetAccountLedger sums the visible transactions of the account completed up
+// to until, or all of them when until is nil. Income counts as positive.
+func (tr *TransRepository) GetAccountLedger(ctx context.Context, userID uuid.UUID, accountID uuid.UUID, until *time.Time) (int64, error) {
+	q := database.GetQueryer(ctx, tr.db)
+	query := `
+		SELECT COALESCE(SUM(CASE WHEN is_income THEN amount ELSE -amount END), 0)
+		FROM Transactions
+		WHERE user_id = $1 AND account_id = $2 AND is_hidden = false
+			AND ($3::timestamptz IS NULL OR completed_at <= $3)
+	`
+	var sum int64
+	if err := q.GetContext(ctx, &sum, query, userID, accountID, until); err != nil {
+		return 0, fmt.Errorf("failed to sum account transactions: %w", err)
+	}
+	return sum, nil
+}
+
+// GetHiddenImportedLedger sums the hidden imported transactions of the
+// account. Bank statements still count them, so the statement balance is
+// this much off the account balance. Income counts as positive.
+func (tr *TransRepository) GetHiddenImportedLedger(ctx context.Context, userID uuid.UUID, accountID uuid.UUID) (int64, error) {
+	q := database.GetQueryer(ctx, tr.db)
+	query := `
+		SELECT COALESCE(SUM(CASE WHEN is_income THEN amount ELSE -amount END), 0)
+		FROM Transactions
+		WHERE user_id = $1 AND account_id = $2 AND is_hidden = true
+			AND external_transaction_id IS NOT NULL
+	`
+	var sum int64
+	if err := q.GetContext(ctx, &sum, query, userID, accountID); err != nil {
+		return 0, fmt.Errorf("failed to sum hidden account transactions: %w", err)
+	}
+	return sum, nil
+}
+
+// GetAccountDailyFlows returns the net change of the account per UTC day for
+// the visible transactions completed in [from, to).
+func (tr *TransRepository) GetAccountDailyFlows(ctx context.Context, userID uuid.UUID, accountID uuid.UUID, from, to time.Time) (map[time.Time]int64, error) {
+	q := database.GetQueryer(ctx, tr.db)
+	var rows []struct {
+		Day    time.Time `db:"day"`
+		Amount int64     `db:"amount"`
+	}
+	query := `
+		SELECT date_trunc('day', completed_at AT TIME ZONE 'UTC') AS day,
+			SUM(CASE WHEN is_income THEN amount ELSE -amount END) AS amount
+		FROM Transactions
+		WHERE user_id = $1 AND account_id = $2 AND is_hidden = false
+			AND completed_at >= $3 AND completed_at < $4
+		GROUP BY day
+	`
+	if err := q.SelectContext(ctx, &rows, query, userID, accountID, from, to); err != nil {
+		return nil, fmt.Errorf("failed to get daily account flows: %w", err)
+	}
+	flows := make(map[time.Time]int64, len(rows))
+	for _, row := range rows {
+		day := row.Day.UTC()
+		flows[time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)] += row.Amount
+	}
+	return flows, nil
+}
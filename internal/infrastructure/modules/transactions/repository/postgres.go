@@ -4,12 +4,12 @@ import (
 	"context"
 	"fmt"
 	"strings"
-	"unicode"
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
 
 	"Finance-Manager-System/internal/infrastructure/database"
+	"Finance-Manager-System/internal/infrastructure/modules/classifier"
 	"Finance-Manager-System/internal/infrastructure/modules/transactions/domain"
 )
 
@@ -27,6 +27,10 @@ func (tr *TransRepository) MoveTransactionsCategory(ctx context.Context, userID
 		return err
 	}
 	query := `UPDATE Transactions SET category_id = $1 WHERE category_id = $2 AND user_id = $3`
+	if _, err := q.ExecContext(ctx, query, newCategoryID, oldCategoryID, userID); err != nil {
+		return err
+	}
+	query = `UPDATE TransactionSplits SET category_id = $1 WHERE category_id = $2 AND user_id = $3`
 	_, err := q.ExecContext(ctx, query, newCategoryID, oldCategoryID, userID)
 	return err
 }
@@ -36,16 +40,23 @@ func (tr *TransRepository) AddTransactions(ctx context.Context, transactions []*
 	if err := tr.ensureTransactionsSchema(ctx, q); err != nil {
 		return 0, err
 	}
+	for _, trans := range transactions {
+		if trans.TransactionID == uuid.Nil {
+			trans.TransactionID = uuid.New()
+		}
+	}
 	query := `
         INSERT INTO Transactions (
-            user_id, account_id, category_id, name_transaction, 
+            transaction_id, user_id, account_id, category_id, name_transaction, 
             is_income, amount, completed_at, is_hidden, is_imported, comment,
-            sender_account, receiver_account, currency, bank_fee, status, external_transaction_id, mcc_code
+            sender_account, receiver_account, currency, bank_fee, status, external_transaction_id, mcc_code,
+            transfer_id, import_batch_id, category_source, category_confidence, is_adjustment
         ) 
         VALUES (
-            :user_id, :account_id, :category_id, :name_transaction, 
+            :transaction_id, :user_id, :account_id, :category_id, :name_transaction, 
             :is_income, :amount, :completed_at, :is_hidden, :is_imported, :comment,
-            :sender_account, :receiver_account, :currency, :bank_fee, :status, :external_transaction_id, :mcc_code
+            :sender_account, :receiver_account, :currency, :bank_fee, :status, :external_transaction_id, :mcc_code,
+            :transfer_id, :import_batch_id, :category_source, :category_confidence, :is_adjustment
         )
         ON CONFLICT (user_id, account_id, external_transaction_id)
         WHERE external_transaction_id IS NOT NULL
@@ -62,6 +73,34 @@ func (tr *TransRepository) AddTransactions(ctx context.Context, transactions []*
 	return int(rowsAffected), nil
 }
 
+func (tr *TransRepository) GetExistingExternalIDs(ctx context.Context, userID uuid.UUID, accountID uuid.UUID, externalIDs []string) (map[string]bool, error) {
+	existing := make(map[string]bool)
+	if len(externalIDs) == 0 {
+		return existing, nil
+	}
+	q := database.GetQueryer(ctx, tr.db)
+	if err := tr.ensureTransactionsSchema(ctx, q); err != nil {
+		return nil, err
+	}
+	query := `
+        SELECT external_transaction_id FROM Transactions
+        WHERE user_id = ? AND account_id = ? AND external_transaction_id IN (?)
+    `
+	query, args, err := sqlx.In(query, userID, accountID, externalIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build external ID query: %w", err)
+	}
+
+	var found []string
+	if err := q.SelectContext(ctx, &found, q.Rebind(query), args...); err != nil {
+		return nil, fmt.Errorf("failed to get existing external IDs: %w", err)
+	}
+	for _, id := range found {
+		existing[id] = true
+	}
+	return existing, nil
+}
+
 func (tr *TransRepository) ShowTransactions(ctx context.Context, userId uuid.UUID, transactionIds []uuid.UUID) error {
 	if len(transactionIds) == 0 {
 		return nil
@@ -126,16 +165,21 @@ func (tr *TransRepository) AddTransaction(ctx context.Context, trans *domain.Tra
 	if err := tr.ensureTransactionsSchema(ctx, q); err != nil {
 		return err
 	}
+	if trans.TransactionID == uuid.Nil {
+		trans.TransactionID = uuid.New()
+	}
 	query := `
         INSERT INTO Transactions (
-            user_id, account_id, category_id, name_transaction, 
+            transaction_id, user_id, account_id, category_id, name_transaction, 
             is_income, amount, completed_at, is_hidden, is_imported, comment,
-            sender_account, receiver_account, currency, bank_fee, status, external_transaction_id, mcc_code
+            sender_account, receiver_account, currency, bank_fee, status, external_transaction_id, mcc_code,
+            transfer_id, import_batch_id, category_source, category_confidence, is_adjustment
         ) 
         VALUES (
-            :user_id, :account_id, :category_id, :name_transaction, 
+            :transaction_id, :user_id, :account_id, :category_id, :name_transaction, 
             :is_income, :amount, :completed_at, :is_hidden, :is_imported, :comment,
-            :sender_account, :receiver_account, :currency, :bank_fee, :status, :external_transaction_id, :mcc_code
+            :sender_account, :receiver_account, :currency, :bank_fee, :status, :external_transaction_id, :mcc_code,
+            :transfer_id, :import_batch_id, :category_source, :category_confidence, :is_adjustment
         )
         ON CONFLICT (user_id, account_id, external_transaction_id)
         WHERE external_transaction_id IS NOT NULL
@@ -167,7 +211,9 @@ func (tr *TransRepository) UpdateTransaction(ctx context.Context, trans *domain.
             bank_fee = :bank_fee,
             status = :status,
             external_transaction_id = :external_transaction_id,
-            mcc_code = :mcc_code
+            mcc_code = :mcc_code,
+            category_source = :category_source,
+            category_confidence = :category_confidence
         WHERE transaction_id = :transaction_id AND user_id = :user_id
     `
 	_, err := q.NamedExecContext(ctx, query, trans)
@@ -198,6 +244,25 @@ func (tr *TransRepository) DeleteTransaction(ctx context.Context, userID uuid.UU
 	return nil
 }
 
+func (tr *TransRepository) DeleteTransactionsByImportBatch(ctx context.Context, userID uuid.UUID, batchID uuid.UUID) (int, error) {
+	q := database.GetQueryer(ctx, tr.db)
+	if err := tr.ensureTransactionsSchema(ctx, q); err != nil {
+		return 0, err
+	}
+	query := `DELETE FROM Transactions WHERE user_id = $1 AND import_batch_id = $2`
+
+	result, err := q.ExecContext(ctx, query, userID, batchID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete import batch transactions: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	return int(rowsAffected), nil
+}
+
 func (tr *TransRepository) GetAllTransactions(ctx context.Context, userID uuid.UUID) ([]domain.Transaction, error) {
 	q := database.GetQueryer(ctx, tr.db)
 	if err := tr.ensureTransactionsSchema(ctx, q); err != nil {
@@ -225,59 +290,178 @@ func (tr *TransRepository) GetTransactionsWithFilter(ctx context.Context, userID
 	}
 	transactions := make([]domain.Transaction, 0)
 
-	query := `SELECT * FROM Transactions WHERE user_id = $1`
-	args := []interface{}{userID}
-	argID := 2
+	conditions, args, _ := filterConditions(filter, []interface{}{userID})
+	query := `SELECT t.* FROM Transactions t WHERE t.user_id = $1` + conditions
+	query += ` ORDER BY t.completed_at DESC, t.transaction_id DESC`
 
-	if filter.AccountID != nil {
-		query += fmt.Sprintf(` AND account_id = $%d`, argID)
-		args = append(args, *filter.AccountID)
+	err := q.SelectContext(ctx, &transactions, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get filtered transactions: %w", err)
+	}
+	return transactions, nil
+}
+
+// SearchTransactions returns one page of the filtered transactions together
+// with the totals of the whole filtered set.
+func (tr *TransRepository) SearchTransactions(ctx context.Context, userID uuid.UUID, search domain.TransactionSearch) (*domain.TransactionPage, error) {
+	q := database.GetQueryer(ctx, tr.db)
+	if err := tr.ensureTransactionsSchema(ctx, q); err != nil {
+		return nil, err
+	}
+
+	conditions, args, argID := filterConditions(search.Filter, []interface{}{userID})
+
+	page := &domain.TransactionPage{Items: make([]domain.Transaction, 0)}
+	totalsQuery := `
+		SELECT
+			COUNT(*) AS total_count,
+			COALESCE(SUM(CASE WHEN t.is_income THEN ` + database.BaseAmount("t.amount") + ` ELSE 0 END), 0) AS total_income,
+			COALESCE(SUM(CASE WHEN t.is_income THEN 0 ELSE ` + database.BaseAmount("t.amount") + ` END), 0) AS total_expense,
+			` + database.UnconvertedCurrencies("t") + ` AS unconverted_currencies
+		FROM Transactions t` + database.BaseCurrencyJoin("t") + `
+		WHERE t.user_id = $1` + conditions
+	var totals struct {
+		TotalCount   int64                 `db:"total_count"`
+		TotalIncome  int64                 `db:"total_income"`
+		TotalExpense int64                 `db:"total_expense"`
+		Unconverted  database.CurrencyList `db:"unconverted_currencies"`
+	}
+	if err := q.GetContext(ctx, &totals, totalsQuery, args...); err != nil {
+		return nil, fmt.Errorf("failed to count transactions: %w", err)
+	}
+	page.TotalCount, page.TotalIncome, page.TotalExpense = totals.TotalCount, totals.TotalIncome, totals.TotalExpense
+	page.UnconvertedCurrencies = totals.Unconverted
+	page.TotalAmount = page.TotalIncome - page.TotalExpense
+
+	column := "t.completed_at"
+	if search.Sort.ByAmount() {
+		column = "t.amount"
+	}
+	direction, compare := "ASC", ">"
+	if search.Sort.Descending() {
+		direction, compare = "DESC", "<"
+	}
+
+	query := `SELECT t.* FROM Transactions t WHERE t.user_id = $1` + conditions
+	if search.Cursor != nil {
+		var value interface{} = search.Cursor.CompletedAt
+		if search.Sort.ByAmount() {
+			value = search.Cursor.Amount
+		}
+		query += fmt.Sprintf(` AND (%s, t.transaction_id) %s ($%d, $%d)`, column, compare, argID, argID+1)
+		args = append(args, value, search.Cursor.TransactionID)
+		argID += 2
+	}
+	query += fmt.Sprintf(` ORDER BY %[1]s %[2]s, t.transaction_id %[2]s LIMIT $%[3]d`, column, direction, argID)
+	// One extra row tells whether there is a next page.
+	args = append(args, search.Limit+1)
+
+	if err := q.SelectContext(ctx, &page.Items, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to search transactions: %w", err)
+	}
+	if len(page.Items) > search.Limit {
+		page.Items = page.Items[:search.Limit]
+		cursor := domain.NewSearchCursor(search.Sort, page.Items[len(page.Items)-1]).Encode()
+		page.NextCursor = &cursor
+	}
+	return page, nil
+}
+
+// filterConditions renders the filter as AND conditions on the Transactions
+// alias t, appending the values to args. It returns the next placeholder number.
+func filterConditions(filter domain.TransactionFilter, args []interface{}) (string, []interface{}, int) {
+	var b strings.Builder
+	argID := len(args) + 1
+	add := func(format string, value interface{}) {
+		b.WriteString(fmt.Sprintf(format, argID))
+		args = append(args, value)
 		argID++
 	}
+	placeholders := func(values []interface{}) string {
+		out := make([]string, len(values))
+		for i, value := range values {
+			out[i] = fmt.Sprintf("$%d", argID)
+			args = append(args, value)
+			argID++
+		}
+		return strings.Join(out, ", ")
+	}
+	addIn := func(column string, values []interface{}) {
+		b.WriteString(` AND ` + column + ` IN (` + placeholders(values) + `)`)
+	}
+
+	if filter.AccountID != nil {
+		add(` AND t.account_id = $%d`, *filter.AccountID)
+	}
 	if filter.CategoryID != nil {
-		query += fmt.Sprintf(` AND category_id = $%d`, argID)
-		args = append(args, *filter.CategoryID)
-		argID++
+		add(` AND t.category_id = $%d`, *filter.CategoryID)
+	}
+	if filter.Uncategorized {
+		b.WriteString(` AND t.category_id IS NULL`)
+	}
+	if filter.NeedsReview {
+		// A category the user accepted or set is never reviewed again.
+		b.WriteString(` AND t.transfer_id IS NULL AND t.category_source <> '` + string(domain.SourceManual) + `'`)
+		b.WriteString(` AND NOT EXISTS (SELECT 1 FROM TransactionSplits sp WHERE sp.transaction_id = t.transaction_id)`)
+		add(` AND (t.category_id IS NULL OR t.category_source = '`+string(domain.SourceFallback)+`'`+
+			` OR (t.category_source = '`+string(domain.SourceClassifier)+`' AND t.category_confidence < $%d))`, domain.ReviewConfidence)
 	}
 	if filter.IsIncome != nil {
-		query += fmt.Sprintf(` AND is_income = $%d`, argID)
-		args = append(args, *filter.IsIncome)
-		argID++
+		add(` AND t.is_income = $%d`, *filter.IsIncome)
 	}
 	if filter.StartDate != nil {
-		query += fmt.Sprintf(` AND completed_at >= $%d`, argID)
-		args = append(args, *filter.StartDate)
-		argID++
+		add(` AND t.completed_at >= $%d`, *filter.StartDate)
 	}
 	if filter.EndDate != nil {
-		query += fmt.Sprintf(` AND completed_at <= $%d`, argID)
-		args = append(args, *filter.EndDate)
-		argID++
+		add(` AND t.completed_at <= $%d`, *filter.EndDate)
 	}
 	if filter.IsHidden != nil {
-		query += fmt.Sprintf(` AND is_hidden = $%d`, argID)
-		args = append(args, *filter.IsHidden)
-		argID++
+		add(` AND t.is_hidden = $%d`, *filter.IsHidden)
 	} else if !filter.IncludeHidden {
-		query += ` AND is_hidden = false`
+		b.WriteString(` AND t.is_hidden = false`)
 	}
 	if len(filter.AccountIDs) > 0 {
-		placeholders := make([]string, len(filter.AccountIDs))
+		values := make([]interface{}, len(filter.AccountIDs))
 		for i, id := range filter.AccountIDs {
-			placeholders[i] = fmt.Sprintf("$%d", argID)
-			args = append(args, id)
-			argID++
+			values[i] = id
 		}
-		query += ` AND account_id IN (` + strings.Join(placeholders, ", ") + `)`
+		addIn("t.account_id", values)
 	}
-
-	query += ` ORDER BY completed_at DESC, transaction_id DESC`
-
-	err := q.SelectContext(ctx, &transactions, query, args...)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get filtered transactions: %w", err)
+	if filter.MinAmount != nil {
+		add(` AND t.amount >= $%d`, *filter.MinAmount)
 	}
-	return transactions, nil
+	if filter.MaxAmount != nil {
+		add(` AND t.amount <= $%d`, *filter.MaxAmount)
+	}
+	if filter.Query != "" {
+		// Served by the trigram indexes on name_transaction and comment.
+		add(` AND (t.name_transaction ILIKE $%[1]d ESCAPE '\' OR t.comment ILIKE $%[1]d ESCAPE '\')`, "%"+database.EscapeLike(filter.Query)+"%")
+	}
+	if len(filter.TagIDs) > 0 {
+		values := make([]interface{}, len(filter.TagIDs))
+		for i, id := range filter.TagIDs {
+			values[i] = id
+		}
+		b.WriteString(` AND EXISTS (SELECT 1 FROM TransactionTags tt WHERE tt.transaction_id = t.transaction_id AND tt.tag_id IN (` + placeholders(values) + `))`)
+	}
+	for _, list := range []struct {
+		column string
+		values []string
+	}{
+		{"t.mcc_code", filter.MCCCodes},
+		{"t.status", filter.Statuses},
+		{"t.currency", filter.Currencies},
+	} {
+		if len(list.values) == 0 {
+			continue
+		}
+		values := make([]interface{}, len(list.values))
+		for i, value := range list.values {
+			values[i] = value
+		}
+		addIn(list.column, values)
+	}
+	return b.String(), args, argID
 }
 
 func (tr *TransRepository) GetTransactionsByIDs(ctx context.Context, userID uuid.UUID, transactionIDs []uuid.UUID) ([]domain.Transaction, error) {
@@ -313,7 +497,7 @@ func (tr *TransRepository) ResolveAutoCategoryID(ctx context.Context, userID uui
 		return nil, err
 	}
 
-	merchantKey := normalizeRuleKey(description)
+	merchantKey := domain.MerchantKey(description)
 	if mccCode != nil {
 		var categoryID uuid.UUID
 		err := q.GetContext(
@@ -322,6 +506,7 @@ func (tr *TransRepository) ResolveAutoCategoryID(ctx context.Context, userID uui
 			`SELECT category_id
 			 FROM AutoCategoryRules
 			 WHERE user_id = $1 AND is_income = $2 AND mcc_code = $3
+			   AND source = 'learned' AND is_enabled AND category_id IS NOT NULL
 			 ORDER BY updated_at DESC
 			 LIMIT 1`,
 			userID,
@@ -343,7 +528,8 @@ func (tr *TransRepository) ResolveAutoCategoryID(ctx context.Context, userID uui
 		&categoryID,
 		`SELECT category_id
 		 FROM AutoCategoryRules
-		 WHERE user_id = $1 AND is_income = $2 AND merchant_key = $3
+		 WHERE user_id = $1 AND is_income = $2 AND merchant_key = $3 AND mcc_code IS NULL
+		   AND source = 'learned' AND is_enabled AND category_id IS NOT NULL
 		 ORDER BY updated_at DESC
 		 LIMIT 1`,
 		userID,
@@ -356,12 +542,77 @@ func (tr *TransRepository) ResolveAutoCategoryID(ctx context.Context, userID uui
 	return &categoryID, nil
 }
 
+// GetClassifierSamples returns the user's latest categorised transactions to
+// train the import classifier on. Only categories the user chose, confirmed
+// or set up a rule for are used, so the classifier does not learn from its
+// own guesses, the MCC map or the fallback. Transfers and split transactions
+// are left out because their category does not describe the merchant.
+func (tr *TransRepository) GetClassifierSamples(ctx context.Context, userID uuid.UUID, limit int) ([]classifier.Sample, error) {
+	q := database.GetQueryer(ctx, tr.db)
+	if err := tr.ensureTransactionsSchema(ctx, q); err != nil {
+		return nil, err
+	}
+
+	samples := make([]classifier.Sample, 0)
+	query := `
+		SELECT t.category_id, t.name_transaction, t.mcc_code, t.amount, t.is_income
+		FROM Transactions t
+		WHERE t.user_id = $1
+		  AND t.category_id IS NOT NULL
+		  AND t.transfer_id IS NULL
+		  AND t.is_adjustment = false
+		  AND t.category_source IN ($3, $4, $5)
+		  AND NOT EXISTS (SELECT 1 FROM TransactionSplits sp WHERE sp.transaction_id = t.transaction_id)
+		ORDER BY t.completed_at DESC
+		LIMIT $2`
+	if err := q.SelectContext(ctx, &samples, query, userID, limit, domain.SourceManual, domain.SourceRule, domain.SourceLearned); err != nil {
+		return nil, fmt.Errorf("failed to get classifier samples: %w", err)
+	}
+	return samples, nil
+}
+
+// SetReviewedCategory sets the category of the transactions and marks it as
+// chosen by the user.
+func (tr *TransRepository) SetReviewedCategory(ctx context.Context, userID uuid.UUID, transactionIDs []uuid.UUID, categoryID uuid.UUID) error {
+	q := database.GetQueryer(ctx, tr.db)
+	query, args, err := sqlx.In(
+		`UPDATE Transactions SET category_id = ?, category_source = ?, category_confidence = NULL
+		 WHERE user_id = ? AND transaction_id IN (?)`,
+		categoryID, domain.SourceManual, userID, transactionIDs,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to build IN query: %w", err)
+	}
+	if _, err := q.ExecContext(ctx, q.Rebind(query), args...); err != nil {
+		return fmt.Errorf("failed to set reviewed category: %w", err)
+	}
+	return nil
+}
+
+// AcceptCategories marks the current categories of the transactions as
+// confirmed by the user.
+func (tr *TransRepository) AcceptCategories(ctx context.Context, userID uuid.UUID, transactionIDs []uuid.UUID) error {
+	q := database.GetQueryer(ctx, tr.db)
+	query, args, err := sqlx.In(
+		`UPDATE Transactions SET category_source = ?, category_confidence = NULL
+		 WHERE user_id = ? AND transaction_id IN (?)`,
+		domain.SourceManual, userID, transactionIDs,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to build IN query: %w", err)
+	}
+	if _, err := q.ExecContext(ctx, q.Rebind(query), args...); err != nil {
+		return fmt.Errorf("failed to accept categories: %w", err)
+	}
+	return nil
+}
+
 func (tr *TransRepository) UpsertAutoCategoryRule(ctx context.Context, userID uuid.UUID, isIncome bool, mccCode *string, description string, categoryID uuid.UUID) error {
 	q := database.GetQueryer(ctx, tr.db)
 	if err := tr.ensureTransactionsSchema(ctx, q); err != nil {
 		return err
 	}
-	merchantKey := normalizeRuleKey(description)
+	merchantKey := domain.MerchantKey(description)
 	normalizedMCC := ""
 	if mccCode != nil {
 		normalizedMCC = strings.TrimSpace(*mccCode)
@@ -374,14 +625,13 @@ func (tr *TransRepository) UpsertAutoCategoryRule(ctx context.Context, userID uu
 	if normalizedMCC != "" {
 		if _, err := q.ExecContext(
 			ctx,
-			`INSERT INTO AutoCategoryRules (user_id, is_income, mcc_code, merchant_key, category_id, created_at, updated_at)
-			 VALUES ($1, $2, $3, $4, $5, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
-			 ON CONFLICT (user_id, is_income, mcc_code)
-			 DO UPDATE SET category_id = EXCLUDED.category_id, merchant_key = EXCLUDED.merchant_key, updated_at = CURRENT_TIMESTAMP`,
+			`INSERT INTO AutoCategoryRules (user_id, is_income, mcc_code, merchant_key, category_id, source, priority, created_at, updated_at)
+			 VALUES ($1, $2, $3, '', $4, 'learned', 1000, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+			 ON CONFLICT (user_id, is_income, mcc_code) WHERE source = 'learned' AND mcc_code IS NOT NULL
+			 DO UPDATE SET category_id = EXCLUDED.category_id, updated_at = CURRENT_TIMESTAMP`,
 			userID,
 			isIncome,
 			normalizedMCC,
-			merchantKey,
 			categoryID,
 		); err != nil {
 			return fmt.Errorf("failed to upsert mcc auto-category rule: %w", err)
@@ -391,9 +641,9 @@ func (tr *TransRepository) UpsertAutoCategoryRule(ctx context.Context, userID uu
 	if merchantKey != "" {
 		if _, err := q.ExecContext(
 			ctx,
-			`INSERT INTO AutoCategoryRules (user_id, is_income, mcc_code, merchant_key, category_id, created_at, updated_at)
-			 VALUES ($1, $2, NULL, $3, $4, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
-			 ON CONFLICT (user_id, is_income, merchant_key)
+			`INSERT INTO AutoCategoryRules (user_id, is_income, mcc_code, merchant_key, category_id, source, priority, created_at, updated_at)
+			 VALUES ($1, $2, NULL, $3, $4, 'learned', 1001, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+			 ON CONFLICT (user_id, is_income, merchant_key) WHERE source = 'learned' AND mcc_code IS NULL AND merchant_key <> ''
 			 DO UPDATE SET category_id = EXCLUDED.category_id, updated_at = CURRENT_TIMESTAMP`,
 			userID,
 			isIncome,
@@ -417,20 +667,6 @@ func (tr *TransRepository) ensureTransactionsSchema(ctx context.Context, q datab
 		`ALTER TABLE Transactions ADD COLUMN IF NOT EXISTS external_transaction_id TEXT`,
 		`ALTER TABLE Transactions ADD COLUMN IF NOT EXISTS mcc_code VARCHAR(4)`,
 		`CREATE UNIQUE INDEX IF NOT EXISTS idx_transactions_external_uid ON Transactions(user_id, account_id, external_transaction_id) WHERE external_transaction_id IS NOT NULL`,
-		`CREATE TABLE IF NOT EXISTS AutoCategoryRules (
-			rule_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
-			user_id UUID NOT NULL,
-			is_income BOOLEAN NOT NULL,
-			mcc_code VARCHAR(4),
-			merchant_key TEXT NOT NULL DEFAULT '',
-			category_id UUID NOT NULL,
-			created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
-			updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
-			CONSTRAINT fk_user_auto_category_rule FOREIGN KEY (user_id) REFERENCES Users(user_id) ON DELETE CASCADE,
-			CONSTRAINT fk_category_auto_category_rule FOREIGN KEY (category_id) REFERENCES Category(category_id) ON DELETE CASCADE
-		)`,
-		`CREATE UNIQUE INDEX IF NOT EXISTS idx_auto_category_rule_mcc ON AutoCategoryRules (user_id, is_income, mcc_code) WHERE mcc_code IS NOT NULL`,
-		`CREATE UNIQUE INDEX IF NOT EXISTS idx_auto_category_rule_merchant ON AutoCategoryRules (user_id, is_income, merchant_key) WHERE merchant_key <> ''`,
 	}
 	for _, query := range queries {
 		if _, err := q.ExecContext(ctx, query); err != nil {
@@ -439,14 +675,3 @@ func (tr *TransRepository) ensureTransactionsSchema(ctx context.Context, q datab
 	}
 	return nil
 }
-
-func normalizeRuleKey(s string) string {
-	s = strings.ToLower(strings.TrimSpace(s))
-	var b strings.Builder
-	for _, r := range s {
-		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == ' ' || r == '.' || r == '*' {
-			b.WriteRune(r)
-		}
-	}
-	return strings.Join(strings.Fields(b.String()), " ")
-}
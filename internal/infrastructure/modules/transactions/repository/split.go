@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"Finance-Manager-System/internal/infrastructure/database"
+	"Finance-Manager-System/internal/infrastructure/modules/transactions/domain"
+)
+
+func (tr *TransRepository) GetSplits(ctx context.Context, userID uuid.UUID, transactionID uuid.UUID) ([]domain.TransactionSplit, error) {
+	q := database.GetQueryer(ctx, tr.db)
+	splits := make([]domain.TransactionSplit, 0)
+	query := `SELECT * FROM TransactionSplits WHERE user_id = $1 AND transaction_id = $2 ORDER BY position ASC`
+	if err := q.SelectContext(ctx, &splits, query, userID, transactionID); err != nil {
+		return nil, fmt.Errorf("failed to get transaction splits: %w", err)
+	}
+	return splits, nil
+}
+
+// ReplaceSplits drops the current parts of the transaction and stores splits.
+func (tr *TransRepository) ReplaceSplits(ctx context.Context, userID uuid.UUID, transactionID uuid.UUID, splits []domain.TransactionSplit) error {
+	if err := tr.DeleteSplits(ctx, userID, transactionID); err != nil {
+		return err
+	}
+	if len(splits) == 0 {
+		return nil
+	}
+	q := database.GetQueryer(ctx, tr.db)
+	query := `
+		INSERT INTO TransactionSplits (split_id, transaction_id, user_id, category_id, amount, comment, position, created_at)
+		VALUES (:split_id, :transaction_id, :user_id, :category_id, :amount, :comment, :position, :created_at)
+	`
+	if _, err := q.NamedExecContext(ctx, query, splits); err != nil {
+		return fmt.Errorf("failed to add transaction splits: %w", err)
+	}
+	return nil
+}
+
+func (tr *TransRepository) DeleteSplits(ctx context.Context, userID uuid.UUID, transactionID uuid.UUID) error {
+	q := database.GetQueryer(ctx, tr.db)
+	query := `DELETE FROM TransactionSplits WHERE user_id = $1 AND transaction_id = $2`
+	if _, err := q.ExecContext(ctx, query, userID, transactionID); err != nil {
+		return fmt.Errorf("failed to delete transaction splits: %w", err)
+	}
+	return nil
+}
+
+// GetSplitTransactionIDs reports which of the transactions are split.
+func (tr *TransRepository) GetSplitTransactionIDs(ctx context.Context, userID uuid.UUID, transactionIDs []uuid.UUID) (map[uuid.UUID]bool, error) {
+	split := make(map[uuid.UUID]bool)
+	if len(transactionIDs) == 0 {
+		return split, nil
+	}
+	q := database.GetQueryer(ctx, tr.db)
+	query, args, err := sqlx.In(
+		`SELECT DISTINCT transaction_id FROM TransactionSplits WHERE user_id = ? AND transaction_id IN (?)`,
+		userID, transactionIDs,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build IN query: %w", err)
+	}
+	var ids []uuid.UUID
+	if err := q.SelectContext(ctx, &ids, q.Rebind(query), args...); err != nil {
+		return nil, fmt.Errorf("failed to get split transactions: %w", err)
+	}
+	for _, id := range ids {
+		split[id] = true
+	}
+	return split, nil
+}
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"Finance-Manager-System/internal/infrastructure/database"
+	"Finance-Manager-System/internal/infrastructure/modules/transactions/domain"
+)
+
+func (tr *TransRepository) AddTransfer(ctx context.Context, transfer *domain.Transfer) error {
+	q := database.GetQueryer(ctx, tr.db)
+	if transfer.TransferID == uuid.Nil {
+		transfer.TransferID = uuid.New()
+	}
+	query := `
+        INSERT INTO Transfers (transfer_id, user_id, from_account_id, to_account_id, amount, to_amount, completed_at, comment, created_at)
+        VALUES (:transfer_id, :user_id, :from_account_id, :to_account_id, :amount, :to_amount, :completed_at, :comment, :created_at)
+    `
+	if _, err := q.NamedExecContext(ctx, query, transfer); err != nil {
+		return fmt.Errorf("failed to add transfer: %w", err)
+	}
+	return nil
+}
+
+func (tr *TransRepository) GetTransfer(ctx context.Context, userID uuid.UUID, transferID uuid.UUID) (*domain.Transfer, error) {
+	q := database.GetQueryer(ctx, tr.db)
+	var transfer domain.Transfer
+	query := `SELECT * FROM Transfers WHERE user_id = $1 AND transfer_id = $2`
+	if err := q.GetContext(ctx, &transfer, query, userID, transferID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrTransferNotFound
+		}
+		return nil, fmt.Errorf("failed to get transfer: %w", err)
+	}
+	return &transfer, nil
+}
+
+func (tr *TransRepository) UpdateTransfer(ctx context.Context, transfer *domain.Transfer) error {
+	q := database.GetQueryer(ctx, tr.db)
+	query := `
+        UPDATE Transfers SET
+            amount = :amount,
+            to_amount = :to_amount,
+            completed_at = :completed_at,
+            comment = :comment
+        WHERE transfer_id = :transfer_id AND user_id = :user_id
+    `
+	if _, err := q.NamedExecContext(ctx, query, transfer); err != nil {
+		return fmt.Errorf("failed to update transfer: %w", err)
+	}
+	return nil
+}
+
+func (tr *TransRepository) DeleteTransfer(ctx context.Context, userID uuid.UUID, transferID uuid.UUID) error {
+	q := database.GetQueryer(ctx, tr.db)
+	if _, err := q.ExecContext(ctx, `DELETE FROM Transactions WHERE user_id = $1 AND transfer_id = $2`, userID, transferID); err != nil {
+		return fmt.Errorf("failed to delete transfer legs: %w", err)
+	}
+
+	result, err := q.ExecContext(ctx, `DELETE FROM Transfers WHERE user_id = $1 AND transfer_id = $2`, userID, transferID)
+	if err != nil {
+		return fmt.Errorf("failed to delete transfer: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if rowsAffected == 0 {
+		return domain.ErrTransferNotFound
+	}
+	return nil
+}
+
+func (tr *TransRepository) GetTransferLegs(ctx context.Context, userID uuid.UUID, transferID uuid.UUID) ([]domain.Transaction, error) {
+	q := database.GetQueryer(ctx, tr.db)
+	if err := tr.ensureTransactionsSchema(ctx, q); err != nil {
+		return nil, err
+	}
+	legs := make([]domain.Transaction, 0, 2)
+	query := `
+        SELECT * FROM Transactions
+        WHERE user_id = $1 AND transfer_id = $2
+        ORDER BY is_income ASC, transaction_id ASC
+    `
+	if err := q.SelectContext(ctx, &legs, query, userID, transferID); err != nil {
+		return nil, fmt.Errorf("failed to get transfer legs: %w", err)
+	}
+	return legs, nil
+}
@@ -0,0 +1,163 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"Finance-Manager-System/internal/infrastructure/modules/transactions/domain"
+)
+
+// BulkUpdate applies one operation to the transactions selected by ID or by
+// filter in a single database transaction. Transactions the operation cannot
+// apply to are skipped and reported per item instead of failing the request.
+func (uc *TransactionUseCase) BulkUpdate(ctx context.Context, userID uuid.UUID, params domain.BulkParams) (*domain.BulkResult, error) {
+	params.TransactionIDs = uniqueIDs(params.TransactionIDs)
+	params.TagIDs = uniqueIDs(params.TagIDs)
+	if params.Comment != nil {
+		comment := strings.TrimSpace(*params.Comment)
+		params.Comment = &comment
+		if comment == "" {
+			params.Comment = nil
+		}
+	}
+	if err := params.Validate(); err != nil {
+		return nil, err
+	}
+
+	var result *domain.BulkResult
+	err := uc.txManager.RunInTransaction(ctx, func(ctx context.Context) error {
+		var transactions []domain.Transaction
+		var err error
+		if params.Filter != nil {
+			transactions, err = uc.transRepo.GetTransactionsWithFilter(ctx, userID, *params.Filter)
+			if err != nil {
+				return fmt.Errorf("failed to fetch transactions: %w", err)
+			}
+			if len(transactions) > domain.MaxBulkItems {
+				return domain.ErrBulkTooManyItems
+			}
+		} else {
+			transactions, err = uc.transRepo.GetTransactionsByIDs(ctx, userID, params.TransactionIDs)
+			if err != nil {
+				return fmt.Errorf("failed to fetch transactions: %w", err)
+			}
+		}
+
+		var split map[uuid.UUID]bool
+		if params.Operation == domain.BulkRecategorize {
+			ids := make([]uuid.UUID, len(transactions))
+			for i, t := range transactions {
+				ids[i] = t.TransactionID
+			}
+			if split, err = uc.transRepo.GetSplitTransactionIDs(ctx, userID, ids); err != nil {
+				return err
+			}
+		}
+		var currency string
+		switch params.Operation {
+		case domain.BulkMove:
+			if currency, err = uc.accountRepo.GetAccountCurrency(ctx, userID, *params.AccountID); err != nil {
+				return fmt.Errorf("%w: %w", domain.ErrBulkAccountNotFound, err)
+			}
+		case domain.BulkAddTags, domain.BulkRemoveTags:
+			for _, tagID := range params.TagIDs {
+				if _, err := uc.tagRepo.GetTagByID(ctx, userID, tagID); err != nil {
+					return fmt.Errorf("%w: %w", domain.ErrBulkTagNotFound, err)
+				}
+			}
+		}
+
+		var eligible []domain.Transaction
+		var requested []uuid.UUID
+		if params.Filter == nil {
+			requested = params.TransactionIDs
+		}
+		eligible, result = domain.PlanBulk(params, requested, transactions, split, currency)
+		if len(eligible) == 0 {
+			return nil
+		}
+		return uc.applyBulk(ctx, userID, params, eligible)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (uc *TransactionUseCase) applyBulk(ctx context.Context, userID uuid.UUID, params domain.BulkParams, transactions []domain.Transaction) error {
+	ids := make([]uuid.UUID, len(transactions))
+	for i, t := range transactions {
+		ids[i] = t.TransactionID
+	}
+
+	// Hidden transactions and balance adjustments are already out of the
+	// balance, so moving or deleting them leaves every account as it is.
+	accountDeltas := make(map[uuid.UUID]int64)
+	switch params.Operation {
+	case domain.BulkRecategorize:
+		if err := uc.transRepo.SetReviewedCategory(ctx, userID, ids, *params.CategoryID); err != nil {
+			return err
+		}
+		for _, t := range transactions {
+			if !t.IsImported {
+				continue
+			}
+			if err := uc.transRepo.UpsertAutoCategoryRule(ctx, userID, t.IsIncome, t.MCCCode, t.NameTransaction, *params.CategoryID); err != nil {
+				return fmt.Errorf("failed to save auto-category rule: %w", err)
+			}
+		}
+	case domain.BulkSetComment:
+		if err := uc.transRepo.SetTransactionsComment(ctx, userID, ids, params.Comment); err != nil {
+			return err
+		}
+	case domain.BulkAddTags:
+		if _, err := uc.tagRepo.AddTagsToTransactions(ctx, userID, ids, params.TagIDs); err != nil {
+			return err
+		}
+	case domain.BulkRemoveTags:
+		if _, err := uc.tagRepo.RemoveTagsFromTransactions(ctx, userID, ids, params.TagIDs); err != nil {
+			return err
+		}
+	case domain.BulkMove:
+		if err := uc.transRepo.MoveTransactions(ctx, userID, ids, *params.AccountID); err != nil {
+			return err
+		}
+		for _, t := range transactions {
+			if t.IsHidden {
+				continue
+			}
+			accountDeltas[t.AccountID] -= signedAmount(t)
+			accountDeltas[*params.AccountID] += signedAmount(t)
+		}
+	case domain.BulkDelete:
+		if err := uc.transRepo.DeleteTransactions(ctx, userID, ids); err != nil {
+			return err
+		}
+		for _, t := range transactions {
+			if !t.IsHidden && !t.IsAdjustment {
+				accountDeltas[t.AccountID] -= signedAmount(t)
+			}
+		}
+	}
+
+	for accountID, delta := range accountDeltas {
+		if delta == 0 {
+			continue
+		}
+		if err := uc.accountRepo.UpdateBalance(ctx, userID, accountID, delta); err != nil {
+			return fmt.Errorf("failed to update balance for account %s: %w", accountID, err)
+		}
+	}
+	return nil
+}
+
+// signedAmount is how much the transaction adds to its account balance.
+func signedAmount(t domain.Transaction) int64 {
+	if t.IsIncome {
+		return t.Amount
+	}
+	return -t.Amount
+}
@@ -0,0 +1,191 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+
+	tagDomain "Finance-Manager-System/internal/infrastructure/modules/tags/domain"
+	transactionDomain "Finance-Manager-System/internal/infrastructure/modules/transactions/domain"
+)
+
+type fakeAccountBalances struct {
+	currencies map[uuid.UUID]string
+	deltas     map[uuid.UUID]int64
+}
+
+func (f *fakeAccountBalances) UpdateBalance(ctx context.Context, userID uuid.UUID, accountID uuid.UUID, amountDelta int64) error {
+	if f.deltas == nil {
+		f.deltas = make(map[uuid.UUID]int64)
+	}
+	f.deltas[accountID] += amountDelta
+	return nil
+}
+
+func (f *fakeAccountBalances) GetAccountCurrency(ctx context.Context, userID uuid.UUID, accountID uuid.UUID) (string, error) {
+	currency, ok := f.currencies[accountID]
+	if !ok {
+		return "", errors.New("account not found")
+	}
+	return currency, nil
+}
+
+type fakeBulkTagRepo struct {
+	tags  map[uuid.UUID]bool
+	added int
+}
+
+func (f *fakeBulkTagRepo) GetTagByID(ctx context.Context, userID uuid.UUID, tagID uuid.UUID) (*tagDomain.Tag, error) {
+	if !f.tags[tagID] {
+		return nil, tagDomain.ErrTagNotFound
+	}
+	return &tagDomain.Tag{TagID: tagID, UserID: userID}, nil
+}
+func (f *fakeBulkTagRepo) AddTagsToTransactions(ctx context.Context, userID uuid.UUID, transactionIDs []uuid.UUID, tagIDs []uuid.UUID) (int, error) {
+	f.added += len(transactionIDs) * len(tagIDs)
+	return len(transactionIDs) * len(tagIDs), nil
+}
+func (f *fakeBulkTagRepo) RemoveTagsFromTransactions(ctx context.Context, userID uuid.UUID, transactionIDs []uuid.UUID, tagIDs []uuid.UUID) (int, error) {
+	return 0, nil
+}
+
+func TestBulkUpdateValidation(t *testing.T) {
+	uc := NewTransactionUseCase(&fakeTransRepo{}, &fakeAccountBalances{}, &fakeBulkTagRepo{}, &fakeTransTxManager{})
+	id := uuid.New()
+	cases := []struct {
+		params transactionDomain.BulkParams
+		want   error
+	}{
+		{transactionDomain.BulkParams{Operation: "archive", TransactionIDs: []uuid.UUID{id}}, transactionDomain.ErrInvalidBulkOperation},
+		{transactionDomain.BulkParams{Operation: transactionDomain.BulkDelete}, transactionDomain.ErrBulkSelection},
+		{transactionDomain.BulkParams{Operation: transactionDomain.BulkDelete, TransactionIDs: []uuid.UUID{id}, Filter: &transactionDomain.TransactionFilter{}}, transactionDomain.ErrBulkSelection},
+		{transactionDomain.BulkParams{Operation: transactionDomain.BulkRecategorize, TransactionIDs: []uuid.UUID{id}}, transactionDomain.ErrBulkCategoryRequired},
+		{transactionDomain.BulkParams{Operation: transactionDomain.BulkAddTags, TransactionIDs: []uuid.UUID{id}}, transactionDomain.ErrBulkTagsRequired},
+		{transactionDomain.BulkParams{Operation: transactionDomain.BulkMove, TransactionIDs: []uuid.UUID{id}}, transactionDomain.ErrBulkAccountRequired},
+		{transactionDomain.BulkParams{Operation: transactionDomain.BulkAddTags, TransactionIDs: []uuid.UUID{id}, TagIDs: []uuid.UUID{uuid.New()}}, transactionDomain.ErrBulkTagNotFound},
+	}
+	for _, tc := range cases {
+		if _, err := uc.BulkUpdate(context.Background(), uuid.New(), tc.params); !errors.Is(err, tc.want) {
+			t.Fatalf("%s: expected %v, got %v", tc.params.Operation, tc.want, err)
+		}
+	}
+}
+
+func TestBulkMoveAndDelete(t *testing.T) {
+	userID := uuid.New()
+	cardID, cashID, usdID := uuid.New(), uuid.New(), uuid.New()
+	transferID := uuid.New()
+
+	expense := &transactionDomain.Transaction{TransactionID: uuid.New(), AccountID: cardID, Amount: 500, Currency: "RUB"}
+	income := &transactionDomain.Transaction{TransactionID: uuid.New(), AccountID: cardID, Amount: 2000, IsIncome: true, Currency: "RUB"}
+	hidden := &transactionDomain.Transaction{TransactionID: uuid.New(), AccountID: cardID, Amount: 700, IsHidden: true, Currency: "RUB"}
+	imported := &transactionDomain.Transaction{TransactionID: uuid.New(), AccountID: cardID, Amount: 300, IsImported: true, Currency: "RUB"}
+	leg := &transactionDomain.Transaction{TransactionID: uuid.New(), AccountID: cardID, Amount: 100, TransferID: &transferID, Currency: "RUB"}
+	newRepo := func() *fakeTransRepo {
+		repo := &fakeTransRepo{byID: make(map[uuid.UUID]*transactionDomain.Transaction)}
+		for _, tx := range []*transactionDomain.Transaction{expense, income, hidden, imported, leg} {
+			copied := *tx
+			repo.byID[tx.TransactionID] = &copied
+		}
+		return repo
+	}
+	ids := []uuid.UUID{expense.TransactionID, income.TransactionID, hidden.TransactionID, imported.TransactionID, leg.TransactionID, uuid.New()}
+	currencies := map[uuid.UUID]string{cardID: "RUB", cashID: "RUB", usdID: "USD"}
+
+	repo := newRepo()
+	balances := &fakeAccountBalances{currencies: currencies}
+	uc := NewTransactionUseCase(repo, balances, nil, &fakeTransTxManager{})
+	result, err := uc.BulkUpdate(context.Background(), userID, transactionDomain.BulkParams{Operation: transactionDomain.BulkMove, TransactionIDs: ids, AccountID: &cashID})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if result.Applied != 3 || result.Skipped != 3 || len(result.Items) != len(ids) {
+		t.Fatalf("unexpected move result: %+v", result)
+	}
+	wantReasons := []transactionDomain.BulkSkipReason{"", "", "", transactionDomain.SkipImported, transactionDomain.SkipTransfer, transactionDomain.SkipNotFound}
+	for i, item := range result.Items {
+		if item.TransactionID != ids[i] || item.Reason != wantReasons[i] {
+			t.Fatalf("item %d: unexpected %+v", i, item)
+		}
+	}
+	if repo.byID[expense.TransactionID].AccountID != cashID || repo.byID[imported.TransactionID].AccountID != cardID {
+		t.Fatalf("only manual transactions must move")
+	}
+	// The expense and the income move, the hidden expense does not count.
+	if balances.deltas[cardID] != -1500 || balances.deltas[cashID] != 1500 {
+		t.Fatalf("unexpected balance deltas: %v", balances.deltas)
+	}
+
+	result, err = uc.BulkUpdate(context.Background(), userID, transactionDomain.BulkParams{Operation: transactionDomain.BulkMove, TransactionIDs: ids[:1], AccountID: &usdID})
+	if err != nil || result.Items[0].Reason != transactionDomain.SkipCurrencyMismatch {
+		t.Fatalf("expected currency mismatch, got %+v %v", result, err)
+	}
+
+	repo = newRepo()
+	balances = &fakeAccountBalances{currencies: currencies}
+	uc = NewTransactionUseCase(repo, balances, nil, &fakeTransTxManager{})
+	result, err = uc.BulkUpdate(context.Background(), userID, transactionDomain.BulkParams{Operation: transactionDomain.BulkDelete, TransactionIDs: ids})
+	if err != nil || result.Applied != 3 {
+		t.Fatalf("unexpected delete result: %+v %v", result, err)
+	}
+	if _, ok := repo.byID[expense.TransactionID]; ok {
+		t.Fatalf("manual transaction must be deleted")
+	}
+	if _, ok := repo.byID[imported.TransactionID]; !ok {
+		t.Fatalf("imported transaction must be kept")
+	}
+	if balances.deltas[cardID] != -1500 {
+		t.Fatalf("unexpected balance delta: %v", balances.deltas)
+	}
+}
+
+func TestBulkRecategorizeAndTagByFilter(t *testing.T) {
+	userID := uuid.New()
+	foodID, tagID := uuid.New(), uuid.New()
+	transferID := uuid.New()
+
+	imported := transactionDomain.Transaction{TransactionID: uuid.New(), IsImported: true, NameTransaction: "Bakery"}
+	manual := transactionDomain.Transaction{TransactionID: uuid.New(), NameTransaction: "Cash"}
+	leg := transactionDomain.Transaction{TransactionID: uuid.New(), TransferID: &transferID}
+	split := transactionDomain.Transaction{TransactionID: uuid.New()}
+	repo := &fakeTransRepo{
+		byID:     make(map[uuid.UUID]*transactionDomain.Transaction),
+		filtered: []transactionDomain.Transaction{imported, manual, leg, split},
+		splits:   map[uuid.UUID][]transactionDomain.TransactionSplit{split.TransactionID: {{}, {}}},
+	}
+	for i := range repo.filtered {
+		copied := repo.filtered[i]
+		repo.byID[copied.TransactionID] = &copied
+	}
+	tags := &fakeBulkTagRepo{tags: map[uuid.UUID]bool{tagID: true}}
+	uc := NewTransactionUseCase(repo, &fakeAccountBalances{}, tags, &fakeTransTxManager{})
+
+	result, err := uc.BulkUpdate(context.Background(), userID, transactionDomain.BulkParams{
+		Operation:  transactionDomain.BulkRecategorize,
+		Filter:     &transactionDomain.TransactionFilter{},
+		CategoryID: &foodID,
+	})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if result.Applied != 2 || result.Skipped != 2 {
+		t.Fatalf("unexpected recategorize result: %+v", result)
+	}
+	if got := repo.byID[manual.TransactionID]; *got.CategoryID != foodID || got.CategorySource != transactionDomain.SourceManual {
+		t.Fatalf("manual transaction must get the category, got %+v", got)
+	}
+	if repo.upsertRulesCount != 1 {
+		t.Fatalf("only the imported transaction must be learned, got %d rules", repo.upsertRulesCount)
+	}
+
+	result, err = uc.BulkUpdate(context.Background(), userID, transactionDomain.BulkParams{
+		Operation: transactionDomain.BulkAddTags,
+		Filter:    &transactionDomain.TransactionFilter{},
+		TagIDs:    []uuid.UUID{tagID, tagID},
+	})
+	if err != nil || result.Applied != 4 || tags.added != 4 {
+		t.Fatalf("every transaction must be tagged once, got %+v %v", result, err)
+	}
+}
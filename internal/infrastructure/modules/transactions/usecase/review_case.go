@@ -0,0 +1,118 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"Finance-Manager-System/internal/infrastructure/modules/transactions/domain"
+)
+
+// GetReviewQueue returns a page of the transactions whose category should be
+// checked by the user.
+func (uc *TransactionUseCase) GetReviewQueue(ctx context.Context, userID uuid.UUID, search domain.TransactionSearch) (*domain.TransactionPage, error) {
+	search.Filter.NeedsReview = true
+	return uc.SearchTransactions(ctx, userID, search)
+}
+
+// ResolveReview accepts or replaces the categories of the transactions. Either
+// way the category becomes a manual one, and for imported transactions it is
+// learned for future imports like a single correction is.
+func (uc *TransactionUseCase) ResolveReview(ctx context.Context, userID uuid.UUID, transactionIDs []uuid.UUID, action domain.ReviewAction, categoryID *uuid.UUID) (*domain.ReviewResult, error) {
+	switch action {
+	case domain.ReviewAccept:
+		if categoryID != nil {
+			return nil, domain.ErrReviewCategoryForAccept
+		}
+	case domain.ReviewRecategorize:
+		if categoryID == nil {
+			return nil, domain.ErrReviewCategoryRequired
+		}
+	default:
+		return nil, domain.ErrInvalidReviewAction
+	}
+	transactionIDs = uniqueIDs(transactionIDs)
+	if len(transactionIDs) == 0 {
+		return nil, domain.ErrReviewEmpty
+	}
+	if len(transactionIDs) > domain.MaxReviewItems {
+		return nil, domain.ErrReviewTooManyItems
+	}
+
+	result := &domain.ReviewResult{Skipped: make([]uuid.UUID, 0)}
+	err := uc.txManager.RunInTransaction(ctx, func(ctx context.Context) error {
+		transactions, err := uc.transRepo.GetTransactionsByIDs(ctx, userID, transactionIDs)
+		if err != nil {
+			return fmt.Errorf("failed to fetch transactions: %w", err)
+		}
+		split, err := uc.transRepo.GetSplitTransactionIDs(ctx, userID, transactionIDs)
+		if err != nil {
+			return err
+		}
+
+		found := make(map[uuid.UUID]bool, len(transactions))
+		eligible := make([]domain.Transaction, 0, len(transactions))
+		for _, t := range transactions {
+			found[t.TransactionID] = true
+			if t.TransferID != nil || split[t.TransactionID] {
+				result.Skipped = append(result.Skipped, t.TransactionID)
+				continue
+			}
+			eligible = append(eligible, t)
+		}
+		for _, id := range transactionIDs {
+			if !found[id] {
+				result.Skipped = append(result.Skipped, id)
+			}
+		}
+		if len(eligible) == 0 {
+			return nil
+		}
+
+		ids := make([]uuid.UUID, len(eligible))
+		for i, t := range eligible {
+			ids[i] = t.TransactionID
+		}
+		if action == domain.ReviewRecategorize {
+			err = uc.transRepo.SetReviewedCategory(ctx, userID, ids, *categoryID)
+		} else {
+			err = uc.transRepo.AcceptCategories(ctx, userID, ids)
+		}
+		if err != nil {
+			return err
+		}
+
+		for _, t := range eligible {
+			learned := t.CategoryID
+			if action == domain.ReviewRecategorize {
+				learned = categoryID
+			}
+			if !t.IsImported || learned == nil {
+				continue
+			}
+			if err := uc.transRepo.UpsertAutoCategoryRule(ctx, userID, t.IsIncome, t.MCCCode, t.NameTransaction, *learned); err != nil {
+				return fmt.Errorf("failed to save auto-category rule: %w", err)
+			}
+		}
+		result.Updated = len(eligible)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func uniqueIDs(ids []uuid.UUID) []uuid.UUID {
+	seen := make(map[uuid.UUID]bool, len(ids))
+	out := make([]uuid.UUID, 0, len(ids))
+	for _, id := range ids {
+		if id == uuid.Nil || seen[id] {
+			continue
+		}
+		seen[id] = true
+		out = append(out, id)
+	}
+	return out
+}
@@ -0,0 +1,89 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+
+	transactionDomain "Finance-Manager-System/internal/infrastructure/modules/transactions/domain"
+)
+
+func TestResolveReview(t *testing.T) {
+	userID := uuid.New()
+	otherID, foodID := uuid.New(), uuid.New()
+	transferID := uuid.New()
+
+	imported := &transactionDomain.Transaction{TransactionID: uuid.New(), UserID: userID, CategoryID: &otherID, CategorySource: transactionDomain.SourceFallback, IsImported: true, NameTransaction: "Bakery"}
+	manual := &transactionDomain.Transaction{TransactionID: uuid.New(), UserID: userID, NameTransaction: "Cash"}
+	transfer := &transactionDomain.Transaction{TransactionID: uuid.New(), UserID: userID, TransferID: &transferID}
+	split := &transactionDomain.Transaction{TransactionID: uuid.New(), UserID: userID, IsImported: true}
+	repo := &fakeTransRepo{
+		byID: map[uuid.UUID]*transactionDomain.Transaction{
+			imported.TransactionID: imported,
+			manual.TransactionID:   manual,
+			transfer.TransactionID: transfer,
+			split.TransactionID:    split,
+		},
+		splits: map[uuid.UUID][]transactionDomain.TransactionSplit{split.TransactionID: {{}, {}}},
+	}
+	uc := NewTransactionUseCase(repo, &fakeBalanceUpdater{}, nil, &fakeTransTxManager{})
+
+	if _, err := uc.ResolveReview(context.Background(), userID, []uuid.UUID{imported.TransactionID}, transactionDomain.ReviewRecategorize, nil); err != transactionDomain.ErrReviewCategoryRequired {
+		t.Fatalf("expected ErrReviewCategoryRequired, got %v", err)
+	}
+	if _, err := uc.ResolveReview(context.Background(), userID, nil, transactionDomain.ReviewAccept, nil); err != transactionDomain.ErrReviewEmpty {
+		t.Fatalf("expected ErrReviewEmpty, got %v", err)
+	}
+	if _, err := uc.ResolveReview(context.Background(), userID, []uuid.UUID{imported.TransactionID}, "approve", nil); err != transactionDomain.ErrInvalidReviewAction {
+		t.Fatalf("expected ErrInvalidReviewAction, got %v", err)
+	}
+
+	missing := uuid.New()
+	ids := []uuid.UUID{imported.TransactionID, manual.TransactionID, transfer.TransactionID, split.TransactionID, missing, imported.TransactionID}
+	result, err := uc.ResolveReview(context.Background(), userID, ids, transactionDomain.ReviewRecategorize, &foodID)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if result.Updated != 2 || len(result.Skipped) != 3 {
+		t.Fatalf("unexpected review result: %+v", result)
+	}
+	if *imported.CategoryID != foodID || imported.CategorySource != transactionDomain.SourceManual {
+		t.Fatalf("imported transaction must get the manual category, got %+v", imported)
+	}
+	if repo.upsertRulesCount != 1 {
+		t.Fatalf("only the imported transaction must be learned, got %d rules", repo.upsertRulesCount)
+	}
+
+	result, err = uc.ResolveReview(context.Background(), userID, []uuid.UUID{manual.TransactionID}, transactionDomain.ReviewAccept, nil)
+	if err != nil || result.Updated != 1 {
+		t.Fatalf("expected accepted transaction, got %+v %v", result, err)
+	}
+	if repo.upsertRulesCount != 1 {
+		t.Fatalf("accepting a manual transaction must not learn a rule")
+	}
+}
+
+func TestUpdateTransactionMarksManualCategory(t *testing.T) {
+	userID := uuid.New()
+	oldCategory, newCategory := uuid.New(), uuid.New()
+	confidence := 0.7
+	trans := &transactionDomain.Transaction{
+		TransactionID:      uuid.New(),
+		UserID:             userID,
+		CategoryID:         &oldCategory,
+		CategorySource:     transactionDomain.SourceClassifier,
+		CategoryConfidence: &confidence,
+		IsImported:         true,
+		NameTransaction:    "Coffee",
+	}
+	repo := &fakeTransRepo{byID: map[uuid.UUID]*transactionDomain.Transaction{trans.TransactionID: trans}}
+	uc := NewTransactionUseCase(repo, &fakeBalanceUpdater{}, nil, &fakeTransTxManager{})
+
+	if err := uc.UpdateImportedTransactionMeta(context.Background(), userID, trans.TransactionID, &newCategory, nil, nil); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if trans.CategorySource != transactionDomain.SourceManual || trans.CategoryConfidence != nil {
+		t.Fatalf("corrected category must become manual, got %+v", trans)
+	}
+}
@@ -0,0 +1,49 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"Finance-Manager-System/internal/infrastructure/modules/transactions/domain"
+)
+
+// SplitTransaction replaces the parts of a transaction. The parts must add up
+// to the transaction amount; the balance is not touched.
+func (uc *TransactionUseCase) SplitTransaction(ctx context.Context, userID uuid.UUID, transactionID uuid.UUID, parts []domain.TransactionSplit) ([]domain.TransactionSplit, error) {
+	var splits []domain.TransactionSplit
+	err := uc.txManager.RunInTransaction(ctx, func(ctx context.Context) error {
+		trans, err := uc.transRepo.GetTransaction(ctx, userID, transactionID)
+		if err != nil {
+			return err
+		}
+		splits, err = domain.NewTransactionSplits(trans, parts)
+		if err != nil {
+			return err
+		}
+		if err := uc.transRepo.ReplaceSplits(ctx, userID, transactionID, splits); err != nil {
+			return fmt.Errorf("failed to save transaction splits: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return splits, nil
+}
+
+func (uc *TransactionUseCase) GetSplits(ctx context.Context, userID uuid.UUID, transactionID uuid.UUID) ([]domain.TransactionSplit, error) {
+	if _, err := uc.transRepo.GetTransaction(ctx, userID, transactionID); err != nil {
+		return nil, err
+	}
+	return uc.transRepo.GetSplits(ctx, userID, transactionID)
+}
+
+// RemoveSplits turns a split transaction back into a single-category one.
+func (uc *TransactionUseCase) RemoveSplits(ctx context.Context, userID uuid.UUID, transactionID uuid.UUID) error {
+	if _, err := uc.transRepo.GetTransaction(ctx, userID, transactionID); err != nil {
+		return err
+	}
+	return uc.transRepo.DeleteSplits(ctx, userID, transactionID)
+}
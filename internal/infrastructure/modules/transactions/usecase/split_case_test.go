@@ -0,0 +1,68 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	transactionDomain "Finance-Manager-System/internal/infrastructure/modules/transactions/domain"
+)
+
+func TestSplitTransactionKeepsBalanceAndLocksAmount(t *testing.T) {
+	userID := uuid.New()
+	txID := uuid.New()
+	groceries, household := uuid.New(), uuid.New()
+	repo := &fakeTransRepo{
+		byID: map[uuid.UUID]*transactionDomain.Transaction{
+			txID: {
+				TransactionID:   txID,
+				UserID:          userID,
+				AccountID:       uuid.New(),
+				NameTransaction: "Supermarket",
+				Amount:          5000,
+				CompletedAt:     time.Now().UTC(),
+				Currency:        "RUB",
+				Status:          "completed",
+			},
+		},
+	}
+	balance := &fakeBalanceUpdater{}
+	uc := NewTransactionUseCase(repo, balance, nil, &fakeTransTxManager{})
+
+	_, err := uc.SplitTransaction(context.Background(), userID, txID, []transactionDomain.TransactionSplit{
+		{CategoryID: &groceries, Amount: 3000},
+		{CategoryID: &household, Amount: 1000},
+	})
+	if err != transactionDomain.ErrSplitSumMismatch {
+		t.Fatalf("expected ErrSplitSumMismatch, got %v", err)
+	}
+
+	splits, err := uc.SplitTransaction(context.Background(), userID, txID, []transactionDomain.TransactionSplit{
+		{CategoryID: &groceries, Amount: 3500},
+		{CategoryID: &household, Amount: 1500},
+	})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if len(splits) != 2 || splits[1].Position != 1 || splits[0].TransactionID != txID {
+		t.Fatalf("unexpected splits: %+v", splits)
+	}
+	if len(balance.calls) != 0 {
+		t.Fatalf("splitting must not change the balance, got %v", balance.calls)
+	}
+
+	trans := repo.byID[txID]
+	err = uc.UpdateTransaction(context.Background(), userID, txID, nil, trans.NameTransaction, false, 6000, trans.CompletedAt, nil, "RUB", 0, "completed")
+	if err != transactionDomain.ErrTransactionIsSplit {
+		t.Fatalf("expected ErrTransactionIsSplit, got %v", err)
+	}
+
+	if err := uc.RemoveSplits(context.Background(), userID, txID); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if err := uc.UpdateTransaction(context.Background(), userID, txID, nil, trans.NameTransaction, false, 6000, trans.CompletedAt, nil, "RUB", 0, "completed"); err != nil {
+		t.Fatalf("expected amount change after removing the split, got %v", err)
+	}
+}
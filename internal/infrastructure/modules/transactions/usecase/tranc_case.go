@@ -10,6 +10,7 @@ import (
 	"github.com/google/uuid"
 
 	"Finance-Manager-System/internal/infrastructure/database"
+	tagDomain "Finance-Manager-System/internal/infrastructure/modules/tags/domain"
 	"Finance-Manager-System/internal/infrastructure/modules/transactions/domain"
 )
 
@@ -20,27 +21,51 @@ type TransactionRepository interface {
 	DeleteTransaction(ctx context.Context, userID uuid.UUID, transactionID uuid.UUID) error
 	GetAllTransactions(ctx context.Context, userID uuid.UUID) ([]domain.Transaction, error)
 	GetTransactionsWithFilter(ctx context.Context, userID uuid.UUID, filter domain.TransactionFilter) ([]domain.Transaction, error)
+	SearchTransactions(ctx context.Context, userID uuid.UUID, search domain.TransactionSearch) (*domain.TransactionPage, error)
+	GetSplits(ctx context.Context, userID uuid.UUID, transactionID uuid.UUID) ([]domain.TransactionSplit, error)
+	GetSplitTransactionIDs(ctx context.Context, userID uuid.UUID, transactionIDs []uuid.UUID) (map[uuid.UUID]bool, error)
+	ReplaceSplits(ctx context.Context, userID uuid.UUID, transactionID uuid.UUID, splits []domain.TransactionSplit) error
+	DeleteSplits(ctx context.Context, userID uuid.UUID, transactionID uuid.UUID) error
 	ShowTransactions(ctx context.Context, userID uuid.UUID, transactionIds []uuid.UUID) error
 	HideTransactions(ctx context.Context, userID uuid.UUID, transactionIds []uuid.UUID) error
 	GetTransactionsByIDs(ctx context.Context, userID uuid.UUID, transactionIDs []uuid.UUID) ([]domain.Transaction, error)
 	ResolveAutoCategoryID(ctx context.Context, userID uuid.UUID, isIncome bool, mccCode *string, description string) (*uuid.UUID, error)
 	UpsertAutoCategoryRule(ctx context.Context, userID uuid.UUID, isIncome bool, mccCode *string, description string, categoryID uuid.UUID) error
+	SetReviewedCategory(ctx context.Context, userID uuid.UUID, transactionIDs []uuid.UUID, categoryID uuid.UUID) error
+	AcceptCategories(ctx context.Context, userID uuid.UUID, transactionIDs []uuid.UUID) error
+	SetTransactionsComment(ctx context.Context, userID uuid.UUID, transactionIDs []uuid.UUID, comment *string) error
+	MoveTransactions(ctx context.Context, userID uuid.UUID, transactionIDs []uuid.UUID, accountID uuid.UUID) error
+	DeleteTransactions(ctx context.Context, userID uuid.UUID, transactionIDs []uuid.UUID) error
+	AddTransfer(ctx context.Context, transfer *domain.Transfer) error
+	GetTransfer(ctx context.Context, userID uuid.UUID, transferID uuid.UUID) (*domain.Transfer, error)
+	UpdateTransfer(ctx context.Context, transfer *domain.Transfer) error
+	DeleteTransfer(ctx context.Context, userID uuid.UUID, transferID uuid.UUID) error
+	GetTransferLegs(ctx context.Context, userID uuid.UUID, transferID uuid.UUID) ([]domain.Transaction, error)
 }
 
 type AccountBalanceUpdater interface {
 	UpdateBalance(ctx context.Context, userID uuid.UUID, accountID uuid.UUID, amountDelta int64) error
+	GetAccountCurrency(ctx context.Context, userID uuid.UUID, accountID uuid.UUID) (string, error)
+}
+
+type TransactionTagRepository interface {
+	GetTagByID(ctx context.Context, userID uuid.UUID, tagID uuid.UUID) (*tagDomain.Tag, error)
+	AddTagsToTransactions(ctx context.Context, userID uuid.UUID, transactionIDs []uuid.UUID, tagIDs []uuid.UUID) (int, error)
+	RemoveTagsFromTransactions(ctx context.Context, userID uuid.UUID, transactionIDs []uuid.UUID, tagIDs []uuid.UUID) (int, error)
 }
 
 type TransactionUseCase struct {
 	transRepo   TransactionRepository
 	accountRepo AccountBalanceUpdater
+	tagRepo     TransactionTagRepository
 	txManager   database.TxManager
 }
 
-func NewTransactionUseCase(tr TransactionRepository, ar AccountBalanceUpdater, tm database.TxManager) *TransactionUseCase {
+func NewTransactionUseCase(tr TransactionRepository, ar AccountBalanceUpdater, tagRepo TransactionTagRepository, tm database.TxManager) *TransactionUseCase {
 	return &TransactionUseCase{
 		transRepo:   tr,
 		accountRepo: ar,
+		tagRepo:     tagRepo,
 		txManager:   tm,
 	}
 }
@@ -50,9 +75,13 @@ func (uc *TransactionUseCase) CreateManualTransaction(ctx context.Context, userI
 	if err != nil {
 		return fmt.Errorf("validation failed: %w", err)
 	}
-	if currency != "" {
-		trans.Currency = currency
+	if currency == "" {
+		currency, err = uc.accountRepo.GetAccountCurrency(ctx, userID, accountID)
+		if err != nil {
+			return fmt.Errorf("failed to resolve account currency: %w", err)
+		}
 	}
+	trans.Currency = strings.ToUpper(strings.TrimSpace(currency))
 	if status != "" {
 		trans.Status = status
 	}
@@ -83,6 +112,24 @@ func (uc *TransactionUseCase) UpdateTransaction(ctx context.Context, userID, tra
 			return fmt.Errorf("failed to fetch transaction: %w", err)
 		}
 
+		if oldTrans.TransferID != nil {
+			if categoryID != nil || isIncome != oldTrans.IsIncome {
+				return domain.ErrTransferLegChange
+			}
+			if amount <= 0 {
+				return domain.ErrTransInvalidAmount
+			}
+			fromAmount, toAmount := amount, int64(0)
+			if oldTrans.IsIncome {
+				fromAmount, toAmount = 0, amount
+			}
+			return uc.updateTransfer(ctx, userID, *oldTrans.TransferID, name, fromAmount, toAmount, completedAt, comment)
+		}
+
+		if oldTrans.IsAdjustment && (amount != oldTrans.Amount || isIncome != oldTrans.IsIncome || !completedAt.Equal(oldTrans.CompletedAt)) {
+			return domain.ErrAdjustmentChange
+		}
+
 		if oldTrans.IsImported {
 			nextCurrency := oldTrans.Currency
 			if currency != "" {
@@ -103,10 +150,19 @@ func (uc *TransactionUseCase) UpdateTransaction(ctx context.Context, userID, tra
 			if amount <= 0 {
 				return domain.ErrTransInvalidAmount
 			}
+			if amount != oldTrans.Amount {
+				splits, err := uc.transRepo.GetSplits(ctx, userID, transID)
+				if err != nil {
+					return err
+				}
+				if len(splits) > 0 {
+					return domain.ErrTransactionIsSplit
+				}
+			}
 		}
 
 		var balanceDelta int64 = 0
-		if !oldTrans.IsImported && !oldTrans.IsHidden {
+		if !oldTrans.IsImported && !oldTrans.IsHidden && !oldTrans.IsAdjustment {
 			oldDelta := oldTrans.Amount
 			if !oldTrans.IsIncome {
 				oldDelta = -oldTrans.Amount
@@ -120,6 +176,10 @@ func (uc *TransactionUseCase) UpdateTransaction(ctx context.Context, userID, tra
 			balanceDelta = newDelta - oldDelta
 		}
 
+		if !sameCategory(oldTrans.CategoryID, categoryID) {
+			oldTrans.CategorySource = domain.SourceManual
+			oldTrans.CategoryConfidence = nil
+		}
 		oldTrans.CategoryID = categoryID
 		oldTrans.NameTransaction = name
 		oldTrans.IsIncome = isIncome
@@ -173,6 +233,8 @@ func (uc *TransactionUseCase) UpdateImportedTransactionMeta(
 
 		if categoryID != nil {
 			trans.CategoryID = categoryID
+			trans.CategorySource = domain.SourceManual
+			trans.CategoryConfidence = nil
 			if upsertErr := uc.transRepo.UpsertAutoCategoryRule(txCtx, userID, trans.IsIncome, trans.MCCCode, trans.NameTransaction, *categoryID); upsertErr != nil {
 				return fmt.Errorf("failed to save auto-category rule: %w", upsertErr)
 			}
@@ -227,12 +289,17 @@ func (uc *TransactionUseCase) DeleteManualTransaction(ctx context.Context, userI
 		if trans.IsImported {
 			return domain.ErrCannotModifyImported
 		}
+		if trans.TransferID != nil {
+			return uc.deleteTransfer(ctx, userID, *trans.TransferID)
+		}
 
 		if err := uc.transRepo.DeleteTransaction(ctx, userID, transactionID); err != nil {
 			return fmt.Errorf("failed to delete transaction: %w", err)
 		}
 
-		if !trans.IsHidden {
+		// An adjustment only brought the ledger in line with the stored
+		// balance, so removing it leaves the balance as it is.
+		if !trans.IsHidden && !trans.IsAdjustment {
 			delta := trans.Amount
 			if trans.IsIncome {
 				delta = -trans.Amount
@@ -258,6 +325,48 @@ func (uc *TransactionUseCase) GetUserTransactions(ctx context.Context, userID uu
 	return transactions, nil
 }
 
+// SearchTransactions returns a page of the filtered transactions. A zero limit
+// means the default page size.
+func (uc *TransactionUseCase) SearchTransactions(ctx context.Context, userID uuid.UUID, search domain.TransactionSearch) (*domain.TransactionPage, error) {
+	if userID == uuid.Nil {
+		return nil, domain.ErrTransEmptyUserID
+	}
+	if search.Limit == 0 {
+		search.Limit = domain.DefaultSearchLimit
+	}
+	if search.Limit < 0 || search.Limit > domain.MaxSearchLimit {
+		return nil, domain.ErrInvalidSearchLimit
+	}
+	if search.Sort == "" {
+		search.Sort = domain.SortDateDesc
+	}
+
+	filter := &search.Filter
+	if filter.MinAmount != nil && filter.MaxAmount != nil && *filter.MinAmount > *filter.MaxAmount {
+		return nil, domain.ErrInvalidAmountRange
+	}
+	if filter.Uncategorized && filter.CategoryID != nil {
+		return nil, domain.ErrConflictingCategory
+	}
+	filter.Query = strings.TrimSpace(filter.Query)
+	for i, currency := range filter.Currencies {
+		filter.Currencies[i] = strings.ToUpper(strings.TrimSpace(currency))
+	}
+
+	page, err := uc.transRepo.SearchTransactions(ctx, userID, search)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search transactions: %w", err)
+	}
+	return page, nil
+}
+
+func sameCategory(a, b *uuid.UUID) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
 func sortTransactionsDesc(transactions []domain.Transaction) {
 	sort.SliceStable(transactions, func(i, j int) bool {
 		left := transactions[i]
@@ -269,6 +378,34 @@ func sortTransactionsDesc(transactions []domain.Transaction) {
 	})
 }
 
+// withTransferLegs adds the other leg of every transfer among transactions,
+// so a transfer is never half hidden.
+func (uc *TransactionUseCase) withTransferLegs(ctx context.Context, userID uuid.UUID, transactions []domain.Transaction) ([]domain.Transaction, error) {
+	seen := make(map[uuid.UUID]bool, len(transactions))
+	for _, t := range transactions {
+		seen[t.TransactionID] = true
+	}
+	result := transactions
+	transfers := make(map[uuid.UUID]bool)
+	for _, t := range transactions {
+		if t.TransferID == nil || transfers[*t.TransferID] {
+			continue
+		}
+		transfers[*t.TransferID] = true
+		legs, err := uc.transRepo.GetTransferLegs(ctx, userID, *t.TransferID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch transfer legs: %w", err)
+		}
+		for _, leg := range legs {
+			if !seen[leg.TransactionID] {
+				seen[leg.TransactionID] = true
+				result = append(result, leg)
+			}
+		}
+	}
+	return result, nil
+}
+
 func (uc *TransactionUseCase) ToggleTransactionsVisibility(ctx context.Context, userID uuid.UUID, transactionIDs []uuid.UUID, hide bool) error {
 	if len(transactionIDs) == 0 {
 		return nil
@@ -278,6 +415,10 @@ func (uc *TransactionUseCase) ToggleTransactionsVisibility(ctx context.Context,
 	if err != nil {
 		return fmt.Errorf("failed to fetch transactions: %w", err)
 	}
+	transactions, err = uc.withTransferLegs(ctx, userID, transactions)
+	if err != nil {
+		return err
+	}
 
 	accountDeltas := make(map[uuid.UUID]int64)
 	var idsToUpdate []uuid.UUID
@@ -288,6 +429,9 @@ func (uc *TransactionUseCase) ToggleTransactionsVisibility(ctx context.Context,
 		}
 
 		idsToUpdate = append(idsToUpdate, t.TransactionID)
+		if t.IsAdjustment {
+			continue
+		}
 		var delta int64
 
 		if hide {
@@ -2,6 +2,7 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
@@ -14,6 +15,9 @@ type fakeTransRepo struct {
 	byID             map[uuid.UUID]*transactionDomain.Transaction
 	filtered         []transactionDomain.Transaction
 	upsertRulesCount int
+	transfers        map[uuid.UUID]*transactionDomain.Transfer
+	searched         *transactionDomain.TransactionSearch
+	splits           map[uuid.UUID][]transactionDomain.TransactionSplit
 }
 
 func (f *fakeTransRepo) GetTransaction(ctx context.Context, userID uuid.UUID, transactionID uuid.UUID) (*transactionDomain.Transaction, error) {
@@ -47,14 +51,48 @@ func (f *fakeTransRepo) GetAllTransactions(ctx context.Context, userID uuid.UUID
 func (f *fakeTransRepo) GetTransactionsWithFilter(ctx context.Context, userID uuid.UUID, filter transactionDomain.TransactionFilter) ([]transactionDomain.Transaction, error) {
 	return f.filtered, nil
 }
+func (f *fakeTransRepo) SearchTransactions(ctx context.Context, userID uuid.UUID, search transactionDomain.TransactionSearch) (*transactionDomain.TransactionPage, error) {
+	f.searched = &search
+	return &transactionDomain.TransactionPage{Items: f.filtered}, nil
+}
+func (f *fakeTransRepo) GetSplits(ctx context.Context, userID uuid.UUID, transactionID uuid.UUID) ([]transactionDomain.TransactionSplit, error) {
+	return f.splits[transactionID], nil
+}
+func (f *fakeTransRepo) ReplaceSplits(ctx context.Context, userID uuid.UUID, transactionID uuid.UUID, splits []transactionDomain.TransactionSplit) error {
+	if f.splits == nil {
+		f.splits = make(map[uuid.UUID][]transactionDomain.TransactionSplit)
+	}
+	f.splits[transactionID] = splits
+	return nil
+}
+func (f *fakeTransRepo) DeleteSplits(ctx context.Context, userID uuid.UUID, transactionID uuid.UUID) error {
+	delete(f.splits, transactionID)
+	return nil
+}
 func (f *fakeTransRepo) ShowTransactions(ctx context.Context, userID uuid.UUID, transactionIds []uuid.UUID) error {
+	for _, id := range transactionIds {
+		if tx, ok := f.byID[id]; ok {
+			tx.IsHidden = false
+		}
+	}
 	return nil
 }
 func (f *fakeTransRepo) HideTransactions(ctx context.Context, userID uuid.UUID, transactionIds []uuid.UUID) error {
+	for _, id := range transactionIds {
+		if tx, ok := f.byID[id]; ok {
+			tx.IsHidden = true
+		}
+	}
 	return nil
 }
 func (f *fakeTransRepo) GetTransactionsByIDs(ctx context.Context, userID uuid.UUID, transactionIDs []uuid.UUID) ([]transactionDomain.Transaction, error) {
-	return nil, nil
+	out := make([]transactionDomain.Transaction, 0, len(transactionIDs))
+	for _, id := range transactionIDs {
+		if tx, ok := f.byID[id]; ok {
+			out = append(out, *tx)
+		}
+	}
+	return out, nil
 }
 func (f *fakeTransRepo) ResolveAutoCategoryID(ctx context.Context, userID uuid.UUID, isIncome bool, mccCode *string, description string) (*uuid.UUID, error) {
 	return nil, nil
@@ -63,6 +101,87 @@ func (f *fakeTransRepo) UpsertAutoCategoryRule(ctx context.Context, userID uuid.
 	f.upsertRulesCount++
 	return nil
 }
+func (f *fakeTransRepo) SetReviewedCategory(ctx context.Context, userID uuid.UUID, transactionIDs []uuid.UUID, categoryID uuid.UUID) error {
+	for _, id := range transactionIDs {
+		category := categoryID
+		f.byID[id].CategoryID = &category
+		f.byID[id].CategorySource = transactionDomain.SourceManual
+	}
+	return nil
+}
+func (f *fakeTransRepo) AcceptCategories(ctx context.Context, userID uuid.UUID, transactionIDs []uuid.UUID) error {
+	for _, id := range transactionIDs {
+		f.byID[id].CategorySource = transactionDomain.SourceManual
+	}
+	return nil
+}
+func (f *fakeTransRepo) SetTransactionsComment(ctx context.Context, userID uuid.UUID, transactionIDs []uuid.UUID, comment *string) error {
+	for _, id := range transactionIDs {
+		f.byID[id].Comment = comment
+	}
+	return nil
+}
+func (f *fakeTransRepo) MoveTransactions(ctx context.Context, userID uuid.UUID, transactionIDs []uuid.UUID, accountID uuid.UUID) error {
+	for _, id := range transactionIDs {
+		f.byID[id].AccountID = accountID
+	}
+	return nil
+}
+func (f *fakeTransRepo) DeleteTransactions(ctx context.Context, userID uuid.UUID, transactionIDs []uuid.UUID) error {
+	for _, id := range transactionIDs {
+		delete(f.byID, id)
+	}
+	return nil
+}
+func (f *fakeTransRepo) GetSplitTransactionIDs(ctx context.Context, userID uuid.UUID, transactionIDs []uuid.UUID) (map[uuid.UUID]bool, error) {
+	split := make(map[uuid.UUID]bool)
+	for _, id := range transactionIDs {
+		if len(f.splits[id]) > 0 {
+			split[id] = true
+		}
+	}
+	return split, nil
+}
+
+func (f *fakeTransRepo) AddTransfer(ctx context.Context, transfer *transactionDomain.Transfer) error {
+	if f.transfers == nil {
+		f.transfers = make(map[uuid.UUID]*transactionDomain.Transfer)
+	}
+	f.transfers[transfer.TransferID] = transfer
+	return nil
+}
+func (f *fakeTransRepo) GetTransfer(ctx context.Context, userID uuid.UUID, transferID uuid.UUID) (*transactionDomain.Transfer, error) {
+	transfer, ok := f.transfers[transferID]
+	if !ok {
+		return nil, transactionDomain.ErrTransferNotFound
+	}
+	return transfer, nil
+}
+func (f *fakeTransRepo) UpdateTransfer(ctx context.Context, transfer *transactionDomain.Transfer) error {
+	f.transfers[transfer.TransferID] = transfer
+	return nil
+}
+func (f *fakeTransRepo) DeleteTransfer(ctx context.Context, userID uuid.UUID, transferID uuid.UUID) error {
+	if _, ok := f.transfers[transferID]; !ok {
+		return transactionDomain.ErrTransferNotFound
+	}
+	delete(f.transfers, transferID)
+	for id, tx := range f.byID {
+		if tx.TransferID != nil && *tx.TransferID == transferID {
+			delete(f.byID, id)
+		}
+	}
+	return nil
+}
+func (f *fakeTransRepo) GetTransferLegs(ctx context.Context, userID uuid.UUID, transferID uuid.UUID) ([]transactionDomain.Transaction, error) {
+	legs := make([]transactionDomain.Transaction, 0, 2)
+	for _, tx := range f.byID {
+		if tx.TransferID != nil && *tx.TransferID == transferID {
+			legs = append(legs, *tx)
+		}
+	}
+	return legs, nil
+}
 
 type fakeBalanceUpdater struct {
 	calls []int64
@@ -73,6 +192,10 @@ func (f *fakeBalanceUpdater) UpdateBalance(ctx context.Context, userID uuid.UUID
 	return nil
 }
 
+func (f *fakeBalanceUpdater) GetAccountCurrency(ctx context.Context, userID uuid.UUID, accountID uuid.UUID) (string, error) {
+	return "RUB", nil
+}
+
 type fakeTransTxManager struct{}
 
 func (f *fakeTransTxManager) RunInTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
@@ -101,7 +224,7 @@ func TestUpdateImportedTransactionMeta(t *testing.T) {
 		},
 	}
 	balance := &fakeBalanceUpdater{}
-	uc := NewTransactionUseCase(repo, balance, &fakeTransTxManager{})
+	uc := NewTransactionUseCase(repo, balance, nil, &fakeTransTxManager{})
 	comment := "manual"
 	hide := true
 	catID := uuid.New()
@@ -147,7 +270,7 @@ func TestUpdateTransactionRejectsImportedCoreFields(t *testing.T) {
 			},
 		},
 	}
-	uc := NewTransactionUseCase(repo, &fakeBalanceUpdater{}, &fakeTransTxManager{})
+	uc := NewTransactionUseCase(repo, &fakeBalanceUpdater{}, nil, &fakeTransTxManager{})
 	err := uc.UpdateTransaction(context.Background(), userID, txID, nil, "Salary", true, 2000, repo.byID[txID].CompletedAt, nil, "RUB", 0, "completed")
 	if err == nil {
 		t.Fatalf("expected error")
@@ -178,7 +301,7 @@ func TestGetUserTransactionsSortsByCompletedAtDesc(t *testing.T) {
 			},
 		},
 	}
-	uc := NewTransactionUseCase(repo, &fakeBalanceUpdater{}, &fakeTransTxManager{})
+	uc := NewTransactionUseCase(repo, &fakeBalanceUpdater{}, nil, &fakeTransTxManager{})
 	got, err := uc.GetUserTransactions(context.Background(), userID, transactionDomain.TransactionFilter{})
 	if err != nil {
 		t.Fatalf("expected nil error, got %v", err)
@@ -190,3 +313,79 @@ func TestGetUserTransactionsSortsByCompletedAtDesc(t *testing.T) {
 		t.Fatalf("unexpected order: %s, %s, %s", got[0].TransactionID, got[1].TransactionID, got[2].TransactionID)
 	}
 }
+
+func TestSearchTransactionsValidatesAndNormalizes(t *testing.T) {
+	userID := uuid.New()
+	repo := &fakeTransRepo{}
+	uc := NewTransactionUseCase(repo, &fakeBalanceUpdater{}, nil, &fakeTransTxManager{})
+
+	search := transactionDomain.TransactionSearch{
+		Filter: transactionDomain.TransactionFilter{Query: "  coffee ", Currencies: []string{"usd"}},
+	}
+	if _, err := uc.SearchTransactions(context.Background(), userID, search); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if repo.searched.Limit != transactionDomain.DefaultSearchLimit || repo.searched.Sort != transactionDomain.SortDateDesc {
+		t.Fatalf("expected defaults, got limit %d sort %q", repo.searched.Limit, repo.searched.Sort)
+	}
+	if repo.searched.Filter.Query != "coffee" || repo.searched.Filter.Currencies[0] != "USD" {
+		t.Fatalf("expected normalized filter, got %+v", repo.searched.Filter)
+	}
+
+	minAmount, maxAmount := int64(500), int64(100)
+	search.Filter.MinAmount, search.Filter.MaxAmount = &minAmount, &maxAmount
+	if _, err := uc.SearchTransactions(context.Background(), userID, search); err != transactionDomain.ErrInvalidAmountRange {
+		t.Fatalf("expected ErrInvalidAmountRange, got %v", err)
+	}
+
+	search.Filter.MinAmount, search.Filter.MaxAmount = nil, nil
+	search.Limit = transactionDomain.MaxSearchLimit + 1
+	if _, err := uc.SearchTransactions(context.Background(), userID, search); err != transactionDomain.ErrInvalidSearchLimit {
+		t.Fatalf("expected ErrInvalidSearchLimit, got %v", err)
+	}
+}
+
+func TestBalanceAdjustmentLeavesStoredBalance(t *testing.T) {
+	userID := uuid.New()
+	accountID := uuid.New()
+	hiddenID := uuid.New()
+	deletedID := uuid.New()
+	newAdjustmentRow := func(id uuid.UUID) *transactionDomain.Transaction {
+		return &transactionDomain.Transaction{
+			TransactionID:   id,
+			UserID:          userID,
+			AccountID:       accountID,
+			NameTransaction: "Корректировка баланса",
+			IsIncome:        true,
+			Amount:          5000,
+			CompletedAt:     time.Now().UTC(),
+			Currency:        "RUB",
+			IsAdjustment:    true,
+		}
+	}
+	repo := &fakeTransRepo{
+		byID: map[uuid.UUID]*transactionDomain.Transaction{
+			hiddenID:  newAdjustmentRow(hiddenID),
+			deletedID: newAdjustmentRow(deletedID),
+		},
+	}
+	balance := &recordingBalanceUpdater{}
+	uc := NewTransactionUseCase(repo, balance, nil, &fakeTransTxManager{})
+
+	err := uc.UpdateTransaction(context.Background(), userID, deletedID, nil, "Корректировка баланса", true, 7000, repo.byID[deletedID].CompletedAt, nil, "", 0, "")
+	if !errors.Is(err, transactionDomain.ErrAdjustmentChange) {
+		t.Fatalf("expected ErrAdjustmentChange, got %v", err)
+	}
+	if err := uc.ToggleTransactionsVisibility(context.Background(), userID, []uuid.UUID{hiddenID}, true); err != nil {
+		t.Fatalf("hide adjustment: %v", err)
+	}
+	if err := uc.DeleteManualTransaction(context.Background(), userID, deletedID); err != nil {
+		t.Fatalf("delete adjustment: %v", err)
+	}
+	if _, ok := repo.byID[deletedID]; ok {
+		t.Fatalf("adjustment must be deleted")
+	}
+	if balance.deltas[accountID] != 0 {
+		t.Fatalf("stored balance must stay as it is, got delta %d", balance.deltas[accountID])
+	}
+}
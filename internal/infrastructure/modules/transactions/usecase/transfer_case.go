@@ -0,0 +1,192 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"Finance-Manager-System/internal/infrastructure/modules/transactions/domain"
+)
+
+func (uc *TransactionUseCase) CreateTransfer(ctx context.Context, userID, fromAccountID, toAccountID uuid.UUID, name string, amount int64, toAmount int64, completedAt time.Time, comment *string) (*domain.Transfer, error) {
+	transfer, err := domain.NewTransfer(userID, fromAccountID, toAccountID, amount, toAmount, completedAt, comment)
+	if err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+	outgoing, incoming, err := transfer.Legs(name)
+	if err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	err = uc.txManager.RunInTransaction(ctx, func(ctx context.Context) error {
+		fromCurrency, err := uc.accountRepo.GetAccountCurrency(ctx, userID, fromAccountID)
+		if err != nil {
+			return fmt.Errorf("failed to resolve source account: %w", err)
+		}
+		toCurrency, err := uc.accountRepo.GetAccountCurrency(ctx, userID, toAccountID)
+		if err != nil {
+			return fmt.Errorf("failed to resolve destination account: %w", err)
+		}
+		if err := domain.CheckTransferAmounts(fromCurrency, toCurrency, amount, toAmount); err != nil {
+			return err
+		}
+		outgoing.Currency = fromCurrency
+		incoming.Currency = toCurrency
+
+		if err := uc.transRepo.AddTransfer(ctx, transfer); err != nil {
+			return fmt.Errorf("failed to save transfer: %w", err)
+		}
+		for _, leg := range []*domain.Transaction{outgoing, incoming} {
+			if err := uc.transRepo.AddTransaction(ctx, leg); err != nil {
+				return fmt.Errorf("failed to save transfer leg: %w", err)
+			}
+		}
+		if err := uc.accountRepo.UpdateBalance(ctx, userID, fromAccountID, -amount); err != nil {
+			return fmt.Errorf("failed to debit source account: %w", err)
+		}
+		if err := uc.accountRepo.UpdateBalance(ctx, userID, toAccountID, transfer.ToAmount); err != nil {
+			return fmt.Errorf("failed to credit destination account: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return transfer, nil
+}
+
+func (uc *TransactionUseCase) GetTransfer(ctx context.Context, userID, transferID uuid.UUID) (*domain.Transfer, []domain.Transaction, error) {
+	transfer, err := uc.transRepo.GetTransfer(ctx, userID, transferID)
+	if err != nil {
+		return nil, nil, err
+	}
+	legs, err := uc.transRepo.GetTransferLegs(ctx, userID, transferID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch transfer legs: %w", err)
+	}
+	return transfer, legs, nil
+}
+
+func (uc *TransactionUseCase) UpdateTransfer(ctx context.Context, userID, transferID uuid.UUID, name string, amount int64, toAmount int64, completedAt time.Time, comment *string) error {
+	if amount <= 0 {
+		return domain.ErrTransInvalidAmount
+	}
+	return uc.txManager.RunInTransaction(ctx, func(ctx context.Context) error {
+		return uc.updateTransfer(ctx, userID, transferID, name, amount, toAmount, completedAt, comment)
+	})
+}
+
+func (uc *TransactionUseCase) DeleteTransfer(ctx context.Context, userID, transferID uuid.UUID) error {
+	return uc.txManager.RunInTransaction(ctx, func(ctx context.Context) error {
+		return uc.deleteTransfer(ctx, userID, transferID)
+	})
+}
+
+// updateTransfer rewrites both legs. A zero amount or toAmount keeps the side
+// unchanged, unless both legs share a currency and the other side is given.
+func (uc *TransactionUseCase) updateTransfer(ctx context.Context, userID, transferID uuid.UUID, name string, amount int64, toAmount int64, completedAt time.Time, comment *string) error {
+	if amount < 0 || toAmount < 0 || (amount == 0 && toAmount == 0) {
+		return domain.ErrTransInvalidAmount
+	}
+	transfer, err := uc.transRepo.GetTransfer(ctx, userID, transferID)
+	if err != nil {
+		return err
+	}
+	legs, err := uc.transRepo.GetTransferLegs(ctx, userID, transferID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch transfer legs: %w", err)
+	}
+
+	sameCurrency := true
+	for _, leg := range legs {
+		if leg.Currency != legs[0].Currency {
+			sameCurrency = false
+		}
+	}
+	switch {
+	case amount == 0 && sameCurrency:
+		amount = toAmount
+	case amount == 0:
+		amount = transfer.Amount
+	}
+	switch {
+	case toAmount == 0 && sameCurrency:
+		toAmount = amount
+	case toAmount == 0:
+		toAmount = transfer.ToAmount
+	}
+	if sameCurrency && amount != toAmount {
+		return domain.ErrTransferAmountMismatch
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = domain.DefaultTransferName
+	}
+	if completedAt.IsZero() {
+		completedAt = transfer.CompletedAt
+	}
+
+	transfer.Amount = amount
+	transfer.ToAmount = toAmount
+	transfer.CompletedAt = completedAt
+	transfer.Comment = comment
+	if err := uc.transRepo.UpdateTransfer(ctx, transfer); err != nil {
+		return fmt.Errorf("failed to update transfer: %w", err)
+	}
+
+	for i := range legs {
+		leg := &legs[i]
+		nextAmount := amount
+		if leg.IsIncome {
+			nextAmount = toAmount
+		}
+		diff := nextAmount - leg.Amount
+
+		leg.NameTransaction = name
+		leg.Amount = nextAmount
+		leg.CompletedAt = completedAt
+		leg.Comment = comment
+		if err := uc.transRepo.UpdateTransaction(ctx, leg); err != nil {
+			return fmt.Errorf("failed to update transfer leg: %w", err)
+		}
+		if diff == 0 || leg.IsHidden {
+			continue
+		}
+		delta := diff
+		if !leg.IsIncome {
+			delta = -diff
+		}
+		if err := uc.accountRepo.UpdateBalance(ctx, userID, leg.AccountID, delta); err != nil {
+			return fmt.Errorf("failed to update account balance during transfer update: %w", err)
+		}
+	}
+	return nil
+}
+
+func (uc *TransactionUseCase) deleteTransfer(ctx context.Context, userID, transferID uuid.UUID) error {
+	legs, err := uc.transRepo.GetTransferLegs(ctx, userID, transferID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch transfer legs: %w", err)
+	}
+	if err := uc.transRepo.DeleteTransfer(ctx, userID, transferID); err != nil {
+		return err
+	}
+
+	for _, leg := range legs {
+		if leg.IsHidden {
+			continue
+		}
+		delta := leg.Amount
+		if leg.IsIncome {
+			delta = -leg.Amount
+		}
+		if err := uc.accountRepo.UpdateBalance(ctx, userID, leg.AccountID, delta); err != nil {
+			return fmt.Errorf("transfer deleted but failed to restore balance: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,215 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	transactionDomain "Finance-Manager-System/internal/infrastructure/modules/transactions/domain"
+)
+
+type recordingBalanceUpdater struct {
+	deltas     map[uuid.UUID]int64
+	currencies map[uuid.UUID]string
+}
+
+func (f *recordingBalanceUpdater) UpdateBalance(ctx context.Context, userID uuid.UUID, accountID uuid.UUID, amountDelta int64) error {
+	if f.deltas == nil {
+		f.deltas = make(map[uuid.UUID]int64)
+	}
+	f.deltas[accountID] += amountDelta
+	return nil
+}
+
+func (f *recordingBalanceUpdater) GetAccountCurrency(ctx context.Context, userID uuid.UUID, accountID uuid.UUID) (string, error) {
+	if currency, ok := f.currencies[accountID]; ok {
+		return currency, nil
+	}
+	return "RUB", nil
+}
+
+func transferLeg(t *testing.T, repo *fakeTransRepo, isIncome bool) *transactionDomain.Transaction {
+	t.Helper()
+	for _, tx := range repo.byID {
+		if tx.TransferID != nil && tx.IsIncome == isIncome {
+			return tx
+		}
+	}
+	t.Fatalf("transfer leg (income=%v) not found", isIncome)
+	return nil
+}
+
+func TestCreateTransferLinksLegsAndMovesBalances(t *testing.T) {
+	userID := uuid.New()
+	fromID := uuid.New()
+	toID := uuid.New()
+	repo := &fakeTransRepo{}
+	balance := &recordingBalanceUpdater{}
+	uc := NewTransactionUseCase(repo, balance, nil, &fakeTransTxManager{})
+
+	transfer, err := uc.CreateTransfer(context.Background(), userID, fromID, toID, "", 50000, 0, time.Now().UTC(), nil)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+
+	outgoing := transferLeg(t, repo, false)
+	incoming := transferLeg(t, repo, true)
+	if *outgoing.TransferID != transfer.TransferID || *incoming.TransferID != transfer.TransferID {
+		t.Fatalf("legs are not linked to the transfer")
+	}
+	if outgoing.AccountID != fromID || incoming.AccountID != toID {
+		t.Fatalf("legs booked on wrong accounts")
+	}
+	if outgoing.NameTransaction != transactionDomain.DefaultTransferName {
+		t.Fatalf("unexpected default name: %s", outgoing.NameTransaction)
+	}
+	if balance.deltas[fromID] != -50000 || balance.deltas[toID] != 50000 {
+		t.Fatalf("unexpected balance deltas: %#v", balance.deltas)
+	}
+}
+
+func TestUpdateTransferLegUpdatesBothLegs(t *testing.T) {
+	userID := uuid.New()
+	fromID := uuid.New()
+	toID := uuid.New()
+	repo := &fakeTransRepo{}
+	balance := &recordingBalanceUpdater{}
+	uc := NewTransactionUseCase(repo, balance, nil, &fakeTransTxManager{})
+
+	if _, err := uc.CreateTransfer(context.Background(), userID, fromID, toID, "Savings", 50000, 0, time.Now().UTC(), nil); err != nil {
+		t.Fatalf("create transfer: %v", err)
+	}
+	incoming := transferLeg(t, repo, true)
+
+	err := uc.UpdateTransaction(context.Background(), userID, incoming.TransactionID, nil, "Savings", true, 70000, incoming.CompletedAt, nil, "", 0, "")
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+
+	if transferLeg(t, repo, false).Amount != 70000 || transferLeg(t, repo, true).Amount != 70000 {
+		t.Fatalf("both legs must carry the new amount")
+	}
+	if balance.deltas[fromID] != -70000 || balance.deltas[toID] != 70000 {
+		t.Fatalf("unexpected balance deltas: %#v", balance.deltas)
+	}
+
+	err = uc.UpdateTransaction(context.Background(), userID, incoming.TransactionID, nil, "Savings", false, 70000, incoming.CompletedAt, nil, "", 0, "")
+	if !errors.Is(err, transactionDomain.ErrTransferLegChange) {
+		t.Fatalf("expected ErrTransferLegChange, got %v", err)
+	}
+}
+
+func TestDeleteTransferLegRemovesBothLegs(t *testing.T) {
+	userID := uuid.New()
+	fromID := uuid.New()
+	toID := uuid.New()
+	repo := &fakeTransRepo{}
+	balance := &recordingBalanceUpdater{}
+	uc := NewTransactionUseCase(repo, balance, nil, &fakeTransTxManager{})
+
+	if _, err := uc.CreateTransfer(context.Background(), userID, fromID, toID, "", 50000, 0, time.Now().UTC(), nil); err != nil {
+		t.Fatalf("create transfer: %v", err)
+	}
+	outgoing := transferLeg(t, repo, false)
+
+	if err := uc.DeleteManualTransaction(context.Background(), userID, outgoing.TransactionID); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if len(repo.byID) != 0 || len(repo.transfers) != 0 {
+		t.Fatalf("transfer and legs must be removed, left %d legs and %d transfers", len(repo.byID), len(repo.transfers))
+	}
+	if balance.deltas[fromID] != 0 || balance.deltas[toID] != 0 {
+		t.Fatalf("balances must be restored: %#v", balance.deltas)
+	}
+}
+
+func TestHideTransferLegHidesBothLegs(t *testing.T) {
+	userID := uuid.New()
+	fromID := uuid.New()
+	toID := uuid.New()
+	repo := &fakeTransRepo{}
+	balance := &recordingBalanceUpdater{}
+	uc := NewTransactionUseCase(repo, balance, nil, &fakeTransTxManager{})
+
+	if _, err := uc.CreateTransfer(context.Background(), userID, fromID, toID, "", 50000, 0, time.Now().UTC(), nil); err != nil {
+		t.Fatalf("create transfer: %v", err)
+	}
+	outgoing := transferLeg(t, repo, false)
+
+	if err := uc.ToggleTransactionsVisibility(context.Background(), userID, []uuid.UUID{outgoing.TransactionID}, true); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if !transferLeg(t, repo, false).IsHidden || !transferLeg(t, repo, true).IsHidden {
+		t.Fatalf("both legs must be hidden")
+	}
+	if balance.deltas[fromID] != 0 || balance.deltas[toID] != 0 {
+		t.Fatalf("balances must be restored: %#v", balance.deltas)
+	}
+}
+
+func TestTransferInOneCurrencyKeepsAmounts(t *testing.T) {
+	userID := uuid.New()
+	fromID := uuid.New()
+	toID := uuid.New()
+	repo := &fakeTransRepo{}
+	balance := &recordingBalanceUpdater{}
+	uc := NewTransactionUseCase(repo, balance, nil, &fakeTransTxManager{})
+
+	_, err := uc.CreateTransfer(context.Background(), userID, fromID, toID, "", 50000, 70000, time.Now().UTC(), nil)
+	if !errors.Is(err, transactionDomain.ErrTransferAmountMismatch) {
+		t.Fatalf("expected ErrTransferAmountMismatch, got %v", err)
+	}
+	if len(repo.byID) != 0 || len(balance.deltas) != 0 {
+		t.Fatalf("a rejected transfer must not touch transactions or balances")
+	}
+
+	transfer, err := uc.CreateTransfer(context.Background(), userID, fromID, toID, "", 50000, 50000, time.Now().UTC(), nil)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	err = uc.UpdateTransfer(context.Background(), userID, transfer.TransferID, "", 50000, 60000, time.Time{}, nil)
+	if !errors.Is(err, transactionDomain.ErrTransferAmountMismatch) {
+		t.Fatalf("expected ErrTransferAmountMismatch, got %v", err)
+	}
+	if balance.deltas[fromID] != -50000 || balance.deltas[toID] != 50000 {
+		t.Fatalf("unexpected balance deltas: %#v", balance.deltas)
+	}
+}
+
+func TestCreateTransferBetweenCurrencies(t *testing.T) {
+	userID := uuid.New()
+	fromID := uuid.New()
+	toID := uuid.New()
+	repo := &fakeTransRepo{}
+	balance := &recordingBalanceUpdater{currencies: map[uuid.UUID]string{fromID: "RUB", toID: "USD"}}
+	uc := NewTransactionUseCase(repo, balance, nil, &fakeTransTxManager{})
+
+	_, err := uc.CreateTransfer(context.Background(), userID, fromID, toID, "", 900000, 0, time.Now().UTC(), nil)
+	if !errors.Is(err, transactionDomain.ErrTransferToAmount) {
+		t.Fatalf("expected ErrTransferToAmount, got %v", err)
+	}
+
+	if _, err := uc.CreateTransfer(context.Background(), userID, fromID, toID, "", 900000, 10000, time.Now().UTC(), nil); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	incoming := transferLeg(t, repo, true)
+	if incoming.Currency != "USD" || incoming.Amount != 10000 {
+		t.Fatalf("unexpected incoming leg: %s %d", incoming.Currency, incoming.Amount)
+	}
+	if balance.deltas[fromID] != -900000 || balance.deltas[toID] != 10000 {
+		t.Fatalf("unexpected balance deltas: %#v", balance.deltas)
+	}
+
+	if err := uc.UpdateTransaction(context.Background(), userID, incoming.TransactionID, nil, "", true, 11000, incoming.CompletedAt, nil, "", 0, ""); err != nil {
+		t.Fatalf("update leg: %v", err)
+	}
+	if transferLeg(t, repo, false).Amount != 900000 {
+		t.Fatalf("editing the incoming leg must keep the source amount")
+	}
+	if balance.deltas[toID] != 11000 {
+		t.Fatalf("unexpected destination balance: %d", balance.deltas[toID])
+	}
+}
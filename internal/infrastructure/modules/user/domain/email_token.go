@@ -0,0 +1,58 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidEmailToken    = errors.New("invalid or expired token")
+	ErrEmailAlreadyVerified = errors.New("email already verified")
+	ErrWrongCurrentPassword = errors.New("current password is incorrect")
+)
+
+// EmailTokenPurpose tells apart the links sent by email, so a verification
+// link cannot be used to reset the password.
+type EmailTokenPurpose string
+
+const (
+	PurposeVerifyEmail   EmailTokenPurpose = "verify_email"
+	PurposeResetPassword EmailTokenPurpose = "reset_password"
+)
+
+// EmailToken is a single-use token sent in a link. Only its SHA-256 is
+// stored; UsedAt is set when the link is followed.
+type EmailToken struct {
+	TokenID   uuid.UUID         `db:"token_id"`
+	UserID    uuid.UUID         `db:"user_id"`
+	Purpose   EmailTokenPurpose `db:"purpose"`
+	TokenHash string            `db:"token_hash"`
+	CreatedAt time.Time         `db:"created_at"`
+	ExpiresAt time.Time         `db:"expires_at"`
+	UsedAt    *time.Time        `db:"used_at"`
+}
+
+// NewEmailToken returns the token to store and the plain token to send.
+func NewEmailToken(userID uuid.UUID, purpose EmailTokenPurpose, ttl time.Duration, now time.Time) (*EmailToken, string, error) {
+	token, err := newRandomToken()
+	if err != nil {
+		return nil, "", err
+	}
+	return &EmailToken{
+		TokenID:   uuid.New(),
+		UserID:    userID,
+		Purpose:   purpose,
+		TokenHash: HashEmailToken(token),
+		CreatedAt: now,
+		ExpiresAt: now.Add(ttl),
+	}, token, nil
+}
+
+func HashEmailToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,33 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestNewEmailToken(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	userID := uuid.New()
+
+	emailToken, token, err := NewEmailToken(userID, PurposeResetPassword, time.Hour, now)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if len(token) != 43 || strings.ContainsAny(token, "+/=") {
+		t.Fatalf("expected a URL-safe 256-bit token, got %q", token)
+	}
+	if emailToken.TokenHash != HashEmailToken(token) || strings.Contains(emailToken.TokenHash, token) {
+		t.Fatalf("only the hash of the token should be stored")
+	}
+	if emailToken.UserID != userID || emailToken.Purpose != PurposeResetPassword || !emailToken.ExpiresAt.Equal(now.Add(time.Hour)) || emailToken.UsedAt != nil {
+		t.Fatalf("unexpected token: %+v", emailToken)
+	}
+
+	_, other, _ := NewEmailToken(userID, PurposeResetPassword, time.Hour, now)
+	if other == token {
+		t.Fatalf("tokens must be random")
+	}
+}
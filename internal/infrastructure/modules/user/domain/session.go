@@ -0,0 +1,115 @@
+package domain
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	ErrSessionNotFound     = errors.New("session not found")
+)
+
+// maxUserAgentLength is the user_agent column size, counted in characters.
+const maxUserAgentLength = 255
+
+// Token types in the "typ" claim. Only access tokens pass RequireAuth.
+const (
+	AccessTokenType    = "access"
+	ChallengeTokenType = "2fa_challenge"
+)
+
+// Session is a signed-in device. Only the SHA-256 of its refresh token is
+// stored; PreviousTokenHash keeps the token it was rotated from so a replayed
+// old token can be told apart from an unknown one.
+type Session struct {
+	SessionID         uuid.UUID  `db:"session_id" json:"session_id"`
+	UserID            uuid.UUID  `db:"user_id" json:"-"`
+	RefreshTokenHash  string     `db:"refresh_token_hash" json:"-"`
+	PreviousTokenHash *string    `db:"previous_token_hash" json:"-"`
+	UserAgent         string     `db:"user_agent" json:"user_agent"`
+	IP                string     `db:"ip" json:"ip"`
+	CreatedAt         time.Time  `db:"created_at" json:"created_at"`
+	LastUsedAt        time.Time  `db:"last_used_at" json:"last_used_at"`
+	ExpiresAt         time.Time  `db:"expires_at" json:"expires_at"`
+	RevokedAt         *time.Time `db:"revoked_at" json:"-"`
+	Current           bool       `db:"-" json:"current"`
+}
+
+// TokenPair is what a client gets on login and on every refresh.
+type TokenPair struct {
+	AccessToken  string    `json:"access_token"`
+	RefreshToken string    `json:"refresh_token"`
+	TokenType    string    `json:"token_type"`
+	ExpiresIn    int64     `json:"expires_in"`
+	SessionID    uuid.UUID `json:"session_id"`
+}
+
+// NewSession starts a session and returns it with its refresh token.
+func NewSession(userID uuid.UUID, userAgent string, ip string, ttl time.Duration, now time.Time) (*Session, string, error) {
+	token, hash, err := newRefreshToken()
+	if err != nil {
+		return nil, "", err
+	}
+	if utf8.RuneCountInString(userAgent) > maxUserAgentLength {
+		userAgent = string([]rune(userAgent)[:maxUserAgentLength])
+	}
+	return &Session{
+		SessionID:        uuid.New(),
+		UserID:           userID,
+		RefreshTokenHash: hash,
+		UserAgent:        userAgent,
+		IP:               ip,
+		CreatedAt:        now,
+		LastUsedAt:       now,
+		ExpiresAt:        now.Add(ttl),
+	}, token, nil
+}
+
+// Rotate replaces the refresh token and extends the session by ttl.
+func (s *Session) Rotate(ttl time.Duration, now time.Time) (string, error) {
+	token, hash, err := newRefreshToken()
+	if err != nil {
+		return "", err
+	}
+	previous := s.RefreshTokenHash
+	s.PreviousTokenHash = &previous
+	s.RefreshTokenHash = hash
+	s.LastUsedAt = now
+	s.ExpiresAt = now.Add(ttl)
+	return token, nil
+}
+
+func (s *Session) IsActive(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
+
+func HashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func newRefreshToken() (string, string, error) {
+	token, err := newRandomToken()
+	if err != nil {
+		return "", "", err
+	}
+	return token, HashRefreshToken(token), nil
+}
+
+// newRandomToken returns 256 random bits for tokens that are handed out
+// once and only stored hashed.
+func newRandomToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
@@ -0,0 +1,62 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+func TestSessionRotation(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	session, token, err := NewSession(uuid.New(), strings.Repeat("a", 300), "10.0.0.1", time.Hour, now)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if token == "" || session.RefreshTokenHash != HashRefreshToken(token) || strings.Contains(session.RefreshTokenHash, token) {
+		t.Fatalf("session must store only the hash of its refresh token")
+	}
+	if len(session.UserAgent) != maxUserAgentLength {
+		t.Fatalf("user agent should be truncated, got %d chars", len(session.UserAgent))
+	}
+
+	later := now.Add(30 * time.Minute)
+	rotated, err := session.Rotate(time.Hour, later)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if rotated == token || session.RefreshTokenHash != HashRefreshToken(rotated) {
+		t.Fatalf("rotation must issue a new refresh token")
+	}
+	if session.PreviousTokenHash == nil || *session.PreviousTokenHash != HashRefreshToken(token) {
+		t.Fatalf("rotation must remember the previous token hash")
+	}
+	if !session.ExpiresAt.Equal(later.Add(time.Hour)) || !session.IsActive(later) {
+		t.Fatalf("rotation should extend the session, expires at %v", session.ExpiresAt)
+	}
+	if session.IsActive(later.Add(2 * time.Hour)) {
+		t.Fatalf("expired session must not be active")
+	}
+
+	revokedAt := later
+	session.RevokedAt = &revokedAt
+	if session.IsActive(later) {
+		t.Fatalf("revoked session must not be active")
+	}
+}
+
+func TestSessionTruncatesUserAgentOnRuneBoundary(t *testing.T) {
+	userAgent := strings.Repeat("я", 300)
+	session, _, err := NewSession(uuid.New(), userAgent, "10.0.0.1", time.Hour, time.Now())
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if !utf8.ValidString(session.UserAgent) {
+		t.Fatalf("truncated user agent must stay valid UTF-8")
+	}
+	if n := utf8.RuneCountInString(session.UserAgent); n != maxUserAgentLength {
+		t.Fatalf("user agent should be truncated to %d characters, got %d", maxUserAgentLength, n)
+	}
+}
@@ -0,0 +1,92 @@
+package domain
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfcSecret is the SHA1 key of the RFC 6238 test vectors, "12345678901234567890".
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTPCode(t *testing.T) {
+	vectors := map[int64]string{
+		59:         "287082",
+		1111111109: "081804",
+		1234567890: "005924",
+		2000000000: "279037",
+	}
+	for unix, want := range vectors {
+		got, err := TOTPCode(rfcSecret, TOTPStep(time.Unix(unix, 0)))
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if got != want {
+			t.Fatalf("at %d: expected %s, got %s", unix, want, got)
+		}
+	}
+}
+
+func TestVerifyTOTP(t *testing.T) {
+	now := time.Unix(1111111109, 0)
+	current := TOTPStep(now)
+	previous, _ := TOTPCode(rfcSecret, current-1)
+
+	step, ok := VerifyTOTP(rfcSecret, "081804", now, 0)
+	if !ok || step != current {
+		t.Fatalf("expected the current code to match step %d, got %d %v", current, step, ok)
+	}
+	if _, ok := VerifyTOTP(rfcSecret, previous, now, 0); !ok {
+		t.Fatalf("a code of the previous step should still be accepted")
+	}
+	if _, ok := VerifyTOTP(rfcSecret, "081804", now, current); ok {
+		t.Fatalf("a code must not be accepted twice")
+	}
+	if _, ok := VerifyTOTP(rfcSecret, previous, now.Add(2*TOTPPeriod), 0); ok {
+		t.Fatalf("an old code must not be accepted")
+	}
+	if _, ok := VerifyTOTP(rfcSecret, "81804", now, 0); ok {
+		t.Fatalf("a short code must not be accepted")
+	}
+}
+
+func TestTwoFactorEnrolment(t *testing.T) {
+	secret, err := NewTOTPSecret()
+	if err != nil || len(secret) != 32 {
+		t.Fatalf("expected a 32-character base32 secret, got %q, %v", secret, err)
+	}
+	code, err := TOTPCode(secret, TOTPStep(time.Now()))
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if _, ok := VerifyTOTP(secret, code, time.Now(), 0); !ok {
+		t.Fatalf("a fresh secret should verify its own code")
+	}
+
+	uri, err := url.Parse(OTPAuthURI("user@example.com", secret))
+	if err != nil {
+		t.Fatalf("expected a valid URI, got %v", err)
+	}
+	if uri.Scheme != "otpauth" || uri.Host != "totp" || uri.Query().Get("secret") != secret || uri.Query().Get("issuer") != TOTPIssuer {
+		t.Fatalf("unexpected otpauth URI: %s", uri)
+	}
+	if !strings.HasSuffix(uri.Path, ":user@example.com") {
+		t.Fatalf("label should name the account, got %s", uri.Path)
+	}
+
+	codes, err := NewRecoveryCodes()
+	if err != nil || len(codes) != RecoveryCodeCount {
+		t.Fatalf("expected %d recovery codes, got %d, %v", RecoveryCodeCount, len(codes), err)
+	}
+	seen := make(map[string]bool)
+	for _, code := range codes {
+		if len(code) != 14 || seen[code] {
+			t.Fatalf("unexpected recovery code %q", code)
+		}
+		seen[code] = true
+	}
+	if NormalizeRecoveryCode(" K7QM-3xwa-PD2F ") != "k7qm3xwapd2f" {
+		t.Fatalf("recovery codes should ignore case, spaces and dashes")
+	}
+}
@@ -25,6 +25,12 @@ type User struct {
 	HashPassword string    `db:"hash_password"`
 	Created_at   time.Time `db:"created_at"`
 	Updated_at   time.Time `db:"updated_at"`
+	BaseCurrency string    `db:"base_currency"`
+	TOTPSecret   *string   `db:"totp_secret"`
+	TOTPEnabled  bool      `db:"totp_enabled"`
+	TOTPLastStep int64     `db:"totp_last_step"`
+	// EmailVerifiedAt is set once the user follows the verification link.
+	EmailVerifiedAt *time.Time `db:"email_verified_at"`
 }
 
 func NewUser(email string, login string, hashPassword string) (*User, error) {
@@ -44,5 +50,6 @@ func NewUser(email string, login string, hashPassword string) (*User, error) {
 		HashPassword: hashPassword,
 		Created_at:   time.Now(),
 		Updated_at:   time.Now(),
+		BaseCurrency: "RUB",
 	}, nil
 }
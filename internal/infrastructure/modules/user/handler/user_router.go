@@ -6,6 +6,7 @@ import (
 	"net/http"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
 
 	"Finance-Manager-System/internal/infrastructure/middleware"
 	"Finance-Manager-System/internal/infrastructure/modules/user/domain"
@@ -14,22 +15,48 @@ import (
 )
 
 type UserRouter struct {
-	userCase *usecase.UserCase
+	userCase    *usecase.UserCase
+	requireAuth func(http.Handler) http.Handler
+	authLimit   func(http.Handler) http.Handler
 }
 
-func NewUserRouter(userCase *usecase.UserCase) *UserRouter {
+// NewUserRouter takes authLimit, the stricter rate limit of the sign-in
+// endpoints that do not need a token.
+func NewUserRouter(userCase *usecase.UserCase, requireAuth func(http.Handler) http.Handler, authLimit func(http.Handler) http.Handler) *UserRouter {
 	return &UserRouter{
-		userCase: userCase,
+		userCase:    userCase,
+		requireAuth: requireAuth,
+		authLimit:   authLimit,
 	}
 }
 
 func (u *UserRouter) Route() chi.Router {
 	r := chi.NewRouter()
 
-	r.Post("/register", u.Register)
-	r.Post("/login", u.Login)
+	r.Group(func(r chi.Router) {
+		r.Use(u.authLimit)
+		r.Post("/register", u.Register)
+		r.Post("/login", u.Login)
+		r.Post("/login/2fa", u.CompleteLogin)
+		r.Post("/refresh", u.Refresh)
+		r.Post("/forgot_password", u.ForgotPassword)
+		r.Post("/reset_password", u.ResetPassword)
+		r.Post("/verify_email", u.VerifyEmail)
+	})
 
-	r.With(middleware.RequireAuth).Put("/change_password", u.ChangePassword)
+	r.Group(func(r chi.Router) {
+		r.Use(u.requireAuth)
+		r.Put("/change_password", u.ChangePassword)
+		r.Post("/verify_email/send", u.SendEmailVerification)
+		r.Post("/logout", u.Logout)
+		r.Post("/logout_all", u.LogoutAll)
+		r.Get("/sessions", u.GetSessions)
+		r.Delete("/sessions/{id}", u.RevokeSession)
+		r.Post("/2fa/setup", u.SetupTwoFactor)
+		r.Post("/2fa/enable", u.EnableTwoFactor)
+		r.Post("/2fa/disable", u.DisableTwoFactor)
+		r.Post("/2fa/recovery_codes", u.RegenerateRecoveryCodes)
+	})
 
 	return r
 }
@@ -46,9 +73,41 @@ type LoginReq struct {
 }
 
 type ChangePasswordReq struct {
+	CurrentPassword string `json:"current_password"`
+	Password        string `json:"password"`
+}
+
+type ForgotPasswordReq struct {
+	Email string `json:"email" example:"user@example.com"`
+}
+
+type ResetPasswordReq struct {
+	Token    string `json:"token"`
 	Password string `json:"password"`
 }
 
+type VerifyEmailReq struct {
+	Token string `json:"token"`
+}
+
+type RefreshReq struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type CompleteLoginReq struct {
+	ChallengeToken string `json:"challenge_token"`
+	Code           string `json:"code" example:"123456"`
+}
+
+type TwoFactorCodeReq struct {
+	Code string `json:"code" example:"123456"`
+}
+
+type DisableTwoFactorReq struct {
+	Password string `json:"password"`
+	Code     string `json:"code" example:"123456"`
+}
+
 // @Summary Регистрация пользователя
 // @Tags users
 // @Accept json
@@ -79,6 +138,7 @@ func (u *UserRouter) Register(w http.ResponseWriter, r *http.Request) {
 }
 
 // @Summary Авторизация (вход)
+// @Description Возвращает короткоживущий access_token (он же token) и refresh_token для продления сессии. Если включена двухфакторная аутентификация, вместо токенов возвращается challenge_token для /users/login/2fa
 // @Tags users
 // @Accept json
 // @Produce json
@@ -92,20 +152,319 @@ func (u *UserRouter) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	token, err := u.userCase.LoginUser(r.Context(), req.Identifier, req.Password)
+	result, err := u.userCase.LoginUser(r.Context(), req.Identifier, req.Password, r.UserAgent(), middleware.ClientIP(r))
+	if err != nil {
+		u.mapError(w, err)
+		return
+	}
+
+	if result.Tokens == nil {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":          "2fa_required",
+			"challenge_token": result.ChallengeToken,
+			"expires_in":      result.ChallengeExpiresIn,
+		})
+		return
+	}
+
+	writeTokens(w, result.Tokens)
+}
+
+// @Summary Второй шаг входа (2FA)
+// @Description Принимает challenge_token из /users/login и код из приложения-аутентификатора или резервный код
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body CompleteLoginReq true "Challenge token и код"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/users/login/2fa [post]
+func (u *UserRouter) CompleteLogin(w http.ResponseWriter, r *http.Request) {
+	var req CompleteLoginReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid json", http.StatusBadRequest)
+		return
+	}
+
+	tokens, err := u.userCase.CompleteLoginChallenge(r.Context(), req.ChallengeToken, req.Code, r.UserAgent(), middleware.ClientIP(r))
+	if err != nil {
+		u.mapError(w, err)
+		return
+	}
+
+	writeTokens(w, tokens)
+}
+
+// @Summary Начать подключение 2FA
+// @Description Создаёт секрет TOTP и otpauth URI для QR-кода; 2FA включается после подтверждения кодом в /users/2fa/enable
+// @Tags users
+// @Security ApiKeyAuth
+// @Produce json
+// @Success 200 {object} domain.TwoFactorSetup
+// @Router /api/v1/users/2fa/setup [post]
+func (u *UserRouter) SetupTwoFactor(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	setup, err := u.userCase.SetupTwoFactor(r.Context(), userID)
+	if err != nil {
+		u.mapError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(setup)
+}
+
+// @Summary Включить 2FA
+// @Description Подтверждает секрет кодом из приложения и возвращает резервные коды; они показываются один раз
+// @Tags users
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param request body TwoFactorCodeReq true "Код из приложения-аутентификатора"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/users/2fa/enable [post]
+func (u *UserRouter) EnableTwoFactor(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req TwoFactorCodeReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid json", http.StatusBadRequest)
+		return
+	}
+
+	codes, err := u.userCase.EnableTwoFactor(r.Context(), userID, req.Code)
+	if err != nil {
+		u.mapError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":         "success",
+		"recovery_codes": codes,
+	})
+}
+
+// @Summary Отключить 2FA
+// @Description Требует пароль и код из приложения или резервный код
+// @Tags users
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param request body DisableTwoFactorReq true "Пароль и код"
+// @Success 202 {object} map[string]interface{}
+// @Router /api/v1/users/2fa/disable [post]
+func (u *UserRouter) DisableTwoFactor(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req DisableTwoFactorReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid json", http.StatusBadRequest)
+		return
+	}
+
+	if err := u.userCase.DisableTwoFactor(r.Context(), userID, req.Password, req.Code); err != nil {
+		u.mapError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":  "success",
+		"message": "Two-factor authentication disabled",
+	})
+}
+
+// @Summary Перевыпустить резервные коды 2FA
+// @Description Старые резервные коды перестают действовать
+// @Tags users
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param request body TwoFactorCodeReq true "Код из приложения или резервный код"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/users/2fa/recovery_codes [post]
+func (u *UserRouter) RegenerateRecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req TwoFactorCodeReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid json", http.StatusBadRequest)
+		return
+	}
+
+	codes, err := u.userCase.RegenerateRecoveryCodes(r.Context(), userID, req.Code)
+	if err != nil {
+		u.mapError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":         "success",
+		"recovery_codes": codes,
+	})
+}
+
+// @Summary Обновить токены
+// @Description Обменивает refresh_token на новую пару токенов; старый refresh_token после этого недействителен, а его повторное использование завершает сессию
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body RefreshReq true "Refresh token"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/users/refresh [post]
+func (u *UserRouter) Refresh(w http.ResponseWriter, r *http.Request) {
+	var req RefreshReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid json", http.StatusBadRequest)
+		return
+	}
+
+	tokens, err := u.userCase.RefreshSession(r.Context(), req.RefreshToken)
+	if err != nil {
+		u.mapError(w, err)
+		return
+	}
+
+	writeTokens(w, tokens)
+}
+
+// @Summary Выйти из текущей сессии
+// @Tags users
+// @Security ApiKeyAuth
+// @Produce json
+// @Success 202 {object} map[string]interface{}
+// @Router /api/v1/users/logout [post]
+func (u *UserRouter) Logout(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	sessionID, err := middleware.GetSessionID(r.Context())
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	if err := u.userCase.RevokeSession(r.Context(), userID, sessionID); err != nil {
+		u.mapError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":  "success",
+		"message": "Logged out",
+	})
+}
+
+// @Summary Выйти на всех устройствах
+// @Tags users
+// @Security ApiKeyAuth
+// @Produce json
+// @Success 202 {object} map[string]interface{}
+// @Router /api/v1/users/logout_all [post]
+func (u *UserRouter) LogoutAll(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
 	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	if err := u.userCase.RevokeAllSessions(r.Context(), userID); err != nil {
 		u.mapError(w, err)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
 	json.NewEncoder(w).Encode(map[string]interface{}{
-		"status": "success",
-		"token":  token,
+		"status":  "success",
+		"message": "Logged out on all devices",
+	})
+}
+
+// @Summary Активные сессии
+// @Description Устройства, на которых выполнен вход; текущая сессия помечена current
+// @Tags users
+// @Security ApiKeyAuth
+// @Produce json
+// @Success 200 {array} domain.Session
+// @Router /api/v1/users/sessions [get]
+func (u *UserRouter) GetSessions(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	sessionID, _ := middleware.GetSessionID(r.Context())
+
+	sessions, err := u.userCase.GetSessions(r.Context(), userID, sessionID)
+	if err != nil {
+		u.mapError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(sessions)
+}
+
+// @Summary Завершить сессию
+// @Tags users
+// @Security ApiKeyAuth
+// @Produce json
+// @Param id path string true "ID сессии"
+// @Success 202 {object} map[string]interface{}
+// @Router /api/v1/users/sessions/{id} [delete]
+func (u *UserRouter) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	sessionID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid session ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := u.userCase.RevokeSession(r.Context(), userID, sessionID); err != nil {
+		u.mapError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":  "success",
+		"message": "Session revoked",
 	})
 }
 
 // @Summary Изменить пароль
+// @Description Требует текущий пароль. Все сессии, включая текущую, завершаются; после смены пароля нужно войти заново
 // @Tags users
 // @Security ApiKeyAuth
 // @Accept json
@@ -126,7 +485,7 @@ func (u *UserRouter) ChangePassword(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = u.userCase.ChangeUserPassword(r.Context(), userID, req.Password)
+	err = u.userCase.ChangeUserPassword(r.Context(), userID, req.CurrentPassword, req.Password)
 	if err != nil {
 		u.mapError(w, err)
 		return
@@ -140,11 +499,125 @@ func (u *UserRouter) ChangePassword(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// @Summary Забыли пароль
+// @Description Отправляет на почту ссылку для сброса пароля. Ответ одинаковый, зарегистрирован email или нет
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body ForgotPasswordReq true "Email"
+// @Success 202 {object} map[string]interface{}
+// @Router /api/v1/users/forgot_password [post]
+func (u *UserRouter) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	var req ForgotPasswordReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid json", http.StatusBadRequest)
+		return
+	}
+
+	if err := u.userCase.RequestPasswordReset(r.Context(), req.Email); err != nil {
+		u.mapError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":  "success",
+		"message": "If the email is registered, a reset link has been sent",
+	})
+}
+
+// @Summary Сброс пароля
+// @Description Задаёт новый пароль по одноразовому токену из письма; все сессии завершаются
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body ResetPasswordReq true "Токен из письма и новый пароль"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/users/reset_password [post]
+func (u *UserRouter) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	var req ResetPasswordReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid json", http.StatusBadRequest)
+		return
+	}
+
+	if err := u.userCase.ResetPassword(r.Context(), req.Token, req.Password); err != nil {
+		u.mapError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":  "success",
+		"message": "Password changed",
+	})
+}
+
+// @Summary Подтвердить email
+// @Description Подтверждает адрес по одноразовому токену из письма
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body VerifyEmailReq true "Токен из письма"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/users/verify_email [post]
+func (u *UserRouter) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	var req VerifyEmailReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid json", http.StatusBadRequest)
+		return
+	}
+
+	if err := u.userCase.VerifyEmail(r.Context(), req.Token); err != nil {
+		u.mapError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":  "success",
+		"message": "Email verified",
+	})
+}
+
+// @Summary Отправить письмо для подтверждения email
+// @Description Отправляет новую ссылку; ранее отправленные ссылки перестают действовать
+// @Tags users
+// @Security ApiKeyAuth
+// @Produce json
+// @Success 202 {object} map[string]interface{}
+// @Router /api/v1/users/verify_email/send [post]
+func (u *UserRouter) SendEmailVerification(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	if err := u.userCase.SendEmailVerification(r.Context(), userID); err != nil {
+		u.mapError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":  "success",
+		"message": "Verification email sent",
+	})
+}
+
 func (u *UserRouter) mapError(w http.ResponseWriter, err error) {
 	var statusCode int
 	var message string
 
+	var locked *domain.LockedError
 	switch {
+	case errors.As(err, &locked):
+		middleware.WriteRetryAfter(w, locked.RetryAfter)
+		statusCode = http.StatusTooManyRequests
+		message = "Too many failed attempts, try again later"
 	case errors.Is(err, domain.ErrUserAlreadyExists):
 		statusCode = http.StatusConflict
 		message = "User already exists"
@@ -160,6 +633,32 @@ func (u *UserRouter) mapError(w http.ResponseWriter, err error) {
 	case errors.Is(err, domain.ErrInvalidPassword):
 		statusCode = http.StatusBadRequest
 		message = "Invalid input password"
+	case errors.Is(err, domain.ErrInvalidRefreshToken):
+		statusCode = http.StatusUnauthorized
+		message = "Invalid or expired refresh token"
+	case errors.Is(err, domain.ErrSessionNotFound):
+		statusCode = http.StatusNotFound
+		message = "Session not found"
+	case errors.Is(err, domain.ErrInvalidTwoFactorCode):
+		statusCode = http.StatusUnauthorized
+		message = "Invalid two-factor code"
+	case errors.Is(err, domain.ErrInvalidChallenge):
+		statusCode = http.StatusUnauthorized
+		message = "Invalid or expired login challenge"
+	case errors.Is(err, domain.ErrWrongCurrentPassword):
+		statusCode = http.StatusForbidden
+		message = "Current password is incorrect"
+	case errors.Is(err, domain.ErrInvalidEmailToken):
+		statusCode = http.StatusBadRequest
+		message = "Invalid or expired token"
+	case errors.Is(err, domain.ErrEmailAlreadyVerified):
+		statusCode = http.StatusConflict
+		message = "Email already verified"
+	case errors.Is(err, domain.ErrTwoFactorAlreadyEnabled),
+		errors.Is(err, domain.ErrTwoFactorNotEnabled),
+		errors.Is(err, domain.ErrTwoFactorNotSetUp):
+		statusCode = http.StatusConflict
+		message = err.Error()
 	default:
 		zap.L().Error("user_handler_internal_error", zap.Error(err))
 		statusCode = http.StatusInternalServerError
@@ -167,3 +666,18 @@ func (u *UserRouter) mapError(w http.ResponseWriter, err error) {
 	}
 	http.Error(w, message, statusCode)
 }
+
+// writeTokens keeps the old "token" field next to the token pair for
+// clients that only read the access token.
+func writeTokens(w http.ResponseWriter, tokens *domain.TokenPair) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":        "success",
+		"token":         tokens.AccessToken,
+		"access_token":  tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+		"token_type":    tokens.TokenType,
+		"expires_in":    tokens.ExpiresIn,
+		"session_id":    tokens.SessionID,
+	})
+}
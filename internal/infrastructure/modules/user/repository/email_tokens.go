@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"Finance-Manager-System/internal/infrastructure/modules/user/domain"
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// CreateEmailToken stores a new token and expires the unused tokens of the
+// same purpose, so only the latest link works.
+func (u *UserRepository) CreateEmailToken(ctx context.Context, token *domain.EmailToken) error {
+	return u.inTx(ctx, func(tx *sqlx.Tx) error {
+		query := `UPDATE EmailTokens SET used_at = $3
+			WHERE user_id = $1 AND purpose = $2 AND used_at IS NULL`
+		if _, err := tx.ExecContext(ctx, query, token.UserID, token.Purpose, token.CreatedAt); err != nil {
+			return err
+		}
+
+		query = `INSERT INTO EmailTokens(token_id, user_id, purpose, token_hash, created_at, expires_at)
+			VALUES(:token_id, :user_id, :purpose, :token_hash, :created_at, :expires_at)`
+		_, err := tx.NamedExecContext(ctx, query, token)
+		return err
+	})
+}
+
+// VerifyEmail uses up a verification token and marks the email verified.
+func (u *UserRepository) VerifyEmail(ctx context.Context, tokenHash string, now time.Time) (uuid.UUID, error) {
+	var userID uuid.UUID
+	err := u.inTx(ctx, func(tx *sqlx.Tx) error {
+		var err error
+		userID, err = consumeEmailToken(ctx, tx, tokenHash, domain.PurposeVerifyEmail, now)
+		if err != nil {
+			return err
+		}
+		return markEmailVerified(ctx, tx, userID, now)
+	})
+	return userID, err
+}
+
+// ResetPassword uses up a reset token, sets the new password hash and signs
+// out every session. Following the emailed link also proves the address.
+func (u *UserRepository) ResetPassword(ctx context.Context, tokenHash string, hashPassword string, now time.Time) (uuid.UUID, error) {
+	var userID uuid.UUID
+	err := u.inTx(ctx, func(tx *sqlx.Tx) error {
+		var err error
+		userID, err = consumeEmailToken(ctx, tx, tokenHash, domain.PurposeResetPassword, now)
+		if err != nil {
+			return err
+		}
+
+		query := `UPDATE Users SET hash_password = $2, updated_at = $3 WHERE user_id = $1`
+		if _, err := tx.ExecContext(ctx, query, userID, hashPassword, now); err != nil {
+			return err
+		}
+		if err := markEmailVerified(ctx, tx, userID, now); err != nil {
+			return err
+		}
+
+		query = `UPDATE Sessions SET revoked_at = $2 WHERE user_id = $1 AND revoked_at IS NULL`
+		_, err = tx.ExecContext(ctx, query, userID, now)
+		return err
+	})
+	return userID, err
+}
+
+func consumeEmailToken(ctx context.Context, tx *sqlx.Tx, tokenHash string, purpose domain.EmailTokenPurpose, now time.Time) (uuid.UUID, error) {
+	query := `UPDATE EmailTokens SET used_at = $3
+		WHERE token_hash = $1 AND purpose = $2 AND used_at IS NULL AND expires_at > $3
+		RETURNING user_id`
+
+	var userID uuid.UUID
+	err := tx.GetContext(ctx, &userID, query, tokenHash, purpose, now)
+	if errors.Is(err, sql.ErrNoRows) {
+		return uuid.Nil, domain.ErrInvalidEmailToken
+	}
+	return userID, err
+}
+
+func markEmailVerified(ctx context.Context, tx *sqlx.Tx, userID uuid.UUID, now time.Time) error {
+	query := `UPDATE Users SET email_verified_at = COALESCE(email_verified_at, $2) WHERE user_id = $1`
+
+	_, err := tx.ExecContext(ctx, query, userID, now)
+	return err
+}
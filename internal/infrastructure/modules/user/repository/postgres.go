@@ -70,16 +70,18 @@ func (u *UserRepository) CheckExistUser(ctx context.Context, login string, email
 	return exists, err
 }
 
-func (u *UserRepository) UpdateUserInfo(ctx context.Context, id uuid.UUID, hash_password string) error {
-	query := `UPDATE Users SET hash_password = :hash_password, updated_at = :updated_at
-	WHERE user_id = :id`
-
-	_, err := u.db.NamedExecContext(ctx, query, map[string]interface{}{
-		"hash_password": hash_password,
-		"id":            id,
-		"updated_at":    time.Now(),
-	})
-
-	return err
+// ChangePassword sets a new password hash and signs out every session in
+// one transaction, so an old session never outlives the old password.
+func (u *UserRepository) ChangePassword(ctx context.Context, id uuid.UUID, hashPassword string) error {
+	now := time.Now()
+	return u.inTx(ctx, func(tx *sqlx.Tx) error {
+		query := `UPDATE Users SET hash_password = $2, updated_at = $3 WHERE user_id = $1`
+		if _, err := tx.ExecContext(ctx, query, id, hashPassword, now); err != nil {
+			return err
+		}
 
+		query = `UPDATE Sessions SET revoked_at = $2 WHERE user_id = $1 AND revoked_at IS NULL`
+		_, err := tx.ExecContext(ctx, query, id, now)
+		return err
+	})
 }
@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"Finance-Manager-System/internal/infrastructure/modules/user/domain"
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func (u *UserRepository) CreateSession(ctx context.Context, session *domain.Session) error {
+	query := `INSERT INTO Sessions(session_id, user_id, refresh_token_hash, user_agent, ip, created_at, last_used_at, expires_at)
+		VALUES(:session_id, :user_id, :refresh_token_hash, :user_agent, :ip, :created_at, :last_used_at, :expires_at)`
+
+	_, err := u.db.NamedExecContext(ctx, query, session)
+	return err
+}
+
+// GetSessionByTokenHash finds the session whose current or previous refresh
+// token has the given hash.
+func (u *UserRepository) GetSessionByTokenHash(ctx context.Context, hash string) (*domain.Session, error) {
+	query := `SELECT * FROM Sessions WHERE refresh_token_hash = $1 OR previous_token_hash = $1 LIMIT 1`
+	var session domain.Session
+
+	err := u.db.GetContext(ctx, &session, query, hash)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// RotateSession stores the new refresh token unless the session was rotated
+// or revoked since it was read.
+func (u *UserRepository) RotateSession(ctx context.Context, session *domain.Session) (bool, error) {
+	query := `UPDATE Sessions
+		SET refresh_token_hash = $1, previous_token_hash = $2, last_used_at = $3, expires_at = $4
+		WHERE session_id = $5 AND refresh_token_hash = $2 AND revoked_at IS NULL`
+
+	res, err := u.db.ExecContext(ctx, query, session.RefreshTokenHash, session.PreviousTokenHash, session.LastUsedAt, session.ExpiresAt, session.SessionID)
+	if err != nil {
+		return false, err
+	}
+	rows, err := res.RowsAffected()
+	return rows == 1, err
+}
+
+func (u *UserRepository) GetActiveSessions(ctx context.Context, userID uuid.UUID) ([]domain.Session, error) {
+	query := `SELECT * FROM Sessions
+		WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > $2
+		ORDER BY last_used_at DESC`
+	sessions := make([]domain.Session, 0)
+
+	err := u.db.SelectContext(ctx, &sessions, query, userID, time.Now())
+	return sessions, err
+}
+
+func (u *UserRepository) IsSessionActive(ctx context.Context, userID uuid.UUID, sessionID uuid.UUID) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM Sessions
+		WHERE session_id = $1 AND user_id = $2 AND revoked_at IS NULL AND expires_at > $3)`
+	var active bool
+
+	err := u.db.GetContext(ctx, &active, query, sessionID, userID, time.Now())
+	return active, err
+}
+
+func (u *UserRepository) RevokeSession(ctx context.Context, userID uuid.UUID, sessionID uuid.UUID) error {
+	query := `UPDATE Sessions SET revoked_at = $3
+		WHERE session_id = $1 AND user_id = $2 AND revoked_at IS NULL`
+
+	res, err := u.db.ExecContext(ctx, query, sessionID, userID, time.Now())
+	if err != nil {
+		return err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return domain.ErrSessionNotFound
+	}
+	return nil
+}
+
+func (u *UserRepository) RevokeAllSessions(ctx context.Context, userID uuid.UUID) error {
+	query := `UPDATE Sessions SET revoked_at = $2 WHERE user_id = $1 AND revoked_at IS NULL`
+
+	_, err := u.db.ExecContext(ctx, query, userID, time.Now())
+	return err
+}
+
+// DeleteStaleSessions drops sessions that expired or were revoked before
+// the given time.
+func (u *UserRepository) DeleteStaleSessions(ctx context.Context, userID uuid.UUID, before time.Time) error {
+	query := `DELETE FROM Sessions WHERE user_id = $1 AND (expires_at < $2 OR revoked_at < $2)`
+
+	_, err := u.db.ExecContext(ctx, query, userID, before)
+	return err
+}
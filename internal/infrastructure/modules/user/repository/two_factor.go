@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"Finance-Manager-System/internal/infrastructure/modules/user/domain"
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+func (u *UserRepository) GetUserByID(ctx context.Context, id uuid.UUID) (*domain.User, error) {
+	query := `SELECT * FROM Users WHERE user_id = $1`
+	var user domain.User
+
+	err := u.db.GetContext(ctx, &user, query, id)
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// SetTOTPSecret stores a secret that is not enabled yet; a nil secret turns
+// 2FA off. Recovery codes are dropped either way.
+func (u *UserRepository) SetTOTPSecret(ctx context.Context, id uuid.UUID, secret *string) error {
+	return u.inTx(ctx, func(tx *sqlx.Tx) error {
+		query := `UPDATE Users SET totp_secret = $2, totp_enabled = false, totp_last_step = 0, updated_at = $3
+		WHERE user_id = $1`
+		if _, err := tx.ExecContext(ctx, query, id, secret, time.Now()); err != nil {
+			return err
+		}
+		_, err := tx.ExecContext(ctx, `DELETE FROM RecoveryCodes WHERE user_id = $1`, id)
+		return err
+	})
+}
+
+// EnableTOTP turns 2FA on with the step of the code that confirmed it and
+// the hashes of fresh recovery codes.
+func (u *UserRepository) EnableTOTP(ctx context.Context, id uuid.UUID, step int64, codeHashes []string) error {
+	return u.inTx(ctx, func(tx *sqlx.Tx) error {
+		query := `UPDATE Users SET totp_enabled = true, totp_last_step = $2, updated_at = $3
+		WHERE user_id = $1`
+		if _, err := tx.ExecContext(ctx, query, id, step, time.Now()); err != nil {
+			return err
+		}
+		return replaceRecoveryCodes(ctx, tx, id, codeHashes)
+	})
+}
+
+// UseTOTPStep records the step of an accepted code. It reports false when
+// the same or a later step was already used.
+func (u *UserRepository) UseTOTPStep(ctx context.Context, id uuid.UUID, step int64) (bool, error) {
+	query := `UPDATE Users SET totp_last_step = $2 WHERE user_id = $1 AND totp_last_step < $2`
+
+	res, err := u.db.ExecContext(ctx, query, id, step)
+	if err != nil {
+		return false, err
+	}
+	rows, err := res.RowsAffected()
+	return rows == 1, err
+}
+
+func (u *UserRepository) ReplaceRecoveryCodes(ctx context.Context, id uuid.UUID, codeHashes []string) error {
+	return u.inTx(ctx, func(tx *sqlx.Tx) error {
+		return replaceRecoveryCodes(ctx, tx, id, codeHashes)
+	})
+}
+
+func (u *UserRepository) GetUnusedRecoveryCodes(ctx context.Context, id uuid.UUID) ([]domain.RecoveryCode, error) {
+	query := `SELECT code_id, user_id, code_hash, used_at FROM RecoveryCodes WHERE user_id = $1 AND used_at IS NULL`
+	codes := make([]domain.RecoveryCode, 0)
+
+	err := u.db.SelectContext(ctx, &codes, query, id)
+	return codes, err
+}
+
+// UseRecoveryCode marks the code used unless that already happened.
+func (u *UserRepository) UseRecoveryCode(ctx context.Context, codeID uuid.UUID) (bool, error) {
+	query := `UPDATE RecoveryCodes SET used_at = $2 WHERE code_id = $1 AND used_at IS NULL`
+
+	res, err := u.db.ExecContext(ctx, query, codeID, time.Now())
+	if err != nil {
+		return false, err
+	}
+	rows, err := res.RowsAffected()
+	return rows == 1, err
+}
+
+func replaceRecoveryCodes(ctx context.Context, tx *sqlx.Tx, id uuid.UUID, codeHashes []string) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM RecoveryCodes WHERE user_id = $1`, id); err != nil {
+		return err
+	}
+	for _, hash := range codeHashes {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO RecoveryCodes(user_id, code_hash) VALUES($1, $2)`, id, hash); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (u *UserRepository) inTx(ctx context.Context, fn func(tx *sqlx.Tx) error) error {
+	tx, err := u.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
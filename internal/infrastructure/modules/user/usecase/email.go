@@ -0,0 +1,164 @@
+package usecase
+
+import (
+	"Finance-Manager-System/internal/infrastructure/mailer"
+	"Finance-Manager-System/internal/infrastructure/modules/user/domain"
+	"Finance-Manager-System/internal/infrastructure/ratelimit"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type Mailer interface {
+	Send(ctx context.Context, msg mailer.Message) error
+}
+
+// EmailLimiter counts requests per bucket and key in fixed windows.
+type EmailLimiter interface {
+	Allow(ctx context.Context, bucket string, key string, rule ratelimit.Rule) (ratelimit.Result, error)
+}
+
+// EmailSettings configures the links sent by email: they point to
+// LinkBaseURL and stop working after their TTL. ResetLimit caps the reset
+// emails sent to one address.
+type EmailSettings struct {
+	LinkBaseURL string
+	ResetTTL    time.Duration
+	VerifyTTL   time.Duration
+	ResetLimit  ratelimit.Rule
+}
+
+// SendEmailVerification emails a new verification link; earlier links stop
+// working.
+func (u *UserCase) SendEmailVerification(ctx context.Context, userID uuid.UUID) error {
+	user, err := u.db.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user.EmailVerifiedAt != nil {
+		return domain.ErrEmailAlreadyVerified
+	}
+	msg, err := u.verificationMessage(ctx, user)
+	if err != nil {
+		return err
+	}
+	return u.mail.Send(ctx, msg)
+}
+
+func (u *UserCase) VerifyEmail(ctx context.Context, token string) error {
+	_, err := u.db.VerifyEmail(ctx, domain.HashEmailToken(token), time.Now())
+	return err
+}
+
+// RequestPasswordReset emails a reset link. It succeeds whether or not the
+// email is registered and does not wait for the mail server, so neither the
+// answer nor its timing can be used to find accounts. Requests over the
+// per-address limit are dropped.
+func (u *UserCase) RequestPasswordReset(ctx context.Context, email string) error {
+	email = strings.ToLower(strings.TrimSpace(email))
+
+	allowed, err := u.limiter.Allow(ctx, "password_reset", email, u.emails.ResetLimit)
+	if err != nil {
+		return err
+	}
+	if !allowed.Allowed {
+		zap.L().Warn("password_reset_throttled", zap.Duration("retry_after", allowed.RetryAfter))
+		return nil
+	}
+
+	user, err := u.db.GetUserByEmailOrLogin(ctx, email)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && user.Email != email) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	token, err := u.newEmailToken(ctx, user.User_id, domain.PurposeResetPassword, u.emails.ResetTTL)
+	if err != nil {
+		return err
+	}
+	msg := mailer.Message{
+		To:      user.Email,
+		Subject: "Сброс пароля",
+		Body: fmt.Sprintf("Здравствуйте, %s!\n\n"+
+			"Чтобы задать новый пароль, перейдите по ссылке:\n%s\n\n"+
+			"Ссылка действует %s. Если вы не запрашивали сброс пароля, просто проигнорируйте это письмо.\n",
+			user.Login, u.link("/reset-password", token), formatTTL(u.emails.ResetTTL)),
+	}
+	u.sendInBackground(ctx, msg, user.User_id, "password_reset_email_failed")
+	return nil
+}
+
+// ResetPassword sets a new password with a token from the reset email and
+// signs out every session.
+func (u *UserCase) ResetPassword(ctx context.Context, token string, password string) error {
+	if password == "" {
+		return domain.ErrInvalidPassword
+	}
+	bytesPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	_, err = u.db.ResetPassword(ctx, domain.HashEmailToken(token), string(bytesPassword), time.Now())
+	return err
+}
+
+// verificationMessage issues a verification token for user and returns the
+// email with its link.
+func (u *UserCase) verificationMessage(ctx context.Context, user *domain.User) (mailer.Message, error) {
+	token, err := u.newEmailToken(ctx, user.User_id, domain.PurposeVerifyEmail, u.emails.VerifyTTL)
+	if err != nil {
+		return mailer.Message{}, err
+	}
+	return mailer.Message{
+		To:      user.Email,
+		Subject: "Подтверждение email",
+		Body: fmt.Sprintf("Здравствуйте, %s!\n\n"+
+			"Подтвердите адрес электронной почты, перейдя по ссылке:\n%s\n\n"+
+			"Ссылка действует %s.\n",
+			user.Login, u.link("/verify-email", token), formatTTL(u.emails.VerifyTTL)),
+	}, nil
+}
+
+// sendInBackground delivers msg without making the request wait for the mail
+// server. Failures are only logged under event.
+func (u *UserCase) sendInBackground(ctx context.Context, msg mailer.Message, userID uuid.UUID, event string) {
+	ctx = context.WithoutCancel(ctx)
+	go func() {
+		if err := u.mail.Send(ctx, msg); err != nil {
+			zap.L().Error(event, zap.String("user_id", userID.String()), zap.Error(err))
+		}
+	}()
+}
+
+func (u *UserCase) newEmailToken(ctx context.Context, userID uuid.UUID, purpose domain.EmailTokenPurpose, ttl time.Duration) (string, error) {
+	emailToken, token, err := domain.NewEmailToken(userID, purpose, ttl, time.Now())
+	if err != nil {
+		return "", err
+	}
+	if err := u.db.CreateEmailToken(ctx, emailToken); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+func (u *UserCase) link(path string, token string) string {
+	return strings.TrimRight(u.emails.LinkBaseURL, "/") + path + "?token=" + url.QueryEscape(token)
+}
+
+func formatTTL(ttl time.Duration) string {
+	if ttl >= time.Hour && ttl%time.Hour == 0 {
+		return fmt.Sprintf("%d ч.", int(ttl.Hours()))
+	}
+	return fmt.Sprintf("%d мин.", int(ttl.Minutes()))
+}
@@ -0,0 +1,220 @@
+package usecase
+
+import (
+	"Finance-Manager-System/internal/infrastructure/modules/user/domain"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// challengeTTL is how long the second login step may take.
+const challengeTTL = 5 * time.Minute
+
+// SetupTwoFactor generates a new secret for the user to add to an
+// authenticator app. It takes effect only after EnableTwoFactor.
+func (u *UserCase) SetupTwoFactor(ctx context.Context, userID uuid.UUID) (*domain.TwoFactorSetup, error) {
+	user, err := u.db.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TOTPEnabled {
+		return nil, domain.ErrTwoFactorAlreadyEnabled
+	}
+
+	secret, err := domain.NewTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	if err := u.db.SetTOTPSecret(ctx, userID, &secret); err != nil {
+		return nil, err
+	}
+
+	return &domain.TwoFactorSetup{
+		Secret:     secret,
+		OTPAuthURI: domain.OTPAuthURI(user.Email, secret),
+	}, nil
+}
+
+// EnableTwoFactor turns 2FA on once a code from the new secret checks out and
+// returns the recovery codes, which are not shown again.
+func (u *UserCase) EnableTwoFactor(ctx context.Context, userID uuid.UUID, code string) ([]string, error) {
+	user, err := u.db.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TOTPEnabled {
+		return nil, domain.ErrTwoFactorAlreadyEnabled
+	}
+	if user.TOTPSecret == nil {
+		return nil, domain.ErrTwoFactorNotSetUp
+	}
+
+	step, ok := domain.VerifyTOTP(*user.TOTPSecret, code, time.Now(), user.TOTPLastStep)
+	if !ok {
+		return nil, domain.ErrInvalidTwoFactorCode
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := u.db.EnableTOTP(ctx, userID, step, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// DisableTwoFactor needs both the password and a current code or recovery
+// code, so a stolen session alone cannot turn 2FA off.
+func (u *UserCase) DisableTwoFactor(ctx context.Context, userID uuid.UUID, password string, code string) error {
+	user, err := u.db.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !user.TOTPEnabled {
+		return domain.ErrTwoFactorNotEnabled
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.HashPassword), []byte(password)); err != nil {
+		return domain.ErrInvalidCredentials
+	}
+	if err := u.verifySecondFactor(ctx, user, code); err != nil {
+		return err
+	}
+
+	return u.db.SetTOTPSecret(ctx, userID, nil)
+}
+
+// RegenerateRecoveryCodes replaces all recovery codes, used or not.
+func (u *UserCase) RegenerateRecoveryCodes(ctx context.Context, userID uuid.UUID, code string) ([]string, error) {
+	user, err := u.db.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.TOTPEnabled {
+		return nil, domain.ErrTwoFactorNotEnabled
+	}
+	if err := u.verifySecondFactor(ctx, user, code); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := u.db.ReplaceRecoveryCodes(ctx, userID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// CompleteLoginChallenge is the second login step: it takes the challenge
+// token from LoginUser and a TOTP or recovery code and starts the session.
+func (u *UserCase) CompleteLoginChallenge(ctx context.Context, challengeToken, code, userAgent, ip string) (*domain.TokenPair, error) {
+	claims, err := u.tokens.Verify(challengeToken)
+	if err != nil {
+		return nil, domain.ErrInvalidChallenge
+	}
+	if typ, _ := claims["typ"].(string); typ != domain.ChallengeTokenType {
+		return nil, domain.ErrInvalidChallenge
+	}
+	subject, _ := claims["sub"].(string)
+	userID, err := uuid.Parse(subject)
+	if err != nil {
+		return nil, domain.ErrInvalidChallenge
+	}
+
+	guardKey := "2fa:" + userID.String()
+	if err := u.checkLocked(ctx, guardKey); err != nil {
+		return nil, err
+	}
+
+	user, err := u.db.GetUserByID(ctx, userID)
+	if err != nil || !user.TOTPEnabled {
+		return nil, domain.ErrInvalidChallenge
+	}
+	if err := u.verifySecondFactor(ctx, user, code); err != nil {
+		if errors.Is(err, domain.ErrInvalidTwoFactorCode) {
+			return nil, u.failAttempt(ctx, guardKey, err)
+		}
+		return nil, err
+	}
+	if err := u.guard.Reset(ctx, guardKey); err != nil {
+		return nil, err
+	}
+
+	return u.startSession(ctx, userID, userAgent, ip)
+}
+
+// verifySecondFactor accepts a TOTP code of a step not used before or an
+// unused recovery code, and uses it up.
+func (u *UserCase) verifySecondFactor(ctx context.Context, user *domain.User, code string) error {
+	if user.TOTPSecret == nil {
+		return domain.ErrTwoFactorNotSetUp
+	}
+
+	if step, ok := domain.VerifyTOTP(*user.TOTPSecret, code, time.Now(), user.TOTPLastStep); ok {
+		used, err := u.db.UseTOTPStep(ctx, user.User_id, step)
+		if err != nil {
+			return err
+		}
+		if !used {
+			return domain.ErrInvalidTwoFactorCode
+		}
+		return nil
+	}
+
+	normalized := domain.NormalizeRecoveryCode(code)
+	if normalized == "" {
+		return domain.ErrInvalidTwoFactorCode
+	}
+	recoveryCodes, err := u.db.GetUnusedRecoveryCodes(ctx, user.User_id)
+	if err != nil {
+		return err
+	}
+	for _, recovery := range recoveryCodes {
+		if bcrypt.CompareHashAndPassword([]byte(recovery.CodeHash), []byte(normalized)) != nil {
+			continue
+		}
+		used, err := u.db.UseRecoveryCode(ctx, recovery.CodeID)
+		if err != nil {
+			return err
+		}
+		if !used {
+			return domain.ErrInvalidTwoFactorCode
+		}
+		return nil
+	}
+	return domain.ErrInvalidTwoFactorCode
+}
+
+func (u *UserCase) issueChallenge(userID uuid.UUID, now time.Time) (string, error) {
+	return u.tokens.Sign(jwt.MapClaims{
+		"typ": domain.ChallengeTokenType,
+		"sub": userID.String(),
+		"iat": now.Unix(),
+		"nbf": now.Unix(),
+		"exp": now.Add(challengeTTL).Unix(),
+	})
+}
+
+// newRecoveryCodes returns the codes to show and their bcrypt hashes, made
+// the same way as password hashes.
+func newRecoveryCodes() ([]string, []string, error) {
+	codes, err := domain.NewRecoveryCodes()
+	if err != nil {
+		return nil, nil, err
+	}
+	hashes := make([]string, 0, len(codes))
+	for _, code := range codes {
+		hash, err := bcrypt.GenerateFromPassword([]byte(domain.NormalizeRecoveryCode(code)), bcrypt.DefaultCost)
+		if err != nil {
+			return nil, nil, err
+		}
+		hashes = append(hashes, string(hash))
+	}
+	return codes, hashes, nil
+}
@@ -4,60 +4,238 @@ import (
 	"Finance-Manager-System/internal/infrastructure/modules/user/domain"
 	"Finance-Manager-System/internal/infrastructure/modules/user/repository"
 	"context"
+	"errors"
 	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
+	"go.uber.org/zap"
 	"golang.org/x/crypto/bcrypt"
 )
 
+// staleSessionAge is how long expired and revoked sessions are kept before
+// they are cleaned up on the next login.
+const staleSessionAge = 30 * 24 * time.Hour
+
 type UserCase struct {
 	db           *repository.UserRepository
-	jwtSecretKey []byte
+	tokens       TokenIssuer
+	accessTTL    time.Duration
+	refreshTTL   time.Duration
 	catBootstrap DefaultCategoryBootstrapper
+	guard        LoginGuard
+	mail         Mailer
+	emails       EmailSettings
+	limiter      EmailLimiter
+}
+
+// TokenIssuer signs access and login challenge tokens, adding the issuer,
+// audience and key ID, and verifies them.
+type TokenIssuer interface {
+	Sign(claims jwt.MapClaims) (string, error)
+	Verify(tokenStr string) (jwt.MapClaims, error)
+}
+
+// LoginGuard counts failed sign-in attempts per key and locks the key for
+// a growing time once there are too many.
+type LoginGuard interface {
+	Locked(ctx context.Context, key string) (time.Duration, error)
+	Fail(ctx context.Context, key string) (time.Duration, error)
+	Reset(ctx context.Context, key string) error
 }
 
 type DefaultCategoryBootstrapper interface {
 	EnsureDefaultCategories(ctx context.Context, userID uuid.UUID) error
 }
 
-func NewUserCase(db *repository.UserRepository, jwtSecret string, catBootstrap DefaultCategoryBootstrapper) *UserCase {
+func NewUserCase(db *repository.UserRepository, tokens TokenIssuer, accessTTL time.Duration, refreshTTL time.Duration, catBootstrap DefaultCategoryBootstrapper, guard LoginGuard, mail Mailer, emails EmailSettings, limiter EmailLimiter) *UserCase {
 	return &UserCase{
 		db:           db,
-		jwtSecretKey: []byte(jwtSecret),
+		tokens:       tokens,
+		accessTTL:    accessTTL,
+		refreshTTL:   refreshTTL,
 		catBootstrap: catBootstrap,
+		guard:        guard,
+		mail:         mail,
+		emails:       emails,
+		limiter:      limiter,
 	}
 }
 
-func (u *UserCase) LoginUser(ctx context.Context, identifier, password string) (string, error) {
+// LoginUser checks the password and starts a session. With 2FA enabled it
+// returns a challenge token instead, to be completed by
+// CompleteLoginChallenge. Repeated failures lock the identifier for the
+// client address they came from, so failures from elsewhere cannot lock the
+// owner out.
+func (u *UserCase) LoginUser(ctx context.Context, identifier, password, userAgent, ip string) (*domain.LoginResult, error) {
 	identifier = strings.ToLower(strings.TrimSpace(identifier))
+	guardKey := "login:" + identifier + "|" + ip
+	if err := u.checkLocked(ctx, guardKey); err != nil {
+		return nil, err
+	}
 
 	user, err := u.db.GetUserByEmailOrLogin(ctx, identifier)
 	if err != nil {
-		return "", domain.ErrInvalidCredentials
+		return nil, u.failAttempt(ctx, guardKey, domain.ErrInvalidCredentials)
 	}
 
 	err = bcrypt.CompareHashAndPassword([]byte(user.HashPassword), []byte(password))
 	if err != nil {
-		return "", domain.ErrInvalidCredentials
+		return nil, u.failAttempt(ctx, guardKey, domain.ErrInvalidCredentials)
+	}
+	if err := u.guard.Reset(ctx, guardKey); err != nil {
+		return nil, err
 	}
 
 	if err := u.catBootstrap.EnsureDefaultCategories(ctx, user.User_id); err != nil {
-		return "", err
+		return nil, err
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"user_id": user.User_id.String(),
-		"exp":     time.Now().Add(time.Hour * 72).Unix(),
-	})
+	if user.TOTPEnabled {
+		challenge, err := u.issueChallenge(user.User_id, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		return &domain.LoginResult{ChallengeToken: challenge, ChallengeExpiresIn: int64(challengeTTL.Seconds())}, nil
+	}
+
+	tokens, err := u.startSession(ctx, user.User_id, userAgent, ip)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.LoginResult{Tokens: tokens}, nil
+}
+
+func (u *UserCase) checkLocked(ctx context.Context, key string) error {
+	retryAfter, err := u.guard.Locked(ctx, key)
+	if err != nil {
+		return err
+	}
+	if retryAfter > 0 {
+		return &domain.LockedError{RetryAfter: retryAfter}
+	}
+	return nil
+}
+
+// failAttempt records a failed attempt and returns cause, or the lock error
+// when this attempt was one too many.
+func (u *UserCase) failAttempt(ctx context.Context, key string, cause error) error {
+	retryAfter, err := u.guard.Fail(ctx, key)
+	if err != nil {
+		return err
+	}
+	if retryAfter > 0 {
+		zap.L().Warn("auth_login_locked", zap.String("key", key), zap.Duration("retry_after", retryAfter))
+		return &domain.LockedError{RetryAfter: retryAfter}
+	}
+	return cause
+}
+
+func (u *UserCase) startSession(ctx context.Context, userID uuid.UUID, userAgent, ip string) (*domain.TokenPair, error) {
+	now := time.Now()
+	if err := u.db.DeleteStaleSessions(ctx, userID, now.Add(-staleSessionAge)); err != nil {
+		return nil, err
+	}
+
+	session, refreshToken, err := domain.NewSession(userID, userAgent, ip, u.refreshTTL, now)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.db.CreateSession(ctx, session); err != nil {
+		return nil, err
+	}
+
+	return u.issueTokens(session, refreshToken, now)
+}
+
+// RefreshSession exchanges a refresh token for a new token pair. Every
+// refresh token works once: presenting one that was already rotated means
+// it leaked, so the whole session is revoked.
+func (u *UserCase) RefreshSession(ctx context.Context, refreshToken string) (*domain.TokenPair, error) {
+	hash := domain.HashRefreshToken(refreshToken)
+
+	session, err := u.db.GetSessionByTokenHash(ctx, hash)
+	if err != nil {
+		if errors.Is(err, domain.ErrSessionNotFound) {
+			return nil, domain.ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
 
-	tokenString, err := token.SignedString(u.jwtSecretKey)
+	if session.RefreshTokenHash != hash {
+		zap.L().Warn("auth_refresh_token_reused", zap.String("session_id", session.SessionID.String()))
+		if err := u.db.RevokeSession(ctx, session.UserID, session.SessionID); err != nil && !errors.Is(err, domain.ErrSessionNotFound) {
+			return nil, err
+		}
+		return nil, domain.ErrInvalidRefreshToken
+	}
+
+	now := time.Now()
+	if !session.IsActive(now) {
+		return nil, domain.ErrInvalidRefreshToken
+	}
+
+	newToken, err := session.Rotate(u.refreshTTL, now)
+	if err != nil {
+		return nil, err
+	}
+	rotated, err := u.db.RotateSession(ctx, session)
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+	if !rotated {
+		return nil, domain.ErrInvalidRefreshToken
 	}
 
-	return tokenString, nil
+	return u.issueTokens(session, newToken, now)
+}
+
+func (u *UserCase) GetSessions(ctx context.Context, userID uuid.UUID, currentSessionID uuid.UUID) ([]domain.Session, error) {
+	sessions, err := u.db.GetActiveSessions(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range sessions {
+		sessions[i].Current = sessions[i].SessionID == currentSessionID
+	}
+	return sessions, nil
+}
+
+func (u *UserCase) RevokeSession(ctx context.Context, userID uuid.UUID, sessionID uuid.UUID) error {
+	return u.db.RevokeSession(ctx, userID, sessionID)
+}
+
+func (u *UserCase) RevokeAllSessions(ctx context.Context, userID uuid.UUID) error {
+	return u.db.RevokeAllSessions(ctx, userID)
+}
+
+// IsSessionActive lets RequireAuth reject access tokens of revoked sessions.
+func (u *UserCase) IsSessionActive(ctx context.Context, userID uuid.UUID, sessionID uuid.UUID) (bool, error) {
+	return u.db.IsSessionActive(ctx, userID, sessionID)
+}
+
+func (u *UserCase) issueTokens(session *domain.Session, refreshToken string, now time.Time) (*domain.TokenPair, error) {
+	tokenString, err := u.tokens.Sign(jwt.MapClaims{
+		"typ":     domain.AccessTokenType,
+		"sub":     session.UserID.String(),
+		"user_id": session.UserID.String(),
+		"sid":     session.SessionID.String(),
+		"iat":     now.Unix(),
+		"nbf":     now.Unix(),
+		"exp":     now.Add(u.accessTTL).Unix(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.TokenPair{
+		AccessToken:  tokenString,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(u.accessTTL.Seconds()),
+		SessionID:    session.SessionID,
+	}, nil
 }
 
 func (u *UserCase) RegistrateUser(ctx context.Context, email string, login string, password string) (uuid.UUID, error) {
@@ -92,15 +270,41 @@ func (u *UserCase) RegistrateUser(ctx context.Context, email string, login strin
 		return uuid.Nil, err
 	}
 
+	msg, err := u.verificationMessage(ctx, user)
+	if err != nil {
+		zap.L().Warn("email_verification_send_failed", zap.String("user_id", id.String()), zap.Error(err))
+	} else {
+		u.sendInBackground(ctx, msg, id, "email_verification_send_failed")
+	}
+
 	return id, nil
 }
 
-func (u *UserCase) ChangeUserPassword(ctx context.Context, id uuid.UUID, password string) error {
+// ChangeUserPassword needs the current password, so a stolen session alone
+// cannot take over the account. Repeated wrong guesses lock it like sign-in.
+func (u *UserCase) ChangeUserPassword(ctx context.Context, id uuid.UUID, currentPassword string, password string) error {
+	if password == "" {
+		return domain.ErrInvalidPassword
+	}
+	guardKey := "password:" + id.String()
+	if err := u.checkLocked(ctx, guardKey); err != nil {
+		return err
+	}
+	user, err := u.db.GetUserByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.HashPassword), []byte(currentPassword)); err != nil {
+		return u.failAttempt(ctx, guardKey, domain.ErrWrongCurrentPassword)
+	}
+	if err := u.guard.Reset(ctx, guardKey); err != nil {
+		return err
+	}
+
 	bytesPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
 		return err
 	}
 
-	err = u.db.UpdateUserInfo(ctx, id, string(bytesPassword))
-	return err
+	return u.db.ChangePassword(ctx, id, string(bytesPassword))
 }
@@ -0,0 +1,53 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+)
+
+const keyPrefix = "ratelimit:"
+
+// Rule allows Limit requests per Window. A rule without a limit allows
+// everything.
+type Rule struct {
+	Limit  int64
+	Window time.Duration
+}
+
+func (r Rule) Enabled() bool {
+	return r.Limit > 0 && r.Window > 0
+}
+
+type Result struct {
+	Allowed   bool
+	Limit     int64
+	Remaining int64
+	// RetryAfter is when the window resets.
+	RetryAfter time.Duration
+}
+
+// Limiter counts requests in fixed windows per bucket and key, such as the
+// "ip" bucket keyed by client address.
+type Limiter struct {
+	store Store
+}
+
+func NewLimiter(store Store) *Limiter {
+	return &Limiter{store: store}
+}
+
+func (l *Limiter) Allow(ctx context.Context, bucket string, key string, rule Rule) (Result, error) {
+	if !rule.Enabled() {
+		return Result{Allowed: true}, nil
+	}
+	count, ttl, err := l.store.Incr(ctx, keyPrefix+bucket+":"+key, rule.Window)
+	if err != nil {
+		return Result{}, err
+	}
+	return Result{
+		Allowed:    count <= rule.Limit,
+		Limit:      rule.Limit,
+		Remaining:  max(rule.Limit-count, 0),
+		RetryAfter: ttl,
+	}, nil
+}
@@ -0,0 +1,117 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func newTestStore() (*MemoryStore, *fakeClock) {
+	clock := &fakeClock{now: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)}
+	store := NewMemoryStore()
+	store.now = clock.Now
+	return store, clock
+}
+
+func TestLimiterWindow(t *testing.T) {
+	store, clock := newTestStore()
+	limiter := NewLimiter(store)
+	ctx := context.Background()
+	rule := Rule{Limit: 3, Window: time.Minute}
+
+	for i := int64(1); i <= 3; i++ {
+		res, err := limiter.Allow(ctx, "ip", "10.0.0.1", rule)
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if !res.Allowed || res.Remaining != 3-i {
+			t.Fatalf("request %d: expected allowed with %d remaining, got %+v", i, 3-i, res)
+		}
+	}
+
+	clock.now = clock.now.Add(20 * time.Second)
+	res, _ := limiter.Allow(ctx, "ip", "10.0.0.1", rule)
+	if res.Allowed || res.Remaining != 0 {
+		t.Fatalf("expected the fourth request to be denied, got %+v", res)
+	}
+	if res.RetryAfter != 40*time.Second {
+		t.Fatalf("expected retry after 40s, got %s", res.RetryAfter)
+	}
+
+	if res, _ := limiter.Allow(ctx, "ip", "10.0.0.2", rule); !res.Allowed {
+		t.Fatalf("another key should have its own counter")
+	}
+	if res, _ := limiter.Allow(ctx, "user", "10.0.0.1", rule); !res.Allowed {
+		t.Fatalf("another bucket should have its own counter")
+	}
+
+	clock.now = clock.now.Add(40 * time.Second)
+	if res, _ := limiter.Allow(ctx, "ip", "10.0.0.1", rule); !res.Allowed || res.Remaining != 2 {
+		t.Fatalf("expected a new window, got %+v", res)
+	}
+
+	if res, _ := limiter.Allow(ctx, "ip", "10.0.0.1", Rule{}); !res.Allowed {
+		t.Fatalf("a rule without a limit should allow everything")
+	}
+}
+
+func TestLockoutProgressive(t *testing.T) {
+	store, clock := newTestStore()
+	lockout := NewLockout(store, LockoutPolicy{
+		MaxFailures:   3,
+		BaseLock:      time.Minute,
+		MaxLock:       5 * time.Minute,
+		FailureWindow: 24 * time.Hour,
+	})
+	ctx := context.Background()
+	key := "login:alice"
+
+	for i := 0; i < 2; i++ {
+		if lock, _ := lockout.Fail(ctx, key); lock != 0 {
+			t.Fatalf("failure %d should not lock, got %s", i+1, lock)
+		}
+	}
+	if lock, _ := lockout.Locked(ctx, key); lock != 0 {
+		t.Fatalf("expected no lock yet, got %s", lock)
+	}
+
+	wantLocks := []time.Duration{time.Minute, 2 * time.Minute, 4 * time.Minute, 5 * time.Minute, 5 * time.Minute}
+	for _, want := range wantLocks {
+		lock, err := lockout.Fail(ctx, key)
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if lock != want {
+			t.Fatalf("expected a lock of %s, got %s", want, lock)
+		}
+		if locked, _ := lockout.Locked(ctx, key); locked != want {
+			t.Fatalf("expected Locked to report %s, got %s", want, locked)
+		}
+		clock.now = clock.now.Add(want)
+		if locked, _ := lockout.Locked(ctx, key); locked != 0 {
+			t.Fatalf("the lock should expire after %s", want)
+		}
+	}
+
+	if err := lockout.Reset(ctx, key); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if lock, _ := lockout.Fail(ctx, key); lock != 0 {
+		t.Fatalf("a reset should start counting failures again, got %s", lock)
+	}
+
+	clock.now = clock.now.Add(25 * time.Hour)
+	for i := 0; i < 2; i++ {
+		if lock, _ := lockout.Fail(ctx, key); lock != 0 {
+			t.Fatalf("failures should be forgotten after the window, got %s", lock)
+		}
+	}
+}
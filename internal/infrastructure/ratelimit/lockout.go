@@ -0,0 +1,76 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+)
+
+// LockoutPolicy locks a key after MaxFailures failures within FailureWindow.
+// Every further failure doubles the lock, up to MaxLock, until a success
+// resets the count or the window runs out.
+type LockoutPolicy struct {
+	MaxFailures   int64
+	BaseLock      time.Duration
+	MaxLock       time.Duration
+	FailureWindow time.Duration
+}
+
+type Lockout struct {
+	store  Store
+	policy LockoutPolicy
+}
+
+func NewLockout(store Store, policy LockoutPolicy) *Lockout {
+	return &Lockout{store: store, policy: policy}
+}
+
+// Locked returns how long the key stays locked, or zero.
+func (l *Lockout) Locked(ctx context.Context, key string) (time.Duration, error) {
+	locked, ttl, err := l.store.Get(ctx, l.lockKey(key))
+	if err != nil || locked == 0 {
+		return 0, err
+	}
+	return ttl, nil
+}
+
+// Fail records a failure and returns the lock it caused, or zero.
+func (l *Lockout) Fail(ctx context.Context, key string) (time.Duration, error) {
+	if l.policy.MaxFailures <= 0 {
+		return 0, nil
+	}
+	failures, _, err := l.store.Incr(ctx, l.failKey(key), l.policy.FailureWindow)
+	if err != nil {
+		return 0, err
+	}
+	if failures < l.policy.MaxFailures {
+		return 0, nil
+	}
+
+	lock := l.policy.BaseLock
+	for i := l.policy.MaxFailures; i < failures && lock < l.policy.MaxLock; i++ {
+		lock *= 2
+	}
+	lock = min(lock, l.policy.MaxLock)
+	if lock <= 0 {
+		return 0, nil
+	}
+	if err := l.store.Set(ctx, l.lockKey(key), 1, lock); err != nil {
+		return 0, err
+	}
+	return lock, nil
+}
+
+func (l *Lockout) Reset(ctx context.Context, key string) error {
+	if err := l.store.Delete(ctx, l.failKey(key)); err != nil {
+		return err
+	}
+	return l.store.Delete(ctx, l.lockKey(key))
+}
+
+func (l *Lockout) failKey(key string) string {
+	return keyPrefix + "failures:" + key
+}
+
+func (l *Lockout) lockKey(key string) string {
+	return keyPrefix + "lock:" + key
+}
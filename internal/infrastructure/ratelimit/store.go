@@ -0,0 +1,145 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"Finance-Manager-System/internal/infrastructure/cache"
+)
+
+// Store keeps counters that expire. Counters of all instances are shared
+// through Redis; the in-memory store only counts for this instance.
+type Store interface {
+	// Incr adds one hit to the counter, starting a window on the first hit,
+	// and returns the count and the time left in the window.
+	Incr(ctx context.Context, key string, window time.Duration) (int64, time.Duration, error)
+	Get(ctx context.Context, key string) (int64, time.Duration, error)
+	Set(ctx context.Context, key string, value int64, ttl time.Duration) error
+	Delete(ctx context.Context, key string) error
+}
+
+// NewStore uses Redis when the cache client is connected and falls back to
+// memory otherwise, and also whenever a Redis call fails.
+func NewStore(client *cache.Client) Store {
+	memory := NewMemoryStore()
+	if !client.Enabled() {
+		return memory
+	}
+	return &redisStore{client: client, fallback: memory}
+}
+
+type redisStore struct {
+	client   *cache.Client
+	fallback *MemoryStore
+}
+
+func (s *redisStore) Incr(ctx context.Context, key string, window time.Duration) (int64, time.Duration, error) {
+	count, ttl, err := s.client.IncrWindow(ctx, key, window)
+	if err != nil {
+		zap.L().Warn("rate_limit_redis_failed", zap.String("key", key), zap.Error(err))
+		return s.fallback.Incr(ctx, key, window)
+	}
+	return count, ttl, nil
+}
+
+func (s *redisStore) Get(ctx context.Context, key string) (int64, time.Duration, error) {
+	count, ttl, err := s.client.GetCounter(ctx, key)
+	if err != nil {
+		zap.L().Warn("rate_limit_redis_failed", zap.String("key", key), zap.Error(err))
+		return s.fallback.Get(ctx, key)
+	}
+	return count, ttl, nil
+}
+
+func (s *redisStore) Set(ctx context.Context, key string, value int64, ttl time.Duration) error {
+	if err := s.client.SetCounter(ctx, key, value, ttl); err != nil {
+		zap.L().Warn("rate_limit_redis_failed", zap.String("key", key), zap.Error(err))
+		return s.fallback.Set(ctx, key, value, ttl)
+	}
+	return nil
+}
+
+func (s *redisStore) Delete(ctx context.Context, key string) error {
+	_ = s.fallback.Delete(ctx, key)
+	if err := s.client.Delete(ctx, key); err != nil {
+		zap.L().Warn("rate_limit_redis_failed", zap.String("key", key), zap.Error(err))
+	}
+	return nil
+}
+
+// sweepInterval is how often expired counters are dropped from memory.
+const sweepInterval = time.Minute
+
+type memoryEntry struct {
+	count     int64
+	expiresAt time.Time
+}
+
+type MemoryStore struct {
+	mu        sync.Mutex
+	entries   map[string]memoryEntry
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{entries: make(map[string]memoryEntry), now: time.Now}
+}
+
+func (s *MemoryStore) Incr(ctx context.Context, key string, window time.Duration) (int64, time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+	entry, ok := s.entries[key]
+	if !ok || !now.Before(entry.expiresAt) {
+		entry = memoryEntry{expiresAt: now.Add(window)}
+	}
+	entry.count++
+	s.entries[key] = entry
+	return entry.count, entry.expiresAt.Sub(now), nil
+}
+
+func (s *MemoryStore) Get(ctx context.Context, key string) (int64, time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	entry, ok := s.entries[key]
+	if !ok || !now.Before(entry.expiresAt) {
+		return 0, 0, nil
+	}
+	return entry.count, entry.expiresAt.Sub(now), nil
+}
+
+func (s *MemoryStore) Set(ctx context.Context, key string, value int64, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries[key] = memoryEntry{count: value, expiresAt: s.now().Add(ttl)}
+	return nil
+}
+
+func (s *MemoryStore) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, key)
+	return nil
+}
+
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+	for key, entry := range s.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(s.entries, key)
+		}
+	}
+}
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_transactions_transfer;
+ALTER TABLE Transactions DROP CONSTRAINT IF EXISTS fk_transfer_transaction;
+ALTER TABLE Transactions DROP COLUMN IF EXISTS transfer_id;
+DROP TABLE IF EXISTS Transfers;
@@ -0,0 +1,35 @@
+CREATE TABLE IF NOT EXISTS Transfers (
+    transfer_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL,
+    from_account_id UUID NOT NULL,
+    to_account_id UUID NOT NULL,
+    amount BIGINT NOT NULL CHECK (amount > 0),
+    completed_at TIMESTAMPTZ NOT NULL,
+    comment TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_user_transfer
+        FOREIGN KEY (user_id)
+        REFERENCES Users(user_id)
+        ON DELETE CASCADE,
+
+    CONSTRAINT fk_from_account_transfer
+        FOREIGN KEY (from_account_id)
+        REFERENCES Accounts(account_id)
+        ON DELETE CASCADE,
+
+    CONSTRAINT fk_to_account_transfer
+        FOREIGN KEY (to_account_id)
+        REFERENCES Accounts(account_id)
+        ON DELETE CASCADE,
+
+    CONSTRAINT chk_transfer_accounts CHECK (from_account_id <> to_account_id)
+) WITH (fillfactor = 85);
+
+ALTER TABLE Transactions ADD COLUMN IF NOT EXISTS transfer_id UUID;
+ALTER TABLE Transactions DROP CONSTRAINT IF EXISTS fk_transfer_transaction;
+ALTER TABLE Transactions ADD CONSTRAINT fk_transfer_transaction
+    FOREIGN KEY (transfer_id)
+    REFERENCES Transfers(transfer_id)
+    ON DELETE CASCADE;
+CREATE INDEX IF NOT EXISTS idx_transactions_transfer ON Transactions(transfer_id) WHERE transfer_id IS NOT NULL;
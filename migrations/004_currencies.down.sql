@@ -0,0 +1,4 @@
+ALTER TABLE Transfers DROP COLUMN IF EXISTS to_amount;
+DROP INDEX IF EXISTS idx_exchange_rates_lookup;
+DROP TABLE IF EXISTS ExchangeRates;
+ALTER TABLE Users DROP COLUMN IF EXISTS base_currency;
@@ -0,0 +1,26 @@
+ALTER TABLE Users ADD COLUMN IF NOT EXISTS base_currency VARCHAR(3) NOT NULL DEFAULT 'RUB';
+
+CREATE TABLE IF NOT EXISTS ExchangeRates (
+    rate_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL,
+    from_currency VARCHAR(3) NOT NULL,
+    to_currency VARCHAR(3) NOT NULL,
+    rate_date DATE NOT NULL,
+    rate NUMERIC(20, 10) NOT NULL CHECK (rate > 0),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_user_exchange_rate
+        FOREIGN KEY (user_id)
+        REFERENCES Users(user_id)
+        ON DELETE CASCADE,
+
+    CONSTRAINT chk_exchange_rate_pair CHECK (from_currency <> to_currency),
+
+    UNIQUE(user_id, from_currency, to_currency, rate_date)
+) WITH (fillfactor = 85);
+
+CREATE INDEX IF NOT EXISTS idx_exchange_rates_lookup ON ExchangeRates(user_id, from_currency, to_currency, rate_date DESC);
+
+ALTER TABLE Transfers ADD COLUMN IF NOT EXISTS to_amount BIGINT;
+UPDATE Transfers SET to_amount = amount WHERE to_amount IS NULL;
+ALTER TABLE Transfers ALTER COLUMN to_amount SET NOT NULL;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_staged_imports_expires;
+DROP TABLE IF EXISTS StagedImports;
@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS StagedImports (
+    staging_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL,
+    account_id UUID,
+    account_name VARCHAR(50),
+    format VARCHAR(50) NOT NULL,
+    payload JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMPTZ NOT NULL,
+
+    CONSTRAINT fk_user_staged_import
+        FOREIGN KEY (user_id)
+        REFERENCES Users(user_id)
+        ON DELETE CASCADE,
+
+    CONSTRAINT fk_account_staged_import
+        FOREIGN KEY (account_id)
+        REFERENCES Accounts(account_id)
+        ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_staged_imports_expires ON StagedImports(expires_at);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_transactions_import_batch;
+ALTER TABLE Transactions DROP CONSTRAINT IF EXISTS fk_import_batch_transaction;
+ALTER TABLE Transactions DROP COLUMN IF EXISTS import_batch_id;
+DROP INDEX IF EXISTS idx_import_batches_account;
+DROP TABLE IF EXISTS ImportBatches;
@@ -0,0 +1,39 @@
+CREATE TABLE IF NOT EXISTS ImportBatches (
+    batch_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL,
+    account_id UUID NOT NULL,
+    format VARCHAR(50) NOT NULL,
+    file_name TEXT NOT NULL DEFAULT '',
+    file_hash VARCHAR(64) NOT NULL,
+    imported_count INTEGER NOT NULL DEFAULT 0,
+    skipped_count INTEGER NOT NULL DEFAULT 0,
+    period_start TIMESTAMPTZ,
+    period_end TIMESTAMPTZ,
+    balance_before BIGINT NOT NULL DEFAULT 0,
+    balance_after BIGINT NOT NULL DEFAULT 0,
+    previous_synced_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_user_import_batch
+        FOREIGN KEY (user_id)
+        REFERENCES Users(user_id)
+        ON DELETE CASCADE,
+
+    CONSTRAINT fk_account_import_batch
+        FOREIGN KEY (account_id)
+        REFERENCES Accounts(account_id)
+        ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_import_batches_account ON ImportBatches(account_id, created_at DESC);
+
+-- The batch row is written after its transactions, once the counts are known,
+-- so the check is deferred to the end of the import transaction.
+ALTER TABLE Transactions ADD COLUMN IF NOT EXISTS import_batch_id UUID;
+ALTER TABLE Transactions DROP CONSTRAINT IF EXISTS fk_import_batch_transaction;
+ALTER TABLE Transactions ADD CONSTRAINT fk_import_batch_transaction
+    FOREIGN KEY (import_batch_id)
+    REFERENCES ImportBatches(batch_id)
+    ON DELETE SET NULL
+    DEFERRABLE INITIALLY DEFERRED;
+CREATE INDEX IF NOT EXISTS idx_transactions_import_batch ON Transactions(import_batch_id) WHERE import_batch_id IS NOT NULL;
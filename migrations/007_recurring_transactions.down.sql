@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS RecurringOccurrences;
+DROP INDEX IF EXISTS idx_recurring_templates_due;
+DROP INDEX IF EXISTS idx_recurring_templates_user;
+DROP TABLE IF EXISTS RecurringTemplates;
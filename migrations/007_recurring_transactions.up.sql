@@ -0,0 +1,58 @@
+CREATE TABLE IF NOT EXISTS RecurringTemplates (
+    template_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL,
+    account_id UUID NOT NULL,
+    category_id UUID,
+    name_template VARCHAR(255) NOT NULL,
+    is_income BOOLEAN NOT NULL DEFAULT false,
+    amount BIGINT NOT NULL CHECK (amount > 0),
+    comment TEXT,
+    frequency VARCHAR(16) NOT NULL,
+    interval_count INTEGER NOT NULL DEFAULT 1 CHECK (interval_count > 0),
+    day_of_month INTEGER CHECK (day_of_month BETWEEN 1 AND 31),
+    start_date TIMESTAMPTZ NOT NULL,
+    end_date TIMESTAMPTZ,
+    occurrence_count INTEGER CHECK (occurrence_count > 0),
+    next_run_at TIMESTAMPTZ,
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_user_recurring
+        FOREIGN KEY (user_id)
+        REFERENCES Users(user_id)
+        ON DELETE CASCADE,
+
+    CONSTRAINT fk_account_recurring
+        FOREIGN KEY (account_id)
+        REFERENCES Accounts(account_id)
+        ON DELETE CASCADE,
+
+    CONSTRAINT fk_category_recurring
+        FOREIGN KEY (category_id)
+        REFERENCES Category(category_id)
+        ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_recurring_templates_user ON RecurringTemplates(user_id);
+CREATE INDEX IF NOT EXISTS idx_recurring_templates_due ON RecurringTemplates(next_run_at) WHERE is_active = true AND next_run_at IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS RecurringOccurrences (
+    template_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    occurrence_at TIMESTAMPTZ NOT NULL,
+    status VARCHAR(16) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (template_id, occurrence_at),
+
+    CONSTRAINT fk_template_occurrence
+        FOREIGN KEY (template_id)
+        REFERENCES RecurringTemplates(template_id)
+        ON DELETE CASCADE,
+
+    CONSTRAINT fk_user_occurrence
+        FOREIGN KEY (user_id)
+        REFERENCES Users(user_id)
+        ON DELETE CASCADE
+);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_budgets_user_category;
+DROP TABLE IF EXISTS Budgets;
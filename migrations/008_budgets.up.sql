@@ -0,0 +1,26 @@
+CREATE TABLE IF NOT EXISTS Budgets (
+    budget_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL,
+    category_id UUID,
+    amount BIGINT NOT NULL CHECK (amount > 0),
+    period_type VARCHAR(16) NOT NULL,
+    period_days INTEGER CHECK (period_days BETWEEN 1 AND 366),
+    rollover BOOLEAN NOT NULL DEFAULT false,
+    start_date TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_user_budget
+        FOREIGN KEY (user_id)
+        REFERENCES Users(user_id)
+        ON DELETE CASCADE,
+
+    CONSTRAINT fk_category_budget
+        FOREIGN KEY (category_id)
+        REFERENCES Category(category_id)
+        ON DELETE CASCADE
+);
+
+-- One budget per category; a NULL category_id is the budget for uncategorized expenses.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_budgets_user_category
+    ON Budgets(user_id, COALESCE(category_id, '00000000-0000-0000-0000-000000000000'::uuid));
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_transactions_comment_trgm;
+DROP INDEX IF EXISTS idx_transactions_name_trgm;
+DROP INDEX IF EXISTS idx_transactions_user_amount;
+DROP INDEX IF EXISTS idx_transactions_user_completed;
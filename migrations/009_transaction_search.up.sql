@@ -0,0 +1,6 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS idx_transactions_user_completed ON Transactions(user_id, completed_at DESC, transaction_id DESC);
+CREATE INDEX IF NOT EXISTS idx_transactions_user_amount ON Transactions(user_id, amount, transaction_id);
+CREATE INDEX IF NOT EXISTS idx_transactions_name_trgm ON Transactions USING GIN (name_transaction gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_transactions_comment_trgm ON Transactions USING GIN (comment gin_trgm_ops);
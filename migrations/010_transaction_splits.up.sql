@@ -0,0 +1,28 @@
+CREATE TABLE IF NOT EXISTS TransactionSplits (
+    split_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    transaction_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    category_id UUID,
+    amount BIGINT NOT NULL CHECK (amount > 0),
+    comment TEXT,
+    position INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_transaction_split
+        FOREIGN KEY (transaction_id)
+        REFERENCES Transactions(transaction_id)
+        ON DELETE CASCADE,
+
+    CONSTRAINT fk_user_split
+        FOREIGN KEY (user_id)
+        REFERENCES Users(user_id)
+        ON DELETE CASCADE,
+
+    CONSTRAINT fk_category_split
+        FOREIGN KEY (category_id)
+        REFERENCES Category(category_id)
+        ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_transaction_splits_transaction ON TransactionSplits(transaction_id);
+CREATE INDEX IF NOT EXISTS idx_transaction_splits_category ON TransactionSplits(category_id) WHERE category_id IS NOT NULL;
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_transaction_tags_tag;
+DROP TABLE IF EXISTS TransactionTags;
+DROP INDEX IF EXISTS idx_tags_user_name;
+DROP TABLE IF EXISTS Tags;
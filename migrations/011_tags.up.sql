@@ -0,0 +1,35 @@
+CREATE TABLE IF NOT EXISTS Tags (
+    tag_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL,
+    name_tag VARCHAR(64) NOT NULL,
+    color VARCHAR(7),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_user_tag
+        FOREIGN KEY (user_id)
+        REFERENCES Users(user_id)
+        ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tags_user_name ON Tags(user_id, lower(name_tag));
+
+CREATE TABLE IF NOT EXISTS TransactionTags (
+    transaction_id UUID NOT NULL,
+    tag_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (transaction_id, tag_id),
+
+    CONSTRAINT fk_transaction_tag_transaction
+        FOREIGN KEY (transaction_id)
+        REFERENCES Transactions(transaction_id)
+        ON DELETE CASCADE,
+
+    CONSTRAINT fk_transaction_tag_tag
+        FOREIGN KEY (tag_id)
+        REFERENCES Tags(tag_id)
+        ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_transaction_tags_tag ON TransactionTags(tag_id);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_category_parent;
+ALTER TABLE Category DROP CONSTRAINT IF EXISTS chk_category_parent_self;
+ALTER TABLE Category DROP CONSTRAINT IF EXISTS fk_category_parent;
+ALTER TABLE Category DROP COLUMN IF EXISTS parent_id;
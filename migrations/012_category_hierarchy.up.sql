@@ -0,0 +1,11 @@
+ALTER TABLE Category ADD COLUMN IF NOT EXISTS parent_id UUID;
+
+ALTER TABLE Category ADD CONSTRAINT fk_category_parent
+    FOREIGN KEY (parent_id)
+    REFERENCES Category(category_id)
+    ON DELETE SET NULL;
+
+ALTER TABLE Category ADD CONSTRAINT chk_category_parent_self
+    CHECK (parent_id IS NULL OR parent_id <> category_id);
+
+CREATE INDEX IF NOT EXISTS idx_category_parent ON Category(parent_id) WHERE parent_id IS NOT NULL;
@@ -0,0 +1,29 @@
+DROP INDEX IF EXISTS idx_auto_category_rule_user_priority;
+DROP INDEX IF EXISTS idx_auto_category_rule_merchant;
+DROP INDEX IF EXISTS idx_auto_category_rule_mcc;
+
+DELETE FROM AutoCategoryRules WHERE source = 'user';
+
+ALTER TABLE AutoCategoryRules DROP CONSTRAINT IF EXISTS chk_auto_category_rule_source;
+ALTER TABLE AutoCategoryRules DROP CONSTRAINT IF EXISTS fk_tag_auto_category_rule;
+ALTER TABLE AutoCategoryRules DROP CONSTRAINT IF EXISTS fk_account_auto_category_rule;
+
+ALTER TABLE AutoCategoryRules DROP COLUMN IF EXISTS hide;
+ALTER TABLE AutoCategoryRules DROP COLUMN IF EXISTS tag_id;
+ALTER TABLE AutoCategoryRules DROP COLUMN IF EXISTS account_id;
+ALTER TABLE AutoCategoryRules DROP COLUMN IF EXISTS max_amount;
+ALTER TABLE AutoCategoryRules DROP COLUMN IF EXISTS min_amount;
+ALTER TABLE AutoCategoryRules DROP COLUMN IF EXISTS description_regex;
+ALTER TABLE AutoCategoryRules DROP COLUMN IF EXISTS description_contains;
+ALTER TABLE AutoCategoryRules DROP COLUMN IF EXISTS is_enabled;
+ALTER TABLE AutoCategoryRules DROP COLUMN IF EXISTS priority;
+ALTER TABLE AutoCategoryRules DROP COLUMN IF EXISTS source;
+ALTER TABLE AutoCategoryRules DROP COLUMN IF EXISTS name;
+
+ALTER TABLE AutoCategoryRules ALTER COLUMN category_id SET NOT NULL;
+ALTER TABLE AutoCategoryRules ALTER COLUMN is_income SET NOT NULL;
+
+DELETE FROM AutoCategoryRules WHERE mcc_code IS NULL AND merchant_key = '';
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_auto_category_rule_mcc ON AutoCategoryRules (user_id, is_income, mcc_code) WHERE mcc_code IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_auto_category_rule_merchant ON AutoCategoryRules (user_id, is_income, merchant_key) WHERE merchant_key <> '';
@@ -0,0 +1,83 @@
+-- AutoCategoryRules used to be created lazily by the transactions repository.
+CREATE TABLE IF NOT EXISTS AutoCategoryRules (
+    rule_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL,
+    is_income BOOLEAN NOT NULL,
+    mcc_code VARCHAR(4),
+    merchant_key TEXT NOT NULL DEFAULT '',
+    category_id UUID NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_user_auto_category_rule
+        FOREIGN KEY (user_id)
+        REFERENCES Users(user_id)
+        ON DELETE CASCADE,
+
+    CONSTRAINT fk_category_auto_category_rule
+        FOREIGN KEY (category_id)
+        REFERENCES Category(category_id)
+        ON DELETE CASCADE
+);
+
+DROP INDEX IF EXISTS idx_auto_category_rule_mcc;
+DROP INDEX IF EXISTS idx_auto_category_rule_merchant;
+
+ALTER TABLE AutoCategoryRules ALTER COLUMN is_income DROP NOT NULL;
+ALTER TABLE AutoCategoryRules ALTER COLUMN category_id DROP NOT NULL;
+
+ALTER TABLE AutoCategoryRules ADD COLUMN IF NOT EXISTS name VARCHAR(128) NOT NULL DEFAULT '';
+ALTER TABLE AutoCategoryRules ADD COLUMN IF NOT EXISTS source VARCHAR(16) NOT NULL DEFAULT 'learned';
+ALTER TABLE AutoCategoryRules ADD COLUMN IF NOT EXISTS priority INTEGER NOT NULL DEFAULT 1000;
+ALTER TABLE AutoCategoryRules ADD COLUMN IF NOT EXISTS is_enabled BOOLEAN NOT NULL DEFAULT TRUE;
+ALTER TABLE AutoCategoryRules ADD COLUMN IF NOT EXISTS description_contains TEXT;
+ALTER TABLE AutoCategoryRules ADD COLUMN IF NOT EXISTS description_regex TEXT;
+ALTER TABLE AutoCategoryRules ADD COLUMN IF NOT EXISTS min_amount BIGINT;
+ALTER TABLE AutoCategoryRules ADD COLUMN IF NOT EXISTS max_amount BIGINT;
+ALTER TABLE AutoCategoryRules ADD COLUMN IF NOT EXISTS account_id UUID;
+ALTER TABLE AutoCategoryRules ADD COLUMN IF NOT EXISTS tag_id UUID;
+ALTER TABLE AutoCategoryRules ADD COLUMN IF NOT EXISTS hide BOOLEAN NOT NULL DEFAULT FALSE;
+
+ALTER TABLE AutoCategoryRules ADD CONSTRAINT fk_account_auto_category_rule
+    FOREIGN KEY (account_id)
+    REFERENCES Accounts(account_id)
+    ON DELETE CASCADE;
+
+ALTER TABLE AutoCategoryRules ADD CONSTRAINT fk_tag_auto_category_rule
+    FOREIGN KEY (tag_id)
+    REFERENCES Tags(tag_id)
+    ON DELETE SET NULL;
+
+ALTER TABLE AutoCategoryRules ADD CONSTRAINT chk_auto_category_rule_source
+    CHECK (source IN ('learned', 'user'));
+
+-- Learned MCC rules used to carry the merchant key too, which made them
+-- collide with merchant rules. Split them into separate MCC and merchant rules.
+INSERT INTO AutoCategoryRules (user_id, is_income, mcc_code, merchant_key, category_id, created_at, updated_at)
+SELECT DISTINCT ON (r.user_id, r.is_income, r.merchant_key)
+    r.user_id, r.is_income, NULL, r.merchant_key, r.category_id, r.created_at, r.updated_at
+FROM AutoCategoryRules r
+WHERE r.mcc_code IS NOT NULL
+  AND r.merchant_key <> ''
+  AND NOT EXISTS (
+      SELECT 1 FROM AutoCategoryRules m
+      WHERE m.mcc_code IS NULL
+        AND m.user_id = r.user_id
+        AND m.is_income = r.is_income
+        AND m.merchant_key = r.merchant_key
+  )
+ORDER BY r.user_id, r.is_income, r.merchant_key, r.updated_at DESC;
+
+UPDATE AutoCategoryRules SET merchant_key = '' WHERE mcc_code IS NOT NULL;
+UPDATE AutoCategoryRules SET priority = 1001 WHERE source = 'learned' AND mcc_code IS NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_auto_category_rule_mcc
+    ON AutoCategoryRules (user_id, is_income, mcc_code)
+    WHERE source = 'learned' AND mcc_code IS NOT NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_auto_category_rule_merchant
+    ON AutoCategoryRules (user_id, is_income, merchant_key)
+    WHERE source = 'learned' AND mcc_code IS NULL AND merchant_key <> '';
+
+CREATE INDEX IF NOT EXISTS idx_auto_category_rule_user_priority
+    ON AutoCategoryRules (user_id, priority, created_at);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_transactions_review;
+ALTER TABLE Transactions DROP COLUMN IF EXISTS category_confidence;
+ALTER TABLE Transactions DROP COLUMN IF EXISTS category_source;
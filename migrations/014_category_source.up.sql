@@ -0,0 +1,12 @@
+ALTER TABLE Transactions ADD COLUMN IF NOT EXISTS category_source VARCHAR(16) NOT NULL DEFAULT '';
+ALTER TABLE Transactions ADD COLUMN IF NOT EXISTS category_confidence DOUBLE PRECISION;
+
+-- Manual transactions were always categorised by the user. Imported ones keep
+-- an unknown source.
+UPDATE Transactions
+SET category_source = 'manual'
+WHERE category_id IS NOT NULL AND NOT is_imported AND transfer_id IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_transactions_review
+    ON Transactions(user_id, completed_at DESC)
+    WHERE transfer_id IS NULL AND (category_id IS NULL OR category_source IN ('fallback', 'classifier'));
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS BalanceSnapshots;
+ALTER TABLE Accounts DROP COLUMN IF EXISTS opening_balance;
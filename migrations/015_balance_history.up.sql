@@ -0,0 +1,40 @@
+-- opening_balance is the balance before the first transaction, so the
+-- balance implied by the ledger is opening_balance plus the visible
+-- transactions. Existing accounts start reconciled.
+ALTER TABLE Accounts ADD COLUMN IF NOT EXISTS opening_balance BIGINT NOT NULL DEFAULT 0;
+
+UPDATE Accounts a
+SET opening_balance = a.balance - COALESCE((
+    SELECT SUM(CASE WHEN t.is_income THEN t.amount ELSE -t.amount END)
+    FROM Transactions t
+    WHERE t.account_id = a.account_id AND t.is_hidden = false
+), 0);
+
+CREATE TABLE IF NOT EXISTS BalanceSnapshots (
+    snapshot_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL,
+    account_id UUID NOT NULL,
+    batch_id UUID,
+    source VARCHAR(16) NOT NULL,
+    balance BIGINT NOT NULL,
+    taken_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_user_balance_snapshot
+        FOREIGN KEY (user_id)
+        REFERENCES Users(user_id)
+        ON DELETE CASCADE,
+
+    CONSTRAINT fk_account_balance_snapshot
+        FOREIGN KEY (account_id)
+        REFERENCES Accounts(account_id)
+        ON DELETE CASCADE,
+
+    -- Undoing an import drops the statement balance it reported.
+    CONSTRAINT fk_batch_balance_snapshot
+        FOREIGN KEY (batch_id)
+        REFERENCES ImportBatches(batch_id)
+        ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_balance_snapshots_account ON BalanceSnapshots(account_id, taken_at DESC);
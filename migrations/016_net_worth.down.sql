@@ -0,0 +1,2 @@
+ALTER TABLE Accounts DROP COLUMN IF EXISTS archived_at;
+ALTER TABLE Accounts DROP COLUMN IF EXISTS kind;
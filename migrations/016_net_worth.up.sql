@@ -0,0 +1,11 @@
+-- kind places an account on the asset or the liability side of net worth.
+-- Existing credit and loan accounts become liabilities.
+ALTER TABLE Accounts ADD COLUMN IF NOT EXISTS kind VARCHAR(16) NOT NULL DEFAULT 'asset';
+
+UPDATE Accounts
+SET kind = 'liability'
+WHERE UPPER(account_type) IN ('CREDIT', 'CREDIT_CARD', 'LOAN', 'MORTGAGE');
+
+-- archived_at bounds the history of an archived account. Accounts archived
+-- before it existed have no history in net worth.
+ALTER TABLE Accounts ADD COLUMN IF NOT EXISTS archived_at TIMESTAMPTZ;
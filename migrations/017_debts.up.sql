@@ -0,0 +1,38 @@
+-- Terms of a loan or a credit card kept on a liability account. Columns of
+-- the other debt type stay at zero.
+CREATE TABLE IF NOT EXISTS Debts (
+    debt_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL,
+    account_id UUID NOT NULL,
+    debt_type VARCHAR(16) NOT NULL,
+    grace_period_days INTEGER NOT NULL DEFAULT 0 CHECK (grace_period_days BETWEEN 0 AND 120),
+    payment_pattern VARCHAR(255),
+
+    principal BIGINT NOT NULL DEFAULT 0,
+    annual_rate DOUBLE PRECISION NOT NULL DEFAULT 0,
+    term_months INTEGER NOT NULL DEFAULT 0,
+    start_date TIMESTAMPTZ NOT NULL,
+    payment_day INTEGER NOT NULL DEFAULT 0,
+    track_from TIMESTAMPTZ NOT NULL,
+
+    credit_limit BIGINT NOT NULL DEFAULT 0,
+    statement_day INTEGER NOT NULL DEFAULT 0,
+    min_payment_percent DOUBLE PRECISION NOT NULL DEFAULT 0,
+    min_payment_floor BIGINT NOT NULL DEFAULT 0,
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_user_debt
+        FOREIGN KEY (user_id)
+        REFERENCES Users(user_id)
+        ON DELETE CASCADE,
+
+    CONSTRAINT fk_account_debt
+        FOREIGN KEY (account_id)
+        REFERENCES Accounts(account_id)
+        ON DELETE CASCADE
+);
+
+-- An account holds the terms of one debt.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_debts_account ON Debts(account_id);
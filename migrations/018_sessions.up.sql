@@ -0,0 +1,23 @@
+-- Signed-in devices. Access tokens carry session_id and stop working as soon
+-- as the session is revoked; only hashes of refresh tokens are stored.
+CREATE TABLE IF NOT EXISTS Sessions (
+    session_id UUID PRIMARY KEY,
+    user_id UUID NOT NULL,
+    refresh_token_hash CHAR(64) NOT NULL,
+    previous_token_hash CHAR(64),
+    user_agent VARCHAR(255) NOT NULL DEFAULT '',
+    ip VARCHAR(64) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_used_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMPTZ NOT NULL,
+    revoked_at TIMESTAMPTZ,
+
+    CONSTRAINT fk_user_session
+        FOREIGN KEY (user_id)
+        REFERENCES Users(user_id)
+        ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_sessions_refresh_token ON Sessions(refresh_token_hash);
+CREATE INDEX IF NOT EXISTS idx_sessions_previous_token ON Sessions(previous_token_hash);
+CREATE INDEX IF NOT EXISTS idx_sessions_user ON Sessions(user_id);
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS RecoveryCodes;
+
+ALTER TABLE Users DROP COLUMN IF EXISTS totp_last_step;
+ALTER TABLE Users DROP COLUMN IF EXISTS totp_enabled;
+ALTER TABLE Users DROP COLUMN IF EXISTS totp_secret;
@@ -0,0 +1,21 @@
+-- Optional TOTP second factor. totp_secret is set on setup and used once
+-- totp_enabled is turned on; totp_last_step keeps a code from being
+-- accepted twice.
+ALTER TABLE Users ADD COLUMN IF NOT EXISTS totp_secret VARCHAR(64);
+ALTER TABLE Users ADD COLUMN IF NOT EXISTS totp_enabled BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE Users ADD COLUMN IF NOT EXISTS totp_last_step BIGINT NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS RecoveryCodes (
+    code_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL,
+    code_hash TEXT NOT NULL,
+    used_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_user_recovery_code
+        FOREIGN KEY (user_id)
+        REFERENCES Users(user_id)
+        ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_recovery_codes_user ON RecoveryCodes(user_id);
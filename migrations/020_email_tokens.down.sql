@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS EmailTokens;
+
+ALTER TABLE Users DROP COLUMN IF EXISTS email_verified_at;
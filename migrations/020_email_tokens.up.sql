@@ -0,0 +1,21 @@
+-- Links sent by email to verify the address and to reset the password.
+-- Tokens are single-use and only their SHA-256 is stored.
+ALTER TABLE Users ADD COLUMN IF NOT EXISTS email_verified_at TIMESTAMPTZ;
+
+CREATE TABLE IF NOT EXISTS EmailTokens (
+    token_id UUID PRIMARY KEY,
+    user_id UUID NOT NULL,
+    purpose VARCHAR(32) NOT NULL,
+    token_hash CHAR(64) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_at TIMESTAMPTZ,
+
+    CONSTRAINT fk_user_email_token
+        FOREIGN KEY (user_id)
+        REFERENCES Users(user_id)
+        ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_email_tokens_hash ON EmailTokens(token_hash);
+CREATE INDEX IF NOT EXISTS idx_email_tokens_user ON EmailTokens(user_id, purpose);
@@ -0,0 +1 @@
+ALTER TABLE Transactions DROP COLUMN IF EXISTS is_adjustment;
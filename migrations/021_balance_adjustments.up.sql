@@ -0,0 +1,12 @@
+-- Adjusting entries posted by reconciliation only move the ledger to the
+-- real balance. They are not income or spending, so reports and budgets
+-- leave them out.
+ALTER TABLE Transactions ADD COLUMN IF NOT EXISTS is_adjustment BOOLEAN NOT NULL DEFAULT false;
+
+UPDATE Transactions
+SET is_adjustment = true
+WHERE name_transaction = 'Корректировка баланса'
+  AND category_id IS NULL
+  AND category_source = 'manual'
+  AND transfer_id IS NULL
+  AND external_transaction_id IS NULL;
@@ -0,0 +1,3 @@
+ALTER TABLE RecurringTemplates DROP COLUMN IF EXISTS retry_at;
+ALTER TABLE RecurringTemplates DROP COLUMN IF EXISTS last_error;
+ALTER TABLE RecurringTemplates DROP COLUMN IF EXISTS failure_count;
@@ -0,0 +1,6 @@
+-- A template whose occurrences fail to post, e.g. because its account was
+-- archived, is retried with a growing delay instead of on every run, and
+-- turned off after too many failures in a row.
+ALTER TABLE RecurringTemplates ADD COLUMN IF NOT EXISTS failure_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE RecurringTemplates ADD COLUMN IF NOT EXISTS last_error TEXT;
+ALTER TABLE RecurringTemplates ADD COLUMN IF NOT EXISTS retry_at TIMESTAMPTZ;
@@ -0,0 +1,2 @@
+-- The flipped balances are not told apart from ones entered negative, so
+-- they are kept.
@@ -0,0 +1,16 @@
+-- Money owed on a liability is a negative balance. Manual credit and loan
+-- accounts created with the debt entered as a positive opening balance are
+-- flipped, keeping the transactions recorded on them as they are.
+UPDATE Accounts
+SET balance = balance - 2 * opening_balance,
+    opening_balance = -opening_balance
+WHERE kind = 'liability' AND is_imported = false AND opening_balance > 0;
+
+UPDATE BalanceSnapshots s
+SET balance = -s.balance
+FROM Accounts a
+WHERE a.account_id = s.account_id
+  AND a.kind = 'liability'
+  AND a.is_imported = false
+  AND s.source IN ('manual', 'valuation')
+  AND s.balance > 0;
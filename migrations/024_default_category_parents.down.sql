@@ -0,0 +1,2 @@
+-- The attached subcategories are not told apart from ones the user moved,
+-- so they are kept.
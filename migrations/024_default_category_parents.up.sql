@@ -0,0 +1,17 @@
+-- Default subcategories seeded before they had a parent stay at the top
+-- level, since seeding never touches existing rows. Attach them once here.
+UPDATE Category c
+SET parent_id = p.category_id
+FROM (VALUES
+    ('Такси', 'Транспорт'),
+    ('Метро', 'Транспорт'),
+    ('Доставка еды', 'Кафе и рестораны'),
+    ('Коммунальные услуги', 'Жилье')
+) AS d(name_category, parent_name)
+JOIN Category p ON p.name_category = d.parent_name
+WHERE c.name_category = d.name_category
+  AND p.user_id = c.user_id
+  AND p.is_income = false
+  AND c.is_income = false
+  AND c.is_custom = false
+  AND c.parent_id IS NULL;